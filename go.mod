@@ -10,6 +10,7 @@ require (
 	github.com/hashicorp/raft-boltdb v0.0.0-20230125174641-2a8082862702
 	github.com/sethvargo/go-retry v0.2.4
 	github.com/tidwall/resp v0.1.1
+	github.com/yuin/gopher-lua v1.1.1
 	gopkg.in/yaml.v3 v3.0.1
 )
 
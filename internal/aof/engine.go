@@ -24,6 +24,7 @@ import (
 	"github.com/echovault/sugardb/internal/clock"
 	"log"
 	"sync"
+	"time"
 )
 
 type Engine struct {
@@ -43,6 +44,7 @@ type Engine struct {
 	getStateFunc      func() map[int]map[string]internal.KeyData
 	setKeyDataFunc    func(database int, key string, data internal.KeyData)
 	handleCommand     func(database int, command []byte)
+	recordLatency     func(event string, latency time.Duration)
 }
 
 func WithClock(clock clock.Clock) func(engine *Engine) {
@@ -93,6 +95,12 @@ func WithHandleCommandFunc(f func(database int, command []byte)) func(engine *En
 	}
 }
 
+func WithRecordLatencyFunc(f func(event string, latency time.Duration)) func(engine *Engine) {
+	return func(engine *Engine) {
+		engine.recordLatency = f
+	}
+}
+
 func WithPreambleReadWriter(rw preamble.ReadWriter) func(engine *Engine) {
 	return func(engine *Engine) {
 		engine.preambleRW = rw
@@ -117,6 +125,7 @@ func NewAOFEngine(options ...func(engine *Engine)) (*Engine, error) {
 		getStateFunc:      func() map[int]map[string]internal.KeyData { return nil },
 		setKeyDataFunc:    func(database int, key string, data internal.KeyData) {},
 		handleCommand:     func(database int, command []byte) {},
+		recordLatency:     func(event string, latency time.Duration) {},
 	}
 
 	// Setup AOFEngine options first as these options are used
@@ -145,6 +154,7 @@ func NewAOFEngine(options ...func(engine *Engine)) (*Engine, error) {
 		logstore.WithStrategy(engine.syncStrategy),
 		logstore.WithReadWriter(engine.appendRW),
 		logstore.WithHandleCommandFunc(engine.handleCommand),
+		logstore.WithRecordLatencyFunc(engine.recordLatency),
 	)
 	if err != nil {
 		return nil, err
@@ -160,6 +170,11 @@ func (engine *Engine) LogCommand(database int, command []byte) {
 	}
 }
 
+// SetStrategy changes the sync strategy of the AOF file.
+func (engine *Engine) SetStrategy(strategy string) {
+	engine.appendStore.SetStrategy(strategy)
+}
+
 func (engine *Engine) RewriteLog() error {
 	engine.mut.Lock()
 	defer engine.mut.Unlock()
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"github.com/echovault/sugardb/internal"
 	"github.com/echovault/sugardb/internal/clock"
+	"github.com/echovault/sugardb/internal/constants"
 	"github.com/tidwall/resp"
 	"io"
 	"log"
@@ -50,6 +51,8 @@ type Store struct {
 	directory string
 	// Function to handle command read from AOF log after restore.
 	handleCommand func(database int, command []byte)
+	// Function to report the duration of each sync of the log to the latency monitor.
+	recordLatency func(event string, latency time.Duration)
 }
 
 func WithClock(clock clock.Clock) func(store *Store) {
@@ -82,6 +85,12 @@ func WithHandleCommandFunc(f func(database int, command []byte)) func(store *Sto
 	}
 }
 
+func WithRecordLatencyFunc(f func(event string, latency time.Duration)) func(store *Store) {
+	return func(store *Store) {
+		store.recordLatency = f
+	}
+}
+
 func NewAppendStore(options ...func(store *Store)) (*Store, error) {
 	store := &Store{
 		clock:           clock.NewClock(),
@@ -91,6 +100,7 @@ func NewAppendStore(options ...func(store *Store)) (*Store, error) {
 		rw:              nil,
 		mut:             sync.Mutex{},
 		handleCommand:   func(database int, command []byte) {},
+		recordLatency:   func(event string, latency time.Duration) {},
 	}
 
 	for _, option := range options {
@@ -112,25 +122,26 @@ func NewAppendStore(options ...func(store *Store)) (*Store, error) {
 	}
 
 	// Start another goroutine that takes handles syncing the content to the file system.
-	// No need to start this goroutine if sync strategy is anything other than 'everysec'.
-	if strings.EqualFold(store.strategy, "everysec") {
-		go func() {
-			ticker := time.NewTicker(1 * time.Second)
-			defer func() {
-				ticker.Stop()
-			}()
-			for {
-				store.mut.Lock()
+	// The content is only synced when the sync strategy is 'everysec'. The goroutine runs with the other
+	// strategies too because the strategy can be changed with SetStrategy.
+	go func() {
+		ticker := time.NewTicker(1 * time.Second)
+		defer func() {
+			ticker.Stop()
+		}()
+		for {
+			store.mut.Lock()
+			if strings.EqualFold(store.strategy, "everysec") {
 				if err := store.Sync(); err != nil {
 					store.mut.Unlock()
 					log.Println(fmt.Errorf("new append store error: %+v", err))
 					break
 				}
-				store.mut.Unlock()
-				<-ticker.C
 			}
-		}()
-	}
+			store.mut.Unlock()
+			<-ticker.C
+		}
+	}()
 
 	return store, nil
 }
@@ -168,8 +179,19 @@ func (store *Store) Write(database int, command []byte) error {
 	return nil
 }
 
+// SetStrategy changes the sync strategy of the append file.
+func (store *Store) SetStrategy(strategy string) {
+	store.mut.Lock()
+	defer store.mut.Unlock()
+	store.strategy = strings.ToLower(strategy)
+}
+
 func (store *Store) Sync() error {
 	if store.rw != nil {
+		start := time.Now()
+		defer func() {
+			store.recordLatency(constants.AOFFsyncLatencyEvent, time.Since(start))
+		}()
 		return store.rw.Sync()
 	}
 	return nil
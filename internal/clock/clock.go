@@ -39,3 +39,16 @@ func (MockClock) Now() time.Time {
 func (MockClock) After(d time.Duration) <-chan time.Time {
 	return time.After(d)
 }
+
+// FixedClock is a clock stopped at Time.
+type FixedClock struct {
+	Time time.Time
+}
+
+func (clock FixedClock) Now() time.Time {
+	return clock.Time
+}
+
+func (FixedClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
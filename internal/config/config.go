@@ -24,7 +24,6 @@ import (
 	"log"
 	"os"
 	"path"
-	"slices"
 	"strings"
 	"time"
 
@@ -57,8 +56,28 @@ type Config struct {
 	EvictionInterval  time.Duration `json:"EvictionInterval" yaml:"EvictionInterval"`
 	Modules           []string      `json:"Plugins" yaml:"Plugins"`
 	DiscoveryPort     uint16        `json:"DiscoveryPort" yaml:"DiscoveryPort"`
-	RaftBindAddr      string
-	RaftBindPort      uint16
+	// MetricsPort is the port of the HTTP listener that serves the metrics in the Prometheus text format at /metrics.
+	// The listener is bound to BindAddr. It's disabled when the port is 0.
+	MetricsPort uint16 `json:"MetricsPort" yaml:"MetricsPort"`
+	// NotifyKeyspaceEvents holds the flags of the keyspace event classes published through pub/sub.
+	// See ParseKeyspaceEvents for the syntax. Keyspace notifications are disabled when empty.
+	NotifyKeyspaceEvents string `json:"NotifyKeyspaceEvents" yaml:"NotifyKeyspaceEvents"`
+	// SlowlogLogSlowerThan is the execution time in microseconds above which a command is recorded in the slow log.
+	// A negative value disables the slow log and 0 records every command.
+	SlowlogLogSlowerThan int64 `json:"SlowlogLogSlowerThan" yaml:"SlowlogLogSlowerThan"`
+	// SlowlogMaxLen is the maximum number of entries kept in the slow log. The oldest entries are removed first.
+	SlowlogMaxLen uint `json:"SlowlogMaxLen" yaml:"SlowlogMaxLen"`
+	// LuaTimeLimit is the execution time in milliseconds after which a Lua script is stopped, unless it already
+	// called a write command. The scripts are not stopped when it's 0.
+	LuaTimeLimit uint64 `json:"LuaTimeLimit" yaml:"LuaTimeLimit"`
+	// LatencyMonitorThreshold is the latency in milliseconds from which the events are recorded by the latency
+	// monitor. The latency monitor is disabled when it's 0.
+	LatencyMonitorThreshold uint64 `json:"LatencyMonitorThreshold" yaml:"LatencyMonitorThreshold"`
+	RaftBindAddr            string
+	RaftBindPort            uint16
+	// ConfigFile is the path of the JSON or YAML config file that the config was loaded from.
+	// CONFIG REWRITE persists the runtime configuration changes to this file.
+	ConfigFile string `json:"-" yaml:"-"`
 }
 
 func GetConfig() (Config, error) {
@@ -88,12 +107,11 @@ func GetConfig() (Config, error) {
 	flag.Func("aof-sync-strategy", `How often to flush the file contents written to append only file.
 The options are 'always' for syncing on each command, 'everysec' to sync every second, and 'no' to leave it up to the os.`,
 		func(option string) error {
-			if !slices.ContainsFunc([]string{"always", "everysec", "no"}, func(s string) bool {
-				return strings.EqualFold(s, option)
-			}) {
-				return errors.New("aofSyncStrategy must be 'always', 'everysec' or 'no'")
+			strategy, err := ParseAOFSyncStrategy(option)
+			if err != nil {
+				return err
 			}
-			aofSyncStrategy = strings.ToLower(option)
+			aofSyncStrategy = strategy
 			return nil
 		})
 
@@ -119,16 +137,11 @@ There is no limit by default.`, func(memory string) error {
 5) volatile-lru - Evict the least recently used keys with an expiration.
 6) allkeys-random - Evict random keys until we get under the max-memory limit.
 7) volatile-random - Evict random keys with an expiration.`, func(policy string) error {
-			policies := []string{
-				constants.NoEviction,
-				constants.AllKeysLFU, constants.AllKeysLRU, constants.AllKeysRandom,
-				constants.VolatileLFU, constants.VolatileLRU, constants.VolatileRandom,
+			p, err := ParseEvictionPolicy(policy)
+			if err != nil {
+				return err
 			}
-			policyIdx := slices.Index(policies, strings.ToLower(policy))
-			if policyIdx == -1 {
-				return fmt.Errorf("policy %s is not a valid policy", policy)
-			}
-			evictionPolicy = strings.ToLower(policy)
+			evictionPolicy = p
 			return nil
 		})
 
@@ -144,6 +157,19 @@ There is no limit by default.`, func(memory string) error {
 			return nil
 		})
 
+	notifyKeyspaceEvents := ""
+	flag.Func("notify-keyspace-events",
+		`The classes of keyspace events to publish to the __keyspace@<db>__:<key> and __keyevent@<db>__:<event> channels.
+The flags are: K (keyspace channels), E (keyevent channels), g (generic), $ (string), l (list), s (set), h (hash),
+z (sorted set), x (expired), e (evicted), t (stream), m (key miss), n (new key) and A (alias for g$lshzxet).
+At least K or E must be combined with a class. Keyspace notifications are disabled by default.`, func(flags string) error {
+			if _, err := ParseKeyspaceEvents(flags); err != nil {
+				return err
+			}
+			notifyKeyspaceEvents = flags
+			return nil
+		})
+
 	tls := flag.Bool("tls", false, "Start the echovault in TLS mode. Default is false.")
 	mtls := flag.Bool("mtls", false, "Use mTLS to verify the client.")
 	port := flag.Int("port", 7480, "Port to use. Default is 7480")
@@ -159,6 +185,15 @@ There is no limit by default.`, func(memory string) error {
 	restoreSnapshot := flag.Bool("restore-snapshot", false, "This flag prompts the echovault to restore state from snapshot when set to true. Only works in standalone mode. Higher priority than restoreAOF.")
 	restoreAOF := flag.Bool("restore-aof", false, "This flag prompts the echovault to restore state from append-only logs. Only works in standalone mode. Lower priority than restoreSnapshot.")
 	evictionSample := flag.Uint("eviction-sample", 20, "An integer specifying the number of keys to sample when checking for expired keys.")
+	slowlogLogSlowerThan := flag.Int64("slowlog-log-slower-than", 10000, `The execution time in microseconds above which a command is recorded in the slow log.
+A negative value disables the slow log and 0 records every command. Default is 10000.`)
+	slowlogMaxLen := flag.Uint("slowlog-max-len", 128, "The maximum number of entries kept in the slow log. Default is 128.")
+	latencyMonitorThreshold := flag.Uint64("latency-monitor-threshold", 0, `The latency in milliseconds from which the events are recorded by the latency monitor.
+The latency monitor is disabled when it's 0. Default is 0.`)
+	luaTimeLimit := flag.Uint64("lua-time-limit", 5000, `The execution time in milliseconds after which a Lua script is stopped,
+unless it already called a write command. The scripts are not stopped when it's 0. Default is 5000.`)
+	metricsPort := flag.Uint("metrics-port", 0, `The port of the HTTP listener that serves the Prometheus metrics at /metrics.
+The metrics listener is disabled when it's 0. Default is 0.`)
 	evictionInterval := flag.Duration("eviction-interval", 100*time.Millisecond, "The interval between each sampling of keys to evict.")
 	forwardCommand := flag.Bool(
 		"forward-commands",
@@ -194,33 +229,41 @@ It is a plain text value by default but you can provide a SHA256 hash by adding
 	}
 
 	conf := Config{
-		CertKeyPairs:      certKeyPairs,
-		ClientCAs:         clientCAs,
-		TLS:               *tls,
-		MTLS:              *mtls,
-		Port:              uint16(*port),
-		ServerID:          *serverId,
-		JoinAddr:          *joinAddr,
-		BindAddr:          *bindAddr,
-		DataDir:           *dataDir,
-		BootstrapCluster:  *bootstrapCluster,
-		AclConfig:         *aclConfig,
-		ForwardCommand:    *forwardCommand,
-		RequirePass:       *requirePass,
-		Password:          *password,
-		SnapShotThreshold: *snapshotThreshold,
-		SnapshotInterval:  *snapshotInterval,
-		RestoreSnapshot:   *restoreSnapshot,
-		RestoreAOF:        *restoreAOF,
-		AOFSyncStrategy:   aofSyncStrategy,
-		MaxMemory:         maxMemory,
-		EvictionPolicy:    evictionPolicy,
-		EvictionSample:    *evictionSample,
-		EvictionInterval:  *evictionInterval,
-		Modules:           modules,
-		DiscoveryPort:     uint16(*discoveryPort),
-		RaftBindAddr:      raftBindAddr,
-		RaftBindPort:      uint16(raftBindPort),
+		CertKeyPairs:         certKeyPairs,
+		ClientCAs:            clientCAs,
+		TLS:                  *tls,
+		MTLS:                 *mtls,
+		Port:                 uint16(*port),
+		ServerID:             *serverId,
+		JoinAddr:             *joinAddr,
+		BindAddr:             *bindAddr,
+		DataDir:              *dataDir,
+		BootstrapCluster:     *bootstrapCluster,
+		AclConfig:            *aclConfig,
+		ForwardCommand:       *forwardCommand,
+		RequirePass:          *requirePass,
+		Password:             *password,
+		SnapShotThreshold:    *snapshotThreshold,
+		SnapshotInterval:     *snapshotInterval,
+		RestoreSnapshot:      *restoreSnapshot,
+		RestoreAOF:           *restoreAOF,
+		AOFSyncStrategy:      aofSyncStrategy,
+		MaxMemory:            maxMemory,
+		EvictionPolicy:       evictionPolicy,
+		EvictionSample:       *evictionSample,
+		EvictionInterval:     *evictionInterval,
+		Modules:              modules,
+		DiscoveryPort:        uint16(*discoveryPort),
+		NotifyKeyspaceEvents: notifyKeyspaceEvents,
+		SlowlogLogSlowerThan: *slowlogLogSlowerThan,
+		SlowlogMaxLen:        *slowlogMaxLen,
+		RaftBindAddr:         raftBindAddr,
+		RaftBindPort:         uint16(raftBindPort),
+		ConfigFile:           *config,
+
+		LatencyMonitorThreshold: *latencyMonitorThreshold,
+		LuaTimeLimit:            *luaTimeLimit,
+		MetricsPort:             uint16(*metricsPort),
 	}
 
 	if len(*config) > 0 {
@@ -257,5 +300,9 @@ It is a plain text value by default but you can provide a SHA256 hash by adding
 		err = errors.New("password cannot be empty if requirePass is true")
 	}
 
+	if _, e = ParseKeyspaceEvents(conf.NotifyKeyspaceEvents); e != nil && err == nil {
+		err = e
+	}
+
 	return conf, err
 }
@@ -38,5 +38,12 @@ func DefaultConfig() Config {
 		EvictionSample:    20,
 		EvictionInterval:  100 * time.Millisecond,
 		Modules:           make([]string, 0),
+
+		SlowlogLogSlowerThan: 10000,
+		SlowlogMaxLen:        128,
+
+		LatencyMonitorThreshold: 0,
+		LuaTimeLimit:            5000,
+		MetricsPort:             0,
 	}
 }
@@ -0,0 +1,105 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The classes of keyspace events that can be enabled with NotifyKeyspaceEvents.
+const (
+	KeyspaceEventsKeyspace  = 1 << iota // K: Publish to the __keyspace@<db>__:<key> channels.
+	KeyspaceEventsKeyevent              // E: Publish to the __keyevent@<db>__:<event> channels.
+	KeyspaceEventsGeneric               // g: Generic commands like DEL, EXPIRE and RENAME.
+	KeyspaceEventsString                // $: String commands.
+	KeyspaceEventsList                  // l: List commands.
+	KeyspaceEventsSet                   // s: Set commands.
+	KeyspaceEventsHash                  // h: Hash commands.
+	KeyspaceEventsSortedSet             // z: Sorted set commands.
+	KeyspaceEventsExpired               // x: Keys deleted because their TTL elapsed.
+	KeyspaceEventsEvicted               // e: Keys deleted because of the max-memory eviction policy.
+	KeyspaceEventsStream                // t: Stream commands.
+	KeyspaceEventsKeyMiss               // m: Accesses to keys that don't exist.
+	KeyspaceEventsNew                   // n: Keys that are created.
+
+	// KeyspaceEventsAll is the "A" alias. It does not include the key miss and new key events.
+	KeyspaceEventsAll = KeyspaceEventsGeneric | KeyspaceEventsString | KeyspaceEventsList | KeyspaceEventsSet |
+		KeyspaceEventsHash | KeyspaceEventsSortedSet | KeyspaceEventsExpired | KeyspaceEventsEvicted |
+		KeyspaceEventsStream
+)
+
+var keyspaceEventClasses = []struct {
+	flag  byte
+	class int
+}{
+	{'g', KeyspaceEventsGeneric},
+	{'$', KeyspaceEventsString},
+	{'l', KeyspaceEventsList},
+	{'s', KeyspaceEventsSet},
+	{'h', KeyspaceEventsHash},
+	{'z', KeyspaceEventsSortedSet},
+	{'x', KeyspaceEventsExpired},
+	{'e', KeyspaceEventsEvicted},
+	{'t', KeyspaceEventsStream},
+	{'K', KeyspaceEventsKeyspace},
+	{'E', KeyspaceEventsKeyevent},
+	{'m', KeyspaceEventsKeyMiss},
+	{'n', KeyspaceEventsNew},
+}
+
+// ParseKeyspaceEvents converts the flags of the notify-keyspace-events setting into a bit mask of event classes.
+// Events are only published when K or E is set together with at least one class.
+// An empty string disables keyspace notifications.
+func ParseKeyspaceEvents(flags string) (int, error) {
+	mask := 0
+	for i := 0; i < len(flags); i++ {
+		if flags[i] == 'A' {
+			mask |= KeyspaceEventsAll
+			continue
+		}
+		found := false
+		for _, class := range keyspaceEventClasses {
+			if class.flag == flags[i] {
+				mask |= class.class
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("invalid keyspace events flag '%c'", flags[i])
+		}
+	}
+	return mask, nil
+}
+
+// FormatKeyspaceEvents converts a bit mask of event classes back into notify-keyspace-events flags.
+// The classes included in the "A" alias are collapsed into "A" when they are all set.
+func FormatKeyspaceEvents(mask int) string {
+	var flags strings.Builder
+	for _, class := range keyspaceEventClasses {
+		if mask&class.class == 0 {
+			continue
+		}
+		if class.class&KeyspaceEventsAll != 0 && mask&KeyspaceEventsAll == KeyspaceEventsAll {
+			if class.class == KeyspaceEventsGeneric {
+				flags.WriteByte('A')
+			}
+			continue
+		}
+		flags.WriteByte(class.flag)
+	}
+	return flags.String()
+}
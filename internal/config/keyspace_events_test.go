@@ -0,0 +1,80 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import "testing"
+
+func Test_ParseKeyspaceEvents(t *testing.T) {
+	tests := []struct {
+		name      string
+		flags     string
+		want      int
+		formatted string
+		wantErr   bool
+	}{
+		{
+			name:      "1. Empty flags disable notifications",
+			flags:     "",
+			want:      0,
+			formatted: "",
+		},
+		{
+			name:      "2. Expired events on the keyevent channels",
+			flags:     "Ex",
+			want:      KeyspaceEventsKeyevent | KeyspaceEventsExpired,
+			formatted: "xE",
+		},
+		{
+			name:      "3. A includes every class except key miss and new key events",
+			flags:     "KEA",
+			want:      KeyspaceEventsKeyspace | KeyspaceEventsKeyevent | KeyspaceEventsAll,
+			formatted: "AKE",
+		},
+		{
+			name:  "4. Classes that add up to A are formatted as A",
+			flags: "Kg$lshzxetmn",
+			want: KeyspaceEventsKeyspace | KeyspaceEventsAll | KeyspaceEventsKeyMiss |
+				KeyspaceEventsNew,
+			formatted: "AKmn",
+		},
+		{
+			name:    "5. Unknown flags are rejected",
+			flags:   "KEq",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseKeyspaceEvents(tt.flags)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error for flags %q", tt.flags)
+				}
+				return
+			}
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("expected mask %b, got %b", tt.want, got)
+			}
+			if formatted := FormatKeyspaceEvents(got); formatted != tt.formatted {
+				t.Errorf("expected flags %q, got %q", tt.formatted, formatted)
+			}
+		})
+	}
+}
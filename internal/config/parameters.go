@@ -0,0 +1,318 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/echovault/sugardb/internal"
+	"github.com/echovault/sugardb/internal/constants"
+	"github.com/gobwas/glob"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parameter is a configuration parameter that can be read with CONFIG GET.
+// The parameters are named after the command line flags.
+type parameter struct {
+	name string
+	get  func(config Config) string
+	// set parses the value and applies it to the config.
+	// It's nil for the parameters that can't be changed while the server is running.
+	set func(config *Config, value string) error
+}
+
+var parameters = []parameter{
+	{name: "tls", get: func(config Config) string { return formatBool(config.TLS) }},
+	{name: "mtls", get: func(config Config) string { return formatBool(config.MTLS) }},
+	{name: "port", get: func(config Config) string { return strconv.Itoa(int(config.Port)) }},
+	{name: "server-id", get: func(config Config) string { return config.ServerID }},
+	{name: "join-addr", get: func(config Config) string { return config.JoinAddr }},
+	{name: "bind-addr", get: func(config Config) string { return config.BindAddr }},
+	{name: "discovery-port", get: func(config Config) string { return strconv.Itoa(int(config.DiscoveryPort)) }},
+	{name: "metrics-port", get: func(config Config) string { return strconv.Itoa(int(config.MetricsPort)) }},
+	{name: "data-dir", get: func(config Config) string { return config.DataDir }},
+	{name: "bootstrap-cluster", get: func(config Config) string { return formatBool(config.BootstrapCluster) }},
+	{name: "acl-config", get: func(config Config) string { return config.AclConfig }},
+	{name: "forward-commands", get: func(config Config) string { return formatBool(config.ForwardCommand) }},
+	{name: "restore-snapshot", get: func(config Config) string { return formatBool(config.RestoreSnapshot) }},
+	{name: "restore-aof", get: func(config Config) string { return formatBool(config.RestoreAOF) }},
+	{name: "loadmodule", get: func(config Config) string { return strings.Join(config.Modules, " ") }},
+	{
+		name: "require-pass",
+		get:  func(config Config) string { return formatBool(config.RequirePass) },
+		set: func(config *Config, value string) error {
+			requirePass, err := parseBool(value)
+			config.RequirePass = requirePass
+			return err
+		},
+	},
+	{
+		name: "password",
+		get:  func(config Config) string { return config.Password },
+		set: func(config *Config, value string) error {
+			config.Password = value
+			return nil
+		},
+	},
+	{
+		name: "snapshot-threshold",
+		get:  func(config Config) string { return strconv.FormatUint(config.SnapShotThreshold, 10) },
+		set: func(config *Config, value string) error {
+			threshold, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return errors.New("snapshot-threshold must be a positive integer")
+			}
+			config.SnapShotThreshold = threshold
+			return nil
+		},
+	},
+	{
+		name: "snapshot-interval",
+		get:  func(config Config) string { return config.SnapshotInterval.String() },
+		set: func(config *Config, value string) error {
+			interval, err := parseDuration(value)
+			config.SnapshotInterval = interval
+			return err
+		},
+	},
+	{
+		name: "aof-sync-strategy",
+		get:  func(config Config) string { return config.AOFSyncStrategy },
+		set: func(config *Config, value string) error {
+			strategy, err := ParseAOFSyncStrategy(value)
+			config.AOFSyncStrategy = strategy
+			return err
+		},
+	},
+	{
+		name: "max-memory",
+		get:  func(config Config) string { return strconv.FormatUint(config.MaxMemory, 10) },
+		set: func(config *Config, value string) error {
+			maxMemory, err := strconv.ParseUint(value, 10, 64)
+			if err != nil && len(value) > 2 {
+				maxMemory, err = internal.ParseMemory(value)
+			}
+			if err != nil {
+				return errors.New("max-memory must be a number of bytes or a size with a unit (kb, mb, gb, tb, pb)")
+			}
+			config.MaxMemory = maxMemory
+			return nil
+		},
+	},
+	{
+		name: "eviction-policy",
+		get:  func(config Config) string { return config.EvictionPolicy },
+		set: func(config *Config, value string) error {
+			policy, err := ParseEvictionPolicy(value)
+			config.EvictionPolicy = policy
+			return err
+		},
+	},
+	{
+		name: "eviction-sample",
+		get:  func(config Config) string { return strconv.FormatUint(uint64(config.EvictionSample), 10) },
+		set: func(config *Config, value string) error {
+			sample, err := strconv.ParseUint(value, 10, 32)
+			if err != nil || sample == 0 {
+				return errors.New("eviction-sample must be an integer greater than 0")
+			}
+			config.EvictionSample = uint(sample)
+			return nil
+		},
+	},
+	{
+		name: "eviction-interval",
+		get:  func(config Config) string { return config.EvictionInterval.String() },
+		set: func(config *Config, value string) error {
+			interval, err := parseDuration(value)
+			if err == nil && interval == 0 {
+				err = errors.New("eviction-interval must be greater than 0")
+			}
+			config.EvictionInterval = interval
+			return err
+		},
+	},
+	{
+		name: "notify-keyspace-events",
+		get: func(config Config) string {
+			mask, _ := ParseKeyspaceEvents(config.NotifyKeyspaceEvents)
+			return FormatKeyspaceEvents(mask)
+		},
+		set: func(config *Config, value string) error {
+			if _, err := ParseKeyspaceEvents(value); err != nil {
+				return err
+			}
+			config.NotifyKeyspaceEvents = value
+			return nil
+		},
+	},
+	{
+		name: "slowlog-log-slower-than",
+		get:  func(config Config) string { return strconv.FormatInt(config.SlowlogLogSlowerThan, 10) },
+		set: func(config *Config, value string) error {
+			threshold, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return errors.New("slowlog-log-slower-than must be an integer")
+			}
+			config.SlowlogLogSlowerThan = threshold
+			return nil
+		},
+	},
+	{
+		name: "slowlog-max-len",
+		get:  func(config Config) string { return strconv.FormatUint(uint64(config.SlowlogMaxLen), 10) },
+		set: func(config *Config, value string) error {
+			maxLen, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return errors.New("slowlog-max-len must be a positive integer")
+			}
+			config.SlowlogMaxLen = uint(maxLen)
+			return nil
+		},
+	},
+	{
+		name: "latency-monitor-threshold",
+		get:  func(config Config) string { return strconv.FormatUint(config.LatencyMonitorThreshold, 10) },
+		set: func(config *Config, value string) error {
+			threshold, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return errors.New("latency-monitor-threshold must be a positive integer")
+			}
+			config.LatencyMonitorThreshold = threshold
+			return nil
+		},
+	},
+	{
+		name: "lua-time-limit",
+		get:  func(config Config) string { return strconv.FormatUint(config.LuaTimeLimit, 10) },
+		set: func(config *Config, value string) error {
+			limit, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return errors.New("lua-time-limit must be a positive integer")
+			}
+			config.LuaTimeLimit = limit
+			return nil
+		},
+	},
+}
+
+// GetParameters returns the value of each configuration parameter whose name matches one of the glob patterns.
+// The patterns are matched case-insensitively.
+func GetParameters(config Config, patterns ...string) map[string]string {
+	globs := make([]glob.Glob, 0, len(patterns))
+	for _, pattern := range patterns {
+		if g, err := glob.Compile(strings.ToLower(pattern)); err == nil {
+			globs = append(globs, g)
+		}
+	}
+
+	res := make(map[string]string)
+	for _, param := range parameters {
+		if slices.ContainsFunc(globs, func(g glob.Glob) bool { return g.Match(param.name) }) {
+			res[param.name] = param.get(config)
+		}
+	}
+	return res
+}
+
+// SetParameters applies the values of the configuration parameters, by parameter name, to the config.
+// The config is left unchanged if one of the parameters is unknown, can't be changed at runtime, or has an
+// invalid value.
+func SetParameters(config *Config, values map[string]string) error {
+	conf := *config
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		idx := slices.IndexFunc(parameters, func(param parameter) bool {
+			return param.name == strings.ToLower(name)
+		})
+		if idx == -1 {
+			return fmt.Errorf("unknown option '%s'", name)
+		}
+		if parameters[idx].set == nil {
+			return fmt.Errorf("can't set immutable config '%s'", name)
+		}
+		if err := parameters[idx].set(&conf, values[name]); err != nil {
+			return fmt.Errorf("invalid value for '%s': %v", name, err)
+		}
+	}
+
+	if conf.RequirePass && conf.Password == "" {
+		return errors.New("password cannot be empty if require-pass is yes")
+	}
+
+	*config = conf
+	return nil
+}
+
+// ParseAOFSyncStrategy validates the AOF sync strategy and returns it in lower case.
+func ParseAOFSyncStrategy(strategy string) (string, error) {
+	if !slices.ContainsFunc([]string{"always", "everysec", "no"}, func(s string) bool {
+		return strings.EqualFold(s, strategy)
+	}) {
+		return "", errors.New("aofSyncStrategy must be 'always', 'everysec' or 'no'")
+	}
+	return strings.ToLower(strategy), nil
+}
+
+// ParseEvictionPolicy validates the eviction policy and returns it in lower case.
+func ParseEvictionPolicy(policy string) (string, error) {
+	policies := []string{
+		constants.NoEviction,
+		constants.AllKeysLFU, constants.AllKeysLRU, constants.AllKeysRandom,
+		constants.VolatileLFU, constants.VolatileLRU, constants.VolatileRandom,
+	}
+	if !slices.Contains(policies, strings.ToLower(policy)) {
+		return "", fmt.Errorf("policy %s is not a valid policy", policy)
+	}
+	return strings.ToLower(policy), nil
+}
+
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes", "true":
+		return true, nil
+	case "no", "false":
+		return false, nil
+	}
+	return false, errors.New("argument must be 'yes' or 'no'")
+}
+
+func formatBool(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
+
+// parseDuration parses a duration such as "100ms" or "5m". A plain integer is a number of seconds.
+func parseDuration(value string) (time.Duration, error) {
+	if seconds, err := strconv.ParseUint(value, 10, 32); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, errors.New("argument must be a duration such as 100ms or 5m, or a number of seconds")
+	}
+	return duration, nil
+}
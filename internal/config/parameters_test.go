@@ -0,0 +1,236 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func Test_GetParameters(t *testing.T) {
+	conf := DefaultConfig()
+	conf.MaxMemory = 1024
+	conf.NotifyKeyspaceEvents = "Kg$lshzxet"
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     map[string]string
+	}{
+		{
+			name:     "1. Get parameters by name",
+			patterns: []string{"max-memory", "PORT"},
+			want:     map[string]string{"max-memory": "1024", "port": "7480"},
+		},
+		{
+			name:     "2. Get parameters by glob pattern",
+			patterns: []string{"eviction-*"},
+			want: map[string]string{
+				"eviction-policy":   "noeviction",
+				"eviction-sample":   "20",
+				"eviction-interval": "100ms",
+			},
+		},
+		{
+			name:     "3. Keyspace events are normalised",
+			patterns: []string{"notify-keyspace-events"},
+			want:     map[string]string{"notify-keyspace-events": "AK"},
+		},
+		{
+			name:     "4. Unknown parameters are ignored",
+			patterns: []string{"non-existent"},
+			want:     map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetParameters(conf, tt.patterns...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_SetParameters(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  map[string]string
+		want    func(conf *Config)
+		wantErr string
+	}{
+		{
+			name: "1. Set runtime parameters",
+			values: map[string]string{
+				"max-memory":                "2mb",
+				"EVICTION-POLICY":           "AllKeys-LRU",
+				"eviction-interval":         "1s",
+				"snapshot-interval":         "30",
+				"aof-sync-strategy":         "always",
+				"require-pass":              "yes",
+				"password":                  "secret",
+				"slowlog-log-slower-than":   "-1",
+				"slowlog-max-len":           "10",
+				"latency-monitor-threshold": "100",
+			},
+			want: func(conf *Config) {
+				conf.MaxMemory = 2 * 1024 * 1024
+				conf.EvictionPolicy = "allkeys-lru"
+				conf.EvictionInterval = time.Second
+				conf.SnapshotInterval = 30 * time.Second
+				conf.AOFSyncStrategy = "always"
+				conf.RequirePass = true
+				conf.Password = "secret"
+				conf.SlowlogLogSlowerThan = -1
+				conf.SlowlogMaxLen = 10
+				conf.LatencyMonitorThreshold = 100
+			},
+		},
+		{
+			name:    "2. Return error when the parameter is unknown",
+			values:  map[string]string{"non-existent": "1"},
+			wantErr: "unknown option 'non-existent'",
+		},
+		{
+			name:    "3. Return error when the parameter can't be changed at runtime",
+			values:  map[string]string{"port": "7481", "max-memory": "10"},
+			wantErr: "can't set immutable config 'port'",
+		},
+		{
+			name:    "4. Return error when the value is invalid",
+			values:  map[string]string{"eviction-policy": "lfu"},
+			wantErr: "invalid value for 'eviction-policy': policy lfu is not a valid policy",
+		},
+		{
+			name:    "5. Return error when a password is required without a password",
+			values:  map[string]string{"require-pass": "yes"},
+			wantErr: "password cannot be empty if require-pass is yes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := DefaultConfig()
+			want := conf
+			if tt.want != nil {
+				tt.want(&want)
+			}
+			err := SetParameters(&conf, tt.values)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("expected error %q, got %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Error(err)
+			}
+			// The config is left unchanged when there's an error.
+			if !reflect.DeepEqual(conf, want) {
+				t.Errorf("expected config %+v, got %+v", want, conf)
+			}
+		})
+	}
+}
+
+func Test_Rewrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		decode   func(data []byte, conf *Config) error
+		contains []string
+	}{
+		{
+			name:    "1. Rewrite YAML config file",
+			file:    "config.yaml",
+			content: "# The port to listen on.\nPort: 7481\nMaxMemory: 1024\n",
+			decode: func(data []byte, conf *Config) error {
+				return yaml.Unmarshal(data, conf)
+			},
+			contains: []string{"# The port to listen on.\nPort: 7481\nMaxMemory: 2048\n", "EvictionInterval: 1s\n"},
+		},
+		{
+			name:    "2. Rewrite JSON config file",
+			file:    "config.json",
+			content: `{"Port": 7481, "MaxMemory": 1024}`,
+			decode: func(data []byte, conf *Config) error {
+				return json.Unmarshal(data, conf)
+			},
+			contains: []string{`"Port": 7481`, `"MaxMemory": 2048`},
+		},
+		{
+			name: "3. Create config file when it does not exist",
+			file: "new.yml",
+			decode: func(data []byte, conf *Config) error {
+				return yaml.Unmarshal(data, conf)
+			},
+			contains: []string{"MaxMemory: 2048\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			conf := DefaultConfig()
+			conf.ConfigFile = path.Join(dir, tt.file)
+			conf.MaxMemory = 2048
+			conf.EvictionPolicy = "allkeys-lfu"
+			conf.EvictionInterval = time.Second
+			conf.NotifyKeyspaceEvents = "Ex"
+
+			if tt.content != "" {
+				if err := os.WriteFile(conf.ConfigFile, []byte(tt.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := Rewrite(conf); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(conf.ConfigFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(string(data), s) {
+					t.Errorf("expected config file to contain %q, got %q", s, string(data))
+				}
+			}
+
+			var got Config
+			if err = tt.decode(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.MaxMemory != conf.MaxMemory || got.EvictionPolicy != conf.EvictionPolicy ||
+				got.EvictionInterval != conf.EvictionInterval || got.NotifyKeyspaceEvents != conf.NotifyKeyspaceEvents ||
+				got.SnapshotInterval != conf.SnapshotInterval {
+				t.Errorf("expected rewritten config to match %+v, got %+v", conf, got)
+			}
+		})
+	}
+
+	t.Run("4. Return error when there's no config file", func(t *testing.T) {
+		if err := Rewrite(DefaultConfig()); err == nil {
+			t.Error("expected error when there's no config file")
+		}
+	})
+}
@@ -0,0 +1,168 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// rewriteField is a setting written to the config file by CONFIG REWRITE.
+type rewriteField struct {
+	key   string
+	value interface{}
+}
+
+// rewriteFields returns the settings that can be changed at runtime, by their key in the config file.
+// The durations are written as strings in YAML files and as nanoseconds in JSON files, which is how they're decoded.
+func rewriteFields(config Config, ext string) []rewriteField {
+	snapshotInterval, evictionInterval := interface{}(config.SnapshotInterval), interface{}(config.EvictionInterval)
+	if ext != ".json" {
+		snapshotInterval, evictionInterval = config.SnapshotInterval.String(), config.EvictionInterval.String()
+	}
+	return []rewriteField{
+		{"RequirePass", config.RequirePass},
+		{"Password", config.Password},
+		{"SnapshotThreshold", config.SnapShotThreshold},
+		{"SnapshotInterval", snapshotInterval},
+		{"AOFSyncStrategy", config.AOFSyncStrategy},
+		{"MaxMemory", config.MaxMemory},
+		{"EvictionPolicy", config.EvictionPolicy},
+		{"EvictionSample", config.EvictionSample},
+		{"EvictionInterval", evictionInterval},
+		{"NotifyKeyspaceEvents", config.NotifyKeyspaceEvents},
+		{"SlowlogLogSlowerThan", config.SlowlogLogSlowerThan},
+		{"SlowlogMaxLen", config.SlowlogMaxLen},
+		{"LatencyMonitorThreshold", config.LatencyMonitorThreshold},
+		{"LuaTimeLimit", config.LuaTimeLimit},
+	}
+}
+
+// Rewrite persists the settings that can be changed at runtime to the config file that the config was loaded from.
+// The other settings in the file are kept. The comments and the order of the settings are kept in YAML files.
+func Rewrite(config Config) error {
+	if config.ConfigFile == "" {
+		return errors.New("the server is running without a config file")
+	}
+
+	mode := fs.FileMode(0644)
+	info, err := os.Stat(config.ConfigFile)
+	if err == nil {
+		mode = info.Mode().Perm()
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	data, err := os.ReadFile(config.ConfigFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	ext := strings.ToLower(path.Ext(config.ConfigFile))
+	switch ext {
+	case ".json":
+		data, err = rewriteJSON(data, rewriteFields(config, ext))
+	case ".yaml", ".yml":
+		data, err = rewriteYAML(data, rewriteFields(config, ext))
+	default:
+		return fmt.Errorf("config file %s is not a JSON or YAML file", config.ConfigFile)
+	}
+	if err != nil {
+		return fmt.Errorf("rewrite config: %v", err)
+	}
+
+	// Write to a temporary file first so that the config file is never left half written.
+	tmp := config.ConfigFile + ".tmp"
+	if err = os.WriteFile(tmp, data, mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp, config.ConfigFile)
+}
+
+func rewriteJSON(data []byte, fields []rewriteField) ([]byte, error) {
+	values := make(map[string]interface{})
+	if len(bytes.TrimSpace(data)) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&values); err != nil {
+			return nil, err
+		}
+	}
+	for _, field := range fields {
+		values[field.key] = field.value
+	}
+	res, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(res, '\n'), nil
+}
+
+func rewriteYAML(data []byte, fields []rewriteField) ([]byte, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if document.Kind == 0 {
+		document = yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+		}
+	}
+	mapping := document.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return nil, errors.New("the config file is not a mapping")
+	}
+
+	for _, field := range fields {
+		var value yaml.Node
+		if err := value.Encode(field.value); err != nil {
+			return nil, err
+		}
+		found := false
+		// The mapping content alternates between the key nodes and the value nodes.
+		for i := 0; i+1 < len(mapping.Content); i += 2 {
+			if mapping.Content[i].Value == field.key {
+				value.HeadComment, value.LineComment = mapping.Content[i+1].HeadComment, mapping.Content[i+1].LineComment
+				mapping.Content[i+1] = &value
+				found = true
+				break
+			}
+		}
+		if !found {
+			mapping.Content = append(mapping.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: field.key}, &value)
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -17,16 +17,21 @@ package constants
 const Version = "0.13.1" // Next SugarDB version. Update this before each release.
 
 const (
-	ACLModule        = "acl"
-	AdminModule      = "admin"
-	ConnectionModule = "connection"
-	GenericModule    = "generic"
-	HashModule       = "hash"
-	ListModule       = "list"
-	PubSubModule     = "pubsub"
-	SetModule        = "set"
-	SortedSetModule  = "sortedset"
-	StringModule     = "string"
+	ACLModule         = "acl"
+	AdminModule       = "admin"
+	BitmapModule      = "bitmap"
+	ConnectionModule  = "connection"
+	GenericModule     = "generic"
+	GeoModule         = "geo"
+	HashModule        = "hash"
+	HyperLogLogModule = "hyperloglog"
+	ListModule        = "list"
+	PubSubModule      = "pubsub"
+	ScriptingModule   = "scripting"
+	SetModule         = "set"
+	SortedSetModule   = "sortedset"
+	StreamModule      = "stream"
+	StringModule      = "string"
 )
 
 const (
@@ -68,6 +73,17 @@ const (
 	VolatileRandom = "volatile-random"
 )
 
+// The events tracked by the latency monitor.
+const (
+	CommandLatencyEvent     = "command"        // The execution of a command that is not fast.
+	FastCommandLatencyEvent = "fast-command"   // The execution of a fast command.
+	AOFFsyncLatencyEvent    = "aof-fsync"      // A sync of the AOF file to the disk.
+	SnapshotLatencyEvent    = "snapshot"       // The creation of a snapshot.
+	RaftApplyLatencyEvent   = "raft-apply"     // The application of a raft log entry.
+	EvictionLatencyEvent    = "eviction-cycle" // A cycle of key evictions when the memory usage exceeds MaxMemory.
+	ExpireLatencyEvent      = "expire-cycle"   // A cycle of sampling and deleting expired keys.
+)
+
 // CompositeTypes are SugarDB KeyData Value types like set, sorted set, etc.
 type CompositeType interface {
 	GetMem() int64
@@ -123,6 +123,17 @@ func (cache *CacheLFU) Update(key string) {
 	heap.Fix(cache, entryIdx)
 }
 
+// SetCount sets the access count of a key that is already in the cache.
+func (cache *CacheLFU) SetCount(key string, count int) {
+	entryIdx := slices.IndexFunc(cache.entries, func(e *EntryLFU) bool {
+		return e.key == key
+	})
+	if entryIdx > -1 {
+		cache.entries[entryIdx].count = count
+		heap.Fix(cache, entryIdx)
+	}
+}
+
 func (cache *CacheLFU) Delete(key string) {
 	entryIdx := slices.IndexFunc(cache.entries, func(entry *EntryLFU) bool {
 		return entry.key == key
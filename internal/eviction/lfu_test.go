@@ -64,3 +64,30 @@ func Test_CacheLFU(t *testing.T) {
 	}
 	mut.Unlock()
 }
+
+func Test_CacheLFU_SetCount(t *testing.T) {
+	cache := eviction.NewCacheLFU()
+	for _, key := range []string{"key1", "key2", "key3"} {
+		cache.Update(key)
+	}
+
+	cache.SetCount("key1", 10)
+	cache.SetCount("key3", 5)
+	// Keys that are not in the cache are ignored.
+	cache.SetCount("key4", 1)
+
+	if count, err := cache.GetCount("key1"); err != nil || count != 10 {
+		t.Errorf("expected count of key1 to be 10, got %d, %v", count, err)
+	}
+	if _, err := cache.GetCount("key4"); err == nil {
+		t.Error("expected key4 not to be added to the cache")
+	}
+
+	expectedKeys := []string{"key2", "key3", "key1"}
+	for i := 0; i < len(expectedKeys); i++ {
+		key := heap.Pop(cache).(string)
+		if key != expectedKeys[i] {
+			t.Errorf("expected popped key at index %d to be %s, got %s", i, expectedKeys[i], key)
+		}
+	}
+}
@@ -112,6 +112,17 @@ func (cache *CacheLRU) Update(key string) {
 	heap.Fix(cache, entryIdx)
 }
 
+// SetTime sets the last access time in unix milliseconds of a key that is already in the cache.
+func (cache *CacheLRU) SetTime(key string, unixTime int64) {
+	entryIdx := slices.IndexFunc(cache.entries, func(e *EntryLRU) bool {
+		return e.key == key
+	})
+	if entryIdx > -1 {
+		cache.entries[entryIdx].unixTime = unixTime
+		heap.Fix(cache, entryIdx)
+	}
+}
+
 func (cache *CacheLRU) Delete(key string) {
 	entryIdx := slices.IndexFunc(cache.entries, func(entry *EntryLRU) bool {
 		return entry.key == key
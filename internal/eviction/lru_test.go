@@ -46,3 +46,27 @@ func Test_CacheLRU(t *testing.T) {
 		}
 	}
 }
+
+func Test_CacheLRU_SetTime(t *testing.T) {
+	cache := eviction.NewCacheLRU()
+	for _, key := range []string{"key1", "key2", "key3"} {
+		cache.Update(key)
+	}
+
+	now := time.Now().UnixMilli()
+	cache.SetTime("key1", now+2000)
+	cache.SetTime("key3", now+1000)
+
+	if unixTime, err := cache.GetTime("key1"); err != nil || unixTime != now+2000 {
+		t.Errorf("expected time of key1 to be %d, got %d, %v", now+2000, unixTime, err)
+	}
+
+	// The most recently accessed keys are popped first.
+	expectedKeys := []string{"key1", "key3", "key2"}
+	for i := 0; i < len(expectedKeys); i++ {
+		key := heap.Pop(cache).(string)
+		if key != expectedKeys[i] {
+			t.Errorf("expected popped key at index %d to be %s, got %s", i, expectedKeys[i], key)
+		}
+	}
+}
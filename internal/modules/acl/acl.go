@@ -140,6 +140,33 @@ func NewACL(config config.Config) *ACL {
 	return &acl
 }
 
+// SetRequirePass changes whether a password is required, and the password of the default user.
+// The connections that are already authenticated stay authenticated.
+func (acl *ACL) SetRequirePass(requirePass bool, password string) {
+	acl.LockUsers()
+	defer acl.UnlockUsers()
+
+	acl.Config.RequirePass = requirePass
+	acl.Config.Password = password
+
+	idx := slices.IndexFunc(acl.Users, func(user *User) bool {
+		return user.Username == "default"
+	})
+	defaultUser := acl.Users[idx]
+	if !requirePass {
+		defaultUser.NoPassword = true
+		defaultUser.Passwords = []Password{}
+		return
+	}
+	defaultUser.NoPassword = false
+	defaultUser.Passwords = []Password{
+		{
+			PasswordType:  GetPasswordType(password),
+			PasswordValue: password,
+		},
+	}
+}
+
 func (acl *ACL) RegisterConnection(conn *net.Conn) {
 	acl.LockUsers()
 	defer acl.UnlockUsers()
@@ -155,6 +182,13 @@ func (acl *ACL) RegisterConnection(conn *net.Conn) {
 	}
 }
 
+// UnregisterConnection forgets the user the connection is authenticated with.
+func (acl *ACL) UnregisterConnection(conn *net.Conn) {
+	acl.LockUsers()
+	defer acl.UnlockUsers()
+	delete(acl.Connections, conn)
+}
+
 func (acl *ACL) SetUser(cmd []string) error {
 	acl.LockUsers()
 	defer acl.UnlockUsers()
@@ -456,6 +490,30 @@ func (acl *ACL) AuthorizeConnection(conn *net.Conn, cmd []string, command intern
 	return nil
 }
 
+// FilterReadKeys returns the subset of keys that the connection is allowed to read.
+// It is used by commands that list keys from the keyspace, such as KEYS and SCAN,
+// where the keys are not known ahead of the ACL authorization.
+func (acl *ACL) FilterReadKeys(conn *net.Conn, keys []string) []string {
+	acl.RLockUsers()
+	defer acl.RUnlockUsers()
+
+	// If password is not required, all keys are readable.
+	if !acl.Config.RequirePass {
+		return keys
+	}
+
+	connection, ok := acl.Connections[conn]
+	if !ok || !connection.Authenticated || connection.User.NoKeys {
+		return []string{}
+	}
+
+	return slices.DeleteFunc(slices.Clone(keys), func(key string) bool {
+		return !slices.ContainsFunc(connection.User.IncludedReadKeys, func(readKeyGlob string) bool {
+			return acl.GlobPatterns[readKeyGlob].Match(key)
+		})
+	})
+}
+
 func (acl *ACL) CompileGlobs() {
 	// Extract all the relevant globs from all the users
 	var allGlobs []string
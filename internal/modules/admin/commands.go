@@ -21,6 +21,7 @@ import (
 	"github.com/echovault/sugardb/internal/constants"
 	"github.com/gobwas/glob"
 	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -189,6 +190,237 @@ func handleCommandDocs(params internal.HandlerFuncParams) ([]byte, error) {
 	return []byte("*0\r\n"), nil
 }
 
+func handleInfo(params internal.HandlerFuncParams) ([]byte, error) {
+	sections := params.GetInfo(params.Command[1:]...)
+
+	var info strings.Builder
+	for i, section := range sections {
+		if i > 0 {
+			info.WriteString("\r\n")
+		}
+		info.WriteString(fmt.Sprintf("# %s\r\n", section.Name))
+		for _, field := range section.Fields {
+			info.WriteString(fmt.Sprintf("%s:%s\r\n", field.Name, field.Value))
+		}
+	}
+
+	return []byte(fmt.Sprintf("$%d\r\n%s\r\n", info.Len(), info.String())), nil
+}
+
+func handleConfigGet(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) < 3 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+
+	values := params.GetConfig(params.Command[2:]...)
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	res := fmt.Sprintf("*%d\r\n", len(names)*2)
+	for _, name := range names {
+		res += fmt.Sprintf("$%d\r\n%s\r\n$%d\r\n%s\r\n", len(name), name, len(values[name]), values[name])
+	}
+	return []byte(res), nil
+}
+
+func handleConfigSet(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) < 4 || len(params.Command)%2 != 0 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+
+	values := make(map[string]string)
+	for i := 2; i < len(params.Command); i += 2 {
+		name := strings.ToLower(params.Command[i])
+		if _, ok := values[name]; ok {
+			return nil, fmt.Errorf("duplicate parameter '%s'", name)
+		}
+		values[name] = params.Command[i+1]
+	}
+
+	if err := params.SetConfig(values); err != nil {
+		return nil, err
+	}
+	return []byte(constants.OkResponse), nil
+}
+
+func handleConfigResetStat(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 2 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	params.ResetStats()
+	return []byte(constants.OkResponse), nil
+}
+
+func handleConfigRewrite(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 2 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	if err := params.RewriteConfig(); err != nil {
+		return nil, err
+	}
+	return []byte(constants.OkResponse), nil
+}
+
+func handleSlowlogGet(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) > 3 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+
+	count := 10
+	if len(params.Command) == 3 {
+		c, err := strconv.Atoi(params.Command[2])
+		if err != nil || c < -1 {
+			return nil, errors.New("count should be greater than or equal to -1")
+		}
+		count = c
+	}
+
+	entries := params.GetSlowlog(count)
+	res := fmt.Sprintf("*%d\r\n", len(entries))
+	for _, entry := range entries {
+		res += fmt.Sprintf("*6\r\n:%d\r\n:%d\r\n:%d\r\n*%d\r\n", entry.Id, entry.Timestamp, entry.Duration, len(entry.Args))
+		for _, arg := range entry.Args {
+			res += fmt.Sprintf("$%d\r\n%s\r\n", len(arg), arg)
+		}
+		res += fmt.Sprintf("$%d\r\n%s\r\n$%d\r\n%s\r\n",
+			len(entry.ClientAddr), entry.ClientAddr, len(entry.ClientName), entry.ClientName)
+	}
+	return []byte(res), nil
+}
+
+func handleSlowlogLen(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 2 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	return []byte(fmt.Sprintf(":%d\r\n", params.SlowlogLen())), nil
+}
+
+func handleSlowlogReset(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 2 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	params.ResetSlowlog()
+	return []byte(constants.OkResponse), nil
+}
+
+func handleLatencyLatest(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 2 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	events := params.GetLatencyLatest()
+	res := fmt.Sprintf("*%d\r\n", len(events))
+	for _, event := range events {
+		res += fmt.Sprintf("*4\r\n$%d\r\n%s\r\n:%d\r\n:%d\r\n:%d\r\n",
+			len(event.Name), event.Name, event.Timestamp, event.Latest, event.Max)
+	}
+	return []byte(res), nil
+}
+
+func handleLatencyHistory(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 3 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	samples := params.GetLatencyHistory(params.Command[2])
+	res := fmt.Sprintf("*%d\r\n", len(samples))
+	for _, sample := range samples {
+		res += fmt.Sprintf("*2\r\n:%d\r\n:%d\r\n", sample.Timestamp, sample.Latency)
+	}
+	return []byte(res), nil
+}
+
+func handleLatencyReset(params internal.HandlerFuncParams) ([]byte, error) {
+	return []byte(fmt.Sprintf(":%d\r\n", params.ResetLatency(params.Command[2:]...))), nil
+}
+
+func handleLatencyHistogram(params internal.HandlerFuncParams) ([]byte, error) {
+	histograms := params.GetLatencyHistograms(params.Command[2:]...)
+	res := fmt.Sprintf("*%d\r\n", len(histograms)*2)
+	for _, histogram := range histograms {
+		res += fmt.Sprintf("$%d\r\n%s\r\n", len(histogram.Command), histogram.Command)
+		res += fmt.Sprintf("*4\r\n$5\r\ncalls\r\n:%d\r\n$14\r\nhistogram_usec\r\n*%d\r\n",
+			histogram.Calls, len(histogram.Buckets)*2)
+		for _, bucket := range histogram.Buckets {
+			res += fmt.Sprintf(":%d\r\n:%d\r\n", bucket.Usec, bucket.Count)
+		}
+	}
+	return []byte(res), nil
+}
+
+// latencyAdvice is the advice given by LATENCY DOCTOR for the spikes of each event.
+var latencyAdvice = map[string]string{
+	constants.CommandLatencyEvent: "Slow commands are blocking the server. Use SLOWLOG GET to find them and avoid " +
+		"commands that scan large values or the whole keyspace.",
+	constants.FastCommandLatencyEvent: "Commands that should run in constant time are slow. The server may be " +
+		"starved of CPU or paused by the garbage collector, check the load of the host.",
+	constants.AOFFsyncLatencyEvent: "The AOF fsync is slow. Check the disk, or set aof-sync-strategy to everysec " +
+		"so that the commands don't wait for the disk.",
+	constants.SnapshotLatencyEvent: "Creating snapshots is slow. Raise snapshot-threshold or snapshot-interval to " +
+		"take snapshots less often, or reduce the size of the dataset.",
+	constants.RaftApplyLatencyEvent: "Applying the raft log is slow. Check the write commands replicated to the " +
+		"cluster and the network between the nodes.",
+	constants.EvictionLatencyEvent: "The eviction cycles are slow. The dataset is above max-memory too often, " +
+		"raise max-memory or reduce the size of the values.",
+	constants.ExpireLatencyEvent: "The expiry of keys with a TTL is slow. Lower eviction-sample, or spread the " +
+		"expiry times of the keys so that fewer keys expire at the same time.",
+}
+
+func handleLatencyDoctor(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 2 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+
+	threshold := params.GetConfig("latency-monitor-threshold")["latency-monitor-threshold"]
+	var report strings.Builder
+	events := params.GetLatencyLatest()
+	switch {
+	case threshold == "0" && len(events) == 0:
+		report.WriteString("The latency monitor is disabled. Use CONFIG SET latency-monitor-threshold <milliseconds> " +
+			"to enable it.\n")
+	case len(events) == 0:
+		report.WriteString(fmt.Sprintf("No latency spikes above %sms were recorded.\n", threshold))
+	default:
+		report.WriteString(fmt.Sprintf("Latency spikes above %sms were recorded for %d events:\n\n", threshold, len(events)))
+		for i, event := range events {
+			samples := params.GetLatencyHistory(event.Name)
+			var sum int64
+			for _, sample := range samples {
+				sum += sample.Latency
+			}
+			avg := sum / int64(max(len(samples), 1))
+			var dev int64
+			for _, sample := range samples {
+				dev += max(sample.Latency-avg, avg-sample.Latency)
+			}
+			dev /= int64(max(len(samples), 1))
+			report.WriteString(fmt.Sprintf(
+				"%d. %s: %d latency spikes (average %dms, mean deviation %dms). Worst all time event %dms.\n",
+				i+1, event.Name, len(samples), avg, dev, event.Max))
+		}
+		report.WriteString("\nAdvice:\n\n")
+		for _, event := range events {
+			if advice, ok := latencyAdvice[event.Name]; ok {
+				report.WriteString(fmt.Sprintf("- %s: %s\n", event.Name, advice))
+			}
+		}
+	}
+
+	return []byte(fmt.Sprintf("$%d\r\n%s\r\n", report.Len(), report.String())), nil
+}
+
+func handleMonitor(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 1 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	// The OK reply is written to the connection before the first command, so there's nothing left to return.
+	if err := params.Monitor(params.Connection); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
 func Commands() []internal.Command {
 	return []internal.Command{
 		{
@@ -313,6 +545,261 @@ Get the list of command names. Allows for filtering by ACL category or glob patt
 				return []byte(constants.OkResponse), nil
 			},
 		},
+		{
+			Command:    "info",
+			Module:     constants.AdminModule,
+			Categories: []string{constants.SlowCategory, constants.DangerousCategory},
+			Description: `(INFO [section [section ...]])
+Returns information and statistics about the server. The sections are server, clients, memory, persistence, stats,
+replication, keyspace and commandstats. All the sections except commandstats are returned by default.
+"all" and "everything" return all the sections.`,
+			Sync: false,
+			KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+				return internal.KeyExtractionFuncResult{
+					Channels: make([]string, 0), ReadKeys: make([]string, 0), WriteKeys: make([]string, 0),
+				}, nil
+			},
+			HandlerFunc: handleInfo,
+		},
+		{
+			Command:     "config",
+			Module:      constants.AdminModule,
+			Categories:  []string{},
+			Description: "",
+			Sync:        false,
+			KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+				return internal.KeyExtractionFuncResult{
+					Channels: make([]string, 0), ReadKeys: make([]string, 0), WriteKeys: make([]string, 0),
+				}, nil
+			},
+			HandlerFunc: func(_ internal.HandlerFuncParams) ([]byte, error) {
+				return nil, errors.New("provide GET, SET, RESETSTAT or REWRITE subcommand")
+			},
+			SubCommands: []internal.SubCommand{
+				{
+					Command:    "get",
+					Module:     constants.AdminModule,
+					Categories: []string{constants.AdminCategory, constants.SlowCategory, constants.DangerousCategory},
+					Description: `(CONFIG GET parameter [parameter ...])
+Returns the configuration parameters that match any of the glob-style patterns, as a list of names and values.`,
+					Sync: false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels: make([]string, 0), ReadKeys: make([]string, 0), WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleConfigGet,
+				},
+				{
+					Command:    "set",
+					Module:     constants.AdminModule,
+					Categories: []string{constants.AdminCategory, constants.SlowCategory, constants.DangerousCategory},
+					Description: `(CONFIG SET parameter value [parameter value ...])
+Changes configuration parameters without restarting the server. The parameters that can be changed are
+max-memory, eviction-policy, eviction-sample, eviction-interval, aof-sync-strategy, snapshot-interval,
+snapshot-threshold, require-pass, password, notify-keyspace-events, slowlog-log-slower-than, slowlog-max-len,
+latency-monitor-threshold and lua-time-limit. None of the parameters is changed if one of them is invalid.`,
+					Sync: false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels: make([]string, 0), ReadKeys: make([]string, 0), WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleConfigSet,
+				},
+				{
+					Command:     "resetstat",
+					Module:      constants.AdminModule,
+					Categories:  []string{constants.AdminCategory, constants.FastCategory, constants.DangerousCategory},
+					Description: `(CONFIG RESETSTAT) Resets the statistics reported by INFO and the metrics endpoint.`,
+					Sync:        false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels: make([]string, 0), ReadKeys: make([]string, 0), WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleConfigResetStat,
+				},
+				{
+					Command:    "rewrite",
+					Module:     constants.AdminModule,
+					Categories: []string{constants.AdminCategory, constants.SlowCategory, constants.DangerousCategory},
+					Description: `(CONFIG REWRITE)
+Persists the parameters that can be changed with CONFIG SET to the JSON or YAML config file the server was
+started with.`,
+					Sync: false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels: make([]string, 0), ReadKeys: make([]string, 0), WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleConfigRewrite,
+				},
+			},
+		},
+		{
+			Command:     "slowlog",
+			Module:      constants.AdminModule,
+			Categories:  []string{},
+			Description: "",
+			Sync:        false,
+			KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+				return internal.KeyExtractionFuncResult{
+					Channels: make([]string, 0), ReadKeys: make([]string, 0), WriteKeys: make([]string, 0),
+				}, nil
+			},
+			HandlerFunc: func(_ internal.HandlerFuncParams) ([]byte, error) {
+				return nil, errors.New("provide GET, LEN or RESET subcommand")
+			},
+			SubCommands: []internal.SubCommand{
+				{
+					Command:    "get",
+					Module:     constants.AdminModule,
+					Categories: []string{constants.AdminCategory, constants.SlowCategory, constants.DangerousCategory},
+					Description: `(SLOWLOG GET [count])
+Returns the count most recent entries of the slow log, newest first. 10 entries are returned by default, and all the
+entries are returned when count is -1. Each entry holds the id, the unix timestamp, the execution time in
+microseconds, the arguments, the client address and the client name.`,
+					Sync: false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels: make([]string, 0), ReadKeys: make([]string, 0), WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleSlowlogGet,
+				},
+				{
+					Command:     "len",
+					Module:      constants.AdminModule,
+					Categories:  []string{constants.AdminCategory, constants.FastCategory, constants.DangerousCategory},
+					Description: `(SLOWLOG LEN) Returns the number of entries in the slow log.`,
+					Sync:        false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels: make([]string, 0), ReadKeys: make([]string, 0), WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleSlowlogLen,
+				},
+				{
+					Command:     "reset",
+					Module:      constants.AdminModule,
+					Categories:  []string{constants.AdminCategory, constants.SlowCategory, constants.DangerousCategory},
+					Description: `(SLOWLOG RESET) Removes all the entries from the slow log.`,
+					Sync:        false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels: make([]string, 0), ReadKeys: make([]string, 0), WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleSlowlogReset,
+				},
+			},
+		},
+		{
+			Command:     "latency",
+			Module:      constants.AdminModule,
+			Categories:  []string{},
+			Description: "",
+			Sync:        false,
+			KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+				return internal.KeyExtractionFuncResult{
+					Channels: make([]string, 0), ReadKeys: make([]string, 0), WriteKeys: make([]string, 0),
+				}, nil
+			},
+			HandlerFunc: func(_ internal.HandlerFuncParams) ([]byte, error) {
+				return nil, errors.New("provide LATEST, HISTORY, RESET, HISTOGRAM or DOCTOR subcommand")
+			},
+			SubCommands: []internal.SubCommand{
+				{
+					Command:    "latest",
+					Module:     constants.AdminModule,
+					Categories: []string{constants.AdminCategory, constants.FastCategory, constants.DangerousCategory},
+					Description: `(LATENCY LATEST) Returns the latest latency spike of each event as an array of the event name,
+the unix time of the spike, its latency and the highest latency of the event in milliseconds. The events are command,
+fast-command, aof-fsync, snapshot, raft-apply, eviction-cycle and expire-cycle.`,
+					Sync: false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels: make([]string, 0), ReadKeys: make([]string, 0), WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleLatencyLatest,
+				},
+				{
+					Command:    "history",
+					Module:     constants.AdminModule,
+					Categories: []string{constants.AdminCategory, constants.FastCategory, constants.DangerousCategory},
+					Description: `(LATENCY HISTORY event) Returns the latency spikes of the event as an array of the unix time
+and the latency in milliseconds, oldest first. Up to 160 spikes are kept for each event.`,
+					Sync: false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels: make([]string, 0), ReadKeys: make([]string, 0), WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleLatencyHistory,
+				},
+				{
+					Command:    "reset",
+					Module:     constants.AdminModule,
+					Categories: []string{constants.AdminCategory, constants.FastCategory, constants.DangerousCategory},
+					Description: `(LATENCY RESET [event [event ...]]) Removes the latency spikes of the events, or of all the events when
+none is given. Returns the number of events that were reset.`,
+					Sync: false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels: make([]string, 0), ReadKeys: make([]string, 0), WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleLatencyReset,
+				},
+				{
+					Command:    "histogram",
+					Module:     constants.AdminModule,
+					Categories: []string{constants.AdminCategory, constants.SlowCategory, constants.DangerousCategory},
+					Description: `(LATENCY HISTOGRAM [command [command ...]]) Returns the latency histogram of the commands,
+or of all the executed commands when none is given. Each histogram holds the number of calls and the cumulative number of
+calls that took at most each power of 2 microseconds. The histograms are reset by CONFIG RESETSTAT.`,
+					Sync: false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels: make([]string, 0), ReadKeys: make([]string, 0), WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleLatencyHistogram,
+				},
+				{
+					Command:    "doctor",
+					Module:     constants.AdminModule,
+					Categories: []string{constants.AdminCategory, constants.SlowCategory, constants.DangerousCategory},
+					Description: `(LATENCY DOCTOR) Returns a report of the latency spikes of each event with advice
+on how to reduce them.`,
+					Sync: false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels: make([]string, 0), ReadKeys: make([]string, 0), WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleLatencyDoctor,
+				},
+			},
+		},
+		{
+			Command:    "monitor",
+			Module:     constants.AdminModule,
+			Categories: []string{constants.AdminCategory, constants.SlowCategory, constants.DangerousCategory},
+			Description: `(MONITOR) Stream every command processed by the server to the connection. Each line holds the unix time,
+the database, the client address and the arguments of the command. The commands replayed from the AOF, applied from
+the raft log and called by scripts are tagged aof, raft and lua in place of the client address.`,
+			Sync: false,
+			KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+				return internal.KeyExtractionFuncResult{
+					Channels: make([]string, 0), ReadKeys: make([]string, 0), WriteKeys: make([]string, 0),
+				}, nil
+			},
+			HandlerFunc: handleMonitor,
+		},
 		{
 			Command:     "module",
 			Module:      constants.AdminModule,
@@ -22,18 +22,24 @@ import (
 	"github.com/echovault/sugardb/internal/constants"
 	"github.com/echovault/sugardb/internal/modules/acl"
 	"github.com/echovault/sugardb/internal/modules/admin"
+	"github.com/echovault/sugardb/internal/modules/bitmap"
 	"github.com/echovault/sugardb/internal/modules/connection"
 	"github.com/echovault/sugardb/internal/modules/generic"
+	"github.com/echovault/sugardb/internal/modules/geo"
 	"github.com/echovault/sugardb/internal/modules/hash"
+	"github.com/echovault/sugardb/internal/modules/hyperloglog"
 	"github.com/echovault/sugardb/internal/modules/list"
 	"github.com/echovault/sugardb/internal/modules/pubsub"
+	"github.com/echovault/sugardb/internal/modules/scripting"
 	"github.com/echovault/sugardb/internal/modules/set"
 	"github.com/echovault/sugardb/internal/modules/sorted_set"
+	"github.com/echovault/sugardb/internal/modules/stream"
 	str "github.com/echovault/sugardb/internal/modules/string"
 	"github.com/echovault/sugardb/sugardb"
 	"github.com/tidwall/resp"
 	"os"
 	"path"
+	"regexp"
 	"slices"
 	"strings"
 	"testing"
@@ -96,13 +102,18 @@ func Test_AdminCommands(t *testing.T) {
 		var commands []internal.Command
 		commands = append(commands, acl.Commands()...)
 		commands = append(commands, admin.Commands()...)
+		commands = append(commands, bitmap.Commands()...)
 		commands = append(commands, generic.Commands()...)
+		commands = append(commands, geo.Commands()...)
 		commands = append(commands, hash.Commands()...)
+		commands = append(commands, hyperloglog.Commands()...)
 		commands = append(commands, list.Commands()...)
 		commands = append(commands, connection.Commands()...)
 		commands = append(commands, pubsub.Commands()...)
+		commands = append(commands, scripting.Commands()...)
 		commands = append(commands, set.Commands()...)
 		commands = append(commands, sorted_set.Commands()...)
+		commands = append(commands, stream.Commands()...)
 		commands = append(commands, str.Commands()...)
 
 		// Flatten the commands and subcommands.
@@ -148,13 +159,18 @@ func Test_AdminCommands(t *testing.T) {
 		var commands []internal.Command
 		commands = append(commands, acl.Commands()...)
 		commands = append(commands, admin.Commands()...)
+		commands = append(commands, bitmap.Commands()...)
 		commands = append(commands, generic.Commands()...)
+		commands = append(commands, geo.Commands()...)
 		commands = append(commands, hash.Commands()...)
+		commands = append(commands, hyperloglog.Commands()...)
 		commands = append(commands, list.Commands()...)
 		commands = append(commands, connection.Commands()...)
 		commands = append(commands, pubsub.Commands()...)
+		commands = append(commands, scripting.Commands()...)
 		commands = append(commands, set.Commands()...)
 		commands = append(commands, sorted_set.Commands()...)
+		commands = append(commands, stream.Commands()...)
 		commands = append(commands, str.Commands()...)
 
 		// Flatten the commands and subcommands.
@@ -189,13 +205,18 @@ func Test_AdminCommands(t *testing.T) {
 		var allCommands []internal.Command
 		allCommands = append(allCommands, acl.Commands()...)
 		allCommands = append(allCommands, admin.Commands()...)
+		allCommands = append(allCommands, bitmap.Commands()...)
 		allCommands = append(allCommands, generic.Commands()...)
+		allCommands = append(allCommands, geo.Commands()...)
 		allCommands = append(allCommands, hash.Commands()...)
+		allCommands = append(allCommands, hyperloglog.Commands()...)
 		allCommands = append(allCommands, list.Commands()...)
 		allCommands = append(allCommands, connection.Commands()...)
 		allCommands = append(allCommands, pubsub.Commands()...)
+		allCommands = append(allCommands, scripting.Commands()...)
 		allCommands = append(allCommands, set.Commands()...)
 		allCommands = append(allCommands, sorted_set.Commands()...)
+		allCommands = append(allCommands, stream.Commands()...)
 		allCommands = append(allCommands, str.Commands()...)
 
 		tests := []struct {
@@ -246,7 +267,9 @@ func Test_AdminCommands(t *testing.T) {
 				want: func() []string {
 					var commands []string
 					for _, command := range sorted_set.Commands() {
-						commands = append(commands, command.Command)
+						if strings.HasPrefix(command.Command, "z") {
+							commands = append(commands, command.Command)
+						}
 					}
 					return commands
 				}(),
@@ -827,6 +850,866 @@ func Test_AdminCommands(t *testing.T) {
 		}
 	})
 
+	t.Run("Test INFO command", func(t *testing.T) {
+		t.Parallel()
+
+		port, err := internal.GetFreePort()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		mockServer, err := setupServer(uint16(port))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		go func() {
+			mockServer.Start()
+		}()
+		t.Cleanup(func() {
+			mockServer.ShutDown()
+		})
+
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		client := resp.NewConn(conn)
+
+		for _, command := range [][]string{
+			{"SET", "InfoKey1", "value1"},
+			{"SET", "InfoKey2", "value2", "PX", "100000"},
+			{"GET", "InfoKey1"},
+			{"GET", "InfoKey3"},
+			{"SET", "InfoKey4"},
+		} {
+			values := make([]resp.Value, len(command))
+			for i, token := range command {
+				values[i] = resp.StringValue(token)
+			}
+			if err = client.WriteArray(values); err != nil {
+				t.Error(err)
+				return
+			}
+			if _, _, err = client.ReadValue(); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+
+		tests := []struct {
+			name         string
+			command      []string
+			wantSections []string
+			wantFields   []string
+		}{
+			{
+				name:         "1. Return the default sections",
+				command:      []string{"INFO"},
+				wantSections: []string{"Server", "Clients", "Memory", "Persistence", "Stats", "Replication", "Keyspace"},
+				wantFields: []string{
+					"sugardb_mode:standalone", "connected_clients:1", "maxmemory_policy:noeviction", "aof_enabled:1",
+					"keyspace_hits:1", "keyspace_misses:1", "role:master", "db0:keys=2,expires=1,avg_ttl=100000",
+				},
+			},
+			{
+				name:         "2. Return the requested sections",
+				command:      []string{"INFO", "memory", "KEYSPACE", "unknown"},
+				wantSections: []string{"Memory", "Keyspace"},
+				wantFields:   []string{"maxmemory:0", "db0:keys=2,expires=1,avg_ttl=100000"},
+			},
+			{
+				name:         "3. Return the command stats",
+				command:      []string{"INFO", "commandstats"},
+				wantSections: []string{"Commandstats"},
+				wantFields: []string{
+					"cmdstat_get:calls=2,", "cmdstat_info:calls=2,", "cmdstat_set:calls=2,",
+					// SET without a value is rejected before it's executed.
+					",rejected_calls=1,failed_calls=0\r\n",
+				},
+			},
+			{
+				name:    "4. Return all the sections",
+				command: []string{"INFO", "all"},
+				wantSections: []string{
+					"Server", "Clients", "Memory", "Persistence", "Stats", "Replication", "Keyspace", "Commandstats",
+				},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				command := make([]resp.Value, len(test.command))
+				for i, token := range test.command {
+					command[i] = resp.StringValue(token)
+				}
+				if err = client.WriteArray(command); err != nil {
+					t.Error(err)
+					return
+				}
+				res, _, err := client.ReadValue()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				sections := make([]string, 0)
+				for _, line := range strings.Split(res.String(), "\r\n") {
+					if strings.HasPrefix(line, "# ") {
+						sections = append(sections, strings.TrimPrefix(line, "# "))
+					}
+				}
+				if !slices.Equal(sections, test.wantSections) {
+					t.Errorf("expected sections %v, got %v", test.wantSections, sections)
+				}
+				for _, field := range test.wantFields {
+					if !strings.Contains(res.String(), field) {
+						t.Errorf("expected INFO to contain %q, got %q", field, res.String())
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("Test CONFIG command", func(t *testing.T) {
+		t.Parallel()
+
+		port, err := internal.GetFreePort()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		configFile := path.Join(t.TempDir(), "config.yaml")
+		if err = os.WriteFile(configFile, []byte("# Test config.\nMaxMemory: 0\n"), 0644); err != nil {
+			t.Error(err)
+			return
+		}
+		cfg := sugardb.DefaultConfig()
+		cfg.DataDir = ""
+		cfg.BindAddr = "localhost"
+		cfg.Port = uint16(port)
+		cfg.ConfigFile = configFile
+		mockServer, err := sugardb.NewSugarDB(sugardb.WithConfig(cfg))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		go func() {
+			mockServer.Start()
+		}()
+		t.Cleanup(func() {
+			mockServer.ShutDown()
+		})
+
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		client := resp.NewConn(conn)
+
+		tests := []struct {
+			name      string
+			command   []string
+			wantArray []string // The expected array response.
+			want      string   // The expected string response, or a substring of the expected bulk string.
+			wantErr   string
+		}{
+			{
+				name:      "1. Get parameters by glob pattern",
+				command:   []string{"CONFIG", "GET", "max-memory", "eviction-p*"},
+				wantArray: []string{"eviction-policy", "noeviction", "max-memory", "0"},
+			},
+			{
+				name:    "2. Set parameters",
+				command: []string{"CONFIG", "SET", "max-memory", "1mb", "notify-keyspace-events", "KEA"},
+				want:    "OK",
+			},
+			{
+				name:      "3. Get the parameters that were set",
+				command:   []string{"CONFIG", "GET", "max-memory", "notify-keyspace-events"},
+				wantArray: []string{"max-memory", "1048576", "notify-keyspace-events", "AKE"},
+			},
+			{
+				name:    "4. The new max memory takes effect",
+				command: []string{"INFO", "memory"},
+				want:    "maxmemory:1048576\r\n",
+			},
+			{
+				name:    "5. Return error when the parameter can't be changed at runtime",
+				command: []string{"CONFIG", "SET", "port", "7481"},
+				wantErr: "Error can't set immutable config 'port'",
+			},
+			{
+				name:    "6. Return error when the value is invalid",
+				command: []string{"CONFIG", "SET", "eviction-sample", "0"},
+				wantErr: "Error invalid value for 'eviction-sample': eviction-sample must be an integer greater than 0",
+			},
+			{
+				name:    "7. Return error when a parameter is repeated",
+				command: []string{"CONFIG", "SET", "max-memory", "1", "MAX-MEMORY", "2"},
+				wantErr: "Error duplicate parameter 'max-memory'",
+			},
+			{
+				name:    "8. Reset the stats",
+				command: []string{"CONFIG", "RESETSTAT"},
+				want:    "OK",
+			},
+			{
+				name:    "9. The command stats are reset",
+				command: []string{"INFO", "commandstats"},
+				// The stats of the commands called before CONFIG RESETSTAT are cleared.
+				want: "# Commandstats\r\ncmdstat_config|resetstat:calls=1,",
+			},
+			{
+				name:    "10. Rewrite the config file",
+				command: []string{"CONFIG", "REWRITE"},
+				want:    "OK",
+			},
+			{
+				name:    "11. Return error when the subcommand is missing",
+				command: []string{"CONFIG"},
+				wantErr: "Error provide GET, SET, RESETSTAT or REWRITE subcommand",
+			},
+			{
+				name:    "12. Require a password",
+				command: []string{"CONFIG", "SET", "require-pass", "yes", "password", "password1"},
+				want:    "OK",
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				command := make([]resp.Value, len(test.command))
+				for i, token := range test.command {
+					command[i] = resp.StringValue(token)
+				}
+				if err = client.WriteArray(command); err != nil {
+					t.Error(err)
+					return
+				}
+				res, _, err := client.ReadValue()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				switch {
+				case test.wantErr != "":
+					if res.Error() == nil || res.Error().Error() != test.wantErr {
+						t.Errorf("expected error %q, got %q", test.wantErr, res.String())
+					}
+				case test.wantArray != nil:
+					got := make([]string, len(res.Array()))
+					for i, value := range res.Array() {
+						got[i] = value.String()
+					}
+					if !slices.Equal(got, test.wantArray) {
+						t.Errorf("expected response %v, got %v", test.wantArray, got)
+					}
+				default:
+					if !strings.Contains(res.String(), test.want) {
+						t.Errorf("expected response to contain %q, got %q", test.want, res.String())
+					}
+				}
+			})
+		}
+
+		// The config file keeps its comments and gets the new values.
+		data, err := os.ReadFile(configFile)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for _, want := range []string{"# Test config.\nMaxMemory: 1048576\n", "NotifyKeyspaceEvents: KEA\n"} {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("expected config file to contain %q, got %q", want, string(data))
+			}
+		}
+
+		// New connections must authenticate once a password is required.
+		newConn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = newConn.Close()
+		}()
+		newClient := resp.NewConn(newConn)
+		for _, test := range []struct {
+			command []string
+			want    string
+		}{
+			{command: []string{"GET", "key"}, want: "Error user must be authenticated"},
+			{command: []string{"AUTH", "password1"}, want: "OK"},
+		} {
+			command := make([]resp.Value, len(test.command))
+			for i, token := range test.command {
+				command[i] = resp.StringValue(token)
+			}
+			if err = newClient.WriteArray(command); err != nil {
+				t.Error(err)
+				return
+			}
+			res, _, err := newClient.ReadValue()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if res.String() != test.want {
+				t.Errorf("expected response %q, got %q", test.want, res.String())
+			}
+		}
+	})
+
+	t.Run("Test SLOWLOG command", func(t *testing.T) {
+		t.Parallel()
+
+		port, err := internal.GetFreePort()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		cfg := sugardb.DefaultConfig()
+		cfg.DataDir = ""
+		cfg.BindAddr = "localhost"
+		cfg.Port = uint16(port)
+		cfg.SlowlogLogSlowerThan = 0 // Record every command.
+		mockServer, err := sugardb.NewSugarDB(sugardb.WithConfig(cfg))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		go func() {
+			mockServer.Start()
+		}()
+		t.Cleanup(func() {
+			mockServer.ShutDown()
+		})
+
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		client := resp.NewConn(conn)
+		addr := conn.LocalAddr().String()
+
+		members := make([]string, 40)
+		for i := range members {
+			members[i] = fmt.Sprintf("member%d", i)
+		}
+
+		// entry returns the id, arguments, client address and client name of a slow log entry.
+		entry := func(value resp.Value) (int, []string, string, string) {
+			fields := value.Array()
+			args := make([]string, len(fields[3].Array()))
+			for i, arg := range fields[3].Array() {
+				args[i] = arg.String()
+			}
+			return fields[0].Integer(), args, fields[4].String(), fields[5].String()
+		}
+
+		tests := []struct {
+			name    string
+			command []string
+			check   func(res resp.Value) error
+		}{
+			{
+				name:    "1. Reset the slow log",
+				command: []string{"SLOWLOG", "RESET"},
+				check: func(res resp.Value) error {
+					if res.String() != "OK" {
+						return fmt.Errorf("expected OK, got %q", res.String())
+					}
+					return nil
+				},
+			},
+			{
+				name:    "2. Record a command with a long argument",
+				command: []string{"SET", "SlowlogKey", strings.Repeat("a", 200)},
+			},
+			{
+				name:    "3. Long arguments are truncated",
+				command: []string{"SLOWLOG", "GET", "1"},
+				check: func(res resp.Value) error {
+					if len(res.Array()) != 1 {
+						return fmt.Errorf("expected 1 entry, got %d", len(res.Array()))
+					}
+					id, args, clientAddr, clientName := entry(res.Array()[0])
+					want := []string{"SET", "SlowlogKey", strings.Repeat("a", 128) + "... (72 more bytes)"}
+					if id != 1 || !slices.Equal(args, want) || clientAddr != addr || clientName != "" {
+						return fmt.Errorf("unexpected entry %d %v %s %s", id, args, clientAddr, clientName)
+					}
+					return nil
+				},
+			},
+			{
+				name:    "4. Record a command with many arguments",
+				command: append([]string{"SADD", "SlowlogSet"}, members...),
+			},
+			{
+				name:    "5. Set the client name",
+				command: []string{"CLIENT", "SETNAME", "slow-client"},
+			},
+			{
+				name:    "6. Get the latest entries",
+				command: []string{"SLOWLOG", "GET", "2"},
+				check: func(res resp.Value) error {
+					if len(res.Array()) != 2 {
+						return fmt.Errorf("expected 2 entries, got %d", len(res.Array()))
+					}
+					id, args, _, clientName := entry(res.Array()[0])
+					if id != 4 || !slices.Equal(args, []string{"CLIENT", "SETNAME", "slow-client"}) || clientName != "slow-client" {
+						return fmt.Errorf("unexpected entry %d %v %s", id, args, clientName)
+					}
+					id, args, _, _ = entry(res.Array()[1])
+					if id != 3 || len(args) != 32 || args[30] != "member28" || args[31] != "... (11 more arguments)" {
+						return fmt.Errorf("unexpected entry %d %v", id, args)
+					}
+					return nil
+				},
+			},
+			{
+				name:    "7. Get the length of the slow log",
+				command: []string{"SLOWLOG", "LEN"},
+				check: func(res resp.Value) error {
+					if res.Integer() != 6 {
+						return fmt.Errorf("expected length 6, got %d", res.Integer())
+					}
+					return nil
+				},
+			},
+			{
+				name:    "8. Get all the entries",
+				command: []string{"SLOWLOG", "GET", "-1"},
+				check: func(res resp.Value) error {
+					if len(res.Array()) != 7 {
+						return fmt.Errorf("expected 7 entries, got %d", len(res.Array()))
+					}
+					return nil
+				},
+			},
+			{
+				name:    "9. Return error when the count is invalid",
+				command: []string{"SLOWLOG", "GET", "-2"},
+				check: func(res resp.Value) error {
+					if res.Error() == nil || res.Error().Error() != "Error count should be greater than or equal to -1" {
+						return fmt.Errorf("expected count error, got %q", res.String())
+					}
+					return nil
+				},
+			},
+			{
+				name:    "10. Record a command with a password",
+				command: []string{"CONFIG", "SET", "password", "slowlog_password"},
+			},
+			{
+				name:    "11. Passwords are redacted",
+				command: []string{"SLOWLOG", "GET", "1"},
+				check: func(res resp.Value) error {
+					_, args, _, _ := entry(res.Array()[0])
+					if !slices.Equal(args, []string{"CONFIG", "SET", "password", "(redacted)"}) {
+						return fmt.Errorf("unexpected entry %v", args)
+					}
+					return nil
+				},
+			},
+			{
+				name:    "12. Disable the slow log",
+				command: []string{"CONFIG", "SET", "slowlog-log-slower-than", "-1"},
+			},
+			{
+				name:    "13. Reset the slow log",
+				command: []string{"SLOWLOG", "RESET"},
+			},
+			{
+				name:    "14. Commands are not recorded when the slow log is disabled",
+				command: []string{"SLOWLOG", "LEN"},
+				check: func(res resp.Value) error {
+					if res.Integer() != 0 {
+						return fmt.Errorf("expected length 0, got %d", res.Integer())
+					}
+					return nil
+				},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				command := make([]resp.Value, len(test.command))
+				for i, token := range test.command {
+					command[i] = resp.StringValue(token)
+				}
+				if err = client.WriteArray(command); err != nil {
+					t.Error(err)
+					return
+				}
+				res, _, err := client.ReadValue()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if test.check != nil {
+					if err = test.check(res); err != nil {
+						t.Error(err)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("Test LATENCY command", func(t *testing.T) {
+		t.Parallel()
+
+		port, err := internal.GetFreePort()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		cfg := sugardb.DefaultConfig()
+		cfg.DataDir = ""
+		cfg.BindAddr = "localhost"
+		cfg.Port = uint16(port)
+		cfg.LatencyMonitorThreshold = 1
+		mockServer, err := sugardb.NewSugarDB(sugardb.WithConfig(cfg))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		go func() {
+			mockServer.Start()
+		}()
+		t.Cleanup(func() {
+			mockServer.ShutDown()
+		})
+
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		client := resp.NewConn(conn)
+
+		// latestEvent returns the fields of the event in the reply of LATENCY LATEST.
+		latestEvent := func(res resp.Value, name string) []resp.Value {
+			for _, event := range res.Array() {
+				if event.Array()[0].String() == name {
+					return event.Array()
+				}
+			}
+			return nil
+		}
+
+		tests := []struct {
+			name    string
+			command []string
+			check   func(res resp.Value) error
+		}{
+			{
+				name:    "1. Return error when no subcommand is provided",
+				command: []string{"LATENCY"},
+				check: func(res resp.Value) error {
+					want := "Error provide LATEST, HISTORY, RESET, HISTOGRAM or DOCTOR subcommand"
+					if res.Error() == nil || res.Error().Error() != want {
+						return fmt.Errorf("expected error %q, got %q", want, res.String())
+					}
+					return nil
+				},
+			},
+			{
+				name:    "2. Run a slow script",
+				command: []string{"EVAL", "local i = 0 while i < 2000000 do i = i + 1 end return i", "0"},
+			},
+			{
+				name:    "3. Get the latest spike of each event",
+				command: []string{"LATENCY", "LATEST"},
+				check: func(res resp.Value) error {
+					event := latestEvent(res, "command")
+					if event == nil {
+						return fmt.Errorf("expected a command event, got %q", res.String())
+					}
+					if event[2].Integer() < 1 || event[3].Integer() < event[2].Integer() {
+						return fmt.Errorf("unexpected command event %v", event)
+					}
+					return nil
+				},
+			},
+			{
+				name:    "4. Get the spikes of the command event",
+				command: []string{"LATENCY", "HISTORY", "COMMAND"},
+				check: func(res resp.Value) error {
+					if len(res.Array()) == 0 || res.Array()[0].Array()[1].Integer() < 1 {
+						return fmt.Errorf("expected the spikes of the command event, got %q", res.String())
+					}
+					return nil
+				},
+			},
+			{
+				name:    "5. Get the spikes of an event without spikes",
+				command: []string{"LATENCY", "HISTORY", "non-existent"},
+				check: func(res resp.Value) error {
+					if len(res.Array()) != 0 {
+						return fmt.Errorf("expected no spikes, got %q", res.String())
+					}
+					return nil
+				},
+			},
+			{
+				name:    "6. Get the latency histogram of a command",
+				command: []string{"LATENCY", "HISTOGRAM", "eval", "non-existent"},
+				check: func(res resp.Value) error {
+					if len(res.Array()) != 2 || res.Array()[0].String() != "eval" {
+						return fmt.Errorf("expected the histogram of eval, got %q", res.String())
+					}
+					fields := res.Array()[1].Array()
+					if fields[0].String() != "calls" || fields[1].Integer() != 1 || fields[2].String() != "histogram_usec" {
+						return fmt.Errorf("unexpected histogram %q", res.Array()[1].String())
+					}
+					// The single call lands in the slowest bucket, which took at least 1ms.
+					buckets := fields[3].Array()
+					if len(buckets) != 2 || buckets[0].Integer() < 1024 || buckets[1].Integer() != 1 {
+						return fmt.Errorf("unexpected histogram buckets %q", fields[3].String())
+					}
+					return nil
+				},
+			},
+			{
+				name:    "7. Get the latency doctor report",
+				command: []string{"LATENCY", "DOCTOR"},
+				check: func(res resp.Value) error {
+					for _, s := range []string{"Latency spikes above 1ms", "command: ", "SLOWLOG GET"} {
+						if !strings.Contains(res.String(), s) {
+							return fmt.Errorf("expected the report to contain %q, got %q", s, res.String())
+						}
+					}
+					return nil
+				},
+			},
+			{
+				name:    "8. Reset the command event",
+				command: []string{"LATENCY", "RESET", "command", "non-existent"},
+				check: func(res resp.Value) error {
+					if res.Integer() != 1 {
+						return fmt.Errorf("expected 1 event to be reset, got %d", res.Integer())
+					}
+					return nil
+				},
+			},
+			{
+				name:    "9. The reset event is removed",
+				command: []string{"LATENCY", "LATEST"},
+				check: func(res resp.Value) error {
+					if latestEvent(res, "command") != nil {
+						return fmt.Errorf("expected no command event, got %q", res.String())
+					}
+					return nil
+				},
+			},
+			{
+				name:    "10. Disable the latency monitor",
+				command: []string{"CONFIG", "SET", "latency-monitor-threshold", "0"},
+			},
+			{
+				name:    "11. Reset all the events",
+				command: []string{"LATENCY", "RESET"},
+			},
+			{
+				name:    "12. The doctor reports that the latency monitor is disabled",
+				command: []string{"LATENCY", "DOCTOR"},
+				check: func(res resp.Value) error {
+					if !strings.HasPrefix(res.String(), "The latency monitor is disabled") {
+						return fmt.Errorf("expected the latency monitor to be disabled, got %q", res.String())
+					}
+					return nil
+				},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				command := make([]resp.Value, len(test.command))
+				for i, token := range test.command {
+					command[i] = resp.StringValue(token)
+				}
+				if err = client.WriteArray(command); err != nil {
+					t.Error(err)
+					return
+				}
+				res, _, err := client.ReadValue()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if test.check != nil {
+					if err = test.check(res); err != nil {
+						t.Error(err)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("Test MONITOR command", func(t *testing.T) {
+		t.Parallel()
+
+		port, err := internal.GetFreePort()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		mockServer, err := setupServer(uint16(port))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		go func() {
+			mockServer.Start()
+		}()
+		t.Cleanup(func() {
+			mockServer.ShutDown()
+		})
+
+		monitorConn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = monitorConn.Close()
+		}()
+		monitorClient := resp.NewConn(monitorConn)
+
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		client := resp.NewConn(conn)
+		addr := conn.LocalAddr().String()
+
+		if err = monitorClient.WriteArray([]resp.Value{resp.StringValue("MONITOR")}); err != nil {
+			t.Error(err)
+			return
+		}
+		res, _, err := monitorClient.ReadValue()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if res.String() != "OK" {
+			t.Errorf("expected OK response, got %q", res.String())
+			return
+		}
+
+		tests := []struct {
+			name    string
+			command []string
+			want    string // The arguments in the line streamed to the monitor.
+		}{
+			{
+				name:    "1. Stream a write command",
+				command: []string{"SET", "MonitorKey", "value"},
+				want:    `"SET" "MonitorKey" "value"`,
+			},
+			{
+				name:    "2. Quote the special characters of the arguments",
+				command: []string{"SET", "MonitorKey", "a \"quoted\"\nvalue"},
+				want:    `"SET" "MonitorKey" "a \"quoted\"\nvalue"`,
+			},
+			{
+				name:    "3. Stream a command that returns an error",
+				command: []string{"INCR", "MonitorKey"},
+				want:    `"INCR" "MonitorKey"`,
+			},
+			{
+				name:    "4. Redact the password of AUTH",
+				command: []string{"AUTH", "user", "password"},
+				want:    `"AUTH" "(redacted)" "(redacted)"`,
+			},
+			{
+				name:    "5. Redact the password rules of ACL SETUSER",
+				command: []string{"ACL", "SETUSER", "MonitorUser", "on", ">password", "<old_password", "~*"},
+				want:    `"ACL" "SETUSER" "MonitorUser" "on" "(redacted)" "(redacted)" "~*"`,
+			},
+			{
+				name:    "6. Redact the password set with CONFIG SET",
+				command: []string{"CONFIG", "SET", "password", "password"},
+				want:    `"CONFIG" "SET" "password" "(redacted)"`,
+			},
+			{
+				name:    "7. Redact the password of MIGRATE",
+				command: []string{"MIGRATE", "localhost", "1", "", "0", "100", "AUTH2", "user", "password", "KEYS", "auth"},
+				want:    `"MIGRATE" "localhost" "1" "" "0" "100" "AUTH2" "(redacted)" "(redacted)" "KEYS" "auth"`,
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				command := make([]resp.Value, len(test.command))
+				for i, token := range test.command {
+					command[i] = resp.StringValue(token)
+				}
+				if err = client.WriteArray(command); err != nil {
+					t.Error(err)
+					return
+				}
+				if _, _, err = client.ReadValue(); err != nil {
+					t.Error(err)
+					return
+				}
+
+				if err = monitorConn.SetReadDeadline(time.Now().Add(2 * time.Second)); err != nil {
+					t.Error(err)
+					return
+				}
+				line, _, err := monitorClient.ReadValue()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				// The line starts with the unix time in seconds with microseconds, followed by the database
+				// and the client address.
+				pattern := fmt.Sprintf(`^\d+\.\d{6} \[0 %s\] %s$`, regexp.QuoteMeta(addr), regexp.QuoteMeta(test.want))
+				if !regexp.MustCompile(pattern).MatchString(line.String()) {
+					t.Errorf("expected line matching %q, got %q", pattern, line.String())
+				}
+			})
+		}
+
+		// The connection in MONITOR mode has the O flag in CLIENT LIST.
+		if err = client.WriteArray([]resp.Value{resp.StringValue("CLIENT"), resp.StringValue("LIST")}); err != nil {
+			t.Error(err)
+			return
+		}
+		res, _, err = client.ReadValue()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		monitorAddr := monitorConn.LocalAddr().String()
+		if !regexp.MustCompile(fmt.Sprintf(`addr=%s .*flags=O `, regexp.QuoteMeta(monitorAddr))).MatchString(res.String()) {
+			t.Errorf("expected the monitor connection to have the O flag, got %q", res.String())
+		}
+	})
+
 	t.Run("Test REWRITEAOF command", func(t *testing.T) {
 		t.Parallel()
 
@@ -0,0 +1,350 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmap
+
+import (
+	"encoding/json"
+	"math"
+	"math/bits"
+	"slices"
+	"sync"
+	"unsafe"
+
+	"github.com/echovault/sugardb/internal"
+)
+
+func init() {
+	internal.RegisterCompositeType("bitmap", func(data []byte) (interface{}, error) {
+		bitmap := NewBitmap(nil)
+		if err := json.Unmarshal(data, &bitmap.bytes); err != nil {
+			return nil, err
+		}
+		return bitmap, nil
+	})
+}
+
+// MaxOffset is the number of bits a bitmap can hold, which makes its maximum size 512MB.
+const MaxOffset = 1 << 32
+
+// Bitmap is a string stored as raw bytes so that bit operations are not affected by the conversion of numeric
+// strings to numbers. The bit at offset 0 is the most significant bit of the first byte.
+type Bitmap struct {
+	mut   sync.RWMutex
+	bytes []byte
+}
+
+// NewBitmap returns a bitmap that holds a copy of b.
+func NewBitmap(b []byte) *Bitmap {
+	return &Bitmap{bytes: slices.Clone(b)}
+}
+
+func (bitmap *Bitmap) GetMem() int64 {
+	bitmap.mut.RLock()
+	defer bitmap.mut.RUnlock()
+	return int64(unsafe.Sizeof(*bitmap)) + int64(cap(bitmap.bytes))
+}
+
+// String returns the bytes of the bitmap as a string.
+func (bitmap *Bitmap) String() string {
+	bitmap.mut.RLock()
+	defer bitmap.mut.RUnlock()
+	return string(bitmap.bytes)
+}
+
+// Bytes returns a copy of the bytes of the bitmap.
+func (bitmap *Bitmap) Bytes() []byte {
+	bitmap.mut.RLock()
+	defer bitmap.mut.RUnlock()
+	return slices.Clone(bitmap.bytes)
+}
+
+// Len returns the number of bytes in the bitmap.
+func (bitmap *Bitmap) Len() int {
+	bitmap.mut.RLock()
+	defer bitmap.mut.RUnlock()
+	return len(bitmap.bytes)
+}
+
+// grow extends the bitmap with zero bytes so that it holds the bit at offset.
+func (bitmap *Bitmap) grow(offset uint64) {
+	if n := int(offset/8) + 1; n > len(bitmap.bytes) {
+		bitmap.bytes = append(bitmap.bytes, make([]byte, n-len(bitmap.bytes))...)
+	}
+}
+
+func (bitmap *Bitmap) bit(offset uint64) int {
+	if offset/8 >= uint64(len(bitmap.bytes)) {
+		return 0
+	}
+	return int(bitmap.bytes[offset/8]>>(7-offset%8)) & 1
+}
+
+func (bitmap *Bitmap) setBit(offset uint64, value int) {
+	bitmap.grow(offset)
+	mask := byte(1) << (7 - offset%8)
+	if value == 1 {
+		bitmap.bytes[offset/8] |= mask
+	} else {
+		bitmap.bytes[offset/8] &^= mask
+	}
+}
+
+// GetBit returns the bit at offset. Bits past the end of the bitmap are 0.
+func (bitmap *Bitmap) GetBit(offset uint64) int {
+	bitmap.mut.RLock()
+	defer bitmap.mut.RUnlock()
+	return bitmap.bit(offset)
+}
+
+// SetBit sets the bit at offset to value, growing the bitmap if needed. Returns the previous bit.
+func (bitmap *Bitmap) SetBit(offset uint64, value int) int {
+	bitmap.mut.Lock()
+	defer bitmap.mut.Unlock()
+	previous := bitmap.bit(offset)
+	bitmap.setBit(offset, value)
+	return previous
+}
+
+// Range holds the bounds of BITCOUNT and BITPOS. Start and End are byte indexes, or bit indexes when Bit is true.
+// Negative indexes count from the end of the bitmap.
+type Range struct {
+	Start int
+	End   int
+	Bit   bool
+}
+
+// bounds returns the first and last bit offsets of the range, or ok false if the range is empty.
+func (r Range) bounds(length int) (start, end uint64, ok bool) {
+	length *= 8
+	if !r.Bit {
+		r.Start *= 8
+		r.End = r.End*8 + 7
+	}
+	if r.Start < 0 {
+		r.Start = max(length+r.Start, 0)
+	}
+	if r.End < 0 {
+		r.End = max(length+r.End, -1)
+	}
+	r.End = min(r.End, length-1)
+	if r.Start > r.End {
+		return 0, 0, false
+	}
+	return uint64(r.Start), uint64(r.End), true
+}
+
+// Count returns the number of bits set to 1 in the range.
+func (bitmap *Bitmap) Count(r Range) int {
+	bitmap.mut.RLock()
+	defer bitmap.mut.RUnlock()
+
+	start, end, ok := r.bounds(len(bitmap.bytes))
+	if !ok {
+		return 0
+	}
+	count := 0
+	for offset := start; offset <= end; {
+		// Count whole bytes at once when the range covers them.
+		if offset%8 == 0 && offset+7 <= end {
+			count += bits.OnesCount8(bitmap.bytes[offset/8])
+			offset += 8
+			continue
+		}
+		count += bitmap.bit(offset)
+		offset++
+	}
+	return count
+}
+
+// Pos returns the offset of the first bit set to value in the range, or -1 if there is none.
+// When looking for 0 without an explicit end, the bitmap is treated as padded with zeros on the right.
+func (bitmap *Bitmap) Pos(value int, r Range, endGiven bool) int {
+	bitmap.mut.RLock()
+	defer bitmap.mut.RUnlock()
+
+	if !endGiven {
+		r.End = -1
+	}
+	start, end, ok := r.bounds(len(bitmap.bytes))
+	if !ok {
+		return -1
+	}
+	// Skip the bytes that can't contain the bit.
+	skip := byte(0)
+	if value == 0 {
+		skip = math.MaxUint8
+	}
+	for offset := start; offset <= end; {
+		if offset%8 == 0 && offset+7 <= end && bitmap.bytes[offset/8] == skip {
+			offset += 8
+			continue
+		}
+		if bitmap.bit(offset) == value {
+			return int(offset)
+		}
+		offset++
+	}
+	if value == 0 && !endGiven {
+		return int(end) + 1
+	}
+	return -1
+}
+
+// Field is an integer encoded in a bitmap by BITFIELD.
+type Field struct {
+	Signed bool
+	Bits   uint // Between 1 and 64 for signed fields, and between 1 and 63 for unsigned fields.
+}
+
+// Overflow controls how BITFIELD handles values that don't fit in a field.
+type Overflow int
+
+const (
+	OverflowWrap Overflow = iota // Wraps around, like the overflow of an integer.
+	OverflowSat                  // Saturates to the minimum or the maximum value of the field.
+	OverflowFail                 // Fails the operation.
+)
+
+// GetField returns the value of the field at offset.
+func (bitmap *Bitmap) GetField(field Field, offset uint64) int64 {
+	bitmap.mut.RLock()
+	defer bitmap.mut.RUnlock()
+	return bitmap.field(field, offset)
+}
+
+func (bitmap *Bitmap) field(field Field, offset uint64) int64 {
+	var value uint64
+	for i := uint64(0); i < uint64(field.Bits); i++ {
+		value = value<<1 | uint64(bitmap.bit(offset+i))
+	}
+	// Extend the sign of negative values.
+	if field.Signed && field.Bits < 64 && value&(1<<(field.Bits-1)) != 0 {
+		value |= math.MaxUint64 << field.Bits
+	}
+	return int64(value)
+}
+
+func (bitmap *Bitmap) setField(field Field, offset uint64, value int64) {
+	for i := uint64(0); i < uint64(field.Bits); i++ {
+		bitmap.setBit(offset+i, int(uint64(value)>>(uint64(field.Bits)-1-i))&1)
+	}
+}
+
+// SetField sets the field at offset to value and returns the previous value.
+// Returns ok false without changing the field if the value overflows and overflow is OverflowFail.
+func (bitmap *Bitmap) SetField(field Field, offset uint64, value int64, overflow Overflow) (int64, bool) {
+	bitmap.mut.Lock()
+	defer bitmap.mut.Unlock()
+
+	previous := bitmap.field(field, offset)
+	value, ok := field.add(value, 0, overflow)
+	if !ok {
+		return 0, false
+	}
+	bitmap.setField(field, offset, value)
+	return previous, true
+}
+
+// IncrField increments the field at offset by increment and returns the new value.
+// Returns ok false without changing the field if the value overflows and overflow is OverflowFail.
+func (bitmap *Bitmap) IncrField(field Field, offset uint64, increment int64, overflow Overflow) (int64, bool) {
+	bitmap.mut.Lock()
+	defer bitmap.mut.Unlock()
+
+	value, ok := field.add(bitmap.field(field, offset), increment, overflow)
+	if !ok {
+		return 0, false
+	}
+	bitmap.setField(field, offset, value)
+	return value, true
+}
+
+// add returns value + increment, handling the overflow of the field.
+func (field Field) add(value, increment int64, overflow Overflow) (int64, bool) {
+	if !field.Signed {
+		return field.addUnsigned(uint64(value), increment, overflow)
+	}
+
+	maxValue := int64(math.MaxInt64)
+	if field.Bits < 64 {
+		maxValue = 1<<(field.Bits-1) - 1
+	}
+	minValue := -maxValue - 1
+
+	var limit int64
+	switch {
+	case value > maxValue ||
+		(field.Bits < 64 && increment > maxValue-value) ||
+		(value >= 0 && increment > 0 && increment > maxValue-value):
+		limit = maxValue
+	case value < minValue ||
+		(field.Bits < 64 && increment < minValue-value) ||
+		(value < 0 && increment < 0 && increment < minValue-value):
+		limit = minValue
+	default:
+		return value + increment, true
+	}
+
+	switch overflow {
+	case OverflowSat:
+		return limit, true
+	case OverflowFail:
+		return 0, false
+	}
+	wrapped := uint64(value) + uint64(increment)
+	if field.Bits < 64 {
+		mask := uint64(math.MaxUint64) << field.Bits
+		if wrapped&(1<<(field.Bits-1)) != 0 {
+			wrapped |= mask
+		} else {
+			wrapped &^= mask
+		}
+	}
+	return int64(wrapped), true
+}
+
+func (field Field) addUnsigned(value uint64, increment int64, overflow Overflow) (int64, bool) {
+	maxValue := uint64(1)<<field.Bits - 1
+
+	var limit uint64
+	switch {
+	case value > maxValue || (increment > 0 && uint64(increment) > maxValue-value):
+		limit = maxValue
+	case increment < 0 && uint64(-increment) > value:
+		limit = 0
+	default:
+		return int64(value + uint64(increment)), true
+	}
+
+	switch overflow {
+	case OverflowSat:
+		return int64(limit), true
+	case OverflowFail:
+		return 0, false
+	}
+	return int64((value + uint64(increment)) & maxValue), true
+}
+
+// CompositeType returns the type tag that restores the bitmap from a snapshot.
+func (bitmap *Bitmap) CompositeType() string {
+	return "bitmap"
+}
+
+// MarshalJSON encodes the bytes of the bitmap so that it can be restored from a snapshot.
+func (bitmap *Bitmap) MarshalJSON() ([]byte, error) {
+	bitmap.mut.RLock()
+	defer bitmap.mut.RUnlock()
+	return json.Marshal(bitmap.bytes)
+}
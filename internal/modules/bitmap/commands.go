@@ -0,0 +1,499 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmap
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/echovault/sugardb/internal"
+	"github.com/echovault/sugardb/internal/constants"
+)
+
+// getBitmap returns the bitmap at the key, or nil if the key does not exist.
+// Strings and numbers are read as the bytes of their string representation.
+func getBitmap(params internal.HandlerFuncParams, key string) (*Bitmap, error) {
+	if !params.KeysExist(params.Context, []string{key})[key] {
+		return nil, nil
+	}
+	switch value := params.GetValues(params.Context, []string{key})[key].(type) {
+	case *Bitmap:
+		return value, nil
+	case string:
+		return NewBitmap([]byte(value)), nil
+	case int, float64:
+		return NewBitmap([]byte(fmt.Sprintf("%v", value))), nil
+	}
+	return nil, fmt.Errorf("value at %s is not a string", key)
+}
+
+func parseOffset(arg string) (uint64, error) {
+	offset, err := strconv.ParseUint(arg, 10, 64)
+	if err != nil || offset >= MaxOffset {
+		return 0, errors.New("bit offset is not an integer or out of range")
+	}
+	return offset, nil
+}
+
+func parseBit(arg string) (int, error) {
+	if arg != "0" && arg != "1" {
+		return 0, errors.New("bit is not an integer or out of range")
+	}
+	return int(arg[0] - '0'), nil
+}
+
+// parseRange parses the start, end and BYTE|BIT arguments of BITCOUNT and BITPOS.
+// Missing arguments keep the values of r.
+func parseRange(args []string, r Range) (Range, error) {
+	var err error
+	if len(args) > 0 {
+		if r.Start, err = strconv.Atoi(args[0]); err != nil {
+			return Range{}, errors.New("value is not an integer or out of range")
+		}
+	}
+	if len(args) > 1 {
+		if r.End, err = strconv.Atoi(args[1]); err != nil {
+			return Range{}, errors.New("value is not an integer or out of range")
+		}
+	}
+	if len(args) > 2 {
+		switch strings.ToLower(args[2]) {
+		case "byte":
+			r.Bit = false
+		case "bit":
+			r.Bit = true
+		default:
+			return Range{}, errors.New("syntax error")
+		}
+	}
+	return r, nil
+}
+
+func handleSETBIT(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := setBitKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key := keys.WriteKeys[0]
+
+	offset, err := parseOffset(params.Command[2])
+	if err != nil {
+		return nil, err
+	}
+	value, err := parseBit(params.Command[3])
+	if err != nil {
+		return nil, err
+	}
+
+	bitmap, err := getBitmap(params, key)
+	if err != nil {
+		return nil, err
+	}
+	if bitmap == nil {
+		bitmap = NewBitmap(nil)
+	}
+	previous := bitmap.SetBit(offset, value)
+
+	if err = params.SetValues(params.Context, map[string]interface{}{key: bitmap}); err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf(":%d\r\n", previous)), nil
+}
+
+func handleGETBIT(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := getBitKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key := keys.ReadKeys[0]
+
+	offset, err := parseOffset(params.Command[2])
+	if err != nil {
+		return nil, err
+	}
+
+	bitmap, err := getBitmap(params, key)
+	if err != nil {
+		return nil, err
+	}
+	if bitmap == nil {
+		return []byte(":0\r\n"), nil
+	}
+	return []byte(fmt.Sprintf(":%d\r\n", bitmap.GetBit(offset))), nil
+}
+
+func handleBITCOUNT(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := bitCountKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key := keys.ReadKeys[0]
+
+	// The range requires both start and end.
+	if len(params.Command) == 3 {
+		return nil, errors.New("syntax error")
+	}
+	r, err := parseRange(params.Command[2:], Range{Start: 0, End: -1})
+	if err != nil {
+		return nil, err
+	}
+
+	bitmap, err := getBitmap(params, key)
+	if err != nil {
+		return nil, err
+	}
+	if bitmap == nil {
+		return []byte(":0\r\n"), nil
+	}
+	return []byte(fmt.Sprintf(":%d\r\n", bitmap.Count(r))), nil
+}
+
+func handleBITPOS(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := bitPosKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key := keys.ReadKeys[0]
+
+	bit, err := parseBit(params.Command[2])
+	if err != nil {
+		return nil, errors.New("The bit argument must be 1 or 0.")
+	}
+	r, err := parseRange(params.Command[3:], Range{Start: 0, End: -1})
+	if err != nil {
+		return nil, err
+	}
+
+	bitmap, err := getBitmap(params, key)
+	if err != nil {
+		return nil, err
+	}
+	if bitmap == nil {
+		// A missing key is an empty string, which is padded with zeros on the right.
+		return []byte(fmt.Sprintf(":%d\r\n", -bit)), nil
+	}
+	return []byte(fmt.Sprintf(":%d\r\n", bitmap.Pos(bit, r, len(params.Command) > 4))), nil
+}
+
+func handleBITOP(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := bitOpKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	destination := keys.WriteKeys[0]
+
+	operation := strings.ToLower(params.Command[1])
+	if !slices.Contains([]string{"and", "or", "xor", "not"}, operation) {
+		return nil, errors.New("syntax error")
+	}
+	if operation == "not" && len(keys.ReadKeys) != 1 {
+		return nil, errors.New("BITOP NOT must be called with a single source key.")
+	}
+
+	sources := make([][]byte, len(keys.ReadKeys))
+	length := 0
+	for i, key := range keys.ReadKeys {
+		bitmap, err := getBitmap(params, key)
+		if err != nil {
+			return nil, err
+		}
+		if bitmap != nil {
+			sources[i] = bitmap.Bytes()
+		}
+		length = max(length, len(sources[i]))
+	}
+
+	// Missing keys and the bytes past the end of shorter sources are zeros.
+	res := make([]byte, length)
+	for i := range res {
+		for j, source := range sources {
+			var b byte
+			if i < len(source) {
+				b = source[i]
+			}
+			switch {
+			case operation == "not":
+				res[i] = ^b
+			case j == 0:
+				res[i] = b
+			case operation == "and":
+				res[i] &= b
+			case operation == "or":
+				res[i] |= b
+			case operation == "xor":
+				res[i] ^= b
+			}
+		}
+	}
+
+	if length == 0 {
+		if params.KeysExist(params.Context, []string{destination})[destination] {
+			if err = params.DeleteKey(params.Context, destination); err != nil {
+				return nil, err
+			}
+		}
+		return []byte(":0\r\n"), nil
+	}
+
+	if err = params.SetValues(params.Context, map[string]interface{}{destination: NewBitmap(res)}); err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf(":%d\r\n", length)), nil
+}
+
+// bitFieldOperation is a GET, SET or INCRBY operation of BITFIELD.
+type bitFieldOperation struct {
+	operation string
+	field     Field
+	offset    uint64
+	value     int64
+	overflow  Overflow
+}
+
+func parseField(arg string) (Field, error) {
+	err := errors.New("Invalid bitfield type. Use something like i16 u8. Note that u64 is not supported but i64 is.")
+	if len(arg) < 2 {
+		return Field{}, err
+	}
+	field := Field{Signed: arg[0] == 'i' || arg[0] == 'I'}
+	if !field.Signed && arg[0] != 'u' && arg[0] != 'U' {
+		return Field{}, err
+	}
+	n, parseErr := strconv.ParseUint(arg[1:], 10, 8)
+	if parseErr != nil || n < 1 || (field.Signed && n > 64) || (!field.Signed && n > 63) {
+		return Field{}, err
+	}
+	field.Bits = uint(n)
+	return field, nil
+}
+
+// parseFieldOffset parses the offset of a field. An offset prefixed with "#" is multiplied by the width of the field.
+func parseFieldOffset(arg string, field Field) (uint64, error) {
+	offset, err := strconv.ParseUint(strings.TrimPrefix(arg, "#"), 10, 64)
+	if err != nil {
+		return 0, errors.New("bit offset is not an integer or out of range")
+	}
+	if strings.HasPrefix(arg, "#") {
+		offset *= uint64(field.Bits)
+	}
+	if offset+uint64(field.Bits) > MaxOffset {
+		return 0, errors.New("bit offset is not an integer or out of range")
+	}
+	return offset, nil
+}
+
+// parseBitFieldOperations parses the operations of BITFIELD and BITFIELD_RO.
+func parseBitFieldOperations(args []string, readOnly bool) ([]bitFieldOperation, error) {
+	operations := make([]bitFieldOperation, 0)
+	overflow := OverflowWrap
+	for i := 0; i < len(args); i++ {
+		operation := strings.ToLower(args[i])
+		if readOnly && operation != "get" {
+			return nil, errors.New("BITFIELD_RO only supports the GET subcommand")
+		}
+
+		switch operation {
+		case "overflow":
+			if i+1 >= len(args) {
+				return nil, errors.New("syntax error")
+			}
+			i++
+			switch strings.ToLower(args[i]) {
+			case "wrap":
+				overflow = OverflowWrap
+			case "sat":
+				overflow = OverflowSat
+			case "fail":
+				overflow = OverflowFail
+			default:
+				return nil, errors.New("Invalid OVERFLOW type specified")
+			}
+			continue
+		case "get":
+			if i+2 >= len(args) {
+				return nil, errors.New("syntax error")
+			}
+		case "set", "incrby":
+			if i+3 >= len(args) {
+				return nil, errors.New("syntax error")
+			}
+		default:
+			return nil, errors.New("syntax error")
+		}
+
+		field, err := parseField(args[i+1])
+		if err != nil {
+			return nil, err
+		}
+		offset, err := parseFieldOffset(args[i+2], field)
+		if err != nil {
+			return nil, err
+		}
+		op := bitFieldOperation{operation: operation, field: field, offset: offset, overflow: overflow}
+		i += 2
+		if operation != "get" {
+			i++
+			if op.value, err = strconv.ParseInt(args[i], 10, 64); err != nil {
+				return nil, errors.New("value is not an integer or out of range")
+			}
+		}
+		operations = append(operations, op)
+	}
+	return operations, nil
+}
+
+func handleBitField(params internal.HandlerFuncParams, key string, readOnly bool) ([]byte, error) {
+	operations, err := parseBitFieldOperations(params.Command[2:], readOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	bitmap, err := getBitmap(params, key)
+	if err != nil {
+		return nil, err
+	}
+	if bitmap == nil {
+		bitmap = NewBitmap(nil)
+	}
+
+	changed := false
+	res := fmt.Sprintf("*%d\r\n", len(operations))
+	for _, op := range operations {
+		var value int64
+		ok := true
+		switch op.operation {
+		case "get":
+			value = bitmap.GetField(op.field, op.offset)
+		case "set":
+			value, ok = bitmap.SetField(op.field, op.offset, op.value, op.overflow)
+		case "incrby":
+			value, ok = bitmap.IncrField(op.field, op.offset, op.value, op.overflow)
+		}
+		if !ok {
+			// The operation failed with OVERFLOW FAIL.
+			res += "$-1\r\n"
+			continue
+		}
+		changed = changed || op.operation != "get"
+		res += fmt.Sprintf(":%d\r\n", value)
+	}
+
+	if changed {
+		if err = params.SetValues(params.Context, map[string]interface{}{key: bitmap}); err != nil {
+			return nil, err
+		}
+	}
+	return []byte(res), nil
+}
+
+func handleBITFIELD(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := bitFieldKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	return handleBitField(params, keys.WriteKeys[0], false)
+}
+
+func handleBITFIELD_RO(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := bitFieldROKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	return handleBitField(params, keys.ReadKeys[0], true)
+}
+
+func Commands() []internal.Command {
+	return []internal.Command{
+		{
+			Command:    "setbit",
+			Module:     constants.BitmapModule,
+			Categories: []string{constants.BitmapCategory, constants.WriteCategory, constants.SlowCategory},
+			Description: `(SETBIT key offset value)
+Sets the bit at offset to value, which is either 0 or 1. The string is grown with zeros if offset is past its end.
+Returns the previous bit.`,
+			Sync:              true,
+			KeyExtractionFunc: setBitKeyFunc,
+			HandlerFunc:       handleSETBIT,
+		},
+		{
+			Command:    "getbit",
+			Module:     constants.BitmapModule,
+			Categories: []string{constants.BitmapCategory, constants.ReadCategory, constants.FastCategory},
+			Description: `(GETBIT key offset)
+Returns the bit at offset. Bits past the end of the string and bits of missing keys are 0.`,
+			Sync:              false,
+			KeyExtractionFunc: getBitKeyFunc,
+			HandlerFunc:       handleGETBIT,
+		},
+		{
+			Command:    "bitcount",
+			Module:     constants.BitmapModule,
+			Categories: []string{constants.BitmapCategory, constants.ReadCategory, constants.SlowCategory},
+			Description: `(BITCOUNT key [start end [BYTE | BIT]])
+Returns the number of bits set to 1. start and end restrict the count to a range of bytes, or of bits with BIT.
+Negative indexes count from the end of the string.`,
+			Sync:              false,
+			KeyExtractionFunc: bitCountKeyFunc,
+			HandlerFunc:       handleBITCOUNT,
+		},
+		{
+			Command:    "bitpos",
+			Module:     constants.BitmapModule,
+			Categories: []string{constants.BitmapCategory, constants.ReadCategory, constants.SlowCategory},
+			Description: `(BITPOS key bit [start [end [BYTE | BIT]]])
+Returns the position of the first bit set to bit, or -1 if there is none. start and end restrict the search
+to a range of bytes, or of bits with BIT.`,
+			Sync:              false,
+			KeyExtractionFunc: bitPosKeyFunc,
+			HandlerFunc:       handleBITPOS,
+		},
+		{
+			Command:    "bitop",
+			Module:     constants.BitmapModule,
+			Categories: []string{constants.BitmapCategory, constants.WriteCategory, constants.SlowCategory},
+			Description: `(BITOP AND | OR | XOR | NOT destkey key [key ...])
+Performs a bitwise operation between the strings and stores the result in destkey.
+Returns the length of the result, which is the length of the longest string.`,
+			Sync:              true,
+			KeyExtractionFunc: bitOpKeyFunc,
+			HandlerFunc:       handleBITOP,
+		},
+		{
+			Command:    "bitfield",
+			Module:     constants.BitmapModule,
+			Categories: []string{constants.BitmapCategory, constants.WriteCategory, constants.SlowCategory},
+			Description: `(BITFIELD key [GET encoding offset | [OVERFLOW WRAP | SAT | FAIL] SET encoding offset value | INCRBY encoding offset increment ...])
+Reads and writes integers of arbitrary width at arbitrary offsets of the string. The encoding is i or u for signed
+or unsigned integers followed by the number of bits. An offset prefixed with # is multiplied by the width.
+OVERFLOW controls how the following SET and INCRBY operations handle overflows.`,
+			Sync:              true,
+			KeyExtractionFunc: bitFieldKeyFunc,
+			HandlerFunc:       handleBITFIELD,
+		},
+		{
+			Command:    "bitfield_ro",
+			Module:     constants.BitmapModule,
+			Categories: []string{constants.BitmapCategory, constants.ReadCategory, constants.FastCategory},
+			Description: `(BITFIELD_RO key [GET encoding offset ...])
+Read-only variant of BITFIELD that only supports GET.`,
+			Sync:              false,
+			KeyExtractionFunc: bitFieldROKeyFunc,
+			HandlerFunc:       handleBITFIELD_RO,
+		},
+	}
+}
@@ -0,0 +1,373 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmap_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/echovault/sugardb/internal"
+	"github.com/echovault/sugardb/internal/config"
+	"github.com/echovault/sugardb/internal/constants"
+	"github.com/echovault/sugardb/sugardb"
+	"github.com/tidwall/resp"
+)
+
+// format returns the response as a string. Arrays are formatted as "[element element ...]" and nulls as "nil".
+func format(v resp.Value) string {
+	if v.IsNull() {
+		return "nil"
+	}
+	if v.Type() == resp.Array {
+		elements := make([]string, len(v.Array()))
+		for i, element := range v.Array() {
+			elements[i] = format(element)
+		}
+		return "[" + strings.Join(elements, " ") + "]"
+	}
+	return v.String()
+}
+
+func Test_Bitmap(t *testing.T) {
+	port, err := internal.GetFreePort()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	mockServer, err := sugardb.NewSugarDB(
+		sugardb.WithConfig(config.Config{
+			BindAddr:       "localhost",
+			Port:           uint16(port),
+			DataDir:        "",
+			EvictionPolicy: constants.NoEviction,
+		}),
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	go func() {
+		mockServer.Start()
+	}()
+
+	t.Cleanup(func() {
+		mockServer.ShutDown()
+	})
+
+	type step struct {
+		command []string
+		want    string // The expected response, as returned by format.
+		wantErr string // The expected error substring when the response is an error.
+	}
+
+	runSteps := func(t *testing.T, client *resp.Conn, steps []step) {
+		for _, step := range steps {
+			values := make([]resp.Value, len(step.command))
+			for i, token := range step.command {
+				values[i] = resp.StringValue(token)
+			}
+			if err := client.WriteArray(values); err != nil {
+				t.Error(err)
+				return
+			}
+			res, _, err := client.ReadValue()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if step.wantErr != "" {
+				if res.Error() == nil || !strings.Contains(res.Error().Error(), step.wantErr) {
+					t.Errorf("%q: expected error \"%s\", got %+v", step.command, step.wantErr, res)
+				}
+				continue
+			}
+			if res.Error() != nil {
+				t.Errorf("%q: unexpected error %v", step.command, res.Error())
+				continue
+			}
+			if got := format(res); got != step.want {
+				t.Errorf("%q: expected response %q, got %q", step.command, step.want, got)
+			}
+		}
+	}
+
+	connect := func(t *testing.T) *resp.Conn {
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			_ = conn.Close()
+		})
+		return resp.NewConn(conn)
+	}
+
+	t.Run("Test_HandleSETBIT_GETBIT", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		tests := []struct {
+			name  string
+			steps []step
+		}{
+			{
+				name: "1. Set and get bits",
+				steps: []step{
+					{command: []string{"SETBIT", "SetbitKey1", "1", "1"}, want: "0"},
+					{command: []string{"SETBIT", "SetbitKey1", "7", "1"}, want: "0"},
+					{command: []string{"SETBIT", "SetbitKey1", "7", "1"}, want: "1"},
+					{command: []string{"GETBIT", "SetbitKey1", "1"}, want: "1"},
+					{command: []string{"GETBIT", "SetbitKey1", "2"}, want: "0"},
+					{command: []string{"GETBIT", "SetbitKey1", "100"}, want: "0"},
+					{command: []string{"GET", "SetbitKey1"}, want: "A"},
+					{command: []string{"TYPE", "SetbitKey1"}, want: "string"},
+					{command: []string{"SETBIT", "SetbitKey1", "7", "0"}, want: "1"},
+					{command: []string{"GET", "SetbitKey1"}, want: "@"},
+				},
+			},
+			{
+				name: "2. Grow the string with zeros",
+				steps: []step{
+					{command: []string{"SETBIT", "SetbitKey2", "20", "1"}, want: "0"},
+					{command: []string{"GET", "SetbitKey2"}, want: "\x00\x00\x08"},
+					{command: []string{"GETBIT", "SetbitKey2", "20"}, want: "1"},
+					{command: []string{"GETBIT", "SetbitKey3", "0"}, want: "0"},
+				},
+			},
+			{
+				name: "3. Operate on the bytes of strings and numbers",
+				steps: []step{
+					{command: []string{"SET", "SetbitKey4", "a"}, want: "OK"},
+					{command: []string{"SETBIT", "SetbitKey4", "6", "1"}, want: "0"},
+					{command: []string{"GET", "SetbitKey4"}, want: "c"},
+					{command: []string{"SET", "SetbitKey5", "1"}, want: "OK"},
+					{command: []string{"GETBIT", "SetbitKey5", "7"}, want: "1"},
+					{command: []string{"SETBIT", "SetbitKey5", "6", "1"}, want: "0"},
+					{command: []string{"GET", "SetbitKey5"}, want: "3"},
+				},
+			},
+			{
+				name: "4. Return an error when the arguments are invalid",
+				steps: []step{
+					{command: []string{"SETBIT", "SetbitKey6", "-1", "1"}, wantErr: "bit offset is not an integer or out of range"},
+					{command: []string{"SETBIT", "SetbitKey6", "4294967296", "1"}, wantErr: "bit offset is not an integer or out of range"},
+					{command: []string{"SETBIT", "SetbitKey6", "0", "2"}, wantErr: "bit is not an integer or out of range"},
+					{command: []string{"GETBIT", "SetbitKey6", "x"}, wantErr: "bit offset is not an integer or out of range"},
+					{command: []string{"LPUSH", "SetbitKey7", "a"}, want: "1"},
+					{command: []string{"SETBIT", "SetbitKey7", "0", "1"}, wantErr: "value at SetbitKey7 is not a string"},
+					{command: []string{"GETBIT", "SetbitKey7", "0"}, wantErr: "value at SetbitKey7 is not a string"},
+					{command: []string{"SETBIT", "SetbitKey6", "0"}, wantErr: constants.WrongArgsResponse},
+					{command: []string{"GETBIT", "SetbitKey6"}, wantErr: constants.WrongArgsResponse},
+				},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				runSteps(t, client, test.steps)
+			})
+		}
+	})
+
+	t.Run("Test_HandleBITCOUNT", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"SET", "BitcountKey1", "foobar"}, want: "OK"},
+			{command: []string{"BITCOUNT", "BitcountKey1"}, want: "26"},
+			{command: []string{"BITCOUNT", "BitcountKey1", "0", "0"}, want: "4"},
+			{command: []string{"BITCOUNT", "BitcountKey1", "1", "1", "BYTE"}, want: "6"},
+			{command: []string{"BITCOUNT", "BitcountKey1", "-2", "-1"}, want: "7"},
+			{command: []string{"BITCOUNT", "BitcountKey1", "5", "30", "BIT"}, want: "17"},
+			{command: []string{"BITCOUNT", "BitcountKey1", "3", "1"}, want: "0"},
+			{command: []string{"BITCOUNT", "BitcountKey1", "0", "100"}, want: "26"},
+			{command: []string{"BITCOUNT", "BitcountKey2"}, want: "0"},
+			{command: []string{"BITCOUNT", "BitcountKey1", "0"}, wantErr: "syntax error"},
+			{command: []string{"BITCOUNT", "BitcountKey1", "0", "1", "WORD"}, wantErr: "syntax error"},
+			{command: []string{"BITCOUNT", "BitcountKey1", "x", "1"}, wantErr: "value is not an integer or out of range"},
+			{command: []string{"BITCOUNT"}, wantErr: constants.WrongArgsResponse},
+		})
+	})
+
+	t.Run("Test_HandleBITPOS", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"SET", "BitposKey1", "\xff\xf0\x00"}, want: "OK"},
+			{command: []string{"BITPOS", "BitposKey1", "0"}, want: "12"},
+			{command: []string{"SET", "BitposKey2", "\x00\xff\xf0"}, want: "OK"},
+			{command: []string{"BITPOS", "BitposKey2", "1", "0"}, want: "8"},
+			{command: []string{"BITPOS", "BitposKey2", "1", "2"}, want: "16"},
+			{command: []string{"BITPOS", "BitposKey2", "1", "2", "-1", "BYTE"}, want: "16"},
+			{command: []string{"BITPOS", "BitposKey2", "1", "7", "15", "BIT"}, want: "8"},
+			{command: []string{"BITPOS", "BitposKey2", "1", "7", "-3", "BIT"}, want: "8"},
+			{command: []string{"BITPOS", "BitposKey2", "0", "12", "-1", "BIT"}, want: "20"},
+			// Without an end, the string is padded with zeros on the right.
+			{command: []string{"SET", "BitposKey3", "\xff\xff"}, want: "OK"},
+			{command: []string{"BITPOS", "BitposKey3", "0"}, want: "16"},
+			{command: []string{"BITPOS", "BitposKey3", "0", "0", "-1"}, want: "-1"},
+			{command: []string{"SET", "BitposKey4", "\x00\x00"}, want: "OK"},
+			{command: []string{"BITPOS", "BitposKey4", "1"}, want: "-1"},
+			{command: []string{"BITPOS", "BitposKey5", "1"}, want: "-1"},
+			{command: []string{"BITPOS", "BitposKey5", "0"}, want: "0"},
+			{command: []string{"BITPOS", "BitposKey1", "2"}, wantErr: "The bit argument must be 1 or 0."},
+			{command: []string{"BITPOS", "BitposKey1", "1", "0", "1", "WORD"}, wantErr: "syntax error"},
+			{command: []string{"BITPOS", "BitposKey1"}, wantErr: constants.WrongArgsResponse},
+		})
+	})
+
+	t.Run("Test_HandleBITOP", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"SET", "BitopKey1", "foobar"}, want: "OK"},
+			{command: []string{"SET", "BitopKey2", "abcdef"}, want: "OK"},
+			{command: []string{"SET", "BitopKey3", "a"}, want: "OK"},
+			{command: []string{"SET", "BitopKey4", "\x0f"}, want: "OK"},
+			{command: []string{"SET", "BitopKey5", "\xff"}, want: "OK"},
+		})
+
+		tests := []struct {
+			name  string
+			steps []step
+		}{
+			{
+				name: "1. Perform bitwise operations between strings",
+				steps: []step{
+					{command: []string{"BITOP", "AND", "BitopDest1", "BitopKey1", "BitopKey2"}, want: "6"},
+					{command: []string{"GET", "BitopDest1"}, want: "`bc`ab"},
+					{command: []string{"BITOP", "OR", "BitopDest1", "BitopKey1", "BitopKey2"}, want: "6"},
+					{command: []string{"GET", "BitopDest1"}, want: "goofev"},
+					{command: []string{"BITOP", "XOR", "BitopDest1", "BitopKey4", "BitopKey5"}, want: "1"},
+					{command: []string{"GET", "BitopDest1"}, want: "\xf0"},
+					{command: []string{"BITOP", "NOT", "BitopDest1", "BitopKey4"}, want: "1"},
+					{command: []string{"GET", "BitopDest1"}, want: "\xf0"},
+				},
+			},
+			{
+				name: "2. Pad shorter strings and missing keys with zeros",
+				steps: []step{
+					{command: []string{"BITOP", "OR", "BitopDest2", "BitopKey3", "BitopKey1"}, want: "6"},
+					{command: []string{"GET", "BitopDest2"}, want: "goobar"},
+					{command: []string{"BITOP", "AND", "BitopDest2", "BitopKey1", "BitopKey6"}, want: "6"},
+					{command: []string{"GET", "BitopDest2"}, want: "\x00\x00\x00\x00\x00\x00"},
+				},
+			},
+			{
+				name: "3. Delete the destination when the result is empty",
+				steps: []step{
+					{command: []string{"SET", "BitopDest3", "value"}, want: "OK"},
+					{command: []string{"BITOP", "AND", "BitopDest3", "BitopKey6", "BitopKey7"}, want: "0"},
+					{command: []string{"GET", "BitopDest3"}, want: "nil"},
+				},
+			},
+			{
+				name: "4. Return an error when the arguments are invalid",
+				steps: []step{
+					{command: []string{"BITOP", "NOT", "BitopDest4", "BitopKey1", "BitopKey2"}, wantErr: "BITOP NOT must be called with a single source key"},
+					{command: []string{"BITOP", "NAND", "BitopDest4", "BitopKey1"}, wantErr: "syntax error"},
+					{command: []string{"LPUSH", "BitopKey8", "a"}, want: "1"},
+					{command: []string{"BITOP", "OR", "BitopDest4", "BitopKey8"}, wantErr: "value at BitopKey8 is not a string"},
+					{command: []string{"BITOP", "OR", "BitopDest4"}, wantErr: constants.WrongArgsResponse},
+				},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				runSteps(t, client, test.steps)
+			})
+		}
+	})
+
+	t.Run("Test_HandleBITFIELD", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		tests := []struct {
+			name  string
+			steps []step
+		}{
+			{
+				name: "1. Get, set and increment fields",
+				steps: []step{
+					{command: []string{"BITFIELD", "BitfieldKey1", "INCRBY", "i5", "100", "1", "GET", "u4", "0"}, want: "[1 0]"},
+					{command: []string{"BITFIELD", "BitfieldKey1", "SET", "i8", "0", "-1", "GET", "u8", "0", "GET", "i8", "0"}, want: "[0 255 -1]"},
+					{command: []string{"BITFIELD", "BitfieldKey1", "SET", "u8", "#1", "200", "GET", "u8", "8"}, want: "[0 200]"},
+					{command: []string{"BITFIELD", "BitfieldKey1"}, want: "[]"},
+				},
+			},
+			{
+				name: "2. Handle overflows",
+				steps: []step{
+					{command: []string{"BITFIELD", "BitfieldKey2", "INCRBY", "u2", "100", "1", "OVERFLOW", "SAT", "INCRBY", "u2", "102", "1"}, want: "[1 1]"},
+					{command: []string{"BITFIELD", "BitfieldKey2", "INCRBY", "u2", "100", "1", "OVERFLOW", "SAT", "INCRBY", "u2", "102", "1"}, want: "[2 2]"},
+					{command: []string{"BITFIELD", "BitfieldKey2", "INCRBY", "u2", "100", "1", "OVERFLOW", "SAT", "INCRBY", "u2", "102", "1"}, want: "[3 3]"},
+					{command: []string{"BITFIELD", "BitfieldKey2", "INCRBY", "u2", "100", "1", "OVERFLOW", "SAT", "INCRBY", "u2", "102", "1"}, want: "[0 3]"},
+					{command: []string{"BITFIELD", "BitfieldKey2", "OVERFLOW", "FAIL", "INCRBY", "u2", "102", "1", "GET", "u2", "102"}, want: "[nil 3]"},
+					{command: []string{"BITFIELD", "BitfieldKey3", "SET", "i8", "0", "127", "INCRBY", "i8", "0", "1"}, want: "[0 -128]"},
+					{command: []string{"BITFIELD", "BitfieldKey3", "OVERFLOW", "SAT", "INCRBY", "i8", "0", "-1", "SET", "i8", "0", "1000"}, want: "[-128 -128]"},
+					{command: []string{"BITFIELD", "BitfieldKey3", "OVERFLOW", "FAIL", "INCRBY", "i8", "0", "128", "GET", "i8", "0"}, want: "[nil 127]"},
+					{
+						command: []string{"BITFIELD", "BitfieldKey4", "SET", "i64", "0", "9223372036854775807", "INCRBY", "i64", "0", "1"},
+						want:    "[0 -9223372036854775808]",
+					},
+					{command: []string{"BITFIELD", "BitfieldKey4", "SET", "u63", "0", "-1", "GET", "u63", "0"}, want: "[4611686018427387904 9223372036854775807]"},
+				},
+			},
+			{
+				name: "3. Don't create the key with only GET operations",
+				steps: []step{
+					{command: []string{"BITFIELD", "BitfieldKey5", "GET", "u8", "0"}, want: "[0]"},
+					{command: []string{"GET", "BitfieldKey5"}, want: "nil"},
+				},
+			},
+			{
+				name: "4. Only allow GET operations with BITFIELD_RO",
+				steps: []step{
+					{command: []string{"SET", "BitfieldKey6", "a"}, want: "OK"},
+					{command: []string{"BITFIELD_RO", "BitfieldKey6", "GET", "u8", "0", "GET", "u4", "4"}, want: "[97 1]"},
+					{command: []string{"BITFIELD_RO", "BitfieldKey6", "SET", "u8", "0", "1"}, wantErr: "BITFIELD_RO only supports the GET subcommand"},
+				},
+			},
+			{
+				name: "5. Return an error when the arguments are invalid",
+				steps: []step{
+					{command: []string{"BITFIELD", "BitfieldKey7", "GET", "u64", "0"}, wantErr: "Invalid bitfield type"},
+					{command: []string{"BITFIELD", "BitfieldKey7", "GET", "i65", "0"}, wantErr: "Invalid bitfield type"},
+					{command: []string{"BITFIELD", "BitfieldKey7", "GET", "x8", "0"}, wantErr: "Invalid bitfield type"},
+					{command: []string{"BITFIELD", "BitfieldKey7", "GET", "u8", "-1"}, wantErr: "bit offset is not an integer or out of range"},
+					{command: []string{"BITFIELD", "BitfieldKey7", "GET", "u8", "4294967290"}, wantErr: "bit offset is not an integer or out of range"},
+					{command: []string{"BITFIELD", "BitfieldKey7", "SET", "u8", "0", "x"}, wantErr: "value is not an integer or out of range"},
+					{command: []string{"BITFIELD", "BitfieldKey7", "OVERFLOW", "NONE"}, wantErr: "Invalid OVERFLOW type specified"},
+					{command: []string{"BITFIELD", "BitfieldKey7", "SET", "u8", "0"}, wantErr: "syntax error"},
+					{command: []string{"BITFIELD", "BitfieldKey7", "DECRBY", "u8", "0", "1"}, wantErr: "syntax error"},
+					{command: []string{"BITFIELD"}, wantErr: constants.WrongArgsResponse},
+				},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				runSteps(t, client, test.steps)
+			})
+		}
+	})
+}
@@ -20,6 +20,8 @@ import (
 	"github.com/echovault/sugardb/internal/modules/acl"
 	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/echovault/sugardb/internal"
 	"github.com/echovault/sugardb/internal/constants"
@@ -171,6 +173,312 @@ func handleSwapDB(params internal.HandlerFuncParams) ([]byte, error) {
 	return []byte(constants.OkResponse), nil
 }
 
+func handleMulti(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 1 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	if err := params.StartTransaction(params.Connection); err != nil {
+		return nil, err
+	}
+	return []byte(constants.OkResponse), nil
+}
+
+func handleExec(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 1 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	return params.ExecTransaction(params.Context, params.Connection)
+}
+
+func handleDiscard(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 1 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	if err := params.DiscardTransaction(params.Connection); err != nil {
+		return nil, err
+	}
+	return []byte(constants.OkResponse), nil
+}
+
+func handleWatch(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) < 2 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	if err := params.WatchKeys(params.Context, params.Connection, params.Command[1:]); err != nil {
+		return nil, err
+	}
+	return []byte(constants.OkResponse), nil
+}
+
+func handleUnwatch(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 1 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	params.UnwatchKeys(params.Context, params.Connection)
+	return []byte(constants.OkResponse), nil
+}
+
+func handleClientTracking(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) < 3 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+
+	var enable bool
+	switch strings.ToLower(params.Command[2]) {
+	case "on":
+		enable = true
+	case "off":
+		enable = false
+	default:
+		return nil, errors.New("syntax error")
+	}
+
+	options, err := getClientTrackingOptions(params.Command[3:], internal.TrackingOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if enable {
+		if len(options.Prefixes) > 0 && !options.BCast {
+			return nil, errors.New("PREFIX option requires BCAST mode to be enabled")
+		}
+		if options.OptIn && options.OptOut {
+			return nil, errors.New("you can't use both OPTIN and OPTOUT")
+		}
+		if options.BCast && (options.OptIn || options.OptOut) {
+			return nil, errors.New("OPTIN and OPTOUT are not compatible with BCAST")
+		}
+	}
+
+	if err = params.SetClientTracking(params.Context, params.Connection, enable, options); err != nil {
+		return nil, err
+	}
+	return []byte(constants.OkResponse), nil
+}
+
+func handleClientCaching(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 3 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+
+	var caching bool
+	switch strings.ToLower(params.Command[2]) {
+	case "yes":
+		caching = true
+	case "no":
+		caching = false
+	default:
+		return nil, errors.New("syntax error")
+	}
+
+	if err := params.SetClientCaching(params.Connection, caching); err != nil {
+		return nil, err
+	}
+	return []byte(constants.OkResponse), nil
+}
+
+func handleClientGetRedir(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 2 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	info := params.GetClientTracking(params.Connection)
+	if !info.Enabled {
+		return []byte(":-1\r\n"), nil
+	}
+	return []byte(fmt.Sprintf(":%d\r\n", info.Redirect)), nil
+}
+
+func handleClientTrackingInfo(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 2 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	protocol, _ := params.Context.Value("Protocol").(int)
+	return buildTrackingInfoResponse(params.GetClientTracking(params.Connection), protocol), nil
+}
+
+func handleClientList(params internal.HandlerFuncParams) ([]byte, error) {
+	clientType := ""
+	var ids []uint64
+
+	args := params.Command[2:]
+	switch {
+	case len(args) == 0:
+	case len(args) == 2 && strings.EqualFold(args[0], "type"):
+		t, err := getClientType(args[1])
+		if err != nil {
+			return nil, err
+		}
+		clientType = t
+	case len(args) >= 2 && strings.EqualFold(args[0], "id"):
+		for _, arg := range args[1:] {
+			id, err := strconv.ParseUint(arg, 10, 64)
+			if err != nil || id == 0 {
+				return nil, errors.New("invalid client ID")
+			}
+			ids = append(ids, id)
+		}
+	default:
+		return nil, errors.New("syntax error")
+	}
+
+	var list strings.Builder
+	for _, client := range params.ListClients() {
+		if ids != nil && !slices.Contains(ids, client.Id) {
+			continue
+		}
+		if clientType != "" && getClientInfoType(client) != clientType {
+			continue
+		}
+		list.WriteString(buildClientInfoLine(client))
+		list.WriteString("\n")
+	}
+
+	return []byte(fmt.Sprintf("$%d\r\n%s\r\n", list.Len(), list.String())), nil
+}
+
+func handleClientInfo(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 2 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	if params.Connection == nil {
+		return nil, errors.New("CLIENT INFO is not supported by the embedded connection")
+	}
+
+	id := params.GetConnectionInfo(params.Connection).Id
+	for _, client := range params.ListClients() {
+		if client.Id == id {
+			line := buildClientInfoLine(client) + "\n"
+			return []byte(fmt.Sprintf("$%d\r\n%s\r\n", len(line), line)), nil
+		}
+	}
+
+	return nil, errors.New("no such client")
+}
+
+func handleClientKill(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) < 3 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+
+	// The old form only accepts the address of the client and returns an error when there is no such client.
+	if len(params.Command) == 3 {
+		filter := internal.ClientKillFilter{Addr: params.Command[2]}
+		if params.KillClients(params.Connection, filter) == 0 {
+			return nil, errors.New("no such client")
+		}
+		return []byte(constants.OkResponse), nil
+	}
+
+	filter, err := getClientKillFilter(params.Command[2:], internal.ClientKillFilter{SkipMe: true})
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(fmt.Sprintf(":%d\r\n", params.KillClients(params.Connection, filter))), nil
+}
+
+func handleClientSetName(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 3 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	for _, c := range params.Command[2] {
+		if c < '!' || c > '~' {
+			return nil, errors.New("Client names cannot contain spaces, newlines or special characters.")
+		}
+	}
+	params.SetClientName(params.Connection, params.Command[2])
+	return []byte(constants.OkResponse), nil
+}
+
+func handleClientGetName(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 2 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	name, _ := params.Context.Value("ConnectionName").(string)
+	if name == "" {
+		return []byte("$-1\r\n"), nil
+	}
+	return []byte(fmt.Sprintf("$%d\r\n%s\r\n", len(name), name)), nil
+}
+
+func handleClientID(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 2 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	return []byte(fmt.Sprintf(":%d\r\n", params.GetConnectionInfo(params.Connection).Id)), nil
+}
+
+func handleClientPause(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) < 3 || len(params.Command) > 4 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+
+	timeout, err := strconv.ParseInt(params.Command[2], 10, 64)
+	if err != nil || timeout < 0 {
+		return nil, errors.New("timeout is not an integer or out of range")
+	}
+
+	writeOnly := false
+	if len(params.Command) == 4 {
+		switch strings.ToLower(params.Command[3]) {
+		case "write":
+			writeOnly = true
+		case "all":
+			writeOnly = false
+		default:
+			return nil, errors.New("syntax error")
+		}
+	}
+
+	params.PauseClients(time.Duration(timeout)*time.Millisecond, writeOnly)
+	return []byte(constants.OkResponse), nil
+}
+
+func handleClientUnpause(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 2 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	params.UnpauseClients()
+	return []byte(constants.OkResponse), nil
+}
+
+func handleClientNoEvict(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 3 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+
+	var noEvict bool
+	switch strings.ToLower(params.Command[2]) {
+	case "on":
+		noEvict = true
+	case "off":
+		noEvict = false
+	default:
+		return nil, errors.New("syntax error")
+	}
+
+	if err := params.SetClientNoEvict(params.Connection, noEvict); err != nil {
+		return nil, err
+	}
+	return []byte(constants.OkResponse), nil
+}
+
+func handleClientReply(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 3 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+
+	mode := strings.ToLower(params.Command[2])
+	if !slices.Contains([]string{"on", "off", "skip"}, mode) {
+		return nil, errors.New("syntax error")
+	}
+
+	if err := params.SetClientReply(params.Connection, mode); err != nil {
+		return nil, err
+	}
+	return []byte(constants.OkResponse), nil
+}
+
 func Commands() []internal.Command {
 	return []internal.Command{
 		{
@@ -277,5 +585,345 @@ and the other way around.`,
 			},
 			HandlerFunc: handleSwapDB,
 		},
+		{
+			Command:    "multi",
+			Module:     constants.ConnectionModule,
+			Categories: []string{constants.FastCategory, constants.TransactionCategory},
+			Description: `(MULTI) Start a transaction. 
+All the subsequent commands from the connection are queued until EXEC or DISCARD is called.`,
+			Sync: false,
+			KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+				return internal.KeyExtractionFuncResult{
+					Channels:  make([]string, 0),
+					ReadKeys:  make([]string, 0),
+					WriteKeys: make([]string, 0),
+				}, nil
+			},
+			HandlerFunc: handleMulti,
+		},
+		{
+			Command:    "exec",
+			Module:     constants.ConnectionModule,
+			Categories: []string{constants.SlowCategory, constants.TransactionCategory},
+			Description: `(EXEC) Atomically execute all the commands queued since MULTI. 
+Returns an array with the response of each command. If any command failed to be queued, 
+the transaction is discarded and none of the commands are executed. If any of the keys watched with WATCH 
+was modified, the transaction is aborted and a null array is returned.`,
+			Sync: false,
+			KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+				return internal.KeyExtractionFuncResult{
+					Channels:  make([]string, 0),
+					ReadKeys:  make([]string, 0),
+					WriteKeys: make([]string, 0),
+				}, nil
+			},
+			HandlerFunc: handleExec,
+		},
+		{
+			Command:     "discard",
+			Module:      constants.ConnectionModule,
+			Categories:  []string{constants.FastCategory, constants.TransactionCategory},
+			Description: `(DISCARD) Discard all the commands queued since MULTI and end the transaction.`,
+			Sync:        false,
+			KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+				return internal.KeyExtractionFuncResult{
+					Channels:  make([]string, 0),
+					ReadKeys:  make([]string, 0),
+					WriteKeys: make([]string, 0),
+				}, nil
+			},
+			HandlerFunc: handleDiscard,
+		},
+		{
+			Command:    "watch",
+			Module:     constants.ConnectionModule,
+			Categories: []string{constants.FastCategory, constants.TransactionCategory},
+			Description: `(WATCH key [key ...]) Watch the keys for the next transaction. 
+If any of the keys is modified, deleted or expires before EXEC is called, the transaction is aborted.`,
+			Sync: false,
+			KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+				if len(cmd) < 2 {
+					return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+				}
+				return internal.KeyExtractionFuncResult{
+					Channels:  make([]string, 0),
+					ReadKeys:  cmd[1:],
+					WriteKeys: make([]string, 0),
+				}, nil
+			},
+			HandlerFunc: handleWatch,
+		},
+		{
+			Command:     "unwatch",
+			Module:      constants.ConnectionModule,
+			Categories:  []string{constants.FastCategory, constants.TransactionCategory},
+			Description: `(UNWATCH) Forget all the keys watched by the connection.`,
+			Sync:        false,
+			KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+				return internal.KeyExtractionFuncResult{
+					Channels:  make([]string, 0),
+					ReadKeys:  make([]string, 0),
+					WriteKeys: make([]string, 0),
+				}, nil
+			},
+			HandlerFunc: handleUnwatch,
+		},
+		{
+			Command:     "client",
+			Module:      constants.ConnectionModule,
+			Categories:  []string{},
+			Description: "",
+			Sync:        false,
+			KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+				return internal.KeyExtractionFuncResult{
+					Channels:  make([]string, 0),
+					ReadKeys:  make([]string, 0),
+					WriteKeys: make([]string, 0),
+				}, nil
+			},
+			HandlerFunc: func(_ internal.HandlerFuncParams) ([]byte, error) {
+				return nil, errors.New("provide LIST, INFO, KILL, SETNAME, GETNAME, ID, PAUSE, UNPAUSE, NO-EVICT, REPLY, " +
+					"TRACKING, CACHING, GETREDIR or TRACKINGINFO subcommand")
+			},
+			SubCommands: []internal.SubCommand{
+				{
+					Command:    "list",
+					Module:     constants.ConnectionModule,
+					Categories: []string{constants.AdminCategory, constants.ConnectionCategory, constants.SlowCategory, constants.DangerousCategory},
+					Description: `(CLIENT LIST [TYPE NORMAL|MASTER|REPLICA|PUBSUB] [ID client-id [client-id ...]])
+Returns the information about the TCP clients, one client per line. Each line contains the client id, address,
+local address, name, age and idle time in seconds, flags, database, number of channel and pattern subscriptions,
+number of queued commands in a transaction (-1 when there is none), query and output buffer sizes, ACL user,
+protocol version and last command. The list can be filtered by client type or by client id.`,
+					Sync: false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels:  make([]string, 0),
+							ReadKeys:  make([]string, 0),
+							WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleClientList,
+				},
+				{
+					Command:     "info",
+					Module:      constants.ConnectionModule,
+					Categories:  []string{constants.ConnectionCategory, constants.SlowCategory},
+					Description: `(CLIENT INFO) Returns the information about the connection in the same format as CLIENT LIST.`,
+					Sync:        false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels:  make([]string, 0),
+							ReadKeys:  make([]string, 0),
+							WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleClientInfo,
+				},
+				{
+					Command:    "kill",
+					Module:     constants.ConnectionModule,
+					Categories: []string{constants.AdminCategory, constants.ConnectionCategory, constants.SlowCategory, constants.DangerousCategory},
+					Description: `(CLIENT KILL addr | CLIENT KILL [ID client-id] [ADDR addr] [LADDR laddr] [USER username] [TYPE NORMAL|MASTER|REPLICA|PUBSUB] [SKIPME YES|NO])
+Closes the connections of the clients. The old form closes the connection with the address and returns an error when
+there is no such client. The new form closes all the connections that match all the filters and returns the number
+of closed connections. The connection that calls the command is skipped unless SKIPME NO is provided.`,
+					Sync: false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels:  make([]string, 0),
+							ReadKeys:  make([]string, 0),
+							WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleClientKill,
+				},
+				{
+					Command:     "setname",
+					Module:      constants.ConnectionModule,
+					Categories:  []string{constants.ConnectionCategory, constants.SlowCategory},
+					Description: `(CLIENT SETNAME name) Sets the name of the connection. An empty name removes it.`,
+					Sync:        false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels:  make([]string, 0),
+							ReadKeys:  make([]string, 0),
+							WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleClientSetName,
+				},
+				{
+					Command:     "getname",
+					Module:      constants.ConnectionModule,
+					Categories:  []string{constants.ConnectionCategory, constants.SlowCategory},
+					Description: `(CLIENT GETNAME) Returns the name of the connection, or null if it has none.`,
+					Sync:        false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels:  make([]string, 0),
+							ReadKeys:  make([]string, 0),
+							WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleClientGetName,
+				},
+				{
+					Command:     "id",
+					Module:      constants.ConnectionModule,
+					Categories:  []string{constants.ConnectionCategory, constants.SlowCategory},
+					Description: `(CLIENT ID) Returns the id of the connection.`,
+					Sync:        false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels:  make([]string, 0),
+							ReadKeys:  make([]string, 0),
+							WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleClientID,
+				},
+				{
+					Command:    "pause",
+					Module:     constants.ConnectionModule,
+					Categories: []string{constants.AdminCategory, constants.ConnectionCategory, constants.SlowCategory, constants.DangerousCategory},
+					Description: `(CLIENT PAUSE timeout [WRITE|ALL])
+Suspends the commands of all the TCP clients for timeout milliseconds. With WRITE, only the commands that write to
+the keyspace are suspended, including EXEC when the transaction contains one. CLIENT commands are never suspended.`,
+					Sync: false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels:  make([]string, 0),
+							ReadKeys:  make([]string, 0),
+							WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleClientPause,
+				},
+				{
+					Command:     "unpause",
+					Module:      constants.ConnectionModule,
+					Categories:  []string{constants.AdminCategory, constants.ConnectionCategory, constants.SlowCategory, constants.DangerousCategory},
+					Description: `(CLIENT UNPAUSE) Resumes the commands suspended by CLIENT PAUSE.`,
+					Sync:        false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels:  make([]string, 0),
+							ReadKeys:  make([]string, 0),
+							WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleClientUnpause,
+				},
+				{
+					Command:    "no-evict",
+					Module:     constants.ConnectionModule,
+					Categories: []string{constants.AdminCategory, constants.ConnectionCategory, constants.SlowCategory, constants.DangerousCategory},
+					Description: `(CLIENT NO-EVICT ON|OFF) Sets the no-evict flag of the connection.
+SugarDB does not evict clients, so the flag is only reported by CLIENT LIST and CLIENT INFO.`,
+					Sync: false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels:  make([]string, 0),
+							ReadKeys:  make([]string, 0),
+							WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleClientNoEvict,
+				},
+				{
+					Command:    "reply",
+					Module:     constants.ConnectionModule,
+					Categories: []string{constants.ConnectionCategory, constants.SlowCategory},
+					Description: `(CLIENT REPLY ON|OFF|SKIP) Sets whether the server replies to the commands of the connection.
+OFF stops the replies until CLIENT REPLY ON is called. SKIP skips the reply to the next command.`,
+					Sync: false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels:  make([]string, 0),
+							ReadKeys:  make([]string, 0),
+							WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleClientReply,
+				},
+				{
+					Command:    "tracking",
+					Module:     constants.ConnectionModule,
+					Categories: []string{constants.ConnectionCategory, constants.SlowCategory},
+					Description: `(CLIENT TRACKING ON|OFF [REDIRECT client-id] [PREFIX prefix [PREFIX prefix ...]] [BCAST] [OPTIN] [OPTOUT] [NOLOOP])
+Enable or disable client side caching for the connection. The server remembers the keys read by the connection
+and sends an invalidation message when they're modified. RESP3 connections receive invalidate push messages.
+With REDIRECT, the messages are sent to the connection with the client id instead. A RESP2 connection receives
+them when it's subscribed to the __redis__:invalidate channel. In BCAST mode, the messages are sent for all the
+keys that match the prefixes, whether they were read or not. With OPTIN, only the keys read right after
+CLIENT CACHING YES are tracked. With OPTOUT, the keys read right after CLIENT CACHING NO are not tracked.
+With NOLOOP, the connection is not notified about the keys it modifies itself.`,
+					Sync: false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						if len(cmd) < 3 {
+							return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+						}
+						return internal.KeyExtractionFuncResult{
+							Channels:  make([]string, 0),
+							ReadKeys:  make([]string, 0),
+							WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleClientTracking,
+				},
+				{
+					Command:    "caching",
+					Module:     constants.ConnectionModule,
+					Categories: []string{constants.ConnectionCategory, constants.FastCategory},
+					Description: `(CLIENT CACHING YES|NO) Set whether the keys read by the next command are tracked.
+YES is only valid in OPTIN mode and NO is only valid in OPTOUT mode.`,
+					Sync: false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						if len(cmd) != 3 {
+							return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+						}
+						return internal.KeyExtractionFuncResult{
+							Channels:  make([]string, 0),
+							ReadKeys:  make([]string, 0),
+							WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleClientCaching,
+				},
+				{
+					Command:    "getredir",
+					Module:     constants.ConnectionModule,
+					Categories: []string{constants.ConnectionCategory, constants.FastCategory},
+					Description: `(CLIENT GETREDIR) Returns the id of the connection that receives the invalidation messages.
+Returns 0 if the messages are not redirected and -1 if tracking is off.`,
+					Sync: false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels:  make([]string, 0),
+							ReadKeys:  make([]string, 0),
+							WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleClientGetRedir,
+				},
+				{
+					Command:    "trackinginfo",
+					Module:     constants.ConnectionModule,
+					Categories: []string{constants.ConnectionCategory, constants.SlowCategory},
+					Description: `(CLIENT TRACKINGINFO) Returns the client side caching state of the connection:
+its tracking flags, the id of the connection that receives the invalidation messages and the tracked prefixes.`,
+					Sync: false,
+					KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+						return internal.KeyExtractionFuncResult{
+							Channels:  make([]string, 0),
+							ReadKeys:  make([]string, 0),
+							WriteKeys: make([]string, 0),
+						}, nil
+					},
+					HandlerFunc: handleClientTrackingInfo,
+				},
+			},
+		},
 	}
 }
@@ -22,10 +22,13 @@ import (
 	"errors"
 	"fmt"
 	"github.com/echovault/sugardb/internal/modules/connection"
+	"io"
+	"net"
 	"reflect"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/echovault/sugardb/internal"
 	"github.com/echovault/sugardb/internal/config"
@@ -997,4 +1000,1037 @@ func Test_Connection(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("Test_HandleTransaction", func(t *testing.T) {
+		t.Parallel()
+
+		port, err := internal.GetFreePort()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		mockServer, err := setUpServer(port, false, "")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		go func() {
+			mockServer.Start()
+		}()
+		t.Cleanup(func() {
+			mockServer.ShutDown()
+		})
+
+		type step struct {
+			command   []string
+			want      string   // The expected response when the response is not an array.
+			wantArray []string // The expected elements when the response is an array.
+			wantErr   string   // The expected error substring when the response is an error.
+		}
+
+		tests := []struct {
+			name  string
+			steps []step
+		}{
+			{
+				name: "1. Queue commands and execute them with EXEC",
+				steps: []step{
+					{command: []string{"MULTI"}, want: "OK"},
+					{command: []string{"SET", "TransactionKey1", "value1"}, want: "QUEUED"},
+					{command: []string{"INCR", "TransactionKey2"}, want: "QUEUED"},
+					{command: []string{"GET", "TransactionKey1"}, want: "QUEUED"},
+					{command: []string{"EXEC"}, wantArray: []string{"OK", "1", "value1"}},
+					{command: []string{"GET", "TransactionKey2"}, want: "1"},
+				},
+			},
+			{
+				name: "2. A command that fails during EXEC does not stop the other commands",
+				steps: []step{
+					{command: []string{"SET", "TransactionKey3", "value3"}, want: "OK"},
+					{command: []string{"MULTI"}, want: "OK"},
+					{command: []string{"INCR", "TransactionKey3"}, want: "QUEUED"},
+					{command: []string{"SET", "TransactionKey4", "value4"}, want: "QUEUED"},
+					{command: []string{"EXEC"}, wantArray: []string{"value is not an integer or out of range", "OK"}},
+					{command: []string{"GET", "TransactionKey4"}, want: "value4"},
+				},
+			},
+			{
+				name: "3. An unknown command aborts the transaction",
+				steps: []step{
+					{command: []string{"MULTI"}, want: "OK"},
+					{command: []string{"SET", "TransactionKey5", "value5"}, want: "QUEUED"},
+					{command: []string{"UNKNOWN", "TransactionKey5"}, wantErr: "command UNKNOWN not supported"},
+					{command: []string{"EXEC"}, wantErr: "EXECABORT Transaction discarded because of previous errors"},
+					{command: []string{"GET", "TransactionKey5"}, want: ""},
+				},
+			},
+			{
+				name: "4. A command with the wrong number of arguments aborts the transaction",
+				steps: []step{
+					{command: []string{"MULTI"}, want: "OK"},
+					{command: []string{"SET", "TransactionKey6", "value6"}, want: "QUEUED"},
+					{command: []string{"SET", "TransactionKey6"}, wantErr: constants.WrongArgsResponse},
+					{command: []string{"EXEC"}, wantErr: "EXECABORT Transaction discarded because of previous errors"},
+					{command: []string{"GET", "TransactionKey6"}, want: ""},
+				},
+			},
+			{
+				name: "5. DISCARD drops the queued commands",
+				steps: []step{
+					{command: []string{"MULTI"}, want: "OK"},
+					{command: []string{"SET", "TransactionKey7", "value7"}, want: "QUEUED"},
+					{command: []string{"DISCARD"}, want: "OK"},
+					{command: []string{"GET", "TransactionKey7"}, want: ""},
+				},
+			},
+			{
+				name: "6. Return error when MULTI is nested",
+				steps: []step{
+					{command: []string{"MULTI"}, want: "OK"},
+					{command: []string{"MULTI"}, wantErr: "MULTI calls can not be nested"},
+					{command: []string{"SET", "TransactionKey8", "value8"}, want: "QUEUED"},
+					{command: []string{"EXEC"}, wantArray: []string{"OK"}},
+				},
+			},
+			{
+				name: "7. Return error when EXEC or DISCARD is called without MULTI",
+				steps: []step{
+					{command: []string{"EXEC"}, wantErr: "EXEC without MULTI"},
+					{command: []string{"DISCARD"}, wantErr: "DISCARD without MULTI"},
+				},
+			},
+			{
+				name: "8. Return error when MULTI, EXEC or DISCARD have the wrong number of arguments",
+				steps: []step{
+					{command: []string{"MULTI", "arg"}, wantErr: constants.WrongArgsResponse},
+					{command: []string{"EXEC", "arg"}, wantErr: constants.WrongArgsResponse},
+					{command: []string{"DISCARD", "arg"}, wantErr: constants.WrongArgsResponse},
+				},
+			},
+			{
+				name: "9. SELECT changes the database of the commands queued after it",
+				steps: []step{
+					{command: []string{"MULTI"}, want: "OK"},
+					{command: []string{"SELECT", "1"}, want: "QUEUED"},
+					{command: []string{"SET", "TransactionKey9", "value9"}, want: "QUEUED"},
+					{command: []string{"SELECT", "0"}, want: "QUEUED"},
+					{command: []string{"GET", "TransactionKey9"}, want: "QUEUED"},
+					{command: []string{"SELECT", "1"}, want: "QUEUED"},
+					{command: []string{"EXEC"}, wantArray: []string{"OK", "OK", "OK", "", "OK"}},
+					{command: []string{"GET", "TransactionKey9"}, want: "value9"},
+				},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				conn, err := internal.GetConnection("localhost", port)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				defer func() {
+					_ = conn.Close()
+				}()
+				client := resp.NewConn(conn)
+
+				for _, step := range test.steps {
+					command := make([]resp.Value, len(step.command))
+					for i, token := range step.command {
+						command[i] = resp.StringValue(token)
+					}
+					if err = client.WriteArray(command); err != nil {
+						t.Error(err)
+						return
+					}
+					res, _, err := client.ReadValue()
+					if err != nil {
+						t.Error(err)
+						return
+					}
+
+					switch {
+					case step.wantErr != "":
+						if res.Error() == nil || !strings.Contains(res.Error().Error(), step.wantErr) {
+							t.Errorf("%v: expected error \"%s\", got %+v", step.command, step.wantErr, res)
+						}
+					case step.wantArray != nil:
+						if len(res.Array()) != len(step.wantArray) {
+							t.Errorf("%v: expected %d responses, got %d", step.command, len(step.wantArray), len(res.Array()))
+							return
+						}
+						for i, element := range res.Array() {
+							if element.Error() != nil {
+								if !strings.Contains(element.Error().Error(), step.wantArray[i]) {
+									t.Errorf("%v: expected error \"%s\" at index %d, got \"%s\"",
+										step.command, step.wantArray[i], i, element.Error().Error())
+								}
+								continue
+							}
+							if element.String() != step.wantArray[i] {
+								t.Errorf("%v: expected \"%s\" at index %d, got \"%s\"",
+									step.command, step.wantArray[i], i, element.String())
+							}
+						}
+					default:
+						if res.String() != step.want {
+							t.Errorf("%v: expected response \"%s\", got \"%s\"", step.command, step.want, res.String())
+						}
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("Test_HandleWatch", func(t *testing.T) {
+		t.Parallel()
+
+		port, err := internal.GetFreePort()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		mockServer, err := setUpServer(port, false, "")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		go func() {
+			mockServer.Start()
+		}()
+		t.Cleanup(func() {
+			mockServer.ShutDown()
+		})
+
+		type step struct {
+			other     bool // True if the command is sent from a second connection.
+			command   []string
+			want      string   // The expected response when the response is not an array.
+			wantArray []string // The expected elements when the response is an array.
+			wantNull  bool     // True if the response is expected to be a null array.
+			wantErr   string   // The expected error substring when the response is an error.
+		}
+
+		tests := []struct {
+			name  string
+			steps []step
+		}{
+			{
+				name: "1. Execute the transaction when the watched keys are not modified",
+				steps: []step{
+					{command: []string{"SET", "WatchKey1", "value1"}, want: "OK"},
+					{command: []string{"WATCH", "WatchKey1", "WatchKey2"}, want: "OK"},
+					{other: true, command: []string{"GET", "WatchKey1"}, want: "value1"},
+					{command: []string{"MULTI"}, want: "OK"},
+					{command: []string{"SET", "WatchKey1", "value2"}, want: "QUEUED"},
+					{command: []string{"EXEC"}, wantArray: []string{"OK"}},
+					{command: []string{"GET", "WatchKey1"}, want: "value2"},
+				},
+			},
+			{
+				name: "2. Abort the transaction when a watched key is modified by another connection",
+				steps: []step{
+					{command: []string{"SET", "WatchKey3", "value1"}, want: "OK"},
+					{command: []string{"WATCH", "WatchKey3"}, want: "OK"},
+					{other: true, command: []string{"SET", "WatchKey3", "value2"}, want: "OK"},
+					{command: []string{"MULTI"}, want: "OK"},
+					{command: []string{"SET", "WatchKey3", "value3"}, want: "QUEUED"},
+					{command: []string{"EXEC"}, wantNull: true},
+					{command: []string{"GET", "WatchKey3"}, want: "value2"},
+					// EXEC unwatches the keys, so the next transaction is executed.
+					{command: []string{"MULTI"}, want: "OK"},
+					{command: []string{"SET", "WatchKey3", "value3"}, want: "QUEUED"},
+					{command: []string{"EXEC"}, wantArray: []string{"OK"}},
+				},
+			},
+			{
+				name: "3. Abort the transaction when a watched key is deleted",
+				steps: []step{
+					{command: []string{"SET", "WatchKey4", "value1"}, want: "OK"},
+					{command: []string{"WATCH", "WatchKey4"}, want: "OK"},
+					{other: true, command: []string{"DEL", "WatchKey4"}, want: "1"},
+					{command: []string{"MULTI"}, want: "OK"},
+					{command: []string{"SET", "WatchKey5", "value1"}, want: "QUEUED"},
+					{command: []string{"EXEC"}, wantNull: true},
+					{command: []string{"GET", "WatchKey5"}, want: ""},
+				},
+			},
+			{
+				name: "4. Abort the transaction when a watched key that did not exist is created",
+				steps: []step{
+					{command: []string{"WATCH", "WatchKey6"}, want: "OK"},
+					{other: true, command: []string{"SET", "WatchKey6", "1"}, want: "OK"},
+					{command: []string{"MULTI"}, want: "OK"},
+					{command: []string{"INCR", "WatchKey6"}, want: "QUEUED"},
+					{command: []string{"EXEC"}, wantNull: true},
+					{command: []string{"GET", "WatchKey6"}, want: "1"},
+				},
+			},
+			{
+				name: "5. Abort the transaction when a watched key expires",
+				steps: []step{
+					{command: []string{"SET", "WatchKey7", "value1"}, want: "OK"},
+					{command: []string{"WATCH", "WatchKey7"}, want: "OK"},
+					{other: true, command: []string{"EXPIRE", "WatchKey7", "100"}, want: "1"},
+					{command: []string{"MULTI"}, want: "OK"},
+					{command: []string{"SET", "WatchKey8", "value1"}, want: "QUEUED"},
+					{command: []string{"EXEC"}, wantNull: true},
+				},
+			},
+			{
+				name: "6. UNWATCH and DISCARD forget the watched keys",
+				steps: []step{
+					{command: []string{"WATCH", "WatchKey9"}, want: "OK"},
+					{command: []string{"UNWATCH"}, want: "OK"},
+					{other: true, command: []string{"SET", "WatchKey9", "value1"}, want: "OK"},
+					{command: []string{"MULTI"}, want: "OK"},
+					{command: []string{"SET", "WatchKey9", "value2"}, want: "QUEUED"},
+					{command: []string{"EXEC"}, wantArray: []string{"OK"}},
+					{command: []string{"WATCH", "WatchKey9"}, want: "OK"},
+					{command: []string{"MULTI"}, want: "OK"},
+					{command: []string{"DISCARD"}, want: "OK"},
+					{other: true, command: []string{"SET", "WatchKey9", "value3"}, want: "OK"},
+					{command: []string{"MULTI"}, want: "OK"},
+					{command: []string{"SET", "WatchKey9", "value4"}, want: "QUEUED"},
+					{command: []string{"EXEC"}, wantArray: []string{"OK"}},
+				},
+			},
+			{
+				name: "7. Return error when WATCH is called inside MULTI",
+				steps: []step{
+					{command: []string{"MULTI"}, want: "OK"},
+					{command: []string{"WATCH", "WatchKey10"}, wantErr: "WATCH inside MULTI is not allowed"},
+					{command: []string{"SET", "WatchKey10", "value1"}, want: "QUEUED"},
+					{command: []string{"EXEC"}, wantArray: []string{"OK"}},
+				},
+			},
+			{
+				name: "8. Return error when WATCH or UNWATCH have the wrong number of arguments",
+				steps: []step{
+					{command: []string{"WATCH"}, wantErr: constants.WrongArgsResponse},
+					{command: []string{"UNWATCH", "arg"}, wantErr: constants.WrongArgsResponse},
+				},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				clients := make([]*resp.Conn, 2)
+				for i := range clients {
+					conn, err := internal.GetConnection("localhost", port)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					defer func() {
+						_ = conn.Close()
+					}()
+					clients[i] = resp.NewConn(conn)
+				}
+
+				for _, step := range test.steps {
+					client := clients[0]
+					if step.other {
+						client = clients[1]
+					}
+
+					command := make([]resp.Value, len(step.command))
+					for i, token := range step.command {
+						command[i] = resp.StringValue(token)
+					}
+					if err = client.WriteArray(command); err != nil {
+						t.Error(err)
+						return
+					}
+					res, _, err := client.ReadValue()
+					if err != nil {
+						t.Error(err)
+						return
+					}
+
+					switch {
+					case step.wantErr != "":
+						if res.Error() == nil || !strings.Contains(res.Error().Error(), step.wantErr) {
+							t.Errorf("%v: expected error \"%s\", got %+v", step.command, step.wantErr, res)
+						}
+					case step.wantNull:
+						if !res.IsNull() {
+							t.Errorf("%v: expected null response, got %+v", step.command, res)
+						}
+					case step.wantArray != nil:
+						if len(res.Array()) != len(step.wantArray) {
+							t.Errorf("%v: expected %d responses, got %d", step.command, len(step.wantArray), len(res.Array()))
+							return
+						}
+						for i, element := range res.Array() {
+							if element.String() != step.wantArray[i] {
+								t.Errorf("%v: expected \"%s\" at index %d, got \"%s\"",
+									step.command, step.wantArray[i], i, element.String())
+							}
+						}
+					default:
+						if res.String() != step.want {
+							t.Errorf("%v: expected response \"%s\", got \"%s\"", step.command, step.want, res.String())
+						}
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("Test_HandleClientTracking", func(t *testing.T) {
+		t.Parallel()
+
+		port, err := internal.GetFreePort()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		mockServer, err := setUpServer(port, false, "")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		go func() {
+			mockServer.Start()
+		}()
+		t.Cleanup(func() {
+			mockServer.ShutDown()
+		})
+
+		type step struct {
+			client     int      // The index of the client that sends the command or receives the push message.
+			command    []string // The command to send. {id1} is replaced with the id of the second client.
+			want       string   // The expected response to the command.
+			wantPush   string   // The expected push message when no command is sent.
+			wantNoPush bool     // True if the client is expected to receive nothing.
+			close      bool     // True if the client closes its connection.
+		}
+
+		invalidate := func(keys ...string) string {
+			res := fmt.Sprintf(">2\r\n$10\r\ninvalidate\r\n*%d\r\n", len(keys))
+			for _, key := range keys {
+				res += fmt.Sprintf("$%d\r\n%s\r\n", len(key), key)
+			}
+			return res
+		}
+
+		tests := []struct {
+			name      string
+			protocols []int // The protocol of each client.
+			steps     []step
+		}{
+			{
+				name:      "1. Invalidate the keys read by the client in the default mode",
+				protocols: []int{3, 3},
+				steps: []step{
+					{client: 1, command: []string{"SET", "TrackingKey1", "value1"}, want: "+OK\r\n"},
+					{client: 0, command: []string{"CLIENT", "TRACKING", "ON"}, want: "+OK\r\n"},
+					{client: 0, command: []string{"GET", "TrackingKey1"}, want: "+value1\r\n"},
+					{client: 1, command: []string{"SET", "TrackingKey1", "value2"}, want: "+OK\r\n"},
+					{client: 0, wantPush: invalidate("TrackingKey1")},
+					// The key is not tracked anymore until it's read again.
+					{client: 1, command: []string{"SET", "TrackingKey1", "value3"}, want: "+OK\r\n"},
+					{client: 0, wantNoPush: true},
+					{client: 0, command: []string{"GET", "TrackingKey1"}, want: "+value3\r\n"},
+					{client: 1, command: []string{"DEL", "TrackingKey1"}, want: ":1\r\n"},
+					{client: 0, wantPush: invalidate("TrackingKey1")},
+				},
+			},
+			{
+				name:      "2. Don't invalidate the keys after tracking is turned off",
+				protocols: []int{3, 3},
+				steps: []step{
+					{client: 0, command: []string{"CLIENT", "TRACKING", "ON"}, want: "+OK\r\n"},
+					{client: 0, command: []string{"GET", "TrackingKey2"}, want: "$-1\r\n"},
+					{client: 0, command: []string{"CLIENT", "TRACKING", "OFF"}, want: "+OK\r\n"},
+					{client: 1, command: []string{"SET", "TrackingKey2", "value1"}, want: "+OK\r\n"},
+					{client: 0, wantNoPush: true},
+				},
+			},
+			{
+				name:      "3. Invalidate the keys that match the prefixes in BCAST mode",
+				protocols: []int{3, 3},
+				steps: []step{
+					{client: 0, command: []string{"CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "bcast:"}, want: "+OK\r\n"},
+					{client: 1, command: []string{"SET", "bcast:TrackingKey3", "value1"}, want: "+OK\r\n"},
+					{client: 0, wantPush: invalidate("bcast:TrackingKey3")},
+					{client: 1, command: []string{"SET", "TrackingKey3", "value1"}, want: "+OK\r\n"},
+					{client: 0, wantNoPush: true},
+				},
+			},
+			{
+				name:      "4. Only track the keys read after CLIENT CACHING YES in OPTIN mode",
+				protocols: []int{3, 3},
+				steps: []step{
+					{client: 0, command: []string{"CLIENT", "TRACKING", "ON", "OPTIN"}, want: "+OK\r\n"},
+					{client: 0, command: []string{"GET", "TrackingKey4"}, want: "$-1\r\n"},
+					{client: 0, command: []string{"CLIENT", "CACHING", "YES"}, want: "+OK\r\n"},
+					{client: 0, command: []string{"GET", "TrackingKey5"}, want: "$-1\r\n"},
+					{client: 0, command: []string{"GET", "TrackingKey6"}, want: "$-1\r\n"},
+					{client: 1, command: []string{"SET", "TrackingKey4", "value1"}, want: "+OK\r\n"},
+					{client: 1, command: []string{"SET", "TrackingKey6", "value1"}, want: "+OK\r\n"},
+					{client: 1, command: []string{"SET", "TrackingKey5", "value1"}, want: "+OK\r\n"},
+					{client: 0, wantPush: invalidate("TrackingKey5")},
+					{client: 0, wantNoPush: true},
+				},
+			},
+			{
+				name:      "5. Don't invalidate the keys modified by the client itself in NOLOOP mode",
+				protocols: []int{3, 3},
+				steps: []step{
+					{client: 0, command: []string{"CLIENT", "TRACKING", "ON", "NOLOOP"}, want: "+OK\r\n"},
+					{client: 0, command: []string{"GET", "TrackingKey7"}, want: "$-1\r\n"},
+					{client: 0, command: []string{"SET", "TrackingKey7", "value1"}, want: "+OK\r\n"},
+					{client: 0, wantNoPush: true},
+					{client: 0, command: []string{"GET", "TrackingKey7"}, want: "+value1\r\n"},
+					{client: 1, command: []string{"SET", "TrackingKey7", "value2"}, want: "+OK\r\n"},
+					{client: 0, wantPush: invalidate("TrackingKey7")},
+				},
+			},
+			{
+				name:      "6. Redirect the invalidation messages to a RESP2 client subscribed to the invalidation channel",
+				protocols: []int{2, 2},
+				steps: []step{
+					{
+						client:  1,
+						command: []string{"SUBSCRIBE", "__redis__:invalidate"},
+						want:    "*3\r\n$9\r\nsubscribe\r\n$20\r\n__redis__:invalidate\r\n:1\r\n",
+					},
+					{client: 0, command: []string{"CLIENT", "TRACKING", "ON", "REDIRECT", "{id1}"}, want: "+OK\r\n"},
+					{client: 0, command: []string{"CLIENT", "GETREDIR"}, want: ":{id1}\r\n"},
+					{client: 0, command: []string{"GET", "TrackingKey8"}, want: "$-1\r\n"},
+					{client: 0, command: []string{"SET", "TrackingKey8", "value1"}, want: "+OK\r\n"},
+					{
+						client:   1,
+						wantPush: "*3\r\n$7\r\nmessage\r\n$20\r\n__redis__:invalidate\r\n*1\r\n$12\r\nTrackingKey8\r\n",
+					},
+					{client: 0, wantNoPush: true},
+				},
+			},
+			{
+				name:      "7. Notify a RESP3 client when the client it redirects to is closed",
+				protocols: []int{3, 3},
+				steps: []step{
+					{client: 0, command: []string{"CLIENT", "TRACKING", "ON", "REDIRECT", "{id1}"}, want: "+OK\r\n"},
+					{client: 1, close: true},
+					{client: 0, wantPush: ">2\r\n$21\r\ntracking-redir-broken\r\n:{id1}\r\n"},
+					{
+						client:  0,
+						command: []string{"CLIENT", "TRACKINGINFO"},
+						want: "%3\r\n+flags\r\n*2\r\n$2\r\non\r\n$15\r\nbroken_redirect\r\n" +
+							"+redirect\r\n:{id1}\r\n+prefixes\r\n*0\r\n",
+					},
+				},
+			},
+			{
+				name:      "8. Return the tracking state of the client",
+				protocols: []int{2, 3},
+				steps: []step{
+					{client: 0, command: []string{"CLIENT", "GETREDIR"}, want: ":-1\r\n"},
+					{
+						client:  0,
+						command: []string{"CLIENT", "TRACKINGINFO"},
+						want:    "*6\r\n+flags\r\n*1\r\n$3\r\noff\r\n+redirect\r\n:-1\r\n+prefixes\r\n*0\r\n",
+					},
+					{
+						client:  0,
+						command: []string{"CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "a", "PREFIX", "b", "NOLOOP"},
+						want:    "+OK\r\n",
+					},
+					{client: 0, command: []string{"CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "c"}, want: "+OK\r\n"},
+					{client: 0, command: []string{"CLIENT", "GETREDIR"}, want: ":0\r\n"},
+					{
+						client:  0,
+						command: []string{"CLIENT", "TRACKINGINFO"},
+						want: "*6\r\n+flags\r\n*2\r\n$2\r\non\r\n$5\r\nbcast\r\n+redirect\r\n:0\r\n" +
+							"+prefixes\r\n*3\r\n$1\r\na\r\n$1\r\nb\r\n$1\r\nc\r\n",
+					},
+					{client: 1, command: []string{"CLIENT", "TRACKING", "ON", "OPTOUT"}, want: "+OK\r\n"},
+					{client: 1, command: []string{"CLIENT", "CACHING", "NO"}, want: "+OK\r\n"},
+					{
+						client:  1,
+						command: []string{"CLIENT", "TRACKINGINFO"},
+						want: "%3\r\n+flags\r\n*3\r\n$2\r\non\r\n$6\r\noptout\r\n$10\r\ncaching-no\r\n" +
+							"+redirect\r\n:0\r\n+prefixes\r\n*0\r\n",
+					},
+				},
+			},
+			{
+				name:      "9. Send a null invalidation message when the keys are flushed",
+				protocols: []int{3, 3},
+				steps: []step{
+					{client: 0, command: []string{"CLIENT", "TRACKING", "ON"}, want: "+OK\r\n"},
+					{client: 1, command: []string{"FLUSHALL"}, want: "+OK\r\n"},
+					{client: 0, wantPush: ">2\r\n$10\r\ninvalidate\r\n_\r\n"},
+				},
+			},
+			{
+				name:      "10. Return errors for invalid options",
+				protocols: []int{2, 2},
+				steps: []step{
+					{
+						client:  0,
+						command: []string{"CLIENT", "TRACKING", "ON", "PREFIX", "a"},
+						want:    "-Error PREFIX option requires BCAST mode to be enabled\r\n",
+					},
+					{
+						client:  0,
+						command: []string{"CLIENT", "TRACKING", "ON", "OPTIN", "OPTOUT"},
+						want:    "-Error you can't use both OPTIN and OPTOUT\r\n",
+					},
+					{
+						client:  0,
+						command: []string{"CLIENT", "TRACKING", "ON", "BCAST", "OPTIN"},
+						want:    "-Error OPTIN and OPTOUT are not compatible with BCAST\r\n",
+					},
+					{
+						client:  0,
+						command: []string{"CLIENT", "TRACKING", "ON", "REDIRECT", "1000000"},
+						want:    "-Error the client ID you want redirect to does not exist\r\n",
+					},
+					{
+						client:  0,
+						command: []string{"CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "pre", "PREFIX", "prefix"},
+						want: "-Error prefix 'prefix' overlaps with an existing prefix 'pre'. " +
+							"Prefixes for a single client must not overlap\r\n",
+					},
+					{
+						client:  0,
+						command: []string{"CLIENT", "TRACKING", "ENABLE"},
+						want:    "-Error syntax error\r\n",
+					},
+					{
+						client:  0,
+						command: []string{"CLIENT", "TRACKING", "ON", "UNKNOWN"},
+						want:    "-Error unknown keyword UNKNOWN\r\n",
+					},
+					{
+						client:  0,
+						command: []string{"CLIENT", "CACHING", "YES"},
+						want: "-Error CLIENT CACHING can be called only when the client is in tracking mode with " +
+							"OPTIN or OPTOUT mode enabled\r\n",
+					},
+					{client: 0, command: []string{"CLIENT", "TRACKING", "ON", "OPTIN"}, want: "+OK\r\n"},
+					{
+						client:  0,
+						command: []string{"CLIENT", "CACHING", "NO"},
+						want:    "-Error CLIENT CACHING NO is only valid when tracking is enabled in OPTOUT mode\r\n",
+					},
+					{
+						client:  0,
+						command: []string{"CLIENT", "TRACKING", "ON", "BCAST"},
+						want: "-Error you can't switch BCAST mode on/off before disabling tracking for this client, " +
+							"and then re-enabling it with a different mode\r\n",
+					},
+					{
+						client:  0,
+						command: []string{"CLIENT", "TRACKING"},
+						want:    fmt.Sprintf("-Error %s\r\n", constants.WrongArgsResponse),
+					},
+				},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				conns := make([]net.Conn, len(test.protocols))
+				readers := make([]*bufio.Reader, len(test.protocols))
+				ids := make([]string, len(test.protocols))
+				for i, protocol := range test.protocols {
+					conn, err := internal.GetConnection("localhost", port)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					defer func() {
+						_ = conn.Close()
+					}()
+					conns[i] = conn
+					readers[i] = bufio.NewReader(conn)
+
+					// Switch to the protocol of the client and get its id from the HELLO response.
+					if _, err = conn.Write(internal.EncodeCommand([]string{"HELLO", strconv.Itoa(protocol)})); err != nil {
+						t.Error(err)
+						return
+					}
+					res, err := readFrame(readers[i])
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					id := res[strings.Index(res, "+id\r\n:")+len("+id\r\n:"):]
+					ids[i] = id[:strings.Index(id, "\r\n")]
+				}
+
+				for _, step := range test.steps {
+					conn, reader := conns[step.client], readers[step.client]
+					switch {
+					case step.close:
+						_ = conn.Close()
+					case step.wantNoPush:
+						_ = conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+						res, err := readFrame(reader)
+						if err == nil {
+							t.Errorf("client %d: expected no push message, got %q", step.client, res)
+						}
+						// The reader is not reused after a timeout, so start a fresh one.
+						readers[step.client] = bufio.NewReader(conn)
+						_ = conn.SetReadDeadline(time.Time{})
+					case step.wantPush != "":
+						_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+						res, err := readFrame(reader)
+						if err != nil {
+							t.Errorf("client %d: expected push message, got error %v", step.client, err)
+							return
+						}
+						_ = conn.SetReadDeadline(time.Time{})
+						want := strings.ReplaceAll(step.wantPush, "{id1}", ids[1])
+						if res != want {
+							t.Errorf("client %d: expected push message %q, got %q", step.client, want, res)
+						}
+					default:
+						command := make([]string, len(step.command))
+						for i, token := range step.command {
+							command[i] = strings.ReplaceAll(token, "{id1}", ids[1])
+						}
+						if _, err := conn.Write(internal.EncodeCommand(command)); err != nil {
+							t.Error(err)
+							return
+						}
+						res, err := readFrame(reader)
+						if err != nil {
+							t.Error(err)
+							return
+						}
+						want := strings.ReplaceAll(step.want, "{id1}", ids[1])
+						if res != want {
+							t.Errorf("%v: expected response %q, got %q", command, want, res)
+						}
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("Test_HandleClient", func(t *testing.T) {
+		t.Parallel()
+
+		port, err := internal.GetFreePort()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		mockServer, err := setUpServer(port, false, "")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		go func() {
+			mockServer.Start()
+		}()
+		t.Cleanup(func() {
+			mockServer.ShutDown()
+		})
+
+		type client struct {
+			conn   net.Conn
+			reader *bufio.Reader
+			id     string
+		}
+
+		// send writes the command to the client's connection and returns the response.
+		send := func(t *testing.T, c *client, command ...string) string {
+			if _, err := c.conn.Write(internal.EncodeCommand(command)); err != nil {
+				t.Error(err)
+				return ""
+			}
+			_ = c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+			defer func() {
+				_ = c.conn.SetReadDeadline(time.Time{})
+			}()
+			res, err := readFrame(c.reader)
+			if err != nil {
+				t.Errorf("%v: %v", command, err)
+			}
+			return res
+		}
+
+		// expectNoResponse checks that nothing is written to the client's connection for a while.
+		expectNoResponse := func(t *testing.T, c *client) {
+			_ = c.conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+			if res, err := readFrame(c.reader); err == nil {
+				t.Errorf("expected no response, got %q", res)
+			}
+			c.reader = bufio.NewReader(c.conn)
+			_ = c.conn.SetReadDeadline(time.Time{})
+		}
+
+		// expectClosed checks that the server closed the client's connection.
+		expectClosed := func(t *testing.T, c *client) {
+			_ = c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+			if res, err := readFrame(c.reader); !errors.Is(err, io.EOF) {
+				t.Errorf("expected the connection to be closed, got %q, %v", res, err)
+			}
+		}
+
+		connect := func(t *testing.T) *client {
+			conn, err := internal.GetConnection("localhost", port)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() {
+				_ = conn.Close()
+			})
+			c := &client{conn: conn, reader: bufio.NewReader(conn)}
+			res := send(t, c, "CLIENT", "ID")
+			c.id = strings.TrimSuffix(strings.TrimPrefix(res, ":"), "\r\n")
+			return c
+		}
+
+		// line returns the CLIENT LIST line of the client.
+		line := func(t *testing.T, c *client, id string) string {
+			res := send(t, c, "CLIENT", "LIST", "ID", id)
+			lines := strings.Split(strings.TrimSuffix(res[strings.Index(res, "\r\n")+2:], "\n\r\n"), "\n")
+			if len(lines) != 1 {
+				t.Errorf("expected 1 client with id %s, got %q", id, res)
+			}
+			return lines[0]
+		}
+
+		t.Run("1. Set and get the client name and id", func(t *testing.T) {
+			c := connect(t)
+			if res := send(t, c, "CLIENT", "GETNAME"); res != "$-1\r\n" {
+				t.Errorf("expected null name, got %q", res)
+			}
+			want := "-Error Client names cannot contain spaces, newlines or special characters.\r\n"
+			if res := send(t, c, "CLIENT", "SETNAME", "client name"); res != want {
+				t.Errorf("expected %q, got %q", want, res)
+			}
+			if res := send(t, c, "CLIENT", "SETNAME", "client-1"); res != "+OK\r\n" {
+				t.Errorf("expected OK, got %q", res)
+			}
+			if res := send(t, c, "CLIENT", "GETNAME"); res != "$8\r\nclient-1\r\n" {
+				t.Errorf("expected client-1, got %q", res)
+			}
+			if _, err := strconv.ParseUint(c.id, 10, 64); err != nil {
+				t.Errorf("expected integer id, got %q", c.id)
+			}
+		})
+
+		t.Run("2. Get the client info and list the clients", func(t *testing.T) {
+			c1, c2, c3 := connect(t), connect(t), connect(t)
+			send(t, c1, "CLIENT", "SETNAME", "info-client")
+			send(t, c1, "SELECT", "1")
+			res := send(t, c1, "CLIENT", "INFO")
+			for _, field := range []string{
+				fmt.Sprintf("id=%s ", c1.id), "name=info-client ", "flags=N ", "db=1 ", "sub=0 ", "psub=0 ",
+				"multi=-1 ", "user=default ", "resp=2 ", "cmd=client|info\n",
+			} {
+				if !strings.Contains(res, field) {
+					t.Errorf("expected CLIENT INFO to contain %q, got %q", field, res)
+				}
+			}
+			if !strings.HasPrefix(res, "$") || !strings.HasSuffix(res, "\n\r\n") {
+				t.Errorf("expected CLIENT INFO to return a bulk string with one line, got %q", res)
+			}
+
+			// The client in a transaction has the x flag and the number of queued commands.
+			send(t, c1, "MULTI")
+			send(t, c1, "SET", "ClientKey1", "value1")
+			if l := line(t, c2, c1.id); !strings.Contains(l, "flags=x ") || !strings.Contains(l, "multi=1 ") {
+				t.Errorf("expected client in transaction, got %q", l)
+			}
+			send(t, c1, "DISCARD")
+
+			// The subscribed client has the P flag and is listed with TYPE pubsub.
+			if _, err := c3.conn.Write(internal.EncodeCommand([]string{"SUBSCRIBE", "client_channel"})); err != nil {
+				t.Error(err)
+				return
+			}
+			if _, err := readFrame(c3.reader); err != nil {
+				t.Error(err)
+				return
+			}
+			if l := line(t, c2, c3.id); !strings.Contains(l, "flags=P ") || !strings.Contains(l, "sub=1 ") {
+				t.Errorf("expected subscribed client, got %q", l)
+			}
+			res = send(t, c2, "CLIENT", "LIST", "TYPE", "pubsub")
+			if !strings.Contains(res, fmt.Sprintf("id=%s ", c3.id)) || strings.Contains(res, fmt.Sprintf("id=%s ", c1.id)) {
+				t.Errorf("expected only the subscribed client, got %q", res)
+			}
+			res = send(t, c2, "CLIENT", "LIST", "TYPE", "normal")
+			if strings.Contains(res, fmt.Sprintf("id=%s ", c3.id)) || !strings.Contains(res, fmt.Sprintf("id=%s ", c1.id)) {
+				t.Errorf("expected only the normal clients, got %q", res)
+			}
+			if res = send(t, c2, "CLIENT", "LIST", "TYPE", "unknown"); res != "-Error unknown client type 'unknown'\r\n" {
+				t.Errorf("expected unknown client type error, got %q", res)
+			}
+
+			if res = send(t, c2, "CLIENT", "NO-EVICT", "ON"); res != "+OK\r\n" {
+				t.Errorf("expected OK, got %q", res)
+			}
+			if res = send(t, c2, "CLIENT", "INFO"); !strings.Contains(res, "flags=e ") {
+				t.Errorf("expected e flag, got %q", res)
+			}
+			if res = send(t, c2, "CLIENT", "NO-EVICT", "MAYBE"); res != "-Error syntax error\r\n" {
+				t.Errorf("expected syntax error, got %q", res)
+			}
+		})
+
+		t.Run("3. Kill clients with filters", func(t *testing.T) {
+			c1, c2, c3, c4 := connect(t), connect(t), connect(t), connect(t)
+
+			// The client calling the command is skipped by default.
+			if res := send(t, c1, "CLIENT", "KILL", "ID", c1.id); res != ":0\r\n" {
+				t.Errorf("expected 0 killed clients, got %q", res)
+			}
+			if res := send(t, c1, "CLIENT", "KILL", "ID", c2.id); res != ":1\r\n" {
+				t.Errorf("expected 1 killed client, got %q", res)
+			}
+			expectClosed(t, c2)
+
+			// The old form kills the client with the address.
+			l := line(t, c1, c3.id)
+			addr := l[strings.Index(l, "addr=")+len("addr="):]
+			addr = addr[:strings.Index(addr, " ")]
+			if res := send(t, c1, "CLIENT", "KILL", addr); res != "+OK\r\n" {
+				t.Errorf("expected OK, got %q", res)
+			}
+			expectClosed(t, c3)
+			if res := send(t, c1, "CLIENT", "KILL", addr); res != "-Error no such client\r\n" {
+				t.Errorf("expected no such client error, got %q", res)
+			}
+
+			if res := send(t, c1, "CLIENT", "KILL", "USER", "user1", "ID", c4.id); res != ":0\r\n" {
+				t.Errorf("expected 0 killed clients, got %q", res)
+			}
+			if res := send(t, c1, "CLIENT", "KILL", "USER", "default", "TYPE", "normal", "ID", c4.id); res != ":1\r\n" {
+				t.Errorf("expected 1 killed client, got %q", res)
+			}
+			expectClosed(t, c4)
+
+			if res := send(t, c1, "CLIENT", "KILL", "ID", "0"); res != "-Error client-id should be greater than 0\r\n" {
+				t.Errorf("expected client id error, got %q", res)
+			}
+			if res := send(t, c1, "CLIENT", "KILL", "ID", c1.id, "SKIPME"); res != "-Error syntax error\r\n" {
+				t.Errorf("expected syntax error, got %q", res)
+			}
+			if res := send(t, c1, "CLIENT", "KILL", "ID", c1.id, "SKIPME", "NO"); res != ":1\r\n" {
+				t.Errorf("expected 1 killed client, got %q", res)
+			}
+			expectClosed(t, c1)
+		})
+
+		t.Run("4. Pause the write commands until the clients are unpaused", func(t *testing.T) {
+			c1, c2 := connect(t), connect(t)
+			if res := send(t, c1, "CLIENT", "PAUSE", "10000", "WRITE"); res != "+OK\r\n" {
+				t.Errorf("expected OK, got %q", res)
+			}
+			// Read commands are not paused.
+			if res := send(t, c2, "GET", "ClientKey2"); res != "$-1\r\n" {
+				t.Errorf("expected null, got %q", res)
+			}
+			if _, err := c2.conn.Write(internal.EncodeCommand([]string{"SET", "ClientKey2", "value1"})); err != nil {
+				t.Error(err)
+				return
+			}
+			expectNoResponse(t, c2)
+			if res := send(t, c1, "CLIENT", "UNPAUSE"); res != "+OK\r\n" {
+				t.Errorf("expected OK, got %q", res)
+			}
+			_ = c2.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+			if res, err := readFrame(c2.reader); err != nil || res != "+OK\r\n" {
+				t.Errorf("expected OK after unpause, got %q, %v", res, err)
+			}
+			_ = c2.conn.SetReadDeadline(time.Time{})
+		})
+
+		t.Run("5. Pause all the commands until the timeout elapses", func(t *testing.T) {
+			c1, c2 := connect(t), connect(t)
+			if res := send(t, c1, "CLIENT", "PAUSE", "500", "ALL"); res != "+OK\r\n" {
+				t.Errorf("expected OK, got %q", res)
+			}
+			start := time.Now()
+			if res := send(t, c2, "PING"); res != "+PONG\r\n" {
+				t.Errorf("expected PONG, got %q", res)
+			}
+			if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+				t.Errorf("expected PING to wait for the pause to end, returned after %v", elapsed)
+			}
+			if res := send(t, c1, "CLIENT", "PAUSE", "-1"); res != "-Error timeout is not an integer or out of range\r\n" {
+				t.Errorf("expected timeout error, got %q", res)
+			}
+		})
+
+		t.Run("6. Turn the replies off and skip the next reply", func(t *testing.T) {
+			c := connect(t)
+			// The commands are sent one at a time, because the server reads one command per message.
+			for _, command := range [][]string{
+				{"CLIENT", "REPLY", "OFF"},
+				{"SET", "ClientKey3", "value1"},
+			} {
+				if _, err := c.conn.Write(internal.EncodeCommand(command)); err != nil {
+					t.Error(err)
+					return
+				}
+				expectNoResponse(t, c)
+			}
+			if res := send(t, c, "CLIENT", "REPLY", "ON"); res != "+OK\r\n" {
+				t.Errorf("expected OK, got %q", res)
+			}
+			for _, command := range [][]string{
+				{"CLIENT", "REPLY", "SKIP"},
+				{"GET", "ClientKey3"},
+			} {
+				if _, err := c.conn.Write(internal.EncodeCommand(command)); err != nil {
+					t.Error(err)
+					return
+				}
+				expectNoResponse(t, c)
+			}
+			if res := send(t, c, "GET", "ClientKey3"); res != "+value1\r\n" {
+				t.Errorf("expected value1, got %q", res)
+			}
+			if res := send(t, c, "CLIENT", "REPLY", "NEVER"); res != "-Error syntax error\r\n" {
+				t.Errorf("expected syntax error, got %q", res)
+			}
+		})
+	})
+}
+
+// readFrame reads one complete RESP2 or RESP3 value from the reader and returns it as it was received.
+func readFrame(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	switch line[0] {
+	case '$':
+		n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if n < 0 {
+			return line, nil
+		}
+		data := make([]byte, n+2)
+		if _, err = io.ReadFull(r, data); err != nil {
+			return "", err
+		}
+		return line + string(data), nil
+	case '*', '%', '>':
+		n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if line[0] == '%' {
+			n *= 2
+		}
+		frame := line
+		for i := 0; i < n; i++ {
+			element, err := readFrame(r)
+			if err != nil {
+				return "", err
+			}
+			frame += element
+		}
+		return frame, nil
+	default:
+		return line, nil
+	}
 }
@@ -1,9 +1,11 @@
 package connection
 
 import (
+	"errors"
 	"fmt"
 	"github.com/echovault/sugardb/internal"
 	"github.com/echovault/sugardb/internal/constants"
+	"strconv"
 	"strings"
 )
 
@@ -64,3 +66,155 @@ func BuildHelloResponse(serverInfo internal.ServerInfo, connectionInfo internal.
 	}
 	return res
 }
+
+func getClientTrackingOptions(cmd []string, options internal.TrackingOptions) (internal.TrackingOptions, error) {
+	if len(cmd) == 0 {
+		return options, nil
+	}
+	switch strings.ToLower(cmd[0]) {
+	case "redirect":
+		if len(cmd) < 2 {
+			return options, fmt.Errorf(constants.WrongArgsResponse)
+		}
+		id, err := strconv.ParseUint(cmd[1], 10, 64)
+		if err != nil {
+			return options, errors.New("client ID must be a positive integer")
+		}
+		options.Redirect = id
+		return getClientTrackingOptions(cmd[2:], options)
+	case "prefix":
+		if len(cmd) < 2 {
+			return options, fmt.Errorf(constants.WrongArgsResponse)
+		}
+		options.Prefixes = append(options.Prefixes, cmd[1])
+		return getClientTrackingOptions(cmd[2:], options)
+	case "bcast":
+		options.BCast = true
+		return getClientTrackingOptions(cmd[1:], options)
+	case "optin":
+		options.OptIn = true
+		return getClientTrackingOptions(cmd[1:], options)
+	case "optout":
+		options.OptOut = true
+		return getClientTrackingOptions(cmd[1:], options)
+	case "noloop":
+		options.NoLoop = true
+		return getClientTrackingOptions(cmd[1:], options)
+	default:
+		return options, fmt.Errorf("unknown keyword %s", strings.ToUpper(cmd[0]))
+	}
+}
+
+func buildTrackingInfoResponse(info internal.TrackingInfo, protocol int) []byte {
+	flags := []string{"off"}
+	redirect := int64(-1)
+	if info.Enabled {
+		flags = []string{"on"}
+		for _, flag := range []struct {
+			name string
+			set  bool
+		}{
+			{"bcast", info.BCast},
+			{"optin", info.OptIn},
+			{"optout", info.OptOut},
+			{"caching-yes", info.OptIn && info.Caching},
+			{"caching-no", info.OptOut && info.Caching},
+			{"noloop", info.NoLoop},
+			{"broken_redirect", info.BrokenRedirect},
+		} {
+			if flag.set {
+				flags = append(flags, flag.name)
+			}
+		}
+		redirect = int64(info.Redirect)
+	}
+
+	var res []byte
+	if protocol == 3 {
+		res = []byte("%3\r\n")
+	} else {
+		res = []byte("*6\r\n")
+	}
+
+	res = append(res, []byte(fmt.Sprintf("+flags\r\n*%d\r\n", len(flags)))...)
+	for _, flag := range flags {
+		res = append(res, []byte(fmt.Sprintf("$%d\r\n%s\r\n", len(flag), flag))...)
+	}
+	res = append(res, []byte(fmt.Sprintf("+redirect\r\n:%d\r\n", redirect))...)
+	res = append(res, []byte(fmt.Sprintf("+prefixes\r\n*%d\r\n", len(info.Prefixes)))...)
+	for _, prefix := range info.Prefixes {
+		res = append(res, []byte(fmt.Sprintf("$%d\r\n%s\r\n", len(prefix), prefix))...)
+	}
+	return res
+}
+
+// getClientType returns the client type in lowercase. SLAVE is an alias of REPLICA.
+func getClientType(t string) (string, error) {
+	switch strings.ToLower(t) {
+	case "normal", "master", "replica", "pubsub":
+		return strings.ToLower(t), nil
+	case "slave":
+		return "replica", nil
+	default:
+		return "", fmt.Errorf("unknown client type '%s'", t)
+	}
+}
+
+// getClientInfoType returns the type of the client. Replication is done with raft,
+// so none of the clients is a master or a replica.
+func getClientInfoType(info internal.ClientInfo) string {
+	if info.Subscriptions+info.PatternSubscriptions > 0 {
+		return "pubsub"
+	}
+	return "normal"
+}
+
+func getClientKillFilter(cmd []string, filter internal.ClientKillFilter) (internal.ClientKillFilter, error) {
+	if len(cmd) == 0 {
+		return filter, nil
+	}
+	if len(cmd) < 2 {
+		return filter, errors.New("syntax error")
+	}
+	switch strings.ToLower(cmd[0]) {
+	case "id":
+		id, err := strconv.ParseUint(cmd[1], 10, 64)
+		if err != nil || id == 0 {
+			return filter, errors.New("client-id should be greater than 0")
+		}
+		filter.Id = id
+	case "addr":
+		filter.Addr = cmd[1]
+	case "laddr":
+		filter.LocalAddr = cmd[1]
+	case "user":
+		filter.User = cmd[1]
+	case "type":
+		t, err := getClientType(cmd[1])
+		if err != nil {
+			return filter, err
+		}
+		filter.Type = t
+	case "skipme":
+		switch strings.ToLower(cmd[1]) {
+		case "yes":
+			filter.SkipMe = true
+		case "no":
+			filter.SkipMe = false
+		default:
+			return filter, errors.New("syntax error")
+		}
+	default:
+		return filter, errors.New("syntax error")
+	}
+	return getClientKillFilter(cmd[2:], filter)
+}
+
+func buildClientInfoLine(info internal.ClientInfo) string {
+	return fmt.Sprintf(
+		"id=%d addr=%s laddr=%s name=%s age=%d idle=%d flags=%s db=%d sub=%d psub=%d multi=%d qbuf=%d obl=%d user=%s resp=%d cmd=%s",
+		info.Id, info.Addr, info.LocalAddr, info.Name, info.Age, info.Idle, info.Flags, info.Database,
+		info.Subscriptions, info.PatternSubscriptions, info.Multi, info.QueryBuffer, info.OutputBuffer,
+		info.User, info.Protocol, info.Command,
+	)
+}
@@ -15,17 +15,22 @@
 package generic
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"log"
-	"reflect"
+	"net"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/echovault/sugardb/internal"
 	"github.com/echovault/sugardb/internal/constants"
+	"github.com/echovault/sugardb/internal/modules/set"
+	"github.com/echovault/sugardb/internal/modules/sorted_set"
+	"github.com/gobwas/glob"
 )
 
 type KeyObject struct {
@@ -56,7 +61,7 @@ func handleSet(params internal.HandlerFuncParams) ([]byte, error) {
 		if !keyExists {
 			res = []byte("$-1\r\n")
 		} else {
-			res = []byte(fmt.Sprintf("+%v\r\n", params.GetValues(params.Context, []string{key})[key]))
+			res = valueResponse(params.GetValues(params.Context, []string{key})[key])
 		}
 	}
 
@@ -121,9 +126,7 @@ func handleGet(params internal.HandlerFuncParams) ([]byte, error) {
 		return []byte("$-1\r\n"), nil
 	}
 
-	value := params.GetValues(params.Context, []string{key})[key]
-
-	return []byte(fmt.Sprintf("+%v\r\n", value)), nil
+	return valueResponse(params.GetValues(params.Context, []string{key})[key]), nil
 }
 
 func handleMGet(params internal.HandlerFuncParams) ([]byte, error) {
@@ -402,6 +405,11 @@ func handleIncr(params internal.HandlerFuncParams) ([]byte, error) {
 	values := params.GetValues(params.Context, []string{key}) // Get the current values for the specified keys
 	currentValue, ok := values[key]                           // Check if the key exists
 
+	// Bitmaps hold the raw bytes of a string, so they're read as strings.
+	if bitmap, isBitmap := currentValue.(fmt.Stringer); isBitmap {
+		currentValue = bitmap.String()
+	}
+
 	var newValue int64
 	var currentValueInt int64
 
@@ -449,6 +457,11 @@ func handleDecr(params internal.HandlerFuncParams) ([]byte, error) {
 	values := params.GetValues(params.Context, []string{key}) // Get the current values for the specified keys
 	currentValue, ok := values[key]                           // Check if the key exists
 
+	// Bitmaps hold the raw bytes of a string, so they're read as strings.
+	if bitmap, isBitmap := currentValue.(fmt.Stringer); isBitmap {
+		currentValue = bitmap.String()
+	}
+
 	var newValue int64
 	var currentValueInt int64
 
@@ -502,6 +515,11 @@ func handleIncrBy(params internal.HandlerFuncParams) ([]byte, error) {
 	values := params.GetValues(params.Context, []string{key}) // Get the current values for the specified keys
 	currentValue, ok := values[key]                           // Check if the key exists
 
+	// Bitmaps hold the raw bytes of a string, so they're read as strings.
+	if bitmap, isBitmap := currentValue.(fmt.Stringer); isBitmap {
+		currentValue = bitmap.String()
+	}
+
 	var newValue int64
 	var currentValueInt int64
 
@@ -554,6 +572,11 @@ func handleIncrByFloat(params internal.HandlerFuncParams) ([]byte, error) {
 	values := params.GetValues(params.Context, []string{key}) // Get the current values for the specified keys
 	currentValue, ok := values[key]                           // Check if the key exists
 
+	// Bitmaps hold the raw bytes of a string, so they're read as strings.
+	if bitmap, isBitmap := currentValue.(fmt.Stringer); isBitmap {
+		currentValue = bitmap.String()
+	}
+
 	var newValue float64
 	var currentValueFloat float64
 
@@ -613,6 +636,11 @@ func handleDecrBy(params internal.HandlerFuncParams) ([]byte, error) {
 	values := params.GetValues(params.Context, []string{key}) // Get the current values for the specified keys
 	currentValue, ok := values[key]                           // Check if the key exists
 
+	// Bitmaps hold the raw bytes of a string, so they're read as strings.
+	if bitmap, isBitmap := currentValue.(fmt.Stringer); isBitmap {
+		currentValue = bitmap.String()
+	}
+
 	var newValue int64
 	var currentValueInt int64
 
@@ -718,7 +746,7 @@ func handleGetdel(params internal.HandlerFuncParams) ([]byte, error) {
 		return nil, err
 	}
 
-	return []byte(fmt.Sprintf("+%v\r\n", value)), nil
+	return valueResponse(value), nil
 }
 
 func handleGetex(params internal.HandlerFuncParams) ([]byte, error) {
@@ -742,7 +770,7 @@ func handleGetex(params internal.HandlerFuncParams) ([]byte, error) {
 
 	// Handle no expire options provided
 	if cmdLen == 2 {
-		return []byte(fmt.Sprintf("+%v\r\n", value)), nil
+		return valueResponse(value), nil
 	}
 
 	// Handle persist
@@ -751,12 +779,12 @@ func handleGetex(params internal.HandlerFuncParams) ([]byte, error) {
 	if exCommand == "persist" {
 		// getValues will update key access so no need here
 		params.SetExpiry(params.Context, exkey, time.Time{}, false)
-		return []byte(fmt.Sprintf("+%v\r\n", value)), nil
+		return valueResponse(value), nil
 	}
 
 	// Handle exipre command passed but no time provided
 	if cmdLen == 3 {
-		return []byte(fmt.Sprintf("+%v\r\n", value)), nil
+		return valueResponse(value), nil
 	}
 
 	// Extract time
@@ -784,7 +812,7 @@ func handleGetex(params internal.HandlerFuncParams) ([]byte, error) {
 
 	params.SetExpiry(params.Context, exkey, expireAt, false)
 
-	return []byte(fmt.Sprintf("+%v\r\n", value)), nil
+	return valueResponse(value), nil
 
 }
 
@@ -801,30 +829,7 @@ func handleType(params internal.HandlerFuncParams) ([]byte, error) {
 	}
 
 	value := params.GetValues(params.Context, []string{key})[key]
-	t := reflect.TypeOf(value)
-	type_string := ""
-	switch t.Kind() {
-	case reflect.String:
-		type_string = "string"
-	case reflect.Int:
-		type_string = "integer"
-	case reflect.Float64:
-		type_string = "float"
-	case reflect.Slice:
-		type_string = "list"
-	case reflect.Map:
-		type_string = "hash"
-	case reflect.Pointer:
-		if t.Elem().Name() == "Set" {
-			type_string = "set"
-		} else if t.Elem().Name() == "SortedSet" {
-			type_string = "zset"
-		} else {
-			type_string = t.Elem().Name()
-		}
-	default:
-		type_string = fmt.Sprintf("%T", value)
-	}
+	type_string := getValueType(value)
 	return []byte(fmt.Sprintf("+%v\r\n", type_string)), nil
 }
 
@@ -960,6 +965,498 @@ func handleMove(params internal.HandlerFuncParams) ([]byte, error) {
 	return []byte(fmt.Sprintf("+%v\r\n", 0)), nil
 }
 
+func handleKeys(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 2 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+
+	pattern, err := glob.Compile(params.Command[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %s", params.Command[1])
+	}
+
+	keys := slices.DeleteFunc(filterReadableKeys(params, params.GetKeys(params.Context)), func(key string) bool {
+		return !pattern.Match(key)
+	})
+
+	res := fmt.Sprintf("*%d\r\n", len(keys))
+	for _, key := range keys {
+		res += fmt.Sprintf("$%d\r\n%s\r\n", len(key), key)
+	}
+
+	return []byte(res), nil
+}
+
+func handleScan(params internal.HandlerFuncParams) ([]byte, error) {
+	options, err := internal.ParseScanOptions(params.Command[1:], true)
+	if err != nil {
+		return nil, err
+	}
+
+	page, cursor := internal.ScanCursor(params.GetKeys(params.Context), options.Cursor, options.Count)
+	keys := filterReadableKeys(params, page)
+
+	if options.Match != nil {
+		keys = slices.DeleteFunc(keys, func(key string) bool {
+			return !options.Match.Match(key)
+		})
+	}
+
+	if options.Type != "" {
+		values := params.GetValues(params.Context, keys)
+		keys = slices.DeleteFunc(keys, func(key string) bool {
+			// Keys that expired while scanning are skipped as well.
+			return values[key] == nil || getValueType(values[key]) != options.Type
+		})
+	}
+
+	res := fmt.Sprintf("*2\r\n$%d\r\n%d\r\n*%d\r\n", len(strconv.FormatUint(cursor, 10)), cursor, len(keys))
+	for _, key := range keys {
+		res += fmt.Sprintf("$%d\r\n%s\r\n", len(key), key)
+	}
+
+	return []byte(res), nil
+}
+
+func handleSort(params internal.HandlerFuncParams) ([]byte, error) {
+	readOnly := strings.EqualFold(params.Command[0], "sort_ro")
+	keys, err := sortKeys(params.Command, readOnly)
+	if err != nil {
+		return nil, err
+	}
+	options, err := getSortCommandOptions(params.Command[2:], SortOptions{readOnly: readOnly})
+	if err != nil {
+		return nil, err
+	}
+	key := keys.ReadKeys[0]
+
+	var elements []string
+	isSet := false
+	if params.KeysExist(params.Context, []string{key})[key] {
+		switch value := params.GetValues(params.Context, []string{key})[key].(type) {
+		case []string:
+			elements = slices.Clone(value)
+		case *set.Set:
+			elements, isSet = value.GetAll(), true
+		case *sorted_set.SortedSet:
+			// Sorted set members are in the order of their scores when sorting is skipped.
+			members := value.GetAll()
+			slices.SortFunc(members, func(a, b sorted_set.MemberParam) int {
+				if a.Score != b.Score {
+					return cmp.Compare(a.Score, b.Score)
+				}
+				return strings.Compare(string(a.Value), string(b.Value))
+			})
+			for _, member := range members {
+				elements = append(elements, string(member.Value))
+			}
+		default:
+			return nil, fmt.Errorf("value at %s is not a list, set or sorted set", key)
+		}
+	}
+
+	// lookup returns the values of the keys and hash fields referenced by the pattern for each element.
+	// The keys are derived from the elements, so the ACL can only check them here.
+	lookup := func(pattern string) ([]*string, error) {
+		patternKeys := make([]string, len(elements))
+		fields := make([]string, len(elements))
+		for i, element := range elements {
+			patternKeys[i], fields[i] = splitSortPattern(pattern, element)
+		}
+		if err := authorizeReadKeys(params, patternKeys); err != nil {
+			return nil, err
+		}
+		values := params.GetValues(params.Context, patternKeys)
+		res := make([]*string, len(elements))
+		for i := range elements {
+			value := values[patternKeys[i]]
+			if fields[i] != "" {
+				hash, ok := value.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				value = hash[fields[i]]
+			}
+			if s, ok := sortValueToString(value); ok {
+				res[i] = &s
+			}
+		}
+		return res, nil
+	}
+
+	// A BY pattern without "*" skips sorting. Sets are still sorted lexicographically, as their order is random.
+	sortBy := options.by == "" || strings.Contains(options.by, "*")
+	alpha := options.alpha
+	if !sortBy && isSet {
+		sortBy, alpha, options.by = true, true, ""
+	}
+
+	if sortBy {
+		weights := make([]*string, len(elements))
+		if options.by != "" {
+			if weights, err = lookup(options.by); err != nil {
+				return nil, err
+			}
+		} else {
+			for i := range elements {
+				weights[i] = &elements[i]
+			}
+		}
+
+		scores := make([]float64, len(elements))
+		if !alpha {
+			for i, weight := range weights {
+				if weight == nil {
+					continue
+				}
+				if scores[i], err = strconv.ParseFloat(*weight, 64); err != nil {
+					return nil, errors.New("One or more scores can't be converted into double")
+				}
+			}
+		}
+
+		indices := make([]int, len(elements))
+		for i := range indices {
+			indices[i] = i
+		}
+		slices.SortStableFunc(indices, func(a, b int) int {
+			var res int
+			if alpha {
+				var weightA, weightB string
+				if weights[a] != nil {
+					weightA = *weights[a]
+				}
+				if weights[b] != nil {
+					weightB = *weights[b]
+				}
+				res = strings.Compare(weightA, weightB)
+			} else {
+				res = cmp.Compare(scores[a], scores[b])
+			}
+			// Compare the elements when the weights are equal so that the order is deterministic.
+			if res == 0 {
+				res = strings.Compare(elements[a], elements[b])
+			}
+			if options.desc {
+				return -res
+			}
+			return res
+		})
+
+		sorted := make([]string, len(elements))
+		for i, index := range indices {
+			sorted[i] = elements[index]
+		}
+		elements = sorted
+	}
+
+	if options.limit {
+		start := min(max(options.offset, 0), len(elements))
+		end := len(elements)
+		if options.count >= 0 {
+			end = min(start+options.count, len(elements))
+		}
+		elements = elements[start:end]
+	}
+
+	// Each element is replaced by the values of the GET patterns, where "#" is the element itself.
+	result := make([]*string, 0, len(elements))
+	if len(options.get) == 0 {
+		for i := range elements {
+			result = append(result, &elements[i])
+		}
+	} else {
+		values := make([][]*string, len(options.get))
+		for i, pattern := range options.get {
+			if pattern == "#" {
+				values[i] = make([]*string, len(elements))
+				for j := range elements {
+					values[i][j] = &elements[j]
+				}
+				continue
+			}
+			if values[i], err = lookup(pattern); err != nil {
+				return nil, err
+			}
+		}
+		for j := range elements {
+			for i := range options.get {
+				result = append(result, values[i][j])
+			}
+		}
+	}
+
+	if options.store != "" {
+		if len(result) == 0 {
+			if params.KeysExist(params.Context, []string{options.store})[options.store] {
+				if err = params.DeleteKey(params.Context, options.store); err != nil {
+					return nil, err
+				}
+			}
+			return []byte(":0\r\n"), nil
+		}
+		list := make([]string, len(result))
+		for i, value := range result {
+			if value != nil {
+				list[i] = *value
+			}
+		}
+		if err = params.SetValues(params.Context, map[string]interface{}{options.store: list}); err != nil {
+			return nil, err
+		}
+		return []byte(fmt.Sprintf(":%d\r\n", len(list))), nil
+	}
+
+	res := fmt.Sprintf("*%d\r\n", len(result))
+	for _, value := range result {
+		if value == nil {
+			res += "$-1\r\n"
+			continue
+		}
+		res += fmt.Sprintf("$%d\r\n%s\r\n", len(*value), *value)
+	}
+	return []byte(res), nil
+}
+
+func handleDump(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := dumpKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key := keys.ReadKeys[0]
+
+	if !params.KeysExist(params.Context, []string{key})[key] {
+		return []byte("$-1\r\n"), nil
+	}
+
+	payload, err := dumpValue(params.GetValues(params.Context, []string{key})[key])
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf("$%d\r\n%s\r\n", len(payload), payload)), nil
+}
+
+func handleRestore(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := restoreKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key := keys.WriteKeys[0]
+
+	ttl, err := strconv.ParseInt(params.Command[2], 10, 64)
+	if err != nil {
+		return nil, errors.New("value is not an integer or out of range")
+	}
+	if ttl < 0 {
+		return nil, errors.New("Invalid TTL value, must be >= 0")
+	}
+
+	options, err := getRestoreCommandOptions(params.Command[4:], RestoreOptions{idleTime: -1, freq: -1})
+	if err != nil {
+		return nil, err
+	}
+
+	keyExists := params.KeysExist(params.Context, []string{key})[key]
+	if keyExists && !options.replace {
+		return nil, errors.New("BUSYKEY Target key name already exists.")
+	}
+
+	value, err := restoreValue([]byte(params.Command[3]))
+	if err != nil {
+		return nil, err
+	}
+
+	var expireAt time.Time
+	if ttl > 0 {
+		if options.absTTL {
+			expireAt = time.UnixMilli(ttl)
+		} else {
+			expireAt = params.GetClock().Now().Add(time.Duration(ttl) * time.Millisecond)
+		}
+	}
+
+	// A key restored with an absolute TTL in the past expires immediately, so it's not created.
+	if !expireAt.IsZero() && !expireAt.After(params.GetClock().Now()) {
+		if keyExists {
+			if err = params.DeleteKey(params.Context, key); err != nil {
+				return nil, err
+			}
+		}
+		return []byte(constants.OkResponse), nil
+	}
+
+	// The eviction information is seeded before the write, so that the write doesn't update the key in the cache.
+	seeded := options.idleTime != -1 || options.freq != -1
+	if options.idleTime != -1 {
+		params.SetObjectIdleTime(params.Context, key, time.Duration(options.idleTime)*time.Second)
+	}
+	if options.freq != -1 {
+		params.SetObjectFrequency(params.Context, key, options.freq)
+	}
+
+	if err = params.SetValues(params.Context, map[string]interface{}{key: value}); err != nil {
+		return nil, err
+	}
+	// Always set the expiry because SetValues keeps the expiry of the key it replaces.
+	params.SetExpiry(params.Context, key, expireAt, false)
+
+	// Apply the seed once the expiry is set, as the volatile policies only cache the keys with an expiry.
+	if seeded {
+		if _, err = params.Touchkey(params.Context, []string{key}); err != nil {
+			return nil, err
+		}
+	}
+
+	return []byte(constants.OkResponse), nil
+}
+
+func handleMigrate(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := migrateKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	options, err := getMigrateCommandOptions(params.Command[6:], MigrateOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	port, err := strconv.Atoi(params.Command[2])
+	if err != nil {
+		return nil, errors.New("value is not an integer or out of range")
+	}
+	database, err := strconv.Atoi(params.Command[4])
+	if err != nil || database < 0 {
+		return nil, errors.New("value is not an integer or out of range")
+	}
+	timeout, err := strconv.Atoi(params.Command[5])
+	if err != nil {
+		return nil, errors.New("value is not an integer or out of range")
+	}
+	if timeout <= 0 {
+		timeout = 1000
+	}
+
+	// Every replica would send the keys to the target, so keys are only migrated between standalone instances.
+	if params.GetServerInfo().Mode != "standalone" {
+		return nil, errors.New("MIGRATE is only supported in standalone mode")
+	}
+	// Transactions and scripts hold the store lock, which must not be held while waiting for the target.
+	if inTransaction, _ := params.Context.Value(internal.ContextTransaction("Transaction")).(bool); inTransaction {
+		return nil, errors.New("MIGRATE is not allowed in transactions and scripts")
+	}
+
+	// Only the deletion of the migrated keys is written to the AOF. Replaying MIGRATE would send the keys again,
+	// and the command may hold the password of the target.
+	params.Propagate()
+
+	var migrated []string
+	exists := params.KeysExist(params.Context, keys.ReadKeys)
+	for _, key := range keys.ReadKeys {
+		if exists[key] && !slices.Contains(migrated, key) {
+			migrated = append(migrated, key)
+		}
+	}
+
+	// The versions are read before the values, so that a key modified during the migration is not deleted.
+	versions := params.GetKeyVersions(params.Context, migrated)
+	values := params.GetValues(params.Context, migrated)
+	// The keys that expired but were not evicted yet have a nil value.
+	migrated = slices.DeleteFunc(migrated, func(key string) bool {
+		return values[key] == nil
+	})
+	if len(migrated) == 0 {
+		return []byte("+NOKEY\r\n"), nil
+	}
+
+	restore := make([][]string, len(migrated))
+	for i, key := range migrated {
+		payload, err := dumpValue(values[key])
+		if err != nil {
+			return nil, err
+		}
+		var ttl int64
+		if expireAt := params.GetExpiry(params.Context, key); !expireAt.IsZero() {
+			// A ttl of 0 means the key does not expire, so a key that is about to expire keeps a ttl of 1.
+			ttl = max(expireAt.Sub(params.GetClock().Now()).Milliseconds(), 1)
+		}
+		restore[i] = []string{"RESTORE", key, strconv.FormatInt(ttl, 10), string(payload)}
+		if options.replace {
+			restore[i] = append(restore[i], "REPLACE")
+		}
+	}
+
+	target, err := dialMigrateTarget(
+		net.JoinHostPort(params.Command[1], strconv.Itoa(port)),
+		time.Duration(timeout)*time.Millisecond,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer target.close()
+
+	if options.password != "" {
+		auth := []string{"AUTH"}
+		if options.username != "" {
+			auth = append(auth, options.username)
+		}
+		if _, err = target.do(append(auth, options.password)...); err != nil {
+			return nil, err
+		}
+	}
+	if _, err = target.do("SELECT", strconv.Itoa(database)); err != nil {
+		return nil, err
+	}
+
+	// The keys are restored in a transaction so that they appear on the target at the same time.
+	if _, err = target.do("MULTI"); err != nil {
+		return nil, err
+	}
+	for _, command := range restore {
+		if _, err = target.do(command...); err != nil {
+			return nil, err
+		}
+	}
+	res, err := target.do("EXEC")
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the keys that were restored on the target are deleted.
+	var restoreErr error
+	deleted := []string{"DEL"}
+	defer func() {
+		if len(deleted) > 1 {
+			params.Propagate(deleted)
+		}
+	}()
+	for i, reply := range res.Array() {
+		if reply.Error() != nil {
+			if restoreErr == nil {
+				restoreErr = targetError(reply.Error())
+			}
+			continue
+		}
+		if !options.copy {
+			ok, err := params.DeleteKeyIfVersion(params.Context, migrated[i], versions[migrated[i]])
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				if restoreErr == nil {
+					restoreErr = fmt.Errorf("key %s was modified during the migration and was not deleted", migrated[i])
+				}
+				continue
+			}
+			deleted = append(deleted, migrated[i])
+		}
+	}
+	if restoreErr != nil {
+		return nil, restoreErr
+	}
+
+	return []byte(constants.OkResponse), nil
+}
+
 func Commands() []internal.Command {
 	return []internal.Command{
 		{
@@ -1321,5 +1818,120 @@ The REPLACE option removes the destination key before copying the value to it.`,
 			KeyExtractionFunc: moveKeyFunc,
 			HandlerFunc:       handleMove,
 		},
+		{
+			Command: "keys",
+			Module:  constants.GenericModule,
+			Categories: []string{
+				constants.KeyspaceCategory,
+				constants.ReadCategory,
+				constants.SlowCategory,
+				constants.DangerousCategory,
+			},
+			Description: `(KEYS pattern) Returns all the keys in the current database that match the glob-style pattern.
+Only the keys the connection is allowed to read are returned.`,
+			Sync:              false,
+			KeyExtractionFunc: keysKeyFunc,
+			HandlerFunc:       handleKeys,
+		},
+		{
+			Command:    "scan",
+			Module:     constants.GenericModule,
+			Categories: []string{constants.KeyspaceCategory, constants.ReadCategory, constants.SlowCategory},
+			Description: `(SCAN cursor [MATCH pattern] [COUNT count] [TYPE type])
+Incrementally iterates over the keys in the current database.
+Returns the cursor for the next call and a page of keys. A returned cursor of 0 means the iteration is complete.
+MATCH - Only return keys that match the glob-style pattern.
+COUNT - The number of keys to examine in this call. Defaults to 10.
+TYPE - Only return keys whose value is of the given type, as reported by the TYPE command.`,
+			Sync:              false,
+			KeyExtractionFunc: scanKeyFunc,
+			HandlerFunc:       handleScan,
+		},
+		{
+			Command: "sort",
+			Module:  constants.GenericModule,
+			Categories: []string{
+				constants.WriteCategory,
+				constants.SetCategory,
+				constants.SortedSetCategory,
+				constants.ListCategory,
+				constants.SlowCategory,
+				constants.DangerousCategory,
+			},
+			Description: `(SORT key [BY pattern] [LIMIT offset count] [GET pattern [GET pattern ...]] [ASC | DESC] [ALPHA] [STORE destination])
+Returns the elements of the list, set or sorted set sorted numerically, or lexicographically with ALPHA.
+BY sorts by the values of the keys made by replacing the first "*" in the pattern with each element,
+and "key->field" uses a hash field. A BY pattern without "*" skips sorting.
+LIMIT returns count elements starting at offset. GET returns the values of the pattern for each element instead
+of the element, and "#" returns the element itself. STORE saves the result as a list at the destination and returns
+its length.`,
+			Sync:              true,
+			KeyExtractionFunc: sortKeyFunc,
+			HandlerFunc:       handleSort,
+		},
+		{
+			Command: "sort_ro",
+			Module:  constants.GenericModule,
+			Categories: []string{
+				constants.ReadCategory,
+				constants.SetCategory,
+				constants.SortedSetCategory,
+				constants.ListCategory,
+				constants.SlowCategory,
+				constants.DangerousCategory,
+			},
+			Description: `(SORT_RO key [BY pattern] [LIMIT offset count] [GET pattern [GET pattern ...]] [ASC | DESC] [ALPHA])
+Read-only variant of SORT that does not support the STORE option.`,
+			Sync:              false,
+			KeyExtractionFunc: sortROKeyFunc,
+			HandlerFunc:       handleSort,
+		},
+		{
+			Command:    "dump",
+			Module:     constants.GenericModule,
+			Categories: []string{constants.KeyspaceCategory, constants.ReadCategory, constants.SlowCategory},
+			Description: `(DUMP key)
+Serializes the value at the key in a format that can be restored with RESTORE. The serialized value includes
+a format version and a checksum. Returns nil if the key does not exist.`,
+			Sync:              false,
+			KeyExtractionFunc: dumpKeyFunc,
+			HandlerFunc:       handleDump,
+		},
+		{
+			Command: "restore",
+			Module:  constants.GenericModule,
+			Categories: []string{
+				constants.KeyspaceCategory,
+				constants.WriteCategory,
+				constants.SlowCategory,
+				constants.DangerousCategory,
+			},
+			Description: `(RESTORE key ttl serialized-value [REPLACE] [ABSTTL] [IDLETIME seconds] [FREQ frequency])
+Creates the key from a value serialized with DUMP. The key expires after ttl milliseconds, or never when ttl is 0.
+ABSTTL makes ttl an absolute unix time in milliseconds. REPLACE replaces the key if it exists.
+IDLETIME and FREQ seed the LRU or LFU eviction information of the key.`,
+			Sync:              true,
+			KeyExtractionFunc: restoreKeyFunc,
+			HandlerFunc:       handleRestore,
+		},
+		{
+			Command: "migrate",
+			Module:  constants.GenericModule,
+			Categories: []string{
+				constants.KeyspaceCategory,
+				constants.WriteCategory,
+				constants.SlowCategory,
+				constants.DangerousCategory,
+			},
+			Description: `(MIGRATE host port key|"" destination-db timeout [COPY] [REPLACE] [AUTH password | AUTH2 username password] [KEYS key [key ...]])
+Moves the keys to the database of another SugarDB server. The keys are restored on the target in a transaction and
+deleted from this server, unless COPY is provided. REPLACE replaces the keys that exist on the target.
+AUTH and AUTH2 authenticate the connection to the target. The timeout is the maximum number of milliseconds
+each request to the target can take. To migrate more than one key, set the key to the empty string and list
+the keys after KEYS. Returns NOKEY if none of the keys exist. Only supported in standalone mode.`,
+			Sync:              false,
+			KeyExtractionFunc: migrateKeyFunc,
+			HandlerFunc:       handleMigrate,
+		},
 	}
 }
@@ -15,8 +15,12 @@
 package generic_test
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"hash/crc64"
+	"net"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
@@ -32,6 +36,20 @@ import (
 	"github.com/tidwall/resp"
 )
 
+func format(v resp.Value) string {
+	if v.IsNull() {
+		return "nil"
+	}
+	if v.Type() == resp.Array {
+		elements := make([]string, len(v.Array()))
+		for i, element := range v.Array() {
+			elements[i] = format(element)
+		}
+		return "[" + strings.Join(elements, " ") + "]"
+	}
+	return v.String()
+}
+
 type KeyData struct {
 	Value    interface{}
 	ExpireAt time.Time
@@ -3633,6 +3651,937 @@ func Test_Generic(t *testing.T) {
 		}
 	})
 
+	t.Run("Test_HandleKEYS", func(t *testing.T) {
+		t.Parallel()
+
+		port, err := internal.GetFreePort()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		mockServer, err := sugardb.NewSugarDB(
+			sugardb.WithConfig(config.Config{
+				BindAddr:       "localhost",
+				Port:           uint16(port),
+				DataDir:        "",
+				EvictionPolicy: constants.NoEviction,
+				RequirePass:    true,
+				Password:       "password1",
+			}),
+		)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		go func() {
+			mockServer.Start()
+		}()
+		t.Cleanup(func() {
+			mockServer.ShutDown()
+		})
+
+		// Add a user that can only read keys with the "user" prefix.
+		if _, err = mockServer.ACLSetUser(sugardb.User{
+			Username:          "keys_user",
+			Enabled:           true,
+			IncludeCategories: []string{"*"},
+			IncludeCommands:   []string{"*"},
+			AddPlainPasswords: []string{"password2"},
+			IncludeReadKeys:   []string{"user*"},
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+
+		for _, key := range []string{"user:1", "user:2", "user:3", "order:1", "order:2"} {
+			if _, _, err = mockServer.Set(key, "value", sugardb.SETOptions{}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+
+		tests := []struct {
+			name        string
+			auth        []string
+			command     []string
+			expected    []string
+			expectedErr error
+		}{
+			{
+				name:     "1. Return all the keys",
+				auth:     []string{"AUTH", "password1"},
+				command:  []string{"KEYS", "*"},
+				expected: []string{"user:1", "user:2", "user:3", "order:1", "order:2"},
+			},
+			{
+				name:     "2. Return the keys that match the pattern",
+				auth:     []string{"AUTH", "password1"},
+				command:  []string{"KEYS", "order:*"},
+				expected: []string{"order:1", "order:2"},
+			},
+			{
+				name:     "3. Return an empty array when no keys match the pattern",
+				auth:     []string{"AUTH", "password1"},
+				command:  []string{"KEYS", "product:*"},
+				expected: []string{},
+			},
+			{
+				name:     "4. Only return the keys the user is allowed to read",
+				auth:     []string{"AUTH", "keys_user", "password2"},
+				command:  []string{"KEYS", "*"},
+				expected: []string{"user:1", "user:2", "user:3"},
+			},
+			{
+				name:        "5. Command too short",
+				auth:        []string{"AUTH", "password1"},
+				command:     []string{"KEYS"},
+				expectedErr: errors.New(constants.WrongArgsResponse),
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				conn, err := internal.GetConnection("localhost", port)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				defer func() {
+					_ = conn.Close()
+				}()
+				client := resp.NewConn(conn)
+
+				if err = client.WriteArray(func() []resp.Value {
+					var auth []resp.Value
+					for _, token := range test.auth {
+						auth = append(auth, resp.StringValue(token))
+					}
+					return auth
+				}()); err != nil {
+					t.Error(err)
+					return
+				}
+				if res, _, err := client.ReadValue(); err != nil || !strings.EqualFold(res.String(), "ok") {
+					t.Errorf("could not authenticate connection: %v %v", res, err)
+					return
+				}
+
+				command := make([]resp.Value, len(test.command))
+				for i, token := range test.command {
+					command[i] = resp.StringValue(token)
+				}
+				if err = client.WriteArray(command); err != nil {
+					t.Error(err)
+					return
+				}
+
+				res, _, err := client.ReadValue()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				if test.expectedErr != nil {
+					if !strings.Contains(res.Error().Error(), test.expectedErr.Error()) {
+						t.Errorf("expected error \"%s\", got \"%s\"", test.expectedErr.Error(), res.Error().Error())
+					}
+					return
+				}
+
+				if len(res.Array()) != len(test.expected) {
+					t.Errorf("expected response of length %d, got %d", len(test.expected), len(res.Array()))
+					return
+				}
+				for _, item := range res.Array() {
+					if !slices.Contains(test.expected, item.String()) {
+						t.Errorf("unexpected key \"%s\" in response", item.String())
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("Test_HandleSCAN", func(t *testing.T) {
+		t.Parallel()
+
+		port, err := internal.GetFreePort()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		mockServer, err := sugardb.NewSugarDB(
+			sugardb.WithConfig(config.Config{
+				BindAddr:       "localhost",
+				Port:           uint16(port),
+				DataDir:        "",
+				EvictionPolicy: constants.NoEviction,
+			}),
+		)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		go func() {
+			mockServer.Start()
+		}()
+		t.Cleanup(func() {
+			mockServer.ShutDown()
+		})
+
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		client := resp.NewConn(conn)
+
+		var stringKeys, listKeys []string
+		for i := 0; i < 50; i++ {
+			stringKeys = append(stringKeys, fmt.Sprintf("ScanKey%d", i))
+			if _, _, err = mockServer.Set(stringKeys[i], "value", sugardb.SETOptions{}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		for i := 0; i < 5; i++ {
+			listKeys = append(listKeys, fmt.Sprintf("ScanList%d", i))
+			if _, err = mockServer.LPush(listKeys[i], "value"); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+
+		tests := []struct {
+			name        string
+			options     []string
+			expected    []string
+			expectedErr error
+		}{
+			{
+				name:     "1. Iterate over all the keys",
+				options:  []string{},
+				expected: append(slices.Clone(stringKeys), listKeys...),
+			},
+			{
+				name:     "2. Iterate over all the keys with a custom count",
+				options:  []string{"COUNT", "7"},
+				expected: append(slices.Clone(stringKeys), listKeys...),
+			},
+			{
+				name:     "3. Only return keys that match the pattern",
+				options:  []string{"MATCH", "ScanList*", "COUNT", "20"},
+				expected: listKeys,
+			},
+			{
+				name:     "4. Only return keys of the given type",
+				options:  []string{"TYPE", "list"},
+				expected: listKeys,
+			},
+			{
+				name:        "5. Return error when count is not an integer",
+				options:     []string{"COUNT", "count"},
+				expectedErr: errors.New("count must be an integer"),
+			},
+			{
+				name:        "6. Return error when an option is unknown",
+				options:     []string{"LIMIT", "10"},
+				expectedErr: errors.New("syntax error"),
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				var keys []string
+				cursor := "0"
+				for {
+					command := []resp.Value{resp.StringValue("SCAN"), resp.StringValue(cursor)}
+					for _, option := range test.options {
+						command = append(command, resp.StringValue(option))
+					}
+					if err = client.WriteArray(command); err != nil {
+						t.Error(err)
+						return
+					}
+
+					res, _, err := client.ReadValue()
+					if err != nil {
+						t.Error(err)
+						return
+					}
+
+					if test.expectedErr != nil {
+						if !strings.Contains(res.Error().Error(), test.expectedErr.Error()) {
+							t.Errorf("expected error \"%s\", got \"%s\"", test.expectedErr.Error(), res.Error().Error())
+						}
+						return
+					}
+
+					cursor = res.Array()[0].String()
+					for _, key := range res.Array()[1].Array() {
+						keys = append(keys, key.String())
+					}
+					if cursor == "0" {
+						break
+					}
+				}
+
+				if len(keys) != len(test.expected) {
+					t.Errorf("expected %d keys, got %d", len(test.expected), len(keys))
+					return
+				}
+				for _, key := range test.expected {
+					if !slices.Contains(keys, key) {
+						t.Errorf("expected key \"%s\" to be returned by the iteration", key)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("Test_HandleSORT", func(t *testing.T) {
+		t.Parallel()
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		client := resp.NewConn(conn)
+
+		tests := []struct {
+			command []string
+			want    string // The expected response, as returned by format.
+			wantErr string // The expected error substring when the response is an error.
+		}{
+			{command: []string{"RPUSH", "SortKeyList", "3", "1", "2", "10"}, want: "4"},
+			{command: []string{"SORT", "SortKeyList"}, want: "[1 2 3 10]"},
+			{command: []string{"SORT", "SortKeyList", "DESC"}, want: "[10 3 2 1]"},
+			{command: []string{"SORT", "SortKeyList", "ALPHA"}, want: "[1 10 2 3]"},
+			{command: []string{"SORT", "SortKeyList", "LIMIT", "1", "2"}, want: "[2 3]"},
+			{command: []string{"SORT", "SortKeyList", "LIMIT", "2", "-1", "DESC"}, want: "[2 1]"},
+			{command: []string{"SORT", "SortKeyList", "LIMIT", "10", "2"}, want: "[]"},
+			// BY sorts by the values of external keys, and a pattern without "*" skips sorting.
+			{command: []string{"MSET", "SortKeyWeight_1", "30", "SortKeyWeight_2", "20", "SortKeyWeight_3", "10", "SortKeyWeight_10", "40"}, want: "OK"},
+			{command: []string{"SORT", "SortKeyList", "BY", "SortKeyWeight_*"}, want: "[3 2 1 10]"},
+			{command: []string{"SORT", "SortKeyList", "BY", "SortKeyMissing_*"}, want: "[1 10 2 3]"},
+			{command: []string{"SORT", "SortKeyList", "BY", "nosort"}, want: "[3 1 2 10]"},
+			// GET returns the values of external keys and hash fields, and "#" returns the element.
+			{command: []string{"MSET", "SortKeyName_1", "one", "SortKeyName_2", "two", "SortKeyName_3", "three"}, want: "OK"},
+			{command: []string{"SORT", "SortKeyList", "GET", "#", "GET", "SortKeyName_*"}, want: "[1 one 2 two 3 three 10 nil]"},
+			{command: []string{"HSET", "SortKeyHash_1", "score", "3", "label", "a"}, want: "2"},
+			{command: []string{"HSET", "SortKeyHash_2", "score", "1", "label", "b"}, want: "2"},
+			{command: []string{"HSET", "SortKeyHash_3", "score", "2", "label", "c"}, want: "2"},
+			{command: []string{"HSET", "SortKeyHash_10", "score", "0.5"}, want: "1"},
+			{command: []string{"SORT", "SortKeyList", "BY", "SortKeyHash_*->score", "GET", "SortKeyHash_*->label"}, want: "[nil b c a]"},
+			// Sets are sorted lexicographically when sorting is skipped, and sorted sets are in the order of their scores.
+			{command: []string{"SADD", "SortKeySet", "b", "c", "a"}, want: "3"},
+			{command: []string{"SORT", "SortKeySet", "ALPHA", "DESC"}, want: "[c b a]"},
+			{command: []string{"SORT", "SortKeySet", "BY", "nosort"}, want: "[a b c]"},
+			{command: []string{"ZADD", "SortKeyZSet", "1", "c", "2", "a", "3", "b"}, want: "3"},
+			{command: []string{"SORT", "SortKeyZSet", "BY", "nosort"}, want: "[c a b]"},
+			{command: []string{"SORT", "SortKeyZSet", "ALPHA"}, want: "[a b c]"},
+			{command: []string{"SORT_RO", "SortKeyZSet", "ALPHA", "LIMIT", "0", "1"}, want: "[a]"},
+			// STORE saves the result as a list, and deletes the destination when the result is empty.
+			{command: []string{"SORT", "SortKeyList", "GET", "SortKeyName_*", "STORE", "SortKeyDestination"}, want: "4"},
+			{command: []string{"LRANGE", "SortKeyDestination", "0", "-1"}, want: "[one two three ]"},
+			{command: []string{"SORT", "SortKeyEmpty", "STORE", "SortKeyDestination"}, want: "0"},
+			{command: []string{"GET", "SortKeyDestination"}, want: "nil"},
+			{command: []string{"SORT", "SortKeyEmpty"}, want: "[]"},
+			// Errors
+			{command: []string{"SORT", "SortKeySet"}, wantErr: "One or more scores can't be converted into double"},
+			{command: []string{"SET", "SortKeyString", "value"}, want: "OK"},
+			{command: []string{"SORT", "SortKeyString"}, wantErr: "value at SortKeyString is not a list, set or sorted set"},
+			{command: []string{"SORT_RO", "SortKeyList", "STORE", "SortKeyDestination"}, wantErr: "syntax error"},
+			{command: []string{"SORT", "SortKeyList", "LIMIT", "1"}, wantErr: "syntax error"},
+			{command: []string{"SORT", "SortKeyList", "LIMIT", "a", "1"}, wantErr: "value is not an integer or out of range"},
+			{command: []string{"SORT", "SortKeyList", "UP"}, wantErr: "syntax error"},
+			{command: []string{"SORT"}, wantErr: constants.WrongArgsResponse},
+		}
+
+		for _, test := range tests {
+			command := make([]resp.Value, len(test.command))
+			for i, token := range test.command {
+				command[i] = resp.StringValue(token)
+			}
+			if err = client.WriteArray(command); err != nil {
+				t.Error(err)
+				return
+			}
+			res, _, err := client.ReadValue()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if test.wantErr != "" {
+				if res.Error() == nil || !strings.Contains(res.Error().Error(), test.wantErr) {
+					t.Errorf("%v: expected error \"%s\", got %+v", test.command, test.wantErr, res)
+				}
+				continue
+			}
+			if res.Error() != nil {
+				t.Errorf("%v: unexpected error %v", test.command, res.Error())
+				continue
+			}
+			if got := format(res); got != test.want {
+				t.Errorf("%v: expected response \"%s\", got \"%s\"", test.command, test.want, got)
+			}
+		}
+	})
+
+	t.Run("Test_HandleSORT_ACL", func(t *testing.T) {
+		t.Parallel()
+
+		port, err := internal.GetFreePort()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		mockServer, err := sugardb.NewSugarDB(
+			sugardb.WithConfig(config.Config{
+				BindAddr:       "localhost",
+				Port:           uint16(port),
+				DataDir:        "",
+				EvictionPolicy: constants.NoEviction,
+				RequirePass:    true,
+				Password:       "password1",
+			}),
+		)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		go func() {
+			mockServer.Start()
+		}()
+		t.Cleanup(func() {
+			mockServer.ShutDown()
+		})
+
+		// Add a user that can only read keys with the "sort" prefix.
+		if _, err = mockServer.ACLSetUser(sugardb.User{
+			Username:          "sort_user",
+			Enabled:           true,
+			IncludeCategories: []string{"*"},
+			IncludeCommands:   []string{"*"},
+			AddPlainPasswords: []string{"password2"},
+			IncludeReadKeys:   []string{"sort*"},
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+
+		if _, err = mockServer.RPush("sortlist", "1", "2"); err != nil {
+			t.Error(err)
+			return
+		}
+		for key, value := range map[string]string{"sortweight_1": "2", "sortweight_2": "1", "secret_1": "a", "secret_2": "b"} {
+			if _, _, err = mockServer.Set(key, value, sugardb.SETOptions{}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		client := resp.NewConn(conn)
+
+		tests := []struct {
+			command []string
+			want    string // The expected response, as returned by format.
+			wantErr string // The expected error substring when the response is an error.
+		}{
+			{command: []string{"AUTH", "sort_user", "password2"}, want: "OK"},
+			{command: []string{"SORT", "sortlist", "BY", "sortweight_*"}, want: "[2 1]"},
+			{command: []string{"SORT", "sortlist", "GET", "#", "GET", "sortweight_*"}, want: "[1 2 2 1]"},
+			// The keys derived from the elements are checked against the ACL.
+			{command: []string{"SORT", "sortlist", "BY", "secret_*"}, wantErr: "not authorised to access the following read keys: [%R~secret_1 %R~secret_2]"},
+			{command: []string{"SORT_RO", "sortlist", "GET", "secret_*"}, wantErr: "not authorised to access the following read keys: [%R~secret_1 %R~secret_2]"},
+			{command: []string{"SORT", "sortlist", "BY", "nosort", "GET", "secret_*->field"}, wantErr: "not authorised to access the following read keys"},
+		}
+
+		for _, test := range tests {
+			values := make([]resp.Value, len(test.command))
+			for i, token := range test.command {
+				values[i] = resp.StringValue(token)
+			}
+			if err = client.WriteArray(values); err != nil {
+				t.Error(err)
+				return
+			}
+			res, _, err := client.ReadValue()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if test.wantErr != "" {
+				if res.Error() == nil || !strings.Contains(res.Error().Error(), test.wantErr) {
+					t.Errorf("%v: expected error \"%s\", got %+v", test.command, test.wantErr, res)
+				}
+				continue
+			}
+			if res.Error() != nil {
+				t.Errorf("%v: unexpected error %v", test.command, res.Error())
+				continue
+			}
+			if got := format(res); got != test.want {
+				t.Errorf("%v: expected response \"%s\", got \"%s\"", test.command, test.want, got)
+			}
+		}
+	})
+
+	t.Run("Test_HandleDUMP_RESTORE", func(t *testing.T) {
+		t.Parallel()
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		client := resp.NewConn(conn)
+
+		send := func(command ...string) resp.Value {
+			values := make([]resp.Value, len(command))
+			for i, token := range command {
+				values[i] = resp.StringValue(token)
+			}
+			if err := client.WriteArray(values); err != nil {
+				t.Fatal(err)
+			}
+			res, _, err := client.ReadValue()
+			if err != nil {
+				t.Fatal(err)
+			}
+			return res
+		}
+
+		// Each value is restored at a new key and read back with the read command.
+		tests := []struct {
+			key     string
+			preset  []string
+			read    []string
+			want    string
+			wantTyp string
+		}{
+			{
+				key:     "DumpKeyString",
+				preset:  []string{"SET", "DumpKeyString", "value"},
+				read:    []string{"GET"},
+				want:    "value",
+				wantTyp: "string",
+			},
+			{
+				key:     "DumpKeyInteger",
+				preset:  []string{"SET", "DumpKeyInteger", "-10"},
+				read:    []string{"GET"},
+				want:    "-10",
+				wantTyp: "integer",
+			},
+			{
+				key:     "DumpKeyFloat",
+				preset:  []string{"SET", "DumpKeyFloat", "3.5"},
+				read:    []string{"GET"},
+				want:    "3.5",
+				wantTyp: "float",
+			},
+			{
+				key:     "DumpKeyHash",
+				preset:  []string{"HSET", "DumpKeyHash", "f1", "v1", "f2", "2"},
+				read:    []string{"HMGET", "", "f1", "f2"},
+				want:    "[v1 2]",
+				wantTyp: "hash",
+			},
+			{
+				key:     "DumpKeyList",
+				preset:  []string{"RPUSH", "DumpKeyList", "c", "a", "b"},
+				read:    []string{"LRANGE", "", "0", "-1"},
+				want:    "[c a b]",
+				wantTyp: "list",
+			},
+			{
+				key:     "DumpKeySet",
+				preset:  []string{"SADD", "DumpKeySet", "b", "a"},
+				read:    []string{"SORT", "", "ALPHA"},
+				want:    "[a b]",
+				wantTyp: "set",
+			},
+			{
+				key:     "DumpKeyZSet",
+				preset:  []string{"ZADD", "DumpKeyZSet", "2.5", "a", "1", "b"},
+				read:    []string{"ZRANGE", "", "-inf", "+inf", "BYSCORE", "WITHSCORES"},
+				want:    "[[b 1] [a 2.5]]",
+				wantTyp: "zset",
+			},
+			{
+				key:     "DumpKeyHyperLogLog",
+				preset:  []string{"PFADD", "DumpKeyHyperLogLog", "a", "b", "c"},
+				read:    []string{"PFCOUNT"},
+				want:    "3",
+				wantTyp: "string",
+			},
+		}
+
+		for _, test := range tests {
+			if res := send(test.preset...); res.Error() != nil {
+				t.Errorf("%v: unexpected error %v", test.preset, res.Error())
+				continue
+			}
+			payload := send("DUMP", test.key)
+			if payload.IsNull() {
+				t.Errorf("DUMP %s: expected a payload, got nil", test.key)
+				continue
+			}
+			restored := test.key + "Restored"
+			if res := send("RESTORE", restored, "0", payload.String()); res.String() != "OK" {
+				t.Errorf("RESTORE %s: expected OK, got %+v", restored, res)
+				continue
+			}
+			read := slices.Clone(test.read)
+			if len(read) == 1 {
+				read = append(read, restored)
+			} else {
+				read[1] = restored
+			}
+			if got := format(send(read...)); got != test.want {
+				t.Errorf("%v: expected response \"%s\", got \"%s\"", read, test.want, got)
+			}
+			if got := send("TYPE", restored).String(); got != test.wantTyp {
+				t.Errorf("TYPE %s: expected \"%s\", got \"%s\"", restored, test.wantTyp, got)
+			}
+		}
+
+		if res := send("DUMP", "DumpKeyMissing"); !res.IsNull() {
+			t.Errorf("DUMP DumpKeyMissing: expected nil, got %+v", res)
+		}
+
+		payload := send("DUMP", "DumpKeyString").String()
+
+		// compositePayload returns the payload of a composite value with the JSON encoding of a key.
+		compositePayload := func(data string) string {
+			b := binary.AppendUvarint([]byte{7}, uint64(len(data)))
+			b = binary.LittleEndian.AppendUint16(append(b, data...), 1)
+			return string(binary.LittleEndian.AppendUint64(b, crc64.Checksum(b, crc64.MakeTable(crc64.ECMA))))
+		}
+
+		steps := []struct {
+			command []string
+			want    string
+			wantErr string
+		}{
+			// The key is only replaced with REPLACE, and the expiry of the replaced key is not kept.
+			{command: []string{"RESTORE", "DumpKeyString", "0", payload}, wantErr: "BUSYKEY Target key name already exists."},
+			{command: []string{"RESTORE", "DumpKeyString", "100000", payload, "REPLACE"}, want: "OK"},
+			{command: []string{"PEXPIRETIME", "DumpKeyString"}, want: strconv.FormatInt(mockClock.Now().Add(100*time.Second).UnixMilli(), 10)},
+			{command: []string{"RESTORE", "DumpKeyString", "0", payload, "REPLACE"}, want: "OK"},
+			{command: []string{"TTL", "DumpKeyString"}, want: "-1"},
+			{command: []string{"RESTORE", "DumpKeyAbsTTL", strconv.FormatInt(mockClock.Now().Add(100*time.Second).UnixMilli(), 10), payload, "ABSTTL"}, want: "OK"},
+			{command: []string{"PEXPIRETIME", "DumpKeyAbsTTL"}, want: strconv.FormatInt(mockClock.Now().Add(100*time.Second).UnixMilli(), 10)},
+			// A key restored with an absolute TTL in the past is deleted.
+			{command: []string{"RESTORE", "DumpKeyAbsTTL", "1000", payload, "ABSTTL", "REPLACE"}, want: "OK"},
+			{command: []string{"GET", "DumpKeyAbsTTL"}, want: "nil"},
+			// Errors
+			{command: []string{"RESTORE", "DumpKeyCorrupt", "0", payload[:len(payload)-1] + "x"}, wantErr: "DUMP payload version or checksum are wrong"},
+			{command: []string{"RESTORE", "DumpKeyCorrupt", "0", "short"}, wantErr: "DUMP payload version or checksum are wrong"},
+			// A composite value must have the type tag of a registered composite type.
+			{command: []string{"RESTORE", "DumpKeyCorrupt", "0", compositePayload(`{"Value":[1,2],"Type":"unknown"}`)}, wantErr: "Bad data format"},
+			{command: []string{"RESTORE", "DumpKeyCorrupt", "0", compositePayload(`{"Value":{"Type":"stream","Data":{}}}`)}, wantErr: "Bad data format"},
+			{command: []string{"RESTORE", "DumpKeyCorrupt", "0", compositePayload(`{"Value":"AQI=","Type":"bitmap"}`)}, want: "OK"},
+			{command: []string{"TYPE", "DumpKeyCorrupt"}, want: "string"},
+			{command: []string{"RESTORE", "DumpKeyCorrupt", "-1", payload}, wantErr: "Invalid TTL value, must be >= 0"},
+			{command: []string{"RESTORE", "DumpKeyCorrupt", "ttl", payload}, wantErr: "value is not an integer or out of range"},
+			{command: []string{"RESTORE", "DumpKeyCorrupt", "0", payload, "IDLETIME", "-1"}, wantErr: "Invalid IDLETIME value, must be >= 0"},
+			{command: []string{"RESTORE", "DumpKeyCorrupt", "0", payload, "FREQ", "256"}, wantErr: "Invalid FREQ value, must be >= 0 and <= 255"},
+			{command: []string{"RESTORE", "DumpKeyCorrupt", "0", payload, "IDLETIME", "1", "FREQ", "1"}, wantErr: "syntax error"},
+			{command: []string{"RESTORE", "DumpKeyCorrupt", "0", payload, "KEEPTTL"}, wantErr: "syntax error"},
+			{command: []string{"RESTORE", "DumpKeyCorrupt", "0"}, wantErr: constants.WrongArgsResponse},
+			{command: []string{"DUMP"}, wantErr: constants.WrongArgsResponse},
+		}
+
+		for _, step := range steps {
+			res := send(step.command...)
+			if step.wantErr != "" {
+				if res.Error() == nil || !strings.Contains(res.Error().Error(), step.wantErr) {
+					t.Errorf("%v: expected error \"%s\", got %+v", step.command, step.wantErr, res)
+				}
+				continue
+			}
+			if res.Error() != nil {
+				t.Errorf("%v: unexpected error %v", step.command, res.Error())
+				continue
+			}
+			if got := format(res); got != step.want {
+				t.Errorf("%v: expected response \"%s\", got \"%s\"", step.command, step.want, got)
+			}
+		}
+	})
+
+	t.Run("Test_HandleMIGRATE", func(t *testing.T) {
+		t.Parallel()
+
+		targetPort, err := internal.GetFreePort()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		targetServer, err := sugardb.NewSugarDB(
+			sugardb.WithConfig(config.Config{
+				BindAddr:       "localhost",
+				Port:           uint16(targetPort),
+				DataDir:        "",
+				EvictionPolicy: constants.NoEviction,
+				RequirePass:    true,
+				Password:       "target_password",
+			}),
+		)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		go func() {
+			targetServer.Start()
+		}()
+		t.Cleanup(func() {
+			targetServer.ShutDown()
+		})
+
+		connect := func(port int) func(command ...string) resp.Value {
+			conn, err := internal.GetConnection("localhost", port)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() {
+				_ = conn.Close()
+			})
+			client := resp.NewConn(conn)
+			return func(command ...string) resp.Value {
+				values := make([]resp.Value, len(command))
+				for i, token := range command {
+					values[i] = resp.StringValue(token)
+				}
+				if err := client.WriteArray(values); err != nil {
+					t.Fatal(err)
+				}
+				res, _, err := client.ReadValue()
+				if err != nil {
+					t.Fatal(err)
+				}
+				return res
+			}
+		}
+		source := connect(port)
+		target := connect(int(targetPort))
+		if res := target("AUTH", "target_password"); res.Error() != nil {
+			t.Fatal(res.Error())
+		}
+		if res := target("SELECT", "1"); res.Error() != nil {
+			t.Fatal(res.Error())
+		}
+
+		// A port without a listener to test connection errors.
+		closedPort, err := internal.GetFreePort()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		targetPortStr := strconv.Itoa(int(targetPort))
+
+		tests := []struct {
+			send    func(command ...string) resp.Value
+			command []string
+			want    string
+			wantErr string
+		}{
+			{send: source, command: []string{"SET", "MigrateKey1", "value1"}, want: "OK"},
+			{send: source, command: []string{"RPUSH", "MigrateKey2", "a", "b"}, want: "2"},
+			{send: source, command: []string{"SET", "MigrateKey3", "value3", "PX", "100000"}, want: "OK"},
+			// The key is moved to the target database.
+			{send: source, command: []string{"MIGRATE", "localhost", targetPortStr, "MigrateKey1", "1", "5000", "AUTH", "target_password"}, want: "OK"},
+			{send: source, command: []string{"GET", "MigrateKey1"}, want: "nil"},
+			{send: target, command: []string{"GET", "MigrateKey1"}, want: "value1"},
+			// COPY keeps the keys on the source, and the expiry of the keys is migrated.
+			{send: source, command: []string{"MIGRATE", "localhost", targetPortStr, "", "1", "5000", "COPY", "AUTH2", "default", "target_password", "KEYS", "MigrateKey2", "MigrateKey3", "MigrateKeyMissing"}, want: "OK"},
+			{send: source, command: []string{"LRANGE", "MigrateKey2", "0", "-1"}, want: "[a b]"},
+			{send: target, command: []string{"LRANGE", "MigrateKey2", "0", "-1"}, want: "[a b]"},
+			{send: target, command: []string{"GET", "MigrateKey3"}, want: "value3"},
+			{send: target, command: []string{"TTL", "MigrateKey3"}, want: "100"},
+			// Keys that exist on the target are only replaced with REPLACE.
+			{send: source, command: []string{"MIGRATE", "localhost", targetPortStr, "MigrateKey2", "1", "5000", "AUTH", "target_password"}, wantErr: "Target instance replied with error: BUSYKEY"},
+			{send: source, command: []string{"LLEN", "MigrateKey2"}, want: "2"},
+			{send: source, command: []string{"RPUSH", "MigrateKey2", "c"}, want: "3"},
+			{send: source, command: []string{"MIGRATE", "localhost", targetPortStr, "MigrateKey2", "1", "5000", "REPLACE", "AUTH", "target_password"}, want: "OK"},
+			{send: source, command: []string{"LLEN", "MigrateKey2"}, want: "0"},
+			{send: target, command: []string{"LRANGE", "MigrateKey2", "0", "-1"}, want: "[a b c]"},
+			{send: source, command: []string{"MIGRATE", "localhost", targetPortStr, "MigrateKeyMissing", "1", "5000"}, want: "NOKEY"},
+			// A key that expired but was not evicted yet does not exist.
+			{send: source, command: []string{"SET", "MigrateKeyExpired", "value", "PXAT", "1"}, want: "OK"},
+			{send: source, command: []string{"MIGRATE", "localhost", targetPortStr, "MigrateKeyExpired", "1", "5000"}, want: "NOKEY"},
+			// Errors
+			{send: source, command: []string{"MIGRATE", "localhost", targetPortStr, "MigrateKey3", "1", "5000", "AUTH", "wrong_password"}, wantErr: "Target instance replied with error"},
+			{send: source, command: []string{"MIGRATE", "localhost", strconv.Itoa(closedPort), "MigrateKey3", "1", "100"}, wantErr: "IOERR error or timeout connecting to the client"},
+			{send: source, command: []string{"MIGRATE", "localhost", targetPortStr, "MigrateKey3", "1", "5000", "KEYS", "MigrateKey2"}, wantErr: "When using MIGRATE KEYS option, the key argument must be set to the empty string"},
+			{send: source, command: []string{"MIGRATE", "localhost", targetPortStr, "MigrateKey3", "db", "5000"}, wantErr: "value is not an integer or out of range"},
+			{send: source, command: []string{"MIGRATE", "localhost", targetPortStr, "MigrateKey3", "1", "5000", "AUTH"}, wantErr: "syntax error"},
+			{send: source, command: []string{"MIGRATE", "localhost", targetPortStr, "MigrateKey3", "1"}, wantErr: constants.WrongArgsResponse},
+			{send: source, command: []string{"EVAL", "return redis.call('MIGRATE', 'localhost', ARGV[1], KEYS[1], '1', '5000')", "1", "MigrateKey3", targetPortStr}, wantErr: "MIGRATE is not allowed in transactions and scripts"},
+			{send: source, command: []string{"MULTI"}, want: "OK"},
+			{send: source, command: []string{"MIGRATE", "localhost", targetPortStr, "MigrateKey3", "1", "5000", "AUTH", "target_password"}, want: "QUEUED"},
+			{send: source, command: []string{"EXEC"}, want: "[Error MIGRATE is not allowed in transactions and scripts]"},
+			{send: source, command: []string{"GET", "MigrateKey3"}, want: "value3"},
+		}
+
+		for _, test := range tests {
+			res := test.send(test.command...)
+			if test.wantErr != "" {
+				if res.Error() == nil || !strings.Contains(res.Error().Error(), test.wantErr) {
+					t.Errorf("%v: expected error \"%s\", got %+v", test.command, test.wantErr, res)
+				}
+				continue
+			}
+			if res.Error() != nil {
+				t.Errorf("%v: unexpected error %v", test.command, res.Error())
+				continue
+			}
+			if got := format(res); got != test.want {
+				t.Errorf("%v: expected response \"%s\", got \"%s\"", test.command, test.want, got)
+			}
+		}
+
+		// A key that is modified while it's sent to the target is not deleted. The fake target modifies the key
+		// on the source before it replies to EXEC.
+		listener, err := net.Listen("tcp", "localhost:0")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		t.Cleanup(func() {
+			_ = listener.Close()
+		})
+		writerConn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		t.Cleanup(func() {
+			_ = writerConn.Close()
+		})
+		go func() {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer func() {
+				_ = conn.Close()
+			}()
+			client, writer := resp.NewConn(conn), resp.NewConn(writerConn)
+			for {
+				command, _, err := client.ReadValue()
+				if err != nil {
+					return
+				}
+				switch strings.ToUpper(command.Array()[0].String()) {
+				case "RESTORE":
+					_ = client.WriteSimpleString("QUEUED")
+				case "EXEC":
+					_ = writer.WriteArray([]resp.Value{
+						resp.StringValue("SET"), resp.StringValue("MigrateKeyModified"), resp.StringValue("modified"),
+					})
+					_, _, _ = writer.ReadValue()
+					_ = client.WriteArray([]resp.Value{resp.SimpleStringValue("OK")})
+				default:
+					_ = client.WriteSimpleString("OK")
+				}
+			}
+		}()
+		fakePort := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+		if res := source("SET", "MigrateKeyModified", "value"); res.Error() != nil {
+			t.Fatal(res.Error())
+		}
+		res := source("MIGRATE", "localhost", fakePort, "MigrateKeyModified", "0", "5000")
+		if res.Error() == nil || !strings.Contains(res.Error().Error(), "was modified during the migration") {
+			t.Errorf("expected modified key error, got %+v", res)
+		}
+		if got := format(source("GET", "MigrateKeyModified")); got != "modified" {
+			t.Errorf("expected the modified key to be kept, got \"%s\"", got)
+		}
+	})
+
+	t.Run("Test_BitmapValues", func(t *testing.T) {
+		t.Parallel()
+
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		client := resp.NewConn(conn)
+
+		// The keys written by the bitmap commands hold raw bytes, which the generic string commands read.
+		tests := []struct {
+			command []string
+			want    string
+		}{
+			{command: []string{"SET", "BitmapValueKey1", "123"}, want: "OK"},
+			{command: []string{"SETBIT", "BitmapValueKey1", "0", "0"}, want: "0"},
+			{command: []string{"INCR", "BitmapValueKey1"}, want: "124"},
+			{command: []string{"SETBIT", "BitmapValueKey1", "0", "0"}, want: "0"},
+			{command: []string{"INCRBY", "BitmapValueKey1", "6"}, want: "130"},
+			{command: []string{"SETBIT", "BitmapValueKey1", "0", "0"}, want: "0"},
+			{command: []string{"DECR", "BitmapValueKey1"}, want: "129"},
+			{command: []string{"SETBIT", "BitmapValueKey1", "0", "0"}, want: "0"},
+			{command: []string{"DECRBY", "BitmapValueKey1", "9"}, want: "120"},
+			{command: []string{"SETBIT", "BitmapValueKey1", "0", "0"}, want: "0"},
+			{command: []string{"INCRBYFLOAT", "BitmapValueKey1", "0.5"}, want: "120.5"},
+			{command: []string{"SETBIT", "BitmapValueKey2", "7", "1"}, want: "0"},
+			{command: []string{"GETEX", "BitmapValueKey2", "PX", "100000"}, want: "\x01"},
+			{command: []string{"SET", "BitmapValueKey2", "value", "GET"}, want: "\x01"},
+			{command: []string{"SETBIT", "BitmapValueKey3", "6", "1"}, want: "0"},
+			{command: []string{"GETDEL", "BitmapValueKey3"}, want: "\x02"},
+			// SORT BY reads the weights held by bitmaps.
+			{command: []string{"RPUSH", "BitmapValueKeyList", "a", "b"}, want: "2"},
+			{command: []string{"SET", "BitmapValueKeyWeight_a", "2"}, want: "OK"},
+			{command: []string{"SETBIT", "BitmapValueKeyWeight_a", "0", "0"}, want: "0"},
+			{command: []string{"SET", "BitmapValueKeyWeight_b", "1"}, want: "OK"},
+			{command: []string{"SORT", "BitmapValueKeyList", "BY", "BitmapValueKeyWeight_*"}, want: "[b a]"},
+		}
+
+		for _, test := range tests {
+			command := make([]resp.Value, len(test.command))
+			for i, token := range test.command {
+				command[i] = resp.StringValue(token)
+			}
+			if err = client.WriteArray(command); err != nil {
+				t.Error(err)
+				return
+			}
+			res, _, err := client.ReadValue()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if res.Error() != nil {
+				t.Errorf("%q: unexpected error %v", test.command, res.Error())
+				continue
+			}
+			if got := format(res); got != test.want {
+				t.Errorf("%q: expected response %q, got %q", test.command, test.want, got)
+			}
+		}
+	})
 }
 
 // Certain commands will need to be tested in a server with an eviction policy.
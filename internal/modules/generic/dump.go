@@ -0,0 +1,343 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generic
+
+import (
+	"cmp"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc64"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/echovault/sugardb/internal"
+	"github.com/echovault/sugardb/internal/modules/set"
+	"github.com/echovault/sugardb/internal/modules/sorted_set"
+)
+
+// A DUMP payload is the type of the value, the encoded value, the version of the format as a little endian
+// uint16 and the CRC-64 of everything before it as a little endian uint64.
+// The version must be incremented when the encoding of a type changes. Payloads created by a newer version
+// are rejected because they can't be decoded.
+const dumpVersion uint16 = 1
+
+const (
+	dumpTypeString byte = iota
+	dumpTypeInteger
+	dumpTypeFloat
+	dumpTypeHash
+	dumpTypeList
+	dumpTypeSet
+	dumpTypeSortedSet
+	// dumpTypeComposite is any other composite type, like a stream or a bitmap. It's encoded in the JSON
+	// format used for snapshots and decoded with the function registered for its type tag.
+	dumpTypeComposite
+)
+
+var crcTable = crc64.MakeTable(crc64.ECMA)
+
+var errDumpPayload = errors.New("DUMP payload version or checksum are wrong")
+var errDumpFormat = errors.New("Bad data format")
+
+// dumpValue serializes the value of a key into a DUMP payload.
+func dumpValue(value interface{}) ([]byte, error) {
+	b, err := appendDumpValue(nil, value)
+	if err != nil {
+		return nil, err
+	}
+	b = binary.LittleEndian.AppendUint16(b, dumpVersion)
+	return binary.LittleEndian.AppendUint64(b, crc64.Checksum(b, crcTable)), nil
+}
+
+// restoreValue checks the version and checksum of a DUMP payload and decodes the value.
+func restoreValue(payload []byte) (interface{}, error) {
+	if len(payload) < 10 {
+		return nil, errDumpPayload
+	}
+	footer := len(payload) - 10
+	version := binary.LittleEndian.Uint16(payload[footer:])
+	checksum := binary.LittleEndian.Uint64(payload[footer+2:])
+	if version > dumpVersion || checksum != crc64.Checksum(payload[:footer+2], crcTable) {
+		return nil, errDumpPayload
+	}
+
+	r := &dumpReader{b: payload[:footer]}
+	value, err := r.readValue()
+	if err != nil || len(r.b) != 0 {
+		return nil, errDumpFormat
+	}
+	return value, nil
+}
+
+func appendDumpString(b []byte, s string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+func appendDumpFloat(b []byte, f float64) []byte {
+	return binary.LittleEndian.AppendUint64(b, math.Float64bits(f))
+}
+
+func appendDumpValue(b []byte, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		return appendDumpString(append(b, dumpTypeString), v), nil
+
+	case int:
+		return binary.AppendVarint(append(b, dumpTypeInteger), int64(v)), nil
+
+	case int64:
+		return binary.AppendVarint(append(b, dumpTypeInteger), v), nil
+
+	case float64:
+		return appendDumpFloat(append(b, dumpTypeFloat), v), nil
+
+	case map[string]interface{}:
+		// Encode the fields in order so that equal hashes have equal payloads.
+		fields := make([]string, 0, len(v))
+		for field := range v {
+			fields = append(fields, field)
+		}
+		slices.Sort(fields)
+		b = binary.AppendUvarint(append(b, dumpTypeHash), uint64(len(fields)))
+		var err error
+		for _, field := range fields {
+			b = appendDumpString(b, field)
+			if b, err = appendDumpValue(b, v[field]); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+
+	case []string:
+		b = binary.AppendUvarint(append(b, dumpTypeList), uint64(len(v)))
+		for _, element := range v {
+			b = appendDumpString(b, element)
+		}
+		return b, nil
+
+	case *set.Set:
+		members := v.GetAll()
+		slices.Sort(members)
+		b = binary.AppendUvarint(append(b, dumpTypeSet), uint64(len(members)))
+		for _, member := range members {
+			b = appendDumpString(b, member)
+		}
+		return b, nil
+
+	case *sorted_set.SortedSet:
+		members := v.GetAll()
+		slices.SortFunc(members, func(a, b sorted_set.MemberParam) int {
+			if a.Score != b.Score {
+				return cmp.Compare(a.Score, b.Score)
+			}
+			return strings.Compare(string(a.Value), string(b.Value))
+		})
+		b = binary.AppendUvarint(append(b, dumpTypeSortedSet), uint64(len(members)))
+		for _, member := range members {
+			b = appendDumpString(b, string(member.Value))
+			b = appendDumpFloat(b, float64(member.Score))
+		}
+		return b, nil
+
+	case internal.CompositeValue:
+		data, err := json.Marshal(internal.KeyData{Value: v})
+		if err != nil {
+			return nil, err
+		}
+		return appendDumpString(append(b, dumpTypeComposite), string(data)), nil
+
+	default:
+		return nil, fmt.Errorf("cannot dump value of type %T", value)
+	}
+}
+
+// dumpReader decodes the values of a DUMP payload. The decoded bytes are removed from b.
+type dumpReader struct {
+	b []byte
+}
+
+func (r *dumpReader) readByte() (byte, error) {
+	if len(r.b) == 0 {
+		return 0, errDumpFormat
+	}
+	c := r.b[0]
+	r.b = r.b[1:]
+	return c, nil
+}
+
+func (r *dumpReader) readUvarint() (uint64, error) {
+	n, size := binary.Uvarint(r.b)
+	if size <= 0 {
+		return 0, errDumpFormat
+	}
+	r.b = r.b[size:]
+	return n, nil
+}
+
+func (r *dumpReader) readVarint() (int64, error) {
+	n, size := binary.Varint(r.b)
+	if size <= 0 {
+		return 0, errDumpFormat
+	}
+	r.b = r.b[size:]
+	return n, nil
+}
+
+func (r *dumpReader) readFloat() (float64, error) {
+	if len(r.b) < 8 {
+		return 0, errDumpFormat
+	}
+	f := math.Float64frombits(binary.LittleEndian.Uint64(r.b))
+	r.b = r.b[8:]
+	return f, nil
+}
+
+func (r *dumpReader) readString() (string, error) {
+	n, err := r.readUvarint()
+	if err != nil {
+		return "", err
+	}
+	if uint64(len(r.b)) < n {
+		return "", errDumpFormat
+	}
+	s := string(r.b[:n])
+	r.b = r.b[n:]
+	return s, nil
+}
+
+// readLength reads the number of items of a collection. Each item takes at least one byte, so a length larger
+// than the rest of the payload is rejected before anything is allocated for it.
+func (r *dumpReader) readLength() (int, error) {
+	n, err := r.readUvarint()
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(len(r.b)) {
+		return 0, errDumpFormat
+	}
+	return int(n), nil
+}
+
+func (r *dumpReader) readStrings() ([]string, error) {
+	n, err := r.readLength()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]string, n)
+	for i := range res {
+		if res[i], err = r.readString(); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (r *dumpReader) readValue() (interface{}, error) {
+	valueType, err := r.readByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch valueType {
+	case dumpTypeString:
+		return r.readString()
+
+	case dumpTypeInteger:
+		n, err := r.readVarint()
+		return int(n), err
+
+	case dumpTypeFloat:
+		return r.readFloat()
+
+	case dumpTypeHash:
+		n, err := r.readLength()
+		if err != nil {
+			return nil, err
+		}
+		hash := make(map[string]interface{}, n)
+		for i := 0; i < n; i++ {
+			field, err := r.readString()
+			if err != nil {
+				return nil, err
+			}
+			value, err := r.readValue()
+			if err != nil {
+				return nil, err
+			}
+			switch value.(type) {
+			case string, int, float64:
+			default:
+				return nil, errDumpFormat
+			}
+			hash[field] = value
+		}
+		return hash, nil
+
+	case dumpTypeList:
+		list, err := r.readStrings()
+		if err != nil || len(list) == 0 {
+			return nil, errDumpFormat
+		}
+		return list, nil
+
+	case dumpTypeSet:
+		members, err := r.readStrings()
+		if err != nil || len(members) == 0 {
+			return nil, errDumpFormat
+		}
+		return set.NewSet(members), nil
+
+	case dumpTypeSortedSet:
+		n, err := r.readLength()
+		if err != nil || n == 0 {
+			return nil, errDumpFormat
+		}
+		members := make([]sorted_set.MemberParam, n)
+		for i := range members {
+			value, err := r.readString()
+			if err != nil {
+				return nil, err
+			}
+			score, err := r.readFloat()
+			if err != nil {
+				return nil, err
+			}
+			members[i] = sorted_set.MemberParam{Value: sorted_set.Value(value), Score: sorted_set.Score(score)}
+		}
+		return sorted_set.NewSortedSet(members), nil
+
+	case dumpTypeComposite:
+		data, err := r.readString()
+		if err != nil {
+			return nil, err
+		}
+		// The value must be decoded by the function registered for its type tag.
+		var keyData internal.KeyData
+		if err = json.Unmarshal([]byte(data), &keyData); err != nil {
+			return nil, errDumpFormat
+		}
+		if _, ok := keyData.Value.(internal.CompositeValue); !ok {
+			return nil, errDumpFormat
+		}
+		return keyData.Value, nil
+
+	default:
+		return nil, errDumpFormat
+	}
+}
@@ -16,6 +16,7 @@ package generic
 
 import (
 	"errors"
+	"strings"
 
 	"github.com/echovault/sugardb/internal"
 	"github.com/echovault/sugardb/internal/constants"
@@ -290,3 +291,122 @@ func moveKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
 		WriteKeys: []string{cmd[1]},
 	}, nil
 }
+
+func keysKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	if len(cmd) != 2 {
+		return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+	}
+	return internal.KeyExtractionFuncResult{
+		Channels:  make([]string, 0),
+		ReadKeys:  make([]string, 0),
+		WriteKeys: make([]string, 0),
+	}, nil
+}
+
+func scanKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	if len(cmd) < 2 || len(cmd) > 8 {
+		return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+	}
+	return internal.KeyExtractionFuncResult{
+		Channels:  make([]string, 0),
+		ReadKeys:  make([]string, 0),
+		WriteKeys: make([]string, 0),
+	}, nil
+}
+
+func sortKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	return sortKeys(cmd, false)
+}
+
+func sortROKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	return sortKeys(cmd, true)
+}
+
+// sortKeys returns the keys of SORT and SORT_RO. The BY and GET patterns are returned as read keys with the
+// "->field" suffix removed, so the ACL can check them against the key patterns the connection is allowed to read.
+func sortKeys(cmd []string, readOnly bool) (internal.KeyExtractionFuncResult, error) {
+	if len(cmd) < 2 {
+		return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+	}
+	options, err := getSortCommandOptions(cmd[2:], SortOptions{readOnly: readOnly})
+	if err != nil {
+		return internal.KeyExtractionFuncResult{}, err
+	}
+
+	readKeys := []string{cmd[1]}
+	// A BY pattern without "*" skips sorting, so it does not read any keys.
+	if strings.Contains(options.by, "*") {
+		key, _ := splitSortPattern(options.by, "*")
+		readKeys = append(readKeys, key)
+	}
+	for _, pattern := range options.get {
+		if pattern != "#" {
+			key, _ := splitSortPattern(pattern, "*")
+			readKeys = append(readKeys, key)
+		}
+	}
+
+	writeKeys := make([]string, 0)
+	if options.store != "" {
+		writeKeys = append(writeKeys, options.store)
+	}
+
+	return internal.KeyExtractionFuncResult{
+		Channels:  make([]string, 0),
+		ReadKeys:  readKeys,
+		WriteKeys: writeKeys,
+	}, nil
+}
+
+func dumpKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	if len(cmd) != 2 {
+		return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+	}
+	return internal.KeyExtractionFuncResult{
+		Channels:  make([]string, 0),
+		ReadKeys:  cmd[1:],
+		WriteKeys: make([]string, 0),
+	}, nil
+}
+
+func restoreKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	if len(cmd) < 4 {
+		return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+	}
+	return internal.KeyExtractionFuncResult{
+		Channels:  make([]string, 0),
+		ReadKeys:  make([]string, 0),
+		WriteKeys: cmd[1:2],
+	}, nil
+}
+
+func migrateKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	if len(cmd) < 6 {
+		return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+	}
+	options, err := getMigrateCommandOptions(cmd[6:], MigrateOptions{})
+	if err != nil {
+		return internal.KeyExtractionFuncResult{}, err
+	}
+
+	keys := []string{cmd[3]}
+	if len(options.keys) > 0 {
+		if cmd[3] != "" {
+			return internal.KeyExtractionFuncResult{}, errors.New(
+				"When using MIGRATE KEYS option, the key argument must be set to the empty string")
+		}
+		keys = options.keys
+	}
+
+	// The keys are deleted after they are migrated, unless COPY is provided.
+	writeKeys := make([]string, 0)
+	if !options.copy {
+		writeKeys = keys
+	}
+
+	return internal.KeyExtractionFuncResult{
+		Channels:  make([]string, 0),
+		ReadKeys:  keys,
+		WriteKeys: writeKeys,
+	}, nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generic
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/tidwall/resp"
+)
+
+// migrateTarget is a RESP connection to the server that MIGRATE moves keys to.
+type migrateTarget struct {
+	conn    net.Conn
+	client  *resp.Conn
+	timeout time.Duration
+}
+
+func dialMigrateTarget(address string, timeout time.Duration) (*migrateTarget, error) {
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("IOERR error or timeout connecting to the client: %v", err)
+	}
+	return &migrateTarget{conn: conn, client: resp.NewConn(conn), timeout: timeout}, nil
+}
+
+// do sends the command to the target and returns its reply. Each command must complete within the timeout.
+// An error reply is returned as an error.
+func (target *migrateTarget) do(command ...string) (resp.Value, error) {
+	if err := target.conn.SetDeadline(time.Now().Add(target.timeout)); err != nil {
+		return resp.Value{}, err
+	}
+
+	values := make([]resp.Value, len(command))
+	for i, token := range command {
+		values[i] = resp.StringValue(token)
+	}
+	if err := target.client.WriteArray(values); err != nil {
+		return resp.Value{}, fmt.Errorf("IOERR error or timeout writing to target instance: %v", err)
+	}
+
+	res, _, err := target.client.ReadValue()
+	if err != nil {
+		return resp.Value{}, fmt.Errorf("IOERR error or timeout reading from target instance: %v", err)
+	}
+	if res.Error() != nil {
+		return resp.Value{}, targetError(res.Error())
+	}
+	return res, nil
+}
+
+func (target *migrateTarget) close() {
+	_ = target.conn.Close()
+}
+
+// targetError wraps an error reply of the target. SugarDB error replies are prefixed with "Error".
+func targetError(err error) error {
+	return fmt.Errorf("Target instance replied with error: %s", strings.TrimPrefix(err.Error(), "Error "))
+}
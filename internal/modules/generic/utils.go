@@ -17,7 +17,11 @@ package generic
 import (
 	"errors"
 	"fmt"
+	"github.com/echovault/sugardb/internal"
 	"github.com/echovault/sugardb/internal/clock"
+	"github.com/echovault/sugardb/internal/modules/acl"
+	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -31,7 +35,34 @@ type SetOptions struct {
 
 type CopyOptions struct {
 	database string
-	replace bool
+	replace  bool
+}
+
+type SortOptions struct {
+	by       string
+	limit    bool
+	offset   int
+	count    int
+	get      []string
+	desc     bool
+	alpha    bool
+	store    string
+	readOnly bool
+}
+
+type RestoreOptions struct {
+	replace  bool
+	absTTL   bool
+	idleTime int // Seconds since the last access of the key. -1 when not provided.
+	freq     int // Access frequency of the key. -1 when not provided.
+}
+
+type MigrateOptions struct {
+	copy     bool
+	replace  bool
+	username string // Only set with AUTH2.
+	password string
+	keys     []string
 }
 
 func getSetCommandOptions(clock clock.Clock, cmd []string, options SetOptions) (SetOptions, error) {
@@ -127,11 +158,11 @@ func getCopyCommandOptions(cmd []string, options CopyOptions) (CopyOptions, erro
 		return options, nil
 	}
 
-	switch strings.ToLower(cmd[0]){
+	switch strings.ToLower(cmd[0]) {
 	case "replace":
 		options.replace = true
 		return getCopyCommandOptions(cmd[1:], options)
-		
+
 	case "db":
 		if len(cmd) < 2 {
 			return CopyOptions{}, errors.New("syntax error")
@@ -141,12 +172,264 @@ func getCopyCommandOptions(cmd []string, options CopyOptions) (CopyOptions, erro
 		if err != nil {
 			return CopyOptions{}, errors.New("value is not an integer or out of range")
 		}
-		
-		options.database = cmd [1]
+
+		options.database = cmd[1]
 		return getCopyCommandOptions(cmd[2:], options)
-		
 
 	default:
 		return CopyOptions{}, fmt.Errorf("unknown option %s for copy command", strings.ToUpper(cmd[0]))
 	}
-}
\ No newline at end of file
+}
+
+func getSortCommandOptions(cmd []string, options SortOptions) (SortOptions, error) {
+	if len(cmd) == 0 {
+		return options, nil
+	}
+
+	switch strings.ToLower(cmd[0]) {
+	case "by":
+		if len(cmd) < 2 {
+			return SortOptions{}, errors.New("syntax error")
+		}
+		options.by = cmd[1]
+		return getSortCommandOptions(cmd[2:], options)
+
+	case "limit":
+		if len(cmd) < 3 {
+			return SortOptions{}, errors.New("syntax error")
+		}
+		offset, err := strconv.Atoi(cmd[1])
+		if err != nil {
+			return SortOptions{}, errors.New("value is not an integer or out of range")
+		}
+		count, err := strconv.Atoi(cmd[2])
+		if err != nil {
+			return SortOptions{}, errors.New("value is not an integer or out of range")
+		}
+		options.limit, options.offset, options.count = true, offset, count
+		return getSortCommandOptions(cmd[3:], options)
+
+	case "get":
+		if len(cmd) < 2 {
+			return SortOptions{}, errors.New("syntax error")
+		}
+		options.get = append(options.get, cmd[1])
+		return getSortCommandOptions(cmd[2:], options)
+
+	case "asc":
+		options.desc = false
+		return getSortCommandOptions(cmd[1:], options)
+
+	case "desc":
+		options.desc = true
+		return getSortCommandOptions(cmd[1:], options)
+
+	case "alpha":
+		options.alpha = true
+		return getSortCommandOptions(cmd[1:], options)
+
+	case "store":
+		// SORT_RO can't store the result.
+		if len(cmd) < 2 || options.readOnly {
+			return SortOptions{}, errors.New("syntax error")
+		}
+		options.store = cmd[1]
+		return getSortCommandOptions(cmd[2:], options)
+
+	default:
+		return SortOptions{}, errors.New("syntax error")
+	}
+}
+
+func getRestoreCommandOptions(cmd []string, options RestoreOptions) (RestoreOptions, error) {
+	if len(cmd) == 0 {
+		return options, nil
+	}
+
+	switch strings.ToLower(cmd[0]) {
+	case "replace":
+		options.replace = true
+		return getRestoreCommandOptions(cmd[1:], options)
+
+	case "absttl":
+		options.absTTL = true
+		return getRestoreCommandOptions(cmd[1:], options)
+
+	case "idletime":
+		// IDLETIME and FREQ seed different eviction policies, so only one of them can be provided.
+		if len(cmd) < 2 || options.freq != -1 {
+			return RestoreOptions{}, errors.New("syntax error")
+		}
+		idleTime, err := strconv.Atoi(cmd[1])
+		if err != nil {
+			return RestoreOptions{}, errors.New("value is not an integer or out of range")
+		}
+		if idleTime < 0 {
+			return RestoreOptions{}, errors.New("Invalid IDLETIME value, must be >= 0")
+		}
+		options.idleTime = idleTime
+		return getRestoreCommandOptions(cmd[2:], options)
+
+	case "freq":
+		if len(cmd) < 2 || options.idleTime != -1 {
+			return RestoreOptions{}, errors.New("syntax error")
+		}
+		freq, err := strconv.Atoi(cmd[1])
+		if err != nil {
+			return RestoreOptions{}, errors.New("value is not an integer or out of range")
+		}
+		if freq < 0 || freq > 255 {
+			return RestoreOptions{}, errors.New("Invalid FREQ value, must be >= 0 and <= 255")
+		}
+		options.freq = freq
+		return getRestoreCommandOptions(cmd[2:], options)
+
+	default:
+		return RestoreOptions{}, errors.New("syntax error")
+	}
+}
+
+func getMigrateCommandOptions(cmd []string, options MigrateOptions) (MigrateOptions, error) {
+	if len(cmd) == 0 {
+		return options, nil
+	}
+
+	switch strings.ToLower(cmd[0]) {
+	case "copy":
+		options.copy = true
+		return getMigrateCommandOptions(cmd[1:], options)
+
+	case "replace":
+		options.replace = true
+		return getMigrateCommandOptions(cmd[1:], options)
+
+	case "auth":
+		if len(cmd) < 2 {
+			return MigrateOptions{}, errors.New("syntax error")
+		}
+		options.username, options.password = "", cmd[1]
+		return getMigrateCommandOptions(cmd[2:], options)
+
+	case "auth2":
+		if len(cmd) < 3 {
+			return MigrateOptions{}, errors.New("syntax error")
+		}
+		options.username, options.password = cmd[1], cmd[2]
+		return getMigrateCommandOptions(cmd[3:], options)
+
+	case "keys":
+		// KEYS is always the last option because all the arguments after it are keys.
+		if len(cmd) < 2 {
+			return MigrateOptions{}, errors.New("syntax error")
+		}
+		options.keys = cmd[1:]
+		return options, nil
+
+	default:
+		return MigrateOptions{}, errors.New("syntax error")
+	}
+}
+
+// splitSortPattern returns the key and the hash field of a BY or GET pattern of the SORT command after replacing the
+// first "*" with the element. The field is empty when the pattern does not reference a hash field with "->".
+func splitSortPattern(pattern string, element string) (string, string) {
+	star := strings.Index(pattern, "*")
+	if star == -1 {
+		return pattern, ""
+	}
+	key, field := pattern, ""
+	if arrow := strings.Index(pattern[star+1:], "->"); arrow != -1 && star+1+arrow+2 < len(pattern) {
+		key, field = pattern[:star+1+arrow], pattern[star+1+arrow+2:]
+	}
+	return strings.Replace(key, "*", element, 1), field
+}
+
+// sortValueToString returns the string representation of a string value or hash field used by the SORT command.
+// It returns false when the value is missing or holds another type.
+func sortValueToString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case int:
+		return strconv.Itoa(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case fmt.Stringer:
+		// Bitmaps hold the raw bytes of a string.
+		return v.String(), true
+	default:
+		return "", false
+	}
+}
+
+// valueResponse returns the response of the commands that return the value of a string key.
+// Bitmaps hold raw bytes, so they're returned as bulk strings.
+func valueResponse(value interface{}) []byte {
+	if bitmap, ok := value.(fmt.Stringer); ok {
+		str := bitmap.String()
+		return []byte(fmt.Sprintf("$%d\r\n%s\r\n", len(str), str))
+	}
+	return []byte(fmt.Sprintf("+%v\r\n", value))
+}
+
+// getValueType returns the string representation of the type of the value as reported by the TYPE command.
+func getValueType(value interface{}) string {
+	t := reflect.TypeOf(value)
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Int:
+		return "integer"
+	case reflect.Float64:
+		return "float"
+	case reflect.Slice:
+		return "list"
+	case reflect.Map:
+		return "hash"
+	case reflect.Pointer:
+		if t.Elem().Name() == "Set" {
+			return "set"
+		} else if t.Elem().Name() == "SortedSet" {
+			return "zset"
+		} else if t.Elem().Name() == "Stream" {
+			return "stream"
+		} else if t.Elem().Name() == "Bitmap" || t.Elem().Name() == "HyperLogLog" {
+			return "string"
+		}
+		return t.Elem().Name()
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// filterReadableKeys removes the keys that the connection is not allowed to read according to the ACL.
+// Commands triggered from the embedded API are not filtered.
+func filterReadableKeys(params internal.HandlerFuncParams, keys []string) []string {
+	if params.Connection == nil {
+		return keys
+	}
+	accessControlList, ok := params.GetACL().(*acl.ACL)
+	if !ok || accessControlList == nil {
+		return keys
+	}
+	return accessControlList.FilterReadKeys(params.Connection, keys)
+}
+
+// authorizeReadKeys returns an error if the connection is not allowed to read one of the keys according to the ACL.
+// It's used for the keys derived from the values of other keys, which are not known ahead of the ACL authorization.
+func authorizeReadKeys(params internal.HandlerFuncParams, keys []string) error {
+	readable := make(map[string]bool, len(keys))
+	for _, key := range filterReadableKeys(params, keys) {
+		readable[key] = true
+	}
+	var notAllowed []string
+	for _, key := range keys {
+		if !readable[key] && !slices.Contains(notAllowed, fmt.Sprintf("%s~%s", "%R", key)) {
+			notAllowed = append(notAllowed, fmt.Sprintf("%s~%s", "%R", key))
+		}
+	}
+	if len(notAllowed) > 0 {
+		return fmt.Errorf("not authorised to access the following read keys: %+v", notAllowed)
+	}
+	return nil
+}
@@ -0,0 +1,620 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/echovault/sugardb/internal"
+	"github.com/echovault/sugardb/internal/constants"
+	"github.com/echovault/sugardb/internal/modules/sorted_set"
+)
+
+// getSortedSet returns the sorted set at the key, or nil if the key does not exist.
+func getSortedSet(params internal.HandlerFuncParams, key string) (*sorted_set.SortedSet, error) {
+	if !params.KeysExist(params.Context, []string{key})[key] {
+		return nil, nil
+	}
+	set, ok := params.GetValues(params.Context, []string{key})[key].(*sorted_set.SortedSet)
+	if !ok {
+		return nil, fmt.Errorf("value at %s is not a sorted set", key)
+	}
+	return set, nil
+}
+
+// position returns the coordinates of the member, or false if the member does not exist.
+func position(set *sorted_set.SortedSet, member string) (Coordinates, bool) {
+	if set == nil || !set.Contains(sorted_set.Value(member)) {
+		return Coordinates{}, false
+	}
+	return Decode(uint64(set.Get(sorted_set.Value(member)).Score)), true
+}
+
+// parseUnit returns the number of meters in the unit.
+func parseUnit(unit string) (float64, error) {
+	switch strings.ToLower(unit) {
+	case "m":
+		return 1, nil
+	case "km":
+		return 1000, nil
+	case "ft":
+		return 0.3048, nil
+	case "mi":
+		return 1609.34, nil
+	}
+	return 0, errors.New("unsupported unit provided. please use M, KM, FT, MI")
+}
+
+func parseFloat(arg string) (float64, error) {
+	f, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return 0, errors.New("value is not a valid float")
+	}
+	return f, nil
+}
+
+func formatFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func formatDistance(distance, unit float64) string {
+	s := fmt.Sprintf("%.4f", distance/unit)
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func handleGEOADD(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := geoaddKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key := keys.WriteKeys[0]
+
+	var updatePolicy interface{}
+	changed := false
+	i := 2
+	for ; i < len(params.Command); i++ {
+		option := strings.ToLower(params.Command[i])
+		if option == "nx" || option == "xx" {
+			if updatePolicy != nil && updatePolicy != option {
+				return nil, errors.New("XX and NX options at the same time are not compatible")
+			}
+			updatePolicy = option
+			continue
+		}
+		if option == "ch" {
+			changed = true
+			continue
+		}
+		break
+	}
+
+	args := params.Command[i:]
+	if len(args) == 0 || len(args)%3 != 0 {
+		return nil, errors.New("syntax error")
+	}
+	members := make([]sorted_set.MemberParam, 0, len(args)/3)
+	for j := 0; j < len(args); j += 3 {
+		longitude, err := parseFloat(args[j])
+		if err != nil {
+			return nil, err
+		}
+		latitude, err := parseFloat(args[j+1])
+		if err != nil {
+			return nil, err
+		}
+		if err = ValidateCoordinates(longitude, latitude); err != nil {
+			return nil, err
+		}
+		members = append(members, sorted_set.MemberParam{
+			Value: sorted_set.Value(args[j+2]),
+			Score: sorted_set.Score(Encode(longitude, latitude)),
+		})
+	}
+
+	set, err := getSortedSet(params, key)
+	if err != nil {
+		return nil, err
+	}
+	if set == nil {
+		set = sorted_set.NewSortedSet(nil)
+	}
+
+	// Count the members here because the count of AddOrUpdate includes updated members without CH.
+	count := 0
+	for _, member := range members {
+		exists := set.Contains(member.Value)
+		switch {
+		case !exists && updatePolicy != "xx":
+			count++
+		case exists && updatePolicy != "nx" && changed && set.Get(member.Value).Score != member.Score:
+			count++
+		}
+	}
+	if _, err = set.AddOrUpdate(members, updatePolicy, nil, nil, nil); err != nil {
+		return nil, err
+	}
+	if set.Cardinality() > 0 {
+		if err = params.SetValues(params.Context, map[string]interface{}{key: set}); err != nil {
+			return nil, err
+		}
+	}
+
+	return []byte(fmt.Sprintf(":%d\r\n", count)), nil
+}
+
+func handleGEOPOS(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := geoposKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := getSortedSet(params, keys.ReadKeys[0])
+	if err != nil {
+		return nil, err
+	}
+
+	res := fmt.Sprintf("*%d\r\n", len(params.Command[2:]))
+	for _, member := range params.Command[2:] {
+		coordinates, ok := position(set, member)
+		if !ok {
+			res += "*-1\r\n"
+			continue
+		}
+		res += "*2\r\n" + formatFloat(coordinates.Longitude) + formatFloat(coordinates.Latitude)
+	}
+
+	return []byte(res), nil
+}
+
+func handleGEODIST(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := geodistKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+
+	unit := 1.0
+	if len(params.Command) == 5 {
+		if unit, err = parseUnit(params.Command[4]); err != nil {
+			return nil, err
+		}
+	}
+
+	set, err := getSortedSet(params, keys.ReadKeys[0])
+	if err != nil {
+		return nil, err
+	}
+
+	a, ok := position(set, params.Command[2])
+	if !ok {
+		return []byte("$-1\r\n"), nil
+	}
+	b, ok := position(set, params.Command[3])
+	if !ok {
+		return []byte("$-1\r\n"), nil
+	}
+
+	return []byte(formatDistance(Distance(a, b), unit)), nil
+}
+
+func handleGEOHASH(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := geohashKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := getSortedSet(params, keys.ReadKeys[0])
+	if err != nil {
+		return nil, err
+	}
+
+	res := fmt.Sprintf("*%d\r\n", len(params.Command[2:]))
+	for _, member := range params.Command[2:] {
+		coordinates, ok := position(set, member)
+		if !ok {
+			res += "$-1\r\n"
+			continue
+		}
+		hash := HashString(coordinates)
+		res += fmt.Sprintf("$%d\r\n%s\r\n", len(hash), hash)
+	}
+
+	return []byte(res), nil
+}
+
+type searchOptions struct {
+	fromMember string
+	fromLonLat *Coordinates
+	radius     *float64
+	box        *[2]float64 // Width and height.
+	unit       float64
+	sort       string // "asc", "desc" or empty to return the results in the order they are found.
+	count      int    // 0 means no limit.
+	any        bool   // Stop searching once count results are found.
+	withCoord  bool
+	withDist   bool
+	withHash   bool
+	storeDist  bool
+}
+
+// parseSearchOptions parses the arguments of GEOSEARCH and GEOSEARCHSTORE after the source key.
+func parseSearchOptions(args []string, store bool) (searchOptions, error) {
+	options := searchOptions{unit: 1}
+	fromCount, byCount := 0, 0
+
+	for i := 0; i < len(args); i++ {
+		remaining := len(args) - i - 1
+		switch strings.ToLower(args[i]) {
+		default:
+			return searchOptions{}, errors.New("syntax error")
+		case "frommember":
+			if remaining < 1 {
+				return searchOptions{}, errors.New("syntax error")
+			}
+			options.fromMember = args[i+1]
+			fromCount++
+			i++
+		case "fromlonlat":
+			if remaining < 2 {
+				return searchOptions{}, errors.New("syntax error")
+			}
+			longitude, err := parseFloat(args[i+1])
+			if err != nil {
+				return searchOptions{}, err
+			}
+			latitude, err := parseFloat(args[i+2])
+			if err != nil {
+				return searchOptions{}, err
+			}
+			if err = ValidateCoordinates(longitude, latitude); err != nil {
+				return searchOptions{}, err
+			}
+			options.fromLonLat = &Coordinates{Longitude: longitude, Latitude: latitude}
+			fromCount++
+			i += 2
+		case "byradius":
+			if remaining < 2 {
+				return searchOptions{}, errors.New("syntax error")
+			}
+			radius, err := parseFloat(args[i+1])
+			if err != nil {
+				return searchOptions{}, err
+			}
+			if radius < 0 {
+				return searchOptions{}, errors.New("radius cannot be negative")
+			}
+			if options.unit, err = parseUnit(args[i+2]); err != nil {
+				return searchOptions{}, err
+			}
+			options.radius = &radius
+			byCount++
+			i += 2
+		case "bybox":
+			if remaining < 3 {
+				return searchOptions{}, errors.New("syntax error")
+			}
+			width, err := parseFloat(args[i+1])
+			if err != nil {
+				return searchOptions{}, err
+			}
+			height, err := parseFloat(args[i+2])
+			if err != nil {
+				return searchOptions{}, err
+			}
+			if width < 0 || height < 0 {
+				return searchOptions{}, errors.New("height or width cannot be negative")
+			}
+			if options.unit, err = parseUnit(args[i+3]); err != nil {
+				return searchOptions{}, err
+			}
+			options.box = &[2]float64{width, height}
+			byCount++
+			i += 3
+		case "asc", "desc":
+			options.sort = strings.ToLower(args[i])
+		case "count":
+			if remaining < 1 {
+				return searchOptions{}, errors.New("syntax error")
+			}
+			count, err := strconv.Atoi(args[i+1])
+			if err != nil || count <= 0 {
+				return searchOptions{}, errors.New("COUNT must be > 0")
+			}
+			options.count = count
+			i++
+			if i+1 < len(args) && strings.EqualFold(args[i+1], "any") {
+				options.any = true
+				i++
+			}
+		case "any":
+			return searchOptions{}, errors.New("the ANY argument requires COUNT argument")
+		case "withcoord", "withdist", "withhash":
+			if store {
+				return searchOptions{}, errors.New("syntax error")
+			}
+			switch strings.ToLower(args[i]) {
+			case "withcoord":
+				options.withCoord = true
+			case "withdist":
+				options.withDist = true
+			case "withhash":
+				options.withHash = true
+			}
+		case "storedist":
+			if !store {
+				return searchOptions{}, errors.New("syntax error")
+			}
+			options.storeDist = true
+		}
+	}
+
+	if fromCount != 1 {
+		return searchOptions{}, errors.New("exactly one of FROMMEMBER or FROMLONLAT can be specified")
+	}
+	if byCount != 1 {
+		return searchOptions{}, errors.New("exactly one of BYRADIUS and BYBOX can be specified")
+	}
+	// Return the closest results when the number of results is limited.
+	if options.count > 0 && !options.any && options.sort == "" {
+		options.sort = "asc"
+	}
+	return options, nil
+}
+
+type searchResult struct {
+	member      string
+	distance    float64 // In meters.
+	hash        uint64
+	coordinates Coordinates
+}
+
+// search returns the members of the set in the area described by the options.
+func search(set *sorted_set.SortedSet, options searchOptions) ([]searchResult, error) {
+	if set == nil {
+		if options.fromMember != "" {
+			return nil, errors.New("could not decode requested zset member")
+		}
+		return []searchResult{}, nil
+	}
+
+	shape := Shape{}
+	if options.fromLonLat != nil {
+		shape.Center = *options.fromLonLat
+	} else {
+		center, ok := position(set, options.fromMember)
+		if !ok {
+			return nil, errors.New("could not decode requested zset member")
+		}
+		shape.Center = center
+	}
+	if options.radius != nil {
+		shape.Radius = *options.radius * options.unit
+	} else {
+		shape.Width, shape.Height = options.box[0]*options.unit, options.box[1]*options.unit
+	}
+
+	// Scan the members of the cells around the centre in the order of their geohash so that the results are stable.
+	results := make([]searchResult, 0)
+	for _, scores := range shape.ScoreRanges() {
+		for _, member := range set.RangeByScore(sorted_set.Score(scores[0]), sorted_set.Score(scores[1]-1)) {
+			coordinates := Decode(uint64(member.Score))
+			distance, ok := shape.Contains(coordinates)
+			if !ok {
+				continue
+			}
+			results = append(results, searchResult{
+				member:      string(member.Value),
+				distance:    distance,
+				hash:        uint64(member.Score),
+				coordinates: coordinates,
+			})
+			if options.any && len(results) == options.count {
+				break
+			}
+		}
+		if options.any && len(results) == options.count {
+			break
+		}
+	}
+
+	switch options.sort {
+	case "asc":
+		slices.SortStableFunc(results, func(a, b searchResult) int {
+			return cmp.Compare(a.distance, b.distance)
+		})
+	case "desc":
+		slices.SortStableFunc(results, func(a, b searchResult) int {
+			return cmp.Compare(b.distance, a.distance)
+		})
+	}
+	if options.count > 0 && len(results) > options.count {
+		results = results[:options.count]
+	}
+	return results, nil
+}
+
+func handleGEOSEARCH(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := geosearchKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := parseSearchOptions(params.Command[2:], false)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := getSortedSet(params, keys.ReadKeys[0])
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := search(set, options)
+	if err != nil {
+		return nil, err
+	}
+
+	res := fmt.Sprintf("*%d\r\n", len(results))
+	for _, result := range results {
+		name := fmt.Sprintf("$%d\r\n%s\r\n", len(result.member), result.member)
+		if !options.withCoord && !options.withDist && !options.withHash {
+			res += name
+			continue
+		}
+		length, item := 1, name
+		if options.withDist {
+			length++
+			item += formatDistance(result.distance, options.unit)
+		}
+		if options.withHash {
+			length++
+			item += fmt.Sprintf(":%d\r\n", result.hash)
+		}
+		if options.withCoord {
+			length++
+			item += "*2\r\n" + formatFloat(result.coordinates.Longitude) + formatFloat(result.coordinates.Latitude)
+		}
+		res += fmt.Sprintf("*%d\r\n%s", length, item)
+	}
+
+	return []byte(res), nil
+}
+
+func handleGEOSEARCHSTORE(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := geosearchstoreKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	destination := keys.WriteKeys[0]
+
+	options, err := parseSearchOptions(params.Command[3:], true)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := getSortedSet(params, keys.ReadKeys[0])
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := search(set, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		if params.KeysExist(params.Context, []string{destination})[destination] {
+			if err = params.DeleteKey(params.Context, destination); err != nil {
+				return nil, err
+			}
+		}
+		return []byte(":0\r\n"), nil
+	}
+
+	members := make([]sorted_set.MemberParam, len(results))
+	for i, result := range results {
+		score := sorted_set.Score(result.hash)
+		if options.storeDist {
+			score = sorted_set.Score(result.distance / options.unit)
+		}
+		members[i] = sorted_set.MemberParam{Value: sorted_set.Value(result.member), Score: score}
+	}
+	if err = params.SetValues(params.Context, map[string]interface{}{
+		destination: sorted_set.NewSortedSet(members),
+	}); err != nil {
+		return nil, err
+	}
+
+	return []byte(fmt.Sprintf(":%d\r\n", len(results))), nil
+}
+
+func Commands() []internal.Command {
+	return []internal.Command{
+		{
+			Command:    "geoadd",
+			Module:     constants.GeoModule,
+			Categories: []string{constants.GeoCategory, constants.WriteCategory, constants.SlowCategory},
+			Description: `(GEOADD key [NX | XX] [CH] longitude latitude member [longitude latitude member ...])
+Adds the members to the geospatial index at key, which is a sorted set scored by the 52-bit geohash of each location.
+NX only adds new members and XX only updates existing members. Returns the number of added members,
+or the number of added and updated members with CH.`,
+			Sync:              true,
+			KeyExtractionFunc: geoaddKeyFunc,
+			HandlerFunc:       handleGEOADD,
+		},
+		{
+			Command:    "geopos",
+			Module:     constants.GeoModule,
+			Categories: []string{constants.GeoCategory, constants.ReadCategory, constants.SlowCategory},
+			Description: `(GEOPOS key [member [member ...]])
+Returns the longitude and latitude of each member, or nil for members that don't exist.`,
+			Sync:              false,
+			KeyExtractionFunc: geoposKeyFunc,
+			HandlerFunc:       handleGEOPOS,
+		},
+		{
+			Command:    "geodist",
+			Module:     constants.GeoModule,
+			Categories: []string{constants.GeoCategory, constants.ReadCategory, constants.SlowCategory},
+			Description: `(GEODIST key member1 member2 [M | KM | FT | MI])
+Returns the distance between two members in the unit, which defaults to meters. Returns nil if a member does not exist.`,
+			Sync:              false,
+			KeyExtractionFunc: geodistKeyFunc,
+			HandlerFunc:       handleGEODIST,
+		},
+		{
+			Command:    "geohash",
+			Module:     constants.GeoModule,
+			Categories: []string{constants.GeoCategory, constants.ReadCategory, constants.SlowCategory},
+			Description: `(GEOHASH key [member [member ...]])
+Returns the 11 character geohash string of each member, or nil for members that don't exist.`,
+			Sync:              false,
+			KeyExtractionFunc: geohashKeyFunc,
+			HandlerFunc:       handleGEOHASH,
+		},
+		{
+			Command:    "geosearch",
+			Module:     constants.GeoModule,
+			Categories: []string{constants.GeoCategory, constants.ReadCategory, constants.SlowCategory},
+			Description: `(GEOSEARCH key FROMMEMBER member | FROMLONLAT longitude latitude
+BYRADIUS radius M | KM | FT | MI | BYBOX width height M | KM | FT | MI
+[ASC | DESC] [COUNT count [ANY]] [WITHCOORD] [WITHDIST] [WITHHASH])
+Returns the members within the radius or the box centred on the member or the coordinates.
+ASC and DESC sort the results by distance. COUNT limits the number of results to the closest ones,
+or to the first ones found with ANY.`,
+			Sync:              false,
+			KeyExtractionFunc: geosearchKeyFunc,
+			HandlerFunc:       handleGEOSEARCH,
+		},
+		{
+			Command:    "geosearchstore",
+			Module:     constants.GeoModule,
+			Categories: []string{constants.GeoCategory, constants.WriteCategory, constants.SlowCategory},
+			Description: `(GEOSEARCHSTORE destination source FROMMEMBER member | FROMLONLAT longitude latitude
+BYRADIUS radius M | KM | FT | MI | BYBOX width height M | KM | FT | MI
+[ASC | DESC] [COUNT count [ANY]] [STOREDIST])
+Works like GEOSEARCH but stores the results in the sorted set at destination and returns their number.
+With STOREDIST, the members are scored by their distance in the unit instead of their geohash.`,
+			Sync:              true,
+			KeyExtractionFunc: geosearchstoreKeyFunc,
+			HandlerFunc:       handleGEOSEARCHSTORE,
+		},
+	}
+}
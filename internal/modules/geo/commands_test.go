@@ -0,0 +1,298 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/echovault/sugardb/internal"
+	"github.com/echovault/sugardb/internal/config"
+	"github.com/echovault/sugardb/internal/constants"
+	"github.com/echovault/sugardb/sugardb"
+	"github.com/tidwall/resp"
+)
+
+// format returns the response as a string. Arrays are formatted as "[element element ...]" and nulls as "nil".
+func format(v resp.Value) string {
+	if v.IsNull() {
+		return "nil"
+	}
+	if v.Type() == resp.Array {
+		elements := make([]string, len(v.Array()))
+		for i, element := range v.Array() {
+			elements[i] = format(element)
+		}
+		return "[" + strings.Join(elements, " ") + "]"
+	}
+	return v.String()
+}
+
+func Test_Geo(t *testing.T) {
+	port, err := internal.GetFreePort()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	mockServer, err := sugardb.NewSugarDB(
+		sugardb.WithConfig(config.Config{
+			BindAddr:       "localhost",
+			Port:           uint16(port),
+			DataDir:        "",
+			EvictionPolicy: constants.NoEviction,
+		}),
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	go func() {
+		mockServer.Start()
+	}()
+
+	t.Cleanup(func() {
+		mockServer.ShutDown()
+	})
+
+	type step struct {
+		command []string
+		want    string // The expected response, as returned by format.
+		wantErr string // The expected error substring when the response is an error.
+	}
+
+	runSteps := func(t *testing.T, client *resp.Conn, steps []step) {
+		for _, step := range steps {
+			values := make([]resp.Value, len(step.command))
+			for i, token := range step.command {
+				values[i] = resp.StringValue(token)
+			}
+			if err := client.WriteArray(values); err != nil {
+				t.Error(err)
+				return
+			}
+			res, _, err := client.ReadValue()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if step.wantErr != "" {
+				if res.Error() == nil || !strings.Contains(res.Error().Error(), step.wantErr) {
+					t.Errorf("%q: expected error \"%s\", got %+v", step.command, step.wantErr, res)
+				}
+				continue
+			}
+			if res.Error() != nil {
+				t.Errorf("%q: unexpected error %v", step.command, res.Error())
+				continue
+			}
+			if got := format(res); got != step.want {
+				t.Errorf("%q: expected response %q, got %q", step.command, step.want, got)
+			}
+		}
+	}
+
+	connect := func(t *testing.T) *resp.Conn {
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			_ = conn.Close()
+		})
+		return resp.NewConn(conn)
+	}
+
+	sicily := []string{
+		"GEOADD", "Sicily", "13.361389", "38.115556", "Palermo", "15.087269", "37.502669", "Catania",
+		"12.758489", "38.788135", "edge1", "17.241510", "38.788135", "edge2",
+	}
+
+	t.Run("Test_HandleGEOADD", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"GEOADD", "GeoaddKey1", "13.361389", "38.115556", "Palermo"}, want: "1"},
+			{command: []string{"GEOADD", "GeoaddKey1", "13.361389", "38.115556", "Palermo", "15.087269", "37.502669", "Catania"}, want: "1"},
+			{command: []string{"GEOADD", "GeoaddKey1", "CH", "13.5", "38.115556", "Palermo", "15.087269", "37.502669", "Catania"}, want: "1"},
+			{command: []string{"GEOADD", "GeoaddKey1", "NX", "13.361389", "38.115556", "Palermo", "12", "37", "Trapani"}, want: "1"},
+			{command: []string{"GEOADD", "GeoaddKey1", "XX", "CH", "13.361389", "38.115556", "Palermo", "12", "37", "Marsala"}, want: "1"},
+			{command: []string{"ZCARD", "GeoaddKey1"}, want: "3"},
+			// The members are stored in a sorted set scored by their geohash.
+			{command: []string{"ZSCORE", "GeoaddKey1", "Palermo"}, want: "3479099956230698"},
+			{command: []string{"TYPE", "GeoaddKey1"}, want: "zset"},
+			{command: []string{"ZREM", "GeoaddKey1", "Trapani"}, want: "1"},
+			{command: []string{"GEOPOS", "GeoaddKey1", "Trapani"}, want: "[nil]"},
+			// XX does not create the key.
+			{command: []string{"GEOADD", "GeoaddKey2", "XX", "13.361389", "38.115556", "Palermo"}, want: "0"},
+			{command: []string{"TYPE", "GeoaddKey2"}, wantErr: "key GeoaddKey2 does not exist"},
+			{command: []string{"GEOADD", "GeoaddKey1", "NX", "XX", "13.361389", "38.115556", "Palermo"}, wantErr: "XX and NX options at the same time are not compatible"},
+			{command: []string{"GEOADD", "GeoaddKey1", "13.361389", "86", "Palermo"}, wantErr: "invalid longitude,latitude pair 13.361389,86.000000"},
+			{command: []string{"GEOADD", "GeoaddKey1", "181", "38", "Palermo"}, wantErr: "invalid longitude,latitude pair"},
+			{command: []string{"GEOADD", "GeoaddKey1", "x", "38", "Palermo"}, wantErr: "value is not a valid float"},
+			{command: []string{"GEOADD", "GeoaddKey1", "13", "38", "Palermo", "15"}, wantErr: "syntax error"},
+			{command: []string{"SET", "GeoaddKey3", "value"}, want: "OK"},
+			{command: []string{"GEOADD", "GeoaddKey3", "13", "38", "Palermo"}, wantErr: "value at GeoaddKey3 is not a sorted set"},
+			{command: []string{"GEOADD", "GeoaddKey1", "13", "38"}, wantErr: constants.WrongArgsResponse},
+		})
+	})
+
+	t.Run("Test_HandleGEOPOS_GEODIST_GEOHASH", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: sicily, want: "4"},
+			{command: []string{"GEOPOS", "Sicily", "Palermo", "Unknown"}, want: "[[13.361389338970184 38.1155563954963] nil]"},
+			{command: []string{"GEOPOS", "GeoposKey1", "Palermo"}, want: "[nil]"},
+			{command: []string{"GEODIST", "Sicily", "Palermo", "Catania"}, want: "166274.1516"},
+			{command: []string{"GEODIST", "Sicily", "Palermo", "Catania", "km"}, want: "166.2742"},
+			{command: []string{"GEODIST", "Sicily", "Palermo", "Catania", "MI"}, want: "103.3182"},
+			{command: []string{"GEODIST", "Sicily", "Palermo", "Catania", "ft"}, want: "545518.8700"},
+			{command: []string{"GEODIST", "Sicily", "Palermo", "Unknown"}, want: "nil"},
+			{command: []string{"GEODIST", "Sicily", "Palermo", "Catania", "yd"}, wantErr: "unsupported unit provided. please use M, KM, FT, MI"},
+			{command: []string{"GEOHASH", "Sicily", "Palermo", "Catania", "Unknown"}, want: "[sqc8b49rny0 sqdtr74hyu0 nil]"},
+			{command: []string{"GEOHASH", "Sicily"}, want: "[]"},
+			{command: []string{"SET", "GeoposKey2", "value"}, want: "OK"},
+			{command: []string{"GEOPOS", "GeoposKey2", "a"}, wantErr: "value at GeoposKey2 is not a sorted set"},
+			{command: []string{"GEODIST", "Sicily", "Palermo"}, wantErr: constants.WrongArgsResponse},
+		})
+	})
+
+	t.Run("Test_HandleGEOSEARCH", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"GEOADD", "GeosearchKey1", "13.361389", "38.115556", "Palermo", "15.087269", "37.502669", "Catania"}, want: "2"},
+			{command: append([]string{"GEOADD", "GeosearchKey1"}, sicily[8:]...), want: "2"},
+		})
+
+		tests := []struct {
+			name  string
+			steps []step
+		}{
+			{
+				name: "1. Search by radius",
+				steps: []step{
+					{command: []string{"GEOSEARCH", "GeosearchKey1", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km", "ASC"}, want: "[Catania Palermo]"},
+					{command: []string{"GEOSEARCH", "GeosearchKey1", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km", "DESC"}, want: "[Palermo Catania]"},
+					{
+						command: []string{"GEOSEARCH", "GeosearchKey1", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km", "WITHDIST", "WITHHASH"},
+						want:    "[[Palermo 190.4424 3479099956230698] [Catania 56.4413 3479447370796909]]",
+					},
+					{command: []string{"GEOSEARCH", "GeosearchKey1", "FROMMEMBER", "Palermo", "BYRADIUS", "50", "mi", "ASC", "WITHDIST"}, want: "[[Palermo 0.0000]]"},
+					{command: []string{"GEOSEARCH", "GeosearchKey1", "FROMLONLAT", "0", "0", "BYRADIUS", "1", "m"}, want: "[]"},
+					{command: []string{"GEOSEARCH", "GeosearchKey2", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km"}, want: "[]"},
+				},
+			},
+			{
+				name: "2. Search by box",
+				steps: []step{
+					{
+						command: []string{"GEOSEARCH", "GeosearchKey1", "FROMLONLAT", "15", "37", "BYBOX", "400", "400", "km", "ASC", "WITHCOORD", "WITHDIST"},
+						want: "[[Catania 56.4413 [15.087267458438873 37.50266842333162]] " +
+							"[Palermo 190.4424 [13.361389338970184 38.1155563954963]] " +
+							"[edge2 279.7403 [17.241510450839996 38.78813451624225]] " +
+							"[edge1 279.7405 [12.75848776102066 38.78813451624225]]]",
+					},
+					{command: []string{"GEOSEARCH", "GeosearchKey1", "FROMMEMBER", "Catania", "BYBOX", "100", "100", "km"}, want: "[Catania]"},
+				},
+			},
+			{
+				name: "3. Limit the number of results",
+				steps: []step{
+					// COUNT returns the closest results.
+					{command: []string{"GEOSEARCH", "GeosearchKey1", "FROMLONLAT", "15", "37", "BYBOX", "400", "400", "km", "COUNT", "2"}, want: "[Catania Palermo]"},
+					{command: []string{"GEOSEARCH", "GeosearchKey1", "FROMLONLAT", "15", "37", "BYBOX", "400", "400", "km", "DESC", "COUNT", "1"}, want: "[edge1]"},
+					// COUNT ANY returns the first results found, in the order of their geohash.
+					{command: []string{"GEOSEARCH", "GeosearchKey1", "FROMLONLAT", "15", "37", "BYBOX", "400", "400", "km", "COUNT", "1", "ANY"}, want: "[Palermo]"},
+				},
+			},
+			{
+				name: "4. Search the cells around the centre across the antimeridian and near the poles",
+				steps: []step{
+					{
+						command: []string{"GEOADD", "GeosearchKey3", "179.9", "0", "east", "-179.9", "0", "west", "0", "85", "north", "180", "85", "north2"},
+						want:    "4",
+					},
+					{command: []string{"GEOSEARCH", "GeosearchKey3", "FROMLONLAT", "180", "0", "BYRADIUS", "20", "km"}, want: "[west east]"},
+					{command: []string{"GEOSEARCH", "GeosearchKey3", "FROMLONLAT", "-180", "0", "BYBOX", "40", "40", "km", "ASC"}, want: "[east west]"},
+					{command: []string{"GEOSEARCH", "GeosearchKey3", "FROMLONLAT", "90", "85", "BYRADIUS", "1200", "km", "ASC"}, want: "[north north2]"},
+					{command: []string{"GEOSEARCH", "GeosearchKey3", "FROMLONLAT", "0", "0", "BYRADIUS", "21000", "km"}, want: "[west north east north2]"},
+				},
+			},
+			{
+				name: "5. Return an error when the arguments are invalid",
+				steps: []step{
+					{command: []string{"GEOSEARCH", "GeosearchKey1", "FROMMEMBER", "Unknown", "BYRADIUS", "1", "km"}, wantErr: "could not decode requested zset member"},
+					{
+						command: []string{"GEOSEARCH", "GeosearchKey1", "FROMMEMBER", "Palermo", "FROMLONLAT", "15", "37", "BYRADIUS", "1", "km"},
+						wantErr: "exactly one of FROMMEMBER or FROMLONLAT can be specified",
+					},
+					{
+						command: []string{"GEOSEARCH", "GeosearchKey1", "FROMMEMBER", "Palermo", "BYRADIUS", "1", "km", "BYBOX", "1", "1", "km"},
+						wantErr: "exactly one of BYRADIUS and BYBOX can be specified",
+					},
+					{command: []string{"GEOSEARCH", "GeosearchKey1", "FROMMEMBER", "Palermo", "ASC", "COUNT", "1"}, wantErr: "exactly one of BYRADIUS and BYBOX can be specified"},
+					{command: []string{"GEOSEARCH", "GeosearchKey1", "FROMMEMBER", "Palermo", "BYRADIUS", "1", "km", "COUNT", "0"}, wantErr: "COUNT must be > 0"},
+					{command: []string{"GEOSEARCH", "GeosearchKey1", "FROMMEMBER", "Palermo", "BYRADIUS", "1", "km", "ANY"}, wantErr: "the ANY argument requires COUNT argument"},
+					{command: []string{"GEOSEARCH", "GeosearchKey1", "FROMMEMBER", "Palermo", "BYRADIUS", "-1", "km"}, wantErr: "radius cannot be negative"},
+					{command: []string{"GEOSEARCH", "GeosearchKey1", "FROMMEMBER", "Palermo", "BYRADIUS", "1", "yd"}, wantErr: "unsupported unit provided"},
+					{command: []string{"GEOSEARCH", "GeosearchKey1", "FROMMEMBER", "Palermo", "BYRADIUS", "1", "km", "STOREDIST"}, wantErr: "syntax error"},
+					{command: []string{"GEOSEARCH", "GeosearchKey1", "FROMMEMBER", "Palermo", "BYRADIUS", "1"}, wantErr: constants.WrongArgsResponse},
+				},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				runSteps(t, client, test.steps)
+			})
+		}
+	})
+
+	t.Run("Test_HandleGEOSEARCHSTORE", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"GEOADD", "GeosearchstoreKey1", "13.361389", "38.115556", "Palermo", "15.087269", "37.502669", "Catania"}, want: "2"},
+			{
+				command: []string{"GEOSEARCHSTORE", "GeosearchstoreDest1", "GeosearchstoreKey1", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km"},
+				want:    "2",
+			},
+			{command: []string{"GEOPOS", "GeosearchstoreDest1", "Catania"}, want: "[[15.087267458438873 37.50266842333162]]"},
+			{
+				command: []string{"GEOSEARCHSTORE", "GeosearchstoreDest2", "GeosearchstoreKey1", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km", "COUNT", "1", "STOREDIST"},
+				want:    "1",
+			},
+			{command: []string{"ZSCORE", "GeosearchstoreDest2", "Catania"}, want: "56.4412578701582"},
+			// The destination is deleted when there are no results.
+			{
+				command: []string{"GEOSEARCHSTORE", "GeosearchstoreDest1", "GeosearchstoreKey1", "FROMLONLAT", "0", "0", "BYRADIUS", "1", "km"},
+				want:    "0",
+			},
+			{command: []string{"TYPE", "GeosearchstoreDest1"}, wantErr: "key GeosearchstoreDest1 does not exist"},
+			{
+				command: []string{"GEOSEARCHSTORE", "GeosearchstoreDest1", "GeosearchstoreKey1", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km", "WITHDIST"},
+				wantErr: "syntax error",
+			},
+			{command: []string{"GEOSEARCHSTORE", "GeosearchstoreDest1", "GeosearchstoreKey1", "FROMLONLAT", "15", "37"}, wantErr: constants.WrongArgsResponse},
+		})
+	})
+}
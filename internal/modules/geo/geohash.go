@@ -0,0 +1,225 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+const (
+	// The latitude limits of the Web Mercator projection. Locations closer to the poles can't be indexed.
+	LatitudeMin  = -85.05112878
+	LatitudeMax  = 85.05112878
+	LongitudeMin = -180.0
+	LongitudeMax = 180.0
+
+	// step is the number of bits used for each coordinate, which makes a geohash 52 bits long so that it
+	// fits exactly in the float64 score of a sorted set member.
+	step = 26
+
+	earthRadiusInMeters = 6372797.560856
+)
+
+// Coordinates is the position of a member of a geospatial index.
+type Coordinates struct {
+	Longitude float64
+	Latitude  float64
+}
+
+// ValidateCoordinates returns an error if the coordinates can't be indexed.
+func ValidateCoordinates(longitude, latitude float64) error {
+	if longitude < LongitudeMin || longitude > LongitudeMax || latitude < LatitudeMin || latitude > LatitudeMax {
+		return fmt.Errorf("invalid longitude,latitude pair %f,%f", longitude, latitude)
+	}
+	return nil
+}
+
+// Encode returns the 52-bit geohash of the coordinates, which is used as the score of the member.
+func Encode(longitude, latitude float64) uint64 {
+	return encode(longitude, latitude, LatitudeMin, LatitudeMax)
+}
+
+func encode(longitude, latitude, latitudeMin, latitudeMax float64) uint64 {
+	latitudeOffset := (latitude - latitudeMin) / (latitudeMax - latitudeMin)
+	longitudeOffset := (longitude - LongitudeMin) / (LongitudeMax - LongitudeMin)
+	latitudeBits := uint32(min(latitudeOffset*(1<<step), 1<<step-1))
+	longitudeBits := uint32(min(longitudeOffset*(1<<step), 1<<step-1))
+	return interleave(latitudeBits, longitudeBits)
+}
+
+// Decode returns the coordinates of the centre of the area of the geohash.
+func Decode(hash uint64) Coordinates {
+	latitudeBits, longitudeBits := deinterleave(hash)
+	scale := float64(uint64(1) << step)
+	latitudeMin := LatitudeMin + float64(latitudeBits)/scale*(LatitudeMax-LatitudeMin)
+	latitudeMax := LatitudeMin + float64(latitudeBits+1)/scale*(LatitudeMax-LatitudeMin)
+	longitudeMin := LongitudeMin + float64(longitudeBits)/scale*(LongitudeMax-LongitudeMin)
+	longitudeMax := LongitudeMin + float64(longitudeBits+1)/scale*(LongitudeMax-LongitudeMin)
+	return Coordinates{
+		Longitude: max(LongitudeMin, min(LongitudeMax, (longitudeMin+longitudeMax)/2)),
+		Latitude:  max(LatitudeMin, min(LatitudeMax, (latitudeMin+latitudeMax)/2)),
+	}
+}
+
+// interleave places the bits of x in the even positions and the bits of y in the odd positions.
+func interleave(x, y uint32) uint64 {
+	return spread(x) | spread(y)<<1
+}
+
+func deinterleave(hash uint64) (x, y uint32) {
+	return squash(hash), squash(hash >> 1)
+}
+
+// spread moves the bits of v to the even positions of the result.
+func spread(v uint32) uint64 {
+	x := uint64(v)
+	x = (x | x<<16) & 0x0000FFFF0000FFFF
+	x = (x | x<<8) & 0x00FF00FF00FF00FF
+	x = (x | x<<4) & 0x0F0F0F0F0F0F0F0F
+	x = (x | x<<2) & 0x3333333333333333
+	x = (x | x<<1) & 0x5555555555555555
+	return x
+}
+
+// squash collects the bits in the even positions of v.
+func squash(v uint64) uint32 {
+	x := v & 0x5555555555555555
+	x = (x | x>>1) & 0x3333333333333333
+	x = (x | x>>2) & 0x0F0F0F0F0F0F0F0F
+	x = (x | x>>4) & 0x00FF00FF00FF00FF
+	x = (x | x>>8) & 0x0000FFFF0000FFFF
+	x = (x | x>>16) & 0x00000000FFFFFFFF
+	return uint32(x)
+}
+
+// HashString returns the standard 11 character geohash of the coordinates.
+// Standard geohashes use the full latitude range, so the score of the member can't be used directly.
+func HashString(coordinates Coordinates) string {
+	const alphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+	hash := encode(coordinates.Longitude, coordinates.Latitude, -90, 90)
+	var sb strings.Builder
+	for i := 0; i < 11; i++ {
+		// The 52 bits of the hash only fill 10 characters and 2 bits of the 11th.
+		index := 0
+		if i < 10 {
+			index = int(hash>>(52-(i+1)*5)) & 0x1f
+		}
+		sb.WriteByte(alphabet[index])
+	}
+	return sb.String()
+}
+
+// Distance returns the distance in meters between the coordinates using the haversine formula.
+func Distance(a, b Coordinates) float64 {
+	latitudeA, latitudeB := radians(a.Latitude), radians(b.Latitude)
+	u := math.Sin((latitudeB - latitudeA) / 2)
+	v := math.Sin((radians(b.Longitude) - radians(a.Longitude)) / 2)
+	return 2 * earthRadiusInMeters * math.Asin(math.Sqrt(u*u+math.Cos(latitudeA)*math.Cos(latitudeB)*v*v))
+}
+
+// latitudeDistance returns the distance in meters between two latitudes on the same meridian.
+func latitudeDistance(a, b float64) float64 {
+	return earthRadiusInMeters * math.Abs(radians(b)-radians(a))
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// Shape is the area searched by GEOSEARCH.
+type Shape struct {
+	Center Coordinates
+	Radius float64 // The radius in meters of a circular area. Only used when Width and Height are 0.
+	Width  float64 // The width in meters of a rectangular area.
+	Height float64 // The height in meters of a rectangular area.
+}
+
+// Contains returns the distance in meters between the centre of the shape and the coordinates,
+// and whether the coordinates are in the shape.
+func (shape Shape) Contains(coordinates Coordinates) (float64, bool) {
+	if shape.Width == 0 && shape.Height == 0 {
+		distance := Distance(shape.Center, coordinates)
+		return distance, distance <= shape.Radius
+	}
+	if latitudeDistance(shape.Center.Latitude, coordinates.Latitude) > shape.Height/2 {
+		return 0, false
+	}
+	// Measure the longitude distance at the latitude of the coordinates, where the box is widest for them.
+	if Distance(Coordinates{Longitude: shape.Center.Longitude, Latitude: coordinates.Latitude}, coordinates) > shape.Width/2 {
+		return 0, false
+	}
+	return Distance(shape.Center, coordinates), true
+}
+
+// ScoreRanges returns the ranges of geohashes that cover the shape, ordered from the smallest geohash.
+// They are the ranges of the cell that contains the centre of the shape and of its neighbours, at the smallest cell
+// size where the cells cover the bounding box of the shape. The end of each range is exclusive.
+func (shape Shape) ScoreRanges() [][2]uint64 {
+	halfWidth, halfHeight := shape.Radius, shape.Radius
+	if shape.Width != 0 || shape.Height != 0 {
+		halfWidth, halfHeight = shape.Width/2, shape.Height/2
+	}
+
+	// The bounding box of the shape in degrees.
+	latitudeDelta := halfHeight / earthRadiusInMeters * 180 / math.Pi
+	latitudeMin := max(LatitudeMin, shape.Center.Latitude-latitudeDelta)
+	latitudeMax := min(LatitudeMax, shape.Center.Latitude+latitudeDelta)
+	// A parallel is shortest at the latitude furthest from the equator, so the box is widest there in degrees.
+	longitudeDelta := 360.0
+	if ratio := math.Sin(halfWidth/earthRadiusInMeters/2) / math.Cos(radians(max(-latitudeMin, latitudeMax))); ratio < 1 {
+		longitudeDelta = 2 * math.Asin(ratio) * 180 / math.Pi
+	}
+
+	hash := Encode(shape.Center.Longitude, shape.Center.Latitude)
+	centerLatitude, centerLongitude := deinterleave(hash)
+	for bits := uint(step); ; bits-- {
+		cells := int64(1) << bits
+		latitudeCell := int64(centerLatitude >> (step - bits))
+		longitudeCell := int64(centerLongitude >> (step - bits))
+		latitudeSize := (LatitudeMax - LatitudeMin) / float64(cells)
+		longitudeSize := (LongitudeMax - LongitudeMin) / float64(cells)
+
+		// The cells of the first and last rows have no neighbours beyond them, and the longitudes wrap around.
+		covered := bits == 1 ||
+			(latitudeCell == 0 || latitudeMin >= LatitudeMin+float64(latitudeCell-1)*latitudeSize) &&
+				(latitudeCell == cells-1 || latitudeMax <= LatitudeMin+float64(latitudeCell+2)*latitudeSize) &&
+				shape.Center.Longitude-longitudeDelta >= LongitudeMin+float64(longitudeCell-1)*longitudeSize &&
+				shape.Center.Longitude+longitudeDelta <= LongitudeMin+float64(longitudeCell+2)*longitudeSize
+		if !covered {
+			continue
+		}
+
+		shift := 2 * (step - bits)
+		ranges := make([][2]uint64, 0, 9)
+		for i := latitudeCell - 1; i <= latitudeCell+1; i++ {
+			if i < 0 || i >= cells {
+				continue
+			}
+			for j := longitudeCell - 1; j <= longitudeCell+1; j++ {
+				cell := interleave(uint32(i), uint32((j+cells)%cells))
+				ranges = append(ranges, [2]uint64{cell << shift, (cell + 1) << shift})
+			}
+		}
+		slices.SortFunc(ranges, func(a, b [2]uint64) int {
+			return cmp.Compare(a[0], b[0])
+		})
+		// The neighbours wrap around to the same cell when there are fewer than 3 cells in a row.
+		return slices.Compact(ranges)
+	}
+}
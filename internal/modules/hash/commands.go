@@ -609,6 +609,54 @@ func handleHDEL(params internal.HandlerFuncParams) ([]byte, error) {
 	return []byte(fmt.Sprintf(":%d\r\n", count)), nil
 }
 
+func handleHSCAN(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := hscanKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := internal.ParseScanOptions(params.Command[2:], false)
+	if err != nil {
+		return nil, err
+	}
+
+	key := keys.ReadKeys[0]
+	keyExists := params.KeysExist(params.Context, keys.ReadKeys)[key]
+
+	if !keyExists {
+		return []byte("*2\r\n$1\r\n0\r\n*0\r\n"), nil
+	}
+
+	hash, ok := params.GetValues(params.Context, []string{key})[key].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("value at %s is not a hash", key)
+	}
+
+	fields := make([]string, 0, len(hash))
+	for field := range hash {
+		fields = append(fields, field)
+	}
+
+	page, cursor := internal.ScanCursor(fields, options.Cursor, options.Count)
+	if options.Match != nil {
+		page = slices.DeleteFunc(page, func(field string) bool {
+			return !options.Match.Match(field)
+		})
+	}
+
+	c := strconv.FormatUint(cursor, 10)
+	res := fmt.Sprintf("*2\r\n$%d\r\n%s\r\n*%d\r\n", len(c), c, len(page)*2)
+	for _, field := range page {
+		value := fmt.Sprintf("%v", hash[field])
+		if f, ok := hash[field].(float64); ok {
+			value = strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		res += fmt.Sprintf("$%d\r\n%s\r\n$%d\r\n%s\r\n", len(field), field, len(value), value)
+	}
+
+	return []byte(res), nil
+}
+
 func Commands() []internal.Command {
 	return []internal.Command{
 		{
@@ -742,5 +790,18 @@ Return the string length of the values stored at the specified fields. 0 if the
 			KeyExtractionFunc: hdelKeyFunc,
 			HandlerFunc:       handleHDEL,
 		},
+		{
+			Command:    "hscan",
+			Module:     constants.HashModule,
+			Categories: []string{constants.HashCategory, constants.ReadCategory, constants.SlowCategory},
+			Description: `(HSCAN key cursor [MATCH pattern] [COUNT count])
+Incrementally iterates over the fields and values of a hash.
+Returns the cursor for the next call and a flat list of fields and values. A returned cursor of 0 means the iteration is complete.
+MATCH - Only return fields that match the glob-style pattern.
+COUNT - The number of fields to examine in this call. Defaults to 10.`,
+			Sync:              false,
+			KeyExtractionFunc: hscanKeyFunc,
+			HandlerFunc:       handleHSCAN,
+		},
 	}
 }
@@ -1928,4 +1928,155 @@ func Test_Hash(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("Test_HandleHSCAN", func(t *testing.T) {
+		t.Parallel()
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		client := resp.NewConn(conn)
+
+		// Preset two hashes with 100 fields each and a string value.
+		var fields []string
+		for i := 0; i < 100; i++ {
+			fields = append(fields, "field"+strconv.Itoa(i))
+		}
+		for _, key := range []string{"HScanKey1", "HScanKey2"} {
+			hash := make(map[string]string, len(fields))
+			for _, field := range fields {
+				hash[field] = "value"
+			}
+			if _, err = mockServer.HSet(key, hash); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		if _, _, err = mockServer.Set("HScanKey4", "value", sugardb.SETOptions{}); err != nil {
+			t.Error(err)
+			return
+		}
+
+		tests := []struct {
+			name          string
+			key           string
+			cursor        string
+			options       []string
+			mutate        bool
+			expected      []string
+			expectedError error
+		}{
+			{
+				name:     "1. Iterate over all the fields",
+				key:      "HScanKey1",
+				options:  []string{},
+				expected: fields,
+			},
+			{
+				name:     "2. Iterate over all the fields while the hash is mutated",
+				key:      "HScanKey2",
+				options:  []string{"COUNT", "15"},
+				mutate:   true,
+				expected: fields,
+			},
+			{
+				name:     "3. Only return fields that match the pattern",
+				key:      "HScanKey1",
+				options:  []string{"MATCH", "field9*", "COUNT", "30"},
+				expected: []string{"field9", "field90", "field91", "field92", "field93", "field94", "field95", "field96", "field97", "field98", "field99"},
+			},
+			{
+				name:     "4. Return an empty page when the key does not exist",
+				key:      "HScanKey3",
+				options:  []string{},
+				expected: []string{},
+			},
+			{
+				name:          "5. Return error when the cursor is invalid",
+				key:           "HScanKey1",
+				cursor:        "cursor",
+				options:       []string{},
+				expectedError: errors.New("invalid cursor"),
+			},
+			{
+				name:          "6. Return error when the TYPE option is provided",
+				key:           "HScanKey1",
+				options:       []string{"TYPE", "string"},
+				expectedError: errors.New("syntax error"),
+			},
+			{
+				name:          "7. Return error when the key is not a hash",
+				key:           "HScanKey4",
+				options:       []string{},
+				expectedError: errors.New("value at HScanKey4 is not a hash"),
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				var got []string
+				cursor := "0"
+				if test.cursor != "" {
+					cursor = test.cursor
+				}
+				for i := 0; ; i++ {
+					command := []resp.Value{resp.StringValue("HSCAN"), resp.StringValue(test.key), resp.StringValue(cursor)}
+					for _, option := range test.options {
+						command = append(command, resp.StringValue(option))
+					}
+					if err = client.WriteArray(command); err != nil {
+						t.Error(err)
+						return
+					}
+					res, _, err := client.ReadValue()
+					if err != nil {
+						t.Error(err)
+						return
+					}
+
+					if test.expectedError != nil {
+						if !strings.Contains(res.Error().Error(), test.expectedError.Error()) {
+							t.Errorf("expected error \"%s\", got \"%s\"", test.expectedError.Error(), res.Error().Error())
+						}
+						return
+					}
+
+					cursor = res.Array()[0].String()
+					for j, item := range res.Array()[1].Array() {
+						if j%2 == 0 {
+							got = append(got, item.String())
+						}
+					}
+					if cursor == "0" {
+						break
+					}
+
+					if test.mutate {
+						// Add and remove fields between calls.
+						if _, err = mockServer.HSet(test.key, map[string]string{"new" + strconv.Itoa(i): "value"}); err != nil {
+							t.Error(err)
+							return
+						}
+						if _, err = mockServer.HDel(test.key, "new"+strconv.Itoa(i-1)); err != nil {
+							t.Error(err)
+							return
+						}
+					}
+				}
+
+				for _, item := range test.expected {
+					if !slices.Contains(got, item) {
+						t.Errorf("expected \"%s\" to be returned by the iteration", item)
+					}
+				}
+				if !test.mutate && len(got) != len(test.expected) {
+					t.Errorf("expected %d items, got %d", len(test.expected), len(got))
+				}
+			})
+		}
+	})
 }
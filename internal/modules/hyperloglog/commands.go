@@ -0,0 +1,168 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hyperloglog
+
+import (
+	"fmt"
+
+	"github.com/echovault/sugardb/internal"
+	"github.com/echovault/sugardb/internal/constants"
+)
+
+// getHyperLogLogs returns the HyperLogLogs at the keys. Keys that don't exist are left out.
+func getHyperLogLogs(params internal.HandlerFuncParams, keys []string) (map[string]*HyperLogLog, error) {
+	hlls := make(map[string]*HyperLogLog, len(keys))
+	exists := params.KeysExist(params.Context, keys)
+	values := params.GetValues(params.Context, keys)
+	for _, key := range keys {
+		if !exists[key] {
+			continue
+		}
+		hll, ok := values[key].(*HyperLogLog)
+		if !ok {
+			return nil, fmt.Errorf("value at %s is not a hyperloglog", key)
+		}
+		hlls[key] = hll
+	}
+	return hlls, nil
+}
+
+func handlePFADD(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := pfaddKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key := keys.WriteKeys[0]
+
+	hlls, err := getHyperLogLogs(params, keys.WriteKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	hll, ok := hlls[key]
+	if !ok {
+		// Creating the key counts as a change, even without elements.
+		hll = NewHyperLogLog()
+		hll.Add(params.Command[2:]...)
+		if err = params.SetValues(params.Context, map[string]interface{}{key: hll}); err != nil {
+			return nil, err
+		}
+		return []byte(":1\r\n"), nil
+	}
+
+	if !hll.Add(params.Command[2:]...) {
+		return []byte(":0\r\n"), nil
+	}
+	if err = params.SetValues(params.Context, map[string]interface{}{key: hll}); err != nil {
+		return nil, err
+	}
+	return []byte(":1\r\n"), nil
+}
+
+func handlePFCOUNT(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := pfcountKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+
+	hlls, err := getHyperLogLogs(params, keys.ReadKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys.ReadKeys) == 1 {
+		hll, ok := hlls[keys.ReadKeys[0]]
+		if !ok {
+			return []byte(":0\r\n"), nil
+		}
+		return []byte(fmt.Sprintf(":%d\r\n", hll.Count())), nil
+	}
+
+	// Count the union of the HyperLogLogs without merging them into any of the keys.
+	sources := make([]*HyperLogLog, 0, len(hlls))
+	for _, hll := range hlls {
+		sources = append(sources, hll)
+	}
+	return []byte(fmt.Sprintf(":%d\r\n", Union(sources...))), nil
+}
+
+func handlePFMERGE(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := pfmergeKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	destination := keys.WriteKeys[0]
+
+	hlls, err := getHyperLogLogs(params, append([]string{destination}, keys.ReadKeys...))
+	if err != nil {
+		return nil, err
+	}
+
+	// The destination is part of the union when it exists.
+	hll, ok := hlls[destination]
+	if !ok {
+		hll = NewHyperLogLog()
+	}
+	sources := make([]*HyperLogLog, 0, len(keys.ReadKeys))
+	for _, key := range keys.ReadKeys {
+		if source, ok := hlls[key]; ok {
+			sources = append(sources, source)
+		}
+	}
+	hll.Merge(sources...)
+
+	if err = params.SetValues(params.Context, map[string]interface{}{destination: hll}); err != nil {
+		return nil, err
+	}
+	return []byte(constants.OkResponse), nil
+}
+
+func Commands() []internal.Command {
+	return []internal.Command{
+		{
+			Command:    "pfadd",
+			Module:     constants.HyperLogLogModule,
+			Categories: []string{constants.HyperLogLogCategory, constants.WriteCategory, constants.FastCategory},
+			Description: `(PFADD key [element [element ...]])
+Adds the elements to the HyperLogLog at key, creating it if it does not exist.
+Returns 1 if the estimated cardinality changed or the key was created, and 0 otherwise.`,
+			Sync:              true,
+			KeyExtractionFunc: pfaddKeyFunc,
+			HandlerFunc:       handlePFADD,
+		},
+		{
+			Command:    "pfcount",
+			Module:     constants.HyperLogLogModule,
+			Categories: []string{constants.HyperLogLogCategory, constants.ReadCategory, constants.SlowCategory},
+			Description: `(PFCOUNT key [key ...])
+Returns the estimated number of unique elements added to the HyperLogLog at key.
+With multiple keys, returns the estimated cardinality of their union without changing them.`,
+			Sync:              false,
+			KeyExtractionFunc: pfcountKeyFunc,
+			HandlerFunc:       handlePFCOUNT,
+		},
+		{
+			Command:    "pfmerge",
+			Module:     constants.HyperLogLogModule,
+			Categories: []string{constants.HyperLogLogCategory, constants.WriteCategory, constants.SlowCategory},
+			Description: `(PFMERGE destkey [sourcekey [sourcekey ...]])
+Merges the HyperLogLogs at the source keys into the HyperLogLog at destkey, so that it estimates the cardinality
+of their union. destkey is created if it does not exist and is part of the union if it does.`,
+			Sync:              true,
+			KeyExtractionFunc: pfmergeKeyFunc,
+			HandlerFunc:       handlePFMERGE,
+		},
+	}
+}
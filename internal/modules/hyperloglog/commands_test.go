@@ -0,0 +1,272 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hyperloglog_test
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/echovault/sugardb/internal"
+	"github.com/echovault/sugardb/internal/config"
+	"github.com/echovault/sugardb/internal/constants"
+	"github.com/echovault/sugardb/sugardb"
+	"github.com/tidwall/resp"
+)
+
+// format returns the response as a string. Arrays are formatted as "[element element ...]" and nulls as "nil".
+func format(v resp.Value) string {
+	if v.IsNull() {
+		return "nil"
+	}
+	if v.Type() == resp.Array {
+		elements := make([]string, len(v.Array()))
+		for i, element := range v.Array() {
+			elements[i] = format(element)
+		}
+		return "[" + strings.Join(elements, " ") + "]"
+	}
+	return v.String()
+}
+
+func Test_HyperLogLog(t *testing.T) {
+	port, err := internal.GetFreePort()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	mockServer, err := sugardb.NewSugarDB(
+		sugardb.WithConfig(config.Config{
+			BindAddr:       "localhost",
+			Port:           uint16(port),
+			DataDir:        "",
+			EvictionPolicy: constants.NoEviction,
+		}),
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	go func() {
+		mockServer.Start()
+	}()
+
+	t.Cleanup(func() {
+		mockServer.ShutDown()
+	})
+
+	type step struct {
+		command []string
+		want    string // The expected response, as returned by format.
+		wantErr string // The expected error substring when the response is an error.
+	}
+
+	runSteps := func(t *testing.T, client *resp.Conn, steps []step) {
+		for _, step := range steps {
+			values := make([]resp.Value, len(step.command))
+			for i, token := range step.command {
+				values[i] = resp.StringValue(token)
+			}
+			if err := client.WriteArray(values); err != nil {
+				t.Error(err)
+				return
+			}
+			res, _, err := client.ReadValue()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if step.wantErr != "" {
+				if res.Error() == nil || !strings.Contains(res.Error().Error(), step.wantErr) {
+					t.Errorf("%q: expected error \"%s\", got %+v", step.command, step.wantErr, res)
+				}
+				continue
+			}
+			if res.Error() != nil {
+				t.Errorf("%q: unexpected error %v", step.command, res.Error())
+				continue
+			}
+			if got := format(res); got != step.want {
+				t.Errorf("%q: expected response %q, got %q", step.command, step.want, got)
+			}
+		}
+	}
+
+	connect := func(t *testing.T) *resp.Conn {
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			_ = conn.Close()
+		})
+		return resp.NewConn(conn)
+	}
+
+	// addElements adds count unique elements with the prefix to the HyperLogLog at key in batches of 1000.
+	addElements := func(t *testing.T, client *resp.Conn, key, prefix string, count int) {
+		for start := 0; start < count; start += 1000 {
+			command := []resp.Value{resp.StringValue("PFADD"), resp.StringValue(key)}
+			for i := start; i < min(start+1000, count); i++ {
+				command = append(command, resp.StringValue(fmt.Sprintf("%s-%d", prefix, i)))
+			}
+			if err := client.WriteArray(command); err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := client.ReadValue(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// assertCount checks that the estimated cardinality is within 2% of want.
+	assertCount := func(t *testing.T, client *resp.Conn, keys []string, want int) {
+		command := []resp.Value{resp.StringValue("PFCOUNT")}
+		for _, key := range keys {
+			command = append(command, resp.StringValue(key))
+		}
+		if err := client.WriteArray(command); err != nil {
+			t.Fatal(err)
+		}
+		res, _, err := client.ReadValue()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := strconv.Atoi(res.String())
+		if err != nil {
+			t.Fatalf("%v: expected an integer response, got %q", keys, res.String())
+		}
+		if math.Abs(float64(got-want)) > float64(want)*0.02 {
+			t.Errorf("%v: expected a count close to %d, got %d", keys, want, got)
+		}
+	}
+
+	t.Run("Test_HandlePFADD_PFCOUNT", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		tests := []struct {
+			name  string
+			steps []step
+		}{
+			{
+				name: "1. Add elements and count them",
+				steps: []step{
+					{command: []string{"PFADD", "PfaddKey1", "a", "b", "c", "d", "e", "f", "g"}, want: "1"},
+					{command: []string{"PFCOUNT", "PfaddKey1"}, want: "7"},
+					{command: []string{"PFADD", "PfaddKey1", "a", "b", "c"}, want: "0"},
+					{command: []string{"PFADD", "PfaddKey1", "h"}, want: "1"},
+					{command: []string{"PFCOUNT", "PfaddKey1"}, want: "8"},
+					{command: []string{"TYPE", "PfaddKey1"}, want: "string"},
+				},
+			},
+			{
+				name: "2. Create an empty HyperLogLog without elements",
+				steps: []step{
+					{command: []string{"PFADD", "PfaddKey2"}, want: "1"},
+					{command: []string{"PFADD", "PfaddKey2"}, want: "0"},
+					{command: []string{"PFCOUNT", "PfaddKey2"}, want: "0"},
+					{command: []string{"TYPE", "PfaddKey2"}, want: "string"},
+				},
+			},
+			{
+				name: "3. Count 0 for keys that don't exist",
+				steps: []step{
+					{command: []string{"PFCOUNT", "PfaddKey3"}, want: "0"},
+					{command: []string{"PFCOUNT", "PfaddKey3", "PfaddKey4"}, want: "0"},
+				},
+			},
+			{
+				name: "4. Return an error when the key is not a HyperLogLog",
+				steps: []step{
+					{command: []string{"SET", "PfaddKey5", "value"}, want: "OK"},
+					{command: []string{"PFADD", "PfaddKey5", "a"}, wantErr: "value at PfaddKey5 is not a hyperloglog"},
+					{command: []string{"PFCOUNT", "PfaddKey1", "PfaddKey5"}, wantErr: "value at PfaddKey5 is not a hyperloglog"},
+					{command: []string{"PFADD"}, wantErr: constants.WrongArgsResponse},
+					{command: []string{"PFCOUNT"}, wantErr: constants.WrongArgsResponse},
+				},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				runSteps(t, client, test.steps)
+			})
+		}
+
+		t.Run("5. Estimate large cardinalities across the sparse and dense representations", func(t *testing.T) {
+			for _, count := range []int{500, 1000, 10000, 100000} {
+				addElements(t, client, "PfaddKey6", "element", count)
+				assertCount(t, client, []string{"PfaddKey6"}, count)
+			}
+		})
+	})
+
+	t.Run("Test_HandlePFCOUNT_Union", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		// The HyperLogLogs share 5000 elements.
+		addElements(t, client, "PfcountKey1", "element", 10000)
+		addElements(t, client, "PfcountKey2", "element", 5000)
+		addElements(t, client, "PfcountKey2", "other", 5000)
+
+		assertCount(t, client, []string{"PfcountKey1", "PfcountKey2"}, 15000)
+		assertCount(t, client, []string{"PfcountKey1", "PfcountKey2", "PfcountKey3"}, 15000)
+
+		// Counting the union does not change the HyperLogLogs.
+		assertCount(t, client, []string{"PfcountKey1"}, 10000)
+		assertCount(t, client, []string{"PfcountKey2"}, 10000)
+	})
+
+	t.Run("Test_HandlePFMERGE", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"PFADD", "PfmergeKey1", "a", "b", "c"}, want: "1"},
+			{command: []string{"PFADD", "PfmergeKey2", "c", "d", "e"}, want: "1"},
+			{command: []string{"PFMERGE", "PfmergeDest1", "PfmergeKey1", "PfmergeKey2", "PfmergeKey3"}, want: "OK"},
+			{command: []string{"PFCOUNT", "PfmergeDest1"}, want: "5"},
+			// The destination is part of the union when it exists.
+			{command: []string{"PFADD", "PfmergeKey3", "f"}, want: "1"},
+			{command: []string{"PFMERGE", "PfmergeDest1", "PfmergeKey3"}, want: "OK"},
+			{command: []string{"PFCOUNT", "PfmergeDest1"}, want: "6"},
+			{command: []string{"PFMERGE", "PfmergeDest1", "PfmergeDest1"}, want: "OK"},
+			{command: []string{"PFCOUNT", "PfmergeDest1"}, want: "6"},
+			// The sources are not changed.
+			{command: []string{"PFCOUNT", "PfmergeKey1"}, want: "3"},
+			// The destination is created without sources.
+			{command: []string{"PFMERGE", "PfmergeDest2"}, want: "OK"},
+			{command: []string{"PFCOUNT", "PfmergeDest2"}, want: "0"},
+			{command: []string{"SET", "PfmergeKey4", "value"}, want: "OK"},
+			{command: []string{"PFMERGE", "PfmergeDest3", "PfmergeKey4"}, wantErr: "value at PfmergeKey4 is not a hyperloglog"},
+			{command: []string{"PFMERGE", "PfmergeKey4", "PfmergeKey1"}, wantErr: "value at PfmergeKey4 is not a hyperloglog"},
+			{command: []string{"PFMERGE"}, wantErr: constants.WrongArgsResponse},
+		})
+
+		// Merging dense HyperLogLogs estimates the cardinality of their union.
+		addElements(t, client, "PfmergeKey5", "element", 10000)
+		addElements(t, client, "PfmergeKey6", "other", 10000)
+		runSteps(t, client, []step{
+			{command: []string{"PFMERGE", "PfmergeDest4", "PfmergeKey5", "PfmergeKey6"}, want: "OK"},
+		})
+		assertCount(t, client, []string{"PfmergeDest4"}, 20000)
+	})
+}
@@ -0,0 +1,331 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hyperloglog
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"math/bits"
+	"slices"
+	"sync"
+	"unsafe"
+
+	"github.com/echovault/sugardb/internal"
+	"github.com/echovault/sugardb/internal/constants"
+)
+
+func init() {
+	internal.RegisterCompositeType("hyperloglog", func(data []byte) (interface{}, error) {
+		hll := NewHyperLogLog()
+		if err := json.Unmarshal(data, hll); err != nil {
+			return nil, err
+		}
+		return hll, nil
+	})
+}
+
+const (
+	precision     = 14                               // The number of bits of the hash used to select a register.
+	registerCount = 1 << precision                   // 16384 registers, for a standard error of 0.81%.
+	registerBits  = 6                                // The number of bits of a register in the dense representation.
+	registerMax   = 1<<registerBits - 1              // The largest value a register can hold.
+	denseSize     = registerCount * registerBits / 8 // 12KB.
+	rankBits      = 64 - precision                   // The number of bits of the hash used to compute the rank.
+
+	// sparseMaxEntries is the number of registers the sparse representation holds before it's converted to the
+	// dense representation. Sparse entries take 4 bytes, so the sparse representation stays under 3KB.
+	sparseMaxEntries = 750
+)
+
+// HyperLogLog estimates the number of unique elements added to it using a fixed amount of memory.
+//
+// It starts with a sparse representation that only stores the registers that are not 0, which is cheap for small
+// cardinalities. It's converted to a dense representation of 16384 6-bit registers once it has too many registers.
+type HyperLogLog struct {
+	mut sync.RWMutex
+	// sparse holds the registers that are not 0, sorted by index. Each entry is the index of the register
+	// in the upper 24 bits and its value in the lower 8 bits. It's only used when dense is nil.
+	sparse []uint32
+	dense  []byte
+}
+
+// compile time interface check
+var _ constants.CompositeType = (*HyperLogLog)(nil)
+
+func NewHyperLogLog() *HyperLogLog {
+	return &HyperLogLog{sparse: make([]uint32, 0)}
+}
+
+func (hll *HyperLogLog) GetMem() int64 {
+	hll.mut.RLock()
+	defer hll.mut.RUnlock()
+	return int64(unsafe.Sizeof(*hll)) + int64(cap(hll.sparse))*4 + int64(cap(hll.dense))
+}
+
+// IsSparse returns true if the HyperLogLog uses the sparse representation.
+func (hll *HyperLogLog) IsSparse() bool {
+	hll.mut.RLock()
+	defer hll.mut.RUnlock()
+	return hll.dense == nil
+}
+
+func (hll *HyperLogLog) register(index int) uint8 {
+	if hll.dense == nil {
+		i, found := hll.sparseIndex(index)
+		if !found {
+			return 0
+		}
+		return uint8(hll.sparse[i])
+	}
+	// Registers are packed from the least significant bit, so a register can span two bytes.
+	offset := index * registerBits
+	b, shift := offset/8, offset%8
+	value := uint16(hll.dense[b]) >> shift
+	if b+1 < len(hll.dense) {
+		value |= uint16(hll.dense[b+1]) << (8 - shift)
+	}
+	return uint8(value & registerMax)
+}
+
+func (hll *HyperLogLog) setRegister(index int, value uint8) {
+	if hll.dense == nil {
+		i, found := hll.sparseIndex(index)
+		entry := uint32(index)<<8 | uint32(value)
+		if found {
+			hll.sparse[i] = entry
+			return
+		}
+		hll.sparse = slices.Insert(hll.sparse, i, entry)
+		if len(hll.sparse) > sparseMaxEntries {
+			hll.toDense()
+		}
+		return
+	}
+	offset := index * registerBits
+	b, shift := offset/8, offset%8
+	word := uint16(hll.dense[b])
+	if b+1 < len(hll.dense) {
+		word |= uint16(hll.dense[b+1]) << 8
+	}
+	word = word&^(registerMax<<shift) | uint16(value)<<shift
+	hll.dense[b] = byte(word)
+	if b+1 < len(hll.dense) {
+		hll.dense[b+1] = byte(word >> 8)
+	}
+}
+
+func (hll *HyperLogLog) sparseIndex(index int) (int, bool) {
+	return slices.BinarySearchFunc(hll.sparse, index, func(entry uint32, index int) int {
+		return int(entry>>8) - index
+	})
+}
+
+func (hll *HyperLogLog) toDense() {
+	sparse := hll.sparse
+	hll.sparse = nil
+	hll.dense = make([]byte, denseSize)
+	for _, entry := range sparse {
+		hll.setRegister(int(entry>>8), uint8(entry))
+	}
+}
+
+// registers returns the value of every register.
+func (hll *HyperLogLog) registers() []uint8 {
+	hll.mut.RLock()
+	defer hll.mut.RUnlock()
+	registers := make([]uint8, registerCount)
+	if hll.dense == nil {
+		for _, entry := range hll.sparse {
+			registers[entry>>8] = uint8(entry)
+		}
+		return registers
+	}
+	for i := range registers {
+		registers[i] = hll.register(i)
+	}
+	return registers
+}
+
+// hashElement returns the register of the element and the rank to store in it, which is the position of the
+// first bit set to 1 in the rest of the hash.
+func hashElement(element string) (int, uint8) {
+	hash := murmurHash64A([]byte(element), 0xadc83b19)
+	index := int(hash & (registerCount - 1))
+	// The extra bit guarantees that the rank is at most rankBits+1.
+	hash = hash>>precision | 1<<rankBits
+	return index, uint8(bits.TrailingZeros64(hash) + 1)
+}
+
+// Add adds the elements to the HyperLogLog. Returns true if the estimated cardinality might have changed.
+func (hll *HyperLogLog) Add(elements ...string) bool {
+	hll.mut.Lock()
+	defer hll.mut.Unlock()
+	changed := false
+	for _, element := range elements {
+		index, rank := hashElement(element)
+		if rank > hll.register(index) {
+			hll.setRegister(index, rank)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Merge sets each register of the HyperLogLog to the maximum of its value and the values of the same register
+// in the other HyperLogLogs, so that it estimates the cardinality of their union.
+func (hll *HyperLogLog) Merge(others ...*HyperLogLog) {
+	for _, other := range others {
+		if other == hll {
+			continue
+		}
+		// Copy the registers of the other HyperLogLog before locking this one so that merges in both
+		// directions can't deadlock.
+		registers := other.registers()
+		hll.mut.Lock()
+		for index, value := range registers {
+			if value > hll.register(index) {
+				hll.setRegister(index, value)
+			}
+		}
+		hll.mut.Unlock()
+	}
+}
+
+// Count returns the estimated number of unique elements added to the HyperLogLog.
+func (hll *HyperLogLog) Count() int {
+	return count(hll.registers())
+}
+
+// Union returns the estimated number of unique elements added to any of the HyperLogLogs without changing them.
+func Union(hlls ...*HyperLogLog) int {
+	registers := make([]uint8, registerCount)
+	for _, hll := range hlls {
+		for index, value := range hll.registers() {
+			registers[index] = max(registers[index], value)
+		}
+	}
+	return count(registers)
+}
+
+// count estimates the cardinality from the registers with the estimator described in
+// "New cardinality estimation algorithms for HyperLogLog sketches" by Otmar Ertl, which is accurate
+// for both small and large cardinalities.
+func count(registers []uint8) int {
+	var histogram [rankBits + 2]int
+	for _, value := range registers {
+		histogram[value]++
+	}
+	m := float64(registerCount)
+	z := m * tau((m-float64(histogram[rankBits+1]))/m)
+	for j := rankBits; j >= 1; j-- {
+		z += float64(histogram[j])
+		z *= 0.5
+	}
+	z += m * sigma(float64(histogram[0])/m)
+	return int(math.Round(0.5 / math.Ln2 * m * m / z))
+}
+
+func sigma(x float64) float64 {
+	if x == 1 {
+		return math.Inf(1)
+	}
+	y, z := 1.0, x
+	for {
+		x *= x
+		previous := z
+		z += x * y
+		y += y
+		if z == previous {
+			return z
+		}
+	}
+}
+
+func tau(x float64) float64 {
+	if x == 0 || x == 1 {
+		return 0
+	}
+	y, z := 1.0, 1-x
+	for {
+		x = math.Sqrt(x)
+		previous := z
+		y *= 0.5
+		z -= math.Pow(1-x, 2) * y
+		if z == previous {
+			return z / 3
+		}
+	}
+}
+
+// murmurHash64A is the 64-bit MurmurHash2 variant by Austin Appleby. It's fast and its bits are evenly
+// distributed, which the estimate relies on.
+func murmurHash64A(key []byte, seed uint64) uint64 {
+	const m = 0xc6a4a7935bd1e995
+	const r = 47
+
+	h := seed ^ uint64(len(key))*m
+	for len(key) >= 8 {
+		k := binary.LittleEndian.Uint64(key)
+		k *= m
+		k ^= k >> r
+		k *= m
+		h ^= k
+		h *= m
+		key = key[8:]
+	}
+	if len(key) > 0 {
+		var tail [8]byte
+		copy(tail[:], key)
+		h ^= binary.LittleEndian.Uint64(tail[:])
+		h *= m
+	}
+	h ^= h >> r
+	h *= m
+	h ^= h >> r
+	return h
+}
+
+type hyperLogLogJSON struct {
+	Sparse []uint32
+	Dense  []byte
+}
+
+// CompositeType returns the type tag that restores the HyperLogLog from a snapshot.
+func (hll *HyperLogLog) CompositeType() string {
+	return "hyperloglog"
+}
+
+// MarshalJSON encodes the registers of the HyperLogLog so that it can be restored from a snapshot.
+func (hll *HyperLogLog) MarshalJSON() ([]byte, error) {
+	hll.mut.RLock()
+	defer hll.mut.RUnlock()
+	return json.Marshal(hyperLogLogJSON{Sparse: hll.sparse, Dense: hll.dense})
+}
+
+// UnmarshalJSON decodes the data of a HyperLogLog encoded by MarshalJSON.
+func (hll *HyperLogLog) UnmarshalJSON(b []byte) error {
+	var data hyperLogLogJSON
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	hll.mut.Lock()
+	defer hll.mut.Unlock()
+	hll.sparse, hll.dense = data.Sparse, data.Dense
+	if hll.dense == nil && hll.sparse == nil {
+		hll.sparse = make([]uint32, 0)
+	}
+	return nil
+}
@@ -19,9 +19,11 @@ import (
 	"fmt"
 	"github.com/echovault/sugardb/internal"
 	"github.com/echovault/sugardb/internal/constants"
+	"math"
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 func handleLLen(params internal.HandlerFuncParams) ([]byte, error) {
@@ -498,6 +500,415 @@ func handlePop(params internal.HandlerFuncParams) ([]byte, error) {
 	return []byte(res), nil
 }
 
+func handleLInsert(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := linsertKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+
+	key := keys.WriteKeys[0]
+	where := strings.ToLower(params.Command[2])
+	if where != "before" && where != "after" {
+		return nil, errors.New("syntax error")
+	}
+	pivot, element := params.Command[3], params.Command[4]
+
+	if !params.KeysExist(params.Context, keys.WriteKeys)[key] {
+		return []byte(":0\r\n"), nil
+	}
+
+	list, ok := params.GetValues(params.Context, []string{key})[key].([]string)
+	if !ok {
+		return nil, errors.New("LINSERT command on non-list item")
+	}
+
+	index := slices.Index(list, pivot)
+	if index == -1 {
+		return []byte(":-1\r\n"), nil
+	}
+	if where == "after" {
+		index += 1
+	}
+
+	list = slices.Insert(slices.Clone(list), index, element)
+	if err = params.SetValues(params.Context, map[string]interface{}{key: list}); err != nil {
+		return nil, err
+	}
+
+	return []byte(fmt.Sprintf(":%d\r\n", len(list))), nil
+}
+
+func handleLPos(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := lposKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+
+	key := keys.ReadKeys[0]
+	element := params.Command[2]
+	rank, count, maxLen := 1, -1, 0
+
+	for i := 3; i < len(params.Command); i += 2 {
+		if i+1 >= len(params.Command) {
+			return nil, errors.New("syntax error")
+		}
+		value, err := strconv.Atoi(params.Command[i+1])
+		if err != nil {
+			return nil, errors.New("value is not an integer or out of range")
+		}
+		switch strings.ToLower(params.Command[i]) {
+		case "rank":
+			if value == 0 {
+				return nil, errors.New("RANK can't be zero: use 1 to start from the first match, 2 from the second ... or use negative to start from the end of the list")
+			}
+			rank = value
+		case "count":
+			if value < 0 {
+				return nil, errors.New("COUNT can't be negative")
+			}
+			count = value
+		case "maxlen":
+			if value < 0 {
+				return nil, errors.New("MAXLEN can't be negative")
+			}
+			maxLen = value
+		default:
+			return nil, errors.New("syntax error")
+		}
+	}
+
+	var list []string
+	if params.KeysExist(params.Context, keys.ReadKeys)[key] {
+		var ok bool
+		if list, ok = params.GetValues(params.Context, []string{key})[key].([]string); !ok {
+			return nil, errors.New("LPOS command on non-list item")
+		}
+	}
+
+	// A negative rank scans the list from the end, and skips the first -rank-1 matches from the end.
+	var matches []int
+	skip := internal.AbsInt(rank) - 1
+	for i := 0; i < len(list) && (maxLen == 0 || i < maxLen); i++ {
+		index := i
+		if rank < 0 {
+			index = len(list) - 1 - i
+		}
+		if list[index] != element {
+			continue
+		}
+		if skip > 0 {
+			skip -= 1
+			continue
+		}
+		matches = append(matches, index)
+		// Without COUNT only the first match is needed, and COUNT 0 returns all the matches.
+		if count == -1 || len(matches) == count {
+			break
+		}
+	}
+
+	// Without COUNT, return the index of the first match or nil.
+	if count == -1 {
+		if len(matches) == 0 {
+			return []byte("$-1\r\n"), nil
+		}
+		return []byte(fmt.Sprintf(":%d\r\n", matches[0])), nil
+	}
+
+	res := fmt.Sprintf("*%d\r\n", len(matches))
+	for _, index := range matches {
+		res += fmt.Sprintf(":%d\r\n", index)
+	}
+	return []byte(res), nil
+}
+
+func handleLMPop(params internal.HandlerFuncParams) ([]byte, error) {
+	if _, err := lmpopKeyFunc(params.Command); err != nil {
+		return nil, err
+	}
+
+	keys, where, count, err := parseMPopArgs(params.Command[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	keysExist := params.KeysExist(params.Context, keys)
+	values := params.GetValues(params.Context, keys)
+	for _, key := range keys {
+		if !keysExist[key] {
+			continue
+		}
+		list, ok := values[key].([]string)
+		if !ok {
+			return nil, errors.New("LMPOP command on non-list item")
+		}
+		if len(list) == 0 {
+			continue
+		}
+		return mpopElements(params, key, list, where, count)
+	}
+
+	return []byte("*-1\r\n"), nil
+}
+
+func handleRPopLPush(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := rpoplpushKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	source, destination := keys.WriteKeys[0], keys.WriteKeys[1]
+
+	if !params.KeysExist(params.Context, []string{source})[source] {
+		return []byte("$-1\r\n"), nil
+	}
+	list, ok := params.GetValues(params.Context, []string{source})[source].([]string)
+	if !ok {
+		return nil, errors.New("both source and destination must be lists")
+	}
+	if len(list) == 0 {
+		return []byte("$-1\r\n"), nil
+	}
+
+	return moveElement(params, source, destination, list, "right", "left")
+}
+
+// parseTimeout parses the timeout of a blocking command in seconds. 0 blocks indefinitely.
+func parseTimeout(arg string) (time.Duration, error) {
+	seconds, err := strconv.ParseFloat(arg, 64)
+	if err != nil || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return 0, errors.New("timeout is not a float or out of range")
+	}
+	if seconds < 0 {
+		return 0, errors.New("timeout is negative")
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// parseMPopArgs parses the "numkeys key [key ...] <LEFT | RIGHT> [COUNT count]" arguments of LMPOP and BLMPOP.
+func parseMPopArgs(args []string) ([]string, string, int, error) {
+	numKeys, err := strconv.Atoi(args[0])
+	if err != nil {
+		return nil, "", 0, errors.New("numkeys must be an integer")
+	}
+	if numKeys <= 0 {
+		return nil, "", 0, errors.New("numkeys should be greater than 0")
+	}
+	if len(args) < numKeys+2 {
+		return nil, "", 0, errors.New("syntax error")
+	}
+	keys := args[1 : numKeys+1]
+
+	where := strings.ToLower(args[numKeys+1])
+	if where != "left" && where != "right" {
+		return nil, "", 0, errors.New("syntax error")
+	}
+
+	count := 1
+	switch rest := args[numKeys+2:]; {
+	case len(rest) == 0:
+	case len(rest) == 2 && strings.EqualFold(rest[0], "count"):
+		if count, err = strconv.Atoi(rest[1]); err != nil || count <= 0 {
+			return nil, "", 0, errors.New("count should be greater than 0")
+		}
+	default:
+		return nil, "", 0, errors.New("syntax error")
+	}
+
+	return keys, where, count, nil
+}
+
+// popElements removes count elements from the end of the list specified by where, and returns them in the order
+// they were removed along with the rest of the list.
+func popElements(list []string, where string, count int) ([]string, []string) {
+	count = min(count, len(list))
+	popped := make([]string, count)
+	for i := range popped {
+		if where == "left" {
+			popped[i] = list[i]
+		} else {
+			popped[i] = list[len(list)-1-i]
+		}
+	}
+	if where == "left" {
+		return popped, append([]string{}, list[count:]...)
+	}
+	return popped, append([]string{}, list[:len(list)-count]...)
+}
+
+// moveElement pops an element from the end of the source list specified by whereFrom, and pushes it to the end of
+// the destination list specified by whereTo. The destination is created if it does not exist.
+func moveElement(
+	params internal.HandlerFuncParams,
+	source, destination string,
+	list []string,
+	whereFrom, whereTo string,
+) ([]byte, error) {
+	destinationList := []string{}
+	if params.KeysExist(params.Context, []string{destination})[destination] {
+		var ok bool
+		if destinationList, ok = params.GetValues(params.Context, []string{destination})[destination].([]string); !ok {
+			return nil, errors.New("both source and destination must be lists")
+		}
+	}
+
+	popped, list := popElements(list, whereFrom, 1)
+	if source == destination {
+		destinationList = list
+	}
+	if whereTo == "left" {
+		destinationList = append([]string{popped[0]}, destinationList...)
+	} else {
+		destinationList = append(append([]string{}, destinationList...), popped[0])
+	}
+
+	entries := map[string]interface{}{source: list}
+	entries[destination] = destinationList
+	if err := params.SetValues(params.Context, entries); err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf("$%d\r\n%s\r\n", len(popped[0]), popped[0])), nil
+}
+
+// mpopElements pops count elements from the list at the key, and returns them as an array of the key and the elements.
+func mpopElements(params internal.HandlerFuncParams, key string, list []string, where string, count int) ([]byte, error) {
+	popped, list := popElements(list, where, count)
+	if err := params.SetValues(params.Context, map[string]interface{}{key: list}); err != nil {
+		return nil, err
+	}
+	res := fmt.Sprintf("*2\r\n$%d\r\n%s\r\n*%d\r\n", len(key), key, len(popped))
+	for _, element := range popped {
+		res += fmt.Sprintf("$%d\r\n%s\r\n", len(element), element)
+	}
+	return []byte(res), nil
+}
+
+// blockingPop calls pop with the first of the keys that holds a non-empty list. When all the lists are empty,
+// it waits until one of the keys is pushed to or the timeout expires, in which case it returns nullResponse.
+// Clients blocked on the same key are served in the order they blocked.
+func blockingPop(
+	params internal.HandlerFuncParams,
+	keys []string,
+	timeout time.Duration,
+	nullResponse string,
+	pop func(key string, list []string) ([]byte, error),
+) ([]byte, error) {
+	// Register before checking the lists so that a push between the check and the wait is not missed.
+	ready, cancel := params.BlockOnKeys(params.Context, keys)
+	defer cancel()
+
+	// On the raft leader, the command is applied through the raft log once one of the lists has elements.
+	apply, _ := params.Context.Value(internal.ContextRaftApply("RaftApply")).(func() ([]byte, error))
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		expired = params.GetClock().After(timeout)
+	}
+
+	for {
+		exists := params.KeysExist(params.Context, keys)
+		values := params.GetValues(params.Context, keys)
+		for _, key := range keys {
+			if !exists[key] {
+				continue
+			}
+			list, ok := values[key].([]string)
+			if !ok {
+				return nil, fmt.Errorf("%s command on non-list item", strings.ToUpper(params.Command[0]))
+			}
+			if len(list) == 0 || (ready != nil && !params.IsFirstBlocked(params.Context, key, ready)) {
+				continue
+			}
+			if apply == nil {
+				return pop(key, list)
+			}
+			res, err := apply()
+			if res != nil || err != nil {
+				return res, err
+			}
+			break
+		}
+
+		if ready == nil {
+			// The command can't block inside a transaction or a script, or when it's replayed.
+			return []byte(nullResponse), nil
+		}
+		select {
+		case <-ready:
+		case <-expired:
+			return []byte(nullResponse), nil
+		case <-params.Context.Done():
+			return []byte(nullResponse), nil
+		}
+	}
+}
+
+func handleBPop(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := blpopKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout, err := parseTimeout(params.Command[len(params.Command)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	where := "left"
+	if strings.EqualFold(params.Command[0], "brpop") {
+		where = "right"
+	}
+
+	return blockingPop(params, keys.WriteKeys, timeout, "*-1\r\n", func(key string, list []string) ([]byte, error) {
+		popped, list := popElements(list, where, 1)
+		if err := params.SetValues(params.Context, map[string]interface{}{key: list}); err != nil {
+			return nil, err
+		}
+		return []byte(fmt.Sprintf("*2\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(key), key, len(popped[0]), popped[0])), nil
+	})
+}
+
+func handleBLMove(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := blmoveKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	source, destination := keys.WriteKeys[0], keys.WriteKeys[1]
+	whereFrom := strings.ToLower(params.Command[3])
+	whereTo := strings.ToLower(params.Command[4])
+
+	if !slices.Contains([]string{"left", "right"}, whereFrom) || !slices.Contains([]string{"left", "right"}, whereTo) {
+		return nil, errors.New("wherefrom and whereto arguments must be either LEFT or RIGHT")
+	}
+
+	timeout, err := parseTimeout(params.Command[5])
+	if err != nil {
+		return nil, err
+	}
+
+	return blockingPop(params, []string{source}, timeout, "$-1\r\n", func(key string, list []string) ([]byte, error) {
+		return moveElement(params, source, destination, list, whereFrom, whereTo)
+	})
+}
+
+func handleBLMPop(params internal.HandlerFuncParams) ([]byte, error) {
+	if _, err := blmpopKeyFunc(params.Command); err != nil {
+		return nil, err
+	}
+
+	timeout, err := parseTimeout(params.Command[1])
+	if err != nil {
+		return nil, err
+	}
+	keys, where, count, err := parseMPopArgs(params.Command[2:])
+	if err != nil {
+		return nil, err
+	}
+
+	return blockingPop(params, keys, timeout, "*-1\r\n", func(key string, list []string) ([]byte, error) {
+		return mpopElements(params, key, list, where, count)
+	})
+}
+
 func Commands() []internal.Command {
 	return []internal.Command{
 		{
@@ -559,6 +970,30 @@ Returns an array of n elements from the beginning of the list when called with a
 			KeyExtractionFunc: lindexKeyFunc,
 			HandlerFunc:       handleLIndex,
 		},
+		{
+			Command:    "linsert",
+			Module:     constants.ListModule,
+			Categories: []string{constants.ListCategory, constants.WriteCategory, constants.SlowCategory},
+			Description: `(LINSERT key <BEFORE | AFTER> pivot element)
+Inserts the element before or after the first occurrence of pivot in the list. Returns the length of the list
+after the insert, -1 when pivot is not found, or 0 when the key does not exist.`,
+			Sync:              true,
+			KeyExtractionFunc: linsertKeyFunc,
+			HandlerFunc:       handleLInsert,
+		},
+		{
+			Command:    "lpos",
+			Module:     constants.ListModule,
+			Categories: []string{constants.ListCategory, constants.ReadCategory, constants.SlowCategory},
+			Description: `(LPOS key element [RANK rank] [COUNT num-matches] [MAXLEN len])
+Returns the index of the first element of the list that matches the element, or nil when there is no match.
+RANK skips the first rank-1 matches, and a negative rank searches from the end of the list.
+COUNT returns an array of the indices of up to num-matches matches, and 0 returns all the matches.
+MAXLEN compares at most len elements, and 0 compares all of them.`,
+			Sync:              false,
+			KeyExtractionFunc: lposKeyFunc,
+			HandlerFunc:       handleLPos,
+		},
 		{
 			Command:           "lset",
 			Module:            constants.ListModule,
@@ -596,6 +1031,17 @@ Move element from one list to the other specifying left/right for both lists.`,
 			KeyExtractionFunc: lmoveKeyFunc,
 			HandlerFunc:       handleLMove,
 		},
+		{
+			Command:    "lmpop",
+			Module:     constants.ListModule,
+			Categories: []string{constants.ListCategory, constants.WriteCategory, constants.SlowCategory},
+			Description: `(LMPOP numkeys key [key ...] <LEFT | RIGHT> [COUNT count])
+Pops up to 'count' elements from the first non-empty list, as an array of the key and the elements.
+LEFT or RIGHT determines whether to pop from the beginning or the end of the list. Returns nil when all the lists are empty.`,
+			Sync:              true,
+			KeyExtractionFunc: lmpopKeyFunc,
+			HandlerFunc:       handleLMPop,
+		},
 		{
 			Command:    "rpop",
 			Module:     constants.ListModule,
@@ -608,6 +1054,17 @@ Returns an array of n elements from the end of the list when called with a count
 			KeyExtractionFunc: popKeyFunc,
 			HandlerFunc:       handlePop,
 		},
+		{
+			Command:    "rpoplpush",
+			Module:     constants.ListModule,
+			Categories: []string{constants.ListCategory, constants.WriteCategory, constants.SlowCategory},
+			Description: `(RPOPLPUSH source destination)
+Removes the last element of the source list and pushes it to the beginning of the destination list, creating
+the destination if it does not exist. Returns the element moved, or nil when the source list is empty.`,
+			Sync:              true,
+			KeyExtractionFunc: rpoplpushKeyFunc,
+			HandlerFunc:       handleRPopLPush,
+		},
 		{
 			Command:           "rpush",
 			Module:            constants.ListModule,
@@ -626,5 +1083,55 @@ Returns an array of n elements from the end of the list when called with a count
 			KeyExtractionFunc: rpushKeyFunc,
 			HandlerFunc:       handleRPush,
 		},
+		{
+			Command:    "blpop",
+			Module:     constants.ListModule,
+			Categories: []string{constants.ListCategory, constants.WriteCategory, constants.SlowCategory, constants.BlockingCategory},
+			Description: `(BLPOP key [key ...] timeout)
+Removes and returns the first element of the first non-empty list, as an array of the key and the element.
+Blocks until an element is pushed to one of the lists when they are all empty. The timeout is in seconds,
+and 0 blocks indefinitely. Returns nil when the timeout expires.
+Clients blocked on the same key are served in the order they blocked.`,
+			Sync:              true,
+			KeyExtractionFunc: blpopKeyFunc,
+			HandlerFunc:       handleBPop,
+		},
+		{
+			Command:    "brpop",
+			Module:     constants.ListModule,
+			Categories: []string{constants.ListCategory, constants.WriteCategory, constants.SlowCategory, constants.BlockingCategory},
+			Description: `(BRPOP key [key ...] timeout)
+Removes and returns the last element of the first non-empty list, as an array of the key and the element.
+Blocks until an element is pushed to one of the lists when they are all empty. The timeout is in seconds,
+and 0 blocks indefinitely. Returns nil when the timeout expires.
+Clients blocked on the same key are served in the order they blocked.`,
+			Sync:              true,
+			KeyExtractionFunc: blpopKeyFunc,
+			HandlerFunc:       handleBPop,
+		},
+		{
+			Command:    "blmove",
+			Module:     constants.ListModule,
+			Categories: []string{constants.ListCategory, constants.WriteCategory, constants.SlowCategory, constants.BlockingCategory},
+			Description: `(BLMOVE source destination <LEFT | RIGHT> <LEFT | RIGHT> timeout)
+Moves an element from one end of the source list to one end of the destination list and returns it.
+The destination list is created if it does not exist. Blocks until an element is pushed to the source list
+when it's empty. The timeout is in seconds, and 0 blocks indefinitely. Returns nil when the timeout expires.`,
+			Sync:              true,
+			KeyExtractionFunc: blmoveKeyFunc,
+			HandlerFunc:       handleBLMove,
+		},
+		{
+			Command:    "blmpop",
+			Module:     constants.ListModule,
+			Categories: []string{constants.ListCategory, constants.WriteCategory, constants.SlowCategory, constants.BlockingCategory},
+			Description: `(BLMPOP timeout numkeys key [key ...] <LEFT | RIGHT> [COUNT count])
+Removes up to count elements from one end of the first non-empty list. Returns an array of the key
+and the elements removed. Blocks until an element is pushed to one of the lists when they are all empty.
+The timeout is in seconds, and 0 blocks indefinitely. Returns nil when the timeout expires.`,
+			Sync:              true,
+			KeyExtractionFunc: blmpopKeyFunc,
+			HandlerFunc:       handleBLMPop,
+		},
 	}
 }
@@ -26,8 +26,23 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
+func format(v resp.Value) string {
+	if v.IsNull() {
+		return "nil"
+	}
+	if v.Type() == resp.Array {
+		elements := make([]string, len(v.Array()))
+		for i, element := range v.Array() {
+			elements[i] = format(element)
+		}
+		return "[" + strings.Join(elements, " ") + "]"
+	}
+	return v.String()
+}
+
 func Test_List(t *testing.T) {
 	port, err := internal.GetFreePort()
 	if err != nil {
@@ -56,6 +71,94 @@ func Test_List(t *testing.T) {
 		mockServer.ShutDown()
 	})
 
+	type step struct {
+		command []string
+		want    string // The expected response, as returned by format.
+		wantErr string // The expected error substring when the response is an error.
+	}
+
+	send := func(client *resp.Conn, command []string) (resp.Value, error) {
+		values := make([]resp.Value, len(command))
+		for i, token := range command {
+			values[i] = resp.StringValue(token)
+		}
+		if err := client.WriteArray(values); err != nil {
+			return resp.Value{}, err
+		}
+		res, _, err := client.ReadValue()
+		return res, err
+	}
+
+	runSteps := func(t *testing.T, client *resp.Conn, steps []step) {
+		for _, step := range steps {
+			res, err := send(client, step.command)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if step.wantErr != "" {
+				if res.Error() == nil || !strings.Contains(res.Error().Error(), step.wantErr) {
+					t.Errorf("%v: expected error \"%s\", got %+v", step.command, step.wantErr, res)
+				}
+				continue
+			}
+			if res.Error() != nil {
+				t.Errorf("%v: unexpected error %v", step.command, res.Error())
+				continue
+			}
+			if got := format(res); got != step.want {
+				t.Errorf("%v: expected response \"%s\", got \"%s\"", step.command, step.want, got)
+			}
+		}
+	}
+
+	connect := func(t *testing.T) *resp.Conn {
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			_ = conn.Close()
+		})
+		return resp.NewConn(conn)
+	}
+
+	// block sends the command from a new connection and returns a channel that receives the response.
+	// It waits until the command had time to block, and fails the test if it returned instead.
+	block := func(t *testing.T, command []string) <-chan string {
+		client := connect(t)
+		done := make(chan string, 1)
+		go func() {
+			res, err := send(client, command)
+			if err != nil {
+				t.Error(err)
+			}
+			if res.Error() != nil {
+				done <- res.Error().Error()
+				return
+			}
+			done <- format(res)
+		}()
+		time.Sleep(100 * time.Millisecond)
+		select {
+		case res := <-done:
+			t.Fatalf("expected %v to block, got %s", command, res)
+		default:
+		}
+		return done
+	}
+
+	receive := func(t *testing.T, done <-chan string, want string) {
+		select {
+		case got := <-done:
+			if got != want {
+				t.Errorf("expected response \"%s\", got \"%s\"", want, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("timed out waiting for response \"%s\"", want)
+		}
+	}
+
 	t.Run("Test_HandleLLEN", func(t *testing.T) {
 		t.Parallel()
 		conn, err := internal.GetConnection("localhost", port)
@@ -1926,4 +2029,213 @@ func Test_List(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("Test_HandleBLPOP_BRPOP", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"RPUSH", "BlpopKey1", "a", "b", "c"}, want: "3"},
+			{command: []string{"BLPOP", "BlpopKey0", "BlpopKey1", "0"}, want: "[BlpopKey1 a]"},
+			{command: []string{"BRPOP", "BlpopKey0", "BlpopKey1", "0"}, want: "[BlpopKey1 c]"},
+			{command: []string{"BLPOP", "BlpopKey1", "1.5"}, want: "[BlpopKey1 b]"},
+			{command: []string{"LLEN", "BlpopKey1"}, want: "0"},
+			{command: []string{"BLPOP", "BlpopKey1", "BlpopKey2", "0.05"}, want: "nil"},
+			{command: []string{"BRPOP", "BlpopKey1", "0.05"}, want: "nil"},
+			// Blocking commands return immediately inside a transaction.
+			{command: []string{"MULTI"}, want: "OK"},
+			{command: []string{"BLPOP", "BlpopKey1", "0"}, want: "QUEUED"},
+			{command: []string{"EXEC"}, want: "[nil]"},
+			{command: []string{"SET", "BlpopKey3", "value"}, want: "OK"},
+			{command: []string{"BLPOP", "BlpopKey1", "BlpopKey3", "0"}, wantErr: "BLPOP command on non-list item"},
+			{command: []string{"BLPOP", "BlpopKey1", "-1"}, wantErr: "timeout is negative"},
+			{command: []string{"BRPOP", "BlpopKey1", "soon"}, wantErr: "timeout is not a float or out of range"},
+			{command: []string{"BLPOP", "BlpopKey1"}, wantErr: constants.WrongArgsResponse},
+		})
+	})
+
+	t.Run("Test_HandleBLPOP_Block", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		// The clients blocked on a key are served in the order they blocked.
+		first := block(t, []string{"BLPOP", "BlpopBlockKey1", "0"})
+		second := block(t, []string{"BRPOP", "BlpopBlockKey2", "BlpopBlockKey1", "0"})
+		third := block(t, []string{"BLPOP", "BlpopBlockKey1", "0"})
+		runSteps(t, client, []step{
+			{command: []string{"RPUSH", "BlpopBlockKey1", "a", "b"}, want: "2"},
+		})
+		receive(t, first, "[BlpopBlockKey1 a]")
+		receive(t, second, "[BlpopBlockKey1 b]")
+		runSteps(t, client, []step{
+			{command: []string{"LPUSH", "BlpopBlockKey1", "c"}, want: "1"},
+		})
+		receive(t, third, "[BlpopBlockKey1 c]")
+
+		// LMOVE wakes the clients blocked on the destination.
+		runSteps(t, client, []step{
+			{command: []string{"RPUSH", "BlpopBlockKey3", "d"}, want: "1"},
+			{command: []string{"RPUSH", "BlpopBlockKey4", "e"}, want: "1"},
+			{command: []string{"RPOP", "BlpopBlockKey4"}, want: "e"},
+		})
+		fourth := block(t, []string{"BLPOP", "BlpopBlockKey4", "0"})
+		runSteps(t, client, []step{
+			{command: []string{"LMOVE", "BlpopBlockKey3", "BlpopBlockKey4", "LEFT", "LEFT"}, want: "OK"},
+		})
+		receive(t, fourth, "[BlpopBlockKey4 d]")
+
+		// The timeout expires when nothing is pushed.
+		fifth := block(t, []string{"BLPOP", "BlpopBlockKey5", "0.2"})
+		receive(t, fifth, "nil")
+	})
+
+	t.Run("Test_HandleBLMOVE", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"RPUSH", "BlmoveKey1", "a", "b", "c"}, want: "3"},
+			{command: []string{"BLMOVE", "BlmoveKey1", "BlmoveKey2", "LEFT", "RIGHT", "0"}, want: "a"},
+			{command: []string{"BLMOVE", "BlmoveKey1", "BlmoveKey2", "RIGHT", "LEFT", "0"}, want: "c"},
+			{command: []string{"LRANGE", "BlmoveKey2", "0", "-1"}, want: "[c a]"},
+			// The source and the destination can be the same list.
+			{command: []string{"RPUSH", "BlmoveKey1", "d"}, want: "2"},
+			{command: []string{"BLMOVE", "BlmoveKey1", "BlmoveKey1", "LEFT", "RIGHT", "0"}, want: "b"},
+			{command: []string{"LRANGE", "BlmoveKey1", "0", "-1"}, want: "[d b]"},
+			{command: []string{"BLMOVE", "BlmoveKey3", "BlmoveKey2", "LEFT", "RIGHT", "0.05"}, want: "nil"},
+			{command: []string{"SET", "BlmoveKey4", "value"}, want: "OK"},
+			{command: []string{"BLMOVE", "BlmoveKey1", "BlmoveKey4", "LEFT", "RIGHT", "0"}, wantErr: "both source and destination must be lists"},
+			{command: []string{"LRANGE", "BlmoveKey1", "0", "-1"}, want: "[d b]"},
+			{command: []string{"BLMOVE", "BlmoveKey1", "BlmoveKey2", "UP", "RIGHT", "0"}, wantErr: "wherefrom and whereto arguments must be either LEFT or RIGHT"},
+			{command: []string{"BLMOVE", "BlmoveKey1", "BlmoveKey2", "LEFT", "RIGHT"}, wantErr: constants.WrongArgsResponse},
+		})
+
+		done := block(t, []string{"BLMOVE", "BlmoveKey5", "BlmoveKey6", "RIGHT", "LEFT", "0"})
+		runSteps(t, client, []step{
+			{command: []string{"RPUSH", "BlmoveKey5", "e", "f"}, want: "2"},
+		})
+		receive(t, done, "f")
+		runSteps(t, client, []step{
+			{command: []string{"LRANGE", "BlmoveKey6", "0", "-1"}, want: "[f]"},
+		})
+	})
+
+	t.Run("Test_HandleBLMPOP", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"RPUSH", "BlmpopKey2", "a", "b", "c", "d"}, want: "4"},
+			{command: []string{"BLMPOP", "0", "2", "BlmpopKey1", "BlmpopKey2", "LEFT"}, want: "[BlmpopKey2 [a]]"},
+			{command: []string{"BLMPOP", "0", "2", "BlmpopKey1", "BlmpopKey2", "RIGHT", "COUNT", "2"}, want: "[BlmpopKey2 [d c]]"},
+			{command: []string{"BLMPOP", "0", "1", "BlmpopKey2", "left", "count", "10"}, want: "[BlmpopKey2 [b]]"},
+			{command: []string{"BLMPOP", "0.05", "1", "BlmpopKey2", "LEFT"}, want: "nil"},
+			{command: []string{"BLMPOP", "0", "0", "BlmpopKey2", "LEFT"}, wantErr: "numkeys should be greater than 0"},
+			{command: []string{"BLMPOP", "0", "x", "BlmpopKey2", "LEFT"}, wantErr: "numkeys must be an integer"},
+			{command: []string{"BLMPOP", "0", "2", "BlmpopKey2", "LEFT"}, wantErr: "syntax error"},
+			{command: []string{"BLMPOP", "0", "1", "BlmpopKey2", "UP"}, wantErr: "syntax error"},
+			{command: []string{"BLMPOP", "0", "1", "BlmpopKey2", "LEFT", "COUNT", "0"}, wantErr: "count should be greater than 0"},
+			{command: []string{"BLMPOP", "0", "1", "BlmpopKey2", "LEFT", "COUNT"}, wantErr: "syntax error"},
+			{command: []string{"BLMPOP", "-1", "1", "BlmpopKey2", "LEFT"}, wantErr: "timeout is negative"},
+			{command: []string{"BLMPOP", "0", "1", "BlmpopKey2"}, wantErr: constants.WrongArgsResponse},
+		})
+
+		done := block(t, []string{"BLMPOP", "0", "2", "BlmpopKey3", "BlmpopKey4", "RIGHT", "COUNT", "2"})
+		runSteps(t, client, []step{
+			{command: []string{"RPUSH", "BlmpopKey4", "e", "f", "g"}, want: "3"},
+		})
+		receive(t, done, "[BlmpopKey4 [g f]]")
+	})
+
+	t.Run("Test_HandleLINSERT", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"RPUSH", "LinsertKey1", "a", "b", "c", "b"}, want: "4"},
+			{command: []string{"LINSERT", "LinsertKey1", "BEFORE", "b", "x"}, want: "5"},
+			{command: []string{"LINSERT", "LinsertKey1", "after", "b", "y"}, want: "6"},
+			{command: []string{"LINSERT", "LinsertKey1", "AFTER", "c", "z"}, want: "7"},
+			{command: []string{"LRANGE", "LinsertKey1", "0", "-1"}, want: "[a x b y c z b]"},
+			{command: []string{"LINSERT", "LinsertKey1", "BEFORE", "missing", "w"}, want: "-1"},
+			{command: []string{"LINSERT", "LinsertKey2", "BEFORE", "a", "w"}, want: "0"},
+			{command: []string{"LLEN", "LinsertKey2"}, want: "0"},
+			{command: []string{"LINSERT", "LinsertKey1", "BETWEEN", "a", "w"}, wantErr: "syntax error"},
+			{command: []string{"SET", "LinsertKey3", "value"}, want: "OK"},
+			{command: []string{"LINSERT", "LinsertKey3", "BEFORE", "a", "w"}, wantErr: "LINSERT command on non-list item"},
+			{command: []string{"LINSERT", "LinsertKey1", "BEFORE", "a"}, wantErr: constants.WrongArgsResponse},
+		})
+	})
+
+	t.Run("Test_HandleLPOS", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"RPUSH", "LposKey1", "a", "b", "c", "1", "2", "3", "c", "c"}, want: "8"},
+			{command: []string{"LPOS", "LposKey1", "c"}, want: "2"},
+			{command: []string{"LPOS", "LposKey1", "x"}, want: "nil"},
+			{command: []string{"LPOS", "LposKey1", "c", "RANK", "2"}, want: "6"},
+			{command: []string{"LPOS", "LposKey1", "c", "RANK", "-1"}, want: "7"},
+			{command: []string{"LPOS", "LposKey1", "c", "RANK", "4"}, want: "nil"},
+			{command: []string{"LPOS", "LposKey1", "c", "COUNT", "2"}, want: "[2 6]"},
+			{command: []string{"LPOS", "LposKey1", "c", "COUNT", "0"}, want: "[2 6 7]"},
+			{command: []string{"LPOS", "LposKey1", "c", "RANK", "-1", "COUNT", "0"}, want: "[7 6 2]"},
+			{command: []string{"LPOS", "LposKey1", "c", "COUNT", "0", "MAXLEN", "7"}, want: "[2 6]"},
+			{command: []string{"LPOS", "LposKey1", "c", "RANK", "-1", "MAXLEN", "1"}, want: "7"},
+			{command: []string{"LPOS", "LposKey1", "x", "COUNT", "0"}, want: "[]"},
+			{command: []string{"LPOS", "LposKey2", "a"}, want: "nil"},
+			{command: []string{"LPOS", "LposKey2", "a", "COUNT", "1"}, want: "[]"},
+			{command: []string{"LPOS", "LposKey1", "c", "RANK", "0"}, wantErr: "RANK can't be zero"},
+			{command: []string{"LPOS", "LposKey1", "c", "COUNT", "-1"}, wantErr: "COUNT can't be negative"},
+			{command: []string{"LPOS", "LposKey1", "c", "MAXLEN", "-1"}, wantErr: "MAXLEN can't be negative"},
+			{command: []string{"LPOS", "LposKey1", "c", "COUNT", "x"}, wantErr: "value is not an integer or out of range"},
+			{command: []string{"LPOS", "LposKey1", "c", "COUNT"}, wantErr: "syntax error"},
+			{command: []string{"LPOS", "LposKey1", "c", "LIMIT", "1"}, wantErr: "syntax error"},
+			{command: []string{"SET", "LposKey3", "value"}, want: "OK"},
+			{command: []string{"LPOS", "LposKey3", "a"}, wantErr: "LPOS command on non-list item"},
+			{command: []string{"LPOS", "LposKey1"}, wantErr: constants.WrongArgsResponse},
+		})
+	})
+
+	t.Run("Test_HandleLMPOP", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"RPUSH", "LmpopKey2", "a", "b", "c", "d"}, want: "4"},
+			{command: []string{"LMPOP", "2", "LmpopKey1", "LmpopKey2", "LEFT"}, want: "[LmpopKey2 [a]]"},
+			{command: []string{"LMPOP", "2", "LmpopKey1", "LmpopKey2", "RIGHT", "COUNT", "2"}, want: "[LmpopKey2 [d c]]"},
+			{command: []string{"LMPOP", "1", "LmpopKey2", "left", "count", "10"}, want: "[LmpopKey2 [b]]"},
+			{command: []string{"LMPOP", "1", "LmpopKey2", "LEFT"}, want: "nil"},
+			{command: []string{"SET", "LmpopKey3", "value"}, want: "OK"},
+			{command: []string{"LMPOP", "2", "LmpopKey1", "LmpopKey3", "LEFT"}, wantErr: "LMPOP command on non-list item"},
+			{command: []string{"LMPOP", "0", "LmpopKey2", "LEFT"}, wantErr: "numkeys should be greater than 0"},
+			{command: []string{"LMPOP", "2", "LmpopKey2", "LEFT"}, wantErr: "syntax error"},
+			{command: []string{"LMPOP", "1", "LmpopKey2", "LEFT", "COUNT", "0"}, wantErr: "count should be greater than 0"},
+			{command: []string{"LMPOP", "1", "LmpopKey2"}, wantErr: constants.WrongArgsResponse},
+		})
+	})
+
+	t.Run("Test_HandleRPOPLPUSH", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"RPUSH", "RpoplpushKey1", "a", "b", "c"}, want: "3"},
+			{command: []string{"RPOPLPUSH", "RpoplpushKey1", "RpoplpushKey2"}, want: "c"},
+			{command: []string{"RPOPLPUSH", "RpoplpushKey1", "RpoplpushKey2"}, want: "b"},
+			{command: []string{"LRANGE", "RpoplpushKey1", "0", "-1"}, want: "[a]"},
+			{command: []string{"LRANGE", "RpoplpushKey2", "0", "-1"}, want: "[b c]"},
+			// The source and the destination can be the same list, which rotates it.
+			{command: []string{"RPOPLPUSH", "RpoplpushKey2", "RpoplpushKey2"}, want: "c"},
+			{command: []string{"LRANGE", "RpoplpushKey2", "0", "-1"}, want: "[c b]"},
+			{command: []string{"RPOPLPUSH", "RpoplpushKey3", "RpoplpushKey2"}, want: "nil"},
+			{command: []string{"SET", "RpoplpushKey4", "value"}, want: "OK"},
+			{command: []string{"RPOPLPUSH", "RpoplpushKey1", "RpoplpushKey4"}, wantErr: "both source and destination must be lists"},
+			{command: []string{"RPOPLPUSH", "RpoplpushKey4", "RpoplpushKey1"}, wantErr: "both source and destination must be lists"},
+			{command: []string{"LRANGE", "RpoplpushKey1", "0", "-1"}, want: "[a]"},
+			{command: []string{"RPOPLPUSH", "RpoplpushKey1"}, wantErr: constants.WrongArgsResponse},
+		})
+	})
 }
@@ -0,0 +1,175 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scripting
+
+import (
+	"errors"
+	"fmt"
+	"github.com/echovault/sugardb/internal"
+	"github.com/echovault/sugardb/internal/constants"
+	"strings"
+)
+
+func handleEval(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, args, err := splitKeysAndArgs(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	script := params.Command[1]
+	params.LoadScript(script)
+	return params.EvalScript(params.Context, params.Connection, script, keys, args)
+}
+
+func handleEvalSha(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, args, err := splitKeysAndArgs(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	script, ok := params.GetScript(params.Command[1])
+	if !ok {
+		return nil, errors.New("NOSCRIPT No matching script. Please use EVAL")
+	}
+	res, err := params.EvalScript(params.Context, params.Connection, script, keys, args)
+	if err != nil {
+		return nil, err
+	}
+	// The script is written to the AOF with its source, as the script cache is not restored with the AOF.
+	params.Propagate(append([]string{"EVAL", script}, params.Command[2:]...))
+	return res, nil
+}
+
+func handleScriptLoad(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 3 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	sha := params.LoadScript(params.Command[2])
+	return []byte(fmt.Sprintf("$%d\r\n%s\r\n", len(sha), sha)), nil
+}
+
+func handleScriptExists(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) < 3 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	res := fmt.Sprintf("*%d\r\n", len(params.Command[2:]))
+	for _, sha := range params.Command[2:] {
+		if _, ok := params.GetScript(sha); ok {
+			res += ":1\r\n"
+			continue
+		}
+		res += ":0\r\n"
+	}
+	return []byte(res), nil
+}
+
+func handleScriptKill(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) != 2 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	if err := params.KillScript(); err != nil {
+		return nil, err
+	}
+	return []byte(constants.OkResponse), nil
+}
+
+func handleScriptFlush(params internal.HandlerFuncParams) ([]byte, error) {
+	if len(params.Command) < 2 || len(params.Command) > 3 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	// ASYNC and SYNC are accepted for compatibility. The cache is always flushed synchronously.
+	if len(params.Command) == 3 && !strings.EqualFold(params.Command[2], "async") &&
+		!strings.EqualFold(params.Command[2], "sync") {
+		return nil, fmt.Errorf("unsupported flush mode %s", params.Command[2])
+	}
+	params.FlushScripts()
+	return []byte(constants.OkResponse), nil
+}
+
+func Commands() []internal.Command {
+	return []internal.Command{
+		{
+			Command:    "eval",
+			Module:     constants.ScriptingModule,
+			Categories: []string{constants.ScriptingCategory, constants.SlowCategory, constants.WriteCategory},
+			Description: `(EVAL script numkeys [key [key ...]] [arg [arg ...]]) Atomically run a Lua script. 
+The keys and arguments are available to the script in the KEYS and ARGV tables. The script can call any command 
+with redis.call and redis.pcall. The script is added to the script cache, so it can be run with EVALSHA.`,
+			Sync:              true,
+			KeyExtractionFunc: evalKeyFunc,
+			HandlerFunc:       handleEval,
+		},
+		{
+			Command:    "evalsha",
+			Module:     constants.ScriptingModule,
+			Categories: []string{constants.ScriptingCategory, constants.SlowCategory, constants.WriteCategory},
+			Description: `(EVALSHA sha1 numkeys [key [key ...]] [arg [arg ...]]) Atomically run the cached Lua script 
+with the SHA1 digest. The script must have been loaded with EVAL or SCRIPT LOAD.`,
+			Sync:              true,
+			KeyExtractionFunc: evalKeyFunc,
+			HandlerFunc:       handleEvalSha,
+		},
+		{
+			Command:     "script",
+			Module:      constants.ScriptingModule,
+			Categories:  []string{},
+			Description: "Script cache commands",
+			KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+				return internal.KeyExtractionFuncResult{
+					Channels: make([]string, 0), ReadKeys: make([]string, 0), WriteKeys: make([]string, 0),
+				}, nil
+			},
+			SubCommands: []internal.SubCommand{
+				{
+					Command:    "load",
+					Module:     constants.ScriptingModule,
+					Categories: []string{constants.ScriptingCategory, constants.SlowCategory, constants.WriteCategory},
+					Description: `(SCRIPT LOAD script) Add the Lua script to the script cache without running it. 
+Returns the SHA1 digest of the script.`,
+					Sync:              true,
+					KeyExtractionFunc: scriptLoadKeyFunc,
+					HandlerFunc:       handleScriptLoad,
+				},
+				{
+					Command:    "exists",
+					Module:     constants.ScriptingModule,
+					Categories: []string{constants.ScriptingCategory, constants.SlowCategory},
+					Description: `(SCRIPT EXISTS sha1 [sha1 ...]) Check whether the scripts with the SHA1 digests are 
+in the script cache. Returns an array with 1 for each cached script and 0 otherwise.`,
+					Sync:              false,
+					KeyExtractionFunc: scriptExistsKeyFunc,
+					HandlerFunc:       handleScriptExists,
+				},
+				{
+					Command:           "flush",
+					Module:            constants.ScriptingModule,
+					Categories:        []string{constants.ScriptingCategory, constants.SlowCategory, constants.WriteCategory},
+					Description:       `(SCRIPT FLUSH [ASYNC | SYNC]) Remove all the scripts from the script cache.`,
+					Sync:              true,
+					KeyExtractionFunc: scriptFlushKeyFunc,
+					HandlerFunc:       handleScriptFlush,
+				},
+				{
+					Command:    "kill",
+					Module:     constants.ScriptingModule,
+					Categories: []string{constants.ScriptingCategory, constants.SlowCategory},
+					Description: `(SCRIPT KILL) Stop the Lua script that is running. The script can only be stopped if it 
+has not called a write command yet.`,
+					Sync:              false,
+					KeyExtractionFunc: scriptKillKeyFunc,
+					HandlerFunc:       handleScriptKill,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,339 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scripting_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/echovault/sugardb/internal"
+	"github.com/echovault/sugardb/internal/config"
+	"github.com/echovault/sugardb/internal/constants"
+	"github.com/echovault/sugardb/sugardb"
+	"github.com/tidwall/resp"
+)
+
+func Test_Scripting(t *testing.T) {
+	port, err := internal.GetFreePort()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	mockServer, err := sugardb.NewSugarDB(
+		sugardb.WithConfig(config.Config{
+			BindAddr:       "localhost",
+			Port:           uint16(port),
+			DataDir:        "",
+			EvictionPolicy: constants.NoEviction,
+		}),
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	go func() {
+		mockServer.Start()
+	}()
+
+	t.Cleanup(func() {
+		mockServer.ShutDown()
+	})
+
+	type step struct {
+		command []string
+		want    string // The expected response, as returned by resp.Value.String().
+		wantErr string // The expected error substring when the response is an error.
+	}
+
+	runSteps := func(t *testing.T, client *resp.Conn, steps []step) {
+		for _, step := range steps {
+			command := make([]resp.Value, len(step.command))
+			for i, token := range step.command {
+				command[i] = resp.StringValue(token)
+			}
+			if err := client.WriteArray(command); err != nil {
+				t.Error(err)
+				return
+			}
+			res, _, err := client.ReadValue()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if step.wantErr != "" {
+				if res.Error() == nil || !strings.Contains(res.Error().Error(), step.wantErr) {
+					t.Errorf("%v: expected error \"%s\", got %+v", step.command, step.wantErr, res)
+				}
+				continue
+			}
+			if res.Error() != nil {
+				t.Errorf("%v: unexpected error %v", step.command, res.Error())
+				continue
+			}
+			got := res.String()
+			if res.Type() == resp.Array {
+				elements := make([]string, len(res.Array()))
+				for i, element := range res.Array() {
+					elements[i] = element.String()
+				}
+				got = strings.Join(elements, ",")
+			}
+			if got != step.want {
+				t.Errorf("%v: expected response \"%s\", got \"%s\"", step.command, step.want, got)
+			}
+		}
+	}
+
+	t.Run("Test_HandleEval", func(t *testing.T) {
+		t.Parallel()
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		client := resp.NewConn(conn)
+
+		tests := []struct {
+			name  string
+			steps []step
+		}{
+			{
+				name: "1. Convert the values returned by the script",
+				steps: []step{
+					{command: []string{"EVAL", "return 10.7", "0"}, want: "10"},
+					{command: []string{"EVAL", "return 'value'", "0"}, want: "value"},
+					{command: []string{"EVAL", "return {1, 'two', {3}, nil, 5}", "0"}, want: "1,two,[3]"},
+					{command: []string{"EVAL", "return true", "0"}, want: "1"},
+					{command: []string{"EVAL", "return false", "0"}, want: ""},
+					{command: []string{"EVAL", "return redis.status_reply('DONE')", "0"}, want: "DONE"},
+					{command: []string{"EVAL", "return redis.error_reply('custom error')", "0"}, wantErr: "custom error"},
+				},
+			},
+			{
+				name: "2. Pass the keys and arguments to the script",
+				steps: []step{
+					{command: []string{"EVAL", "return {KEYS[1], KEYS[2], ARGV[1]}", "2", "EvalKey1", "EvalKey2", "arg1"}, want: "EvalKey1,EvalKey2,arg1"},
+					{command: []string{"EVAL", "return #ARGV", "0", "arg1", "arg2"}, want: "2"},
+				},
+			},
+			{
+				name: "3. Call commands with redis.call",
+				steps: []step{
+					{command: []string{"EVAL", "redis.call('SET', KEYS[1], ARGV[1]); return redis.call('GET', KEYS[1])", "1", "EvalKey3", "value3"}, want: "value3"},
+					{command: []string{"GET", "EvalKey3"}, want: "value3"},
+					{command: []string{"EVAL", "return redis.call('INCRBY', KEYS[1], 5)", "1", "EvalKey4"}, want: "5"},
+					{command: []string{"EVAL", "return redis.call('SET', KEYS[1], 'value') == 'OK'", "1", "EvalKey5"}, want: "1"},
+					{command: []string{"EVAL", "return redis.call('GET', KEYS[1]) == false", "1", "EvalKey6"}, want: "1"},
+				},
+			},
+			{
+				name: "4. Rate limiter script",
+				steps: []step{
+					{command: []string{"EVAL", rateLimiterScript, "1", "EvalKey7", "2", "60"}, want: "1"},
+					{command: []string{"EVAL", rateLimiterScript, "1", "EvalKey7", "2", "60"}, want: "1"},
+					{command: []string{"EVAL", rateLimiterScript, "1", "EvalKey7", "2", "60"}, want: "0"},
+				},
+			},
+			{
+				name: "5. redis.call raises an error and redis.pcall returns it",
+				steps: []step{
+					{command: []string{"SET", "EvalKey8", "value"}, want: "OK"},
+					{command: []string{"EVAL", "redis.call('INCR', KEYS[1]); redis.call('SET', KEYS[1], 'changed')", "1", "EvalKey8"}, wantErr: "value is not an integer or out of range"},
+					{command: []string{"GET", "EvalKey8"}, want: "value"},
+					{command: []string{"EVAL", "local res = redis.pcall('INCR', KEYS[1]); return res['err']", "1", "EvalKey8"}, want: "value is not an integer or out of range"},
+					{command: []string{"EVAL", "return redis.pcall('UNKNOWN')", "0"}, wantErr: "command UNKNOWN not supported"},
+				},
+			},
+			{
+				name: "6. Return error when a command is not allowed from script",
+				steps: []step{
+					{command: []string{"EVAL", "return redis.call('MULTI')", "0"}, wantErr: "command MULTI is not allowed from script"},
+					{command: []string{"EVAL", "return redis.call('EVAL', 'return 1', '0')", "0"}, wantErr: "command EVAL is not allowed from script"},
+					{command: []string{"EVAL", "return redis.call('SCRIPT', 'FLUSH')", "0"}, wantErr: "command SCRIPT is not allowed from script"},
+				},
+			},
+			{
+				name: "7. Return error when the script can't be compiled or accesses the host",
+				steps: []step{
+					{command: []string{"EVAL", "return (", "0"}, wantErr: "error compiling script"},
+					{command: []string{"EVAL", "return os.time()", "0"}, wantErr: "error running script"},
+					{command: []string{"EVAL", "return dofile('/etc/passwd')", "0"}, wantErr: "error running script"},
+				},
+			},
+			{
+				name: "8. Return error when numkeys is invalid",
+				steps: []step{
+					{command: []string{"EVAL", "return 1"}, wantErr: constants.WrongArgsResponse},
+					{command: []string{"EVAL", "return 1", "one"}, wantErr: "numkeys must be an integer"},
+					{command: []string{"EVAL", "return 1", "-1"}, wantErr: "numkeys can't be negative"},
+					{command: []string{"EVAL", "return 1", "2", "EvalKey9"}, wantErr: "numkeys can't be greater than the number of arguments"},
+				},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				runSteps(t, client, test.steps)
+			})
+		}
+	})
+
+	t.Run("Test_HandleEvalSha", func(t *testing.T) {
+		t.Parallel()
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		client := resp.NewConn(conn)
+
+		script := "return redis.call('INCRBY', KEYS[1], ARGV[1])"
+		sha := strings.Repeat("0", 40) // A digest that is not in the script cache.
+
+		runSteps(t, client, []step{
+			{command: []string{"SCRIPT", "EXISTS", sha}, want: "0"},
+			{command: []string{"EVALSHA", sha, "1", "EvalShaKey1", "2"}, wantErr: "NOSCRIPT No matching script"},
+		})
+
+		// Load the script and run it with its digest.
+		if err = client.WriteArray([]resp.Value{
+			resp.StringValue("SCRIPT"), resp.StringValue("LOAD"), resp.StringValue(script),
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+		res, _, err := client.ReadValue()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		sha = res.String()
+		if len(sha) != 40 {
+			t.Errorf("expected SHA1 digest, got \"%s\"", sha)
+			return
+		}
+
+		runSteps(t, client, []step{
+			{command: []string{"EVALSHA", sha, "1", "EvalShaKey1", "2"}, want: "2"},
+			{command: []string{"EVALSHA", strings.ToUpper(sha), "1", "EvalShaKey1", "3"}, want: "5"},
+			{command: []string{"EVAL", "return redis.sha1hex(ARGV[1])", "0", script}, want: sha},
+		})
+	})
+
+	t.Run("Test_HandleScriptExistsAndFlush", func(t *testing.T) {
+		// Not parallel, so SCRIPT FLUSH runs before the parallel tests load their scripts.
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		client := resp.NewConn(conn)
+
+		runSteps(t, client, []step{
+			// EVAL adds the script to the script cache.
+			{command: []string{"EVAL", "return 'exists'", "0"}, want: "exists"},
+			{command: []string{"SCRIPT", "EXISTS", "0fdb70b8794c5d14a6f1280b8227b9380aea0a05", "unknown"}, want: "1,0"},
+			{command: []string{"SCRIPT", "LOAD", "return 1"}, want: "e0e1f9fabfc9d4800c877a703b823ac0578ff8db"},
+			{command: []string{"SCRIPT", "EXISTS", "e0e1f9fabfc9d4800c877a703b823ac0578ff8db", "unknown"}, want: "1,0"},
+			{command: []string{"SCRIPT", "FLUSH", "INVALID"}, wantErr: "unsupported flush mode INVALID"},
+			{command: []string{"SCRIPT", "FLUSH", "ASYNC"}, want: "OK"},
+			{command: []string{"SCRIPT", "EXISTS", "0fdb70b8794c5d14a6f1280b8227b9380aea0a05", "e0e1f9fabfc9d4800c877a703b823ac0578ff8db"}, want: "0,0"},
+			{command: []string{"SCRIPT", "LOAD"}, wantErr: constants.WrongArgsResponse},
+			{command: []string{"SCRIPT", "EXISTS"}, wantErr: constants.WrongArgsResponse},
+		})
+	})
+
+	t.Run("Test_ScriptACL", func(t *testing.T) {
+		t.Parallel()
+
+		port, err := internal.GetFreePort()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		mockServer, err := sugardb.NewSugarDB(
+			sugardb.WithConfig(config.Config{
+				BindAddr:       "localhost",
+				Port:           uint16(port),
+				DataDir:        "",
+				EvictionPolicy: constants.NoEviction,
+				RequirePass:    true,
+				Password:       "password1",
+			}),
+		)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if _, err = mockServer.ACLSetUser(sugardb.User{
+			Username:             "script_user",
+			Enabled:              true,
+			AddPlainPasswords:    []string{"password2"},
+			IncludeCategories:    []string{"*"},
+			IncludeCommands:      []string{"*"},
+			ExcludeCommands:      []string{"del"},
+			IncludeReadWriteKeys: []string{"*"},
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+		go func() {
+			mockServer.Start()
+		}()
+		t.Cleanup(func() {
+			mockServer.ShutDown()
+		})
+
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		client := resp.NewConn(conn)
+
+		runSteps(t, client, []step{
+			{command: []string{"AUTH", "script_user", "password2"}, want: "OK"},
+			{command: []string{"EVAL", "return redis.call('SET', KEYS[1], 'value')", "1", "ScriptACLKey1"}, want: "OK"},
+			{command: []string{"EVAL", "return redis.call('DEL', KEYS[1])", "1", "ScriptACLKey1"}, wantErr: "not authorised to run DEL command"},
+			{command: []string{"GET", "ScriptACLKey1"}, want: "value"},
+		})
+	})
+}
+
+// rateLimiterScript allows ARGV[1] calls per window of ARGV[2] seconds. Returns 1 if the call is allowed.
+const rateLimiterScript = `
+local current = redis.call('INCR', KEYS[1])
+if current == 1 then
+	redis.call('EXPIRE', KEYS[1], ARGV[2])
+end
+if current > tonumber(ARGV[1]) then
+	return 0
+end
+return 1
+`
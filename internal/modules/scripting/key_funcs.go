@@ -0,0 +1,96 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scripting
+
+import (
+	"errors"
+	"github.com/echovault/sugardb/internal"
+	"github.com/echovault/sugardb/internal/constants"
+	"strconv"
+)
+
+// splitKeysAndArgs splits the arguments after the script or SHA1 digest of EVAL and EVALSHA into keys and arguments.
+func splitKeysAndArgs(cmd []string) ([]string, []string, error) {
+	if len(cmd) < 3 {
+		return nil, nil, errors.New(constants.WrongArgsResponse)
+	}
+	numkeys, err := strconv.Atoi(cmd[2])
+	if err != nil {
+		return nil, nil, errors.New("numkeys must be an integer")
+	}
+	if numkeys < 0 {
+		return nil, nil, errors.New("numkeys can't be negative")
+	}
+	if numkeys > len(cmd)-3 {
+		return nil, nil, errors.New("numkeys can't be greater than the number of arguments")
+	}
+	return cmd[3 : 3+numkeys], cmd[3+numkeys:], nil
+}
+
+func evalKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	keys, _, err := splitKeysAndArgs(cmd)
+	if err != nil {
+		return internal.KeyExtractionFuncResult{}, err
+	}
+	return internal.KeyExtractionFuncResult{
+		Channels:  make([]string, 0),
+		ReadKeys:  keys,
+		WriteKeys: keys,
+	}, nil
+}
+
+func scriptLoadKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	if len(cmd) != 3 {
+		return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+	}
+	return internal.KeyExtractionFuncResult{
+		Channels:  make([]string, 0),
+		ReadKeys:  make([]string, 0),
+		WriteKeys: make([]string, 0),
+	}, nil
+}
+
+func scriptExistsKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	if len(cmd) < 3 {
+		return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+	}
+	return internal.KeyExtractionFuncResult{
+		Channels:  make([]string, 0),
+		ReadKeys:  make([]string, 0),
+		WriteKeys: make([]string, 0),
+	}, nil
+}
+
+func scriptFlushKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	if len(cmd) < 2 || len(cmd) > 3 {
+		return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+	}
+	return internal.KeyExtractionFuncResult{
+		Channels:  make([]string, 0),
+		ReadKeys:  make([]string, 0),
+		WriteKeys: make([]string, 0),
+	}, nil
+}
+
+func scriptKillKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	if len(cmd) != 2 {
+		return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+	}
+	return internal.KeyExtractionFuncResult{
+		Channels:  make([]string, 0),
+		ReadKeys:  make([]string, 0),
+		WriteKeys: make([]string, 0),
+	}, nil
+}
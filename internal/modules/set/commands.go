@@ -20,6 +20,7 @@ import (
 	"github.com/echovault/sugardb/internal"
 	"github.com/echovault/sugardb/internal/constants"
 	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -567,6 +568,45 @@ func handleSUNIONSTORE(params internal.HandlerFuncParams) ([]byte, error) {
 	return []byte(fmt.Sprintf(":%d\r\n", union.Cardinality())), nil
 }
 
+func handleSSCAN(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := sscanKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := internal.ParseScanOptions(params.Command[2:], false)
+	if err != nil {
+		return nil, err
+	}
+
+	key := keys.ReadKeys[0]
+	keyExists := params.KeysExist(params.Context, keys.ReadKeys)[key]
+
+	if !keyExists {
+		return []byte("*2\r\n$1\r\n0\r\n*0\r\n"), nil
+	}
+
+	set, ok := params.GetValues(params.Context, []string{key})[key].(*Set)
+	if !ok {
+		return nil, fmt.Errorf("value at key %s is not a set", key)
+	}
+
+	page, cursor := internal.ScanCursor(set.GetAll(), options.Cursor, options.Count)
+	if options.Match != nil {
+		page = slices.DeleteFunc(page, func(member string) bool {
+			return !options.Match.Match(member)
+		})
+	}
+
+	c := strconv.FormatUint(cursor, 10)
+	res := fmt.Sprintf("*2\r\n$%d\r\n%s\r\n*%d\r\n", len(c), c, len(page))
+	for _, member := range page {
+		res += fmt.Sprintf("$%d\r\n%s\r\n", len(member), member)
+	}
+
+	return []byte(res), nil
+}
+
 func Commands() []internal.Command {
 	return []internal.Command{
 		{
@@ -719,5 +759,18 @@ Returns the cardinality of the intersection between multiple sets.`,
 			KeyExtractionFunc: sunionstoreKeyFunc,
 			HandlerFunc:       handleSUNIONSTORE,
 		},
+		{
+			Command:    "sscan",
+			Module:     constants.SetModule,
+			Categories: []string{constants.SetCategory, constants.ReadCategory, constants.SlowCategory},
+			Description: `(SSCAN key cursor [MATCH pattern] [COUNT count])
+Incrementally iterates over the members of a set.
+Returns the cursor for the next call and a page of members. A returned cursor of 0 means the iteration is complete.
+MATCH - Only return members that match the glob-style pattern.
+COUNT - The number of members to examine in this call. Defaults to 10.`,
+			Sync:              false,
+			KeyExtractionFunc: sscanKeyFunc,
+			HandlerFunc:       handleSSCAN,
+		},
 	}
 }
@@ -2472,4 +2472,151 @@ func Test_Set(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("Test_HandleSSCAN", func(t *testing.T) {
+		t.Parallel()
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		client := resp.NewConn(conn)
+
+		// Preset two sets with 100 members each and a string value.
+		var members []string
+		for i := 0; i < 100; i++ {
+			members = append(members, "member"+strconv.Itoa(i))
+		}
+		for _, key := range []string{"SScanKey1", "SScanKey2"} {
+			if _, err = mockServer.SAdd(key, members...); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		if _, _, err = mockServer.Set("SScanKey4", "value", sugardb.SETOptions{}); err != nil {
+			t.Error(err)
+			return
+		}
+
+		tests := []struct {
+			name          string
+			key           string
+			cursor        string
+			options       []string
+			mutate        bool
+			expected      []string
+			expectedError error
+		}{
+			{
+				name:     "1. Iterate over all the members",
+				key:      "SScanKey1",
+				options:  []string{},
+				expected: members,
+			},
+			{
+				name:     "2. Iterate over all the members while the set is mutated",
+				key:      "SScanKey2",
+				options:  []string{"COUNT", "15"},
+				mutate:   true,
+				expected: members,
+			},
+			{
+				name:     "3. Only return members that match the pattern",
+				key:      "SScanKey1",
+				options:  []string{"MATCH", "member9*", "COUNT", "30"},
+				expected: []string{"member9", "member90", "member91", "member92", "member93", "member94", "member95", "member96", "member97", "member98", "member99"},
+			},
+			{
+				name:     "4. Return an empty page when the key does not exist",
+				key:      "SScanKey3",
+				options:  []string{},
+				expected: []string{},
+			},
+			{
+				name:          "5. Return error when the cursor is invalid",
+				key:           "SScanKey1",
+				cursor:        "cursor",
+				options:       []string{},
+				expectedError: errors.New("invalid cursor"),
+			},
+			{
+				name:          "6. Return error when the TYPE option is provided",
+				key:           "SScanKey1",
+				options:       []string{"TYPE", "string"},
+				expectedError: errors.New("syntax error"),
+			},
+			{
+				name:          "7. Return error when the key is not a set",
+				key:           "SScanKey4",
+				options:       []string{},
+				expectedError: errors.New("value at key SScanKey4 is not a set"),
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				var got []string
+				cursor := "0"
+				if test.cursor != "" {
+					cursor = test.cursor
+				}
+				for i := 0; ; i++ {
+					command := []resp.Value{resp.StringValue("SSCAN"), resp.StringValue(test.key), resp.StringValue(cursor)}
+					for _, option := range test.options {
+						command = append(command, resp.StringValue(option))
+					}
+					if err = client.WriteArray(command); err != nil {
+						t.Error(err)
+						return
+					}
+					res, _, err := client.ReadValue()
+					if err != nil {
+						t.Error(err)
+						return
+					}
+
+					if test.expectedError != nil {
+						if !strings.Contains(res.Error().Error(), test.expectedError.Error()) {
+							t.Errorf("expected error \"%s\", got \"%s\"", test.expectedError.Error(), res.Error().Error())
+						}
+						return
+					}
+
+					cursor = res.Array()[0].String()
+					for j, item := range res.Array()[1].Array() {
+						if j%1 == 0 {
+							got = append(got, item.String())
+						}
+					}
+					if cursor == "0" {
+						break
+					}
+
+					if test.mutate {
+						// Add and remove members between calls.
+						if _, err = mockServer.SAdd(test.key, "new"+strconv.Itoa(i)); err != nil {
+							t.Error(err)
+							return
+						}
+						if _, err = mockServer.SRem(test.key, "new"+strconv.Itoa(i-1)); err != nil {
+							t.Error(err)
+							return
+						}
+					}
+				}
+
+				for _, item := range test.expected {
+					if !slices.Contains(got, item) {
+						t.Errorf("expected \"%s\" to be returned by the iteration", item)
+					}
+				}
+				if !test.mutate && len(got) != len(test.expected) {
+					t.Errorf("expected %d items, got %d", len(test.expected), len(got))
+				}
+			})
+		}
+	})
 }
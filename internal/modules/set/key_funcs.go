@@ -210,3 +210,14 @@ func sunionstoreKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error)
 		WriteKeys: cmd[1:2],
 	}, nil
 }
+
+func sscanKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	if len(cmd) < 3 || len(cmd) > 7 {
+		return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+	}
+	return internal.KeyExtractionFuncResult{
+		Channels:  make([]string, 0),
+		ReadKeys:  cmd[1:2],
+		WriteKeys: make([]string, 0),
+	}, nil
+}
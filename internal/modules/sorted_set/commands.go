@@ -24,6 +24,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 func handleZADD(params internal.HandlerFuncParams) ([]byte, error) {
@@ -146,10 +147,15 @@ func handleZADD(params internal.HandlerFuncParams) ([]byte, error) {
 		if !ok {
 			return nil, fmt.Errorf("value at %s is not a sorted set", key)
 		}
+		// Update a copy of the sorted set, as clients blocked on the key may be reading it.
+		set = NewSortedSet(set.GetAll())
 		count, err := set.AddOrUpdate(members, updatePolicy, comparison, changed, incr)
 		if err != nil {
 			return nil, err
 		}
+		if err = params.SetValues(params.Context, map[string]interface{}{key: set}); err != nil {
+			return nil, err
+		}
 		// If INCR option is provided, return the new score value
 		if incr != nil {
 			m := set.Get(members[0].Value)
@@ -442,6 +448,8 @@ func handleZINCRBY(params internal.HandlerFuncParams) ([]byte, error) {
 	if !ok {
 		return nil, fmt.Errorf("value at %s is not a sorted set", key)
 	}
+	// Update a copy of the sorted set, as clients blocked on the key may be reading it.
+	set = NewSortedSet(set.GetAll())
 	if _, err = set.AddOrUpdate(
 		[]MemberParam{
 			{Value: member, Score: increment}},
@@ -451,6 +459,9 @@ func handleZINCRBY(params internal.HandlerFuncParams) ([]byte, error) {
 		"incr"); err != nil {
 		return nil, err
 	}
+	if err = params.SetValues(params.Context, map[string]interface{}{key: set}); err != nil {
+		return nil, err
+	}
 	return []byte(fmt.Sprintf("+%s\r\n",
 		strconv.FormatFloat(float64(set.Get(member).Score), 'f', -1, 64))), nil
 }
@@ -674,6 +685,138 @@ func handleZPOP(params internal.HandlerFuncParams) ([]byte, error) {
 	return []byte(res), nil
 }
 
+// blockingPop pops count members from the first of the keys that holds a non-empty sorted set. When all the sorted
+// sets are empty, it waits until members are added to one of the keys or the timeout expires, in which case it
+// returns a null array. Clients blocked on the same key are served in the order they blocked.
+func blockingPop(
+	params internal.HandlerFuncParams,
+	keys []string,
+	timeout time.Duration,
+	pop func(key string, set *SortedSet) ([]byte, error),
+) ([]byte, error) {
+	// Register before checking the sorted sets so that a write between the check and the wait is not missed.
+	ready, cancel := params.BlockOnKeys(params.Context, keys)
+	defer cancel()
+
+	// On the raft leader, the command is applied through the raft log once one of the sorted sets has members.
+	apply, _ := params.Context.Value(internal.ContextRaftApply("RaftApply")).(func() ([]byte, error))
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		expired = params.GetClock().After(timeout)
+	}
+
+	for {
+		exists := params.KeysExist(params.Context, keys)
+		values := params.GetValues(params.Context, keys)
+		for _, key := range keys {
+			if !exists[key] {
+				continue
+			}
+			set, ok := values[key].(*SortedSet)
+			if !ok {
+				return nil, fmt.Errorf("value at key %s is not a sorted set", key)
+			}
+			if set.Cardinality() == 0 || (ready != nil && !params.IsFirstBlocked(params.Context, key, ready)) {
+				continue
+			}
+			if apply == nil {
+				return pop(key, set)
+			}
+			res, err := apply()
+			if res != nil || err != nil {
+				return res, err
+			}
+			break
+		}
+
+		if ready == nil {
+			// The command can't block inside a transaction or a script, or when it's replayed.
+			return []byte("*-1\r\n"), nil
+		}
+		select {
+		case <-ready:
+		case <-expired:
+			return []byte("*-1\r\n"), nil
+		case <-params.Context.Done():
+			return []byte("*-1\r\n"), nil
+		}
+	}
+}
+
+func handleBZPOP(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := bzpopKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout, err := parseTimeout(params.Command[len(params.Command)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	policy := "min"
+	if strings.EqualFold(params.Command[0], "bzpopmax") {
+		policy = "max"
+	}
+
+	return blockingPop(params, keys.WriteKeys, timeout, func(key string, set *SortedSet) ([]byte, error) {
+		// Pop from a copy of the sorted set, as other clients may be reading it.
+		set = NewSortedSet(set.GetAll())
+		popped, err := set.Pop(1, policy)
+		if err != nil {
+			return nil, err
+		}
+		if err = params.SetValues(params.Context, map[string]interface{}{key: set}); err != nil {
+			return nil, err
+		}
+		m := popped.GetAll()[0]
+		return []byte(fmt.Sprintf("*3\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n+%s\r\n",
+			len(key), key, len(m.Value), m.Value, strconv.FormatFloat(float64(m.Score), 'f', -1, 64))), nil
+	})
+}
+
+func handleBZMPOP(params internal.HandlerFuncParams) ([]byte, error) {
+	if _, err := bzmpopKeyFunc(params.Command); err != nil {
+		return nil, err
+	}
+
+	timeout, err := parseTimeout(params.Command[1])
+	if err != nil {
+		return nil, err
+	}
+	keys, policy, count, err := parseBZMPopArgs(params.Command[2:])
+	if err != nil {
+		return nil, err
+	}
+
+	return blockingPop(params, keys, timeout, func(key string, set *SortedSet) ([]byte, error) {
+		// Pop from a copy of the sorted set, as other clients may be reading it.
+		set = NewSortedSet(set.GetAll())
+		popped, err := set.Pop(count, policy)
+		if err != nil {
+			return nil, err
+		}
+		if err = params.SetValues(params.Context, map[string]interface{}{key: set}); err != nil {
+			return nil, err
+		}
+		// Return the members in the order they were popped.
+		members := popped.GetAll()
+		slices.SortFunc(members, func(a, b MemberParam) int {
+			if policy == "min" {
+				return cmp.Compare(a.Score, b.Score)
+			}
+			return cmp.Compare(b.Score, a.Score)
+		})
+		res := fmt.Sprintf("*2\r\n$%d\r\n%s\r\n*%d", len(key), key, len(members))
+		for _, m := range members {
+			res += fmt.Sprintf("\r\n*2\r\n$%d\r\n%s\r\n+%s", len(m.Value), m.Value, strconv.FormatFloat(float64(m.Score), 'f', -1, 64))
+		}
+		res += "\r\n"
+		return []byte(res), nil
+	})
+}
+
 func handleZMSCORE(params internal.HandlerFuncParams) ([]byte, error) {
 	keys, err := zmscoreKeyFunc(params.Command)
 	if err != nil {
@@ -1366,8 +1509,94 @@ func handleZUNIONSTORE(params internal.HandlerFuncParams) ([]byte, error) {
 	return []byte(fmt.Sprintf(":%d\r\n", union.Cardinality())), nil
 }
 
+func handleZSCAN(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := zscanKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := internal.ParseScanOptions(params.Command[2:], false)
+	if err != nil {
+		return nil, err
+	}
+
+	key := keys.ReadKeys[0]
+	keyExists := params.KeysExist(params.Context, keys.ReadKeys)[key]
+
+	if !keyExists {
+		return []byte("*2\r\n$1\r\n0\r\n*0\r\n"), nil
+	}
+
+	set, ok := params.GetValues(params.Context, []string{key})[key].(*SortedSet)
+	if !ok {
+		return nil, fmt.Errorf("value at %s is not a sorted set", key)
+	}
+
+	members := set.GetAll()
+	values := make([]string, len(members))
+	for i, m := range members {
+		values[i] = string(m.Value)
+	}
+
+	page, cursor := internal.ScanCursor(values, options.Cursor, options.Count)
+	if options.Match != nil {
+		page = slices.DeleteFunc(page, func(member string) bool {
+			return !options.Match.Match(member)
+		})
+	}
+
+	c := strconv.FormatUint(cursor, 10)
+	res := fmt.Sprintf("*2\r\n$%d\r\n%s\r\n*%d\r\n", len(c), c, len(page)*2)
+	for _, member := range page {
+		score := strconv.FormatFloat(float64(set.Get(Value(member)).Score), 'f', -1, 64)
+		res += fmt.Sprintf("$%d\r\n%s\r\n$%d\r\n%s\r\n", len(member), member, len(score), score)
+	}
+
+	return []byte(res), nil
+}
+
 func Commands() []internal.Command {
 	return []internal.Command{
+		{
+			Command:    "bzmpop",
+			Module:     constants.SortedSetModule,
+			Categories: []string{constants.SortedSetCategory, constants.WriteCategory, constants.SlowCategory, constants.BlockingCategory},
+			Description: `(BZMPOP timeout numkeys key [key ...] <MIN | MAX> [COUNT count])
+Pops 'count' members from the first non-empty sorted set, as an array of the key and the members with their scores.
+MIN or MAX determines whether to pop the members with the lowest or highest scores respectively.
+Blocks until members are added to one of the sorted sets when they are all empty. The timeout is in seconds,
+and 0 blocks indefinitely. Returns nil when the timeout expires.
+Clients blocked on the same key are served in the order they blocked.`,
+			Sync:              true,
+			KeyExtractionFunc: bzmpopKeyFunc,
+			HandlerFunc:       handleBZMPOP,
+		},
+		{
+			Command:    "bzpopmax",
+			Module:     constants.SortedSetModule,
+			Categories: []string{constants.SortedSetCategory, constants.WriteCategory, constants.SlowCategory, constants.BlockingCategory},
+			Description: `(BZPOPMAX key [key ...] timeout)
+Removes and returns the member with the highest score from the first non-empty sorted set, as an array of the key,
+the member and its score. Blocks until members are added to one of the sorted sets when they are all empty.
+The timeout is in seconds, and 0 blocks indefinitely. Returns nil when the timeout expires.
+Clients blocked on the same key are served in the order they blocked.`,
+			Sync:              true,
+			KeyExtractionFunc: bzpopKeyFunc,
+			HandlerFunc:       handleBZPOP,
+		},
+		{
+			Command:    "bzpopmin",
+			Module:     constants.SortedSetModule,
+			Categories: []string{constants.SortedSetCategory, constants.WriteCategory, constants.SlowCategory, constants.BlockingCategory},
+			Description: `(BZPOPMIN key [key ...] timeout)
+Removes and returns the member with the lowest score from the first non-empty sorted set, as an array of the key,
+the member and its score. Blocks until members are added to one of the sorted sets when they are all empty.
+The timeout is in seconds, and 0 blocks indefinitely. Returns nil when the timeout expires.
+Clients blocked on the same key are served in the order they blocked.`,
+			Sync:              true,
+			KeyExtractionFunc: bzpopKeyFunc,
+			HandlerFunc:       handleBZPOP,
+		},
 		{
 			Command:    "zadd",
 			Module:     constants.SortedSetModule,
@@ -1637,5 +1866,18 @@ The resulting union is stored at the destination key.`,
 			KeyExtractionFunc: zunionstoreKeyFunc,
 			HandlerFunc:       handleZUNIONSTORE,
 		},
+		{
+			Command:    "zscan",
+			Module:     constants.SortedSetModule,
+			Categories: []string{constants.SortedSetCategory, constants.ReadCategory, constants.SlowCategory},
+			Description: `(ZSCAN key cursor [MATCH pattern] [COUNT count])
+Incrementally iterates over the members and scores of a sorted set.
+Returns the cursor for the next call and a flat list of members and scores. A returned cursor of 0 means the iteration is complete.
+MATCH - Only return members that match the glob-style pattern.
+COUNT - The number of members to examine in this call. Defaults to 10.`,
+			Sync:              false,
+			KeyExtractionFunc: zscanKeyFunc,
+			HandlerFunc:       handleZSCAN,
+		},
 	}
 }
@@ -21,6 +21,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/echovault/sugardb/internal"
 	"github.com/echovault/sugardb/internal/config"
@@ -30,6 +31,20 @@ import (
 	"github.com/tidwall/resp"
 )
 
+func format(v resp.Value) string {
+	if v.IsNull() {
+		return "nil"
+	}
+	if v.Type() == resp.Array {
+		elements := make([]string, len(v.Array()))
+		for i, element := range v.Array() {
+			elements[i] = format(element)
+		}
+		return "[" + strings.Join(elements, " ") + "]"
+	}
+	return v.String()
+}
+
 func Test_SortedSet(t *testing.T) {
 	port, err := internal.GetFreePort()
 	if err != nil {
@@ -58,6 +73,94 @@ func Test_SortedSet(t *testing.T) {
 		mockServer.ShutDown()
 	})
 
+	type step struct {
+		command []string
+		want    string // The expected response, as returned by format.
+		wantErr string // The expected error substring when the response is an error.
+	}
+
+	send := func(client *resp.Conn, command []string) (resp.Value, error) {
+		values := make([]resp.Value, len(command))
+		for i, token := range command {
+			values[i] = resp.StringValue(token)
+		}
+		if err := client.WriteArray(values); err != nil {
+			return resp.Value{}, err
+		}
+		res, _, err := client.ReadValue()
+		return res, err
+	}
+
+	runSteps := func(t *testing.T, client *resp.Conn, steps []step) {
+		for _, step := range steps {
+			res, err := send(client, step.command)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if step.wantErr != "" {
+				if res.Error() == nil || !strings.Contains(res.Error().Error(), step.wantErr) {
+					t.Errorf("%v: expected error \"%s\", got %+v", step.command, step.wantErr, res)
+				}
+				continue
+			}
+			if res.Error() != nil {
+				t.Errorf("%v: unexpected error %v", step.command, res.Error())
+				continue
+			}
+			if got := format(res); got != step.want {
+				t.Errorf("%v: expected response \"%s\", got \"%s\"", step.command, step.want, got)
+			}
+		}
+	}
+
+	connect := func(t *testing.T) *resp.Conn {
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			_ = conn.Close()
+		})
+		return resp.NewConn(conn)
+	}
+
+	// block sends the command from a new connection and returns a channel that receives the response.
+	// It waits until the command had time to block, and fails the test if it returned instead.
+	block := func(t *testing.T, command []string) <-chan string {
+		client := connect(t)
+		done := make(chan string, 1)
+		go func() {
+			res, err := send(client, command)
+			if err != nil {
+				t.Error(err)
+			}
+			if res.Error() != nil {
+				done <- res.Error().Error()
+				return
+			}
+			done <- format(res)
+		}()
+		time.Sleep(100 * time.Millisecond)
+		select {
+		case res := <-done:
+			t.Fatalf("expected %v to block, got %s", command, res)
+		default:
+		}
+		return done
+	}
+
+	receive := func(t *testing.T, done <-chan string, want string) {
+		select {
+		case got := <-done:
+			if got != want {
+				t.Errorf("expected response \"%s\", got \"%s\"", want, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("timed out waiting for response \"%s\"", want)
+		}
+	}
+
 	t.Run("Test_HandleZADD", func(t *testing.T) {
 		t.Parallel()
 		conn, err := internal.GetConnection("localhost", port)
@@ -5745,4 +5848,241 @@ func Test_SortedSet(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("Test_HandleZSCAN", func(t *testing.T) {
+		t.Parallel()
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		client := resp.NewConn(conn)
+
+		// Preset two sorted sets with 100 members each and a string value.
+		var members []string
+		for i := 0; i < 100; i++ {
+			members = append(members, "member"+strconv.Itoa(i))
+		}
+		for _, key := range []string{"ZScanKey1", "ZScanKey2"} {
+			scores := make(map[string]float64, len(members))
+			for i, member := range members {
+				scores[member] = float64(i)
+			}
+			if _, err = mockServer.ZAdd(key, scores, sugardb.ZAddOptions{}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		if _, _, err = mockServer.Set("ZScanKey4", "value", sugardb.SETOptions{}); err != nil {
+			t.Error(err)
+			return
+		}
+
+		tests := []struct {
+			name          string
+			key           string
+			cursor        string
+			options       []string
+			mutate        bool
+			expected      []string
+			expectedError error
+		}{
+			{
+				name:     "1. Iterate over all the members",
+				key:      "ZScanKey1",
+				options:  []string{},
+				expected: members,
+			},
+			{
+				name:     "2. Iterate over all the members while the sorted set is mutated",
+				key:      "ZScanKey2",
+				options:  []string{"COUNT", "15"},
+				mutate:   true,
+				expected: members,
+			},
+			{
+				name:     "3. Only return members that match the pattern",
+				key:      "ZScanKey1",
+				options:  []string{"MATCH", "member9*", "COUNT", "30"},
+				expected: []string{"member9", "member90", "member91", "member92", "member93", "member94", "member95", "member96", "member97", "member98", "member99"},
+			},
+			{
+				name:     "4. Return an empty page when the key does not exist",
+				key:      "ZScanKey3",
+				options:  []string{},
+				expected: []string{},
+			},
+			{
+				name:          "5. Return error when the cursor is invalid",
+				key:           "ZScanKey1",
+				cursor:        "cursor",
+				options:       []string{},
+				expectedError: errors.New("invalid cursor"),
+			},
+			{
+				name:          "6. Return error when the TYPE option is provided",
+				key:           "ZScanKey1",
+				options:       []string{"TYPE", "string"},
+				expectedError: errors.New("syntax error"),
+			},
+			{
+				name:          "7. Return error when the key is not a sorted set",
+				key:           "ZScanKey4",
+				options:       []string{},
+				expectedError: errors.New("value at ZScanKey4 is not a sorted set"),
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				var got []string
+				cursor := "0"
+				if test.cursor != "" {
+					cursor = test.cursor
+				}
+				for i := 0; ; i++ {
+					command := []resp.Value{resp.StringValue("ZSCAN"), resp.StringValue(test.key), resp.StringValue(cursor)}
+					for _, option := range test.options {
+						command = append(command, resp.StringValue(option))
+					}
+					if err = client.WriteArray(command); err != nil {
+						t.Error(err)
+						return
+					}
+					res, _, err := client.ReadValue()
+					if err != nil {
+						t.Error(err)
+						return
+					}
+
+					if test.expectedError != nil {
+						if !strings.Contains(res.Error().Error(), test.expectedError.Error()) {
+							t.Errorf("expected error \"%s\", got \"%s\"", test.expectedError.Error(), res.Error().Error())
+						}
+						return
+					}
+
+					cursor = res.Array()[0].String()
+					for j, item := range res.Array()[1].Array() {
+						if j%2 == 0 {
+							got = append(got, item.String())
+						}
+					}
+					if cursor == "0" {
+						break
+					}
+
+					if test.mutate {
+						// Add and remove members between calls.
+						if _, err = mockServer.ZAdd(test.key, map[string]float64{"new" + strconv.Itoa(i): float64(i)}, sugardb.ZAddOptions{}); err != nil {
+							t.Error(err)
+							return
+						}
+						if _, err = mockServer.ZRem(test.key, "new"+strconv.Itoa(i-1)); err != nil {
+							t.Error(err)
+							return
+						}
+					}
+				}
+
+				for _, item := range test.expected {
+					if !slices.Contains(got, item) {
+						t.Errorf("expected \"%s\" to be returned by the iteration", item)
+					}
+				}
+				if !test.mutate && len(got) != len(test.expected) {
+					t.Errorf("expected %d items, got %d", len(test.expected), len(got))
+				}
+			})
+		}
+	})
+
+	t.Run("Test_HandleBZPOPMIN_BZPOPMAX", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"ZADD", "BzpopKey1", "1", "a", "2", "b", "3", "c"}, want: "3"},
+			{command: []string{"BZPOPMIN", "BzpopKey0", "BzpopKey1", "0"}, want: "[BzpopKey1 a 1]"},
+			{command: []string{"BZPOPMAX", "BzpopKey0", "BzpopKey1", "0"}, want: "[BzpopKey1 c 3]"},
+			{command: []string{"BZPOPMIN", "BzpopKey1", "1.5"}, want: "[BzpopKey1 b 2]"},
+			{command: []string{"ZCARD", "BzpopKey1"}, want: "0"},
+			{command: []string{"BZPOPMIN", "BzpopKey1", "BzpopKey2", "0.05"}, want: "nil"},
+			{command: []string{"BZPOPMAX", "BzpopKey1", "0.05"}, want: "nil"},
+			// Blocking commands return immediately inside a transaction.
+			{command: []string{"MULTI"}, want: "OK"},
+			{command: []string{"BZPOPMIN", "BzpopKey1", "0"}, want: "QUEUED"},
+			{command: []string{"EXEC"}, want: "[nil]"},
+			{command: []string{"SET", "BzpopKey3", "value"}, want: "OK"},
+			{command: []string{"BZPOPMIN", "BzpopKey1", "BzpopKey3", "0"}, wantErr: "value at key BzpopKey3 is not a sorted set"},
+			{command: []string{"BZPOPMIN", "BzpopKey1", "-1"}, wantErr: "timeout is negative"},
+			{command: []string{"BZPOPMAX", "BzpopKey1", "soon"}, wantErr: "timeout is not a float or out of range"},
+			{command: []string{"BZPOPMIN", "BzpopKey1"}, wantErr: constants.WrongArgsResponse},
+		})
+	})
+
+	t.Run("Test_HandleBZPOPMIN_Block", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		// The clients blocked on a key are served in the order they blocked.
+		first := block(t, []string{"BZPOPMIN", "BzpopBlockKey1", "0"})
+		second := block(t, []string{"BZPOPMAX", "BzpopBlockKey2", "BzpopBlockKey1", "0"})
+		third := block(t, []string{"BZPOPMIN", "BzpopBlockKey1", "0"})
+		runSteps(t, client, []step{
+			{command: []string{"ZADD", "BzpopBlockKey1", "1", "a", "2", "b"}, want: "2"},
+		})
+		receive(t, first, "[BzpopBlockKey1 a 1]")
+		receive(t, second, "[BzpopBlockKey1 b 2]")
+
+		// Adding to an existing sorted set, ZINCRBY and ZUNIONSTORE wake the blocked clients.
+		runSteps(t, client, []step{
+			{command: []string{"ZADD", "BzpopBlockKey1", "3", "c"}, want: "1"},
+		})
+		receive(t, third, "[BzpopBlockKey1 c 3]")
+		fourth := block(t, []string{"BZPOPMAX", "BzpopBlockKey1", "0"})
+		runSteps(t, client, []step{
+			{command: []string{"ZINCRBY", "BzpopBlockKey1", "4.5", "d"}, want: "4.5"},
+		})
+		receive(t, fourth, "[BzpopBlockKey1 d 4.5]")
+		fifth := block(t, []string{"BZPOPMIN", "BzpopBlockKey3", "0"})
+		runSteps(t, client, []step{
+			{command: []string{"ZADD", "BzpopBlockKey4", "5", "e"}, want: "1"},
+			{command: []string{"ZUNIONSTORE", "BzpopBlockKey3", "1", "BzpopBlockKey4"}, want: "1"},
+		})
+		receive(t, fifth, "[BzpopBlockKey3 e 5]")
+
+		// The timeout expires when nothing is added.
+		sixth := block(t, []string{"BZPOPMIN", "BzpopBlockKey5", "0.2"})
+		receive(t, sixth, "nil")
+	})
+
+	t.Run("Test_HandleBZMPOP", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"ZADD", "BzmpopKey2", "1", "a", "2", "b", "3", "c", "4", "d"}, want: "4"},
+			{command: []string{"BZMPOP", "0", "2", "BzmpopKey1", "BzmpopKey2", "MIN"}, want: "[BzmpopKey2 [[a 1]]]"},
+			{command: []string{"BZMPOP", "0", "2", "BzmpopKey1", "BzmpopKey2", "MAX", "COUNT", "2"}, want: "[BzmpopKey2 [[d 4] [c 3]]]"},
+			{command: []string{"BZMPOP", "0", "1", "BzmpopKey2", "min", "count", "10"}, want: "[BzmpopKey2 [[b 2]]]"},
+			{command: []string{"BZMPOP", "0.05", "1", "BzmpopKey2", "MIN"}, want: "nil"},
+			{command: []string{"BZMPOP", "0", "0", "BzmpopKey2", "MIN"}, wantErr: "numkeys should be greater than 0"},
+			{command: []string{"BZMPOP", "0", "x", "BzmpopKey2", "MIN"}, wantErr: "numkeys must be an integer"},
+			{command: []string{"BZMPOP", "0", "2", "BzmpopKey2", "MIN"}, wantErr: "syntax error"},
+			{command: []string{"BZMPOP", "0", "1", "BzmpopKey2", "LOW"}, wantErr: "syntax error"},
+			{command: []string{"BZMPOP", "0", "1", "BzmpopKey2", "MIN", "COUNT", "0"}, wantErr: "count should be greater than 0"},
+			{command: []string{"BZMPOP", "-1", "1", "BzmpopKey2", "MIN"}, wantErr: "timeout is negative"},
+			{command: []string{"BZMPOP", "0", "1", "BzmpopKey2"}, wantErr: constants.WrongArgsResponse},
+		})
+
+		done := block(t, []string{"BZMPOP", "0", "2", "BzmpopKey3", "BzmpopKey4", "MAX", "COUNT", "2"})
+		runSteps(t, client, []step{
+			{command: []string{"ZADD", "BzmpopKey4", "1", "e", "2", "f", "3", "g"}, want: "3"},
+		})
+		receive(t, done, "[BzmpopKey4 [[g 3] [f 2]]]")
+	})
 }
@@ -46,6 +46,7 @@ type MemberParam struct {
 
 type SortedSet struct {
 	members map[Value]MemberObject
+	byScore []MemberParam // The members ordered by score and value, for the range queries by score.
 }
 
 func (s *SortedSet) GetMem() int64 {
@@ -64,6 +65,8 @@ func (s *SortedSet) GetMem() int64 {
 		size += int64(unsafe.Sizeof(v.Value))
 		size += int64(len(v.Value))
 	}
+	// score index, which shares the strings of the map
+	size += int64(len(s.byScore)) * int64(unsafe.Sizeof(MemberParam{}))
 
 	return size
 }
@@ -82,9 +85,50 @@ func NewSortedSet(members []MemberParam) *SortedSet {
 			Exists: true,
 		}
 	}
+	s.byScore = s.GetAll()
+	slices.SortFunc(s.byScore, compareMembers)
 	return s
 }
 
+// compareMembers orders the members by score, and by value when the scores are equal.
+func compareMembers(a, b MemberParam) int {
+	if c := cmp.Compare(a.Score, b.Score); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Value, b.Value)
+}
+
+// put sets the score of the member, adding the member if it doesn't exist.
+func (set *SortedSet) put(v Value, score Score) {
+	if old, ok := set.members[v]; ok {
+		set.unindex(MemberParam{Value: v, Score: old.Score})
+	}
+	set.members[v] = MemberObject{Value: v, Score: score, Exists: true}
+	m := MemberParam{Value: v, Score: score}
+	i, _ := slices.BinarySearchFunc(set.byScore, m, compareMembers)
+	set.byScore = slices.Insert(set.byScore, i, m)
+}
+
+// unindex removes the member from the score index.
+func (set *SortedSet) unindex(m MemberParam) {
+	if i, found := slices.BinarySearchFunc(set.byScore, m, compareMembers); found {
+		set.byScore = slices.Delete(set.byScore, i, i+1)
+	}
+}
+
+// RangeByScore returns the members with a score between minimum and maximum inclusive,
+// ordered by score and value.
+func (set *SortedSet) RangeByScore(minimum, maximum Score) []MemberParam {
+	i, _ := slices.BinarySearchFunc(set.byScore, minimum, func(m MemberParam, score Score) int {
+		return cmp.Compare(m.Score, score)
+	})
+	var res []MemberParam
+	for ; i < len(set.byScore) && set.byScore[i].Score <= maximum; i++ {
+		res = append(res, set.byScore[i])
+	}
+	return res
+}
+
 func (set *SortedSet) Contains(m Value) bool {
 	return set.members[m].Exists
 }
@@ -176,11 +220,7 @@ func (set *SortedSet) AddOrUpdate(
 		for _, m := range members {
 			if !set.Contains(m.Value) {
 				// If the member is not contained, add it with the increment as its Score
-				set.members[m.Value] = MemberObject{
-					Value:  m.Value,
-					Score:  m.Score,
-					Exists: true,
-				}
+				set.put(m.Value, m.Score)
 				// Always add count because this is the addition of a new element
 				count += 1
 				return count, err
@@ -188,11 +228,7 @@ func (set *SortedSet) AddOrUpdate(
 			if slices.Contains([]Score{Score(math.Inf(-1)), Score(math.Inf(1))}, set.members[m.Value].Score) {
 				return count, errors.New("cannot increment -inf or +inf")
 			}
-			set.members[m.Value] = MemberObject{
-				Value:  m.Value,
-				Score:  set.members[m.Value].Score + m.Score,
-				Exists: true,
-			}
+			set.put(m.Value, set.members[m.Value].Score+m.Score)
 			if strings.EqualFold(ch, "ch") {
 				count += 1
 			}
@@ -204,11 +240,7 @@ func (set *SortedSet) AddOrUpdate(
 		if strings.EqualFold(policy, "xx") {
 			// Only update existing elements, do not add new elements
 			if set.Contains(m.Value) {
-				set.members[m.Value] = MemberObject{
-					Value:  m.Value,
-					Score:  compareScores(set.members[m.Value].Score, m.Score, comp),
-					Exists: true,
-				}
+				set.put(m.Value, compareScores(set.members[m.Value].Score, m.Score, comp))
 				if strings.EqualFold(ch, "ch") {
 					count += 1
 				}
@@ -218,11 +250,7 @@ func (set *SortedSet) AddOrUpdate(
 		if strings.EqualFold(policy, "nx") {
 			// Only add new elements, do not update existing elements
 			if !set.Contains(m.Value) {
-				set.members[m.Value] = MemberObject{
-					Value:  m.Value,
-					Score:  m.Score,
-					Exists: true,
-				}
+				set.put(m.Value, m.Score)
 				count += 1
 			}
 			continue
@@ -231,17 +259,14 @@ func (set *SortedSet) AddOrUpdate(
 		if set.members[m.Value].Score != m.Score || !set.members[m.Value].Exists {
 			count += 1
 		}
-		set.members[m.Value] = MemberObject{
-			Value:  m.Value,
-			Score:  compareScores(set.members[m.Value].Score, m.Score, comp),
-			Exists: true,
-		}
+		set.put(m.Value, compareScores(set.members[m.Value].Score, m.Score, comp))
 	}
 	return count, nil
 }
 
 func (set *SortedSet) Remove(v Value) bool {
 	if set.Contains(v) {
+		set.unindex(MemberParam{Value: v, Score: set.members[v].Score})
 		delete(set.members, v)
 		return true
 	}
@@ -16,9 +16,11 @@ package sorted_set
 
 import (
 	"errors"
+	"math"
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 func extractKeysWeightsAggregateWithScores(cmd []string) ([]string, []int, string, bool, error) {
@@ -167,3 +169,48 @@ func compareScores(old Score, new Score, comp string) Score {
 		return old
 	}
 }
+
+// parseTimeout parses the timeout of the blocking commands, which is a number of seconds.
+func parseTimeout(arg string) (time.Duration, error) {
+	seconds, err := strconv.ParseFloat(arg, 64)
+	if err != nil || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return 0, errors.New("timeout is not a float or out of range")
+	}
+	if seconds < 0 {
+		return 0, errors.New("timeout is negative")
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// parseBZMPopArgs parses the "numkeys key [key ...] <MIN | MAX> [COUNT count]" arguments of BZMPOP.
+func parseBZMPopArgs(args []string) ([]string, string, int, error) {
+	numKeys, err := strconv.Atoi(args[0])
+	if err != nil {
+		return nil, "", 0, errors.New("numkeys must be an integer")
+	}
+	if numKeys <= 0 {
+		return nil, "", 0, errors.New("numkeys should be greater than 0")
+	}
+	if len(args) < numKeys+2 {
+		return nil, "", 0, errors.New("syntax error")
+	}
+	keys := args[1 : numKeys+1]
+
+	policy := strings.ToLower(args[numKeys+1])
+	if policy != "min" && policy != "max" {
+		return nil, "", 0, errors.New("syntax error")
+	}
+
+	count := 1
+	switch rest := args[numKeys+2:]; {
+	case len(rest) == 0:
+	case len(rest) == 2 && strings.EqualFold(rest[0], "count"):
+		if count, err = strconv.Atoi(rest[1]); err != nil || count <= 0 {
+			return nil, "", 0, errors.New("count should be greater than 0")
+		}
+	default:
+		return nil, "", 0, errors.New("syntax error")
+	}
+
+	return keys, policy, count, nil
+}
@@ -0,0 +1,1453 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stream
+
+import (
+	"errors"
+	"fmt"
+	"github.com/echovault/sugardb/internal"
+	"github.com/echovault/sugardb/internal/constants"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// trimOptions holds the MAXLEN or MINID trimming options of XADD and XTRIM.
+type trimOptions struct {
+	strategy  string // "maxlen", "minid", or empty if the stream should not be trimmed.
+	threshold string
+	limit     int
+}
+
+// parseTrimOptions parses the trimming options at the start of args.
+// It returns the options and the number of arguments consumed.
+func parseTrimOptions(args []string) (trimOptions, int, error) {
+	var opts trimOptions
+	if len(args) == 0 {
+		return opts, 0, nil
+	}
+
+	strategy := strings.ToLower(args[0])
+	if strategy != "maxlen" && strategy != "minid" {
+		return opts, 0, nil
+	}
+	opts.strategy = strategy
+
+	i := 1
+	approximate := false
+	if i < len(args) && (args[i] == "=" || args[i] == "~") {
+		approximate = args[i] == "~"
+		i++
+	}
+	if i >= len(args) {
+		return opts, 0, errors.New(constants.WrongArgsResponse)
+	}
+	opts.threshold = args[i]
+	i++
+
+	if i+1 < len(args) && strings.EqualFold(args[i], "limit") {
+		if !approximate {
+			return opts, 0, errors.New("syntax error, LIMIT cannot be used without the special ~ option")
+		}
+		limit, err := strconv.Atoi(args[i+1])
+		if err != nil || limit < 0 {
+			return opts, 0, errors.New("LIMIT must be a non-negative integer")
+		}
+		opts.limit = limit
+		i += 2
+	}
+
+	return opts, i, nil
+}
+
+// trim trims the stream with the options and returns the number of entries removed.
+func (opts trimOptions) trim(stream *Stream) (int, error) {
+	switch opts.strategy {
+	case "maxlen":
+		maxLen, err := strconv.Atoi(opts.threshold)
+		if err != nil || maxLen < 0 {
+			return 0, errors.New("MAXLEN must be a non-negative integer")
+		}
+		return stream.TrimMaxLen(maxLen, opts.limit), nil
+	case "minid":
+		minID, err := ParseID(opts.threshold, 0)
+		if err != nil {
+			return 0, err
+		}
+		return stream.TrimMinID(minID, opts.limit), nil
+	}
+	return 0, nil
+}
+
+// validate checks the threshold before the stream is modified.
+func (opts trimOptions) validate() error {
+	_, err := opts.trim(NewStream())
+	return err
+}
+
+// getStream returns the stream at the key, or nil if the key does not exist.
+func getStream(params internal.HandlerFuncParams, key string) (*Stream, error) {
+	if !params.KeysExist(params.Context, []string{key})[key] {
+		return nil, nil
+	}
+	stream, ok := params.GetValues(params.Context, []string{key})[key].(*Stream)
+	if !ok {
+		return nil, fmt.Errorf("value at %s is not a stream", key)
+	}
+	return stream, nil
+}
+
+// encodeEntries returns the RESP array of the entries. Each entry is an array of its ID and its field-value pairs.
+// The field-value pairs of an entry without fields, such as a pending entry that was deleted, are a null array.
+func encodeEntries(entries []Entry) string {
+	res := fmt.Sprintf("*%d\r\n", len(entries))
+	for _, entry := range entries {
+		id := entry.ID.String()
+		if entry.Fields == nil {
+			res += fmt.Sprintf("*2\r\n$%d\r\n%s\r\n*-1\r\n", len(id), id)
+			continue
+		}
+		res += fmt.Sprintf("*2\r\n$%d\r\n%s\r\n*%d\r\n", len(id), id, len(entry.Fields))
+		for _, field := range entry.Fields {
+			res += fmt.Sprintf("$%d\r\n%s\r\n", len(field), field)
+		}
+	}
+	return res
+}
+
+func handleXADD(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := xaddKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key := keys.WriteKeys[0]
+
+	i := 2
+	noMkStream := false
+	if strings.EqualFold(params.Command[i], "nomkstream") {
+		noMkStream = true
+		i++
+	}
+
+	opts, n, err := parseTrimOptions(params.Command[i:])
+	if err != nil {
+		return nil, err
+	}
+	if err = opts.validate(); err != nil {
+		return nil, err
+	}
+	i += n
+
+	if i >= len(params.Command) {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+	id := params.Command[i]
+	fields := params.Command[i+1:]
+	if len(fields) == 0 || len(fields)%2 != 0 {
+		return nil, errors.New(constants.WrongArgsResponse)
+	}
+
+	stream, err := getStream(params, key)
+	if err != nil {
+		return nil, err
+	}
+	if stream == nil {
+		if noMkStream {
+			return []byte("$-1\r\n"), nil
+		}
+		stream = NewStream()
+	}
+
+	newID, err := stream.Add(id, fields, params.GetClock().Now())
+	if err != nil {
+		return nil, err
+	}
+	if _, err = opts.trim(stream); err != nil {
+		return nil, err
+	}
+
+	if err = params.SetValues(params.Context, map[string]interface{}{key: stream}); err != nil {
+		return nil, err
+	}
+
+	res := newID.String()
+	if strings.Contains(id, "*") {
+		// The entry is written to the AOF with the generated ID, so that it gets the same ID when the AOF is replayed.
+		cmd := slices.Clone(params.Command)
+		cmd[i] = res
+		params.Propagate(cmd)
+	}
+	return []byte(fmt.Sprintf("$%d\r\n%s\r\n", len(res), res)), nil
+}
+
+// parseRangeID parses the start or end of an XRANGE interval.
+// "-" and "+" are the smallest and largest possible IDs. An ID prefixed with "(" is exclusive.
+// When the sequence number is omitted, it's 0 for the start and the largest sequence number for the end.
+func parseRangeID(s string, end bool) (ID, error) {
+	switch s {
+	case "-":
+		return MinID, nil
+	case "+":
+		return MaxID, nil
+	}
+
+	var defaultSeq uint64
+	if end {
+		defaultSeq = MaxID.Seq
+	}
+
+	exclusive := strings.HasPrefix(s, "(")
+	id, err := ParseID(strings.TrimPrefix(s, "("), defaultSeq)
+	if err != nil || !exclusive {
+		return id, err
+	}
+
+	var ok bool
+	if end {
+		id, ok = id.Prev()
+	} else {
+		id, ok = id.Next()
+	}
+	if !ok {
+		return ID{}, errors.New("invalid start or end ID for the interval")
+	}
+	return id, nil
+}
+
+func handleRange(params internal.HandlerFuncParams, reverse bool) ([]byte, error) {
+	keys, err := xrangeKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key := keys.ReadKeys[0]
+
+	startArg, endArg := params.Command[2], params.Command[3]
+	if reverse {
+		startArg, endArg = endArg, startArg
+	}
+	start, err := parseRangeID(startArg, false)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseRangeID(endArg, true)
+	if err != nil {
+		return nil, err
+	}
+
+	count := 0
+	if len(params.Command) == 6 {
+		if !strings.EqualFold(params.Command[4], "count") {
+			return nil, errors.New("syntax error")
+		}
+		if count, err = strconv.Atoi(params.Command[5]); err != nil {
+			return nil, errors.New("COUNT must be an integer")
+		}
+		if count <= 0 {
+			return []byte("*0\r\n"), nil
+		}
+	}
+
+	stream, err := getStream(params, key)
+	if err != nil {
+		return nil, err
+	}
+	if stream == nil {
+		return []byte("*0\r\n"), nil
+	}
+
+	return []byte(encodeEntries(stream.Range(start, end, count, reverse))), nil
+}
+
+func handleXRANGE(params internal.HandlerFuncParams) ([]byte, error) {
+	return handleRange(params, false)
+}
+
+func handleXREVRANGE(params internal.HandlerFuncParams) ([]byte, error) {
+	return handleRange(params, true)
+}
+
+func handleXLEN(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := xlenKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := getStream(params, keys.ReadKeys[0])
+	if err != nil {
+		return nil, err
+	}
+	if stream == nil {
+		return []byte(":0\r\n"), nil
+	}
+
+	return []byte(fmt.Sprintf(":%d\r\n", stream.Len())), nil
+}
+
+func handleXDEL(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := xdelKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key := keys.WriteKeys[0]
+
+	ids := make([]ID, len(params.Command[2:]))
+	for i, arg := range params.Command[2:] {
+		if ids[i], err = ParseID(arg, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	stream, err := getStream(params, key)
+	if err != nil {
+		return nil, err
+	}
+	if stream == nil {
+		return []byte(":0\r\n"), nil
+	}
+
+	count := stream.Delete(ids)
+	if count > 0 {
+		if err = params.SetValues(params.Context, map[string]interface{}{key: stream}); err != nil {
+			return nil, err
+		}
+	}
+
+	return []byte(fmt.Sprintf(":%d\r\n", count)), nil
+}
+
+func handleXTRIM(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := xtrimKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key := keys.WriteKeys[0]
+
+	opts, n, err := parseTrimOptions(params.Command[2:])
+	if err != nil {
+		return nil, err
+	}
+	if opts.strategy == "" || n != len(params.Command[2:]) {
+		return nil, errors.New("syntax error")
+	}
+	if err = opts.validate(); err != nil {
+		return nil, err
+	}
+
+	stream, err := getStream(params, key)
+	if err != nil {
+		return nil, err
+	}
+	if stream == nil {
+		return []byte(":0\r\n"), nil
+	}
+
+	count, err := opts.trim(stream)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		if err = params.SetValues(params.Context, map[string]interface{}{key: stream}); err != nil {
+			return nil, err
+		}
+	}
+
+	return []byte(fmt.Sprintf(":%d\r\n", count)), nil
+}
+
+func handleXREAD(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := xreadKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	_, idArgs, _ := splitStreamsArgs(params.Command)
+
+	count := 0
+	block := false
+	var timeout time.Duration
+	for i := 1; !strings.EqualFold(params.Command[i], "streams"); i += 2 {
+		if i+1 >= len(params.Command) {
+			return nil, errors.New("syntax error")
+		}
+		switch strings.ToLower(params.Command[i]) {
+		case "count":
+			if count, err = strconv.Atoi(params.Command[i+1]); err != nil {
+				return nil, errors.New("COUNT must be an integer")
+			}
+		case "block":
+			ms, err := strconv.Atoi(params.Command[i+1])
+			if err != nil || ms < 0 {
+				return nil, errors.New("timeout must be a non-negative integer")
+			}
+			block = true
+			timeout = time.Duration(ms) * time.Millisecond
+		default:
+			return nil, errors.New("syntax error")
+		}
+	}
+
+	// Resolve the IDs. "$" is the ID of the last entry in the stream when XREAD is called.
+	ids := make([]ID, len(idArgs))
+	for i, arg := range idArgs {
+		if arg != "$" {
+			if ids[i], err = ParseID(arg, 0); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		stream, err := getStream(params, keys.ReadKeys[i])
+		if err != nil {
+			return nil, err
+		}
+		if stream != nil {
+			ids[i] = stream.LastID()
+		}
+	}
+
+	read := func() ([]byte, error) {
+		res := ""
+		n := 0
+		for i, key := range keys.ReadKeys {
+			stream, err := getStream(params, key)
+			if err != nil {
+				return nil, err
+			}
+			if stream == nil {
+				continue
+			}
+			entries := stream.After(ids[i], count)
+			if len(entries) == 0 {
+				continue
+			}
+			res += fmt.Sprintf("*2\r\n$%d\r\n%s\r\n%s", len(key), key, encodeEntries(entries))
+			n++
+		}
+		if n == 0 {
+			return nil, nil
+		}
+		return []byte(fmt.Sprintf("*%d\r\n%s", n, res)), nil
+	}
+
+	if !block {
+		res, err := read()
+		if res == nil && err == nil {
+			return []byte("*-1\r\n"), nil
+		}
+		return res, err
+	}
+
+	// Register before reading so that an entry added between the read and the wait is not missed.
+	ready, cancel := params.BlockOnKeys(params.Context, keys.ReadKeys)
+	defer cancel()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		expired = params.GetClock().After(timeout)
+	}
+
+	for {
+		res, err := read()
+		if res != nil || err != nil {
+			return res, err
+		}
+		if ready == nil {
+			// XREAD can't block inside a transaction or a script.
+			return []byte("*-1\r\n"), nil
+		}
+		select {
+		case <-ready:
+		case <-expired:
+			return []byte("*-1\r\n"), nil
+		case <-params.Context.Done():
+			return []byte("*-1\r\n"), nil
+		}
+	}
+}
+
+// groupError returns the NOGROUP error of the key and group when err is ErrNoGroup.
+func groupError(err error, key, group string) error {
+	if errors.Is(err, ErrNoGroup) {
+		return fmt.Errorf("NOGROUP No such consumer group '%s' for key name '%s'", group, key)
+	}
+	return err
+}
+
+// getGroupStream returns the stream at the key. It returns an error if the key does not exist.
+func getGroupStream(params internal.HandlerFuncParams, key string) (*Stream, error) {
+	stream, err := getStream(params, key)
+	if err != nil {
+		return nil, err
+	}
+	if stream == nil {
+		return nil, errors.New("The XGROUP subcommand requires the key to exist. " +
+			"Note that for CREATE you may want to use the MKSTREAM option to create an empty stream automatically.")
+	}
+	return stream, nil
+}
+
+// parseGroupID parses the last delivered ID of XGROUP CREATE and XGROUP SETID.
+// "$" is the ID of the last entry added to the stream.
+func parseGroupID(arg string, stream *Stream) (ID, error) {
+	if arg == "$" {
+		return stream.LastID(), nil
+	}
+	return ParseID(arg, 0)
+}
+
+// parseEntriesRead parses the ENTRIESREAD option of XGROUP CREATE and XGROUP SETID.
+func parseEntriesRead(args []string) (int64, error) {
+	if len(args) != 2 || !strings.EqualFold(args[0], "entriesread") {
+		return 0, errors.New("syntax error")
+	}
+	entriesRead, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil || entriesRead < 0 {
+		return 0, errors.New("ENTRIESREAD must be a non-negative integer")
+	}
+	return entriesRead, nil
+}
+
+func handleXGROUPCreate(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := xgroupKeyFunc(5, 8)(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key, group := keys.WriteKeys[0], params.Command[3]
+
+	mkStream := false
+	var entriesRead int64 = -1
+	options := params.Command[5:]
+	if len(options) > 0 && strings.EqualFold(options[0], "mkstream") {
+		mkStream = true
+		options = options[1:]
+	}
+	if len(options) > 0 {
+		if entriesRead, err = parseEntriesRead(options); err != nil {
+			return nil, err
+		}
+	}
+
+	stream, err := getStream(params, key)
+	if err != nil {
+		return nil, err
+	}
+	if stream == nil {
+		if !mkStream {
+			_, err = getGroupStream(params, key)
+			return nil, err
+		}
+		stream = NewStream()
+	}
+
+	id, err := parseGroupID(params.Command[4], stream)
+	if err != nil {
+		return nil, err
+	}
+	if err = stream.CreateGroup(group, id, entriesRead); err != nil {
+		return nil, err
+	}
+
+	if err = params.SetValues(params.Context, map[string]interface{}{key: stream}); err != nil {
+		return nil, err
+	}
+	return []byte(constants.OkResponse), nil
+}
+
+func handleXGROUPDestroy(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := xgroupKeyFunc(4, 4)(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key := keys.WriteKeys[0]
+
+	stream, err := getGroupStream(params, key)
+	if err != nil {
+		return nil, err
+	}
+	if !stream.DestroyGroup(params.Command[3]) {
+		return []byte(":0\r\n"), nil
+	}
+
+	if err = params.SetValues(params.Context, map[string]interface{}{key: stream}); err != nil {
+		return nil, err
+	}
+	return []byte(":1\r\n"), nil
+}
+
+func handleXGROUPSetID(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := xgroupKeyFunc(5, 7)(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key, group := keys.WriteKeys[0], params.Command[3]
+
+	var entriesRead int64 = -1
+	if len(params.Command) > 5 {
+		if entriesRead, err = parseEntriesRead(params.Command[5:]); err != nil {
+			return nil, err
+		}
+	}
+
+	stream, err := getGroupStream(params, key)
+	if err != nil {
+		return nil, err
+	}
+	id, err := parseGroupID(params.Command[4], stream)
+	if err != nil {
+		return nil, err
+	}
+	if err = stream.SetGroupID(group, id, entriesRead); err != nil {
+		return nil, groupError(err, key, group)
+	}
+
+	if err = params.SetValues(params.Context, map[string]interface{}{key: stream}); err != nil {
+		return nil, err
+	}
+	return []byte(constants.OkResponse), nil
+}
+
+func handleXGROUPCreateConsumer(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := xgroupKeyFunc(5, 5)(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key, group := keys.WriteKeys[0], params.Command[3]
+
+	stream, err := getGroupStream(params, key)
+	if err != nil {
+		return nil, err
+	}
+	created, err := stream.CreateConsumer(group, params.Command[4], params.GetClock().Now())
+	if err != nil {
+		return nil, groupError(err, key, group)
+	}
+	if !created {
+		return []byte(":0\r\n"), nil
+	}
+
+	if err = params.SetValues(params.Context, map[string]interface{}{key: stream}); err != nil {
+		return nil, err
+	}
+	return []byte(":1\r\n"), nil
+}
+
+func handleXGROUPDelConsumer(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := xgroupKeyFunc(5, 5)(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key, group := keys.WriteKeys[0], params.Command[3]
+
+	stream, err := getGroupStream(params, key)
+	if err != nil {
+		return nil, err
+	}
+	count, err := stream.DeleteConsumer(group, params.Command[4])
+	if err != nil {
+		return nil, groupError(err, key, group)
+	}
+
+	if err = params.SetValues(params.Context, map[string]interface{}{key: stream}); err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf(":%d\r\n", count)), nil
+}
+
+func handleXREADGROUP(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := xreadgroupKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	_, idArgs, _ := splitStreamsArgs(params.Command[4:])
+	group, consumer := params.Command[2], params.Command[3]
+
+	count := 0
+	block := false
+	noAck := false
+	var timeout time.Duration
+	for i := 4; !strings.EqualFold(params.Command[i], "streams"); i++ {
+		if strings.EqualFold(params.Command[i], "noack") {
+			noAck = true
+			continue
+		}
+		if i+1 >= len(params.Command) {
+			return nil, errors.New("syntax error")
+		}
+		switch strings.ToLower(params.Command[i]) {
+		case "count":
+			if count, err = strconv.Atoi(params.Command[i+1]); err != nil {
+				return nil, errors.New("COUNT must be an integer")
+			}
+		case "block":
+			ms, err := strconv.Atoi(params.Command[i+1])
+			if err != nil || ms < 0 {
+				return nil, errors.New("timeout must be a non-negative integer")
+			}
+			block = true
+			timeout = time.Duration(ms) * time.Millisecond
+		default:
+			return nil, errors.New("syntax error")
+		}
+		i++
+	}
+
+	// ">" reads the entries that were not delivered to the group yet. Any other ID reads the
+	// pending entries of the consumer after the ID.
+	streams := make([]*Stream, len(keys.ReadKeys))
+	ids := make([]*ID, len(idArgs))
+	for i, key := range keys.ReadKeys {
+		if streams[i], err = getStream(params, key); err != nil {
+			return nil, err
+		}
+		if streams[i] == nil {
+			return nil, fmt.Errorf("NOGROUP No such key '%s' or consumer group '%s' in XREADGROUP with GROUP option", key, group)
+		}
+		if idArgs[i] == ">" {
+			continue
+		}
+		// Only new entries can be waited for.
+		block = false
+		id, err := ParseID(idArgs[i], 0)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = &id
+	}
+
+	read := func() ([]byte, error) {
+		res := ""
+		n := 0
+		delivered := make(map[string]interface{})
+		for i, key := range keys.ReadKeys {
+			var entries []Entry
+			var err error
+			if ids[i] == nil {
+				entries, err = streams[i].ReadGroup(group, consumer, count, noAck, params.GetClock().Now())
+				if len(entries) == 0 && err == nil {
+					continue
+				}
+				delivered[key] = streams[i]
+			} else {
+				entries, err = streams[i].ReadPending(group, consumer, *ids[i], count, params.GetClock().Now())
+			}
+			if err != nil {
+				return nil, fmt.Errorf("NOGROUP No such key '%s' or consumer group '%s' in XREADGROUP with GROUP option", key, group)
+			}
+			res += fmt.Sprintf("*2\r\n$%d\r\n%s\r\n%s", len(key), key, encodeEntries(entries))
+			n++
+		}
+		if len(delivered) > 0 {
+			if err := params.SetValues(params.Context, delivered); err != nil {
+				return nil, err
+			}
+		}
+		if n == 0 {
+			return nil, nil
+		}
+		return []byte(fmt.Sprintf("*%d\r\n%s", n, res)), nil
+	}
+
+	if apply, ok := params.Context.Value(internal.ContextRaftApply("RaftApply")).(func() ([]byte, error)); ok {
+		// On the raft leader, the entries are read through the raft log so that their delivery is replicated.
+		read = apply
+	}
+
+	if !block {
+		res, err := read()
+		if res == nil && err == nil {
+			return []byte("*-1\r\n"), nil
+		}
+		return res, err
+	}
+
+	// Register before reading so that an entry added between the read and the wait is not missed.
+	ready, cancel := params.BlockOnKeys(params.Context, keys.ReadKeys)
+	defer cancel()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		expired = params.GetClock().After(timeout)
+	}
+
+	for {
+		res, err := read()
+		if res != nil || err != nil {
+			return res, err
+		}
+		if ready == nil {
+			// XREADGROUP can't block inside a transaction or a script, or when it's replayed.
+			return []byte("*-1\r\n"), nil
+		}
+		select {
+		case <-ready:
+		case <-expired:
+			return []byte("*-1\r\n"), nil
+		case <-params.Context.Done():
+			return []byte("*-1\r\n"), nil
+		}
+	}
+}
+
+func handleXACK(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := xackKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key := keys.WriteKeys[0]
+
+	ids := make([]ID, len(params.Command[3:]))
+	for i, arg := range params.Command[3:] {
+		if ids[i], err = ParseID(arg, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	stream, err := getStream(params, key)
+	if err != nil {
+		return nil, err
+	}
+	if stream == nil {
+		return []byte(":0\r\n"), nil
+	}
+	count, err := stream.Ack(params.Command[2], ids)
+	if errors.Is(err, ErrNoGroup) {
+		return []byte(":0\r\n"), nil
+	}
+
+	if count > 0 {
+		if err = params.SetValues(params.Context, map[string]interface{}{key: stream}); err != nil {
+			return nil, err
+		}
+	}
+	return []byte(fmt.Sprintf(":%d\r\n", count)), nil
+}
+
+func handleXPENDING(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := xpendingKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key, group := keys.ReadKeys[0], params.Command[2]
+
+	stream, err := getStream(params, key)
+	if err != nil {
+		return nil, err
+	}
+	if stream == nil {
+		return nil, fmt.Errorf("NOGROUP No such key '%s' or consumer group '%s'", key, group)
+	}
+
+	if len(params.Command) == 3 {
+		pending, err := stream.PendingSummary(group)
+		if err != nil {
+			return nil, fmt.Errorf("NOGROUP No such key '%s' or consumer group '%s'", key, group)
+		}
+		if len(pending) == 0 {
+			return []byte("*4\r\n:0\r\n$-1\r\n$-1\r\n*-1\r\n"), nil
+		}
+
+		consumers := make([]string, 0)
+		counts := make(map[string]int)
+		for _, entry := range pending {
+			if counts[entry.Consumer] == 0 {
+				consumers = append(consumers, entry.Consumer)
+			}
+			counts[entry.Consumer]++
+		}
+		slices.Sort(consumers)
+
+		first, last := pending[0].ID.String(), pending[len(pending)-1].ID.String()
+		res := fmt.Sprintf("*4\r\n:%d\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n*%d\r\n",
+			len(pending), len(first), first, len(last), last, len(consumers))
+		for _, consumer := range consumers {
+			count := strconv.Itoa(counts[consumer])
+			res += fmt.Sprintf("*2\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(consumer), consumer, len(count), count)
+		}
+		return []byte(res), nil
+	}
+
+	args := params.Command[3:]
+	var minIdle time.Duration
+	if strings.EqualFold(args[0], "idle") {
+		ms, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || ms < 0 {
+			return nil, errors.New("IDLE must be a non-negative integer")
+		}
+		minIdle = time.Duration(ms) * time.Millisecond
+		args = args[2:]
+	}
+	if len(args) != 3 && len(args) != 4 {
+		return nil, errors.New("syntax error")
+	}
+
+	start, err := parseRangeID(args[0], false)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseRangeID(args[1], true)
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(args[2])
+	if err != nil {
+		return nil, errors.New("count must be an integer")
+	}
+	if count <= 0 {
+		return []byte("*0\r\n"), nil
+	}
+	consumer := ""
+	if len(args) == 4 {
+		consumer = args[3]
+	}
+
+	now := params.GetClock().Now()
+	pending, err := stream.Pending(group, start, end, count, consumer, minIdle, now)
+	if err != nil {
+		return nil, fmt.Errorf("NOGROUP No such key '%s' or consumer group '%s'", key, group)
+	}
+
+	res := fmt.Sprintf("*%d\r\n", len(pending))
+	for _, entry := range pending {
+		id := entry.ID.String()
+		res += fmt.Sprintf("*4\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n:%d\r\n:%d\r\n",
+			len(id), id, len(entry.Consumer), entry.Consumer,
+			now.Sub(entry.DeliveryTime).Milliseconds(), entry.DeliveryCount)
+	}
+	return []byte(res), nil
+}
+
+// parseMinIdleTime parses the min-idle-time argument of XCLAIM and XAUTOCLAIM.
+func parseMinIdleTime(arg string) (time.Duration, error) {
+	ms, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || ms < 0 {
+		return 0, errors.New("min-idle-time must be a non-negative integer")
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// encodeIDs returns the RESP array of the IDs.
+func encodeIDs(ids []ID) string {
+	res := fmt.Sprintf("*%d\r\n", len(ids))
+	for _, id := range ids {
+		s := id.String()
+		res += fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+	}
+	return res
+}
+
+func handleXCLAIM(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := xclaimKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key, group, consumer := keys.WriteKeys[0], params.Command[2], params.Command[3]
+
+	minIdle, err := parseMinIdleTime(params.Command[4])
+	if err != nil {
+		return nil, err
+	}
+
+	// The IDs are followed by the options.
+	ids := make([]ID, 0)
+	i := 5
+	for ; i < len(params.Command); i++ {
+		id, err := ParseID(params.Command[i], 0)
+		if err != nil {
+			break
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("invalid stream ID specified as stream command argument")
+	}
+
+	var opts ClaimOptions
+	for ; i < len(params.Command); i++ {
+		option := strings.ToLower(params.Command[i])
+		switch option {
+		case "force":
+			opts.Force = true
+			continue
+		case "justid":
+			opts.JustID = true
+			continue
+		}
+		if i+1 >= len(params.Command) {
+			return nil, errors.New("syntax error")
+		}
+		i++
+		switch option {
+		case "idle", "time":
+			ms, err := strconv.ParseInt(params.Command[i], 10, 64)
+			if err != nil || ms < 0 {
+				return nil, fmt.Errorf("%s must be a non-negative integer", strings.ToUpper(option))
+			}
+			if option == "idle" {
+				idle := time.Duration(ms) * time.Millisecond
+				opts.Idle = &idle
+			} else {
+				t := time.UnixMilli(ms)
+				opts.Time = &t
+			}
+		case "retrycount":
+			retryCount, err := strconv.ParseUint(params.Command[i], 10, 64)
+			if err != nil {
+				return nil, errors.New("RETRYCOUNT must be a non-negative integer")
+			}
+			opts.RetryCount = &retryCount
+		case "lastid":
+			lastID, err := ParseID(params.Command[i], 0)
+			if err != nil {
+				return nil, err
+			}
+			opts.LastID = &lastID
+		default:
+			return nil, errors.New("syntax error")
+		}
+	}
+
+	stream, err := getStream(params, key)
+	if err != nil {
+		return nil, err
+	}
+	if stream == nil {
+		return nil, fmt.Errorf("NOGROUP No such key '%s' or consumer group '%s'", key, group)
+	}
+	entries, err := stream.Claim(group, consumer, minIdle, ids, opts, params.GetClock().Now())
+	if err != nil {
+		return nil, fmt.Errorf("NOGROUP No such key '%s' or consumer group '%s'", key, group)
+	}
+
+	if err = params.SetValues(params.Context, map[string]interface{}{key: stream}); err != nil {
+		return nil, err
+	}
+
+	if opts.JustID {
+		claimed := make([]ID, len(entries))
+		for i, entry := range entries {
+			claimed[i] = entry.ID
+		}
+		return []byte(encodeIDs(claimed)), nil
+	}
+	return []byte(encodeEntries(entries)), nil
+}
+
+func handleXAUTOCLAIM(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := xclaimKeyFunc(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key, group, consumer := keys.WriteKeys[0], params.Command[2], params.Command[3]
+
+	minIdle, err := parseMinIdleTime(params.Command[4])
+	if err != nil {
+		return nil, err
+	}
+	start, err := parseRangeID(params.Command[5], false)
+	if err != nil {
+		return nil, err
+	}
+
+	count := 100
+	justID := false
+	for i := 6; i < len(params.Command); i++ {
+		switch strings.ToLower(params.Command[i]) {
+		case "justid":
+			justID = true
+		case "count":
+			if i+1 >= len(params.Command) {
+				return nil, errors.New("syntax error")
+			}
+			i++
+			if count, err = strconv.Atoi(params.Command[i]); err != nil || count <= 0 {
+				return nil, errors.New("COUNT must be a positive integer")
+			}
+		default:
+			return nil, errors.New("syntax error")
+		}
+	}
+
+	stream, err := getStream(params, key)
+	if err != nil {
+		return nil, err
+	}
+	if stream == nil {
+		return nil, fmt.Errorf("NOGROUP No such key '%s' or consumer group '%s'", key, group)
+	}
+	next, entries, deleted, err := stream.AutoClaim(group, consumer, minIdle, start, count, justID, params.GetClock().Now())
+	if err != nil {
+		return nil, fmt.Errorf("NOGROUP No such key '%s' or consumer group '%s'", key, group)
+	}
+
+	if err = params.SetValues(params.Context, map[string]interface{}{key: stream}); err != nil {
+		return nil, err
+	}
+
+	cursor := next.String()
+	res := fmt.Sprintf("*3\r\n$%d\r\n%s\r\n", len(cursor), cursor)
+	if justID {
+		claimed := make([]ID, len(entries))
+		for i, entry := range entries {
+			claimed[i] = entry.ID
+		}
+		res += encodeIDs(claimed)
+	} else {
+		res += encodeEntries(entries)
+	}
+	res += encodeIDs(deleted)
+	return []byte(res), nil
+}
+
+// encodeEntry returns the RESP array of the entry, or a null array if entry is nil.
+func encodeEntry(entry *Entry) string {
+	if entry == nil {
+		return "*-1\r\n"
+	}
+	return strings.TrimPrefix(encodeEntries([]Entry{*entry}), "*1\r\n")
+}
+
+// encodeField returns a bulk string field name followed by the value.
+func encodeField(name string, value string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n%s", len(name), name, value)
+}
+
+func bulkString(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func handleXINFOStream(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := xinfoKeyFunc(3)(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key := keys.ReadKeys[0]
+
+	stream, err := getStream(params, key)
+	if err != nil {
+		return nil, err
+	}
+	if stream == nil {
+		return nil, errors.New("no such key")
+	}
+
+	info := stream.Info()
+	res := "*14\r\n"
+	res += encodeField("length", fmt.Sprintf(":%d\r\n", info.Length))
+	res += encodeField("last-generated-id", bulkString(info.LastID.String()))
+	res += encodeField("max-deleted-entry-id", bulkString(info.MaxDeletedID.String()))
+	res += encodeField("entries-added", fmt.Sprintf(":%d\r\n", info.EntriesAdded))
+	res += encodeField("groups", fmt.Sprintf(":%d\r\n", info.Groups))
+	res += encodeField("first-entry", encodeEntry(info.FirstEntry))
+	res += encodeField("last-entry", encodeEntry(info.LastEntry))
+	return []byte(res), nil
+}
+
+func handleXINFOGroups(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := xinfoKeyFunc(3)(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key := keys.ReadKeys[0]
+
+	stream, err := getStream(params, key)
+	if err != nil {
+		return nil, err
+	}
+	if stream == nil {
+		return nil, errors.New("no such key")
+	}
+
+	groups := stream.Groups()
+	res := fmt.Sprintf("*%d\r\n", len(groups))
+	for _, group := range groups {
+		res += "*12\r\n"
+		res += encodeField("name", bulkString(group.Name))
+		res += encodeField("consumers", fmt.Sprintf(":%d\r\n", group.Consumers))
+		res += encodeField("pending", fmt.Sprintf(":%d\r\n", group.Pending))
+		res += encodeField("last-delivered-id", bulkString(group.LastDeliveredID.String()))
+		res += encodeField("entries-read", fmt.Sprintf(":%d\r\n", group.EntriesRead))
+		res += encodeField("lag", fmt.Sprintf(":%d\r\n", group.Lag))
+	}
+	return []byte(res), nil
+}
+
+func handleXINFOConsumers(params internal.HandlerFuncParams) ([]byte, error) {
+	keys, err := xinfoKeyFunc(4)(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	key, group := keys.ReadKeys[0], params.Command[3]
+
+	stream, err := getStream(params, key)
+	if err != nil {
+		return nil, err
+	}
+	if stream == nil {
+		return nil, errors.New("no such key")
+	}
+
+	consumers, err := stream.Consumers(group, params.GetClock().Now())
+	if err != nil {
+		return nil, groupError(err, key, group)
+	}
+
+	res := fmt.Sprintf("*%d\r\n", len(consumers))
+	for _, consumer := range consumers {
+		res += "*8\r\n"
+		res += encodeField("name", bulkString(consumer.Name))
+		res += encodeField("pending", fmt.Sprintf(":%d\r\n", consumer.Pending))
+		res += encodeField("idle", fmt.Sprintf(":%d\r\n", consumer.Idle))
+		res += encodeField("inactive", fmt.Sprintf(":%d\r\n", consumer.Inactive))
+	}
+	return []byte(res), nil
+}
+
+func Commands() []internal.Command {
+	return []internal.Command{
+		{
+			Command:    "xadd",
+			Module:     constants.StreamModule,
+			Categories: []string{constants.StreamCategory, constants.WriteCategory, constants.FastCategory},
+			Description: `(XADD key [NOMKSTREAM] [MAXLEN | MINID [= | ~] threshold [LIMIT count]] * | id field value [field value ...])
+Appends an entry to the stream at key and returns its ID. The stream is created if it does not exist, unless NOMKSTREAM is provided.
+With *, the ID is generated from the current time. An explicit ID must be greater than the ID of the last entry in the stream.
+MAXLEN and MINID trim the stream after the entry is added.`,
+			Sync:              true,
+			KeyExtractionFunc: xaddKeyFunc,
+			HandlerFunc:       handleXADD,
+		},
+		{
+			Command:    "xrange",
+			Module:     constants.StreamModule,
+			Categories: []string{constants.StreamCategory, constants.ReadCategory, constants.SlowCategory},
+			Description: `(XRANGE key start end [COUNT count])
+Returns the entries of the stream with IDs between start and end. "-" and "+" are the smallest and largest possible IDs.
+An ID prefixed with "(" is excluded from the range.`,
+			Sync:              false,
+			KeyExtractionFunc: xrangeKeyFunc,
+			HandlerFunc:       handleXRANGE,
+		},
+		{
+			Command:    "xrevrange",
+			Module:     constants.StreamModule,
+			Categories: []string{constants.StreamCategory, constants.ReadCategory, constants.SlowCategory},
+			Description: `(XREVRANGE key end start [COUNT count])
+Returns the entries of the stream with IDs between end and start, from the largest ID to the smallest.`,
+			Sync:              false,
+			KeyExtractionFunc: xrangeKeyFunc,
+			HandlerFunc:       handleXREVRANGE,
+		},
+		{
+			Command:           "xlen",
+			Module:            constants.StreamModule,
+			Categories:        []string{constants.StreamCategory, constants.ReadCategory, constants.FastCategory},
+			Description:       "(XLEN key) Returns the number of entries in the stream.",
+			Sync:              false,
+			KeyExtractionFunc: xlenKeyFunc,
+			HandlerFunc:       handleXLEN,
+		},
+		{
+			Command:    "xdel",
+			Module:     constants.StreamModule,
+			Categories: []string{constants.StreamCategory, constants.WriteCategory, constants.FastCategory},
+			Description: `(XDEL key id [id ...])
+Removes the entries with the IDs from the stream and returns the number of entries removed.`,
+			Sync:              true,
+			KeyExtractionFunc: xdelKeyFunc,
+			HandlerFunc:       handleXDEL,
+		},
+		{
+			Command:    "xtrim",
+			Module:     constants.StreamModule,
+			Categories: []string{constants.StreamCategory, constants.WriteCategory, constants.SlowCategory},
+			Description: `(XTRIM key MAXLEN | MINID [= | ~] threshold [LIMIT count])
+Removes the oldest entries of the stream. MAXLEN keeps at most threshold entries and MINID removes the entries with IDs
+smaller than threshold. Returns the number of entries removed.`,
+			Sync:              true,
+			KeyExtractionFunc: xtrimKeyFunc,
+			HandlerFunc:       handleXTRIM,
+		},
+		{
+			Command: "xread",
+			Module:  constants.StreamModule,
+			Categories: []string{
+				constants.StreamCategory, constants.ReadCategory, constants.SlowCategory, constants.BlockingCategory,
+			},
+			Description: `(XREAD [COUNT count] [BLOCK milliseconds] STREAMS key [key ...] id [id ...])
+Returns the entries with IDs greater than the ID provided for each stream. "$" is the ID of the last entry in the stream.
+With BLOCK, waits up to milliseconds for new entries when there are none. BLOCK 0 waits indefinitely.`,
+			Sync:              false,
+			KeyExtractionFunc: xreadKeyFunc,
+			HandlerFunc:       handleXREAD,
+		},
+		{
+			Command:     "xgroup",
+			Module:      constants.StreamModule,
+			Categories:  []string{},
+			Description: "Consumer group commands",
+			KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+				return internal.KeyExtractionFuncResult{
+					Channels: make([]string, 0), ReadKeys: make([]string, 0), WriteKeys: make([]string, 0),
+				}, nil
+			},
+			SubCommands: []internal.SubCommand{
+				{
+					Command:    "create",
+					Module:     constants.StreamModule,
+					Categories: []string{constants.StreamCategory, constants.WriteCategory, constants.SlowCategory},
+					Description: `(XGROUP CREATE key group id | $ [MKSTREAM] [ENTRIESREAD entries-read])
+Creates a consumer group that delivers the entries with IDs greater than id. "$" is the ID of the last entry in the stream.
+MKSTREAM creates an empty stream if the key does not exist.`,
+					Sync:              true,
+					KeyExtractionFunc: xgroupKeyFunc(5, 8),
+					HandlerFunc:       handleXGROUPCreate,
+				},
+				{
+					Command:           "destroy",
+					Module:            constants.StreamModule,
+					Categories:        []string{constants.StreamCategory, constants.WriteCategory, constants.SlowCategory},
+					Description:       `(XGROUP DESTROY key group) Removes the consumer group and its pending entries.`,
+					Sync:              true,
+					KeyExtractionFunc: xgroupKeyFunc(4, 4),
+					HandlerFunc:       handleXGROUPDestroy,
+				},
+				{
+					Command:    "setid",
+					Module:     constants.StreamModule,
+					Categories: []string{constants.StreamCategory, constants.WriteCategory, constants.SlowCategory},
+					Description: `(XGROUP SETID key group id | $ [ENTRIESREAD entries-read])
+Sets the ID of the last entry delivered to the consumer group.`,
+					Sync:              true,
+					KeyExtractionFunc: xgroupKeyFunc(5, 7),
+					HandlerFunc:       handleXGROUPSetID,
+				},
+				{
+					Command:           "createconsumer",
+					Module:            constants.StreamModule,
+					Categories:        []string{constants.StreamCategory, constants.WriteCategory, constants.SlowCategory},
+					Description:       `(XGROUP CREATECONSUMER key group consumer) Creates a consumer in the consumer group.`,
+					Sync:              true,
+					KeyExtractionFunc: xgroupKeyFunc(5, 5),
+					HandlerFunc:       handleXGROUPCreateConsumer,
+				},
+				{
+					Command:    "delconsumer",
+					Module:     constants.StreamModule,
+					Categories: []string{constants.StreamCategory, constants.WriteCategory, constants.SlowCategory},
+					Description: `(XGROUP DELCONSUMER key group consumer)
+Removes the consumer and its pending entries from the consumer group. Returns the number of pending entries removed.`,
+					Sync:              true,
+					KeyExtractionFunc: xgroupKeyFunc(5, 5),
+					HandlerFunc:       handleXGROUPDelConsumer,
+				},
+			},
+		},
+		{
+			Command: "xreadgroup",
+			Module:  constants.StreamModule,
+			Categories: []string{
+				constants.StreamCategory, constants.WriteCategory, constants.SlowCategory, constants.BlockingCategory,
+			},
+			Description: `(XREADGROUP GROUP group consumer [COUNT count] [BLOCK milliseconds] [NOACK] STREAMS key [key ...] id [id ...])
+Reads the entries of the streams as the consumer of the group. ">" delivers the entries that were not delivered to the group yet
+and adds them to the pending entries of the consumer, unless NOACK is provided. Any other ID returns the pending entries
+of the consumer with IDs greater than the ID. With BLOCK, waits up to milliseconds for new entries when there are none.`,
+			Sync:              true,
+			KeyExtractionFunc: xreadgroupKeyFunc,
+			HandlerFunc:       handleXREADGROUP,
+		},
+		{
+			Command:    "xack",
+			Module:     constants.StreamModule,
+			Categories: []string{constants.StreamCategory, constants.WriteCategory, constants.FastCategory},
+			Description: `(XACK key group id [id ...])
+Removes the entries from the pending entries of the consumer group. Returns the number of entries acknowledged.`,
+			Sync:              true,
+			KeyExtractionFunc: xackKeyFunc,
+			HandlerFunc:       handleXACK,
+		},
+		{
+			Command:    "xpending",
+			Module:     constants.StreamModule,
+			Categories: []string{constants.StreamCategory, constants.ReadCategory, constants.SlowCategory},
+			Description: `(XPENDING key group [[IDLE min-idle-time] start end count [consumer]])
+Returns a summary of the pending entries of the consumer group, or the pending entries with IDs between start and end
+with their consumer, idle time and delivery count.`,
+			Sync:              false,
+			KeyExtractionFunc: xpendingKeyFunc,
+			HandlerFunc:       handleXPENDING,
+		},
+		{
+			Command:    "xclaim",
+			Module:     constants.StreamModule,
+			Categories: []string{constants.StreamCategory, constants.WriteCategory, constants.FastCategory},
+			Description: `(XCLAIM key group consumer min-idle-time id [id ...] [IDLE ms] [TIME unix-time-milliseconds] [RETRYCOUNT count] [FORCE] [JUSTID] [LASTID lastid])
+Transfers the pending entries with the IDs that were idle for at least min-idle-time to the consumer.`,
+			Sync:              true,
+			KeyExtractionFunc: xclaimKeyFunc,
+			HandlerFunc:       handleXCLAIM,
+		},
+		{
+			Command:    "xautoclaim",
+			Module:     constants.StreamModule,
+			Categories: []string{constants.StreamCategory, constants.WriteCategory, constants.FastCategory},
+			Description: `(XAUTOCLAIM key group consumer min-idle-time start [COUNT count] [JUSTID])
+Transfers the pending entries with IDs greater than or equal to start that were idle for at least min-idle-time to the consumer.
+Returns the ID to start the next call from, the claimed entries and the IDs of the pending entries that were deleted from the stream.`,
+			Sync:              true,
+			KeyExtractionFunc: xclaimKeyFunc,
+			HandlerFunc:       handleXAUTOCLAIM,
+		},
+		{
+			Command:     "xinfo",
+			Module:      constants.StreamModule,
+			Categories:  []string{},
+			Description: "Stream introspection commands",
+			KeyExtractionFunc: func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+				return internal.KeyExtractionFuncResult{
+					Channels: make([]string, 0), ReadKeys: make([]string, 0), WriteKeys: make([]string, 0),
+				}, nil
+			},
+			SubCommands: []internal.SubCommand{
+				{
+					Command:           "stream",
+					Module:            constants.StreamModule,
+					Categories:        []string{constants.StreamCategory, constants.ReadCategory, constants.SlowCategory},
+					Description:       `(XINFO STREAM key) Returns information about the stream.`,
+					Sync:              false,
+					KeyExtractionFunc: xinfoKeyFunc(3),
+					HandlerFunc:       handleXINFOStream,
+				},
+				{
+					Command:           "groups",
+					Module:            constants.StreamModule,
+					Categories:        []string{constants.StreamCategory, constants.ReadCategory, constants.SlowCategory},
+					Description:       `(XINFO GROUPS key) Returns information about the consumer groups of the stream.`,
+					Sync:              false,
+					KeyExtractionFunc: xinfoKeyFunc(3),
+					HandlerFunc:       handleXINFOGroups,
+				},
+				{
+					Command:           "consumers",
+					Module:            constants.StreamModule,
+					Categories:        []string{constants.StreamCategory, constants.ReadCategory, constants.SlowCategory},
+					Description:       `(XINFO CONSUMERS key group) Returns information about the consumers of the consumer group.`,
+					Sync:              false,
+					KeyExtractionFunc: xinfoKeyFunc(4),
+					HandlerFunc:       handleXINFOConsumers,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,782 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stream_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/echovault/sugardb/internal"
+	"github.com/echovault/sugardb/internal/config"
+	"github.com/echovault/sugardb/internal/constants"
+	"github.com/echovault/sugardb/sugardb"
+	"github.com/tidwall/resp"
+)
+
+// format returns the response as a string. Arrays are formatted as "[element element ...]" and nulls as "nil".
+func format(v resp.Value) string {
+	if v.IsNull() {
+		return "nil"
+	}
+	if v.Type() == resp.Array {
+		elements := make([]string, len(v.Array()))
+		for i, element := range v.Array() {
+			elements[i] = format(element)
+		}
+		return "[" + strings.Join(elements, " ") + "]"
+	}
+	return v.String()
+}
+
+func Test_Stream(t *testing.T) {
+	port, err := internal.GetFreePort()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	mockServer, err := sugardb.NewSugarDB(
+		sugardb.WithConfig(config.Config{
+			BindAddr:       "localhost",
+			Port:           uint16(port),
+			DataDir:        "",
+			EvictionPolicy: constants.NoEviction,
+		}),
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	go func() {
+		mockServer.Start()
+	}()
+
+	t.Cleanup(func() {
+		mockServer.ShutDown()
+	})
+
+	type step struct {
+		command []string
+		want    string // The expected response, as returned by format.
+		wantErr string // The expected error substring when the response is an error.
+	}
+
+	send := func(client *resp.Conn, command []string) (resp.Value, error) {
+		values := make([]resp.Value, len(command))
+		for i, token := range command {
+			values[i] = resp.StringValue(token)
+		}
+		if err := client.WriteArray(values); err != nil {
+			return resp.Value{}, err
+		}
+		res, _, err := client.ReadValue()
+		return res, err
+	}
+
+	runSteps := func(t *testing.T, client *resp.Conn, steps []step) {
+		for _, step := range steps {
+			res, err := send(client, step.command)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if step.wantErr != "" {
+				if res.Error() == nil || !strings.Contains(res.Error().Error(), step.wantErr) {
+					t.Errorf("%v: expected error \"%s\", got %+v", step.command, step.wantErr, res)
+				}
+				continue
+			}
+			if res.Error() != nil {
+				t.Errorf("%v: unexpected error %v", step.command, res.Error())
+				continue
+			}
+			if got := format(res); got != step.want {
+				t.Errorf("%v: expected response \"%s\", got \"%s\"", step.command, step.want, got)
+			}
+		}
+	}
+
+	connect := func(t *testing.T) *resp.Conn {
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			_ = conn.Close()
+		})
+		return resp.NewConn(conn)
+	}
+
+	t.Run("Test_HandleXADD", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		tests := []struct {
+			name  string
+			steps []step
+		}{
+			{
+				name: "1. Generate the IDs from the current time",
+				steps: []step{
+					{command: []string{"XADD", "XaddKey1", "*", "a", "1"}, want: "1136189045000-0"},
+					{command: []string{"XADD", "XaddKey1", "*", "b", "2", "c", "3"}, want: "1136189045000-1"},
+					{command: []string{"XLEN", "XaddKey1"}, want: "2"},
+					{
+						command: []string{"XRANGE", "XaddKey1", "-", "+"},
+						want:    "[[1136189045000-0 [a 1]] [1136189045000-1 [b 2 c 3]]]",
+					},
+				},
+			},
+			{
+				name: "2. Add entries with explicit IDs",
+				steps: []step{
+					{command: []string{"XADD", "XaddKey2", "1-1", "a", "1"}, want: "1-1"},
+					{command: []string{"XADD", "XaddKey2", "1-1", "a", "1"}, wantErr: "equal or smaller than the target stream top item"},
+					{command: []string{"XADD", "XaddKey2", "0-5", "a", "1"}, wantErr: "equal or smaller than the target stream top item"},
+					{command: []string{"XADD", "XaddKey2", "1-*", "a", "1"}, want: "1-2"},
+					{command: []string{"XADD", "XaddKey2", "5", "a", "1"}, want: "5-0"},
+					{command: []string{"XADD", "XaddKey2", "5-*", "a", "1"}, want: "5-1"},
+					{command: []string{"XADD", "XaddKey2", "7-*", "a", "1"}, want: "7-0"},
+					{command: []string{"XADD", "XaddKey3", "0-0", "a", "1"}, wantErr: "must be greater than 0-0"},
+					{command: []string{"XADD", "XaddKey3", "0-*", "a", "1"}, want: "0-1"},
+					{command: []string{"XADD", "XaddKey3", "a-1", "a", "1"}, wantErr: "invalid stream ID"},
+				},
+			},
+			{
+				name: "3. Don't create the stream with NOMKSTREAM",
+				steps: []step{
+					{command: []string{"XADD", "XaddKey4", "NOMKSTREAM", "*", "a", "1"}, want: "nil"},
+					{command: []string{"XLEN", "XaddKey4"}, want: "0"},
+					{command: []string{"XADD", "XaddKey4", "1-1", "a", "1"}, want: "1-1"},
+					{command: []string{"XADD", "XaddKey4", "NOMKSTREAM", "2-1", "a", "1"}, want: "2-1"},
+					{command: []string{"XLEN", "XaddKey4"}, want: "2"},
+				},
+			},
+			{
+				name: "4. Trim the stream with MAXLEN and MINID",
+				steps: []step{
+					{command: []string{"XADD", "XaddKey5", "MAXLEN", "2", "1-1", "a", "1"}, want: "1-1"},
+					{command: []string{"XADD", "XaddKey5", "MAXLEN", "2", "2-1", "a", "2"}, want: "2-1"},
+					{command: []string{"XADD", "XaddKey5", "MAXLEN", "=", "2", "3-1", "a", "3"}, want: "3-1"},
+					{command: []string{"XRANGE", "XaddKey5", "-", "+"}, want: "[[2-1 [a 2]] [3-1 [a 3]]]"},
+					{command: []string{"XADD", "XaddKey5", "MINID", "~", "3", "LIMIT", "5", "4-1", "a", "4"}, want: "4-1"},
+					{command: []string{"XRANGE", "XaddKey5", "-", "+"}, want: "[[3-1 [a 3]] [4-1 [a 4]]]"},
+				},
+			},
+			{
+				name: "5. Return an error when the arguments are invalid",
+				steps: []step{
+					{command: []string{"XADD", "XaddKey6", "*", "a"}, wantErr: constants.WrongArgsResponse},
+					{command: []string{"XADD", "XaddKey6", "*", "a", "1", "b"}, wantErr: constants.WrongArgsResponse},
+					{command: []string{"XADD", "XaddKey6", "MAXLEN", "-1", "*", "a", "1"}, wantErr: "MAXLEN must be a non-negative integer"},
+					{command: []string{"XADD", "XaddKey6", "MAXLEN", "1", "LIMIT", "1", "*", "a", "1"}, wantErr: "LIMIT cannot be used without the special ~ option"},
+					{command: []string{"XLEN", "XaddKey6"}, want: "0"},
+				},
+			},
+			{
+				name: "6. Return an error when the key is not a stream",
+				steps: []step{
+					{command: []string{"SET", "XaddKey7", "value"}, want: "OK"},
+					{command: []string{"XADD", "XaddKey7", "*", "a", "1"}, wantErr: "value at XaddKey7 is not a stream"},
+					{command: []string{"TYPE", "XaddKey1"}, want: "stream"},
+				},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				runSteps(t, client, test.steps)
+			})
+		}
+	})
+
+	t.Run("Test_HandleXRANGE", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"XADD", "XrangeKey1", "1-1", "a", "1"}, want: "1-1"},
+			{command: []string{"XADD", "XrangeKey1", "1-2", "b", "2"}, want: "1-2"},
+			{command: []string{"XADD", "XrangeKey1", "2-0", "c", "3"}, want: "2-0"},
+			{command: []string{"XADD", "XrangeKey1", "3-0", "d", "4"}, want: "3-0"},
+		})
+
+		tests := []struct {
+			name  string
+			steps []step
+		}{
+			{
+				name: "1. Return all the entries",
+				steps: []step{
+					{
+						command: []string{"XRANGE", "XrangeKey1", "-", "+"},
+						want:    "[[1-1 [a 1]] [1-2 [b 2]] [2-0 [c 3]] [3-0 [d 4]]]",
+					},
+					{
+						command: []string{"XREVRANGE", "XrangeKey1", "+", "-"},
+						want:    "[[3-0 [d 4]] [2-0 [c 3]] [1-2 [b 2]] [1-1 [a 1]]]",
+					},
+				},
+			},
+			{
+				name: "2. Return the entries with IDs matching a partial ID",
+				steps: []step{
+					{command: []string{"XRANGE", "XrangeKey1", "1", "1"}, want: "[[1-1 [a 1]] [1-2 [b 2]]]"},
+					{command: []string{"XRANGE", "XrangeKey1", "2", "+"}, want: "[[2-0 [c 3]] [3-0 [d 4]]]"},
+				},
+			},
+			{
+				name: "3. Exclude the IDs prefixed with (",
+				steps: []step{
+					{command: []string{"XRANGE", "XrangeKey1", "(1-1", "(3-0"}, want: "[[1-2 [b 2]] [2-0 [c 3]]]"},
+					{command: []string{"XREVRANGE", "XrangeKey1", "(3-0", "(1-2"}, want: "[[2-0 [c 3]]]"},
+				},
+			},
+			{
+				name: "4. Limit the number of entries with COUNT",
+				steps: []step{
+					{command: []string{"XRANGE", "XrangeKey1", "-", "+", "COUNT", "2"}, want: "[[1-1 [a 1]] [1-2 [b 2]]]"},
+					{command: []string{"XREVRANGE", "XrangeKey1", "+", "-", "COUNT", "1"}, want: "[[3-0 [d 4]]]"},
+					{command: []string{"XRANGE", "XrangeKey1", "-", "+", "COUNT", "0"}, want: "[]"},
+				},
+			},
+			{
+				name: "5. Return an empty array when there are no entries in the range",
+				steps: []step{
+					{command: []string{"XRANGE", "XrangeKey1", "3-1", "+"}, want: "[]"},
+					{command: []string{"XRANGE", "XrangeKey1", "+", "-"}, want: "[]"},
+					{command: []string{"XRANGE", "XrangeKey2", "-", "+"}, want: "[]"},
+				},
+			},
+			{
+				name: "6. Return an error when the arguments are invalid",
+				steps: []step{
+					{command: []string{"XRANGE", "XrangeKey1", "-"}, wantErr: constants.WrongArgsResponse},
+					{command: []string{"XRANGE", "XrangeKey1", "x", "+"}, wantErr: "invalid stream ID"},
+					{command: []string{"XRANGE", "XrangeKey1", "-", "+", "LIMIT", "1"}, wantErr: "syntax error"},
+					{command: []string{"XRANGE", "XrangeKey1", "-", "+", "COUNT", "x"}, wantErr: "COUNT must be an integer"},
+				},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				runSteps(t, client, test.steps)
+			})
+		}
+	})
+
+	t.Run("Test_HandleXLEN", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"XLEN", "XlenKey1"}, want: "0"},
+			{command: []string{"XADD", "XlenKey1", "*", "a", "1"}, want: "1136189045000-0"},
+			{command: []string{"XLEN", "XlenKey1"}, want: "1"},
+			{command: []string{"SET", "XlenKey2", "value"}, want: "OK"},
+			{command: []string{"XLEN", "XlenKey2"}, wantErr: "value at XlenKey2 is not a stream"},
+			{command: []string{"XLEN"}, wantErr: constants.WrongArgsResponse},
+		})
+	})
+
+	t.Run("Test_HandleXDEL", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"XADD", "XdelKey1", "1-1", "a", "1"}, want: "1-1"},
+			{command: []string{"XADD", "XdelKey1", "2-1", "a", "2"}, want: "2-1"},
+			{command: []string{"XADD", "XdelKey1", "3-1", "a", "3"}, want: "3-1"},
+			{command: []string{"XDEL", "XdelKey1", "1-1", "3-1", "4-1"}, want: "2"},
+			{command: []string{"XRANGE", "XdelKey1", "-", "+"}, want: "[[2-1 [a 2]]]"},
+			// Deleting the last entry doesn't allow reusing its ID.
+			{command: []string{"XADD", "XdelKey1", "3-1", "a", "3"}, wantErr: "equal or smaller than the target stream top item"},
+			{command: []string{"XDEL", "XdelKey2", "1-1"}, want: "0"},
+			{command: []string{"XDEL", "XdelKey1", "x"}, wantErr: "invalid stream ID"},
+			{command: []string{"XDEL", "XdelKey1"}, wantErr: constants.WrongArgsResponse},
+		})
+	})
+
+	t.Run("Test_HandleXTRIM", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"XADD", "XtrimKey1", "1-1", "a", "1"}, want: "1-1"},
+			{command: []string{"XADD", "XtrimKey1", "2-1", "a", "2"}, want: "2-1"},
+			{command: []string{"XADD", "XtrimKey1", "3-1", "a", "3"}, want: "3-1"},
+			{command: []string{"XADD", "XtrimKey1", "4-1", "a", "4"}, want: "4-1"},
+			{command: []string{"XADD", "XtrimKey1", "5-1", "a", "5"}, want: "5-1"},
+			{command: []string{"XTRIM", "XtrimKey1", "MAXLEN", "~", "1", "LIMIT", "1"}, want: "1"},
+			{command: []string{"XTRIM", "XtrimKey1", "MAXLEN", "3"}, want: "1"},
+			{command: []string{"XTRIM", "XtrimKey1", "MINID", "=", "4-1"}, want: "1"},
+			{command: []string{"XTRIM", "XtrimKey1", "MINID", "1"}, want: "0"},
+			{command: []string{"XRANGE", "XtrimKey1", "-", "+"}, want: "[[4-1 [a 4]] [5-1 [a 5]]]"},
+			{command: []string{"XTRIM", "XtrimKey2", "MAXLEN", "0"}, want: "0"},
+			{command: []string{"XTRIM", "XtrimKey1", "SIZE", "1"}, wantErr: "syntax error"},
+			{command: []string{"XTRIM", "XtrimKey1", "MAXLEN", "1", "EXTRA"}, wantErr: "syntax error"},
+			{command: []string{"XTRIM", "XtrimKey1", "MAXLEN"}, wantErr: constants.WrongArgsResponse},
+		})
+	})
+
+	t.Run("Test_HandleXREAD", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"XADD", "XreadKey1", "1-1", "a", "1"}, want: "1-1"},
+			{command: []string{"XADD", "XreadKey1", "2-1", "a", "2"}, want: "2-1"},
+			{command: []string{"XADD", "XreadKey2", "1-1", "b", "1"}, want: "1-1"},
+		})
+
+		tests := []struct {
+			name  string
+			steps []step
+		}{
+			{
+				name: "1. Return the entries after the IDs",
+				steps: []step{
+					{
+						command: []string{"XREAD", "STREAMS", "XreadKey1", "XreadKey2", "0", "0"},
+						want:    "[[XreadKey1 [[1-1 [a 1]] [2-1 [a 2]]]] [XreadKey2 [[1-1 [b 1]]]]]",
+					},
+					{
+						command: []string{"XREAD", "STREAMS", "XreadKey1", "XreadKey2", "1-1", "1-1"},
+						want:    "[[XreadKey1 [[2-1 [a 2]]]]]",
+					},
+					{
+						command: []string{"XREAD", "COUNT", "1", "STREAMS", "XreadKey1", "0-0"},
+						want:    "[[XreadKey1 [[1-1 [a 1]]]]]",
+					},
+				},
+			},
+			{
+				name: "2. Return nil when there are no new entries",
+				steps: []step{
+					{command: []string{"XREAD", "STREAMS", "XreadKey1", "XreadKey2", "$", "$"}, want: "nil"},
+					{command: []string{"XREAD", "STREAMS", "XreadKey3", "0"}, want: "nil"},
+				},
+			},
+			{
+				name: "3. Return nil when the block timeout expires",
+				steps: []step{
+					{command: []string{"XREAD", "BLOCK", "50", "STREAMS", "XreadKey1", "$"}, want: "nil"},
+				},
+			},
+			{
+				name: "4. Don't block inside a transaction",
+				steps: []step{
+					{command: []string{"MULTI"}, want: "OK"},
+					{command: []string{"XREAD", "BLOCK", "0", "STREAMS", "XreadKey1", "$"}, want: "QUEUED"},
+					{command: []string{"EXEC"}, want: "[nil]"},
+				},
+			},
+			{
+				name: "5. Return an error when the arguments are invalid",
+				steps: []step{
+					{command: []string{"XREAD", "STREAMS", "XreadKey1"}, wantErr: "unbalanced XREAD list of streams"},
+					{command: []string{"XREAD", "XreadKey1", "0"}, wantErr: constants.WrongArgsResponse},
+					{command: []string{"XREAD", "BLOCK", "-1", "STREAMS", "XreadKey1", "0"}, wantErr: "timeout must be a non-negative integer"},
+					{command: []string{"XREAD", "FOO", "1", "STREAMS", "XreadKey1", "0"}, wantErr: "syntax error"},
+					{command: []string{"XREAD", "STREAMS", "XreadKey1", "x"}, wantErr: "invalid stream ID"},
+				},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				runSteps(t, client, test.steps)
+			})
+		}
+	})
+
+	t.Run("Test_HandleXREAD_Block", func(t *testing.T) {
+		t.Parallel()
+		reader := connect(t)
+		writer := connect(t)
+
+		runSteps(t, writer, []step{
+			{command: []string{"XADD", "XreadBlockKey1", "1-1", "a", "1"}, want: "1-1"},
+		})
+
+		done := make(chan resp.Value)
+		go func() {
+			res, err := send(reader, []string{"XREAD", "BLOCK", "0", "STREAMS", "XreadBlockKey1", "XreadBlockKey2", "$", "$"})
+			if err != nil {
+				t.Error(err)
+			}
+			done <- res
+		}()
+
+		// Give the reader time to block before adding the entry.
+		time.Sleep(100 * time.Millisecond)
+		select {
+		case res := <-done:
+			t.Fatalf("expected XREAD to block, got %s", format(res))
+		default:
+		}
+
+		runSteps(t, writer, []step{
+			{command: []string{"XADD", "XreadBlockKey2", "5-1", "b", "2"}, want: "5-1"},
+		})
+
+		select {
+		case res := <-done:
+			if got, want := format(res), "[[XreadBlockKey2 [[5-1 [b 2]]]]]"; got != want {
+				t.Errorf("expected response \"%s\", got \"%s\"", want, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("timed out waiting for XREAD to return")
+		}
+	})
+
+	t.Run("Test_HandleXGROUP", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"XADD", "XgroupKey1", "1-1", "a", "1"}, want: "1-1"},
+			{command: []string{"XADD", "XgroupKey1", "2-1", "a", "2"}, want: "2-1"},
+			{command: []string{"SET", "XgroupKey3", "value"}, want: "OK"},
+		})
+
+		tests := []struct {
+			name  string
+			steps []step
+		}{
+			{
+				name: "1. Create consumer groups",
+				steps: []step{
+					{command: []string{"XGROUP", "CREATE", "XgroupKey1", "g1", "0"}, want: "OK"},
+					{command: []string{"XGROUP", "CREATE", "XgroupKey1", "g2", "$", "ENTRIESREAD", "2"}, want: "OK"},
+					{
+						command: []string{"XINFO", "GROUPS", "XgroupKey1"},
+						want: "[[name g1 consumers 0 pending 0 last-delivered-id 0-0 entries-read 0 lag 2] " +
+							"[name g2 consumers 0 pending 0 last-delivered-id 2-1 entries-read 2 lag 0]]",
+					},
+					{command: []string{"XGROUP", "CREATE", "XgroupKey1", "g1", "$"}, wantErr: "BUSYGROUP"},
+				},
+			},
+			{
+				name: "2. Create the stream with MKSTREAM",
+				steps: []step{
+					{command: []string{"XGROUP", "CREATE", "XgroupKey2", "g1", "$"}, wantErr: "requires the key to exist"},
+					{command: []string{"XGROUP", "CREATE", "XgroupKey2", "g1", "$", "MKSTREAM"}, want: "OK"},
+					{command: []string{"TYPE", "XgroupKey2"}, want: "stream"},
+					{command: []string{"XLEN", "XgroupKey2"}, want: "0"},
+				},
+			},
+			{
+				name: "3. Set the last delivered ID",
+				steps: []step{
+					{command: []string{"XGROUP", "CREATE", "XgroupKey1", "g3", "$"}, want: "OK"},
+					{command: []string{"XGROUP", "SETID", "XgroupKey1", "g3", "1-1"}, want: "OK"},
+					{command: []string{"XREADGROUP", "GROUP", "g3", "alice", "STREAMS", "XgroupKey1", ">"}, want: "[[XgroupKey1 [[2-1 [a 2]]]]]"},
+					{command: []string{"XGROUP", "SETID", "XgroupKey1", "g9", "0"}, wantErr: "NOGROUP"},
+				},
+			},
+			{
+				name: "4. Create and delete consumers",
+				steps: []step{
+					{command: []string{"XGROUP", "CREATE", "XgroupKey1", "g4", "0"}, want: "OK"},
+					{command: []string{"XGROUP", "CREATECONSUMER", "XgroupKey1", "g4", "alice"}, want: "1"},
+					{command: []string{"XGROUP", "CREATECONSUMER", "XgroupKey1", "g4", "alice"}, want: "0"},
+					{command: []string{"XREADGROUP", "GROUP", "g4", "bob", "STREAMS", "XgroupKey1", ">"}, want: "[[XgroupKey1 [[1-1 [a 1]] [2-1 [a 2]]]]]"},
+					{command: []string{"XGROUP", "DELCONSUMER", "XgroupKey1", "g4", "alice"}, want: "0"},
+					{command: []string{"XGROUP", "DELCONSUMER", "XgroupKey1", "g4", "bob"}, want: "2"},
+					{command: []string{"XPENDING", "XgroupKey1", "g4"}, want: "[0 nil nil nil]"},
+					{command: []string{"XGROUP", "CREATECONSUMER", "XgroupKey1", "g9", "alice"}, wantErr: "NOGROUP"},
+				},
+			},
+			{
+				name: "5. Destroy consumer groups",
+				steps: []step{
+					{command: []string{"XGROUP", "CREATE", "XgroupKey1", "g5", "0"}, want: "OK"},
+					{command: []string{"XGROUP", "DESTROY", "XgroupKey1", "g5"}, want: "1"},
+					{command: []string{"XGROUP", "DESTROY", "XgroupKey1", "g5"}, want: "0"},
+					{command: []string{"XREADGROUP", "GROUP", "g5", "alice", "STREAMS", "XgroupKey1", ">"}, wantErr: "NOGROUP"},
+				},
+			},
+			{
+				name: "6. Return an error when the arguments are invalid",
+				steps: []step{
+					{command: []string{"XGROUP", "CREATE", "XgroupKey3", "g1", "$"}, wantErr: "value at XgroupKey3 is not a stream"},
+					{command: []string{"XGROUP", "CREATE", "XgroupKey1", "g6", "x"}, wantErr: "invalid stream ID"},
+					{command: []string{"XGROUP", "CREATE", "XgroupKey1", "g6", "0", "FOO"}, wantErr: "syntax error"},
+					{command: []string{"XGROUP", "CREATE", "XgroupKey1", "g6", "0", "ENTRIESREAD", "-1"}, wantErr: "ENTRIESREAD must be a non-negative integer"},
+					{command: []string{"XGROUP", "CREATE", "XgroupKey1", "g6"}, wantErr: constants.WrongArgsResponse},
+					{command: []string{"XGROUP", "DESTROY", "XgroupKey4", "g1"}, wantErr: "requires the key to exist"},
+				},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				runSteps(t, client, test.steps)
+			})
+		}
+	})
+
+	t.Run("Test_HandleXREADGROUP", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"XADD", "XreadgroupKey1", "1-1", "a", "1"}, want: "1-1"},
+			{command: []string{"XADD", "XreadgroupKey1", "2-1", "a", "2"}, want: "2-1"},
+			{command: []string{"XADD", "XreadgroupKey1", "3-1", "a", "3"}, want: "3-1"},
+			{command: []string{"XGROUP", "CREATE", "XreadgroupKey1", "g1", "0"}, want: "OK"},
+		})
+
+		tests := []struct {
+			name  string
+			steps []step
+		}{
+			{
+				name: "1. Deliver new entries to the consumers",
+				steps: []step{
+					{
+						command: []string{"XREADGROUP", "GROUP", "g1", "alice", "COUNT", "2", "STREAMS", "XreadgroupKey1", ">"},
+						want:    "[[XreadgroupKey1 [[1-1 [a 1]] [2-1 [a 2]]]]]",
+					},
+					{
+						command: []string{"XREADGROUP", "GROUP", "g1", "bob", "STREAMS", "XreadgroupKey1", ">"},
+						want:    "[[XreadgroupKey1 [[3-1 [a 3]]]]]",
+					},
+					{command: []string{"XREADGROUP", "GROUP", "g1", "bob", "STREAMS", "XreadgroupKey1", ">"}, want: "nil"},
+					{command: []string{"XPENDING", "XreadgroupKey1", "g1"}, want: "[3 1-1 3-1 [[alice 2] [bob 1]]]"},
+				},
+			},
+			{
+				name: "2. Return the pending entries of the consumer",
+				steps: []step{
+					{
+						command: []string{"XREADGROUP", "GROUP", "g1", "alice", "STREAMS", "XreadgroupKey1", "0"},
+						want:    "[[XreadgroupKey1 [[1-1 [a 1]] [2-1 [a 2]]]]]",
+					},
+					{
+						command: []string{"XREADGROUP", "GROUP", "g1", "alice", "STREAMS", "XreadgroupKey1", "1-1"},
+						want:    "[[XreadgroupKey1 [[2-1 [a 2]]]]]",
+					},
+					{command: []string{"XREADGROUP", "GROUP", "g1", "carol", "STREAMS", "XreadgroupKey1", "0"}, want: "[[XreadgroupKey1 []]]"},
+					{command: []string{"XDEL", "XreadgroupKey1", "2-1"}, want: "1"},
+					{
+						command: []string{"XREADGROUP", "GROUP", "g1", "alice", "STREAMS", "XreadgroupKey1", "0"},
+						want:    "[[XreadgroupKey1 [[1-1 [a 1]] [2-1 nil]]]]",
+					},
+				},
+			},
+			{
+				name: "3. Don't add the entries to the pending entries with NOACK",
+				steps: []step{
+					{command: []string{"XADD", "XreadgroupKey2", "1-1", "a", "1"}, want: "1-1"},
+					{command: []string{"XGROUP", "CREATE", "XreadgroupKey2", "g1", "0"}, want: "OK"},
+					{
+						command: []string{"XREADGROUP", "GROUP", "g1", "alice", "NOACK", "STREAMS", "XreadgroupKey2", ">"},
+						want:    "[[XreadgroupKey2 [[1-1 [a 1]]]]]",
+					},
+					{command: []string{"XPENDING", "XreadgroupKey2", "g1"}, want: "[0 nil nil nil]"},
+				},
+			},
+			{
+				name: "4. Return nil when the block timeout expires",
+				steps: []step{
+					{command: []string{"XREADGROUP", "GROUP", "g1", "alice", "BLOCK", "50", "STREAMS", "XreadgroupKey1", ">"}, want: "nil"},
+				},
+			},
+			{
+				name: "5. Return an error when the key or the group does not exist",
+				steps: []step{
+					{command: []string{"XREADGROUP", "GROUP", "g9", "alice", "STREAMS", "XreadgroupKey1", ">"}, wantErr: "NOGROUP"},
+					{command: []string{"XREADGROUP", "GROUP", "g1", "alice", "STREAMS", "XreadgroupKey3", ">"}, wantErr: "NOGROUP"},
+				},
+			},
+			{
+				name: "6. Return an error when the arguments are invalid",
+				steps: []step{
+					{command: []string{"XREADGROUP", "FOO", "g1", "alice", "STREAMS", "XreadgroupKey1", ">"}, wantErr: constants.WrongArgsResponse},
+					{command: []string{"XREADGROUP", "GROUP", "g1", "alice", "STREAMS", "XreadgroupKey1"}, wantErr: constants.WrongArgsResponse},
+					{command: []string{"XREADGROUP", "GROUP", "g1", "alice", "COUNT", "x", "STREAMS", "XreadgroupKey1", ">"}, wantErr: "COUNT must be an integer"},
+					{command: []string{"XREADGROUP", "GROUP", "g1", "alice", "STREAMS", "XreadgroupKey1", "x"}, wantErr: "invalid stream ID"},
+				},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				runSteps(t, client, test.steps)
+			})
+		}
+	})
+
+	t.Run("Test_HandleXREADGROUP_Block", func(t *testing.T) {
+		t.Parallel()
+		reader := connect(t)
+		writer := connect(t)
+
+		runSteps(t, writer, []step{
+			{command: []string{"XGROUP", "CREATE", "XreadgroupBlockKey1", "g1", "$", "MKSTREAM"}, want: "OK"},
+		})
+
+		done := make(chan resp.Value)
+		go func() {
+			res, err := send(reader, []string{"XREADGROUP", "GROUP", "g1", "alice", "BLOCK", "0", "STREAMS", "XreadgroupBlockKey1", ">"})
+			if err != nil {
+				t.Error(err)
+			}
+			done <- res
+		}()
+
+		// Give the reader time to block before adding the entry.
+		time.Sleep(100 * time.Millisecond)
+		select {
+		case res := <-done:
+			t.Fatalf("expected XREADGROUP to block, got %s", format(res))
+		default:
+		}
+
+		runSteps(t, writer, []step{
+			{command: []string{"XADD", "XreadgroupBlockKey1", "5-1", "b", "2"}, want: "5-1"},
+		})
+
+		select {
+		case res := <-done:
+			if got, want := format(res), "[[XreadgroupBlockKey1 [[5-1 [b 2]]]]]"; got != want {
+				t.Errorf("expected response \"%s\", got \"%s\"", want, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("timed out waiting for XREADGROUP to return")
+		}
+
+		runSteps(t, writer, []step{
+			{command: []string{"XPENDING", "XreadgroupBlockKey1", "g1"}, want: "[1 5-1 5-1 [[alice 1]]]"},
+		})
+	})
+
+	t.Run("Test_HandleXACK_XPENDING", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"XADD", "XpendingKey1", "1-1", "a", "1"}, want: "1-1"},
+			{command: []string{"XADD", "XpendingKey1", "2-1", "a", "2"}, want: "2-1"},
+			{command: []string{"XADD", "XpendingKey1", "3-1", "a", "3"}, want: "3-1"},
+			{command: []string{"XGROUP", "CREATE", "XpendingKey1", "g1", "0"}, want: "OK"},
+			{
+				command: []string{"XREADGROUP", "GROUP", "g1", "alice", "COUNT", "2", "STREAMS", "XpendingKey1", ">"},
+				want:    "[[XpendingKey1 [[1-1 [a 1]] [2-1 [a 2]]]]]",
+			},
+			{command: []string{"XREADGROUP", "GROUP", "g1", "bob", "STREAMS", "XpendingKey1", ">"}, want: "[[XpendingKey1 [[3-1 [a 3]]]]]"},
+		})
+
+		runSteps(t, client, []step{
+			{command: []string{"XPENDING", "XpendingKey1", "g1"}, want: "[3 1-1 3-1 [[alice 2] [bob 1]]]"},
+			{command: []string{"XPENDING", "XpendingKey1", "g1", "-", "+", "10"}, want: "[[1-1 alice 0 1] [2-1 alice 0 1] [3-1 bob 0 1]]"},
+			{command: []string{"XPENDING", "XpendingKey1", "g1", "-", "+", "10", "bob"}, want: "[[3-1 bob 0 1]]"},
+			{command: []string{"XPENDING", "XpendingKey1", "g1", "(1-1", "+", "1"}, want: "[[2-1 alice 0 1]]"},
+			{command: []string{"XPENDING", "XpendingKey1", "g1", "IDLE", "1000", "-", "+", "10"}, want: "[]"},
+			{command: []string{"XACK", "XpendingKey1", "g1", "1-1", "3-1", "5-1"}, want: "2"},
+			{command: []string{"XPENDING", "XpendingKey1", "g1"}, want: "[1 2-1 2-1 [[alice 1]]]"},
+			{command: []string{"XACK", "XpendingKey1", "g1", "2-1"}, want: "1"},
+			{command: []string{"XPENDING", "XpendingKey1", "g1"}, want: "[0 nil nil nil]"},
+			{command: []string{"XACK", "XpendingKey1", "g9", "1-1"}, want: "0"},
+			{command: []string{"XACK", "XpendingKey2", "g1", "1-1"}, want: "0"},
+			{command: []string{"XACK", "XpendingKey1", "g1", "x"}, wantErr: "invalid stream ID"},
+			{command: []string{"XPENDING", "XpendingKey1", "g9"}, wantErr: "NOGROUP"},
+			{command: []string{"XPENDING", "XpendingKey2", "g1"}, wantErr: "NOGROUP"},
+			{command: []string{"XPENDING", "XpendingKey1", "g1", "-", "+"}, wantErr: constants.WrongArgsResponse},
+			{command: []string{"XPENDING", "XpendingKey1", "g1", "-", "+", "x"}, wantErr: "count must be an integer"},
+		})
+	})
+
+	t.Run("Test_HandleXCLAIM_XAUTOCLAIM", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"XADD", "XclaimKey1", "1-1", "a", "1"}, want: "1-1"},
+			{command: []string{"XADD", "XclaimKey1", "2-1", "a", "2"}, want: "2-1"},
+			{command: []string{"XADD", "XclaimKey1", "3-1", "a", "3"}, want: "3-1"},
+			{command: []string{"XGROUP", "CREATE", "XclaimKey1", "g1", "0"}, want: "OK"},
+			{
+				command: []string{"XREADGROUP", "GROUP", "g1", "alice", "STREAMS", "XclaimKey1", ">"},
+				want:    "[[XclaimKey1 [[1-1 [a 1]] [2-1 [a 2]] [3-1 [a 3]]]]]",
+			},
+		})
+
+		runSteps(t, client, []step{
+			// The entries were just delivered, so they are not idle for long enough.
+			{command: []string{"XCLAIM", "XclaimKey1", "g1", "bob", "1000", "1-1"}, want: "[]"},
+			{command: []string{"XCLAIM", "XclaimKey1", "g1", "bob", "0", "1-1"}, want: "[[1-1 [a 1]]]"},
+			{
+				command: []string{"XCLAIM", "XclaimKey1", "g1", "bob", "0", "2-1", "IDLE", "5000", "RETRYCOUNT", "7", "JUSTID"},
+				want:    "[2-1]",
+			},
+			{command: []string{"XPENDING", "XclaimKey1", "g1", "-", "+", "10"}, want: "[[1-1 bob 0 2] [2-1 bob 5000 7] [3-1 alice 0 1]]"},
+			{command: []string{"XADD", "XclaimKey1", "4-1", "a", "4"}, want: "4-1"},
+			{command: []string{"XCLAIM", "XclaimKey1", "g1", "bob", "0", "4-1"}, want: "[]"},
+			{command: []string{"XCLAIM", "XclaimKey1", "g1", "bob", "0", "4-1", "FORCE"}, want: "[[4-1 [a 4]]]"},
+			{command: []string{"XPENDING", "XclaimKey1", "g1"}, want: "[4 1-1 4-1 [[alice 1] [bob 3]]]"},
+			{command: []string{"XAUTOCLAIM", "XclaimKey1", "g1", "carol", "1000", "0"}, want: "[0-0 [[2-1 [a 2]]] []]"},
+			{command: []string{"XDEL", "XclaimKey1", "3-1"}, want: "1"},
+			{command: []string{"XAUTOCLAIM", "XclaimKey1", "g1", "carol", "0", "0", "COUNT", "2", "JUSTID"}, want: "[3-1 [1-1 2-1] []]"},
+			{command: []string{"XAUTOCLAIM", "XclaimKey1", "g1", "carol", "0", "3-1"}, want: "[0-0 [[4-1 [a 4]]] [3-1]]"},
+			{command: []string{"XPENDING", "XclaimKey1", "g1"}, want: "[3 1-1 4-1 [[carol 3]]]"},
+			{command: []string{"XCLAIM", "XclaimKey1", "g9", "bob", "0", "1-1"}, wantErr: "NOGROUP"},
+			{command: []string{"XCLAIM", "XclaimKey1", "g1", "bob", "-1", "1-1"}, wantErr: "min-idle-time must be a non-negative integer"},
+			{command: []string{"XCLAIM", "XclaimKey1", "g1", "bob", "0", "x"}, wantErr: "invalid stream ID"},
+			{command: []string{"XCLAIM", "XclaimKey1", "g1", "bob", "0", "1-1", "FOO"}, wantErr: "syntax error"},
+			{command: []string{"XAUTOCLAIM", "XclaimKey1", "g1", "bob", "0", "0", "COUNT", "0"}, wantErr: "COUNT must be a positive integer"},
+			{command: []string{"XAUTOCLAIM", "XclaimKey2", "g1", "bob", "0", "0"}, wantErr: "NOGROUP"},
+		})
+	})
+
+	t.Run("Test_HandleXINFO", func(t *testing.T) {
+		t.Parallel()
+		client := connect(t)
+
+		runSteps(t, client, []step{
+			{command: []string{"XADD", "XinfoKey1", "1-1", "a", "1"}, want: "1-1"},
+			{command: []string{"XADD", "XinfoKey1", "2-1", "b", "2"}, want: "2-1"},
+			{command: []string{"XADD", "XinfoKey1", "3-1", "c", "3"}, want: "3-1"},
+			{command: []string{"XDEL", "XinfoKey1", "3-1"}, want: "1"},
+			{command: []string{"XGROUP", "CREATE", "XinfoKey1", "g1", "0"}, want: "OK"},
+			{command: []string{"XREADGROUP", "GROUP", "g1", "alice", "COUNT", "1", "STREAMS", "XinfoKey1", ">"}, want: "[[XinfoKey1 [[1-1 [a 1]]]]]"},
+			{command: []string{"XGROUP", "CREATECONSUMER", "XinfoKey1", "g1", "bob"}, want: "1"},
+		})
+
+		runSteps(t, client, []step{
+			{
+				command: []string{"XINFO", "STREAM", "XinfoKey1"},
+				want: "[length 2 last-generated-id 3-1 max-deleted-entry-id 3-1 entries-added 3 groups 1 " +
+					"first-entry [1-1 [a 1]] last-entry [2-1 [b 2]]]",
+			},
+			{
+				command: []string{"XINFO", "GROUPS", "XinfoKey1"},
+				want:    "[[name g1 consumers 2 pending 1 last-delivered-id 1-1 entries-read 1 lag 1]]",
+			},
+			{
+				command: []string{"XINFO", "CONSUMERS", "XinfoKey1", "g1"},
+				want:    "[[name alice pending 1 idle 0 inactive 0] [name bob pending 0 idle 0 inactive -1]]",
+			},
+			{command: []string{"XINFO", "STREAM", "XinfoKey2"}, wantErr: "no such key"},
+			{command: []string{"XINFO", "CONSUMERS", "XinfoKey1", "g9"}, wantErr: "NOGROUP"},
+			{command: []string{"XINFO", "GROUPS", "XinfoKey1", "g1"}, wantErr: constants.WrongArgsResponse},
+		})
+	})
+}
@@ -0,0 +1,537 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stream
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"time"
+)
+
+var (
+	ErrNoGroup   = errors.New("no such consumer group")
+	ErrBusyGroup = errors.New("BUSYGROUP Consumer Group name already exists")
+)
+
+// PendingEntry is an entry that was delivered to a consumer of a group and not acknowledged yet.
+type PendingEntry struct {
+	ID            ID
+	Consumer      string
+	DeliveryTime  time.Time // The last time the entry was delivered.
+	DeliveryCount uint64    // The number of times the entry was delivered.
+}
+
+// Consumer is a consumer of a group.
+type Consumer struct {
+	Name       string
+	SeenTime   time.Time // The last time the consumer read from or claimed entries of the group.
+	ActiveTime time.Time // The last time the consumer was delivered or claimed an entry. Zero if it never was.
+}
+
+// ConsumerGroup tracks the entries delivered to the consumers of a group.
+// The pending entries list of the group is sorted by ID. The pending entries of a consumer are the entries
+// in the list that are owned by the consumer.
+type ConsumerGroup struct {
+	Name            string
+	LastDeliveredID ID
+	EntriesRead     int64 // The number of entries delivered to the group.
+	Pending         []*PendingEntry
+	Consumers       map[string]*Consumer
+}
+
+// GroupInfo holds the state of a consumer group returned by XINFO GROUPS.
+type GroupInfo struct {
+	Name            string
+	Consumers       int
+	Pending         int
+	LastDeliveredID ID
+	EntriesRead     int64
+	Lag             int
+}
+
+// ConsumerInfo holds the state of a consumer returned by XINFO CONSUMERS.
+type ConsumerInfo struct {
+	Name     string
+	Pending  int
+	Idle     int64 // Milliseconds since the consumer was last seen.
+	Inactive int64 // Milliseconds since the consumer was last active, or -1 if it never was.
+}
+
+// ClaimOptions holds the options of XCLAIM.
+type ClaimOptions struct {
+	Idle       *time.Duration // Sets the idle time of the claimed entries.
+	Time       *time.Time     // Sets the delivery time of the claimed entries.
+	RetryCount *uint64        // Sets the delivery count of the claimed entries.
+	Force      bool           // Creates the pending entry if the entry is not pending.
+	JustID     bool           // Doesn't increment the delivery count.
+	LastID     *ID            // Sets the last delivered ID of the group if it's greater than the current one.
+}
+
+func (stream *Stream) group(name string) (*ConsumerGroup, error) {
+	group, ok := stream.groups[name]
+	if !ok {
+		return nil, ErrNoGroup
+	}
+	return group, nil
+}
+
+// entryIndex returns the index of the entry with the ID, or -1 if there is no such entry.
+func (stream *Stream) entryIndex(id ID) int {
+	i := stream.search(id)
+	if i == len(stream.entries) || stream.entries[i].ID.Compare(id) != 0 {
+		return -1
+	}
+	return i
+}
+
+// countAfter returns the number of entries with IDs greater than id.
+func (stream *Stream) countAfter(id ID) int {
+	next, ok := id.Next()
+	if !ok {
+		return 0
+	}
+	return len(stream.entries) - stream.search(next)
+}
+
+// pendingIndex returns the index of the pending entry with the ID, or the index where it would be inserted.
+func (group *ConsumerGroup) pendingIndex(id ID) (int, bool) {
+	return slices.BinarySearchFunc(group.Pending, id, func(pending *PendingEntry, id ID) int {
+		return pending.ID.Compare(id)
+	})
+}
+
+// consumer returns the consumer with the name, creating it if it does not exist.
+func (group *ConsumerGroup) consumer(name string, now time.Time) *Consumer {
+	consumer, ok := group.Consumers[name]
+	if !ok {
+		consumer = &Consumer{Name: name, SeenTime: now}
+		group.Consumers[name] = consumer
+	}
+	return consumer
+}
+
+// deliver adds the entry to the pending entries of the consumer.
+// An entry that is already pending is transferred to the consumer.
+func (group *ConsumerGroup) deliver(id ID, consumer string, now time.Time) {
+	i, found := group.pendingIndex(id)
+	if found {
+		group.Pending[i].Consumer = consumer
+		group.Pending[i].DeliveryTime = now
+		group.Pending[i].DeliveryCount = 1
+		return
+	}
+	group.Pending = slices.Insert(group.Pending, i, &PendingEntry{
+		ID:            id,
+		Consumer:      consumer,
+		DeliveryTime:  now,
+		DeliveryCount: 1,
+	})
+}
+
+// initialEntriesRead estimates the number of entries read by a group with the last delivered ID.
+// Entries that were deleted after the ID are counted as read.
+func (stream *Stream) initialEntriesRead(id ID) int64 {
+	if id.Compare(MinID) == 0 {
+		return 0
+	}
+	return int64(stream.entriesAdded) - int64(stream.countAfter(id))
+}
+
+// CreateGroup creates a consumer group with the last delivered ID.
+// When entriesRead is negative, it's computed from the entries in the stream.
+func (stream *Stream) CreateGroup(name string, id ID, entriesRead int64) error {
+	stream.mut.Lock()
+	defer stream.mut.Unlock()
+
+	if _, ok := stream.groups[name]; ok {
+		return ErrBusyGroup
+	}
+	if entriesRead < 0 {
+		entriesRead = stream.initialEntriesRead(id)
+	}
+	stream.groups[name] = &ConsumerGroup{
+		Name:            name,
+		LastDeliveredID: id,
+		EntriesRead:     entriesRead,
+		Pending:         make([]*PendingEntry, 0),
+		Consumers:       make(map[string]*Consumer),
+	}
+	return nil
+}
+
+// DestroyGroup removes the consumer group. Returns false if the group does not exist.
+func (stream *Stream) DestroyGroup(name string) bool {
+	stream.mut.Lock()
+	defer stream.mut.Unlock()
+
+	if _, ok := stream.groups[name]; !ok {
+		return false
+	}
+	delete(stream.groups, name)
+	return true
+}
+
+// SetGroupID sets the last delivered ID of the consumer group.
+// When entriesRead is negative, it's computed from the entries in the stream.
+func (stream *Stream) SetGroupID(name string, id ID, entriesRead int64) error {
+	stream.mut.Lock()
+	defer stream.mut.Unlock()
+
+	group, err := stream.group(name)
+	if err != nil {
+		return err
+	}
+	if entriesRead < 0 {
+		entriesRead = stream.initialEntriesRead(id)
+	}
+	group.LastDeliveredID = id
+	group.EntriesRead = entriesRead
+	return nil
+}
+
+// CreateConsumer creates the consumer in the group. Returns false if the consumer already exists.
+func (stream *Stream) CreateConsumer(groupName, name string, now time.Time) (bool, error) {
+	stream.mut.Lock()
+	defer stream.mut.Unlock()
+
+	group, err := stream.group(groupName)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := group.Consumers[name]; ok {
+		return false, nil
+	}
+	group.consumer(name, now)
+	return true, nil
+}
+
+// DeleteConsumer removes the consumer and its pending entries from the group.
+// Returns the number of pending entries the consumer had.
+func (stream *Stream) DeleteConsumer(groupName, name string) (int, error) {
+	stream.mut.Lock()
+	defer stream.mut.Unlock()
+
+	group, err := stream.group(groupName)
+	if err != nil {
+		return 0, err
+	}
+	if _, ok := group.Consumers[name]; !ok {
+		return 0, nil
+	}
+	delete(group.Consumers, name)
+
+	count := len(group.Pending)
+	group.Pending = slices.DeleteFunc(group.Pending, func(pending *PendingEntry) bool {
+		return pending.Consumer == name
+	})
+	return count - len(group.Pending), nil
+}
+
+// ReadGroup delivers the entries that were not delivered to the group yet to the consumer.
+// The entries are added to the pending entries of the consumer unless noAck is true.
+// When count is greater than 0, at most count entries are delivered.
+func (stream *Stream) ReadGroup(groupName, consumerName string, count int, noAck bool, now time.Time) ([]Entry, error) {
+	stream.mut.Lock()
+	defer stream.mut.Unlock()
+
+	group, err := stream.group(groupName)
+	if err != nil {
+		return nil, err
+	}
+	consumer := group.consumer(consumerName, now)
+	consumer.SeenTime = now
+
+	start, ok := group.LastDeliveredID.Next()
+	if !ok {
+		return []Entry{}, nil
+	}
+	entries := stream.entries[stream.search(start):]
+	if count > 0 && count < len(entries) {
+		entries = entries[:count]
+	}
+	entries = slices.Clone(entries)
+
+	for _, entry := range entries {
+		if !noAck {
+			group.deliver(entry.ID, consumerName, now)
+		}
+		group.LastDeliveredID = entry.ID
+		group.EntriesRead++
+	}
+	if len(entries) > 0 {
+		consumer.ActiveTime = now
+	}
+
+	return entries, nil
+}
+
+// ReadPending returns the pending entries of the consumer with IDs greater than id.
+// Pending entries that were deleted from the stream are returned without fields.
+// When count is greater than 0, at most count entries are returned.
+func (stream *Stream) ReadPending(groupName, consumerName string, id ID, count int, now time.Time) ([]Entry, error) {
+	stream.mut.Lock()
+	defer stream.mut.Unlock()
+
+	group, err := stream.group(groupName)
+	if err != nil {
+		return nil, err
+	}
+	consumer := group.consumer(consumerName, now)
+	consumer.SeenTime = now
+
+	entries := make([]Entry, 0)
+	for _, pending := range group.Pending {
+		if count > 0 && len(entries) == count {
+			break
+		}
+		if pending.Consumer != consumerName || pending.ID.Compare(id) <= 0 {
+			continue
+		}
+		entry := Entry{ID: pending.ID}
+		if i := stream.entryIndex(pending.ID); i != -1 {
+			entry = stream.entries[i]
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// Ack removes the entries from the pending entries of the group and returns the number of entries removed.
+func (stream *Stream) Ack(groupName string, ids []ID) (int, error) {
+	stream.mut.Lock()
+	defer stream.mut.Unlock()
+
+	group, err := stream.group(groupName)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, id := range ids {
+		if i, found := group.pendingIndex(id); found {
+			group.Pending = slices.Delete(group.Pending, i, i+1)
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Pending returns the pending entries of the group with IDs between start and end that were idle for at least
+// minIdle. When consumer is not empty, only the pending entries of the consumer are returned.
+// When count is greater than 0, at most count entries are returned.
+func (stream *Stream) Pending(groupName string, start, end ID, count int, consumer string, minIdle time.Duration, now time.Time) ([]PendingEntry, error) {
+	stream.mut.RLock()
+	defer stream.mut.RUnlock()
+
+	group, err := stream.group(groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]PendingEntry, 0)
+	i, _ := group.pendingIndex(start)
+	for ; i < len(group.Pending) && group.Pending[i].ID.Compare(end) <= 0; i++ {
+		if count > 0 && len(res) == count {
+			break
+		}
+		pending := group.Pending[i]
+		if consumer != "" && pending.Consumer != consumer {
+			continue
+		}
+		if now.Sub(pending.DeliveryTime) < minIdle {
+			continue
+		}
+		res = append(res, *pending)
+	}
+	return res, nil
+}
+
+// PendingSummary returns all the pending entries of the group.
+func (stream *Stream) PendingSummary(groupName string) ([]PendingEntry, error) {
+	stream.mut.RLock()
+	defer stream.mut.RUnlock()
+
+	group, err := stream.group(groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]PendingEntry, len(group.Pending))
+	for i, pending := range group.Pending {
+		res[i] = *pending
+	}
+	return res, nil
+}
+
+// claim transfers the pending entry at index i to the consumer.
+func (group *ConsumerGroup) claim(i int, consumer *Consumer, justID bool, now time.Time) {
+	pending := group.Pending[i]
+	pending.Consumer = consumer.Name
+	pending.DeliveryTime = now
+	if !justID {
+		pending.DeliveryCount++
+	}
+	consumer.ActiveTime = now
+}
+
+// Claim transfers the pending entries with the IDs that were idle for at least minIdle to the consumer.
+// Pending entries that were deleted from the stream are removed from the group and not returned.
+func (stream *Stream) Claim(groupName, consumerName string, minIdle time.Duration, ids []ID, opts ClaimOptions, now time.Time) ([]Entry, error) {
+	stream.mut.Lock()
+	defer stream.mut.Unlock()
+
+	group, err := stream.group(groupName)
+	if err != nil {
+		return nil, err
+	}
+	consumer := group.consumer(consumerName, now)
+	consumer.SeenTime = now
+
+	if opts.LastID != nil && opts.LastID.Compare(group.LastDeliveredID) > 0 {
+		group.LastDeliveredID = *opts.LastID
+	}
+
+	deliveryTime := now
+	if opts.Idle != nil {
+		deliveryTime = now.Add(-*opts.Idle)
+	} else if opts.Time != nil {
+		deliveryTime = *opts.Time
+	}
+
+	claimed := make([]Entry, 0)
+	for _, id := range ids {
+		entryIndex := stream.entryIndex(id)
+		i, found := group.pendingIndex(id)
+		if !found {
+			if !opts.Force || entryIndex == -1 {
+				continue
+			}
+			group.Pending = slices.Insert(group.Pending, i, &PendingEntry{ID: id, Consumer: consumerName})
+		} else if entryIndex == -1 {
+			group.Pending = slices.Delete(group.Pending, i, i+1)
+			continue
+		} else if now.Sub(group.Pending[i].DeliveryTime) < minIdle {
+			continue
+		}
+
+		group.claim(i, consumer, opts.JustID, now)
+		group.Pending[i].DeliveryTime = deliveryTime
+		if opts.RetryCount != nil {
+			group.Pending[i].DeliveryCount = *opts.RetryCount
+		}
+		claimed = append(claimed, stream.entries[entryIndex])
+	}
+
+	return claimed, nil
+}
+
+// AutoClaim transfers the pending entries with IDs greater than or equal to start that were idle for at least
+// minIdle to the consumer. At most count pending entries are examined. Pending entries that were deleted from
+// the stream are removed from the group.
+// Returns the ID to start the next call from, or 0-0 if all the pending entries were examined, the claimed
+// entries, and the IDs of the deleted entries.
+func (stream *Stream) AutoClaim(groupName, consumerName string, minIdle time.Duration, start ID, count int, justID bool, now time.Time) (ID, []Entry, []ID, error) {
+	stream.mut.Lock()
+	defer stream.mut.Unlock()
+
+	group, err := stream.group(groupName)
+	if err != nil {
+		return ID{}, nil, nil, err
+	}
+	consumer := group.consumer(consumerName, now)
+	consumer.SeenTime = now
+
+	claimed := make([]Entry, 0)
+	deleted := make([]ID, 0)
+	i, _ := group.pendingIndex(start)
+	for examined := 0; i < len(group.Pending) && examined < count; examined++ {
+		pending := group.Pending[i]
+		entryIndex := stream.entryIndex(pending.ID)
+		if entryIndex == -1 {
+			deleted = append(deleted, pending.ID)
+			group.Pending = slices.Delete(group.Pending, i, i+1)
+			continue
+		}
+		if now.Sub(pending.DeliveryTime) >= minIdle {
+			group.claim(i, consumer, justID, now)
+			claimed = append(claimed, stream.entries[entryIndex])
+		}
+		i++
+	}
+
+	next := MinID
+	if i < len(group.Pending) {
+		next = group.Pending[i].ID
+	}
+	return next, claimed, deleted, nil
+}
+
+// Groups returns the state of the consumer groups of the stream, sorted by name.
+func (stream *Stream) Groups() []GroupInfo {
+	stream.mut.RLock()
+	defer stream.mut.RUnlock()
+
+	res := make([]GroupInfo, 0, len(stream.groups))
+	for _, group := range stream.groups {
+		res = append(res, GroupInfo{
+			Name:            group.Name,
+			Consumers:       len(group.Consumers),
+			Pending:         len(group.Pending),
+			LastDeliveredID: group.LastDeliveredID,
+			EntriesRead:     group.EntriesRead,
+			Lag:             stream.countAfter(group.LastDeliveredID),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+// Consumers returns the state of the consumers of the group, sorted by name.
+func (stream *Stream) Consumers(groupName string, now time.Time) ([]ConsumerInfo, error) {
+	stream.mut.RLock()
+	defer stream.mut.RUnlock()
+
+	group, err := stream.group(groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make(map[string]int)
+	for _, entry := range group.Pending {
+		pending[entry.Consumer]++
+	}
+
+	res := make([]ConsumerInfo, 0, len(group.Consumers))
+	for _, consumer := range group.Consumers {
+		info := ConsumerInfo{
+			Name:     consumer.Name,
+			Pending:  pending[consumer.Name],
+			Idle:     now.Sub(consumer.SeenTime).Milliseconds(),
+			Inactive: -1,
+		}
+		if !consumer.ActiveTime.IsZero() {
+			info.Inactive = now.Sub(consumer.ActiveTime).Milliseconds()
+		}
+		res = append(res, info)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res, nil
+}
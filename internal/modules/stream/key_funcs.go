@@ -0,0 +1,179 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stream
+
+import (
+	"errors"
+	"github.com/echovault/sugardb/internal"
+	"github.com/echovault/sugardb/internal/constants"
+	"slices"
+	"strings"
+)
+
+func xaddKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	if len(cmd) < 5 {
+		return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+	}
+	return internal.KeyExtractionFuncResult{
+		Channels:  make([]string, 0),
+		ReadKeys:  make([]string, 0),
+		WriteKeys: cmd[1:2],
+	}, nil
+}
+
+func xrangeKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	if len(cmd) != 4 && len(cmd) != 6 {
+		return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+	}
+	return internal.KeyExtractionFuncResult{
+		Channels:  make([]string, 0),
+		ReadKeys:  cmd[1:2],
+		WriteKeys: make([]string, 0),
+	}, nil
+}
+
+func xlenKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	if len(cmd) != 2 {
+		return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+	}
+	return internal.KeyExtractionFuncResult{
+		Channels:  make([]string, 0),
+		ReadKeys:  cmd[1:],
+		WriteKeys: make([]string, 0),
+	}, nil
+}
+
+func xdelKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	if len(cmd) < 3 {
+		return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+	}
+	return internal.KeyExtractionFuncResult{
+		Channels:  make([]string, 0),
+		ReadKeys:  make([]string, 0),
+		WriteKeys: cmd[1:2],
+	}, nil
+}
+
+func xtrimKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	if len(cmd) < 4 {
+		return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+	}
+	return internal.KeyExtractionFuncResult{
+		Channels:  make([]string, 0),
+		ReadKeys:  make([]string, 0),
+		WriteKeys: cmd[1:2],
+	}, nil
+}
+
+// splitStreamsArgs returns the keys and IDs after the STREAMS keyword of XREAD.
+func splitStreamsArgs(cmd []string) ([]string, []string, error) {
+	i := slices.IndexFunc(cmd, func(arg string) bool {
+		return strings.EqualFold(arg, "streams")
+	})
+	if i == -1 || i == len(cmd)-1 {
+		return nil, nil, errors.New(constants.WrongArgsResponse)
+	}
+	args := cmd[i+1:]
+	if len(args)%2 != 0 {
+		return nil, nil, errors.New("unbalanced XREAD list of streams: for each stream key an ID or '$' must be specified")
+	}
+	return args[:len(args)/2], args[len(args)/2:], nil
+}
+
+func xreadKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	keys, _, err := splitStreamsArgs(cmd)
+	if err != nil {
+		return internal.KeyExtractionFuncResult{}, err
+	}
+	return internal.KeyExtractionFuncResult{
+		Channels:  make([]string, 0),
+		ReadKeys:  keys,
+		WriteKeys: make([]string, 0),
+	}, nil
+}
+
+func xgroupKeyFunc(minArgs, maxArgs int) internal.KeyExtractionFunc {
+	return func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+		if len(cmd) < minArgs || len(cmd) > maxArgs {
+			return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+		}
+		return internal.KeyExtractionFuncResult{
+			Channels:  make([]string, 0),
+			ReadKeys:  make([]string, 0),
+			WriteKeys: cmd[2:3],
+		}, nil
+	}
+}
+
+func xreadgroupKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	if len(cmd) < 7 || !strings.EqualFold(cmd[1], "group") {
+		return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+	}
+	keys, _, err := splitStreamsArgs(cmd[4:])
+	if err != nil {
+		return internal.KeyExtractionFuncResult{}, err
+	}
+	return internal.KeyExtractionFuncResult{
+		Channels:  make([]string, 0),
+		ReadKeys:  keys,
+		WriteKeys: keys,
+	}, nil
+}
+
+func xackKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	if len(cmd) < 4 {
+		return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+	}
+	return internal.KeyExtractionFuncResult{
+		Channels:  make([]string, 0),
+		ReadKeys:  make([]string, 0),
+		WriteKeys: cmd[1:2],
+	}, nil
+}
+
+func xpendingKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	if len(cmd) != 3 && (len(cmd) < 6 || len(cmd) > 9) {
+		return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+	}
+	return internal.KeyExtractionFuncResult{
+		Channels:  make([]string, 0),
+		ReadKeys:  cmd[1:2],
+		WriteKeys: make([]string, 0),
+	}, nil
+}
+
+func xclaimKeyFunc(cmd []string) (internal.KeyExtractionFuncResult, error) {
+	if len(cmd) < 6 {
+		return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+	}
+	return internal.KeyExtractionFuncResult{
+		Channels:  make([]string, 0),
+		ReadKeys:  make([]string, 0),
+		WriteKeys: cmd[1:2],
+	}, nil
+}
+
+func xinfoKeyFunc(args int) internal.KeyExtractionFunc {
+	return func(cmd []string) (internal.KeyExtractionFuncResult, error) {
+		if len(cmd) != args {
+			return internal.KeyExtractionFuncResult{}, errors.New(constants.WrongArgsResponse)
+		}
+		return internal.KeyExtractionFuncResult{
+			Channels:  make([]string, 0),
+			ReadKeys:  cmd[2:3],
+			WriteKeys: make([]string, 0),
+		}, nil
+	}
+}
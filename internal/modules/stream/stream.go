@@ -0,0 +1,416 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stream
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/echovault/sugardb/internal"
+	"github.com/echovault/sugardb/internal/constants"
+)
+
+func init() {
+	internal.RegisterCompositeType("stream", func(data []byte) (interface{}, error) {
+		stream := NewStream()
+		if err := json.Unmarshal(data, stream); err != nil {
+			return nil, err
+		}
+		return stream, nil
+	})
+}
+
+// ID identifies an entry in a stream. It's made up of a millisecond timestamp and a sequence number.
+type ID struct {
+	Ms  uint64
+	Seq uint64
+}
+
+var (
+	MinID = ID{Ms: 0, Seq: 0}
+	MaxID = ID{Ms: math.MaxUint64, Seq: math.MaxUint64}
+)
+
+func (id ID) String() string {
+	return fmt.Sprintf("%d-%d", id.Ms, id.Seq)
+}
+
+// Compare returns -1 if id is smaller than other, 0 if they are equal and 1 if id is greater than other.
+func (id ID) Compare(other ID) int {
+	switch {
+	case id.Ms < other.Ms:
+		return -1
+	case id.Ms > other.Ms:
+		return 1
+	case id.Seq < other.Seq:
+		return -1
+	case id.Seq > other.Seq:
+		return 1
+	}
+	return 0
+}
+
+// Next returns the smallest ID that is greater than id. Returns false if id is the largest possible ID.
+func (id ID) Next() (ID, bool) {
+	switch {
+	case id.Seq < math.MaxUint64:
+		return ID{Ms: id.Ms, Seq: id.Seq + 1}, true
+	case id.Ms < math.MaxUint64:
+		return ID{Ms: id.Ms + 1, Seq: 0}, true
+	}
+	return id, false
+}
+
+// Prev returns the largest ID that is smaller than id. Returns false if id is the smallest possible ID.
+func (id ID) Prev() (ID, bool) {
+	switch {
+	case id.Seq > 0:
+		return ID{Ms: id.Ms, Seq: id.Seq - 1}, true
+	case id.Ms > 0:
+		return ID{Ms: id.Ms - 1, Seq: math.MaxUint64}, true
+	}
+	return id, false
+}
+
+// ParseID parses an ID in the "ms-seq" format. When the sequence number is omitted, it is set to defaultSeq.
+func ParseID(s string, defaultSeq uint64) (ID, error) {
+	msPart, seqPart, hasSeq := strings.Cut(s, "-")
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return ID{}, errors.New("invalid stream ID specified as stream command argument")
+	}
+	if !hasSeq {
+		return ID{Ms: ms, Seq: defaultSeq}, nil
+	}
+	seq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return ID{}, errors.New("invalid stream ID specified as stream command argument")
+	}
+	return ID{Ms: ms, Seq: seq}, nil
+}
+
+// Entry is an entry in a stream.
+type Entry struct {
+	ID     ID
+	Fields []string // The field-value pairs of the entry, in the order they were added.
+}
+
+// Stream is an append-only log of entries ordered by ID.
+// The entries are kept sorted in a slice. New entries always have the largest ID, so they are appended to the end,
+// and ranges are found with a binary search.
+type Stream struct {
+	mut          sync.RWMutex
+	entries      []Entry
+	lastID       ID     // The ID of the last entry added to the stream. Deleting entries does not change it.
+	entriesAdded uint64 // The number of entries added to the stream since it was created.
+	maxDeletedID ID     // The largest ID of the entries deleted from the stream.
+	groups       map[string]*ConsumerGroup
+}
+
+// Info holds the state of a stream returned by XINFO STREAM.
+type Info struct {
+	Length       int
+	LastID       ID
+	MaxDeletedID ID
+	EntriesAdded uint64
+	Groups       int
+	FirstEntry   *Entry
+	LastEntry    *Entry
+}
+
+func (stream *Stream) GetMem() int64 {
+	stream.mut.RLock()
+	defer stream.mut.RUnlock()
+
+	var size int64
+	size += int64(unsafe.Sizeof(*stream))
+	for _, entry := range stream.entries {
+		size += int64(unsafe.Sizeof(entry))
+		for _, field := range entry.Fields {
+			size += int64(unsafe.Sizeof(field))
+			size += int64(len(field))
+		}
+	}
+	for name, group := range stream.groups {
+		size += int64(unsafe.Sizeof(*group)) + int64(len(name))
+		size += int64(len(group.Pending)) * int64(unsafe.Sizeof(PendingEntry{}))
+		size += int64(len(group.Consumers)) * int64(unsafe.Sizeof(Consumer{}))
+	}
+
+	return size
+}
+
+// compile time interface check
+var _ constants.CompositeType = (*Stream)(nil)
+
+func NewStream() *Stream {
+	return &Stream{
+		entries: make([]Entry, 0),
+		groups:  make(map[string]*ConsumerGroup),
+	}
+}
+
+// Len returns the number of entries in the stream.
+func (stream *Stream) Len() int {
+	stream.mut.RLock()
+	defer stream.mut.RUnlock()
+	return len(stream.entries)
+}
+
+// LastID returns the ID of the last entry added to the stream.
+func (stream *Stream) LastID() ID {
+	stream.mut.RLock()
+	defer stream.mut.RUnlock()
+	return stream.lastID
+}
+
+// Info returns the state of the stream.
+func (stream *Stream) Info() Info {
+	stream.mut.RLock()
+	defer stream.mut.RUnlock()
+
+	info := Info{
+		Length:       len(stream.entries),
+		LastID:       stream.lastID,
+		MaxDeletedID: stream.maxDeletedID,
+		EntriesAdded: stream.entriesAdded,
+		Groups:       len(stream.groups),
+	}
+	if len(stream.entries) > 0 {
+		first, last := stream.entries[0], stream.entries[len(stream.entries)-1]
+		info.FirstEntry, info.LastEntry = &first, &last
+	}
+	return info
+}
+
+// Add appends an entry to the stream and returns its ID.
+// The id can be "*" to generate the ID from the current time, "ms-*" to generate only the sequence number,
+// or an explicit "ms-seq" ID. The ID must be greater than the ID of the last entry added to the stream.
+func (stream *Stream) Add(id string, fields []string, now time.Time) (ID, error) {
+	stream.mut.Lock()
+	defer stream.mut.Unlock()
+
+	newID, err := stream.nextID(id, now)
+	if err != nil {
+		return ID{}, err
+	}
+
+	stream.entries = append(stream.entries, Entry{ID: newID, Fields: fields})
+	stream.lastID = newID
+	stream.entriesAdded++
+
+	return newID, nil
+}
+
+func (stream *Stream) nextID(id string, now time.Time) (ID, error) {
+	if id == "*" {
+		ms := uint64(now.UnixMilli())
+		if ms > stream.lastID.Ms {
+			return ID{Ms: ms, Seq: 0}, nil
+		}
+		next, ok := stream.lastID.Next()
+		if !ok {
+			return ID{}, errors.New("the stream has exhausted the last possible ID, unable to add more items")
+		}
+		return next, nil
+	}
+
+	if ms, found := strings.CutSuffix(id, "-*"); found {
+		newID, err := ParseID(ms, 0)
+		if err != nil {
+			return ID{}, err
+		}
+		if newID.Ms == stream.lastID.Ms {
+			if newID.Seq = stream.lastID.Seq + 1; newID.Seq == 0 {
+				return ID{}, errors.New("the ID specified in XADD is equal or smaller than the target stream top item")
+			}
+		}
+		if newID.Compare(MinID) == 0 {
+			newID.Seq = 1
+		}
+		if newID.Compare(stream.lastID) <= 0 {
+			return ID{}, errors.New("the ID specified in XADD is equal or smaller than the target stream top item")
+		}
+		return newID, nil
+	}
+
+	newID, err := ParseID(id, 0)
+	if err != nil {
+		return ID{}, err
+	}
+	if newID.Compare(MinID) == 0 {
+		return ID{}, errors.New("the ID specified in XADD must be greater than 0-0")
+	}
+	if newID.Compare(stream.lastID) <= 0 {
+		return ID{}, errors.New("the ID specified in XADD is equal or smaller than the target stream top item")
+	}
+	return newID, nil
+}
+
+// search returns the index of the first entry with an ID greater than or equal to id.
+func (stream *Stream) search(id ID) int {
+	i, _ := slices.BinarySearchFunc(stream.entries, id, func(entry Entry, id ID) int {
+		return entry.ID.Compare(id)
+	})
+	return i
+}
+
+// Range returns the entries with IDs between start and end, both inclusive.
+// When reverse is true, the entries are returned from the largest ID to the smallest.
+// When count is greater than 0, at most count entries are returned.
+func (stream *Stream) Range(start, end ID, count int, reverse bool) []Entry {
+	stream.mut.RLock()
+	defer stream.mut.RUnlock()
+
+	if start.Compare(end) > 0 {
+		return []Entry{}
+	}
+
+	from := stream.search(start)
+	to := stream.search(end)
+	if to < len(stream.entries) && stream.entries[to].ID.Compare(end) == 0 {
+		to++
+	}
+
+	entries := slices.Clone(stream.entries[from:to])
+	if reverse {
+		slices.Reverse(entries)
+	}
+	if count > 0 && count < len(entries) {
+		entries = entries[:count]
+	}
+
+	return entries
+}
+
+// After returns the entries with IDs greater than id.
+// When count is greater than 0, at most count entries are returned.
+func (stream *Stream) After(id ID, count int) []Entry {
+	start, ok := id.Next()
+	if !ok {
+		return []Entry{}
+	}
+	return stream.Range(start, MaxID, count, false)
+}
+
+// Delete removes the entries with the IDs and returns the number of entries removed.
+func (stream *Stream) Delete(ids []ID) int {
+	stream.mut.Lock()
+	defer stream.mut.Unlock()
+
+	count := 0
+	for _, id := range ids {
+		i := stream.search(id)
+		if i == len(stream.entries) || stream.entries[i].ID.Compare(id) != 0 {
+			continue
+		}
+		stream.entries = slices.Delete(stream.entries, i, i+1)
+		if id.Compare(stream.maxDeletedID) > 0 {
+			stream.maxDeletedID = id
+		}
+		count++
+	}
+
+	return count
+}
+
+// TrimMaxLen removes the oldest entries until the stream has at most maxLen entries.
+// When limit is greater than 0, at most limit entries are removed.
+// Returns the number of entries removed.
+func (stream *Stream) TrimMaxLen(maxLen int, limit int) int {
+	stream.mut.Lock()
+	defer stream.mut.Unlock()
+	return stream.trim(len(stream.entries)-maxLen, limit)
+}
+
+// TrimMinID removes the entries with IDs smaller than minID.
+// When limit is greater than 0, at most limit entries are removed.
+// Returns the number of entries removed.
+func (stream *Stream) TrimMinID(minID ID, limit int) int {
+	stream.mut.Lock()
+	defer stream.mut.Unlock()
+	return stream.trim(stream.search(minID), limit)
+}
+
+func (stream *Stream) trim(count int, limit int) int {
+	if count <= 0 {
+		return 0
+	}
+	if limit > 0 && count > limit {
+		count = limit
+	}
+	if last := stream.entries[count-1].ID; last.Compare(stream.maxDeletedID) > 0 {
+		stream.maxDeletedID = last
+	}
+	stream.entries = slices.Delete(stream.entries, 0, count)
+	return count
+}
+
+// streamJSON is the persisted form of a stream.
+type streamJSON struct {
+	Entries      []Entry
+	LastID       ID
+	EntriesAdded uint64
+	MaxDeletedID ID
+	Groups       map[string]*ConsumerGroup
+}
+
+// CompositeType returns the type tag that restores the stream from a snapshot.
+func (stream *Stream) CompositeType() string {
+	return "stream"
+}
+
+// MarshalJSON encodes the entries and the consumer groups of the stream so that it can be restored from a snapshot.
+func (stream *Stream) MarshalJSON() ([]byte, error) {
+	stream.mut.RLock()
+	defer stream.mut.RUnlock()
+	return json.Marshal(streamJSON{
+		Entries:      stream.entries,
+		LastID:       stream.lastID,
+		EntriesAdded: stream.entriesAdded,
+		MaxDeletedID: stream.maxDeletedID,
+		Groups:       stream.groups,
+	})
+}
+
+// UnmarshalJSON decodes the data of a stream encoded by MarshalJSON.
+func (stream *Stream) UnmarshalJSON(b []byte) error {
+	var data streamJSON
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	stream.mut.Lock()
+	defer stream.mut.Unlock()
+	stream.entries = data.Entries
+	if stream.entries == nil {
+		stream.entries = make([]Entry, 0)
+	}
+	stream.lastID = data.LastID
+	stream.entriesAdded = data.EntriesAdded
+	stream.maxDeletedID = data.MaxDeletedID
+	stream.groups = data.Groups
+	if stream.groups == nil {
+		stream.groups = make(map[string]*ConsumerGroup)
+	}
+	return nil
+}
@@ -20,8 +20,29 @@ import (
 
 	"github.com/echovault/sugardb/internal"
 	"github.com/echovault/sugardb/internal/constants"
+	"github.com/echovault/sugardb/internal/modules/bitmap"
 )
 
+// getString returns the string held by the value. Bitmaps hold the raw bytes of a string, so they're read as strings.
+func getString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case *bitmap.Bitmap:
+		return v.String(), true
+	}
+	return "", false
+}
+
+// storedValue returns the value to store when the string str is written to a key that holds current.
+// A key that holds a bitmap keeps holding the raw bytes of the string. Other keys are stored as value.
+func storedValue(current interface{}, str string, value interface{}) interface{} {
+	if _, ok := current.(*bitmap.Bitmap); ok {
+		return bitmap.NewBitmap([]byte(str))
+	}
+	return value
+}
+
 func handleSetRange(params internal.HandlerFuncParams) ([]byte, error) {
 	keys, err := setRangeKeyFunc(params.Command)
 	if err != nil {
@@ -42,7 +63,8 @@ func handleSetRange(params internal.HandlerFuncParams) ([]byte, error) {
 		return []byte(fmt.Sprintf(":%d\r\n", len(newStr))), nil
 	}
 
-	str, ok := params.GetValues(params.Context, []string{key})[key].(string)
+	current := params.GetValues(params.Context, []string{key})[key]
+	str, ok := getString(current)
 	if !ok {
 		return nil, fmt.Errorf("value at key %s is not a string", key)
 	}
@@ -50,7 +72,7 @@ func handleSetRange(params internal.HandlerFuncParams) ([]byte, error) {
 	// If the offset  >= length of the string, append the new string to the old one.
 	if offset >= len(str) {
 		newStr = str + newStr
-		if err = params.SetValues(params.Context, map[string]interface{}{key: newStr}); err != nil {
+		if err = params.SetValues(params.Context, map[string]interface{}{key: storedValue(current, newStr, newStr)}); err != nil {
 			return nil, err
 		}
 		return []byte(fmt.Sprintf(":%d\r\n", len(newStr))), nil
@@ -59,31 +81,34 @@ func handleSetRange(params internal.HandlerFuncParams) ([]byte, error) {
 	// If the offset is < 0, prepend the new string to the old one.
 	if offset < 0 {
 		newStr = newStr + str
-		if err = params.SetValues(params.Context, map[string]interface{}{key: newStr}); err != nil {
+		if err = params.SetValues(params.Context, map[string]interface{}{key: storedValue(current, newStr, newStr)}); err != nil {
 			return nil, err
 		}
 		return []byte(fmt.Sprintf(":%d\r\n", len(newStr))), nil
 	}
 
-	strRunes := []rune(str)
+	// The string is overwritten byte by byte, as bitmaps hold bytes that may not be valid UTF-8.
+	strBytes := []byte(str)
 
 	for i := 0; i < len(newStr); i++ {
-		// If we're still withing the length of the original string, replace the rune in strRunes
+		// If we're still withing the length of the original string, replace the byte in strBytes
 		if offset < len(str) {
-			strRunes[offset] = rune(newStr[i])
+			strBytes[offset] = newStr[i]
 			offset += 1
 			continue
 		}
-		// We are past the length of the original string, append the remainder of newStr to strRunes
-		strRunes = append(strRunes, []rune(newStr)[i:]...)
+		// We are past the length of the original string, append the remainder of newStr to strBytes
+		strBytes = append(strBytes, newStr[i:]...)
 		break
 	}
 
-	if err = params.SetValues(params.Context, map[string]interface{}{key: string(strRunes)}); err != nil {
+	if err = params.SetValues(params.Context, map[string]interface{}{
+		key: storedValue(current, string(strBytes), string(strBytes)),
+	}); err != nil {
 		return nil, err
 	}
 
-	return []byte(fmt.Sprintf(":%d\r\n", len(strRunes))), nil
+	return []byte(fmt.Sprintf(":%d\r\n", len(strBytes))), nil
 }
 
 func handleStrLen(params internal.HandlerFuncParams) ([]byte, error) {
@@ -99,7 +124,7 @@ func handleStrLen(params internal.HandlerFuncParams) ([]byte, error) {
 		return []byte(":0\r\n"), nil
 	}
 
-	value, ok := params.GetValues(params.Context, []string{key})[key].(string)
+	value, ok := getString(params.GetValues(params.Context, []string{key})[key])
 
 	if !ok {
 		return nil, fmt.Errorf("value at key %s is not a string", key)
@@ -129,7 +154,7 @@ func handleSubStr(params internal.HandlerFuncParams) ([]byte, error) {
 		return nil, fmt.Errorf("key %s does not exist", key)
 	}
 
-	value, ok := params.GetValues(params.Context, []string{key})[key].(string)
+	value, ok := getString(params.GetValues(params.Context, []string{key})[key])
 	if !ok {
 		return nil, fmt.Errorf("value at key %s is not a string", key)
 	}
@@ -184,13 +209,14 @@ func handleAppend(params internal.HandlerFuncParams) ([]byte, error) {
 		}
 		return []byte(fmt.Sprintf(":%d\r\n", len(value))), nil
 	}
-	currentValue, ok := params.GetValues(params.Context, []string{key})[key].(string)
+	current := params.GetValues(params.Context, []string{key})[key]
+	currentValue, ok := getString(current)
 	if !ok {
 		return nil, fmt.Errorf("Value at key %s is not a string", key)
 	}
 	newValue := fmt.Sprintf("%v%s", currentValue, value)
 	if err = params.SetValues(params.Context, map[string]interface{}{
-		key: internal.AdaptType(newValue),
+		key: storedValue(current, newValue, internal.AdaptType(newValue)),
 	}); err != nil {
 		return nil, err
 	}
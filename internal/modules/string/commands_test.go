@@ -553,4 +553,61 @@ func Test_String(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("Test_BitmapStrings", func(t *testing.T) {
+		t.Parallel()
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		client := resp.NewConn(conn)
+
+		// The keys written by the bitmap commands hold raw bytes, which the string commands read and write.
+		tests := []struct {
+			command []string
+			want    string
+		}{
+			{command: []string{"SETBIT", "BitmapStringKey1", "7", "1"}, want: "0"},
+			{command: []string{"STRLEN", "BitmapStringKey1"}, want: "1"},
+			{command: []string{"GETRANGE", "BitmapStringKey1", "0", "-1"}, want: "\x01"},
+			{command: []string{"APPEND", "BitmapStringKey1", "a"}, want: "2"},
+			{command: []string{"SETRANGE", "BitmapStringKey1", "0", "b"}, want: "2"},
+			{command: []string{"GETRANGE", "BitmapStringKey1", "0", "-1"}, want: "ba"},
+			{command: []string{"GETBIT", "BitmapStringKey1", "6"}, want: "1"},
+			// Bytes that are not valid UTF-8 are kept.
+			{command: []string{"SETBIT", "BitmapStringKey2", "0", "1"}, want: "0"},
+			{command: []string{"APPEND", "BitmapStringKey2", "\xff"}, want: "2"},
+			{command: []string{"SETRANGE", "BitmapStringKey2", "2", "\xfe"}, want: "3"},
+			{command: []string{"SETRANGE", "BitmapStringKey2", "1", "\x00"}, want: "3"},
+			{command: []string{"GETRANGE", "BitmapStringKey2", "0", "-1"}, want: "\x80\x00\xfe"},
+			{command: []string{"STRLEN", "BitmapStringKey2"}, want: "3"},
+		}
+
+		for _, test := range tests {
+			command := make([]resp.Value, len(test.command))
+			for i, c := range test.command {
+				command[i] = resp.StringValue(c)
+			}
+			if err = client.WriteArray(command); err != nil {
+				t.Error(err)
+				return
+			}
+			res, _, err := client.ReadValue()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if res.Error() != nil {
+				t.Errorf("%q: unexpected error %v", test.command, res.Error())
+				continue
+			}
+			if res.String() != test.want {
+				t.Errorf("%q: expected response %q, got %q", test.command, test.want, res.String())
+			}
+		}
+	})
 }
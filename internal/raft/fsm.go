@@ -20,9 +20,9 @@ import (
 	"fmt"
 	"github.com/echovault/sugardb/internal"
 	"github.com/echovault/sugardb/internal/config"
+	"github.com/echovault/sugardb/internal/constants"
 	"github.com/hashicorp/raft"
 	"io"
-	"log"
 	"net"
 	"strings"
 	"time"
@@ -39,6 +39,11 @@ type FSMOpts struct {
 	FinishSnapshot        func()
 	SetLatestSnapshotTime func(msec int64)
 	GetHandlerFuncParams  func(ctx context.Context, cmd []string, conn *net.Conn) internal.HandlerFuncParams
+	FeedMonitors          func(ctx context.Context, cmd []string)
+	RecordLatency         func(event string, latency time.Duration)
+	ExecuteTransaction    func(ctx context.Context, cmds [][]string, watched []internal.WatchedKey, conn *net.Conn) []byte
+	GetKeyVersions        func() internal.KeyVersions
+	SetKeyVersions        func(versions internal.KeyVersions)
 }
 
 type FSM struct {
@@ -57,6 +62,11 @@ func (fsm *FSM) Apply(log *raft.Log) interface{} {
 	default:
 		// No-Op
 	case raft.LogCommand:
+		start := time.Now()
+		defer func() {
+			fsm.options.RecordLatency(constants.RaftApplyLatencyEvent, time.Since(start))
+		}()
+
 		var request internal.ApplyRequest
 
 		if err := json.Unmarshal(log.Data, &request); err != nil {
@@ -70,6 +80,16 @@ func (fsm *FSM) Apply(log *raft.Log) interface{} {
 		ctx = context.WithValue(ctx, internal.ContextConnID("ConnectionID"), request.ConnectionID)
 		ctx = context.WithValue(ctx, "Protocol", request.Protocol)
 		ctx = context.WithValue(ctx, "Database", request.Database)
+		// Blocking commands must not stall the raft log.
+		ctx = context.WithValue(ctx, internal.ContextNoBlock("NoBlock"), true)
+		// MONITOR shows the commands applied from the raft log with the "raft" tag.
+		ctx = context.WithValue(ctx, internal.ContextMonitorSource("MonitorSource"), "raft")
+		// The keys modified by the entry get its index as their version.
+		ctx = context.WithValue(ctx, internal.ContextRaftIndex("RaftIndex"), log.Index)
+		// The handlers use the leader's time, so that every node derives the same values from the clock.
+		if request.Time != 0 {
+			ctx = context.WithValue(ctx, internal.ContextRaftTime("RaftTime"), time.Unix(0, request.Time))
+		}
 
 		switch strings.ToLower(request.Type) {
 		default:
@@ -79,12 +99,15 @@ func (fsm *FSM) Apply(log *raft.Log) interface{} {
 			}
 
 		case "delete-key":
+			ctx = context.WithValue(ctx, internal.ContextKeyEvent("KeyEvent"), request.Event)
 			if err := fsm.options.DeleteKey(ctx, request.Key); err != nil {
 				return internal.ApplyResponse{
 					Error:    err,
 					Response: nil,
 				}
 			}
+			// The deletion is shown as a DEL command, like the deletions that Redis replicates.
+			fsm.options.FeedMonitors(ctx, []string{"DEL", request.Key})
 			return internal.ApplyResponse{
 				Error:    nil,
 				Response: []byte("OK"),
@@ -114,7 +137,9 @@ func (fsm *FSM) Apply(log *raft.Log) interface{} {
 				handler = subCommand.HandlerFunc
 			}
 
-			if res, err := handler(fsm.options.GetHandlerFuncParams(ctx, request.CMD, nil)); err != nil {
+			res, err := handler(fsm.options.GetHandlerFuncParams(ctx, request.CMD, nil))
+			fsm.options.FeedMonitors(ctx, request.CMD)
+			if err != nil {
 				return internal.ApplyResponse{
 					Error:    err,
 					Response: nil,
@@ -125,6 +150,14 @@ func (fsm *FSM) Apply(log *raft.Log) interface{} {
 					Response: res,
 				}
 			}
+
+		case "transaction":
+			// Execute all the queued commands of the transaction atomically.
+			// The watched keys are checked here, so that no write applied before the transaction is missed.
+			return internal.ApplyResponse{
+				Error:    nil,
+				Response: fsm.options.ExecuteTransaction(ctx, request.Transaction, request.Watched, nil),
+			}
 		}
 	}
 
@@ -138,17 +171,17 @@ func (fsm *FSM) Snapshot() (raft.FSMSnapshot, error) {
 		startSnapshot:         fsm.options.StartSnapshot,
 		finishSnapshot:        fsm.options.FinishSnapshot,
 		setLatestSnapshotTime: fsm.options.SetLatestSnapshotTime,
+		recordLatency:         fsm.options.RecordLatency,
 		data:                  fsm.options.GetState(),
+		keyVersions:           fsm.options.GetKeyVersions(),
 	}), nil
 }
 
 // Restore implements raft.FSM interface
 func (fsm *FSM) Restore(snapshot io.ReadCloser) error {
 	b, err := io.ReadAll(snapshot)
-
 	if err != nil {
-		log.Fatal(err)
-		return err
+		return fmt.Errorf("restore snapshot: %v", err)
 	}
 
 	data := internal.SnapshotObject{
@@ -157,8 +190,7 @@ func (fsm *FSM) Restore(snapshot io.ReadCloser) error {
 	}
 
 	if err = json.Unmarshal(b, &data); err != nil {
-		log.Fatal(err)
-		return err
+		return fmt.Errorf("restore snapshot: %v", err)
 	}
 
 	// Set state
@@ -166,12 +198,17 @@ func (fsm *FSM) Restore(snapshot io.ReadCloser) error {
 		ctx := context.WithValue(context.Background(), "Database", database)
 		for key, keyData := range data {
 			if err = fsm.options.SetValues(ctx, map[string]interface{}{key: keyData.Value}); err != nil {
-				log.Fatal(err)
+				return fmt.Errorf("restore snapshot: %v", err)
 			}
 			fsm.options.SetExpiry(ctx, key, keyData.ExpireAt, false)
 		}
 	}
 
+	// Set the versions of the keys after the values, as setting a value gives the key a new version.
+	if data.KeyVersions != nil {
+		fsm.options.SetKeyVersions(*data.KeyVersions)
+	}
+
 	// Set latest snapshot milliseconds.
 	fsm.options.SetLatestSnapshotTime(data.LatestSnapshotMilliseconds)
 
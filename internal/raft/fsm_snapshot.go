@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"github.com/echovault/sugardb/internal"
 	"github.com/echovault/sugardb/internal/config"
+	"github.com/echovault/sugardb/internal/constants"
 	"github.com/hashicorp/raft"
 	"strconv"
 	"strings"
@@ -27,9 +28,11 @@ import (
 type SnapshotOpts struct {
 	config                config.Config
 	data                  map[int]map[string]internal.KeyData
+	keyVersions           internal.KeyVersions
 	startSnapshot         func()
 	finishSnapshot        func()
 	setLatestSnapshotTime func(msec int64)
+	recordLatency         func(event string, latency time.Duration)
 }
 
 type Snapshot struct {
@@ -46,6 +49,11 @@ func NewFSMSnapshot(opts SnapshotOpts) *Snapshot {
 func (s *Snapshot) Persist(sink raft.SnapshotSink) error {
 	s.options.startSnapshot()
 
+	start := time.Now()
+	defer func() {
+		s.options.recordLatency(constants.SnapshotLatencyEvent, time.Since(start))
+	}()
+
 	msec, err := strconv.Atoi(strings.Split(sink.ID(), "-")[2])
 	if err != nil {
 		_ = sink.Cancel()
@@ -55,6 +63,7 @@ func (s *Snapshot) Persist(sink raft.SnapshotSink) error {
 	snapshotObject := internal.SnapshotObject{
 		State:                      internal.FilterExpiredKeys(time.Now(), s.options.data),
 		LatestSnapshotMilliseconds: int64(msec),
+		KeyVersions:                &s.options.keyVersions,
 	}
 
 	o, err := json.Marshal(snapshotObject)
@@ -25,6 +25,8 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/raft"
@@ -42,6 +44,29 @@ type Opts struct {
 	FinishSnapshot        func()
 	SetLatestSnapshotTime func(msec int64)
 	GetHandlerFuncParams  func(ctx context.Context, cmd []string, conn *net.Conn) internal.HandlerFuncParams
+	FeedMonitors          func(ctx context.Context, cmd []string)
+	RecordLatency         func(event string, latency time.Duration)
+	ExecuteTransaction    func(ctx context.Context, cmds [][]string, watched []internal.WatchedKey, conn *net.Conn) []byte
+	GetKeyVersions        func() internal.KeyVersions
+	SetKeyVersions        func(versions internal.KeyVersions)
+}
+
+// Peer is a server in the raft configuration.
+type Peer struct {
+	ID       string
+	Address  string
+	Suffrage string
+}
+
+// Info holds the replication state of the node.
+type Info struct {
+	State         string // Follower, Candidate, Leader or Shutdown.
+	LeaderID      string
+	LeaderAddress string
+	Term          uint64
+	CommitIndex   uint64
+	AppliedIndex  uint64
+	Peers         []Peer
 }
 
 type Raft struct {
@@ -123,6 +148,11 @@ func (r *Raft) RaftInit(ctx context.Context) {
 			FinishSnapshot:        r.options.FinishSnapshot,
 			SetLatestSnapshotTime: r.options.SetLatestSnapshotTime,
 			GetHandlerFuncParams:  r.options.GetHandlerFuncParams,
+			FeedMonitors:          r.options.FeedMonitors,
+			RecordLatency:         r.options.RecordLatency,
+			ExecuteTransaction:    r.options.ExecuteTransaction,
+			GetKeyVersions:        r.options.GetKeyVersions,
+			SetKeyVersions:        r.options.SetKeyVersions,
 		}),
 		logStore,
 		stableStore,
@@ -211,6 +241,36 @@ func (r *Raft) RemoveServer(meta memberlist.NodeMeta) error {
 	return nil
 }
 
+// Info returns the replication state of the node.
+func (r *Raft) Info() Info {
+	stats := r.raft.Stats()
+	term, _ := strconv.ParseUint(stats["term"], 10, 64)
+	commitIndex, _ := strconv.ParseUint(stats["commit_index"], 10, 64)
+	leaderAddress, leaderID := r.raft.LeaderWithID()
+
+	info := Info{
+		State:         r.raft.State().String(),
+		LeaderID:      string(leaderID),
+		LeaderAddress: string(leaderAddress),
+		Term:          term,
+		CommitIndex:   commitIndex,
+		AppliedIndex:  r.raft.AppliedIndex(),
+		Peers:         make([]Peer, 0),
+	}
+
+	if future := r.raft.GetConfiguration(); future.Error() == nil {
+		for _, server := range future.Configuration().Servers {
+			info.Peers = append(info.Peers, Peer{
+				ID:       string(server.ID),
+				Address:  string(server.Address),
+				Suffrage: strings.ToLower(server.Suffrage.String()),
+			})
+		}
+	}
+
+	return info
+}
+
 func (r *Raft) TakeSnapshot() error {
 	return r.raft.Snapshot().Error()
 }
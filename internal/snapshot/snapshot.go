@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"github.com/echovault/sugardb/internal"
 	"github.com/echovault/sugardb/internal/clock"
+	"github.com/echovault/sugardb/internal/constants"
 	"io"
 	"io/fs"
 	"log"
@@ -42,14 +43,16 @@ type Engine struct {
 	clock                     clock.Clock
 	changeCount               atomic.Uint64
 	directory                 string
-	snapshotInterval          time.Duration
-	snapshotThreshold         uint64
+	snapshotInterval          atomic.Int64  // The interval between the snapshot checks in nanoseconds.
+	snapshotThreshold         atomic.Uint64 // The number of changes that trigger a snapshot.
+	intervalChanged           chan struct{} // Signals the snapshot goroutine that the interval has changed.
 	startSnapshotFunc         func()
 	finishSnapshotFunc        func()
 	getStateFunc              func() map[int]map[string]internal.KeyData
 	setLatestSnapshotTimeFunc func(msec int64)
 	getLatestSnapshotTimeFunc func() int64
 	setKeyDataFunc            func(database int, key string, data internal.KeyData)
+	recordLatencyFunc         func(event string, latency time.Duration)
 }
 
 func WithClock(clock clock.Clock) func(engine *Engine) {
@@ -66,13 +69,13 @@ func WithDirectory(directory string) func(engine *Engine) {
 
 func WithInterval(interval time.Duration) func(engine *Engine) {
 	return func(engine *Engine) {
-		engine.snapshotInterval = interval
+		engine.snapshotInterval.Store(int64(interval))
 	}
 }
 
 func WithThreshold(threshold uint64) func(engine *Engine) {
 	return func(engine *Engine) {
-		engine.snapshotThreshold = threshold
+		engine.snapshotThreshold.Store(threshold)
 	}
 }
 
@@ -112,13 +115,18 @@ func WithSetKeyDataFunc(f func(database int, key string, data internal.KeyData))
 	}
 }
 
+func WithRecordLatencyFunc(f func(event string, latency time.Duration)) func(engine *Engine) {
+	return func(engine *Engine) {
+		engine.recordLatencyFunc = f
+	}
+}
+
 func NewSnapshotEngine(options ...func(engine *Engine)) *Engine {
 	engine := &Engine{
 		clock:              clock.NewClock(),
 		changeCount:        atomic.Uint64{},
 		directory:          "",
-		snapshotInterval:   5 * time.Minute,
-		snapshotThreshold:  1000,
+		intervalChanged:    make(chan struct{}, 1),
 		startSnapshotFunc:  func() {},
 		finishSnapshotFunc: func() {},
 		getStateFunc: func() map[int]map[string]internal.KeyData {
@@ -129,28 +137,42 @@ func NewSnapshotEngine(options ...func(engine *Engine)) *Engine {
 		getLatestSnapshotTimeFunc: func() int64 {
 			return 0
 		},
+		recordLatencyFunc: func(event string, latency time.Duration) {},
 	}
 
+	engine.snapshotInterval.Store(int64(5 * time.Minute))
+	engine.snapshotThreshold.Store(1000)
+
 	for _, option := range options {
 		option(engine)
 	}
 
-	if engine.snapshotInterval != 0 {
-		go func() {
-			ticker := time.NewTicker(engine.snapshotInterval)
-			defer func() {
-				ticker.Stop()
-			}()
-			for {
-				<-ticker.C
-				if engine.changeCount.Load() == engine.snapshotThreshold {
+	go func() {
+		// The ticker is stopped while the interval is 0.
+		ticker := time.NewTicker(time.Minute)
+		ticker.Stop()
+		if interval := engine.interval(); interval != 0 {
+			ticker.Reset(interval)
+		}
+		defer func() {
+			ticker.Stop()
+		}()
+		for {
+			select {
+			case <-ticker.C:
+				if engine.changeCount.Load() == engine.snapshotThreshold.Load() {
 					if err := engine.TakeSnapshot(); err != nil {
 						log.Println(err)
 					}
 				}
+			case <-engine.intervalChanged:
+				ticker.Stop()
+				if interval := engine.interval(); interval != 0 {
+					ticker.Reset(interval)
+				}
 			}
-		}()
-	}
+		}
+	}()
 
 	return engine
 }
@@ -159,6 +181,11 @@ func (engine *Engine) TakeSnapshot() error {
 	engine.startSnapshotFunc()
 	defer engine.finishSnapshotFunc()
 
+	start := time.Now()
+	defer func() {
+		engine.recordLatencyFunc(constants.SnapshotLatencyEvent, time.Since(start))
+	}()
+
 	// Extract current time
 	msec := engine.clock.Now().UnixMilli()
 
@@ -378,6 +405,29 @@ func (engine *Engine) IncrementChangeCount() {
 	engine.changeCount.Add(1)
 }
 
+// ChangeCount returns the number of changes made to the store since the latest snapshot.
+func (engine *Engine) ChangeCount() uint64 {
+	return engine.changeCount.Load()
+}
+
+// SetInterval changes the interval between the snapshot checks. Periodic snapshots are disabled when it's 0.
+func (engine *Engine) SetInterval(interval time.Duration) {
+	engine.snapshotInterval.Store(int64(interval))
+	select {
+	case engine.intervalChanged <- struct{}{}:
+	default:
+	}
+}
+
+// SetThreshold changes the number of changes that trigger a snapshot.
+func (engine *Engine) SetThreshold(threshold uint64) {
+	engine.snapshotThreshold.Store(threshold)
+}
+
+func (engine *Engine) interval() time.Duration {
+	return time.Duration(engine.snapshotInterval.Load())
+}
+
 func (engine *Engine) resetChangeCount() {
 	engine.changeCount.Store(0)
 }
@@ -16,6 +16,7 @@ package internal
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
@@ -32,6 +33,73 @@ type KeyData struct {
 	ExpireAt time.Time
 }
 
+// compositeTypeDecoders holds the functions that restore the composite types persisted with a type tag.
+var compositeTypeDecoders = make(map[string]func(data []byte) (interface{}, error))
+
+// CompositeValue is implemented by the composite types registered with RegisterCompositeType.
+// CompositeType returns the name the type is registered with.
+type CompositeValue interface {
+	json.Marshaler
+	CompositeType() string
+}
+
+// RegisterCompositeType registers the function that restores a composite type from a snapshot or the AOF preamble.
+// The decode function receives the data encoded by the MarshalJSON method of the type. It must be called from the
+// init function of the package.
+func RegisterCompositeType(name string, decode func(data []byte) (interface{}, error)) {
+	compositeTypeDecoders[name] = decode
+}
+
+// keyDataJSON is the JSON encoding of KeyData. The type tag of a composite value is stored next to the value,
+// where the value itself can't set it.
+type keyDataJSON struct {
+	Value    json.RawMessage
+	ExpireAt time.Time
+	Type     string `json:",omitempty"`
+}
+
+// MarshalJSON encodes the value of a key with the type tag of its composite type, if any.
+func (k KeyData) MarshalJSON() ([]byte, error) {
+	value, err := json.Marshal(k.Value)
+	if err != nil {
+		return nil, err
+	}
+	data := keyDataJSON{Value: value, ExpireAt: k.ExpireAt}
+	if composite, ok := k.Value.(CompositeValue); ok {
+		data.Type = composite.CompositeType()
+	}
+	return json.Marshal(data)
+}
+
+// UnmarshalJSON restores the value of a key. Values with a type tag are decoded with the function registered
+// for the composite type. Any other value is decoded into an interface{}.
+func (k *KeyData) UnmarshalJSON(b []byte) error {
+	var data keyDataJSON
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	k.ExpireAt = data.ExpireAt
+
+	if data.Type != "" {
+		decode, ok := compositeTypeDecoders[data.Type]
+		if !ok {
+			return fmt.Errorf("unknown composite type %s", data.Type)
+		}
+		value, err := decode(data.Value)
+		if err != nil {
+			return err
+		}
+		k.Value = value
+		return nil
+	}
+
+	if data.Value == nil {
+		k.Value = nil
+		return nil
+	}
+	return json.Unmarshal(data.Value, &k.Value)
+}
+
 func (k *KeyData) GetMem() (int64, error) {
 	var size int64
 	size = int64(unsafe.Sizeof(k.ExpireAt))
@@ -95,14 +163,78 @@ func (k *KeyData) GetMem() (int64, error) {
 type ContextServerID string
 type ContextConnID string
 
+// ContextTransaction marks the context of a command that is executed as part of a transaction.
+// The store lock is already held for the whole transaction when this value is true.
+type ContextTransaction string
+
+// ContextNoBlock marks the context of a command that is replayed from the AOF or applied from the raft log.
+// Blocking commands return immediately instead of waiting when this value is true.
+type ContextNoBlock string
+
+// ContextRaftApply holds the function that applies a blocking command through the raft log without blocking.
+// Blocking commands run on the raft leader with this value wait for their keys locally and call the function instead
+// of modifying the keys, so that waiting doesn't stall the raft log. The function returns a nil response when the
+// command could not make progress.
+type ContextRaftApply string
+
+// ContextCommand holds the lower case name of the command behind the context. It's the event name of the keyspace
+// notifications published for the keys that the command modifies.
+type ContextCommand string
+
+// ContextKeyEvent holds the reason a key is deleted, "expired" or "evicted", so that the keyspace notification of the
+// deletion reports it. Deletions without it are reported as "del".
+type ContextKeyEvent string
+
+// ContextReadCommand marks the context of a command that only reads keys. The keys it looks up are counted as
+// keyspace hits and misses.
+type ContextReadCommand string
+
+// ContextMonitorSource holds the origin of a command that is not sent by a client: "aof" for the commands replayed
+// from the AOF, "raft" for the commands applied from the raft log and "lua" for the commands called by scripts.
+// MONITOR shows it in place of the client address.
+type ContextMonitorSource string
+
+// ContextPropagate holds the commands that are written to the AOF in place of the command behind the context.
+// The handlers set them with HandlerFuncParams.Propagate.
+type ContextPropagate string
+
+// ContextRaftIndex holds the index of the raft log entry that is being applied. In cluster mode, the keys modified by the
+// entry get the index as their version, so that the versions compared by WATCH are the same on every node.
+type ContextRaftIndex string
+
+// ContextRaftTime holds the time of the leader's clock when the raft log entry being applied was proposed.
+// The handlers read it as the current time, so that the values derived from the clock are the same on every node.
+type ContextRaftTime string
+
 type ApplyRequest struct {
-	Type         string   `json:"Type"` // command | delete-key
-	ServerID     string   `json:"ServerID"`
-	ConnectionID string   `json:"ConnectionID"`
-	Protocol     int      `json:"Protocol"`
-	Database     int      `json:"Database"`
-	CMD          []string `json:"CMD"`
-	Key          string   `json:"Key"` // Optional: Used with delete-key type to specify which key to delete.
+	Type         string       `json:"Type"` // command | delete-key | transaction
+	ServerID     string       `json:"ServerID"`
+	ConnectionID string       `json:"ConnectionID"`
+	Protocol     int          `json:"Protocol"`
+	Database     int          `json:"Database"`
+	CMD          []string     `json:"CMD"`
+	Key          string       `json:"Key"`         // Optional: Used with delete-key type to specify which key to delete.
+	Event        string       `json:"Event"`       // Optional: Used with delete-key type to specify why the key is deleted.
+	Transaction  [][]string   `json:"Transaction"` // Optional: Used with transaction type to specify the queued commands.
+	Watched      []WatchedKey `json:"Watched"`     // Optional: Used with transaction type to specify the keys watched by the client.
+	Time         int64        `json:"Time"`        // Optional: The leader's clock in unix nanoseconds when the command or transaction is proposed.
+}
+
+// WatchedKey is a key watched with WATCH and the version it had when it was watched.
+// Deleted is the version of the latest deletion in the database, which detects a watched key that didn't exist
+// and was created and deleted again.
+type WatchedKey struct {
+	Database int    `json:"Database"`
+	Key      string `json:"Key"`
+	Version  uint64 `json:"Version"`
+	Deleted  uint64 `json:"Deleted"`
+}
+
+// KeyVersions holds the versions of the keys and of the latest deletion in each database. They are saved in the
+// raft snapshots so that the nodes restored from a snapshot compare the same versions as the other nodes.
+type KeyVersions struct {
+	Versions map[int]map[string]uint64 `json:"Versions"`
+	Deleted  map[int]uint64            `json:"Deleted"`
 }
 
 type ApplyResponse struct {
@@ -113,6 +245,7 @@ type ApplyResponse struct {
 type SnapshotObject struct {
 	State                      map[int]map[string]KeyData
 	LatestSnapshotMilliseconds int64
+	KeyVersions                *KeyVersions `json:",omitempty"` // Only saved in the raft snapshots.
 }
 
 // ServerInfo holds information about the server/node.
@@ -127,6 +260,59 @@ type ServerInfo struct {
 	MaxMemory  uint64
 }
 
+// InfoField is a field of an INFO section.
+type InfoField struct {
+	Name  string
+	Value string
+}
+
+// InfoSection is a section of the INFO report with its fields in the order they're reported.
+type InfoSection struct {
+	Name   string // The section name, for example "Server".
+	Fields []InfoField
+}
+
+// SlowlogEntry is a command recorded in the slow log because its execution time exceeded the
+// slowlog-log-slower-than threshold.
+type SlowlogEntry struct {
+	Id         uint64   // The unique, incrementing id of the entry.
+	Timestamp  int64    // The unix time in seconds at which the command was executed.
+	Duration   int64    // The execution time of the command in microseconds.
+	Args       []string // The command and its arguments, truncated when they're too many or too long.
+	ClientAddr string   // The address of the client that executed the command. Empty for the embedded API.
+	ClientName string   // The name of the client that executed the command, set with CLIENT SETNAME.
+}
+
+// LatencyEvent is the latest latency spike of an event tracked by the latency monitor.
+type LatencyEvent struct {
+	Name      string // The name of the event, for example "command" or "aof-fsync".
+	Timestamp int64  // The unix time in seconds of the latest spike.
+	Latest    int64  // The latency of the latest spike in milliseconds.
+	Max       int64  // The highest latency of the event in milliseconds.
+}
+
+// LatencySample is a latency spike of an event. The spikes that happen in the same second are merged into one
+// sample with the highest latency.
+type LatencySample struct {
+	Timestamp int64 // The unix time in seconds of the spike.
+	Latency   int64 // The latency of the spike in milliseconds.
+}
+
+// LatencyHistogram is the distribution of the execution times of a command.
+type LatencyHistogram struct {
+	Command string // The name of the command, for example "get" or "client|list".
+	Calls   uint64 // The number of executions.
+	// Buckets holds the number of executions that took at most each power of 2 microseconds, from the fastest to
+	// the slowest execution. The counts are cumulative.
+	Buckets []LatencyHistogramBucket
+}
+
+// LatencyHistogramBucket is a bucket of LatencyHistogram.
+type LatencyHistogramBucket struct {
+	Usec  uint64 // The upper bound of the bucket in microseconds.
+	Count uint64 // The number of executions that took at most Usec microseconds.
+}
+
 // ConnectionInfo holds information about the connection
 type ConnectionInfo struct {
 	Id       uint64 // Connection id.
@@ -135,6 +321,55 @@ type ConnectionInfo struct {
 	Database int    // Database index currently being used by the connection.
 }
 
+// ClientInfo holds the information about a TCP client that is reported by CLIENT LIST and CLIENT INFO.
+type ClientInfo struct {
+	Id                   uint64 // Connection id.
+	Addr                 string // Address of the client.
+	LocalAddr            string // Address of the server side of the connection.
+	Name                 string // Name set with CLIENT SETNAME or HELLO.
+	Age                  int64  // Seconds since the connection was established.
+	Idle                 int64  // Seconds since the last command of the client.
+	Flags                string // Client flags. N when no flag is set.
+	Database             int    // Database index currently being used by the connection.
+	Subscriptions        int    // Number of channel subscriptions.
+	PatternSubscriptions int    // Number of pattern subscriptions.
+	Multi                int    // Number of commands queued in the transaction. -1 when there's no transaction.
+	QueryBuffer          int    // Size of the command that is being executed, in bytes.
+	OutputBuffer         int    // Size of the response that is being written, in bytes.
+	User                 string // The ACL user of the connection.
+	Protocol             int    // The RESP protocol used by the client.
+	Command              string // The last command executed by the client.
+}
+
+// ClientKillFilter selects the clients closed by CLIENT KILL. Only the clients that match all the filters that are
+// set are closed.
+type ClientKillFilter struct {
+	Id        uint64 // The connection id. 0 matches any id.
+	Addr      string // The address of the client.
+	LocalAddr string // The address of the server side of the connection.
+	User      string // The ACL user of the connection.
+	Type      string // The type of client: normal, pubsub, master or replica.
+	SkipMe    bool   // Don't close the connection that calls CLIENT KILL.
+}
+
+// TrackingOptions holds the options of client side caching set with CLIENT TRACKING.
+type TrackingOptions struct {
+	Redirect uint64   // The id of the connection that receives the invalidation messages. 0 if they're not redirected.
+	Prefixes []string // The key prefixes tracked in broadcasting mode. All the keys are tracked if it's empty.
+	BCast    bool     // Broadcasting mode. Invalidations are sent for every key that matches the prefixes.
+	OptIn    bool     // Only the keys read by the command after CLIENT CACHING YES are tracked.
+	OptOut   bool     // The keys read by the command after CLIENT CACHING NO are not tracked.
+	NoLoop   bool     // No invalidations are sent for the keys modified by the connection itself.
+}
+
+// TrackingInfo holds the client side caching state of a connection.
+type TrackingInfo struct {
+	TrackingOptions
+	Enabled        bool // True if tracking is on.
+	Caching        bool // True if CLIENT CACHING was called for the next command.
+	BrokenRedirect bool // True if the connection that receives the invalidation messages is closed.
+}
+
 // KeyExtractionFuncResult is the return type of the KeyExtractionFunc for the command/subcommand.
 type KeyExtractionFuncResult struct {
 	Channels  []string // The pubsub channels the command accesses. For non pubsub commands, this should be an empty slice.
@@ -165,6 +400,14 @@ type HandlerFuncParams struct {
 	GetExpiry func(ctx context.Context, key string) time.Time
 	// DeleteKey deletes the specified key. Returns an error if the deletion was unsuccessful.
 	DeleteKey func(ctx context.Context, key string) error
+	// GetKeyVersions returns the versions of the keys. The version of a key changes every time it's modified.
+	GetKeyVersions func(ctx context.Context, keys []string) map[string]uint64
+	// DeleteKeyIfVersion deletes the key if it exists and its version is still version.
+	// Returns false if the key was modified or deleted in the meantime.
+	DeleteKeyIfVersion func(ctx context.Context, key string, version uint64) (bool, error)
+	// GetKeys returns all the keys in the database selected in the context.
+	// Expired keys are not included.
+	GetKeys func(ctx context.Context) []string
 	// GetValues retrieves the values from the specified keys.
 	// Non-existent keys will be nil.
 	GetValues func(ctx context.Context, keys []string) map[string]interface{}
@@ -172,6 +415,9 @@ type HandlerFuncParams struct {
 	SetValues func(ctx context.Context, entries map[string]interface{}) error
 	// Set expiry sets the expiry time of the key.
 	SetExpiry func(ctx context.Context, key string, expire time.Time, touch bool)
+	// Propagate replaces the command written to the AOF with the provided commands, so that replaying the AOF
+	// produces the same result as the execution of the command. Nothing is written when no command is provided.
+	Propagate func(cmds ...[]string)
 	// GetClock gets the clock used by the server.
 	// Use this when making use of time methods like .Now and .After.
 	// This inversion of control is a helper for testing as the clock is automatically mocked in tests.
@@ -204,8 +450,66 @@ type HandlerFuncParams struct {
 	SetConnectionInfo func(conn *net.Conn, clientname string, protocol int, database int)
 	// GetConnectionInfo returns information about the current connection.
 	GetConnectionInfo func(conn *net.Conn) ConnectionInfo
+	// SetClientTracking turns client side caching on or off for the connection. When it's turned on again,
+	// the new prefixes are added to the prefixes that are already tracked. The options are ignored when turning it off.
+	SetClientTracking func(ctx context.Context, conn *net.Conn, enable bool, options TrackingOptions) error
+	// SetClientCaching sets whether the keys read by the next command of the connection are tracked.
+	// It's only allowed in OPTIN and OPTOUT modes.
+	SetClientCaching func(conn *net.Conn, caching bool) error
+	// GetClientTracking returns the client side caching state of the connection.
+	GetClientTracking func(conn *net.Conn) TrackingInfo
+	// ListClients returns the information about the TCP clients, ordered by id.
+	ListClients func() []ClientInfo
+	// KillClients closes the TCP connections that match the filter and returns the number of closed connections.
+	// The connection is the one that calls the function.
+	KillClients func(conn *net.Conn, filter ClientKillFilter) int
+	// SetClientName sets the name of the connection. An empty name removes it.
+	SetClientName func(conn *net.Conn, name string)
+	// PauseClients suspends the commands of the TCP clients until the timeout elapses or UnpauseClients is called.
+	// When writeOnly is true, only the commands that write to the keyspace are suspended.
+	PauseClients func(timeout time.Duration, writeOnly bool)
+	// UnpauseClients resumes the commands suspended by PauseClients.
+	UnpauseClients func()
+	// SetClientNoEvict sets the no-evict flag of the connection.
+	SetClientNoEvict func(conn *net.Conn, noEvict bool) error
+	// SetClientReply sets whether the server replies to the commands of the connection.
+	// The mode is one of "on", "off" or "skip".
+	SetClientReply func(conn *net.Conn, mode string) error
 	// GetServerInfo returns information about the server when requested by commands such as HELLO.
 	GetServerInfo func() ServerInfo
+	// GetInfo returns the sections of the INFO report. The default sections are returned when no section is
+	// provided. "all" and "everything" return all the sections. Unknown sections are ignored.
+	GetInfo func(sections ...string) []InfoSection
+	// GetConfig returns the configuration parameters whose name matches one of the glob patterns,
+	// mapped to their value.
+	GetConfig func(patterns ...string) map[string]string
+	// SetConfig changes the configuration parameters, by name, while the server is running.
+	// None of the parameters is changed if one of them is unknown, immutable or invalid.
+	SetConfig func(values map[string]string) error
+	// RewriteConfig persists the runtime configuration to the config file the server was started with.
+	RewriteConfig func() error
+	// ResetStats resets the counters reported by INFO.
+	ResetStats func()
+	// GetSlowlog returns the count most recent entries of the slow log, newest first.
+	// All the entries are returned when count is negative.
+	GetSlowlog func(count int) []SlowlogEntry
+	// SlowlogLen returns the number of entries in the slow log.
+	SlowlogLen func() int
+	// ResetSlowlog removes all the entries from the slow log.
+	ResetSlowlog func()
+	// Monitor sends the commands processed by the server to the TCP connection until it's closed.
+	// It writes the OK reply of the MONITOR command itself so that it precedes the streamed commands.
+	Monitor func(conn *net.Conn) error
+	// GetLatencyLatest returns the latest spike of each event tracked by the latency monitor, ordered by name.
+	GetLatencyLatest func() []LatencyEvent
+	// GetLatencyHistory returns the spikes of the event, oldest first.
+	GetLatencyHistory func(event string) []LatencySample
+	// ResetLatency removes the spikes of the events, or of all the events when none is given.
+	// It returns the number of events that were reset.
+	ResetLatency func(events ...string) int
+	// GetLatencyHistograms returns the latency histograms of the commands, or of all the executed commands when
+	// none is given, ordered by command name.
+	GetLatencyHistograms func(commands ...string) []LatencyHistogram
 	// SwapDBs swaps two databases,
 	// so that immediately all the clients connected to a given database will see the data of the other database,
 	// and the other way around.
@@ -222,6 +526,45 @@ type HandlerFuncParams struct {
 	GetObjectFrequency func(ctx context.Context, keys string) (int, error)
 	// GetObjectIdleTime retrieves the time in seconds since the last access of a key. Can only be used with LRU type eviction policies.
 	GetObjectIdleTime func(ctx context.Context, keys string) (float64, error)
+	// SetObjectFrequency seeds the access frequency count of a key that is about to be written with SetValues.
+	// The count replaces the access of the write in the LFU cache. It's ignored when the eviction policy is not
+	// a type of LFU.
+	SetObjectFrequency func(ctx context.Context, key string, freq int)
+	// SetObjectIdleTime seeds the time since the last access of a key that is about to be written with SetValues.
+	// The time replaces the access of the write in the LRU cache. It's ignored when the eviction policy is not
+	// a type of LRU.
+	SetObjectIdleTime func(ctx context.Context, key string, idleTime time.Duration)
+	// StartTransaction starts queueing the commands sent by the connection until EXEC or DISCARD is called.
+	// A nil connection refers to the embedded API session.
+	StartTransaction func(conn *net.Conn) error
+	// ExecTransaction atomically executes the commands queued by the connection and
+	// returns an array with the response of each command.
+	ExecTransaction func(ctx context.Context, conn *net.Conn) ([]byte, error)
+	// DiscardTransaction drops the commands queued by the connection.
+	DiscardTransaction func(conn *net.Conn) error
+	// WatchKeys marks the keys so that the next transaction of the connection is aborted if any of them is modified.
+	WatchKeys func(ctx context.Context, conn *net.Conn, keys []string) error
+	// UnwatchKeys forgets all the keys watched by the connection.
+	UnwatchKeys func(ctx context.Context, conn *net.Conn)
+	// EvalScript atomically runs the Lua script with the provided keys and arguments and returns its response.
+	EvalScript func(ctx context.Context, conn *net.Conn, script string, keys []string, args []string) ([]byte, error)
+	// LoadScript adds the script to the script cache and returns its SHA1 digest.
+	LoadScript func(script string) string
+	// GetScript returns the cached script with the SHA1 digest.
+	GetScript func(sha string) (string, bool)
+	// FlushScripts removes all the scripts from the script cache.
+	FlushScripts func()
+	// KillScript stops the script that is running. A script that already executed write commands can't be stopped.
+	KillScript func() error
+	// BlockOnKeys registers the connection to be notified when any of the keys is written.
+	// The channel receives a value after the next write to one of the keys. The returned function
+	// must be called to stop receiving notifications. The channel is nil when the command must not block,
+	// for example inside a transaction or a script.
+	BlockOnKeys func(ctx context.Context, keys []string) (<-chan struct{}, func())
+	// IsFirstBlocked returns true if no other connection has been blocked on the key for longer than the one that
+	// received the channel from BlockOnKeys. Commands that consume what they wait for, like BLPOP, only consume
+	// from a key when it's their turn so that blocked connections are served in the order they blocked.
+	IsFirstBlocked func(ctx context.Context, key string, ready <-chan struct{}) bool
 }
 
 // HandlerFunc is a functions described by a command where the bulk of the command handling is done.
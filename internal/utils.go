@@ -18,9 +18,11 @@ import (
 	"bufio"
 	"bytes"
 	"cmp"
+	"container/heap"
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"log"
 	"math/big"
@@ -34,6 +36,7 @@ import (
 	"time"
 
 	"github.com/echovault/sugardb/internal/constants"
+	"github.com/gobwas/glob"
 	"github.com/sethvargo/go-retry"
 	"github.com/tidwall/resp"
 )
@@ -256,6 +259,139 @@ func CompareLex(s1 string, s2 string) int {
 	return c
 }
 
+// ScanOptions holds the cursor and optional parameters of the cursor-based iteration
+// commands (SCAN, HSCAN, SSCAN and ZSCAN).
+type ScanOptions struct {
+	Cursor uint64    // The cursor to resume the iteration from. 0 starts a new iteration.
+	Match  glob.Glob // The compiled MATCH pattern. Nil when no pattern is provided.
+	Count  int       // The number of members to examine in this call. Defaults to 10.
+	Type   string    // The TYPE filter. Only accepted when parsing with allowType set to true.
+}
+
+// ParseScanOptions parses the "cursor [MATCH pattern] [COUNT count] [TYPE type]" tail of
+// a cursor-based iteration command. The TYPE option is only accepted when allowType is true.
+func ParseScanOptions(cmd []string, allowType bool) (ScanOptions, error) {
+	if len(cmd) == 0 {
+		return ScanOptions{}, errors.New(constants.WrongArgsResponse)
+	}
+
+	cursor, err := strconv.ParseUint(cmd[0], 10, 64)
+	if err != nil {
+		return ScanOptions{}, errors.New("invalid cursor")
+	}
+
+	options := ScanOptions{Cursor: cursor, Count: 10}
+
+	for i := 1; i < len(cmd); i += 2 {
+		if i+1 >= len(cmd) {
+			return ScanOptions{}, errors.New("syntax error")
+		}
+		switch strings.ToLower(cmd[i]) {
+		case "match":
+			if options.Match, err = glob.Compile(cmd[i+1]); err != nil {
+				return ScanOptions{}, fmt.Errorf("invalid MATCH pattern %s", cmd[i+1])
+			}
+		case "count":
+			count, err := strconv.Atoi(cmd[i+1])
+			if err != nil {
+				return ScanOptions{}, errors.New("count must be an integer")
+			}
+			if count < 1 {
+				return ScanOptions{}, errors.New("count must be greater than 0")
+			}
+			options.Count = count
+		case "type":
+			if !allowType {
+				return ScanOptions{}, errors.New("syntax error")
+			}
+			options.Type = strings.ToLower(cmd[i+1])
+		default:
+			return ScanOptions{}, errors.New("syntax error")
+		}
+	}
+
+	return options, nil
+}
+
+type scanEntry struct {
+	hash   uint64
+	member string
+}
+
+func compareScanEntries(a, b scanEntry) int {
+	if c := cmp.Compare(a.hash, b.hash); c != 0 {
+		return c
+	}
+	return strings.Compare(a.member, b.member)
+}
+
+// scanHeap is a max heap of scan entries, used to keep the smallest entries seen during a scan.
+type scanHeap []scanEntry
+
+func (h scanHeap) Len() int           { return len(h) }
+func (h scanHeap) Less(i, j int) bool { return compareScanEntries(h[i], h[j]) > 0 }
+func (h scanHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *scanHeap) Push(x any)        { *h = append(*h, x.(scanEntry)) }
+func (h *scanHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// ScanCursor returns the next page of members for a cursor-based iteration.
+// Members are visited in the order of their 64-bit FNV-1a hash and the returned cursor is the
+// hash value to resume from. This keeps the cursor stable when the collection is mutated
+// between calls: a member that is present for the whole iteration is returned at least once.
+// Members that share a hash are always returned in the same page.
+// A returned cursor of 0 means the iteration is complete.
+func ScanCursor(members []string, cursor uint64, count int) ([]string, uint64) {
+	h := &scanHeap{}
+	var candidates []scanEntry
+
+	for _, member := range members {
+		hasher := fnv.New64a()
+		_, _ = hasher.Write([]byte(member))
+		entry := scanEntry{hash: hasher.Sum64(), member: member}
+		if entry.hash < cursor {
+			continue
+		}
+		candidates = append(candidates, entry)
+		if h.Len() < count {
+			heap.Push(h, entry)
+		} else if compareScanEntries(entry, (*h)[0]) < 0 {
+			(*h)[0] = entry
+			heap.Fix(h, 0)
+		}
+	}
+
+	if h.Len() == 0 {
+		return []string{}, 0
+	}
+
+	// The heap holds the smallest entries, so every candidate up to the largest hash in the heap
+	// belongs to this page. This also pulls in members that share the boundary hash.
+	boundary := (*h)[0].hash
+	page := make([]scanEntry, 0, h.Len())
+	var next uint64
+	for _, entry := range candidates {
+		if entry.hash <= boundary {
+			page = append(page, entry)
+			continue
+		}
+		next = boundary + 1
+	}
+	slices.SortFunc(page, compareScanEntries)
+
+	res := make([]string, len(page))
+	for i, entry := range page {
+		res[i] = entry.member
+	}
+
+	return res, next
+}
+
 func EncodeCommand(cmd []string) []byte {
 	res := fmt.Sprintf("*%d\r\n", len(cmd))
 	for _, token := range cmd {
@@ -360,6 +496,48 @@ func ParseNestedStringArrayResponse(b []byte) ([][]string, error) {
 	return arr, nil
 }
 
+// ParseScanResponse parses the response of the cursor-based iteration commands.
+// It returns the next cursor and the page of elements.
+func ParseScanResponse(b []byte) (uint64, []string, error) {
+	r := resp.NewReader(bytes.NewReader(b))
+	v, _, err := r.ReadValue()
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(v.Array()) != 2 {
+		return 0, nil, errors.New("invalid scan response")
+	}
+	cursor, err := strconv.ParseUint(v.Array()[0].String(), 10, 64)
+	if err != nil {
+		return 0, nil, err
+	}
+	elements := make([]string, len(v.Array()[1].Array()))
+	for i, e := range v.Array()[1].Array() {
+		elements[i] = e.String()
+	}
+	return cursor, elements, nil
+}
+
+// ParseRawArrayResponse splits an array response into the RESP encoded bytes of each of its elements.
+// A null array returns a nil slice.
+func ParseRawArrayResponse(b []byte) ([][]byte, error) {
+	r := resp.NewReader(bytes.NewReader(b))
+	v, _, err := r.ReadValue()
+	if err != nil {
+		return nil, err
+	}
+	if v.IsNull() {
+		return nil, nil
+	}
+	arr := make([][]byte, len(v.Array()))
+	for i, e := range v.Array() {
+		if arr[i], err = e.MarshalRESP(); err != nil {
+			return nil, err
+		}
+	}
+	return arr, nil
+}
+
 func ParseIntegerArrayResponse(b []byte) ([]int, error) {
 	r := resp.NewReader(bytes.NewReader(b))
 	v, _, err := r.ReadValue()
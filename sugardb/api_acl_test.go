@@ -153,10 +153,12 @@ func TestSugarDB_ACLCat(t *testing.T) {
 			name: "1. Get all ACL categories loaded on the server",
 			args: make([]string, 0),
 			want: []string{
-				constants.AdminCategory, constants.ConnectionCategory, constants.DangerousCategory,
-				constants.HashCategory, constants.FastCategory, constants.KeyspaceCategory, constants.ListCategory,
-				constants.PubSubCategory, constants.ReadCategory, constants.WriteCategory, constants.SetCategory,
-				constants.SortedSetCategory, constants.SlowCategory, constants.StringCategory,
+				constants.AdminCategory, constants.BitmapCategory, constants.BlockingCategory, constants.ConnectionCategory,
+				constants.DangerousCategory, constants.GeoCategory, constants.HashCategory, constants.HyperLogLogCategory,
+				constants.FastCategory, constants.KeyspaceCategory, constants.ListCategory, constants.PubSubCategory,
+				constants.ReadCategory, constants.ScriptingCategory, constants.WriteCategory, constants.SetCategory,
+				constants.SortedSetCategory, constants.SlowCategory, constants.StreamCategory, constants.StringCategory,
+				constants.TransactionCategory,
 			},
 			wantErr: false,
 		},
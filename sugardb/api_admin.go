@@ -15,11 +15,15 @@
 package sugardb
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"github.com/echovault/sugardb/internal"
+	"github.com/tidwall/resp"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // CommandListOptions modifies the result from the CommandList command.
@@ -194,6 +198,391 @@ func (server *SugarDB) LastSave() (int, error) {
 	return internal.ParseIntegerResponse(b)
 }
 
+// Info returns information and statistics about the server.
+//
+// Parameters:
+//
+// `sections` - ...string - The sections to return: server, clients, memory, persistence, stats, replication,
+// keyspace and commandstats. All the sections except commandstats are returned when no section is provided.
+// "all" and "everything" return all the sections. Unknown sections are ignored.
+//
+// Returns: A map of the lowercase section names to the fields of each section, for example
+// info["memory"]["used_memory"].
+func (server *SugarDB) Info(sections ...string) (map[string]map[string]string, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(append([]string{"INFO"}, sections...)), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+	res, err := internal.ParseStringResponse(b)
+	if err != nil {
+		return nil, err
+	}
+
+	info := make(map[string]map[string]string)
+	var section map[string]string
+	for _, line := range strings.Split(res, "\r\n") {
+		switch {
+		case strings.HasPrefix(line, "# "):
+			section = make(map[string]string)
+			info[strings.ToLower(strings.TrimPrefix(line, "# "))] = section
+		case section != nil && strings.Contains(line, ":"):
+			field, value, _ := strings.Cut(line, ":")
+			section[field] = value
+		}
+	}
+	return info, nil
+}
+
+// ConfigGet returns the configuration parameters that match any of the glob patterns.
+//
+// Parameters:
+//
+// `patterns` - ...string - The glob patterns that the parameter names are matched against, for example "eviction-*".
+//
+// Returns: A map of the parameter names to their values. Boolean parameters have the value "yes" or "no".
+//
+// Errors:
+//
+// "wrong number of arguments" - when no pattern is provided.
+func (server *SugarDB) ConfigGet(patterns ...string) (map[string]string, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(append([]string{"CONFIG", "GET"}, patterns...)), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+	arr, err := internal.ParseStringArrayResponse(b)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]string, len(arr)/2)
+	for i := 0; i+1 < len(arr); i += 2 {
+		res[arr[i]] = arr[i+1]
+	}
+	return res, nil
+}
+
+// ConfigSet changes configuration parameters while the server is running.
+//
+// Parameters:
+//
+// `values` - map[string]string - The new values of the parameters, by parameter name. The parameters that can be
+// changed are max-memory, eviction-policy, eviction-sample, eviction-interval, aof-sync-strategy, snapshot-interval,
+// snapshot-threshold, require-pass, password, notify-keyspace-events, slowlog-log-slower-than,
+// slowlog-max-len, latency-monitor-threshold and lua-time-limit.
+//
+// Returns: true when the parameters are changed.
+//
+// Errors:
+//
+// "unknown option" - when a parameter does not exist.
+//
+// "can't set immutable config" - when a parameter can't be changed while the server is running.
+//
+// "invalid value" - when the value of a parameter is invalid. None of the parameters is changed.
+func (server *SugarDB) ConfigSet(values map[string]string) (bool, error) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	cmd := []string{"CONFIG", "SET"}
+	for _, name := range names {
+		cmd = append(cmd, name, values[name])
+	}
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return false, err
+	}
+	res, err := internal.ParseStringResponse(b)
+	return strings.EqualFold(res, "ok"), err
+}
+
+// ConfigResetStat resets the statistics reported by Info and the metrics endpoint.
+func (server *SugarDB) ConfigResetStat() (bool, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"CONFIG", "RESETSTAT"}), nil, false, true)
+	if err != nil {
+		return false, err
+	}
+	res, err := internal.ParseStringResponse(b)
+	return strings.EqualFold(res, "ok"), err
+}
+
+// ConfigRewrite persists the parameters that can be changed with ConfigSet to the JSON or YAML config file that the
+// server was started with. The config file is set with the WithConfigFile option.
+//
+// Errors:
+//
+// "the server is running without a config file" - when no config file is set.
+func (server *SugarDB) ConfigRewrite() (bool, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"CONFIG", "REWRITE"}), nil, false, true)
+	if err != nil {
+		return false, err
+	}
+	res, err := internal.ParseStringResponse(b)
+	return strings.EqualFold(res, "ok"), err
+}
+
+// SlowlogEntry is a command recorded in the slow log.
+//
+// ID is the unique, incrementing id of the entry.
+//
+// Timestamp is the unix time in seconds at which the command was executed.
+//
+// Duration is the execution time of the command.
+//
+// Args holds the command and its arguments. Only the first 32 arguments and the first 128 bytes of each argument
+// are kept.
+//
+// ClientAddr and ClientName identify the client that executed the command. ClientAddr is empty for the embedded API.
+type SlowlogEntry struct {
+	ID         int
+	Timestamp  int
+	Duration   time.Duration
+	Args       []string
+	ClientAddr string
+	ClientName string
+}
+
+// SlowlogGet returns the most recent entries of the slow log, newest first.
+//
+// Parameters:
+//
+// `count` - int - The number of entries to return. All the entries are returned when count is -1.
+//
+// Errors:
+//
+// "count should be greater than or equal to -1" - when count is less than -1.
+func (server *SugarDB) SlowlogGet(count int) ([]SlowlogEntry, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"SLOWLOG", "GET", strconv.Itoa(count)}), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+	v, _, err := resp.NewReader(bytes.NewReader(b)).ReadValue()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]SlowlogEntry, len(v.Array()))
+	for i, entry := range v.Array() {
+		fields := entry.Array()
+		args := make([]string, len(fields[3].Array()))
+		for j, arg := range fields[3].Array() {
+			args[j] = arg.String()
+		}
+		entries[i] = SlowlogEntry{
+			ID:         fields[0].Integer(),
+			Timestamp:  fields[1].Integer(),
+			Duration:   time.Duration(fields[2].Integer()) * time.Microsecond,
+			Args:       args,
+			ClientAddr: fields[4].String(),
+			ClientName: fields[5].String(),
+		}
+	}
+	return entries, nil
+}
+
+// SlowlogLen returns the number of entries in the slow log.
+func (server *SugarDB) SlowlogLen() (int, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"SLOWLOG", "LEN"}), nil, false, true)
+	if err != nil {
+		return 0, err
+	}
+	return internal.ParseIntegerResponse(b)
+}
+
+// SlowlogReset removes all the entries from the slow log.
+func (server *SugarDB) SlowlogReset() (bool, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"SLOWLOG", "RESET"}), nil, false, true)
+	if err != nil {
+		return false, err
+	}
+	res, err := internal.ParseStringResponse(b)
+	return strings.EqualFold(res, "ok"), err
+}
+
+// LatencyEvent is the latest latency spike of an event tracked by the latency monitor.
+//
+// Name is the event: command, fast-command, aof-fsync, snapshot, raft-apply, eviction-cycle or expire-cycle.
+//
+// Timestamp is the unix time in seconds of the latest spike.
+//
+// Latest is the latency of the latest spike and Max is the highest latency of the event.
+type LatencyEvent struct {
+	Name      string
+	Timestamp int
+	Latest    time.Duration
+	Max       time.Duration
+}
+
+// LatencySample is a latency spike of an event. The spikes that happen in the same second are merged into one
+// sample with the highest latency.
+type LatencySample struct {
+	Timestamp int
+	Latency   time.Duration
+}
+
+// LatencyHistogram is the distribution of the execution times of a command.
+//
+// Calls is the number of times the command was executed.
+//
+// Histogram maps the upper bound of each bucket, a power of 2 microseconds, to the number of calls that took at
+// most that time.
+type LatencyHistogram struct {
+	Calls     int
+	Histogram map[time.Duration]int
+}
+
+// LatencyLatest returns the latest latency spike of each event, ordered by name. The latency monitor is enabled with
+// the WithLatencyMonitorThreshold option or by setting latency-monitor-threshold with ConfigSet.
+func (server *SugarDB) LatencyLatest() ([]LatencyEvent, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"LATENCY", "LATEST"}), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+	v, _, err := resp.NewReader(bytes.NewReader(b)).ReadValue()
+	if err != nil {
+		return nil, err
+	}
+	events := make([]LatencyEvent, len(v.Array()))
+	for i, event := range v.Array() {
+		fields := event.Array()
+		events[i] = LatencyEvent{
+			Name:      fields[0].String(),
+			Timestamp: fields[1].Integer(),
+			Latest:    time.Duration(fields[2].Integer()) * time.Millisecond,
+			Max:       time.Duration(fields[3].Integer()) * time.Millisecond,
+		}
+	}
+	return events, nil
+}
+
+// LatencyHistory returns the latency spikes of the event, oldest first.
+//
+// Parameters:
+//
+// `event` - string - The name of the event. An empty slice is returned when the event has no spikes.
+func (server *SugarDB) LatencyHistory(event string) ([]LatencySample, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"LATENCY", "HISTORY", event}), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+	v, _, err := resp.NewReader(bytes.NewReader(b)).ReadValue()
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]LatencySample, len(v.Array()))
+	for i, sample := range v.Array() {
+		samples[i] = LatencySample{
+			Timestamp: sample.Array()[0].Integer(),
+			Latency:   time.Duration(sample.Array()[1].Integer()) * time.Millisecond,
+		}
+	}
+	return samples, nil
+}
+
+// LatencyReset removes the latency spikes of the events.
+//
+// Parameters:
+//
+// `events` - ...string - The names of the events to reset. All the events are reset when none is given.
+//
+// Returns: The number of events that were reset.
+func (server *SugarDB) LatencyReset(events ...string) (int, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(append([]string{"LATENCY", "RESET"}, events...)), nil, false, true)
+	if err != nil {
+		return 0, err
+	}
+	return internal.ParseIntegerResponse(b)
+}
+
+// LatencyHistogram returns the latency histograms of the commands. The histograms are reset by ConfigResetStat.
+//
+// Parameters:
+//
+// `commands` - ...string - The names of the commands. The histograms of all the executed commands are returned when
+// none is given. Subcommands are named like "config|set".
+//
+// Returns: A map of the command names to their histograms. The commands that were never executed are left out.
+func (server *SugarDB) LatencyHistogram(commands ...string) (map[string]LatencyHistogram, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(append([]string{"LATENCY", "HISTOGRAM"}, commands...)), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+	v, _, err := resp.NewReader(bytes.NewReader(b)).ReadValue()
+	if err != nil {
+		return nil, err
+	}
+	histograms := make(map[string]LatencyHistogram)
+	for i := 0; i+1 < len(v.Array()); i += 2 {
+		fields := v.Array()[i+1].Array()
+		histogram := LatencyHistogram{Calls: fields[1].Integer(), Histogram: make(map[time.Duration]int)}
+		buckets := fields[3].Array()
+		for j := 0; j+1 < len(buckets); j += 2 {
+			histogram.Histogram[time.Duration(buckets[j].Integer())*time.Microsecond] = buckets[j+1].Integer()
+		}
+		histograms[v.Array()[i].String()] = histogram
+	}
+	return histograms, nil
+}
+
+// LatencyDoctor returns a report of the latency spikes of each event, with advice on how to reduce them.
+func (server *SugarDB) LatencyDoctor() (string, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"LATENCY", "DOCTOR"}), nil, false, true)
+	if err != nil {
+		return "", err
+	}
+	return internal.ParseStringResponse(b)
+}
+
+// MonitorEvent is a command processed by the server, as streamed by MONITOR.
+//
+// Time is the time at which the command was processed.
+//
+// Database is the index of the database the command was executed on.
+//
+// Client is the address of the TCP client that sent the command, or "embedded" for the embedded API.
+// The commands that are not sent by a client are tagged "aof" when they're replayed from the AOF, "raft" when
+// they're applied from the raft log and "lua" when they're called by a script.
+//
+// Args holds the command and its arguments. The passwords of AUTH and HELLO are replaced with "(redacted)".
+type MonitorEvent struct {
+	Time     time.Time
+	Database int
+	Client   string
+	Args     []string
+}
+
+// String formats the event like a line of the MONITOR output, for example:
+//
+// 1339518083.107412 [0 127.0.0.1:60866] "SET" "key" "value"
+func (event MonitorEvent) String() string {
+	args := make([]string, len(event.Args))
+	for i, arg := range event.Args {
+		args[i] = quoteMonitorArg(arg)
+	}
+	return fmt.Sprintf("%d.%06d [%d %s] %s",
+		event.Time.Unix(), event.Time.Nanosecond()/1000, event.Database, event.Client, strings.Join(args, " "))
+}
+
+// Monitor streams the commands processed by the server, including the commands replayed from the AOF and the
+// commands applied from the raft log, until the context is cancelled. The channel is closed when the context is done.
+//
+// The events are buffered. The events are dropped while the buffer is full so that a slow reader never holds up the
+// commands.
+//
+// Parameters:
+//
+// `ctx` - context.Context - The context that stops the stream when it's done.
+//
+// Returns: A channel that receives a MonitorEvent for each command.
+func (server *SugarDB) Monitor(ctx context.Context) <-chan MonitorEvent {
+	m := server.addMonitor(nil)
+	go func() {
+		<-ctx.Done()
+		server.removeMonitor(m)
+	}()
+	return m.events
+}
+
 // RewriteAOF triggers a compaction of the AOF file.
 func (server *SugarDB) RewriteAOF() (string, error) {
 	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"REWRITEAOF"}), nil, false, true)
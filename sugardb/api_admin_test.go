@@ -16,8 +16,10 @@ package sugardb
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"github.com/echovault/sugardb/internal"
 	"github.com/echovault/sugardb/internal/clock"
 	"github.com/echovault/sugardb/internal/constants"
 	"github.com/tidwall/resp"
@@ -425,7 +427,8 @@ func TestSugarDB_CommandList(t *testing.T) {
 			want: func() []string {
 				var commands []string
 				for _, command := range server.commands {
-					if strings.EqualFold(command.Module, constants.SortedSetModule) {
+					if strings.EqualFold(command.Module, constants.SortedSetModule) &&
+						strings.HasPrefix(strings.ToLower(command.Command), "z") {
 						commands = append(commands, strings.ToLower(command.Command))
 					}
 				}
@@ -567,3 +570,448 @@ func TestSugarDB_LastSave(t *testing.T) {
 		})
 	}
 }
+
+func TestSugarDB_Info(t *testing.T) {
+	server := createSugarDB()
+
+	if _, _, err := server.Set("InfoKey1", "value1", SETOptions{}); err != nil {
+		t.Error(err)
+		return
+	}
+	// InfoKey2 expired a second ago.
+	presetKeyData(server, context.Background(), "InfoKey2", internal.KeyData{
+		Value:    "value2",
+		ExpireAt: clock.NewClock().Now().Add(-1 * time.Second),
+	})
+	for _, key := range []string{"InfoKey1", "InfoKey2", "InfoKey3"} {
+		if _, err := server.Get(key); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	tests := []struct {
+		name         string
+		sections     []string
+		wantSections []string
+		wantFields   map[string]map[string]string
+	}{
+		{
+			name:         "1. Get the default sections",
+			sections:     []string{},
+			wantSections: []string{"server", "clients", "memory", "persistence", "stats", "replication", "keyspace"},
+			wantFields: map[string]map[string]string{
+				"server":      {"sugardb_mode": "standalone", "uptime_in_seconds": "0"},
+				"stats":       {"keyspace_hits": "1", "keyspace_misses": "2", "expired_keys": "1", "evicted_keys": "0"},
+				"replication": {"role": "master"},
+				"keyspace":    {"db0": "keys=1,expires=0,avg_ttl=0"},
+			},
+		},
+		{
+			name:         "2. Get the command stats",
+			sections:     []string{"commandstats"},
+			wantSections: []string{"commandstats"},
+			wantFields: map[string]map[string]string{
+				"commandstats": {"cmdstat_info": "calls=1,"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := server.Info(tt.sections...)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			sections := make([]string, 0, len(got))
+			for section := range got {
+				sections = append(sections, section)
+			}
+			want := slices.Clone(tt.wantSections)
+			slices.Sort(sections)
+			slices.Sort(want)
+			if !slices.Equal(sections, want) {
+				t.Errorf("Info() got sections %v, want %v", sections, tt.wantSections)
+			}
+			for section, fields := range tt.wantFields {
+				for field, want := range fields {
+					if value := got[section][field]; !strings.HasPrefix(value, want) {
+						t.Errorf("Info() got %s %s = %s, want %s", section, field, value, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSugarDB_Config(t *testing.T) {
+	configFile := path.Join(t.TempDir(), "config.json")
+	conf := DefaultConfig()
+	conf.DataDir = ""
+	server, err := NewSugarDB(WithConfig(conf), WithConfigFile(configFile))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Cleanup(func() {
+		server.ShutDown()
+	})
+
+	tests := []struct {
+		name    string
+		values  map[string]string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name: "1. Set parameters",
+			values: map[string]string{
+				"max-memory":        "100kb",
+				"eviction-policy":   "allkeys-lru",
+				"eviction-interval": "50ms",
+				"snapshot-interval": "1m",
+				"aof-sync-strategy": "no",
+			},
+			want: map[string]string{
+				"max-memory":        "102400",
+				"eviction-policy":   "allkeys-lru",
+				"eviction-interval": "50ms",
+				"snapshot-interval": "1m0s",
+				"aof-sync-strategy": "no",
+			},
+		},
+		{
+			name:    "2. Return error when the value is invalid",
+			values:  map[string]string{"max-memory": "1", "aof-sync-strategy": "sometimes"},
+			wantErr: "invalid value for 'aof-sync-strategy': aofSyncStrategy must be 'always', 'everysec' or 'no'",
+			want:    map[string]string{"max-memory": "102400", "aof-sync-strategy": "no"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := server.ConfigSet(tt.values)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("ConfigSet() error = %v, wantErr %q", err, tt.wantErr)
+				}
+			} else if err != nil || !ok {
+				t.Errorf("ConfigSet() got = %v, error = %v", ok, err)
+				return
+			}
+
+			patterns := make([]string, 0, len(tt.want))
+			for name := range tt.want {
+				patterns = append(patterns, name)
+			}
+			got, err := server.ConfigGet(patterns...)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ConfigGet() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("3. Reset the stats", func(t *testing.T) {
+		if _, err := server.Get("key"); err != nil {
+			t.Error(err)
+			return
+		}
+		if ok, err := server.ConfigResetStat(); err != nil || !ok {
+			t.Errorf("ConfigResetStat() got = %v, error = %v", ok, err)
+			return
+		}
+		info, err := server.Info("stats")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if info["stats"]["keyspace_misses"] != "0" {
+			t.Errorf("expected keyspace_misses to be reset, got %s", info["stats"]["keyspace_misses"])
+		}
+	})
+
+	t.Run("4. Rewrite the config file", func(t *testing.T) {
+		if ok, err := server.ConfigRewrite(); err != nil || !ok {
+			t.Errorf("ConfigRewrite() got = %v, error = %v", ok, err)
+			return
+		}
+		data, err := os.ReadFile(configFile)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for _, want := range []string{`"MaxMemory": 102400`, `"EvictionPolicy": "allkeys-lru"`, `"EvictionInterval": 50000000`} {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("expected config file to contain %s, got %s", want, string(data))
+			}
+		}
+	})
+}
+
+func TestSugarDB_Slowlog(t *testing.T) {
+	conf := DefaultConfig()
+	conf.DataDir = ""
+	conf.SlowlogLogSlowerThan = 0 // Record every command.
+	conf.SlowlogMaxLen = 2
+	server := createSugarDBWithConfig(conf)
+	t.Cleanup(func() {
+		server.ShutDown()
+	})
+
+	for _, key := range []string{"SlowlogKey1", "SlowlogKey2", "SlowlogKey3"} {
+		if _, _, err := server.Set(key, "value", SETOptions{}); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	// The oldest entries are dropped once the max length is reached.
+	length, err := server.SlowlogLen()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if length != 2 {
+		t.Errorf("SlowlogLen() got = %d, want 2", length)
+	}
+
+	entries, err := server.SlowlogGet(-1)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want := []SlowlogEntry{
+		{ID: 3, Args: []string{"SLOWLOG", "LEN"}},
+		{ID: 2, Args: []string{"SET", "SlowlogKey3", "value"}},
+	}
+	if len(entries) != len(want) {
+		t.Errorf("SlowlogGet() got %d entries, want %d", len(entries), len(want))
+		return
+	}
+	for i := range want {
+		if entries[i].ID != want[i].ID || !slices.Equal(entries[i].Args, want[i].Args) || entries[i].ClientAddr != "" {
+			t.Errorf("SlowlogGet() got entry %+v, want %+v", entries[i], want[i])
+		}
+	}
+
+	if ok, err := server.SlowlogReset(); err != nil || !ok {
+		t.Errorf("SlowlogReset() got = %v, error = %v", ok, err)
+		return
+	}
+	// SLOWLOG RESET is recorded once the slow log is reset.
+	if length, err = server.SlowlogLen(); err != nil || length != 1 {
+		t.Errorf("SlowlogLen() got = %d, error = %v, want 1", length, err)
+	}
+}
+
+func TestSugarDB_Latency(t *testing.T) {
+	conf := DefaultConfig()
+	conf.DataDir = ""
+	conf.LatencyMonitorThreshold = 10
+	server := createSugarDBWithConfig(conf)
+	t.Cleanup(func() {
+		server.ShutDown()
+	})
+
+	// The spikes below the threshold are ignored, and the spikes of the same second are merged.
+	server.recordLatency(constants.AOFFsyncLatencyEvent, 5*time.Millisecond)
+	server.recordLatency(constants.AOFFsyncLatencyEvent, 20*time.Millisecond)
+	server.recordLatency(constants.AOFFsyncLatencyEvent, 30*time.Millisecond)
+	server.recordLatency(constants.EvictionLatencyEvent, 50*time.Millisecond)
+
+	t.Run("1. Get the latest spike of each event", func(t *testing.T) {
+		events, err := server.LatencyLatest()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		want := []LatencyEvent{
+			{Name: "aof-fsync", Timestamp: int(server.clock.Now().Unix()), Latest: 30 * time.Millisecond, Max: 30 * time.Millisecond},
+			{Name: "eviction-cycle", Timestamp: int(server.clock.Now().Unix()), Latest: 50 * time.Millisecond, Max: 50 * time.Millisecond},
+		}
+		if !reflect.DeepEqual(events, want) {
+			t.Errorf("LatencyLatest() got = %+v, want %+v", events, want)
+		}
+	})
+
+	t.Run("2. Get the spikes of an event", func(t *testing.T) {
+		samples, err := server.LatencyHistory("aof-fsync")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		want := []LatencySample{{Timestamp: int(server.clock.Now().Unix()), Latency: 30 * time.Millisecond}}
+		if !reflect.DeepEqual(samples, want) {
+			t.Errorf("LatencyHistory() got = %+v, want %+v", samples, want)
+		}
+	})
+
+	t.Run("3. Get the latency doctor report", func(t *testing.T) {
+		report, err := server.LatencyDoctor()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for _, s := range []string{"aof-fsync: 1 latency spikes", "eviction-cycle: 1 latency spikes", "aof-sync-strategy", "max-memory"} {
+			if !strings.Contains(report, s) {
+				t.Errorf("LatencyDoctor() expected report to contain %q, got %q", s, report)
+			}
+		}
+	})
+
+	t.Run("4. Get the latency histogram of a command", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			if _, _, err := server.Set("LatencyKey", "value", SETOptions{}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		histograms, err := server.LatencyHistogram("set")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		histogram, ok := histograms["set"]
+		if len(histograms) != 1 || !ok || histogram.Calls != 3 {
+			t.Errorf("LatencyHistogram() got = %+v, want 3 calls of set", histograms)
+			return
+		}
+		// The buckets are cumulative, so the slowest bucket holds all the calls.
+		var slowest time.Duration
+		for usec := range histogram.Histogram {
+			slowest = max(slowest, usec)
+		}
+		if histogram.Histogram[slowest] != 3 {
+			t.Errorf("LatencyHistogram() got = %+v, want 3 calls in the slowest bucket", histogram.Histogram)
+		}
+	})
+
+	t.Run("5. Reset the events", func(t *testing.T) {
+		if count, err := server.LatencyReset("aof-fsync", "non-existent"); err != nil || count != 1 {
+			t.Errorf("LatencyReset() got = %d, error = %v, want 1", count, err)
+			return
+		}
+		if count, err := server.LatencyReset("EVICTION-CYCLE"); err != nil || count != 1 {
+			t.Errorf("LatencyReset() got = %d, error = %v, want 1", count, err)
+			return
+		}
+		// Reset the command spikes, if any.
+		if _, err := server.LatencyReset(); err != nil {
+			t.Error(err)
+			return
+		}
+		if events, err := server.LatencyLatest(); err != nil || len(events) != 0 {
+			t.Errorf("LatencyLatest() got = %+v, error = %v, want no events", events, err)
+		}
+	})
+}
+
+func TestSugarDB_Monitor(t *testing.T) {
+	server := createSugarDB()
+	t.Cleanup(func() {
+		server.ShutDown()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := server.Monitor(ctx)
+
+	// next returns the next event streamed to the monitor.
+	next := func() (MonitorEvent, error) {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return MonitorEvent{}, errors.New("monitor stream closed")
+			}
+			return event, nil
+		case <-time.After(2 * time.Second):
+			return MonitorEvent{}, errors.New("timed out waiting for monitor event")
+		}
+	}
+
+	tests := []struct {
+		name    string
+		command func() error
+		want    []MonitorEvent
+	}{
+		{
+			name: "1. Stream the commands of the embedded API",
+			command: func() error {
+				_, _, err := server.Set("MonitorKey1", "value", SETOptions{})
+				return err
+			},
+			want: []MonitorEvent{{Client: "embedded", Args: []string{"SET", "MonitorKey1", "value"}}},
+		},
+		{
+			name: "2. Tag the commands called by scripts",
+			command: func() error {
+				_, err := server.Eval("return redis.call('SET', KEYS[1], ARGV[1])", []string{"MonitorKey2"}, []string{"value"})
+				return err
+			},
+			want: []MonitorEvent{
+				{Client: "lua", Args: []string{"SET", "MonitorKey2", "value"}},
+				{Client: "embedded", Args: []string{"EVAL", "return redis.call('SET', KEYS[1], ARGV[1])", "1", "MonitorKey2", "value"}},
+			},
+		},
+		{
+			name: "3. Tag the commands replayed from the AOF",
+			command: func() error {
+				// This is the context that the AOF engine replays the commands with.
+				ctx := context.WithValue(context.Background(), "Database", 0)
+				ctx = context.WithValue(ctx, internal.ContextMonitorSource("MonitorSource"), "aof")
+				_, err := server.handleCommand(ctx, internal.EncodeCommand([]string{"SET", "MonitorKey3", "value"}), nil, true, false)
+				return err
+			},
+			want: []MonitorEvent{{Client: "aof", Args: []string{"SET", "MonitorKey3", "value"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.command(); err != nil {
+				t.Error(err)
+				return
+			}
+			for _, want := range tt.want {
+				event, err := next()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if event.Database != want.Database || event.Client != want.Client || !slices.Equal(event.Args, want.Args) {
+					t.Errorf("Monitor() got event %+v, want %+v", event, want)
+				}
+			}
+		})
+	}
+
+	t.Run("4. Format the event like a line of the MONITOR output", func(t *testing.T) {
+		event := MonitorEvent{
+			Time:     time.Unix(1339518083, 107412000),
+			Database: 1,
+			Client:   "127.0.0.1:60866",
+			Args:     []string{"SET", "key", "a \"quoted\"\\value\n\x00"},
+		}
+		want := `1339518083.107412 [1 127.0.0.1:60866] "SET" "key" "a \"quoted\"\\value\n\x00"`
+		if event.String() != want {
+			t.Errorf("String() got = %s, want %s", event.String(), want)
+		}
+	})
+
+	t.Run("5. Close the stream when the context is cancelled", func(t *testing.T) {
+		cancel()
+		for {
+			select {
+			case _, ok := <-events:
+				if !ok {
+					return
+				}
+			case <-time.After(2 * time.Second):
+				t.Error("expected the monitor stream to be closed")
+				return
+			}
+		}
+	})
+}
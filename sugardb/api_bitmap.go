@@ -0,0 +1,266 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sugardb
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/echovault/sugardb/internal"
+	"github.com/tidwall/resp"
+)
+
+// BitCountOptions restricts BitCount to a range of the string.
+//
+// Start and End are the inclusive bounds of the range. Negative values count from the end of the string.
+//
+// Bit makes Start and End bit offsets instead of byte offsets.
+type BitCountOptions struct {
+	Start int
+	End   int
+	Bit   bool
+}
+
+// BitPosOptions restricts BitPos to a range of the string.
+//
+// Start is the offset to start searching from. Negative values count from the end of the string.
+//
+// End is the offset to stop searching at, and is only used when WithEnd is true. Negative values count from the end
+// of the string.
+//
+// Bit makes Start and End bit offsets instead of byte offsets. It's only used when WithEnd is true.
+type BitPosOptions struct {
+	Start   int
+	End     int
+	WithEnd bool
+	Bit     bool
+}
+
+// BitFieldOperation is an operation of BitField and BitFieldRO.
+//
+// Op is one of "GET", "SET" and "INCRBY". BitFieldRO only supports "GET".
+//
+// Encoding is the type of the field, like "i8" for a signed 8-bit integer or "u4" for an unsigned 4-bit integer.
+// Signed fields are up to 64 bits long and unsigned fields up to 63 bits long.
+//
+// Offset is the bit offset of the field. When prefixed with "#", it's multiplied by the width of the field.
+//
+// Value is the value to set with "SET" and the increment with "INCRBY".
+//
+// Overflow is one of "WRAP", "SAT" and "FAIL". It changes the overflow behaviour of this operation and of the
+// operations after it. The behaviour is not changed when empty.
+type BitFieldOperation struct {
+	Op       string
+	Encoding string
+	Offset   string
+	Value    int
+	Overflow string
+}
+
+// SetBit sets or clears the bit at the offset of the string at the key. The string is grown with zero bytes when
+// it's too short to hold the bit, and it's created when the key does not exist.
+//
+// Parameters:
+//
+// `key` - string - the key to the string.
+//
+// `offset` - uint - the offset of the bit, where 0 is the most significant bit of the first byte.
+//
+// `value` - int - 1 to set the bit and 0 to clear it.
+//
+// Returns: The previous value of the bit.
+//
+// Errors:
+//
+// "value at <key> is not a string" - when the key exists but does not hold a string.
+//
+// "bit offset is not an integer or out of range" - when the offset is larger than 2^32-1.
+func (server *SugarDB) SetBit(key string, offset uint, value int) (int, error) {
+	cmd := []string{"SETBIT", key, strconv.FormatUint(uint64(offset), 10), strconv.Itoa(value)}
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return 0, err
+	}
+	return internal.ParseIntegerResponse(b)
+}
+
+// GetBit returns the bit at the offset of the string at the key.
+//
+// Parameters:
+//
+// `key` - string - the key to the string.
+//
+// `offset` - uint - the offset of the bit, where 0 is the most significant bit of the first byte.
+//
+// Returns: The value of the bit. 0 if the key does not exist or the offset is past the end of the string.
+//
+// Errors:
+//
+// "value at <key> is not a string" - when the key exists but does not hold a string.
+func (server *SugarDB) GetBit(key string, offset uint) (int, error) {
+	cmd := []string{"GETBIT", key, strconv.FormatUint(uint64(offset), 10)}
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return 0, err
+	}
+	return internal.ParseIntegerResponse(b)
+}
+
+// BitCount counts the bits set to 1 in the string at the key.
+//
+// Parameters:
+//
+// `key` - string - the key to the string.
+//
+// `options` - BitCountOptions - restricts the count to a range of the string.
+//
+// Returns: The number of bits set to 1. 0 if the key does not exist.
+//
+// Errors:
+//
+// "value at <key> is not a string" - when the key exists but does not hold a string.
+func (server *SugarDB) BitCount(key string, options ...BitCountOptions) (int, error) {
+	cmd := []string{"BITCOUNT", key}
+	if len(options) > 0 {
+		cmd = append(cmd, strconv.Itoa(options[0].Start), strconv.Itoa(options[0].End))
+		if options[0].Bit {
+			cmd = append(cmd, "BIT")
+		}
+	}
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return 0, err
+	}
+	return internal.ParseIntegerResponse(b)
+}
+
+// BitPos returns the offset of the first bit set to the provided value in the string at the key.
+//
+// Parameters:
+//
+// `key` - string - the key to the string.
+//
+// `bit` - int - the value of the bit to look for, either 1 or 0.
+//
+// `options` - BitPosOptions - restricts the search to a range of the string.
+//
+// Returns: The offset of the bit, or -1 if there is none. When looking for 0 without an end, the string is
+// treated as padded with zeros on the right, so the offset after the end of the string is returned.
+//
+// Errors:
+//
+// "value at <key> is not a string" - when the key exists but does not hold a string.
+func (server *SugarDB) BitPos(key string, bit int, options ...BitPosOptions) (int, error) {
+	cmd := []string{"BITPOS", key, strconv.Itoa(bit)}
+	if len(options) > 0 {
+		cmd = append(cmd, strconv.Itoa(options[0].Start))
+		if options[0].WithEnd {
+			cmd = append(cmd, strconv.Itoa(options[0].End))
+			if options[0].Bit {
+				cmd = append(cmd, "BIT")
+			}
+		}
+	}
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return 0, err
+	}
+	return internal.ParseIntegerResponse(b)
+}
+
+// BitOp performs a bitwise operation between the strings at the keys and stores the result at the destination.
+// Shorter strings and missing keys are treated as padded with zero bytes. The destination is deleted when the
+// result is empty.
+//
+// Parameters:
+//
+// `operation` - string - one of "AND", "OR", "XOR" and "NOT". "NOT" takes a single key.
+//
+// `destination` - string - the key to store the result at.
+//
+// `keys` - ...string - the keys to the source strings.
+//
+// Returns: The length of the result in bytes.
+//
+// Errors:
+//
+// "value at <key> is not a string" - when a source key exists but does not hold a string.
+//
+// "BITOP NOT must be called with a single source key." - when "NOT" is called with more than one key.
+func (server *SugarDB) BitOp(operation, destination string, keys ...string) (int, error) {
+	cmd := append([]string{"BITOP", operation, destination}, keys...)
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return 0, err
+	}
+	return internal.ParseIntegerResponse(b)
+}
+
+// BitField treats the string at the key as an array of integer fields, and gets, sets or increments them.
+//
+// Parameters:
+//
+// `key` - string - the key to the string.
+//
+// `operations` - ...BitFieldOperation - the operations to perform in order.
+//
+// Returns: The result of each operation. "GET" returns the value of the field, "SET" returns its previous value
+// and "INCRBY" returns its new value. The result is nil for operations that fail because of the "FAIL" overflow
+// behaviour.
+//
+// Errors:
+//
+// "value at <key> is not a string" - when the key exists but does not hold a string.
+func (server *SugarDB) BitField(key string, operations ...BitFieldOperation) ([]*int, error) {
+	return server.bitField("BITFIELD", key, operations)
+}
+
+// BitFieldRO works the same as BitField but only supports "GET" operations.
+func (server *SugarDB) BitFieldRO(key string, operations ...BitFieldOperation) ([]*int, error) {
+	return server.bitField("BITFIELD_RO", key, operations)
+}
+
+func (server *SugarDB) bitField(command, key string, operations []BitFieldOperation) ([]*int, error) {
+	cmd := []string{command, key}
+	for _, operation := range operations {
+		if operation.Overflow != "" {
+			cmd = append(cmd, "OVERFLOW", operation.Overflow)
+		}
+		cmd = append(cmd, operation.Op, operation.Encoding, operation.Offset)
+		if !strings.EqualFold(operation.Op, "GET") {
+			cmd = append(cmd, strconv.Itoa(operation.Value))
+		}
+	}
+
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+
+	v, _, err := resp.NewReader(bytes.NewReader(b)).ReadValue()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*int, len(v.Array()))
+	for i, element := range v.Array() {
+		if element.IsNull() {
+			continue
+		}
+		value := element.Integer()
+		res[i] = &value
+	}
+	return res, nil
+}
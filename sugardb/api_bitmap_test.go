@@ -0,0 +1,248 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sugardb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSugarDB_SetBit_GetBit(t *testing.T) {
+	t.Parallel()
+
+	server := createSugarDB()
+
+	_, _ = server.LPush("SetBitKey2", "a")
+
+	tests := []struct {
+		name    string
+		key     string
+		offset  uint
+		value   int
+		want    int
+		wantErr bool
+	}{
+		{name: "1. Set a bit of a new string", key: "SetBitKey1", offset: 1, value: 1, want: 0},
+		{name: "2. Set another bit of the string", key: "SetBitKey1", offset: 7, value: 1, want: 0},
+		{name: "3. Return the previous value of the bit", key: "SetBitKey1", offset: 7, value: 0, want: 1},
+		{name: "4. Return error when the key is not a string", key: "SetBitKey2", offset: 0, value: 1, wantErr: true},
+		{name: "5. Return error when the bit is invalid", key: "SetBitKey1", offset: 0, value: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := server.SetBit(tt.key, tt.offset, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetBit() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("SetBit() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("6. Get the bits of the string", func(t *testing.T) {
+		for offset, want := range map[uint]int{0: 0, 1: 1, 7: 0, 100: 0} {
+			got, err := server.GetBit("SetBitKey1", offset)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if got != want {
+				t.Errorf("GetBit() at offset %d got = %v, want %v", offset, got, want)
+			}
+		}
+		if value, err := server.Get("SetBitKey1"); err != nil || value != "@" {
+			t.Errorf("Get() got = %v, %v, want @", value, err)
+		}
+	})
+}
+
+func TestSugarDB_BitCount_BitPos(t *testing.T) {
+	t.Parallel()
+
+	server := createSugarDB()
+
+	_, _, _ = server.Set("BitCountKey1", "foobar", SETOptions{})
+	_, _, _ = server.Set("BitPosKey1", "\x00\xff\xf0", SETOptions{})
+
+	t.Run("1. Count the bits set to 1", func(t *testing.T) {
+		tests := []struct {
+			options []BitCountOptions
+			want    int
+		}{
+			{want: 26},
+			{options: []BitCountOptions{{Start: 1, End: 1}}, want: 6},
+			{options: []BitCountOptions{{Start: -2, End: -1}}, want: 7},
+			{options: []BitCountOptions{{Start: 5, End: 30, Bit: true}}, want: 17},
+		}
+		for _, tt := range tests {
+			got, err := server.BitCount("BitCountKey1", tt.options...)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("BitCount() with options %v got = %v, want %v", tt.options, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("2. Find the first bit set to a value", func(t *testing.T) {
+		tests := []struct {
+			bit     int
+			options []BitPosOptions
+			want    int
+		}{
+			{bit: 1, want: 8},
+			{bit: 1, options: []BitPosOptions{{Start: 2}}, want: 16},
+			{bit: 1, options: []BitPosOptions{{Start: 7, End: 15, WithEnd: true, Bit: true}}, want: 8},
+			{bit: 0, options: []BitPosOptions{{Start: 1}}, want: 20},
+			{bit: 1, options: []BitPosOptions{{Start: 0, End: 0, WithEnd: true}}, want: -1},
+		}
+		for _, tt := range tests {
+			got, err := server.BitPos("BitPosKey1", tt.bit, tt.options...)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("BitPos() with bit %d and options %v got = %v, want %v", tt.bit, tt.options, got, tt.want)
+			}
+		}
+	})
+}
+
+func TestSugarDB_BitOp(t *testing.T) {
+	t.Parallel()
+
+	server := createSugarDB()
+
+	_, _, _ = server.Set("BitOpKey1", "foobar", SETOptions{})
+	_, _, _ = server.Set("BitOpKey2", "abcdef", SETOptions{})
+
+	tests := []struct {
+		name      string
+		operation string
+		keys      []string
+		want      int
+		wantValue string
+		wantErr   bool
+	}{
+		{name: "1. AND the strings", operation: "AND", keys: []string{"BitOpKey1", "BitOpKey2"}, want: 6, wantValue: "`bc`ab"},
+		{name: "2. OR the strings", operation: "OR", keys: []string{"BitOpKey1", "BitOpKey2"}, want: 6, wantValue: "goofev"},
+		{name: "3. Delete the destination when the result is empty", operation: "OR", keys: []string{"BitOpKey3"}, want: 0},
+		{name: "4. Return error when NOT has multiple keys", operation: "NOT", keys: []string{"BitOpKey1", "BitOpKey2"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := server.BitOp(tt.operation, "BitOpDestination", tt.keys...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BitOp() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("BitOp() got = %v, want %v", got, tt.want)
+			}
+			if value, _ := server.Get("BitOpDestination"); value != tt.wantValue {
+				t.Errorf("BitOp() destination got = %q, want %q", value, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestSugarDB_BitField(t *testing.T) {
+	t.Parallel()
+
+	server := createSugarDB()
+
+	value := func(v int) *int {
+		return &v
+	}
+
+	tests := []struct {
+		name       string
+		key        string
+		readOnly   bool
+		operations []BitFieldOperation
+		want       []*int
+		wantErr    bool
+	}{
+		{
+			name: "1. Set, increment and get fields",
+			key:  "BitFieldKey1",
+			operations: []BitFieldOperation{
+				{Op: "SET", Encoding: "i8", Offset: "0", Value: -1},
+				{Op: "INCRBY", Encoding: "u8", Offset: "#1", Value: 200},
+				{Op: "GET", Encoding: "u8", Offset: "0"},
+			},
+			want: []*int{value(0), value(200), value(255)},
+		},
+		{
+			name: "2. Handle overflows",
+			key:  "BitFieldKey1",
+			operations: []BitFieldOperation{
+				{Op: "INCRBY", Encoding: "i8", Offset: "0", Value: -128},
+				{Op: "INCRBY", Encoding: "u8", Offset: "8", Value: 100, Overflow: "SAT"},
+				{Op: "INCRBY", Encoding: "u8", Offset: "8", Value: 100, Overflow: "FAIL"},
+			},
+			want: []*int{value(127), value(255), nil},
+		},
+		{
+			name:       "3. Get fields with BitFieldRO",
+			key:        "BitFieldKey1",
+			readOnly:   true,
+			operations: []BitFieldOperation{{Op: "GET", Encoding: "i8", Offset: "0"}},
+			want:       []*int{value(127)},
+		},
+		{
+			name:       "4. Return error when BitFieldRO is called with SET",
+			key:        "BitFieldKey1",
+			readOnly:   true,
+			operations: []BitFieldOperation{{Op: "SET", Encoding: "i8", Offset: "0", Value: 1}},
+			wantErr:    true,
+		},
+		{
+			name:       "5. Return error when the encoding is invalid",
+			key:        "BitFieldKey1",
+			operations: []BitFieldOperation{{Op: "GET", Encoding: "u64", Offset: "0"}},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []*int
+			var err error
+			if tt.readOnly {
+				got, err = server.BitFieldRO(tt.key, tt.operations...)
+			} else {
+				got, err = server.BitField(tt.key, tt.operations...)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BitField() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BitField() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
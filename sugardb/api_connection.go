@@ -16,9 +16,34 @@ package sugardb
 
 import (
 	"errors"
+	"github.com/echovault/sugardb/internal"
+	"net"
 	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// CLIENTKILLOptions is a struct wrapper for the filters of the ClientKill command.
+// The connections that match all the provided filters are closed.
+//
+// `ID` - uint64 - The id of the connection.
+//
+// `Addr` - string - The address of the client, in the ip:port format.
+//
+// `LAddr` - string - The address of the server side of the connection, in the ip:port format.
+//
+// `User` - string - The ACL user that the connection is authenticated as.
+//
+// `Type` - string - The type of the client: "normal" or "pubsub".
+type CLIENTKILLOptions struct {
+	ID    uint64
+	Addr  string
+	LAddr string
+	User  string
+	Type  string
+}
+
 // SetProtocol sets the RESP protocol that's expected from responses to embedded API calls.
 // This command does not affect the RESP protocol expected by any of the TCP clients.
 //
@@ -68,3 +93,264 @@ func (server *SugarDB) SelectDB(database int) error {
 
 	return nil
 }
+
+// Transaction is a transaction of the embedded API, started with Multi.
+// Each transaction has its own session, so the other embedded API calls, from any goroutine, are executed
+// immediately and are never queued in it. A Transaction must not be used concurrently.
+type Transaction struct {
+	server  *SugarDB
+	session *net.Conn
+}
+
+// Multi starts a transaction. The commands added with Queue are executed atomically by Exec.
+//
+// Parameters:
+//
+// `watch` - ...string - The keys to watch. Exec aborts the transaction if any of them is modified, deleted or
+// expires after Multi is called.
+//
+// Returns: The transaction.
+func (server *SugarDB) Multi(watch ...string) (*Transaction, error) {
+	tx := &Transaction{server: server, session: new(net.Conn)}
+	if len(watch) > 0 {
+		if _, err := tx.execute(append([]string{"WATCH"}, watch...)); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.execute([]string{"MULTI"}); err != nil {
+		tx.server.unwatchKeys(tx.server.context, tx.session)
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (tx *Transaction) execute(cmd []string) ([]byte, error) {
+	return tx.server.handleCommand(tx.server.context, internal.EncodeCommand(cmd), tx.session, false, true)
+}
+
+// Queue adds a command to the transaction. The commands are executed in the order they were queued.
+// A SELECT command changes the database of the commands queued after it.
+//
+// Parameters:
+//
+// `command` - ...string - The command and its arguments, for example "SET", "key", "value".
+//
+// Errors:
+//
+// An error is returned if the command does not exist or has the wrong number of arguments. Exec then discards
+// the transaction. The commands that change the state of a client connection, like SUBSCRIBE, HELLO, AUTH, MONITOR
+// and CLIENT TRACKING, can't be queued.
+func (tx *Transaction) Queue(command ...string) error {
+	if len(command) == 0 {
+		return errors.New("empty command")
+	}
+	_, err := tx.execute(command)
+	return err
+}
+
+// Exec atomically executes all the queued commands and ends the transaction.
+//
+// Returns: A slice with the raw RESP response of each queued command, in the order the commands were queued.
+// A command that fails during execution does not stop the transaction, its response is a RESP error.
+// Returns nil if the transaction was aborted because one of the watched keys was modified.
+//
+// Errors:
+//
+// "EXEC without MULTI" - When the transaction has already ended.
+//
+// "EXECABORT Transaction discarded because of previous errors" - When one of the commands could not be queued.
+// None of the commands are executed in that case.
+func (tx *Transaction) Exec() ([][]byte, error) {
+	defer tx.server.closeSession(tx.session)
+	b, err := tx.execute([]string{"EXEC"})
+	if err != nil {
+		return nil, err
+	}
+	return internal.ParseRawArrayResponse(b)
+}
+
+// Discard drops all the queued commands, unwatches the keys and ends the transaction.
+//
+// Errors:
+//
+// "DISCARD without MULTI" - When the transaction has already ended.
+func (tx *Transaction) Discard() error {
+	defer tx.server.closeSession(tx.session)
+	_, err := tx.execute([]string{"DISCARD"})
+	return err
+}
+
+// WatchTransaction runs a read-modify-write transaction with optimistic locking.
+// The keys are watched before fn is called. fn reads the current state and returns the commands to execute,
+// which are then executed atomically. If any of the watched keys is modified between the call to fn and the
+// execution of the commands, the transaction is aborted and retried by calling fn again.
+// Each call uses its own session, so it does not interfere with the other embedded API calls and can be called
+// concurrently.
+//
+// Parameters:
+//
+// `keys` - []string - The keys to watch.
+//
+// `retries` - uint - The number of times to retry the transaction after the first attempt was aborted.
+//
+// `fn` - func() ([][]string, error) - Reads the keys and returns the commands to execute in the transaction.
+// If fn returns an error, the transaction is not executed and the error is returned.
+//
+// Returns: A slice with the raw RESP response of each command returned by fn.
+//
+// Errors:
+//
+// "transaction aborted, watched keys were modified on every attempt" - When the transaction was aborted on all the attempts.
+func (server *SugarDB) WatchTransaction(keys []string, retries uint, fn func() ([][]string, error)) ([][]byte, error) {
+	session := new(net.Conn)
+	defer server.closeSession(session)
+	for attempt := uint(0); attempt <= retries; attempt++ {
+		res, err := server.watchTransaction(session, keys, fn)
+		if err != nil {
+			return nil, err
+		}
+		if res != nil {
+			return res, nil
+		}
+	}
+	return nil, errors.New("transaction aborted, watched keys were modified on every attempt")
+}
+
+// watchTransaction makes one attempt of WatchTransaction. Returns nil if the transaction was aborted.
+func (server *SugarDB) watchTransaction(session *net.Conn, keys []string, fn func() ([][]string, error)) ([][]byte, error) {
+	execute := func(cmd []string) ([]byte, error) {
+		return server.handleCommand(server.context, internal.EncodeCommand(cmd), session, false, true)
+	}
+
+	if _, err := execute(append([]string{"WATCH"}, keys...)); err != nil {
+		return nil, err
+	}
+
+	cmds, err := fn()
+	if err != nil {
+		server.unwatchKeys(server.context, session)
+		return nil, err
+	}
+
+	if _, err = execute([]string{"MULTI"}); err != nil {
+		server.unwatchKeys(server.context, session)
+		return nil, err
+	}
+	for _, cmd := range cmds {
+		if _, err = execute(cmd); err != nil {
+			// Discarding the transaction also unwatches the keys.
+			_, _ = execute([]string{"DISCARD"})
+			return nil, err
+		}
+	}
+
+	b, err := execute([]string{"EXEC"})
+	if err != nil {
+		return nil, err
+	}
+	return internal.ParseRawArrayResponse(b)
+}
+
+// ClientList returns the information about the TCP clients.
+//
+// Returns: A slice with one line per client, ordered by client id. Each line contains space separated fields
+// in the field=value format: id, addr, laddr, name, age, idle, flags, db, sub, psub, multi, qbuf, obl, user,
+// resp and cmd.
+func (server *SugarDB) ClientList() ([]string, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"CLIENT", "LIST"}), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+	list, err := internal.ParseStringResponse(b)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(list, "\n")
+	return lines[:len(lines)-1], nil
+}
+
+// ClientKill closes the connections of the TCP clients that match the filters.
+//
+// Parameters:
+//
+// `options` - CLIENTKILLOptions.
+//
+// Returns: The number of closed connections.
+//
+// Errors:
+//
+// "unknown client type '<type>'" - When the client type is not valid.
+func (server *SugarDB) ClientKill(options CLIENTKILLOptions) (int, error) {
+	cmd := []string{"CLIENT", "KILL"}
+	if options.ID != 0 {
+		cmd = append(cmd, "ID", strconv.FormatUint(options.ID, 10))
+	}
+	if options.Addr != "" {
+		cmd = append(cmd, "ADDR", options.Addr)
+	}
+	if options.LAddr != "" {
+		cmd = append(cmd, "LADDR", options.LAddr)
+	}
+	if options.User != "" {
+		cmd = append(cmd, "USER", options.User)
+	}
+	if options.Type != "" {
+		cmd = append(cmd, "TYPE", options.Type)
+	}
+	// The embedded API session is not a TCP client, so there is nothing to skip.
+	cmd = append(cmd, "SKIPME", "YES")
+
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return 0, err
+	}
+	return internal.ParseIntegerResponse(b)
+}
+
+// ClientPause suspends the commands of all the TCP clients until the timeout elapses or ClientUnpause is called.
+// The embedded API calls are not suspended.
+//
+// Parameters:
+//
+// `timeout` - time.Duration - The duration of the pause, with millisecond precision.
+//
+// `writeOnly` - bool - Whether to only suspend the commands that write to the keyspace.
+func (server *SugarDB) ClientPause(timeout time.Duration, writeOnly bool) error {
+	mode := "ALL"
+	if writeOnly {
+		mode = "WRITE"
+	}
+	cmd := []string{"CLIENT", "PAUSE", strconv.FormatInt(timeout.Milliseconds(), 10), mode}
+	_, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	return err
+}
+
+// ClientUnpause resumes the commands suspended by ClientPause.
+func (server *SugarDB) ClientUnpause() error {
+	_, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"CLIENT", "UNPAUSE"}), nil, false, true)
+	return err
+}
+
+// ClientSetName sets the name of the embedded API session. An empty name removes it.
+//
+// Parameters:
+//
+// `name` - string - The name of the session.
+//
+// Errors:
+//
+// "Client names cannot contain spaces, newlines or special characters." - When the name contains characters that
+// are not printable ASCII characters, or spaces.
+func (server *SugarDB) ClientSetName(name string) error {
+	_, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"CLIENT", "SETNAME", name}), nil, false, true)
+	return err
+}
+
+// ClientGetName returns the name of the embedded API session, or an empty string if it has none.
+func (server *SugarDB) ClientGetName() (string, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"CLIENT", "GETNAME"}), nil, false, true)
+	if err != nil {
+		return "", err
+	}
+	return internal.ParseStringResponse(b)
+}
@@ -17,12 +17,18 @@ package sugardb
 import (
 	"bufio"
 	"bytes"
+	"errors"
+	"fmt"
 	"github.com/echovault/sugardb/internal"
 	"github.com/echovault/sugardb/internal/constants"
 	"github.com/echovault/sugardb/internal/modules/connection"
 	"github.com/tidwall/resp"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestSugarDB_Hello(t *testing.T) {
@@ -283,3 +289,481 @@ func TestSugarDB_SetProtocol(t *testing.T) {
 		})
 	}
 }
+
+func TestSugarDB_Transaction(t *testing.T) {
+	t.Parallel()
+
+	server := createSugarDB()
+
+	t.Run("1. Execute the queued commands with Exec", func(t *testing.T) {
+		tx, err := server.Multi()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for _, cmd := range [][]string{
+			{"SET", "key1", "value1"},
+			{"INCR", "key2"},
+			// Commands that acquire the store lock themselves must not block inside the transaction.
+			{"SWAPDB", "1", "2"},
+			{"GET", "key1"},
+		} {
+			if err = tx.Queue(cmd...); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		got, err := tx.Exec()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		want := []string{"+OK\r\n", ":1\r\n", "+OK\r\n", "+value1\r\n"}
+		if len(got) != len(want) {
+			t.Errorf("Exec() got = %q, want %q", got, want)
+			return
+		}
+		for i := range want {
+			if string(got[i]) != want[i] {
+				t.Errorf("Exec() got = %q at index %d, want %q", got[i], i, want[i])
+			}
+		}
+	})
+
+	t.Run("2. Discard the queued commands", func(t *testing.T) {
+		tx, err := server.Multi()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if err = tx.Queue("SET", "key3", "value3"); err != nil {
+			t.Error(err)
+			return
+		}
+		if err = tx.Discard(); err != nil {
+			t.Error(err)
+			return
+		}
+		got, err := server.Get("key3")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got != "" {
+			t.Errorf("Get() got = %s, want empty string", got)
+		}
+	})
+
+	t.Run("3. Return error when a command could not be queued", func(t *testing.T) {
+		tx, err := server.Multi()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if err = tx.Queue("SET", "key4", "value4"); err != nil {
+			t.Error(err)
+			return
+		}
+		if err = tx.Queue("UNKNOWN"); err == nil {
+			t.Error("Queue() expected error when queueing unknown command")
+		}
+		if _, err = tx.Exec(); err == nil {
+			t.Error("Exec() expected error after a command could not be queued")
+		}
+		got, err := server.Get("key4")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got != "" {
+			t.Errorf("Get() got = %s, want empty string", got)
+		}
+	})
+
+	t.Run("4. Return error when Exec or Discard are called after the transaction ended", func(t *testing.T) {
+		tx, err := server.Multi()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if _, err = tx.Exec(); err != nil {
+			t.Error(err)
+			return
+		}
+		if _, err = tx.Exec(); err == nil {
+			t.Error("Exec() expected error after the transaction ended")
+		}
+		if err = tx.Discard(); err == nil {
+			t.Error("Discard() expected error after the transaction ended")
+		}
+	})
+
+	t.Run("5. Other embedded calls are not queued in the transaction", func(t *testing.T) {
+		tx, err := server.Multi()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = tx.Discard()
+		}()
+
+		done := make(chan error)
+		go func() {
+			if _, _, err := server.Set("key5", "value5", SETOptions{}); err != nil {
+				done <- err
+				return
+			}
+			got, err := server.Get("key5")
+			if err == nil && got != "value5" {
+				err = fmt.Errorf("Get() got = %s, want value5", got)
+			}
+			done <- err
+		}()
+		if err = <-done; err != nil {
+			t.Error(err)
+		}
+		if _, err = server.ExecuteCommand("MULTI"); err == nil {
+			t.Error("ExecuteCommand() expected error when MULTI is called without a connection")
+		}
+	})
+
+	t.Run("6. SELECT changes the database of the commands queued after it", func(t *testing.T) {
+		tx, err := server.Multi()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for _, cmd := range [][]string{
+			{"SELECT", "3"},
+			{"SET", "key6", "value6"},
+			{"SELECT", "0"},
+			{"GET", "key6"},
+		} {
+			if err = tx.Queue(cmd...); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		got, err := tx.Exec()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		want := []string{"+OK\r\n", "+OK\r\n", "+OK\r\n", "$-1\r\n"}
+		for i := range want {
+			if i >= len(got) || string(got[i]) != want[i] {
+				t.Errorf("Exec() got = %q, want %q", got, want)
+				break
+			}
+		}
+		if err = server.SelectDB(3); err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = server.SelectDB(0)
+		}()
+		if value, err := server.Get("key6"); err != nil || value != "value6" {
+			t.Errorf("Get() got = %s, error = %v, want value6", value, err)
+		}
+		// The session of the transaction is not left in the connection info.
+		if clients, err := server.ClientList(); err != nil || len(clients) != 0 {
+			t.Errorf("ClientList() got = %q, error = %v, want no clients", clients, err)
+		}
+	})
+
+	t.Run("7. Return error when a connection command is queued", func(t *testing.T) {
+		tx, err := server.Multi()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for _, cmd := range [][]string{
+			{"SUBSCRIBE", "channel"},
+			{"PSUBSCRIBE", "pattern*"},
+			{"HELLO", "3"},
+			{"CLIENT", "TRACKING", "ON"},
+		} {
+			if err = tx.Queue(cmd...); err == nil || !strings.Contains(err.Error(), "not allowed in embedded transactions") {
+				t.Errorf("Queue(%v) expected error, got %v", cmd, err)
+			}
+		}
+		if _, err = tx.Exec(); err == nil {
+			t.Error("Exec() expected error after a command could not be queued")
+		}
+		// The session is not left tracking keys.
+		server.tracking.mut.Lock()
+		defer server.tracking.mut.Unlock()
+		if len(server.tracking.clients) != 0 {
+			t.Errorf("expected no tracking clients, got %d", len(server.tracking.clients))
+		}
+	})
+}
+
+func TestSugarDB_Watch(t *testing.T) {
+	t.Parallel()
+
+	server := createSugarDB()
+
+	t.Run("1. Exec returns nil when a watched key is modified", func(t *testing.T) {
+		if _, _, err := server.Set("WatchKey1", "value1", SETOptions{}); err != nil {
+			t.Error(err)
+			return
+		}
+		tx, err := server.Multi("WatchKey1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if _, _, err = server.Set("WatchKey1", "value2", SETOptions{}); err != nil {
+			t.Error(err)
+			return
+		}
+		if err = tx.Queue("SET", "WatchKey1", "value3"); err != nil {
+			t.Error(err)
+			return
+		}
+		got, err := tx.Exec()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got != nil {
+			t.Errorf("Exec() got = %q, want nil", got)
+		}
+		value, err := server.Get("WatchKey1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if value != "value2" {
+			t.Errorf("Get() got = %s, want value2", value)
+		}
+	})
+
+	t.Run("2. Exec runs the commands when the watched keys are not modified", func(t *testing.T) {
+		tx, err := server.Multi("WatchKey2")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if err = tx.Queue("SET", "WatchKey2", "value2"); err != nil {
+			t.Error(err)
+			return
+		}
+		got, err := tx.Exec()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(got) != 1 || string(got[0]) != "+OK\r\n" {
+			t.Errorf("Exec() got = %q, want %q", got, []string{"+OK\r\n"})
+		}
+	})
+
+	t.Run("3. Return error when WATCH is queued in a transaction", func(t *testing.T) {
+		tx, err := server.Multi()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if err = tx.Queue("WATCH", "WatchKey3"); err == nil {
+			t.Error("Queue() expected error for WATCH inside MULTI")
+		}
+		if err = tx.Discard(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("4. Concurrent WatchTransaction calls do not lose updates", func(t *testing.T) {
+		if _, err := server.HSet("WatchKey4", map[string]string{"stock": "0"}); err != nil {
+			t.Error(err)
+			return
+		}
+
+		increments := 10
+		errs := make(chan error, increments)
+		var wg sync.WaitGroup
+		for i := 0; i < increments; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := server.WatchTransaction([]string{"WatchKey4"}, 100, func() ([][]string, error) {
+					values, err := server.HGet("WatchKey4", "stock")
+					if err != nil {
+						return nil, err
+					}
+					stock, err := strconv.Atoi(values[0])
+					if err != nil {
+						return nil, err
+					}
+					return [][]string{{"HSET", "WatchKey4", "stock", strconv.Itoa(stock + 1)}}, nil
+				})
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		}
+
+		values, err := server.HGet("WatchKey4", "stock")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if values[0] != strconv.Itoa(increments) {
+			t.Errorf("HGet() got = %s, want %d", values[0], increments)
+		}
+	})
+
+	t.Run("5. WatchTransaction returns the error from the callback", func(t *testing.T) {
+		_, err := server.WatchTransaction([]string{"WatchKey5"}, 1, func() ([][]string, error) {
+			return nil, errors.New("callback error")
+		})
+		if err == nil || err.Error() != "callback error" {
+			t.Errorf("WatchTransaction() got error = %v, want callback error", err)
+		}
+	})
+
+	t.Run("6. WatchTransaction returns error when the watched keys are modified on every attempt", func(t *testing.T) {
+		attempts := 0
+		_, err := server.WatchTransaction([]string{"WatchKey6"}, 2, func() ([][]string, error) {
+			attempts++
+			if _, _, err := server.Set("WatchKey6", strconv.Itoa(attempts), SETOptions{}); err != nil {
+				return nil, err
+			}
+			return [][]string{{"SET", "WatchKey6", "value"}}, nil
+		})
+		if err == nil {
+			t.Error("WatchTransaction() expected error when all the attempts are aborted")
+		}
+		if attempts != 3 {
+			t.Errorf("WatchTransaction() got %d attempts, want 3", attempts)
+		}
+		value, err := server.Get("WatchKey6")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if value != "3" {
+			t.Errorf("Get() got = %s, want 3", value)
+		}
+	})
+}
+
+func TestSugarDB_Client(t *testing.T) {
+	t.Parallel()
+
+	port, err := internal.GetFreePort()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	conf := DefaultConfig()
+	conf.DataDir = ""
+	conf.Port = uint16(port)
+	conf.RequirePass = false
+
+	mockServer := createSugarDBWithConfig(conf)
+	go func() {
+		mockServer.Start()
+	}()
+	t.Cleanup(func() {
+		mockServer.ShutDown()
+	})
+
+	t.Run("1. Set and get the name of the embedded session", func(t *testing.T) {
+		if err := mockServer.ClientSetName("embedded-client"); err != nil {
+			t.Error(err)
+			return
+		}
+		name, err := mockServer.ClientGetName()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if name != "embedded-client" {
+			t.Errorf("ClientGetName() got = %s, want embedded-client", name)
+		}
+		if err = mockServer.ClientSetName("embedded client"); err == nil {
+			t.Error("ClientSetName() expected error for name with a space")
+		}
+	})
+
+	t.Run("2. List and kill the TCP clients", func(t *testing.T) {
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		if _, err = conn.Write(internal.EncodeCommand([]string{"CLIENT", "SETNAME", "tcp-client"})); err != nil {
+			t.Error(err)
+			return
+		}
+		if _, err = bufio.NewReader(conn).ReadString('\n'); err != nil {
+			t.Error(err)
+			return
+		}
+
+		clients, err := mockServer.ClientList()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		var id uint64
+		for _, client := range clients {
+			if strings.Contains(client, " name=tcp-client ") {
+				idField := strings.Fields(client)[0]
+				id, _ = strconv.ParseUint(strings.TrimPrefix(idField, "id="), 10, 64)
+			}
+		}
+		if id == 0 {
+			t.Errorf("ClientList() got = %v, want a client named tcp-client", clients)
+			return
+		}
+
+		killed, err := mockServer.ClientKill(CLIENTKILLOptions{ID: id, Type: "pubsub"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if killed != 0 {
+			t.Errorf("ClientKill() got = %d, want 0", killed)
+		}
+		killed, err = mockServer.ClientKill(CLIENTKILLOptions{ID: id, Type: "normal"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if killed != 1 {
+			t.Errorf("ClientKill() got = %d, want 1", killed)
+		}
+		if _, err = mockServer.ClientKill(CLIENTKILLOptions{Type: "unknown"}); err == nil {
+			t.Error("ClientKill() expected error for unknown client type")
+		}
+	})
+
+	t.Run("3. Pausing the clients does not pause the embedded session", func(t *testing.T) {
+		if err := mockServer.ClientPause(10*time.Second, false); err != nil {
+			t.Error(err)
+			return
+		}
+		if _, _, err := mockServer.Set("ClientKey1", "value1", SETOptions{}); err != nil {
+			t.Error(err)
+			return
+		}
+		if err := mockServer.ClientUnpause(); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/echovault/sugardb/internal"
 )
@@ -147,6 +148,82 @@ type COPYOptions struct {
 	Replace  bool
 }
 
+// SCANOptions is a struct wrapper for all optional parameters of the Scan command.
+//
+// `Match` - string - Glob-style pattern. Only the keys that match the pattern are returned.
+//
+// `Count` - int - The number of keys to examine in the call. Defaults to 10 when not provided.
+//
+// `Type` - string - Only return keys whose value is of this type, as reported by Type.
+type SCANOptions struct {
+	Match string
+	Count int
+	Type  string
+}
+
+// RESTOREOptions is a struct wrapper for all optional parameters of the Restore command.
+//
+// `Replace` - bool - Whether to replace the key if it exists.
+//
+// `AbsTTL` - bool - Whether the ttl is an absolute unix time in milliseconds instead of a duration in milliseconds.
+//
+// `IdleTime` - uint - The number of seconds since the last access of the key, used by the LRU eviction policies.
+// Only used when greater than 0.
+//
+// `Freq` - uint - The access frequency of the key, used by the LFU eviction policies. Only used when greater than 0.
+// IdleTime and Freq can't both be provided.
+type RESTOREOptions struct {
+	Replace  bool
+	AbsTTL   bool
+	IdleTime uint
+	Freq     uint
+}
+
+// MIGRATEOptions is a struct wrapper for all optional parameters of the Migrate command.
+//
+// `Copy` - bool - Whether to keep the keys on this server after they are migrated.
+//
+// `Replace` - bool - Whether to replace the keys that exist on the target server.
+//
+// `Username` - string - The ACL user to authenticate with on the target server. Only used with Password.
+//
+// `Password` - string - The password to authenticate with on the target server. The default user is used when
+// Username is empty.
+//
+// `Timeout` - time.Duration - The maximum duration of each request to the target server. Defaults to 1 second.
+type MIGRATEOptions struct {
+	Copy     bool
+	Replace  bool
+	Username string
+	Password string
+	Timeout  time.Duration
+}
+
+// SORTOptions is a struct wrapper for all optional parameters of the Sort, SortRO and SortStore commands.
+//
+// `By` - string - Pattern of the keys whose values are used as the weights to sort the elements by.
+// The first "*" in the pattern is replaced by the element, and a "->field" suffix reads a hash field.
+// When the pattern has no "*", the elements are returned without sorting.
+//
+// `Limit` - bool - Whether to only return Count elements starting at Offset. A negative Count returns all
+// the elements from Offset.
+//
+// `Get` - []string - Patterns of the keys whose values are returned instead of the elements.
+// The pattern "#" returns the element itself.
+//
+// `Desc` - bool - Whether to sort the elements from the largest to the smallest.
+//
+// `Alpha` - bool - Whether to sort the elements lexicographically instead of numerically.
+type SORTOptions struct {
+	By     string
+	Limit  bool
+	Offset int
+	Count  int
+	Get    []string
+	Desc   bool
+	Alpha  bool
+}
+
 // Set creates or modifies the value at the given key.
 //
 // Parameters:
@@ -773,3 +850,257 @@ func (server *SugarDB) Move(key string, destinationDB int) (int, error) {
 	}
 	return internal.ParseIntegerResponse(b)
 }
+
+// Keys returns all the keys in the currently selected database that match the glob-style pattern.
+//
+// Parameters:
+//
+// `pattern` - string - the glob-style pattern to match the keys against. Use "*" to return all the keys.
+//
+// Returns: A string slice of the matching keys. The slice is empty when no keys match the pattern.
+func (server *SugarDB) Keys(pattern string) ([]string, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"KEYS", pattern}), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+	return internal.ParseStringArrayResponse(b)
+}
+
+// Scan incrementally iterates over the keys in the currently selected database.
+// Start an iteration with cursor 0 and pass the returned cursor to the next call.
+// The iteration is complete when the returned cursor is 0.
+//
+// Parameters:
+//
+// `cursor` - uint64 - the cursor returned by the previous call, or 0 to start a new iteration.
+//
+// `options` - SCANOptions.
+//
+// Returns: The cursor for the next call and the page of keys. A page may be empty even when the iteration
+// is not complete.
+//
+// Errors:
+//
+// "count must be greater than 0" - when a negative Count is provided.
+func (server *SugarDB) Scan(cursor uint64, options SCANOptions) (uint64, []string, error) {
+	cmd := []string{"SCAN", strconv.FormatUint(cursor, 10)}
+
+	if options.Match != "" {
+		cmd = append(cmd, "MATCH", options.Match)
+	}
+
+	if options.Count != 0 {
+		cmd = append(cmd, "COUNT", strconv.Itoa(options.Count))
+	}
+
+	if options.Type != "" {
+		cmd = append(cmd, "TYPE", options.Type)
+	}
+
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return 0, nil, err
+	}
+	return internal.ParseScanResponse(b)
+}
+
+// Sort returns the elements of the list, set or sorted set at the key, sorted numerically by default.
+//
+// Parameters:
+//
+// `key` - string - the key to the list, set or sorted set.
+//
+// `options` - SORTOptions.
+//
+// Returns: The sorted elements, or the values of the Get patterns for each element. Values that do not exist
+// are returned as empty strings. Returns an empty slice if the key does not exist.
+//
+// Errors:
+//
+// "value at <key> is not a list, set or sorted set" - when the key exists but holds another type.
+//
+// "One or more scores can't be converted into double" - when Alpha is false and an element or weight is not a number.
+func (server *SugarDB) Sort(key string, options SORTOptions) ([]string, error) {
+	cmd := append([]string{"SORT", key}, buildSortArgs(options)...)
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+	return internal.ParseStringArrayResponse(b)
+}
+
+// SortRO works like Sort but uses the read-only SORT_RO command.
+func (server *SugarDB) SortRO(key string, options SORTOptions) ([]string, error) {
+	cmd := append([]string{"SORT_RO", key}, buildSortArgs(options)...)
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+	return internal.ParseStringArrayResponse(b)
+}
+
+// SortStore works like Sort but stores the result in a list at the destination instead of returning it.
+// The destination is replaced if it exists, and deleted if the result is empty.
+//
+// Parameters:
+//
+// `key` - string - the key to the list, set or sorted set.
+//
+// `destination` - string - the key to store the result at.
+//
+// `options` - SORTOptions.
+//
+// Returns: The number of elements stored.
+//
+// Errors:
+//
+// "value at <key> is not a list, set or sorted set" - when the key exists but holds another type.
+//
+// "One or more scores can't be converted into double" - when Alpha is false and an element or weight is not a number.
+func (server *SugarDB) SortStore(key, destination string, options SORTOptions) (int, error) {
+	cmd := append([]string{"SORT", key}, buildSortArgs(options)...)
+	cmd = append(cmd, "STORE", destination)
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return 0, err
+	}
+	return internal.ParseIntegerResponse(b)
+}
+
+func buildSortArgs(options SORTOptions) []string {
+	var args []string
+	if options.By != "" {
+		args = append(args, "BY", options.By)
+	}
+	if options.Limit {
+		args = append(args, "LIMIT", strconv.Itoa(options.Offset), strconv.Itoa(options.Count))
+	}
+	for _, pattern := range options.Get {
+		args = append(args, "GET", pattern)
+	}
+	if options.Desc {
+		args = append(args, "DESC")
+	}
+	if options.Alpha {
+		args = append(args, "ALPHA")
+	}
+	return args
+}
+
+// Dump serializes the value at the key in a format that can be restored with Restore.
+// The serialized value includes a format version and a checksum.
+//
+// Parameters:
+//
+// `key` - string - the key to serialize.
+//
+// Returns: The serialized value, or an empty string if the key does not exist.
+func (server *SugarDB) Dump(key string) (string, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"DUMP", key}), nil, false, true)
+	if err != nil {
+		return "", err
+	}
+	return internal.ParseStringResponse(b)
+}
+
+// Restore creates the key from a value serialized with Dump.
+//
+// Parameters:
+//
+// `key` - string - the key to create.
+//
+// `ttl` - int - the number of milliseconds until the key expires, or 0 for a key that does not expire.
+//
+// `value` - string - the value returned by Dump.
+//
+// `options` - RESTOREOptions.
+//
+// Returns: true if the key is restored. A key restored with an absolute ttl in the past is not created.
+//
+// Errors:
+//
+// "BUSYKEY Target key name already exists." - when the key exists and Replace is false.
+//
+// "DUMP payload version or checksum are wrong" - when the value is corrupt or was serialized by a newer version.
+//
+// "Invalid FREQ value, must be >= 0 and <= 255" - when Freq is greater than 255.
+func (server *SugarDB) Restore(key string, ttl int, value string, options RESTOREOptions) (bool, error) {
+	cmd := []string{"RESTORE", key, strconv.Itoa(ttl), value}
+
+	if options.Replace {
+		cmd = append(cmd, "REPLACE")
+	}
+
+	if options.AbsTTL {
+		cmd = append(cmd, "ABSTTL")
+	}
+
+	if options.IdleTime > 0 {
+		cmd = append(cmd, "IDLETIME", strconv.Itoa(int(options.IdleTime)))
+	}
+
+	if options.Freq > 0 {
+		cmd = append(cmd, "FREQ", strconv.Itoa(int(options.Freq)))
+	}
+
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return false, err
+	}
+	s, err := internal.ParseStringResponse(b)
+	return s == "OK", err
+}
+
+// Migrate moves the keys to the database of another SugarDB server. The keys are restored on the target in a
+// transaction and deleted from this server, unless Copy is true. Only supported in standalone mode.
+//
+// Parameters:
+//
+// `host` - string - the host of the target server.
+//
+// `port` - int - the port of the target server.
+//
+// `destinationDB` - int - the database of the target server to move the keys to.
+//
+// `keys` - []string - the keys to migrate.
+//
+// `options` - MIGRATEOptions.
+//
+// Returns: true if the keys are migrated, or false if none of the keys exist.
+//
+// Errors:
+//
+// "IOERR error or timeout connecting to the client" - when the target server can't be reached within the timeout.
+//
+// "Target instance replied with error: <error>" - when the target server rejects a request, for example when a key
+// exists on the target and Replace is false. The keys that were migrated are still deleted from this server.
+func (server *SugarDB) Migrate(host string, port int, destinationDB int, keys []string, options MIGRATEOptions) (bool, error) {
+	cmd := []string{"MIGRATE", host, strconv.Itoa(port), "", strconv.Itoa(destinationDB),
+		strconv.FormatInt(options.Timeout.Milliseconds(), 10)}
+
+	if options.Copy {
+		cmd = append(cmd, "COPY")
+	}
+
+	if options.Replace {
+		cmd = append(cmd, "REPLACE")
+	}
+
+	if options.Password != "" {
+		if options.Username != "" {
+			cmd = append(cmd, "AUTH2", options.Username, options.Password)
+		} else {
+			cmd = append(cmd, "AUTH", options.Password)
+		}
+	}
+
+	cmd = append(cmd, "KEYS")
+	cmd = append(cmd, keys...)
+
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return false, err
+	}
+	s, err := internal.ParseStringResponse(b)
+	return s == "OK", err
+}
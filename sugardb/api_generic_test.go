@@ -16,6 +16,7 @@ package sugardb
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 	"slices"
 	"strings"
@@ -1975,3 +1976,497 @@ func TestSugarDB_MOVE(t *testing.T) {
 		})
 	}
 }
+
+func TestSugarDB_KEYS(t *testing.T) {
+	server := createSugarDB()
+
+	for _, key := range []string{"user:1", "user:2", "order:1"} {
+		if err := presetValue(server, context.Background(), key, "value"); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "1. Return all the keys",
+			pattern: "*",
+			want:    []string{"user:1", "user:2", "order:1"},
+			wantErr: false,
+		},
+		{
+			name:    "2. Return the keys that match the pattern",
+			pattern: "user:?",
+			want:    []string{"user:1", "user:2"},
+			wantErr: false,
+		},
+		{
+			name:    "3. Return an empty slice when no keys match",
+			pattern: "product:*",
+			want:    []string{},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := server.Keys(tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("KEYS() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			slices.Sort(got)
+			slices.Sort(tt.want)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("KEYS() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSugarDB_SCAN(t *testing.T) {
+	server := createSugarDB()
+
+	var keys []string
+	for i := 0; i < 100; i++ {
+		keys = append(keys, fmt.Sprintf("key%d", i))
+		if err := presetValue(server, context.Background(), keys[i], i); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	t.Run("1. Iterate over all the keys while the keyspace is mutated", func(t *testing.T) {
+		var got []string
+		var cursor uint64
+		var page []string
+		var err error
+		added := 0
+		for {
+			cursor, page, err = server.Scan(cursor, SCANOptions{Count: 10})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			got = append(got, page...)
+			// Add and delete keys between calls. Keys that exist for the whole iteration
+			// must still be returned.
+			if err = presetValue(server, context.Background(), fmt.Sprintf("new%d", added), added); err != nil {
+				t.Error(err)
+				return
+			}
+			added++
+			if cursor == 0 {
+				break
+			}
+		}
+		for _, key := range keys {
+			if !slices.Contains(got, key) {
+				t.Errorf("SCAN() expected key %s to be returned", key)
+			}
+		}
+	})
+
+	t.Run("2. Filter keys with MATCH and TYPE", func(t *testing.T) {
+		var got []string
+		var cursor uint64
+		var page []string
+		var err error
+		for {
+			cursor, page, err = server.Scan(cursor, SCANOptions{Match: "key1*", Type: "integer"})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			got = append(got, page...)
+			if cursor == 0 {
+				break
+			}
+		}
+		// key1, key10-key19
+		if len(got) != 11 {
+			t.Errorf("SCAN() expected 11 keys, got %d: %v", len(got), got)
+		}
+	})
+
+	t.Run("3. Return an error when count is not positive", func(t *testing.T) {
+		_, _, err := server.Scan(0, SCANOptions{Count: -1})
+		if err == nil {
+			t.Error("SCAN() expected an error for a negative count")
+		}
+	})
+}
+
+func TestSugarDB_SORT(t *testing.T) {
+	server := createSugarDB()
+
+	if _, err := server.RPush("sort_list", "3", "1", "2"); err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err := server.SAdd("sort_set", "b", "c", "a"); err != nil {
+		t.Error(err)
+		return
+	}
+	for element, weight := range map[string]string{"1": "30", "2": "10", "3": "20"} {
+		if _, _, err := server.Set("sort_weight_"+element, weight, SETOptions{}); err != nil {
+			t.Error(err)
+			return
+		}
+		if _, err := server.HSet("sort_hash_"+element, map[string]string{"name": "name" + element}); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		options SORTOptions
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "1. Sort a list numerically",
+			key:  "sort_list",
+			want: []string{"1", "2", "3"},
+		},
+		{
+			name:    "2. Sort a set lexicographically in descending order",
+			key:     "sort_set",
+			options: SORTOptions{Alpha: true, Desc: true},
+			want:    []string{"c", "b", "a"},
+		},
+		{
+			name:    "3. Sort by external weights and get hash fields",
+			key:     "sort_list",
+			options: SORTOptions{By: "sort_weight_*", Get: []string{"#", "sort_hash_*->name", "missing_*"}},
+			want:    []string{"2", "name2", "", "3", "name3", "", "1", "name1", ""},
+		},
+		{
+			name:    "4. Limit the result",
+			key:     "sort_list",
+			options: SORTOptions{Limit: true, Offset: 1, Count: -1},
+			want:    []string{"2", "3"},
+		},
+		{
+			name: "5. Return an empty slice when the key does not exist",
+			key:  "sort_missing",
+			want: []string{},
+		},
+		{
+			name:    "6. Return an error when an element is not a number",
+			key:     "sort_set",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, sort := range []func(string, SORTOptions) ([]string, error){server.Sort, server.SortRO} {
+				got, err := sort(tt.key, tt.options)
+				if (err != nil) != tt.wantErr {
+					t.Errorf("Sort() error = %v, wantErr %v", err, tt.wantErr)
+					return
+				}
+				if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Sort() got = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	t.Run("7. Store the result in a list", func(t *testing.T) {
+		got, err := server.SortStore("sort_list", "sort_destination", SORTOptions{Desc: true})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got != 3 {
+			t.Errorf("SortStore() got = %d, want 3", got)
+		}
+		stored, err := server.LRange("sort_destination", 0, -1)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(stored, []string{"3", "2", "1"}) {
+			t.Errorf("SortStore() stored %v, want [3 2 1]", stored)
+		}
+	})
+}
+
+func TestSugarDB_DUMP_RESTORE(t *testing.T) {
+	server := createSugarDB()
+
+	t.Run("1. Restore a dumped list at a new key", func(t *testing.T) {
+		if _, err := server.RPush("dump_list", "a", "b", "c"); err != nil {
+			t.Error(err)
+			return
+		}
+		payload, err := server.Dump("dump_list")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		ok, err := server.Restore("restored_list", 100000, payload, RESTOREOptions{})
+		if err != nil || !ok {
+			t.Errorf("Restore() got = %v, %v, want true", ok, err)
+			return
+		}
+		list, err := server.LRange("restored_list", 0, -1)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(list, []string{"a", "b", "c"}) {
+			t.Errorf("Restore() restored %v, want [a b c]", list)
+		}
+		ttl, err := server.PTTL("restored_list")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if ttl <= 0 || ttl > 100000 {
+			t.Errorf("Restore() expected a ttl of at most 100000 milliseconds, got %d", ttl)
+		}
+
+		if _, err = server.Restore("restored_list", 0, payload, RESTOREOptions{}); err == nil {
+			t.Error("Restore() expected an error when the key exists")
+		}
+		if ok, err = server.Restore("restored_list", 0, payload, RESTOREOptions{Replace: true}); err != nil || !ok {
+			t.Errorf("Restore() with Replace got = %v, %v, want true", ok, err)
+		}
+	})
+
+	t.Run("2. Return an empty payload when the key does not exist", func(t *testing.T) {
+		payload, err := server.Dump("dump_missing")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if payload != "" {
+			t.Errorf("Dump() got = %q, want empty string", payload)
+		}
+	})
+
+	t.Run("3. Reject a corrupt payload", func(t *testing.T) {
+		payload, err := server.Dump("dump_list")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err = server.Restore("corrupt_list", 0, "x"+payload[1:], RESTOREOptions{})
+		if err == nil || !strings.Contains(err.Error(), "DUMP payload version or checksum are wrong") {
+			t.Errorf("Restore() expected a checksum error, got %v", err)
+		}
+	})
+
+	t.Run("4. Seed the LFU cache with FREQ", func(t *testing.T) {
+		lfuServer := createSugarDBWithConfig(config.Config{
+			DataDir:          "",
+			EvictionPolicy:   constants.AllKeysLFU,
+			EvictionInterval: 30 * time.Second,
+			MaxMemory:        4000000,
+		})
+		payload, err := server.Dump("dump_list")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if _, err = lfuServer.Restore("restored_list", 0, payload, RESTOREOptions{Freq: 50}); err != nil {
+			t.Error(err)
+			return
+		}
+		freq, err := lfuServer.ObjectFreq("restored_list")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if freq != 50 {
+			t.Errorf("ObjectFreq() got = %d, want 50", freq)
+		}
+	})
+
+	t.Run("5. Seed the LRU cache with IDLETIME", func(t *testing.T) {
+		lruServer := createSugarDBWithConfig(config.Config{
+			DataDir:          "",
+			EvictionPolicy:   constants.AllKeysLRU,
+			EvictionInterval: 30 * time.Second,
+			MaxMemory:        4000000,
+		})
+		payload, err := server.Dump("dump_list")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if _, err = lruServer.Restore("restored_list", 0, payload, RESTOREOptions{IdleTime: 100}); err != nil {
+			t.Error(err)
+			return
+		}
+		idleTime, err := lruServer.ObjectIdleTime("restored_list")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if idleTime < 100 {
+			t.Errorf("ObjectIdleTime() got = %v, want at least 100", idleTime)
+		}
+	})
+
+	t.Run("6. Seed the volatile caches once the expiry is set", func(t *testing.T) {
+		payload, err := server.Dump("dump_list")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		lfuServer := createSugarDBWithConfig(config.Config{
+			DataDir:          "",
+			EvictionPolicy:   constants.VolatileLFU,
+			EvictionInterval: 30 * time.Second,
+			MaxMemory:        4000000,
+		})
+		if _, err = lfuServer.Restore("restored_list", 100000, payload, RESTOREOptions{Freq: 50}); err != nil {
+			t.Error(err)
+			return
+		}
+		freq, err := lfuServer.ObjectFreq("restored_list")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if freq != 50 {
+			t.Errorf("ObjectFreq() got = %d, want 50", freq)
+		}
+
+		lruServer := createSugarDBWithConfig(config.Config{
+			DataDir:          "",
+			EvictionPolicy:   constants.VolatileLRU,
+			EvictionInterval: 30 * time.Second,
+			MaxMemory:        4000000,
+		})
+		if _, err = lruServer.Restore("restored_list", 100000, payload, RESTOREOptions{IdleTime: 100}); err != nil {
+			t.Error(err)
+			return
+		}
+		idleTime, err := lruServer.ObjectIdleTime("restored_list")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if idleTime < 100 {
+			t.Errorf("ObjectIdleTime() got = %v, want at least 100", idleTime)
+		}
+	})
+}
+
+func TestSugarDB_MIGRATE(t *testing.T) {
+	port, err := internal.GetFreePort()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	conf := DefaultConfig()
+	conf.BindAddr = "localhost"
+	conf.Port = uint16(port)
+	conf.DataDir = ""
+	conf.RequirePass = true
+	conf.Password = "password"
+	target := createSugarDBWithConfig(conf)
+	go func() {
+		target.Start()
+	}()
+	t.Cleanup(func() {
+		target.ShutDown()
+	})
+	// Wait for the target to accept connections.
+	conn, err := internal.GetConnection("localhost", port)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_ = conn.Close()
+
+	server := createSugarDB()
+	if _, _, err = server.Set("migrate_key1", "value1", SETOptions{}); err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err = server.RPush("migrate_key2", "a", "b"); err != nil {
+		t.Error(err)
+		return
+	}
+	if err = target.SelectDB(2); err != nil {
+		t.Error(err)
+		return
+	}
+
+	tests := []struct {
+		name    string
+		keys    []string
+		options MIGRATEOptions
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:    "1. Copy the keys to the target",
+			keys:    []string{"migrate_key1", "migrate_key2"},
+			options: MIGRATEOptions{Copy: true, Password: "password", Timeout: time.Second},
+			want:    true,
+		},
+		{
+			name:    "2. Return an error when the keys exist on the target",
+			keys:    []string{"migrate_key1"},
+			options: MIGRATEOptions{Password: "password"},
+			wantErr: true,
+		},
+		{
+			name:    "3. Move the keys to the target and replace them",
+			keys:    []string{"migrate_key1", "migrate_key2"},
+			options: MIGRATEOptions{Replace: true, Username: "default", Password: "password"},
+			want:    true,
+		},
+		{
+			name:    "4. Return false when none of the keys exist",
+			keys:    []string{"migrate_key1", "migrate_key2"},
+			options: MIGRATEOptions{Password: "password"},
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := server.Migrate("localhost", port, 2, tt.keys, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Migrate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Migrate() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	value, err := target.Get("migrate_key1")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if value != "value1" {
+		t.Errorf("Migrate() expected value1 on the target, got %q", value)
+	}
+	list, err := target.LRange("migrate_key2", 0, -1)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !reflect.DeepEqual(list, []string{"a", "b"}) {
+		t.Errorf("Migrate() expected [a b] on the target, got %v", list)
+	}
+	if value, err = server.Get("migrate_key1"); err != nil || value != "" {
+		t.Errorf("Migrate() expected migrate_key1 to be deleted, got %q, %v", value, err)
+	}
+}
@@ -0,0 +1,348 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sugardb
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/echovault/sugardb/internal"
+	"github.com/tidwall/resp"
+)
+
+// GeoLocation is a member of a geospatial index and its position.
+type GeoLocation struct {
+	Member    string
+	Longitude float64
+	Latitude  float64
+}
+
+// GeoAddOptions modifies the behaviour of GeoAdd.
+//
+// NX only adds new members and never updates the position of existing members.
+//
+// XX only updates the position of existing members and never adds new members. NX and XX can't both be true.
+//
+// CH modifies the result to return the number of members added or moved, instead of only the number of members added.
+type GeoAddOptions struct {
+	NX bool
+	XX bool
+	CH bool
+}
+
+// GeoCoordinates is the position of a member of a geospatial index.
+type GeoCoordinates struct {
+	Longitude float64
+	Latitude  float64
+}
+
+// GeoSearchOptions describes the area searched by GeoSearch and GeoSearchStore, and how the results are returned.
+//
+// FromMember is the member at the centre of the area. When empty, Longitude and Latitude are the centre instead.
+//
+// Radius is the radius of a circular area. When it's 0, Width and Height are the dimensions of a rectangular area.
+//
+// Unit is the unit of Radius, Width and Height and of the distances in the result. It's one of "m", "km", "ft"
+// and "mi", and defaults to "m".
+//
+// Sort is "ASC" to sort the results from the nearest to the farthest and "DESC" to sort them from the farthest to
+// the nearest. The results are not sorted when empty.
+//
+// Count limits the number of results when it's not 0. When Any is false, the nearest results are returned.
+// When Any is true, the search stops as soon as enough results are found, so they are not the nearest.
+type GeoSearchOptions struct {
+	FromMember string
+	Longitude  float64
+	Latitude   float64
+	Radius     float64
+	Width      float64
+	Height     float64
+	Unit       string
+	Sort       string
+	Count      uint
+	Any        bool
+}
+
+// GeoSearchResult is a member found by GeoSearch.
+//
+// Distance is the distance between the member and the centre of the area in the unit of the search.
+//
+// Hash is the 52-bit geohash of the member, which is its score in the sorted set.
+type GeoSearchResult struct {
+	Member      string
+	Distance    float64
+	Hash        int
+	Coordinates GeoCoordinates
+}
+
+// GeoAdd adds the locations to the geospatial index at the key. The index is a sorted set where the score of
+// each member is the geohash of its position, so the sorted set commands can be used on it.
+// The index is created if it does not exist.
+//
+// Parameters:
+//
+// `key` - string - the key to the geospatial index.
+//
+// `options` - GeoAddOptions.
+//
+// `locations` - ...GeoLocation - the members to add and their positions.
+//
+// Returns: The number of members added, or the number of members added or moved when CH is true.
+//
+// Errors:
+//
+// "value at <key> is not a sorted set" - when the key exists but does not hold a sorted set.
+//
+// "invalid longitude,latitude pair <longitude>,<latitude>" - when the longitude is not between -180 and 180, or the
+// latitude is not between -85.05112878 and 85.05112878.
+//
+// "XX and NX options at the same time are not compatible" - when both NX and XX are true.
+func (server *SugarDB) GeoAdd(key string, options GeoAddOptions, locations ...GeoLocation) (int, error) {
+	cmd := []string{"GEOADD", key}
+	if options.NX {
+		cmd = append(cmd, "NX")
+	}
+	if options.XX {
+		cmd = append(cmd, "XX")
+	}
+	if options.CH {
+		cmd = append(cmd, "CH")
+	}
+	for _, location := range locations {
+		cmd = append(cmd,
+			strconv.FormatFloat(location.Longitude, 'f', -1, 64),
+			strconv.FormatFloat(location.Latitude, 'f', -1, 64),
+			location.Member,
+		)
+	}
+
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return 0, err
+	}
+	return internal.ParseIntegerResponse(b)
+}
+
+// GeoPos returns the positions of the members of the geospatial index at the key.
+//
+// Parameters:
+//
+// `key` - string - the key to the geospatial index.
+//
+// `members` - ...string - the members to return the positions of.
+//
+// Returns: A slice with the position of each member, in the order of the members. The position is nil when the
+// member or the key does not exist.
+//
+// Errors:
+//
+// "value at <key> is not a sorted set" - when the key exists but does not hold a sorted set.
+func (server *SugarDB) GeoPos(key string, members ...string) ([]*GeoCoordinates, error) {
+	cmd := append([]string{"GEOPOS", key}, members...)
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+
+	v, _, err := resp.NewReader(bytes.NewReader(b)).ReadValue()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*GeoCoordinates, len(v.Array()))
+	for i, element := range v.Array() {
+		if element.IsNull() {
+			continue
+		}
+		res[i] = &GeoCoordinates{Longitude: element.Array()[0].Float(), Latitude: element.Array()[1].Float()}
+	}
+	return res, nil
+}
+
+// GeoDist returns the distance between two members of the geospatial index at the key.
+//
+// Parameters:
+//
+// `key` - string - the key to the geospatial index.
+//
+// `member1` - string - the first member.
+//
+// `member2` - string - the second member.
+//
+// `unit` - string - the unit of the distance. One of "m", "km", "ft" and "mi". Defaults to "m" when empty.
+//
+// Returns: The distance, and false if either member or the key does not exist.
+//
+// Errors:
+//
+// "value at <key> is not a sorted set" - when the key exists but does not hold a sorted set.
+//
+// "unsupported unit provided. please use M, KM, FT, MI" - when the unit is not supported.
+func (server *SugarDB) GeoDist(key, member1, member2, unit string) (float64, bool, error) {
+	cmd := []string{"GEODIST", key, member1, member2}
+	if unit != "" {
+		cmd = append(cmd, unit)
+	}
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return 0, false, err
+	}
+
+	v, _, err := resp.NewReader(bytes.NewReader(b)).ReadValue()
+	if err != nil {
+		return 0, false, err
+	}
+	if v.IsNull() {
+		return 0, false, nil
+	}
+	return v.Float(), true, nil
+}
+
+// GeoHash returns the standard 11 character geohash strings of the members of the geospatial index at the key.
+//
+// Parameters:
+//
+// `key` - string - the key to the geospatial index.
+//
+// `members` - ...string - the members to return the geohashes of.
+//
+// Returns: A slice with the geohash of each member, in the order of the members. The geohash is an empty string
+// when the member or the key does not exist.
+//
+// Errors:
+//
+// "value at <key> is not a sorted set" - when the key exists but does not hold a sorted set.
+func (server *SugarDB) GeoHash(key string, members ...string) ([]string, error) {
+	cmd := append([]string{"GEOHASH", key}, members...)
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+	return internal.ParseStringArrayResponse(b)
+}
+
+// GeoSearch returns the members of the geospatial index at the key that are in the area described by the options.
+//
+// Parameters:
+//
+// `key` - string - the key to the geospatial index.
+//
+// `options` - GeoSearchOptions.
+//
+// Returns: The members found, with their distance from the centre of the area, their geohash and their position.
+// Returns an empty slice if the key does not exist.
+//
+// Errors:
+//
+// "value at <key> is not a sorted set" - when the key exists but does not hold a sorted set.
+//
+// "could not decode requested zset member" - when FromMember is not a member of the geospatial index.
+func (server *SugarDB) GeoSearch(key string, options GeoSearchOptions) ([]GeoSearchResult, error) {
+	cmd := append([]string{"GEOSEARCH", key}, buildGeoSearchArgs(options)...)
+	cmd = append(cmd, "WITHCOORD", "WITHDIST", "WITHHASH")
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+
+	v, _, err := resp.NewReader(bytes.NewReader(b)).ReadValue()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]GeoSearchResult, len(v.Array()))
+	for i, element := range v.Array() {
+		item := element.Array()
+		res[i] = GeoSearchResult{
+			Member:   item[0].String(),
+			Distance: item[1].Float(),
+			Hash:     item[2].Integer(),
+			Coordinates: GeoCoordinates{
+				Longitude: item[3].Array()[0].Float(),
+				Latitude:  item[3].Array()[1].Float(),
+			},
+		}
+	}
+	return res, nil
+}
+
+// GeoSearchStore works like GeoSearch but stores the members found in a new sorted set at the destination.
+// The destination is deleted if no members are found.
+//
+// Parameters:
+//
+// `destination` - string - the key to store the members found at.
+//
+// `source` - string - the key to the geospatial index.
+//
+// `options` - GeoSearchOptions.
+//
+// `storeDist` - bool - when true, the score of each member is its distance from the centre of the area in the unit
+// of the search. Otherwise, the score is its geohash so that the destination is a geospatial index.
+//
+// Returns: The number of members stored.
+//
+// Errors:
+//
+// "value at <key> is not a sorted set" - when the source exists but does not hold a sorted set.
+//
+// "could not decode requested zset member" - when FromMember is not a member of the geospatial index.
+func (server *SugarDB) GeoSearchStore(destination, source string, options GeoSearchOptions, storeDist bool) (int, error) {
+	cmd := append([]string{"GEOSEARCHSTORE", destination, source}, buildGeoSearchArgs(options)...)
+	if storeDist {
+		cmd = append(cmd, "STOREDIST")
+	}
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return 0, err
+	}
+	return internal.ParseIntegerResponse(b)
+}
+
+func buildGeoSearchArgs(options GeoSearchOptions) []string {
+	unit := options.Unit
+	if unit == "" {
+		unit = "m"
+	}
+
+	var args []string
+	if options.FromMember != "" {
+		args = append(args, "FROMMEMBER", options.FromMember)
+	} else {
+		args = append(args, "FROMLONLAT",
+			strconv.FormatFloat(options.Longitude, 'f', -1, 64),
+			strconv.FormatFloat(options.Latitude, 'f', -1, 64),
+		)
+	}
+
+	if options.Radius != 0 {
+		args = append(args, "BYRADIUS", strconv.FormatFloat(options.Radius, 'f', -1, 64), unit)
+	} else {
+		args = append(args, "BYBOX",
+			strconv.FormatFloat(options.Width, 'f', -1, 64),
+			strconv.FormatFloat(options.Height, 'f', -1, 64),
+			unit,
+		)
+	}
+
+	if options.Sort != "" {
+		args = append(args, options.Sort)
+	}
+	if options.Count != 0 {
+		args = append(args, "COUNT", strconv.Itoa(int(options.Count)))
+		if options.Any {
+			args = append(args, "ANY")
+		}
+	}
+	return args
+}
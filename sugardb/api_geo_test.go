@@ -0,0 +1,248 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sugardb
+
+import (
+	"reflect"
+	"testing"
+)
+
+var sicily = []GeoLocation{
+	{Member: "Palermo", Longitude: 13.361389, Latitude: 38.115556},
+	{Member: "Catania", Longitude: 15.087269, Latitude: 37.502669},
+}
+
+func TestSugarDB_GeoAdd(t *testing.T) {
+	t.Parallel()
+
+	server := createSugarDB()
+
+	_, _, _ = server.Set("GeoAddKey2", "value", SETOptions{})
+
+	tests := []struct {
+		name      string
+		key       string
+		options   GeoAddOptions
+		locations []GeoLocation
+		want      int
+		wantErr   bool
+	}{
+		{name: "1. Create a geospatial index", key: "GeoAddKey1", locations: sicily, want: 2},
+		{
+			name:      "2. Only count new members without CH",
+			key:       "GeoAddKey1",
+			locations: []GeoLocation{{Member: "Palermo", Longitude: 13.5, Latitude: 38}, {Member: "Trapani", Longitude: 12.5, Latitude: 38}},
+			want:      1,
+		},
+		{
+			name:      "3. Count moved members with CH",
+			key:       "GeoAddKey1",
+			options:   GeoAddOptions{CH: true},
+			locations: sicily[:1],
+			want:      1,
+		},
+		{
+			name:      "4. Don't add new members with XX",
+			key:       "GeoAddKey1",
+			options:   GeoAddOptions{XX: true},
+			locations: []GeoLocation{{Member: "Marsala", Longitude: 12.4, Latitude: 37.8}},
+			want:      0,
+		},
+		{
+			name:      "5. Return error when the coordinates are invalid",
+			key:       "GeoAddKey1",
+			locations: []GeoLocation{{Member: "North Pole", Longitude: 0, Latitude: 90}},
+			wantErr:   true,
+		},
+		{name: "6. Return error when NX and XX are both set", key: "GeoAddKey1", options: GeoAddOptions{NX: true, XX: true}, locations: sicily, wantErr: true},
+		{name: "7. Return error when the key is not a sorted set", key: "GeoAddKey2", locations: sicily, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := server.GeoAdd(tt.key, tt.options, tt.locations...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GeoAdd() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("GeoAdd() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("8. The geospatial index is a sorted set", func(t *testing.T) {
+		if card, err := server.ZCard("GeoAddKey1"); err != nil || card != 3 {
+			t.Errorf("ZCard() got = %v, %v, want 3", card, err)
+		}
+	})
+}
+
+func TestSugarDB_GeoPos_GeoDist_GeoHash(t *testing.T) {
+	t.Parallel()
+
+	server := createSugarDB()
+
+	if _, err := server.GeoAdd("GeoPosKey1", GeoAddOptions{}, sicily...); err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Run("1. Return the positions of the members", func(t *testing.T) {
+		got, err := server.GeoPos("GeoPosKey1", "Palermo", "Unknown")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		want := []*GeoCoordinates{{Longitude: 13.361389338970184, Latitude: 38.1155563954963}, nil}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("GeoPos() got = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("2. Return the distance between members", func(t *testing.T) {
+		if got, ok, err := server.GeoDist("GeoPosKey1", "Palermo", "Catania", "km"); err != nil || !ok || got != 166.2742 {
+			t.Errorf("GeoDist() got = %v, %v, %v, want 166.2742", got, ok, err)
+		}
+		if got, ok, err := server.GeoDist("GeoPosKey1", "Palermo", "Catania", ""); err != nil || !ok || got != 166274.1516 {
+			t.Errorf("GeoDist() got = %v, %v, %v, want 166274.1516", got, ok, err)
+		}
+		if _, ok, err := server.GeoDist("GeoPosKey1", "Palermo", "Unknown", ""); err != nil || ok {
+			t.Errorf("GeoDist() got = %v, %v, want false", ok, err)
+		}
+		if _, _, err := server.GeoDist("GeoPosKey1", "Palermo", "Catania", "yd"); err == nil {
+			t.Error("GeoDist() expected error when the unit is not supported")
+		}
+	})
+
+	t.Run("3. Return the geohashes of the members", func(t *testing.T) {
+		got, err := server.GeoHash("GeoPosKey1", "Palermo", "Catania", "Unknown")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		want := []string{"sqc8b49rny0", "sqdtr74hyu0", ""}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("GeoHash() got = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestSugarDB_GeoSearch(t *testing.T) {
+	t.Parallel()
+
+	server := createSugarDB()
+
+	if _, err := server.GeoAdd("GeoSearchKey1", GeoAddOptions{}, sicily...); err != nil {
+		t.Error(err)
+		return
+	}
+
+	catania := GeoSearchResult{
+		Member:      "Catania",
+		Distance:    56.4413,
+		Hash:        3479447370796909,
+		Coordinates: GeoCoordinates{Longitude: 15.087267458438873, Latitude: 37.50266842333162},
+	}
+	palermo := GeoSearchResult{
+		Member:      "Palermo",
+		Distance:    190.4424,
+		Hash:        3479099956230698,
+		Coordinates: GeoCoordinates{Longitude: 13.361389338970184, Latitude: 38.1155563954963},
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		options GeoSearchOptions
+		want    []GeoSearchResult
+		wantErr bool
+	}{
+		{
+			name:    "1. Search by radius",
+			key:     "GeoSearchKey1",
+			options: GeoSearchOptions{Longitude: 15, Latitude: 37, Radius: 200, Unit: "km", Sort: "ASC"},
+			want:    []GeoSearchResult{catania, palermo},
+		},
+		{
+			name:    "2. Search by box",
+			key:     "GeoSearchKey1",
+			options: GeoSearchOptions{Longitude: 15, Latitude: 37, Width: 400, Height: 400, Unit: "km", Sort: "DESC"},
+			want:    []GeoSearchResult{palermo, catania},
+		},
+		{
+			name:    "3. Limit the number of results",
+			key:     "GeoSearchKey1",
+			options: GeoSearchOptions{Longitude: 15, Latitude: 37, Radius: 200, Unit: "km", Count: 1},
+			want:    []GeoSearchResult{catania},
+		},
+		{
+			name:    "4. Return an empty slice when the key does not exist",
+			key:     "GeoSearchKey2",
+			options: GeoSearchOptions{Longitude: 15, Latitude: 37, Radius: 200, Unit: "km"},
+			want:    []GeoSearchResult{},
+		},
+		{
+			name:    "5. Return error when the member does not exist",
+			key:     "GeoSearchKey1",
+			options: GeoSearchOptions{FromMember: "Unknown", Radius: 200, Unit: "km"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := server.GeoSearch(tt.key, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GeoSearch() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GeoSearch() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSugarDB_GeoSearchStore(t *testing.T) {
+	t.Parallel()
+
+	server := createSugarDB()
+
+	if _, err := server.GeoAdd("GeoSearchStoreKey1", GeoAddOptions{}, sicily...); err != nil {
+		t.Error(err)
+		return
+	}
+	options := GeoSearchOptions{FromMember: "Catania", Radius: 100, Unit: "km"}
+
+	t.Run("1. Store the members found as a geospatial index", func(t *testing.T) {
+		if got, err := server.GeoSearchStore("GeoSearchStoreDest1", "GeoSearchStoreKey1", options, false); err != nil || got != 1 {
+			t.Errorf("GeoSearchStore() got = %v, %v, want 1", got, err)
+		}
+		got, err := server.GeoHash("GeoSearchStoreDest1", "Catania")
+		if err != nil || !reflect.DeepEqual(got, []string{"sqdtr74hyu0"}) {
+			t.Errorf("GeoHash() got = %v, %v, want [sqdtr74hyu0]", got, err)
+		}
+	})
+
+	t.Run("2. Store the distances of the members found", func(t *testing.T) {
+		if got, err := server.GeoSearchStore("GeoSearchStoreDest2", "GeoSearchStoreKey1", options, true); err != nil || got != 1 {
+			t.Errorf("GeoSearchStore() got = %v, %v, want 1", got, err)
+		}
+		if score, err := server.ZScore("GeoSearchStoreDest2", "Catania"); err != nil || score != float64(0) {
+			t.Errorf("ZScore() got = %v, %v, want 0", score, err)
+		}
+	})
+}
@@ -29,6 +29,16 @@ type HRandFieldOptions struct {
 	WithValues bool
 }
 
+// HScanOptions modifies the behaviour of the HScan function.
+//
+// Match is a glob-style pattern. Only the fields that match the pattern are returned.
+//
+// Count is the number of fields to examine in the call. Defaults to 10 when set to 0.
+type HScanOptions struct {
+	Match string
+	Count int
+}
+
 // HSet creates or modifies a hash map with the values provided. If the hash map does not exist it will be created.
 //
 // Parameters:
@@ -354,3 +364,36 @@ func (server *SugarDB) HDel(key string, fields ...string) (int, error) {
 	}
 	return internal.ParseIntegerResponse(b)
 }
+
+// HScan incrementally iterates over the fields and values of a hash map.
+// Start an iteration with cursor 0 and pass the returned cursor to the next call.
+// The iteration is complete when the returned cursor is 0.
+//
+// Parameters:
+//
+// `key` - string - the key to the hash map.
+//
+// `cursor` - uint64 - the cursor returned by the previous call, or 0 to start a new iteration.
+//
+// `options` - HScanOptions.
+//
+// Returns: The cursor for the next call and a flattened string slice where every second element is a value
+// preceded by its corresponding field. If the key does not exist, an empty slice is returned.
+//
+// Errors:
+//
+// "value at <key> is not a hash" - when the provided key exists but is not a hash.
+func (server *SugarDB) HScan(key string, cursor uint64, options HScanOptions) (uint64, []string, error) {
+	cmd := []string{"HSCAN", key, strconv.FormatUint(cursor, 10)}
+	if options.Match != "" {
+		cmd = append(cmd, "MATCH", options.Match)
+	}
+	if options.Count != 0 {
+		cmd = append(cmd, "COUNT", strconv.Itoa(options.Count))
+	}
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return 0, nil, err
+	}
+	return internal.ParseScanResponse(b)
+}
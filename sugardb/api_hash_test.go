@@ -882,3 +882,83 @@ func TestSugarDB_HMGet(t *testing.T) {
 		})
 	}
 }
+
+func TestSugarDB_HSCAN(t *testing.T) {
+	server := createSugarDB()
+
+	tests := []struct {
+		name        string
+		presetValue interface{}
+		key         string
+		options     HScanOptions
+		want        map[string]string
+		wantErr     bool
+	}{
+		{
+			name:        "1. Iterate over all the fields and values in a hash",
+			key:         "key1",
+			presetValue: map[string]interface{}{"field1": "value1", "field2": 123456789, "field3": 3.142},
+			options:     HScanOptions{Count: 1},
+			want:        map[string]string{"field1": "value1", "field2": "123456789", "field3": "3.142"},
+			wantErr:     false,
+		},
+		{
+			name:        "2. Only return the fields that match the pattern",
+			key:         "key2",
+			presetValue: map[string]interface{}{"field1": "value1", "field2": "value2", "other": "value3"},
+			options:     HScanOptions{Match: "field*"},
+			want:        map[string]string{"field1": "value1", "field2": "value2"},
+			wantErr:     false,
+		},
+		{
+			name:        "3. Return an empty result when the key does not exist",
+			key:         "key3",
+			presetValue: nil,
+			options:     HScanOptions{},
+			want:        map[string]string{},
+			wantErr:     false,
+		},
+		{
+			name:        "4. Return error when the key is not a hash",
+			key:         "key4",
+			presetValue: "Default value",
+			options:     HScanOptions{},
+			want:        nil,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.presetValue != nil {
+				err := presetValue(server, context.Background(), tt.key, tt.presetValue)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+			got := make(map[string]string)
+			var cursor uint64
+			for {
+				var page []string
+				var err error
+				cursor, page, err = server.HScan(tt.key, cursor, tt.options)
+				if (err != nil) != tt.wantErr {
+					t.Errorf("HSCAN() error = %v, wantErr %v", err, tt.wantErr)
+					return
+				}
+				if err != nil {
+					return
+				}
+				for i := 0; i < len(page); i += 2 {
+					got[page[i]] = page[i+1]
+				}
+				if cursor == 0 {
+					break
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HSCAN() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
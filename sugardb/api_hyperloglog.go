@@ -0,0 +1,91 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sugardb
+
+import (
+	"strings"
+
+	"github.com/echovault/sugardb/internal"
+)
+
+// PFAdd adds the elements to the HyperLogLog at the key. The HyperLogLog is created if it does not exist.
+//
+// Parameters:
+//
+// `key` - string - the key to the HyperLogLog.
+//
+// `elements` - ...string - the elements to add.
+//
+// Returns: true if the estimated cardinality changed or the HyperLogLog was created.
+//
+// Errors:
+//
+// "value at <key> is not a hyperloglog" - when the key exists but does not hold a HyperLogLog.
+func (server *SugarDB) PFAdd(key string, elements ...string) (bool, error) {
+	cmd := append([]string{"PFADD", key}, elements...)
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return false, err
+	}
+	return internal.ParseBooleanResponse(b)
+}
+
+// PFCount returns the estimated number of unique elements added to the HyperLogLogs at the keys.
+// With multiple keys, the estimated cardinality of their union is returned and the HyperLogLogs are not changed.
+//
+// Parameters:
+//
+// `keys` - ...string - the keys to the HyperLogLogs. Keys that don't exist are treated as empty HyperLogLogs.
+//
+// Returns: The estimated cardinality, which has a standard error of 0.81%.
+//
+// Errors:
+//
+// "value at <key> is not a hyperloglog" - when a key exists but does not hold a HyperLogLog.
+func (server *SugarDB) PFCount(keys ...string) (int, error) {
+	cmd := append([]string{"PFCOUNT"}, keys...)
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return 0, err
+	}
+	return internal.ParseIntegerResponse(b)
+}
+
+// PFMerge merges the HyperLogLogs at the source keys into the HyperLogLog at the destination, so that it estimates
+// the cardinality of their union. The destination is created if it does not exist and is part of the union if it does.
+//
+// Parameters:
+//
+// `destination` - string - the key to store the merged HyperLogLog at.
+//
+// `sources` - ...string - the keys to the HyperLogLogs to merge. Keys that don't exist are skipped.
+//
+// Returns: true when the merge is successful.
+//
+// Errors:
+//
+// "value at <key> is not a hyperloglog" - when the destination or a source key exists but does not hold a HyperLogLog.
+func (server *SugarDB) PFMerge(destination string, sources ...string) (bool, error) {
+	cmd := append([]string{"PFMERGE", destination}, sources...)
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return false, err
+	}
+	s, err := internal.ParseStringResponse(b)
+	if err != nil {
+		return false, err
+	}
+	return strings.EqualFold(s, "ok"), nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sugardb
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSugarDB_PFAdd_PFCount(t *testing.T) {
+	t.Parallel()
+
+	server := createSugarDB()
+
+	_, _, _ = server.Set("PFAddKey2", "value", SETOptions{})
+
+	tests := []struct {
+		name     string
+		key      string
+		elements []string
+		want     bool
+		wantErr  bool
+	}{
+		{name: "1. Create a HyperLogLog", key: "PFAddKey1", elements: []string{"a", "b", "c"}, want: true},
+		{name: "2. Add new elements", key: "PFAddKey1", elements: []string{"c", "d"}, want: true},
+		{name: "3. Return false when the elements were already added", key: "PFAddKey1", elements: []string{"a", "d"}, want: false},
+		{name: "4. Return error when the key is not a HyperLogLog", key: "PFAddKey2", elements: []string{"a"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := server.PFAdd(tt.key, tt.elements...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PFAdd() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("PFAdd() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("5. Count the elements", func(t *testing.T) {
+		if count, err := server.PFCount("PFAddKey1"); err != nil || count != 4 {
+			t.Errorf("PFCount() got = %v, %v, want 4", count, err)
+		}
+		if count, err := server.PFCount("PFAddKey3"); err != nil || count != 0 {
+			t.Errorf("PFCount() got = %v, %v, want 0", count, err)
+		}
+		if _, err := server.PFCount("PFAddKey1", "PFAddKey2"); err == nil {
+			t.Error("PFCount() expected error when a key is not a HyperLogLog")
+		}
+	})
+
+	t.Run("6. Count the union of multiple HyperLogLogs", func(t *testing.T) {
+		if _, err := server.PFAdd("PFAddKey4", "d", "e", "f"); err != nil {
+			t.Error(err)
+			return
+		}
+		if count, err := server.PFCount("PFAddKey1", "PFAddKey4"); err != nil || count != 6 {
+			t.Errorf("PFCount() got = %v, %v, want 6", count, err)
+		}
+		if count, err := server.PFCount("PFAddKey1"); err != nil || count != 4 {
+			t.Errorf("PFCount() after counting the union got = %v, %v, want 4", count, err)
+		}
+	})
+}
+
+func TestSugarDB_PFMerge(t *testing.T) {
+	t.Parallel()
+
+	server := createSugarDB()
+
+	for i := 0; i < 2000; i++ {
+		_, _ = server.PFAdd("PFMergeKey1", fmt.Sprintf("a-%d", i))
+		_, _ = server.PFAdd("PFMergeKey2", fmt.Sprintf("b-%d", i))
+	}
+	_, _ = server.PFAdd("PFMergeKey3", "a", "b")
+	_, _, _ = server.Set("PFMergeKey4", "value", SETOptions{})
+
+	tests := []struct {
+		name        string
+		destination string
+		sources     []string
+		wantMin     int
+		wantMax     int
+		wantErr     bool
+	}{
+		{
+			name:        "1. Merge HyperLogLogs into a new key",
+			destination: "PFMergeDestination1",
+			sources:     []string{"PFMergeKey1", "PFMergeKey2", "PFMergeKey5"},
+			wantMin:     3920,
+			wantMax:     4080,
+		},
+		{
+			name:        "2. Include the destination in the union when it exists",
+			destination: "PFMergeDestination1",
+			sources:     []string{"PFMergeKey3"},
+			wantMin:     3922,
+			wantMax:     4082,
+		},
+		{
+			name:        "3. Return error when a source is not a HyperLogLog",
+			destination: "PFMergeDestination2",
+			sources:     []string{"PFMergeKey4"},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := server.PFMerge(tt.destination, tt.sources...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PFMerge() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !ok {
+				t.Error("PFMerge() got = false, want true")
+			}
+			count, err := server.PFCount(tt.destination)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if count < tt.wantMin || count > tt.wantMax {
+				t.Errorf("PFCount() after PFMerge() got = %d, want between %d and %d", count, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
@@ -15,11 +15,57 @@
 package sugardb
 
 import (
+	"bytes"
+	"context"
 	"github.com/echovault/sugardb/internal"
+	"github.com/tidwall/resp"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// LPosOptions modifies the behaviour of LPos.
+//
+// Rank skips the first Rank-1 matches. A negative Rank searches from the end of the list and skips the first
+// -Rank-1 matches from the end. Defaults to 1 when 0.
+//
+// Count is the maximum number of matches to return. All the matches are returned when 0.
+//
+// MaxLen is the maximum number of elements to compare. All the elements are compared when 0.
+type LPosOptions struct {
+	Rank   int
+	Count  uint
+	MaxLen uint
+}
+
+// LMPopOptions modifies the behaviour of LMPop.
+//
+// Left pops the elements from the start of the list. Left is higher priority than Right. Left is the default
+// when neither is true.
+//
+// Right pops the elements from the end of the list.
+//
+// Count is the maximum number of elements to pop. Defaults to 1 when 0.
+type LMPopOptions struct {
+	Left  bool
+	Right bool
+	Count uint
+}
+
+// BLMPopOptions modifies the behaviour of BLMPop.
+//
+// Left pops the elements from the start of the list. Left is higher priority than Right. Left is the default
+// when neither is true.
+//
+// Right pops the elements from the end of the list.
+//
+// Count is the maximum number of elements to pop. Defaults to 1 when 0.
+type BLMPopOptions struct {
+	Left  bool
+	Right bool
+	Count uint
+}
+
 // LLen returns the length of the list.
 //
 // Parameters:
@@ -151,6 +197,74 @@ func (server *SugarDB) LRem(key string, count int, value string) (int, error) {
 	return internal.ParseIntegerResponse(b)
 }
 
+// LInsert inserts an element before or after the first occurrence of the pivot in the list.
+//
+// Parameters:
+//
+// `key` - string - the key to the list.
+//
+// `where` - string - either "BEFORE" or "AFTER" the pivot.
+//
+// `pivot` - string - the element to insert the new element next to.
+//
+// `element` - string - the element to insert.
+//
+// Returns: The length of the list after the insert, -1 if the pivot was not found, or 0 if the list does not exist.
+//
+// Errors:
+//
+// "LINSERT command on non-list item" - when the provided key exists but is not a list.
+//
+// "syntax error" - when where is not "BEFORE" or "AFTER".
+func (server *SugarDB) LInsert(key, where, pivot, element string) (int, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"LINSERT", key, where, pivot, element}), nil, false, true)
+	if err != nil {
+		return 0, err
+	}
+	return internal.ParseIntegerResponse(b)
+}
+
+// LPos returns the indices of the elements of the list that match the element.
+//
+// Parameters:
+//
+// `key` - string - the key to the list.
+//
+// `element` - string - the element to search for.
+//
+// `options` - LPosOptions.
+//
+// Returns: The indices of the matches in the order they were found. Returns an empty slice if there is no match or
+// the list does not exist.
+//
+// Errors:
+//
+// "LPOS command on non-list item" - when the provided key exists but is not a list.
+func (server *SugarDB) LPos(key, element string, options LPosOptions) ([]int, error) {
+	cmd := []string{"LPOS", key, element}
+	if options.Rank != 0 {
+		cmd = append(cmd, "RANK", strconv.Itoa(options.Rank))
+	}
+	cmd = append(cmd, "COUNT", strconv.Itoa(int(options.Count)))
+	if options.MaxLen != 0 {
+		cmd = append(cmd, "MAXLEN", strconv.Itoa(int(options.MaxLen)))
+	}
+
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+	v, _, err := resp.NewReader(bytes.NewReader(b)).ReadValue()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]int, len(v.Array()))
+	for i, index := range v.Array() {
+		res[i] = index.Integer()
+	}
+	return res, nil
+}
+
 // LMove moves an element from one list to another.
 //
 // Parameters:
@@ -181,6 +295,41 @@ func (server *SugarDB) LMove(source, destination, whereFrom, whereTo string) (bo
 	return strings.EqualFold(s, "ok"), err
 }
 
+// LMPop pops elements from the first non-empty list.
+//
+// Parameters:
+//
+// `keys` - []string - the keys to the lists, in the order they are checked.
+//
+// `options` - LMPopOptions.
+//
+// Returns: The key of the list and the elements popped, in the order they were popped. The key is empty if all the
+// lists are empty.
+//
+// Errors:
+//
+// "LMPOP command on non-list item" - when one of the keys exists but is not a list.
+func (server *SugarDB) LMPop(keys []string, options LMPopOptions) (string, []string, error) {
+	cmd := append([]string{"LMPOP", strconv.Itoa(len(keys))}, keys...)
+	switch {
+	case options.Left:
+		cmd = append(cmd, "LEFT")
+	case options.Right:
+		cmd = append(cmd, "RIGHT")
+	default:
+		cmd = append(cmd, "LEFT")
+	}
+	if options.Count != 0 {
+		cmd = append(cmd, "COUNT", strconv.Itoa(int(options.Count)))
+	}
+
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return "", nil, err
+	}
+	return parseMPopResponse(b)
+}
+
 // LPop pops an element from the start of the list and return it.
 //
 // Parameters:
@@ -231,6 +380,33 @@ func (server *SugarDB) RPop(key string, count uint) ([]string, error) {
 	return internal.ParseStringArrayResponse(b)
 }
 
+// RPopLPush removes the last element of the source list and pushes it to the start of the destination list.
+// The destination list is created if it does not exist.
+//
+// Parameters:
+//
+// `source` - string - the key to the source list.
+//
+// `destination` - string - the key to the destination list.
+//
+// Returns: The element moved, and false if the source list is empty or does not exist.
+//
+// Errors:
+//
+// "both source and destination must be lists" - when the source or the destination exists but is not a list.
+func (server *SugarDB) RPopLPush(source, destination string) (string, bool, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"RPOPLPUSH", source, destination}), nil, false, true)
+	if err != nil {
+		return "", false, err
+	}
+	isNil, err := internal.ParseNilResponse(b)
+	if err != nil || isNil {
+		return "", false, err
+	}
+	element, err := internal.ParseStringResponse(b)
+	return element, err == nil, err
+}
+
 // LPush pushed 1 or more values to the beginning of a list. If the list does not exist, a new list is created
 // wth the passed elements as its members.
 //
@@ -320,3 +496,162 @@ func (server *SugarDB) RPushX(key string, values ...string) (int, error) {
 	}
 	return internal.ParseIntegerResponse(b)
 }
+
+// BLPop pops an element from the start of the first non-empty list. When all the lists are empty, it blocks until an
+// element is pushed to one of them, the timeout expires or the context is cancelled.
+// Clients blocked on the same key are served in the order they blocked.
+//
+// Parameters:
+//
+// `ctx` - context.Context - cancelling the context stops waiting.
+//
+// `timeout` - time.Duration - the maximum time to wait. 0 waits until the context is cancelled.
+//
+// `keys` - ...string - the keys to the lists, in the order they are checked.
+//
+// Returns: The key of the list and the element popped. Both are empty if nothing was popped.
+//
+// Errors:
+//
+// "BLPOP command on non-list item" - when one of the keys exists but is not a list.
+func (server *SugarDB) BLPop(ctx context.Context, timeout time.Duration, keys ...string) (string, string, error) {
+	return server.bPop(ctx, "BLPOP", timeout, keys)
+}
+
+// BRPop works like BLPop but pops the element from the end of the list.
+//
+// Parameters:
+//
+// `ctx` - context.Context - cancelling the context stops waiting.
+//
+// `timeout` - time.Duration - the maximum time to wait. 0 waits until the context is cancelled.
+//
+// `keys` - ...string - the keys to the lists, in the order they are checked.
+//
+// Returns: The key of the list and the element popped. Both are empty if nothing was popped.
+//
+// Errors:
+//
+// "BRPOP command on non-list item" - when one of the keys exists but is not a list.
+func (server *SugarDB) BRPop(ctx context.Context, timeout time.Duration, keys ...string) (string, string, error) {
+	return server.bPop(ctx, "BRPOP", timeout, keys)
+}
+
+func (server *SugarDB) bPop(ctx context.Context, command string, timeout time.Duration, keys []string) (string, string, error) {
+	ctx, cancel := server.withCallerContext(ctx)
+	defer cancel()
+
+	cmd := append(append([]string{command}, keys...), formatTimeout(timeout))
+	b, err := server.handleCommand(ctx, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return "", "", err
+	}
+	res, err := internal.ParseStringArrayResponse(b)
+	if err != nil || len(res) == 0 {
+		return "", "", err
+	}
+	return res[0], res[1], nil
+}
+
+// BLMove moves an element from one end of the source list to one end of the destination list. When the source list
+// is empty, it blocks until an element is pushed to it, the timeout expires or the context is cancelled.
+// The destination list is created if it does not exist.
+//
+// Parameters:
+//
+// `ctx` - context.Context - cancelling the context stops waiting.
+//
+// `source` - string - the key to the list to pop the element from.
+//
+// `destination` - string - the key to the list to push the element to.
+//
+// `whereFrom` - string - "LEFT" to pop from the start of the source list and "RIGHT" to pop from its end.
+//
+// `whereTo` - string - "LEFT" to push to the start of the destination list and "RIGHT" to push to its end.
+//
+// `timeout` - time.Duration - the maximum time to wait. 0 waits until the context is cancelled.
+//
+// Returns: The element moved, and false if nothing was moved.
+//
+// Errors:
+//
+// "both source and destination must be lists" - when the source or the destination exists but is not a list.
+//
+// "wherefrom and whereto arguments must be either LEFT or RIGHT" - when whereFrom or whereTo is not LEFT or RIGHT.
+func (server *SugarDB) BLMove(ctx context.Context, source, destination, whereFrom, whereTo string, timeout time.Duration) (string, bool, error) {
+	ctx, cancel := server.withCallerContext(ctx)
+	defer cancel()
+
+	cmd := []string{"BLMOVE", source, destination, whereFrom, whereTo, formatTimeout(timeout)}
+	b, err := server.handleCommand(ctx, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return "", false, err
+	}
+	isNil, err := internal.ParseNilResponse(b)
+	if err != nil || isNil {
+		return "", false, err
+	}
+	element, err := internal.ParseStringResponse(b)
+	return element, err == nil, err
+}
+
+// BLMPop pops elements from the first non-empty list. When all the lists are empty, it blocks until an element is
+// pushed to one of them, the timeout expires or the context is cancelled.
+//
+// Parameters:
+//
+// `ctx` - context.Context - cancelling the context stops waiting.
+//
+// `timeout` - time.Duration - the maximum time to wait. 0 waits until the context is cancelled.
+//
+// `keys` - []string - the keys to the lists, in the order they are checked.
+//
+// `options` - BLMPopOptions.
+//
+// Returns: The key of the list and the elements popped, in the order they were popped. The key is empty if nothing
+// was popped.
+//
+// Errors:
+//
+// "BLMPOP command on non-list item" - when one of the keys exists but is not a list.
+func (server *SugarDB) BLMPop(ctx context.Context, timeout time.Duration, keys []string, options BLMPopOptions) (string, []string, error) {
+	ctx, cancel := server.withCallerContext(ctx)
+	defer cancel()
+
+	cmd := append([]string{"BLMPOP", formatTimeout(timeout), strconv.Itoa(len(keys))}, keys...)
+	switch {
+	case options.Left:
+		cmd = append(cmd, "LEFT")
+	case options.Right:
+		cmd = append(cmd, "RIGHT")
+	default:
+		cmd = append(cmd, "LEFT")
+	}
+	if options.Count != 0 {
+		cmd = append(cmd, "COUNT", strconv.Itoa(int(options.Count)))
+	}
+
+	b, err := server.handleCommand(ctx, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return "", nil, err
+	}
+	return parseMPopResponse(b)
+}
+
+// parseMPopResponse parses the key and the elements popped by LMPOP and BLMPOP.
+func parseMPopResponse(b []byte) (string, []string, error) {
+	v, _, err := resp.NewReader(bytes.NewReader(b)).ReadValue()
+	if err != nil || v.IsNull() {
+		return "", nil, err
+	}
+	elements := make([]string, len(v.Array()[1].Array()))
+	for i, element := range v.Array()[1].Array() {
+		elements[i] = element.String()
+	}
+	return v.Array()[0].String(), elements, nil
+}
+
+// formatTimeout formats the timeout of a blocking command in seconds.
+func formatTimeout(timeout time.Duration) string {
+	return strconv.FormatFloat(timeout.Seconds(), 'f', -1, 64)
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestSugarDB_LLEN(t *testing.T) {
@@ -936,3 +937,531 @@ func TestSugarDB_LTRIM(t *testing.T) {
 		})
 	}
 }
+
+func TestSugarDB_BPOP(t *testing.T) {
+	server := createSugarDB()
+
+	tests := []struct {
+		name        string
+		preset      bool
+		presetValue interface{}
+		keys        []string
+		popFunc     func(ctx context.Context, timeout time.Duration, keys ...string) (string, string, error)
+		wantKey     string
+		wantElement string
+		wantErr     bool
+	}{
+		{
+			name:        "1. BLPOP pops the first element of the first non-empty list",
+			preset:      true,
+			presetValue: []string{"value1", "value2", "value3"},
+			keys:        []string{"bpop_key0", "bpop_key1"},
+			popFunc:     server.BLPop,
+			wantKey:     "bpop_key1",
+			wantElement: "value1",
+		},
+		{
+			name:        "2. BRPOP pops the last element of the first non-empty list",
+			preset:      true,
+			presetValue: []string{"value1", "value2", "value3"},
+			keys:        []string{"bpop_key0", "bpop_key2"},
+			popFunc:     server.BRPop,
+			wantKey:     "bpop_key2",
+			wantElement: "value3",
+		},
+		{
+			name:    "3. Return empty strings when the timeout expires",
+			keys:    []string{"bpop_key3"},
+			popFunc: server.BLPop,
+		},
+		{
+			name:        "4. Return an error when a key is not a list",
+			preset:      true,
+			presetValue: "Default value",
+			keys:        []string{"bpop_key4"},
+			popFunc:     server.BRPop,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.preset {
+				err := presetValue(server, context.Background(), tt.keys[len(tt.keys)-1], tt.presetValue)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+			key, element, err := tt.popFunc(context.Background(), 50*time.Millisecond, tt.keys...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BPOP() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if key != tt.wantKey || element != tt.wantElement {
+				t.Errorf("BPOP() got = %v, %v, want %v, %v", key, element, tt.wantKey, tt.wantElement)
+			}
+		})
+	}
+
+	t.Run("5. Block until an element is pushed", func(t *testing.T) {
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			if _, err := server.RPush("bpop_key5", "value1"); err != nil {
+				t.Error(err)
+			}
+		}()
+		key, element, err := server.BLPop(context.Background(), 0, "bpop_key5")
+		if err != nil || key != "bpop_key5" || element != "value1" {
+			t.Errorf("BLPop() got = %v, %v, %v, want bpop_key5, value1", key, element, err)
+		}
+	})
+
+	t.Run("6. Stop waiting when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		key, element, err := server.BRPop(ctx, 0, "bpop_key6")
+		if err != nil || key != "" || element != "" {
+			t.Errorf("BRPop() got = %v, %v, %v, want empty strings", key, element, err)
+		}
+	})
+}
+
+func TestSugarDB_BLMOVE(t *testing.T) {
+	server := createSugarDB()
+
+	if err := presetValue(server, context.Background(), "blmove_source1", []string{"value1", "value2"}); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := presetValue(server, context.Background(), "blmove_destination2", "Default value"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	tests := []struct {
+		name        string
+		source      string
+		destination string
+		whereFrom   string
+		whereTo     string
+		want        string
+		wantOk      bool
+		wantErr     bool
+	}{
+		{
+			name:        "1. Move an element to a new list",
+			source:      "blmove_source1",
+			destination: "blmove_destination1",
+			whereFrom:   "RIGHT",
+			whereTo:     "LEFT",
+			want:        "value2",
+			wantOk:      true,
+		},
+		{
+			name:        "2. Return false when the timeout expires",
+			source:      "blmove_source2",
+			destination: "blmove_destination1",
+			whereFrom:   "LEFT",
+			whereTo:     "LEFT",
+		},
+		{
+			name:        "3. Return an error when the destination is not a list",
+			source:      "blmove_source1",
+			destination: "blmove_destination2",
+			whereFrom:   "LEFT",
+			whereTo:     "LEFT",
+			wantErr:     true,
+		},
+		{
+			name:        "4. Return an error when whereFrom is not LEFT or RIGHT",
+			source:      "blmove_source1",
+			destination: "blmove_destination1",
+			whereFrom:   "UP",
+			whereTo:     "LEFT",
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := server.BLMove(context.Background(), tt.source, tt.destination, tt.whereFrom, tt.whereTo, 50*time.Millisecond)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BLMove() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("BLMove() got = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+
+	t.Run("5. The element is pushed to the destination", func(t *testing.T) {
+		got, err := server.LRange("blmove_destination1", 0, -1)
+		if err != nil || !reflect.DeepEqual(got, []string{"value2"}) {
+			t.Errorf("LRange() got = %v, %v, want [value2]", got, err)
+		}
+	})
+}
+
+func TestSugarDB_BLMPOP(t *testing.T) {
+	server := createSugarDB()
+
+	if err := presetValue(server, context.Background(), "blmpop_key2", []string{"value1", "value2", "value3"}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	tests := []struct {
+		name         string
+		keys         []string
+		options      BLMPopOptions
+		wantKey      string
+		wantElements []string
+	}{
+		{
+			name:         "1. Pop from the start of the first non-empty list",
+			keys:         []string{"blmpop_key1", "blmpop_key2"},
+			options:      BLMPopOptions{},
+			wantKey:      "blmpop_key2",
+			wantElements: []string{"value1"},
+		},
+		{
+			name:         "2. Pop up to count elements from the end of the list",
+			keys:         []string{"blmpop_key1", "blmpop_key2"},
+			options:      BLMPopOptions{Right: true, Count: 5},
+			wantKey:      "blmpop_key2",
+			wantElements: []string{"value3", "value2"},
+		},
+		{
+			name:    "3. Return an empty key when the timeout expires",
+			keys:    []string{"blmpop_key1", "blmpop_key2"},
+			options: BLMPopOptions{Left: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, elements, err := server.BLMPop(context.Background(), 50*time.Millisecond, tt.keys, tt.options)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if key != tt.wantKey || !reflect.DeepEqual(elements, tt.wantElements) {
+				t.Errorf("BLMPop() got = %v, %v, want %v, %v", key, elements, tt.wantKey, tt.wantElements)
+			}
+		})
+	}
+}
+
+func TestSugarDB_LINSERT(t *testing.T) {
+	server := createSugarDB()
+
+	tests := []struct {
+		name        string
+		preset      bool
+		presetValue interface{}
+		key         string
+		where       string
+		pivot       string
+		element     string
+		want        int
+		wantList    []string
+		wantErr     bool
+	}{
+		{
+			name:        "1. Insert the element before the pivot",
+			preset:      true,
+			presetValue: []string{"value1", "value2", "value3"},
+			key:         "linsert_key1",
+			where:       "BEFORE",
+			pivot:       "value2",
+			element:     "value4",
+			want:        4,
+			wantList:    []string{"value1", "value4", "value2", "value3"},
+		},
+		{
+			name:        "2. Insert the element after the pivot",
+			preset:      true,
+			presetValue: []string{"value1", "value2", "value3"},
+			key:         "linsert_key2",
+			where:       "AFTER",
+			pivot:       "value3",
+			element:     "value4",
+			want:        4,
+			wantList:    []string{"value1", "value2", "value3", "value4"},
+		},
+		{
+			name:        "3. Return -1 when the pivot is not found",
+			preset:      true,
+			presetValue: []string{"value1"},
+			key:         "linsert_key3",
+			where:       "AFTER",
+			pivot:       "value2",
+			element:     "value4",
+			want:        -1,
+			wantList:    []string{"value1"},
+		},
+		{
+			name:    "4. Return 0 when the list does not exist",
+			key:     "linsert_key4",
+			where:   "AFTER",
+			pivot:   "value1",
+			element: "value2",
+			want:    0,
+		},
+		{
+			name:        "5. Return an error when the key is not a list",
+			preset:      true,
+			presetValue: "Default value",
+			key:         "linsert_key5",
+			where:       "BEFORE",
+			pivot:       "value1",
+			element:     "value2",
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.preset {
+				err := presetValue(server, context.Background(), tt.key, tt.presetValue)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+			got, err := server.LInsert(tt.key, tt.where, tt.pivot, tt.element)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LINSERT() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("LINSERT() got = %v, want %v", got, tt.want)
+			}
+			if tt.wantList != nil {
+				list, err := server.LRange(tt.key, 0, -1)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if !reflect.DeepEqual(list, tt.wantList) {
+					t.Errorf("LINSERT() list = %v, want %v", list, tt.wantList)
+				}
+			}
+		})
+	}
+}
+
+func TestSugarDB_LPOS(t *testing.T) {
+	server := createSugarDB()
+
+	if err := presetValue(server, context.Background(), "lpos_key1", []string{"a", "b", "c", "d", "c", "c"}); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := presetValue(server, context.Background(), "lpos_key2", "Default value"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		element string
+		options LPosOptions
+		want    []int
+		wantErr bool
+	}{
+		{
+			name:    "1. Return all the matches",
+			key:     "lpos_key1",
+			element: "c",
+			want:    []int{2, 4, 5},
+		},
+		{
+			name:    "2. Return the first match after skipping a match",
+			key:     "lpos_key1",
+			element: "c",
+			options: LPosOptions{Rank: 2, Count: 1},
+			want:    []int{4},
+		},
+		{
+			name:    "3. Search from the end of the list",
+			key:     "lpos_key1",
+			element: "c",
+			options: LPosOptions{Rank: -1, Count: 2},
+			want:    []int{5, 4},
+		},
+		{
+			name:    "4. Only compare MaxLen elements",
+			key:     "lpos_key1",
+			element: "c",
+			options: LPosOptions{MaxLen: 4},
+			want:    []int{2},
+		},
+		{
+			name:    "5. Return an empty slice when there is no match",
+			key:     "lpos_key1",
+			element: "e",
+			want:    []int{},
+		},
+		{
+			name:    "6. Return an empty slice when the list does not exist",
+			key:     "lpos_key3",
+			element: "a",
+			want:    []int{},
+		},
+		{
+			name:    "7. Return an error when the key is not a list",
+			key:     "lpos_key2",
+			element: "a",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := server.LPos(tt.key, tt.element, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LPOS() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LPOS() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSugarDB_LMPOP(t *testing.T) {
+	server := createSugarDB()
+
+	tests := []struct {
+		name        string
+		preset      bool
+		presetKey   string
+		presetValue interface{}
+		keys        []string
+		options     LMPopOptions
+		wantKey     string
+		want        []string
+		wantErr     bool
+	}{
+		{
+			name:        "1. Pop elements from the start of the first non-empty list",
+			preset:      true,
+			presetKey:   "lmpop_key2",
+			presetValue: []string{"value1", "value2", "value3"},
+			keys:        []string{"lmpop_key1", "lmpop_key2"},
+			options:     LMPopOptions{Count: 2},
+			wantKey:     "lmpop_key2",
+			want:        []string{"value1", "value2"},
+		},
+		{
+			name:        "2. Pop an element from the end of the list",
+			preset:      true,
+			presetKey:   "lmpop_key3",
+			presetValue: []string{"value1", "value2", "value3"},
+			keys:        []string{"lmpop_key3"},
+			options:     LMPopOptions{Right: true},
+			wantKey:     "lmpop_key3",
+			want:        []string{"value3"},
+		},
+		{
+			name: "3. Return an empty key when all the lists are empty",
+			keys: []string{"lmpop_key4", "lmpop_key5"},
+		},
+		{
+			name:        "4. Return an error when a key is not a list",
+			preset:      true,
+			presetKey:   "lmpop_key6",
+			presetValue: "Default value",
+			keys:        []string{"lmpop_key6"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.preset {
+				err := presetValue(server, context.Background(), tt.presetKey, tt.presetValue)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+			key, got, err := server.LMPop(tt.keys, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LMPop() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if key != tt.wantKey || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LMPop() got = %v, %v, want %v, %v", key, got, tt.wantKey, tt.want)
+			}
+		})
+	}
+}
+
+func TestSugarDB_RPOPLPUSH(t *testing.T) {
+	server := createSugarDB()
+
+	if err := presetValue(server, context.Background(), "rpoplpush_source1", []string{"value1", "value2"}); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := presetValue(server, context.Background(), "rpoplpush_destination2", "Default value"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	tests := []struct {
+		name        string
+		source      string
+		destination string
+		want        string
+		wantOk      bool
+		wantErr     bool
+	}{
+		{
+			name:        "1. Move the last element to a new list",
+			source:      "rpoplpush_source1",
+			destination: "rpoplpush_destination1",
+			want:        "value2",
+			wantOk:      true,
+		},
+		{
+			name:        "2. Push the element to the start of the destination list",
+			source:      "rpoplpush_source1",
+			destination: "rpoplpush_destination1",
+			want:        "value1",
+			wantOk:      true,
+		},
+		{
+			name:        "3. Return false when the source list is empty",
+			source:      "rpoplpush_source1",
+			destination: "rpoplpush_destination1",
+		},
+		{
+			name:        "4. Return an error when the destination is not a list",
+			source:      "rpoplpush_destination1",
+			destination: "rpoplpush_destination2",
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := server.RPopLPush(tt.source, tt.destination)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RPopLPush() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("RPopLPush() got = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+
+	list, err := server.LRange("rpoplpush_destination1", 0, -1)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !reflect.DeepEqual(list, []string{"value1", "value2"}) {
+		t.Errorf("RPopLPush() destination = %v, want [value1 value2]", list)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sugardb
+
+import (
+	"github.com/echovault/sugardb/internal"
+	"strconv"
+)
+
+// Eval atomically runs a Lua script. The script can call any command with redis.call and redis.pcall.
+// The script is added to the script cache, so it can later be run with EvalSha.
+//
+// Parameters:
+//
+// `script` - string - The source of the Lua script.
+//
+// `keys` - []string - The keys accessed by the script. They are available to the script in the KEYS table.
+//
+// `args` - []string - The arguments of the script. They are available to the script in the ARGV table.
+//
+// Returns: The raw RESP response of the script.
+//
+// Errors:
+//
+// "error compiling script: <error>" - When the script is not valid Lua.
+//
+// "error running script: <error>" - When the script raises an error, including the errors of redis.call.
+func (server *SugarDB) Eval(script string, keys []string, args []string) ([]byte, error) {
+	cmd := append([]string{"EVAL", script, strconv.Itoa(len(keys))}, keys...)
+	return server.handleCommand(server.context, internal.EncodeCommand(append(cmd, args...)), nil, false, true)
+}
+
+// EvalSha atomically runs a script from the script cache.
+//
+// Parameters:
+//
+// `sha` - string - The SHA1 digest of the script, as returned by ScriptLoad.
+//
+// `keys` - []string - The keys accessed by the script. They are available to the script in the KEYS table.
+//
+// `args` - []string - The arguments of the script. They are available to the script in the ARGV table.
+//
+// Returns: The raw RESP response of the script.
+//
+// Errors:
+//
+// "NOSCRIPT No matching script. Please use EVAL" - When the script is not in the script cache.
+func (server *SugarDB) EvalSha(sha string, keys []string, args []string) ([]byte, error) {
+	cmd := append([]string{"EVALSHA", sha, strconv.Itoa(len(keys))}, keys...)
+	return server.handleCommand(server.context, internal.EncodeCommand(append(cmd, args...)), nil, false, true)
+}
+
+// ScriptLoad adds a Lua script to the script cache without running it.
+//
+// Parameters:
+//
+// `script` - string - The source of the Lua script.
+//
+// Returns: The SHA1 digest of the script.
+func (server *SugarDB) ScriptLoad(script string) (string, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"SCRIPT", "LOAD", script}), nil, false, true)
+	if err != nil {
+		return "", err
+	}
+	return internal.ParseStringResponse(b)
+}
+
+// ScriptExists checks whether scripts are in the script cache.
+//
+// Parameters:
+//
+// `shas` - ...string - The SHA1 digests of the scripts.
+//
+// Returns: A slice with true for each script that is in the script cache, in the order of the digests.
+func (server *SugarDB) ScriptExists(shas ...string) ([]bool, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(append([]string{"SCRIPT", "EXISTS"}, shas...)), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+	return internal.ParseBooleanArrayResponse(b)
+}
+
+// ScriptFlush removes all the scripts from the script cache.
+func (server *SugarDB) ScriptFlush() error {
+	_, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"SCRIPT", "FLUSH"}), nil, false, true)
+	return err
+}
+
+// ScriptKill stops the Lua script that is running. A script that already called a write command can't be stopped.
+//
+// Errors:
+//
+// "NOTBUSY No scripts in execution right now" - When no script is running.
+//
+// "UNKILLABLE Sorry the script already executed write commands against the dataset" - When the script already
+// called a write command.
+func (server *SugarDB) ScriptKill() error {
+	_, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"SCRIPT", "KILL"}), nil, false, true)
+	return err
+}
@@ -0,0 +1,214 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sugardb
+
+import (
+	"github.com/echovault/sugardb/internal/config"
+	"github.com/echovault/sugardb/internal/constants"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSugarDB_Eval(t *testing.T) {
+	t.Parallel()
+
+	server := createSugarDB()
+
+	tests := []struct {
+		name    string
+		script  string
+		keys    []string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "1. Return a bulk string",
+			script: "return ARGV[1]",
+			args:   []string{"value1"},
+			want:   "$6\r\nvalue1\r\n",
+		},
+		{
+			name:   "2. Call commands with redis.call",
+			script: "redis.call('SET', KEYS[1], ARGV[1]); return redis.call('GET', KEYS[1])",
+			keys:   []string{"EvalKey1"},
+			args:   []string{"value2"},
+			want:   "$6\r\nvalue2\r\n",
+		},
+		{
+			name:   "3. Return an array",
+			script: "return {1, 'two'}",
+			want:   "*2\r\n:1\r\n$3\r\ntwo\r\n",
+		},
+		{
+			name:    "4. Return error when the script raises an error",
+			script:  "error('script error')",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := server.Eval(tt.script, tt.keys, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Eval() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("Eval() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSugarDB_EvalSha(t *testing.T) {
+	t.Parallel()
+
+	server := createSugarDB()
+
+	sha, err := server.ScriptLoad("return redis.call('INCRBY', KEYS[1], ARGV[1])")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if sha != "8cd00688c05c46bde4a2e60658ef20a2e5c0b248" {
+		t.Errorf("ScriptLoad() got = %s, want %s", sha, "8cd00688c05c46bde4a2e60658ef20a2e5c0b248")
+		return
+	}
+
+	got, err := server.EvalSha(sha, []string{"EvalShaKey1"}, []string{"3"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if string(got) != ":3\r\n" {
+		t.Errorf("EvalSha() got = %q, want %q", got, ":3\r\n")
+	}
+
+	exists, err := server.ScriptExists(sha, "unknown")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !reflect.DeepEqual(exists, []bool{true, false}) {
+		t.Errorf("ScriptExists() got = %v, want %v", exists, []bool{true, false})
+	}
+
+	if err = server.ScriptFlush(); err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err = server.EvalSha(sha, []string{"EvalShaKey1"}, []string{"3"}); err == nil {
+		t.Error("EvalSha() expected error after ScriptFlush")
+	}
+}
+
+func TestSugarDB_ScriptKill(t *testing.T) {
+	t.Parallel()
+
+	t.Run("1. Return error when no script is running", func(t *testing.T) {
+		server := createSugarDB()
+		err := server.ScriptKill()
+		if err == nil || !strings.Contains(err.Error(), "NOTBUSY") {
+			t.Errorf("ScriptKill() error = %v, want NOTBUSY error", err)
+		}
+	})
+
+	t.Run("2. Stop a script that runs forever", func(t *testing.T) {
+		server := createSugarDB()
+
+		done := make(chan error)
+		go func() {
+			_, err := server.Eval("while true do end", nil, nil)
+			done <- err
+		}()
+
+		// Retry until the script is running.
+		for {
+			err := server.ScriptKill()
+			if err == nil {
+				break
+			}
+			if !strings.Contains(err.Error(), "NOTBUSY") {
+				t.Errorf("ScriptKill() unexpected error = %v", err)
+				return
+			}
+			<-time.After(10 * time.Millisecond)
+		}
+
+		select {
+		case err := <-done:
+			if err == nil || !strings.Contains(err.Error(), "SCRIPT KILL") {
+				t.Errorf("Eval() error = %v, want error from SCRIPT KILL", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("Eval() did not return after SCRIPT KILL")
+		}
+
+		// The server is usable after the script is stopped.
+		if _, _, err := server.Set("ScriptKillKey1", "value1", SETOptions{}); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("3. Return error when the script already called a write command", func(t *testing.T) {
+		server := createSugarDB()
+
+		cancelled := false
+		server.scripts.running = &runningScript{
+			cancel:  func(error) { cancelled = true },
+			written: true,
+		}
+		err := server.ScriptKill()
+		if err == nil || !strings.Contains(err.Error(), "UNKILLABLE") {
+			t.Errorf("ScriptKill() error = %v, want UNKILLABLE error", err)
+		}
+		if cancelled {
+			t.Error("ScriptKill() stopped a script that already called a write command")
+		}
+	})
+
+	t.Run("4. Stop a script that runs longer than lua-time-limit", func(t *testing.T) {
+		server := createSugarDBWithConfig(config.Config{
+			DataDir:        "",
+			EvictionPolicy: constants.NoEviction,
+			LuaTimeLimit:   50,
+		})
+
+		start := time.Now()
+		_, err := server.Eval("while true do end", nil, nil)
+		if err == nil || !strings.Contains(err.Error(), "lua-time-limit") {
+			t.Errorf("Eval() error = %v, want lua-time-limit error", err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("Eval() returned after %s, want shortly after lua-time-limit", elapsed)
+		}
+
+		// A script that already wrote to the keyspace is not stopped.
+		res, err := server.Eval(
+			"redis.call('SET', KEYS[1], 'value1'); local i = 0; while i < 2000000 do i = i + 1 end; return i",
+			[]string{"ScriptKillKey2"}, nil,
+		)
+		if err != nil {
+			t.Errorf("Eval() unexpected error = %v", err)
+			return
+		}
+		if string(res) != ":2000000\r\n" {
+			t.Errorf("Eval() got = %q, want %q", res, ":2000000\r\n")
+		}
+	})
+}
@@ -19,6 +19,16 @@ import (
 	"strconv"
 )
 
+// SScanOptions modifies the behaviour of the SScan function.
+//
+// Match is a glob-style pattern. Only the members that match the pattern are returned.
+//
+// Count is the number of members to examine in the call. Defaults to 10 when set to 0.
+type SScanOptions struct {
+	Match string
+	Count int
+}
+
 // SAdd adds member(s) to a set. If the set does not exist, a new sorted set is created with the
 // member(s).
 //
@@ -346,3 +356,35 @@ func (server *SugarDB) SUnionStore(destination string, keys ...string) (int, err
 	}
 	return internal.ParseIntegerResponse(b)
 }
+
+// SScan incrementally iterates over the members of a set.
+// Start an iteration with cursor 0 and pass the returned cursor to the next call.
+// The iteration is complete when the returned cursor is 0.
+//
+// Parameters:
+//
+// `key` - string - the key to the set.
+//
+// `cursor` - uint64 - the cursor returned by the previous call, or 0 to start a new iteration.
+//
+// `options` - SScanOptions.
+//
+// Returns: The cursor for the next call and a page of members. If the key does not exist, an empty slice is returned.
+//
+// Errors:
+//
+// "value at <key> is not a set" - when the provided key exists but is not a set.
+func (server *SugarDB) SScan(key string, cursor uint64, options SScanOptions) (uint64, []string, error) {
+	cmd := []string{"SSCAN", key, strconv.FormatUint(cursor, 10)}
+	if options.Match != "" {
+		cmd = append(cmd, "MATCH", options.Match)
+	}
+	if options.Count != 0 {
+		cmd = append(cmd, "COUNT", strconv.Itoa(options.Count))
+	}
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return 0, nil, err
+	}
+	return internal.ParseScanResponse(b)
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/echovault/sugardb/internal/modules/set"
 	"reflect"
 	"slices"
+	"strconv"
 	"testing"
 )
 
@@ -1209,3 +1210,102 @@ func TestSugarDB_SUNIONSTORE(t *testing.T) {
 		})
 	}
 }
+
+func TestSugarDB_SSCAN(t *testing.T) {
+	server := createSugarDB()
+
+	tests := []struct {
+		name        string
+		presetValue interface{}
+		key         string
+		options     SScanOptions
+		mutate      bool
+		want        []string
+		wantErr     bool
+	}{
+		{
+			name:        "1. Iterate over all the members of a set",
+			key:         "key1",
+			presetValue: set.NewSet([]string{"one", "two", "three", "four", "five", "six", "seven", "eight"}),
+			options:     SScanOptions{Count: 3},
+			want:        []string{"one", "two", "three", "four", "five", "six", "seven", "eight"},
+			wantErr:     false,
+		},
+		{
+			name:        "2. Return the original members when the set is mutated between calls",
+			key:         "key2",
+			presetValue: set.NewSet([]string{"one", "two", "three", "four", "five", "six", "seven", "eight"}),
+			options:     SScanOptions{Count: 2},
+			mutate:      true,
+			want:        []string{"one", "two", "three", "four", "five", "six", "seven", "eight"},
+			wantErr:     false,
+		},
+		{
+			name:        "3. Only return the members that match the pattern",
+			key:         "key3",
+			presetValue: set.NewSet([]string{"one", "two", "three", "four"}),
+			options:     SScanOptions{Match: "t*"},
+			want:        []string{"two", "three"},
+			wantErr:     false,
+		},
+		{
+			name:        "4. Return an empty result when the key does not exist",
+			key:         "key4",
+			presetValue: nil,
+			options:     SScanOptions{},
+			want:        []string{},
+			wantErr:     false,
+		},
+		{
+			name:        "5. Return error when the key is not a set",
+			key:         "key5",
+			presetValue: "Default value",
+			options:     SScanOptions{},
+			want:        nil,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.presetValue != nil {
+				err := presetValue(server, context.Background(), tt.key, tt.presetValue)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+			var got []string
+			var cursor uint64
+			for i := 0; ; i++ {
+				var page []string
+				var err error
+				cursor, page, err = server.SScan(tt.key, cursor, tt.options)
+				if (err != nil) != tt.wantErr {
+					t.Errorf("SSCAN() error = %v, wantErr %v", err, tt.wantErr)
+					return
+				}
+				if err != nil {
+					return
+				}
+				got = append(got, page...)
+				if cursor == 0 {
+					break
+				}
+				if tt.mutate {
+					if _, err = server.SAdd(tt.key, "new"+strconv.Itoa(i)); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}
+			for _, member := range tt.want {
+				if !slices.Contains(got, member) {
+					t.Errorf("SSCAN() got = %v, want %v", got, tt.want)
+				}
+			}
+			if !tt.mutate && len(got) != len(tt.want) {
+				t.Errorf("SSCAN() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
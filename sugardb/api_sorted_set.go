@@ -15,8 +15,12 @@
 package sugardb
 
 import (
+	"bytes"
+	"context"
 	"github.com/echovault/sugardb/internal"
+	"github.com/tidwall/resp"
 	"strconv"
+	"time"
 )
 
 // ZAddOptions allows you to modify the effects of the ZAdd command.
@@ -79,6 +83,20 @@ type ZMPopOptions struct {
 	Count uint
 }
 
+// BZMPopOptions modifies the behaviour of BZMPop.
+//
+// Min pops the members with the lowest scores. Min is higher priority than Max. Min is the default when neither
+// is true.
+//
+// Max pops the members with the highest scores.
+//
+// Count is the maximum number of members to pop. Defaults to 1 when 0.
+type BZMPopOptions struct {
+	Min   bool
+	Max   bool
+	Count uint
+}
+
 // ZRangeOptions allows you to modify the effects of the ZRange* family of commands.
 //
 // WithScores specifies whether to return the associated scores.
@@ -102,6 +120,16 @@ type ZRangeOptions struct {
 }
 type ZRangeStoreOptions ZRangeOptions
 
+// ZScanOptions modifies the behaviour of the ZScan function.
+//
+// Match is a glob-style pattern. Only the members that match the pattern are returned.
+//
+// Count is the number of members to examine in the call. Defaults to 10 when set to 0.
+type ZScanOptions struct {
+	Match string
+	Count int
+}
+
 func buildMemberScoreMap(arr [][]string, withscores bool) (map[string]float64, error) {
 	result := make(map[string]float64, len(arr))
 	for _, entry := range arr {
@@ -1019,3 +1047,165 @@ func (server *SugarDB) ZRangeStore(destination, source, start, stop string, opti
 
 	return internal.ParseIntegerResponse(b)
 }
+
+// ZScan incrementally iterates over the members and scores of a sorted set.
+// Start an iteration with cursor 0 and pass the returned cursor to the next call.
+// The iteration is complete when the returned cursor is 0.
+//
+// Parameters:
+//
+// `key` - string - the key to the sorted set.
+//
+// `cursor` - uint64 - the cursor returned by the previous call, or 0 to start a new iteration.
+//
+// `options` - ZScanOptions.
+//
+// Returns: The cursor for the next call and a map of map[string]float64 where the key is the member and the value
+// is its score. If the key does not exist, an empty map is returned.
+//
+// Errors:
+//
+// "value at <key> is not a sorted set" - when the provided key exists but is not a sorted set.
+func (server *SugarDB) ZScan(key string, cursor uint64, options ZScanOptions) (uint64, map[string]float64, error) {
+	cmd := []string{"ZSCAN", key, strconv.FormatUint(cursor, 10)}
+	if options.Match != "" {
+		cmd = append(cmd, "MATCH", options.Match)
+	}
+	if options.Count != 0 {
+		cmd = append(cmd, "COUNT", strconv.Itoa(options.Count))
+	}
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	next, arr, err := internal.ParseScanResponse(b)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	res := make(map[string]float64, len(arr)/2)
+	for i := 0; i+1 < len(arr); i += 2 {
+		score, err := strconv.ParseFloat(arr[i+1], 64)
+		if err != nil {
+			return 0, nil, err
+		}
+		res[arr[i]] = score
+	}
+
+	return next, res, nil
+}
+
+// BZPopMin pops the member with the lowest score from the first non-empty sorted set. When all the sorted sets are
+// empty, it blocks until members are added to one of them, the timeout expires or the context is cancelled.
+// Clients blocked on the same key are served in the order they blocked.
+//
+// Parameters:
+//
+// `ctx` - context.Context - cancelling the context stops waiting.
+//
+// `timeout` - time.Duration - the maximum time to wait. 0 waits until the context is cancelled.
+//
+// `keys` - ...string - the keys to the sorted sets, in the order they are checked.
+//
+// Returns: The key of the sorted set, and the member popped and its score. The key and the member are empty if
+// nothing was popped.
+//
+// Errors:
+//
+// "value at key <key> is not a sorted set" - when one of the keys exists but is not a sorted set.
+func (server *SugarDB) BZPopMin(ctx context.Context, timeout time.Duration, keys ...string) (string, string, float64, error) {
+	return server.bzPop(ctx, "BZPOPMIN", timeout, keys)
+}
+
+// BZPopMax works like BZPopMin but pops the member with the highest score.
+//
+// Parameters:
+//
+// `ctx` - context.Context - cancelling the context stops waiting.
+//
+// `timeout` - time.Duration - the maximum time to wait. 0 waits until the context is cancelled.
+//
+// `keys` - ...string - the keys to the sorted sets, in the order they are checked.
+//
+// Returns: The key of the sorted set, and the member popped and its score. The key and the member are empty if
+// nothing was popped.
+//
+// Errors:
+//
+// "value at key <key> is not a sorted set" - when one of the keys exists but is not a sorted set.
+func (server *SugarDB) BZPopMax(ctx context.Context, timeout time.Duration, keys ...string) (string, string, float64, error) {
+	return server.bzPop(ctx, "BZPOPMAX", timeout, keys)
+}
+
+func (server *SugarDB) bzPop(ctx context.Context, command string, timeout time.Duration, keys []string) (string, string, float64, error) {
+	ctx, cancel := server.withCallerContext(ctx)
+	defer cancel()
+
+	cmd := append(append([]string{command}, keys...), formatTimeout(timeout))
+	b, err := server.handleCommand(ctx, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return "", "", 0, err
+	}
+	res, err := internal.ParseStringArrayResponse(b)
+	if err != nil || len(res) == 0 {
+		return "", "", 0, err
+	}
+	score, err := strconv.ParseFloat(res[2], 64)
+	if err != nil {
+		return "", "", 0, err
+	}
+	return res[0], res[1], score, nil
+}
+
+// BZMPop pops members from the first non-empty sorted set. When all the sorted sets are empty, it blocks until
+// members are added to one of them, the timeout expires or the context is cancelled.
+// Clients blocked on the same key are served in the order they blocked.
+//
+// Parameters:
+//
+// `ctx` - context.Context - cancelling the context stops waiting.
+//
+// `timeout` - time.Duration - the maximum time to wait. 0 waits until the context is cancelled.
+//
+// `keys` - []string - the keys to the sorted sets, in the order they are checked.
+//
+// `options` - BZMPopOptions.
+//
+// Returns: The key of the sorted set, and a 2-dimensional slice where each slice contains the member and score
+// at the 0 and 1 indices respectively, in the order they were popped. The key is empty if nothing was popped.
+//
+// Errors:
+//
+// "value at key <key> is not a sorted set" - when one of the keys exists but is not a sorted set.
+func (server *SugarDB) BZMPop(ctx context.Context, timeout time.Duration, keys []string, options BZMPopOptions) (string, [][]string, error) {
+	ctx, cancel := server.withCallerContext(ctx)
+	defer cancel()
+
+	cmd := append([]string{"BZMPOP", formatTimeout(timeout), strconv.Itoa(len(keys))}, keys...)
+	switch {
+	case options.Min:
+		cmd = append(cmd, "MIN")
+	case options.Max:
+		cmd = append(cmd, "MAX")
+	default:
+		cmd = append(cmd, "MIN")
+	}
+	if options.Count != 0 {
+		cmd = append(cmd, "COUNT", strconv.Itoa(int(options.Count)))
+	}
+
+	b, err := server.handleCommand(ctx, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return "", nil, err
+	}
+	v, _, err := resp.NewReader(bytes.NewReader(b)).ReadValue()
+	if err != nil || v.IsNull() {
+		return "", nil, err
+	}
+	members := make([][]string, len(v.Array()[1].Array()))
+	for i, member := range v.Array()[1].Array() {
+		members[i] = []string{member.Array()[0].String(), member.Array()[1].String()}
+	}
+	return v.Array()[0].String(), members, nil
+}
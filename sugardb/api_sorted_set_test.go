@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"strconv"
 	"testing"
+	"time"
 )
 
 func TestSugarDB_ZADD(t *testing.T) {
@@ -3588,3 +3589,259 @@ func TestSugarDB_ZRemRangeByRank(t *testing.T) {
 		})
 	}
 }
+
+func TestSugarDB_ZSCAN(t *testing.T) {
+	server := createSugarDB()
+
+	tests := []struct {
+		name        string
+		presetValue interface{}
+		key         string
+		options     ZScanOptions
+		want        map[string]float64
+		wantErr     bool
+	}{
+		{
+			name: "1. Iterate over all the members and scores of a sorted set",
+			key:  "key1",
+			presetValue: ss.NewSortedSet([]ss.MemberParam{
+				{Value: "one", Score: 1}, {Value: "two", Score: 2}, {Value: "three", Score: 3},
+				{Value: "four", Score: 4.5}, {Value: "five", Score: ss.Score(math.Inf(1))},
+			}),
+			options: ZScanOptions{Count: 2},
+			want: map[string]float64{
+				"one": 1, "two": 2, "three": 3, "four": 4.5, "five": math.Inf(1),
+			},
+			wantErr: false,
+		},
+		{
+			name: "2. Only return the members that match the pattern",
+			key:  "key2",
+			presetValue: ss.NewSortedSet([]ss.MemberParam{
+				{Value: "one", Score: 1}, {Value: "two", Score: 2}, {Value: "three", Score: 3},
+			}),
+			options: ZScanOptions{Match: "t*"},
+			want:    map[string]float64{"two": 2, "three": 3},
+			wantErr: false,
+		},
+		{
+			name:        "3. Return an empty result when the key does not exist",
+			key:         "key3",
+			presetValue: nil,
+			options:     ZScanOptions{},
+			want:        map[string]float64{},
+			wantErr:     false,
+		},
+		{
+			name:        "4. Return error when the key is not a sorted set",
+			key:         "key4",
+			presetValue: "Default value",
+			options:     ZScanOptions{},
+			want:        nil,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.presetValue != nil {
+				err := presetValue(server, context.Background(), tt.key, tt.presetValue)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+			got := make(map[string]float64)
+			var cursor uint64
+			for {
+				var page map[string]float64
+				var err error
+				cursor, page, err = server.ZScan(tt.key, cursor, tt.options)
+				if (err != nil) != tt.wantErr {
+					t.Errorf("ZSCAN() error = %v, wantErr %v", err, tt.wantErr)
+					return
+				}
+				if err != nil {
+					return
+				}
+				for member, score := range page {
+					got[member] = score
+				}
+				if cursor == 0 {
+					break
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ZSCAN() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSugarDB_BZPOP(t *testing.T) {
+	server := createSugarDB()
+
+	tests := []struct {
+		name        string
+		preset      bool
+		presetValue interface{}
+		keys        []string
+		popFunc     func(ctx context.Context, timeout time.Duration, keys ...string) (string, string, float64, error)
+		wantKey     string
+		wantMember  string
+		wantScore   float64
+		wantErr     bool
+	}{
+		{
+			name:   "1. BZPOPMIN pops the member with the lowest score from the first non-empty sorted set",
+			preset: true,
+			presetValue: ss.NewSortedSet([]ss.MemberParam{
+				{Value: "one", Score: 1}, {Value: "two", Score: 2}, {Value: "three", Score: 3},
+			}),
+			keys:       []string{"bzpop_key0", "bzpop_key1"},
+			popFunc:    server.BZPopMin,
+			wantKey:    "bzpop_key1",
+			wantMember: "one",
+			wantScore:  1,
+		},
+		{
+			name:   "2. BZPOPMAX pops the member with the highest score from the first non-empty sorted set",
+			preset: true,
+			presetValue: ss.NewSortedSet([]ss.MemberParam{
+				{Value: "one", Score: 1}, {Value: "two", Score: 2}, {Value: "three", Score: 3.5},
+			}),
+			keys:       []string{"bzpop_key0", "bzpop_key2"},
+			popFunc:    server.BZPopMax,
+			wantKey:    "bzpop_key2",
+			wantMember: "three",
+			wantScore:  3.5,
+		},
+		{
+			name:    "3. Return empty strings when the timeout expires",
+			keys:    []string{"bzpop_key3"},
+			popFunc: server.BZPopMin,
+		},
+		{
+			name:        "4. Return an error when a key is not a sorted set",
+			preset:      true,
+			presetValue: "Default value",
+			keys:        []string{"bzpop_key4"},
+			popFunc:     server.BZPopMax,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.preset {
+				err := presetValue(server, context.Background(), tt.keys[len(tt.keys)-1], tt.presetValue)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+			key, member, score, err := tt.popFunc(context.Background(), 50*time.Millisecond, tt.keys...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BZPOP() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if key != tt.wantKey || member != tt.wantMember || score != tt.wantScore {
+				t.Errorf("BZPOP() got = %v, %v, %v, want %v, %v, %v",
+					key, member, score, tt.wantKey, tt.wantMember, tt.wantScore)
+			}
+		})
+	}
+
+	t.Run("5. Block until a member is added", func(t *testing.T) {
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			if _, err := server.ZAdd("bzpop_key5", map[string]float64{"member1": 5}, ZAddOptions{}); err != nil {
+				t.Error(err)
+			}
+		}()
+		key, member, score, err := server.BZPopMin(context.Background(), 0, "bzpop_key5")
+		if err != nil || key != "bzpop_key5" || member != "member1" || score != 5 {
+			t.Errorf("BZPopMin() got = %v, %v, %v, %v, want bzpop_key5, member1, 5", key, member, score, err)
+		}
+	})
+
+	t.Run("6. Stop waiting when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		key, member, _, err := server.BZPopMax(ctx, 0, "bzpop_key6")
+		if err != nil || key != "" || member != "" {
+			t.Errorf("BZPopMax() got = %v, %v, %v, want empty strings", key, member, err)
+		}
+	})
+}
+
+func TestSugarDB_BZMPOP(t *testing.T) {
+	server := createSugarDB()
+
+	tests := []struct {
+		name        string
+		preset      bool
+		presetKey   string
+		presetValue interface{}
+		keys        []string
+		options     BZMPopOptions
+		wantKey     string
+		want        [][]string
+		wantErr     bool
+	}{
+		{
+			name:      "1. Pop the members with the lowest scores from the first non-empty sorted set",
+			preset:    true,
+			presetKey: "bzmpop_key2",
+			presetValue: ss.NewSortedSet([]ss.MemberParam{
+				{Value: "one", Score: 1}, {Value: "two", Score: 2}, {Value: "three", Score: 3},
+			}),
+			keys:    []string{"bzmpop_key1", "bzmpop_key2"},
+			options: BZMPopOptions{Count: 2},
+			wantKey: "bzmpop_key2",
+			want:    [][]string{{"one", "1"}, {"two", "2"}},
+		},
+		{
+			name:      "2. Pop the members with the highest scores",
+			preset:    true,
+			presetKey: "bzmpop_key3",
+			presetValue: ss.NewSortedSet([]ss.MemberParam{
+				{Value: "one", Score: 1}, {Value: "two", Score: 2}, {Value: "three", Score: 3},
+			}),
+			keys:    []string{"bzmpop_key3"},
+			options: BZMPopOptions{Max: true},
+			wantKey: "bzmpop_key3",
+			want:    [][]string{{"three", "3"}},
+		},
+		{
+			name:    "3. Return an empty key when the timeout expires",
+			keys:    []string{"bzmpop_key4"},
+			options: BZMPopOptions{Max: true},
+		},
+		{
+			name:        "4. Return an error when a key is not a sorted set",
+			preset:      true,
+			presetKey:   "bzmpop_key5",
+			presetValue: "Default value",
+			keys:        []string{"bzmpop_key5"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.preset {
+				err := presetValue(server, context.Background(), tt.presetKey, tt.presetValue)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+			key, got, err := server.BZMPop(context.Background(), 50*time.Millisecond, tt.keys, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BZMPop() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if key != tt.wantKey || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BZMPop() got = %v, %v, want %v, %v", key, got, tt.wantKey, tt.want)
+			}
+		})
+	}
+}
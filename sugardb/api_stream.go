@@ -0,0 +1,998 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sugardb
+
+import (
+	"bytes"
+	"github.com/echovault/sugardb/internal"
+	"github.com/tidwall/resp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StreamEntry is an entry of a stream returned by XRange, XRevRange, XRead, XReadGroup, XClaim and XAutoClaim.
+//
+// ID is the ID of the entry in the "ms-seq" format.
+//
+// Fields holds the field-value pairs of the entry. It's nil for pending entries that were deleted from the stream
+// and for entries claimed with JustID.
+type StreamEntry struct {
+	ID     string
+	Fields map[string]string
+}
+
+// XTrimOptions allows you to trim a stream with XTrim and XAdd.
+//
+// Strategy is either "MAXLEN" or "MINID". "MAXLEN" keeps at most Threshold entries, and "MINID" removes the entries
+// with IDs smaller than Threshold. The stream is not trimmed if Strategy is empty.
+//
+// Approximate allows SugarDB to remove fewer entries than requested. It's required to use Limit.
+//
+// Limit is the maximum number of entries to remove. 0 means no limit.
+type XTrimOptions struct {
+	Strategy    string
+	Threshold   string
+	Approximate bool
+	Limit       uint
+}
+
+// XAddOptions allows you to modify the behaviour of the XAdd command.
+//
+// NoMkStream does not create the stream if it does not exist.
+//
+// ID is the ID of the new entry. When empty, the ID is generated from the current time. "ms-*" generates only
+// the sequence number.
+//
+// Trim trims the stream after the entry is added.
+type XAddOptions struct {
+	NoMkStream bool
+	ID         string
+	Trim       XTrimOptions
+}
+
+// XReadOptions allows you to modify the behaviour of the XRead command.
+//
+// Count is the maximum number of entries to return for each stream. 0 means no limit.
+//
+// Block waits for new entries when there are none.
+//
+// Timeout is the maximum time to wait when Block is true. 0 waits indefinitely.
+type XReadOptions struct {
+	Count   uint
+	Block   bool
+	Timeout time.Duration
+}
+
+// XGroupCreateOptions allows you to modify the behaviour of the XGroupCreate command.
+//
+// MkStream creates an empty stream if the stream does not exist.
+type XGroupCreateOptions struct {
+	MkStream bool
+}
+
+// XReadGroupOptions allows you to modify the behaviour of the XReadGroup command.
+//
+// Count is the maximum number of entries to return for each stream. 0 means no limit.
+//
+// Block waits for new entries when there are none.
+//
+// Timeout is the maximum time to wait when Block is true. 0 waits indefinitely.
+//
+// NoAck does not add the delivered entries to the pending entries of the consumer.
+type XReadGroupOptions struct {
+	Count   uint
+	Block   bool
+	Timeout time.Duration
+	NoAck   bool
+}
+
+// XPendingSummary is the summary of the pending entries of a consumer group returned by XPending.
+//
+// Count is the number of pending entries.
+//
+// First and Last are the smallest and the largest IDs of the pending entries. They are empty if there are none.
+//
+// Consumers maps the consumers that have pending entries to their number of pending entries.
+type XPendingSummary struct {
+	Count     int
+	First     string
+	Last      string
+	Consumers map[string]int
+}
+
+// XPendingOptions selects the pending entries returned by XPendingRange.
+//
+// Idle is the minimum time since the entries were last delivered.
+//
+// Start and End are the smallest and the largest IDs of the range. They default to "-" and "+".
+//
+// Count is the maximum number of entries to return. 0 returns no entries.
+//
+// Consumer only returns the pending entries of the consumer when not empty.
+type XPendingOptions struct {
+	Idle     time.Duration
+	Start    string
+	End      string
+	Count    uint
+	Consumer string
+}
+
+// PendingEntry is a pending entry of a consumer group returned by XPendingRange.
+//
+// Idle is the time since the entry was last delivered.
+//
+// DeliveryCount is the number of times the entry was delivered.
+type PendingEntry struct {
+	ID            string
+	Consumer      string
+	Idle          time.Duration
+	DeliveryCount int
+}
+
+// XClaimOptions allows you to modify the behaviour of the XClaim command.
+//
+// Idle sets the time since the claimed entries were last delivered. Time sets the time the claimed entries were
+// last delivered instead. The entries are delivered now when both are zero.
+//
+// RetryCount sets the delivery count of the claimed entries when greater than 0.
+//
+// Force claims the entries that are not pending in any consumer as long as they exist in the stream.
+//
+// JustID returns only the IDs of the claimed entries and does not increment their delivery count.
+//
+// LastID sets the last delivered ID of the group if it's greater than the current one.
+type XClaimOptions struct {
+	Idle       time.Duration
+	Time       time.Time
+	RetryCount uint
+	Force      bool
+	JustID     bool
+	LastID     string
+}
+
+// XAutoClaimOptions allows you to modify the behaviour of the XAutoClaim command.
+//
+// Count is the maximum number of pending entries to examine. It defaults to 100.
+//
+// JustID returns only the IDs of the claimed entries and does not increment their delivery count.
+type XAutoClaimOptions struct {
+	Count  uint
+	JustID bool
+}
+
+// StreamInfo is the state of a stream returned by XInfoStream.
+//
+// FirstEntry and LastEntry are nil when the stream is empty.
+type StreamInfo struct {
+	Length            int
+	LastGeneratedID   string
+	MaxDeletedEntryID string
+	EntriesAdded      int
+	Groups            int
+	FirstEntry        *StreamEntry
+	LastEntry         *StreamEntry
+}
+
+// StreamGroupInfo is the state of a consumer group returned by XInfoGroups.
+//
+// Lag is the number of entries in the stream that were not delivered to the group yet.
+type StreamGroupInfo struct {
+	Name            string
+	Consumers       int
+	Pending         int
+	LastDeliveredID string
+	EntriesRead     int
+	Lag             int
+}
+
+// StreamConsumerInfo is the state of a consumer returned by XInfoConsumers.
+//
+// Idle is the time since the consumer last read or claimed entries.
+//
+// Inactive is the time since the consumer was last delivered or claimed an entry. It's negative if the consumer
+// never was.
+type StreamConsumerInfo struct {
+	Name     string
+	Pending  int
+	Idle     time.Duration
+	Inactive time.Duration
+}
+
+func buildTrimArgs(options XTrimOptions) []string {
+	if options.Strategy == "" {
+		return []string{}
+	}
+	args := []string{strings.ToUpper(options.Strategy)}
+	if options.Approximate {
+		args = append(args, "~")
+	}
+	args = append(args, options.Threshold)
+	if options.Limit > 0 {
+		args = append(args, "LIMIT", strconv.FormatUint(uint64(options.Limit), 10))
+	}
+	return args
+}
+
+func parseStreamEntries(v resp.Value) []StreamEntry {
+	entries := make([]StreamEntry, len(v.Array()))
+	for i, e := range v.Array() {
+		entry := e.Array()
+		entries[i] = StreamEntry{ID: entry[0].String()}
+		if entry[1].IsNull() {
+			// The entry was deleted from the stream but is still pending.
+			continue
+		}
+		fields := entry[1].Array()
+		entries[i].Fields = make(map[string]string, len(fields)/2)
+		for j := 0; j+1 < len(fields); j += 2 {
+			entries[i].Fields[fields[j].String()] = fields[j+1].String()
+		}
+	}
+	return entries
+}
+
+// parseStreamsResponse returns the entries of each stream in the response of XREAD and XREADGROUP.
+func parseStreamsResponse(b []byte) (map[string][]StreamEntry, error) {
+	v, _, err := resp.NewReader(bytes.NewReader(b)).ReadValue()
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string][]StreamEntry)
+	for _, stream := range v.Array() {
+		res[stream.Array()[0].String()] = parseStreamEntries(stream.Array()[1])
+	}
+	return res, nil
+}
+
+// parseInfoFields returns the field-value pairs of an XINFO reply.
+func parseInfoFields(v resp.Value) map[string]resp.Value {
+	fields := make(map[string]resp.Value)
+	for i := 0; i+1 < len(v.Array()); i += 2 {
+		fields[v.Array()[i].String()] = v.Array()[i+1]
+	}
+	return fields
+}
+
+// parseClaimedEntries returns the entries claimed by XCLAIM and XAUTOCLAIM.
+func parseClaimedEntries(v resp.Value, justID bool) []StreamEntry {
+	if !justID {
+		return parseStreamEntries(v)
+	}
+	entries := make([]StreamEntry, len(v.Array()))
+	for i, id := range v.Array() {
+		entries[i] = StreamEntry{ID: id.String()}
+	}
+	return entries
+}
+
+func parseStreamEntriesResponse(b []byte) ([]StreamEntry, error) {
+	v, _, err := resp.NewReader(bytes.NewReader(b)).ReadValue()
+	if err != nil {
+		return nil, err
+	}
+	return parseStreamEntries(v), nil
+}
+
+// XAdd appends an entry to the stream.
+//
+// Parameters:
+//
+// `key` - string - the key of the stream.
+//
+// `fields` - map[string]string - the field-value pairs of the entry. The fields are added in lexicographical order.
+//
+// `options` - XAddOptions.
+//
+// Returns: The ID of the new entry. Returns an empty string if NoMkStream is true and the stream does not exist.
+//
+// Errors:
+//
+// "value at <key> is not a stream" - when the provided key exists but is not a stream.
+//
+// "the ID specified in XADD is equal or smaller than the target stream top item" - when the ID is not greater than
+// the ID of the last entry in the stream.
+func (server *SugarDB) XAdd(key string, fields map[string]string, options XAddOptions) (string, error) {
+	cmd := []string{"XADD", key}
+	if options.NoMkStream {
+		cmd = append(cmd, "NOMKSTREAM")
+	}
+	cmd = append(cmd, buildTrimArgs(options.Trim)...)
+
+	if options.ID == "" {
+		cmd = append(cmd, "*")
+	} else {
+		cmd = append(cmd, options.ID)
+	}
+
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	slices.Sort(names)
+	for _, field := range names {
+		cmd = append(cmd, field, fields[field])
+	}
+
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return "", err
+	}
+	return internal.ParseStringResponse(b)
+}
+
+// XRange returns the entries of the stream with IDs between start and end.
+//
+// Parameters:
+//
+// `key` - string - the key of the stream.
+//
+// `start` - string - the smallest ID of the range. "-" is the smallest possible ID. An ID prefixed with "(" is excluded.
+//
+// `end` - string - the largest ID of the range. "+" is the largest possible ID. An ID prefixed with "(" is excluded.
+//
+// `count` - uint - the maximum number of entries to return. 0 means no limit.
+//
+// Returns: The entries in the range, from the smallest ID to the largest. Returns an empty slice if the stream
+// does not exist.
+//
+// Errors:
+//
+// "value at <key> is not a stream" - when the provided key exists but is not a stream.
+func (server *SugarDB) XRange(key, start, end string, count uint) ([]StreamEntry, error) {
+	cmd := []string{"XRANGE", key, start, end}
+	if count > 0 {
+		cmd = append(cmd, "COUNT", strconv.FormatUint(uint64(count), 10))
+	}
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+	return parseStreamEntriesResponse(b)
+}
+
+// XRevRange returns the entries of the stream with IDs between end and start, from the largest ID to the smallest.
+//
+// Parameters:
+//
+// `key` - string - the key of the stream.
+//
+// `end` - string - the largest ID of the range. "+" is the largest possible ID. An ID prefixed with "(" is excluded.
+//
+// `start` - string - the smallest ID of the range. "-" is the smallest possible ID. An ID prefixed with "(" is excluded.
+//
+// `count` - uint - the maximum number of entries to return. 0 means no limit.
+//
+// Returns: The entries in the range, from the largest ID to the smallest. Returns an empty slice if the stream
+// does not exist.
+//
+// Errors:
+//
+// "value at <key> is not a stream" - when the provided key exists but is not a stream.
+func (server *SugarDB) XRevRange(key, end, start string, count uint) ([]StreamEntry, error) {
+	cmd := []string{"XREVRANGE", key, end, start}
+	if count > 0 {
+		cmd = append(cmd, "COUNT", strconv.FormatUint(uint64(count), 10))
+	}
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+	return parseStreamEntriesResponse(b)
+}
+
+// XLen returns the number of entries in the stream.
+//
+// Parameters:
+//
+// `key` - string - the key of the stream.
+//
+// Returns: The number of entries in the stream. Returns 0 if the stream does not exist.
+//
+// Errors:
+//
+// "value at <key> is not a stream" - when the provided key exists but is not a stream.
+func (server *SugarDB) XLen(key string) (int, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"XLEN", key}), nil, false, true)
+	if err != nil {
+		return 0, err
+	}
+	return internal.ParseIntegerResponse(b)
+}
+
+// XDel removes the entries with the IDs from the stream.
+//
+// Parameters:
+//
+// `key` - string - the key of the stream.
+//
+// `ids` - ...string - the IDs of the entries to remove.
+//
+// Returns: The number of entries removed.
+//
+// Errors:
+//
+// "value at <key> is not a stream" - when the provided key exists but is not a stream.
+func (server *SugarDB) XDel(key string, ids ...string) (int, error) {
+	cmd := append([]string{"XDEL", key}, ids...)
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return 0, err
+	}
+	return internal.ParseIntegerResponse(b)
+}
+
+// XTrim removes the oldest entries of the stream.
+//
+// Parameters:
+//
+// `key` - string - the key of the stream.
+//
+// `options` - XTrimOptions.
+//
+// Returns: The number of entries removed.
+//
+// Errors:
+//
+// "value at <key> is not a stream" - when the provided key exists but is not a stream.
+func (server *SugarDB) XTrim(key string, options XTrimOptions) (int, error) {
+	cmd := append([]string{"XTRIM", key}, buildTrimArgs(options)...)
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return 0, err
+	}
+	return internal.ParseIntegerResponse(b)
+}
+
+// XRead returns the entries with IDs greater than the provided ID of each stream.
+//
+// Parameters:
+//
+// `streams` - map[string]string - the keys of the streams mapped to the ID to read after. "$" is the ID of the
+// last entry in the stream when XRead is called.
+//
+// `options` - XReadOptions.
+//
+// Returns: A map of the keys of the streams that have new entries to their entries. Returns an empty map if there
+// are no new entries, or if the timeout expires when blocking.
+//
+// Errors:
+//
+// "value at <key> is not a stream" - when one of the provided keys exists but is not a stream.
+func (server *SugarDB) XRead(streams map[string]string, options XReadOptions) (map[string][]StreamEntry, error) {
+	cmd := []string{"XREAD"}
+	if options.Count > 0 {
+		cmd = append(cmd, "COUNT", strconv.FormatUint(uint64(options.Count), 10))
+	}
+	if options.Block {
+		cmd = append(cmd, "BLOCK", strconv.FormatInt(options.Timeout.Milliseconds(), 10))
+	}
+
+	cmd = append(cmd, "STREAMS")
+	keys := make([]string, 0, len(streams))
+	for key := range streams {
+		keys = append(keys, key)
+	}
+	cmd = append(cmd, keys...)
+	for _, key := range keys {
+		cmd = append(cmd, streams[key])
+	}
+
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+	return parseStreamsResponse(b)
+}
+
+// XGroupCreate creates a consumer group that delivers the entries with IDs greater than the provided ID.
+//
+// Parameters:
+//
+// `key` - string - the key of the stream.
+//
+// `group` - string - the name of the consumer group.
+//
+// `id` - string - the ID of the last entry delivered to the group. "$" is the ID of the last entry in the stream.
+//
+// `options` - XGroupCreateOptions.
+//
+// Returns: true if the consumer group is created.
+//
+// Errors:
+//
+// "value at <key> is not a stream" - when the provided key exists but is not a stream.
+//
+// "BUSYGROUP Consumer Group name already exists" - when the consumer group already exists.
+//
+// "The XGROUP subcommand requires the key to exist" - when the stream does not exist and MkStream is false.
+func (server *SugarDB) XGroupCreate(key, group, id string, options XGroupCreateOptions) (bool, error) {
+	cmd := []string{"XGROUP", "CREATE", key, group, id}
+	if options.MkStream {
+		cmd = append(cmd, "MKSTREAM")
+	}
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return false, err
+	}
+	s, err := internal.ParseStringResponse(b)
+	return strings.EqualFold(s, "ok"), err
+}
+
+// XGroupDestroy removes the consumer group and its pending entries.
+//
+// Parameters:
+//
+// `key` - string - the key of the stream.
+//
+// `group` - string - the name of the consumer group.
+//
+// Returns: true if the consumer group is removed, false if it does not exist.
+//
+// Errors:
+//
+// "The XGROUP subcommand requires the key to exist" - when the stream does not exist.
+func (server *SugarDB) XGroupDestroy(key, group string) (bool, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"XGROUP", "DESTROY", key, group}), nil, false, true)
+	if err != nil {
+		return false, err
+	}
+	return internal.ParseBooleanResponse(b)
+}
+
+// XGroupSetID sets the ID of the last entry delivered to the consumer group.
+//
+// Parameters:
+//
+// `key` - string - the key of the stream.
+//
+// `group` - string - the name of the consumer group.
+//
+// `id` - string - the ID of the last entry delivered to the group. "$" is the ID of the last entry in the stream.
+//
+// Returns: true if the ID is set.
+//
+// Errors:
+//
+// "NOGROUP No such consumer group <group> for key name <key>" - when the consumer group does not exist.
+func (server *SugarDB) XGroupSetID(key, group, id string) (bool, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"XGROUP", "SETID", key, group, id}), nil, false, true)
+	if err != nil {
+		return false, err
+	}
+	s, err := internal.ParseStringResponse(b)
+	return strings.EqualFold(s, "ok"), err
+}
+
+// XGroupCreateConsumer creates a consumer in the consumer group.
+//
+// Parameters:
+//
+// `key` - string - the key of the stream.
+//
+// `group` - string - the name of the consumer group.
+//
+// `consumer` - string - the name of the consumer.
+//
+// Returns: true if the consumer is created, false if it already exists.
+//
+// Errors:
+//
+// "NOGROUP No such consumer group <group> for key name <key>" - when the consumer group does not exist.
+func (server *SugarDB) XGroupCreateConsumer(key, group, consumer string) (bool, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"XGROUP", "CREATECONSUMER", key, group, consumer}), nil, false, true)
+	if err != nil {
+		return false, err
+	}
+	return internal.ParseBooleanResponse(b)
+}
+
+// XGroupDelConsumer removes the consumer and its pending entries from the consumer group.
+//
+// Parameters:
+//
+// `key` - string - the key of the stream.
+//
+// `group` - string - the name of the consumer group.
+//
+// `consumer` - string - the name of the consumer.
+//
+// Returns: The number of pending entries the consumer had.
+//
+// Errors:
+//
+// "NOGROUP No such consumer group <group> for key name <key>" - when the consumer group does not exist.
+func (server *SugarDB) XGroupDelConsumer(key, group, consumer string) (int, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"XGROUP", "DELCONSUMER", key, group, consumer}), nil, false, true)
+	if err != nil {
+		return 0, err
+	}
+	return internal.ParseIntegerResponse(b)
+}
+
+// XReadGroup reads the entries of the streams as a consumer of the consumer group.
+//
+// Parameters:
+//
+// `group` - string - the name of the consumer group.
+//
+// `consumer` - string - the name of the consumer. The consumer is created if it does not exist.
+//
+// `streams` - map[string]string - the keys of the streams mapped to an ID. ">" delivers the entries that were not
+// delivered to the group yet and adds them to the pending entries of the consumer. Any other ID returns the pending
+// entries of the consumer with IDs greater than the ID.
+//
+// `options` - XReadGroupOptions.
+//
+// Returns: A map of the keys of the streams that have entries to their entries. Returns an empty map if there
+// are no new entries, or if the timeout expires when blocking.
+//
+// Errors:
+//
+// "NOGROUP No such key <key> or consumer group <group> in XREADGROUP with GROUP option" - when one of the streams
+// or its consumer group does not exist.
+func (server *SugarDB) XReadGroup(group, consumer string, streams map[string]string, options XReadGroupOptions) (map[string][]StreamEntry, error) {
+	cmd := []string{"XREADGROUP", "GROUP", group, consumer}
+	if options.Count > 0 {
+		cmd = append(cmd, "COUNT", strconv.FormatUint(uint64(options.Count), 10))
+	}
+	if options.Block {
+		cmd = append(cmd, "BLOCK", strconv.FormatInt(options.Timeout.Milliseconds(), 10))
+	}
+	if options.NoAck {
+		cmd = append(cmd, "NOACK")
+	}
+
+	cmd = append(cmd, "STREAMS")
+	keys := make([]string, 0, len(streams))
+	for key := range streams {
+		keys = append(keys, key)
+	}
+	cmd = append(cmd, keys...)
+	for _, key := range keys {
+		cmd = append(cmd, streams[key])
+	}
+
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+	return parseStreamsResponse(b)
+}
+
+// XAck removes the entries from the pending entries of the consumer group.
+//
+// Parameters:
+//
+// `key` - string - the key of the stream.
+//
+// `group` - string - the name of the consumer group.
+//
+// `ids` - ...string - the IDs of the entries to acknowledge.
+//
+// Returns: The number of entries acknowledged. Returns 0 if the stream or the consumer group does not exist.
+func (server *SugarDB) XAck(key, group string, ids ...string) (int, error) {
+	cmd := append([]string{"XACK", key, group}, ids...)
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return 0, err
+	}
+	return internal.ParseIntegerResponse(b)
+}
+
+// XPending returns a summary of the pending entries of the consumer group.
+//
+// Parameters:
+//
+// `key` - string - the key of the stream.
+//
+// `group` - string - the name of the consumer group.
+//
+// Returns: XPendingSummary.
+//
+// Errors:
+//
+// "NOGROUP No such key <key> or consumer group <group>" - when the stream or the consumer group does not exist.
+func (server *SugarDB) XPending(key, group string) (XPendingSummary, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"XPENDING", key, group}), nil, false, true)
+	if err != nil {
+		return XPendingSummary{}, err
+	}
+	v, _, err := resp.NewReader(bytes.NewReader(b)).ReadValue()
+	if err != nil {
+		return XPendingSummary{}, err
+	}
+
+	res := XPendingSummary{
+		Count:     v.Array()[0].Integer(),
+		First:     v.Array()[1].String(),
+		Last:      v.Array()[2].String(),
+		Consumers: make(map[string]int),
+	}
+	for _, consumer := range v.Array()[3].Array() {
+		res.Consumers[consumer.Array()[0].String()] = consumer.Array()[1].Integer()
+	}
+	return res, nil
+}
+
+// XPendingRange returns the pending entries of the consumer group.
+//
+// Parameters:
+//
+// `key` - string - the key of the stream.
+//
+// `group` - string - the name of the consumer group.
+//
+// `options` - XPendingOptions.
+//
+// Returns: The pending entries in the range, from the smallest ID to the largest.
+//
+// Errors:
+//
+// "NOGROUP No such key <key> or consumer group <group>" - when the stream or the consumer group does not exist.
+func (server *SugarDB) XPendingRange(key, group string, options XPendingOptions) ([]PendingEntry, error) {
+	cmd := []string{"XPENDING", key, group}
+	if options.Idle > 0 {
+		cmd = append(cmd, "IDLE", strconv.FormatInt(options.Idle.Milliseconds(), 10))
+	}
+	start, end := options.Start, options.End
+	if start == "" {
+		start = "-"
+	}
+	if end == "" {
+		end = "+"
+	}
+	cmd = append(cmd, start, end, strconv.FormatUint(uint64(options.Count), 10))
+	if options.Consumer != "" {
+		cmd = append(cmd, options.Consumer)
+	}
+
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+	v, _, err := resp.NewReader(bytes.NewReader(b)).ReadValue()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]PendingEntry, len(v.Array()))
+	for i, entry := range v.Array() {
+		res[i] = PendingEntry{
+			ID:            entry.Array()[0].String(),
+			Consumer:      entry.Array()[1].String(),
+			Idle:          time.Duration(entry.Array()[2].Integer()) * time.Millisecond,
+			DeliveryCount: entry.Array()[3].Integer(),
+		}
+	}
+	return res, nil
+}
+
+// XClaim transfers the pending entries with the IDs to the consumer.
+//
+// Parameters:
+//
+// `key` - string - the key of the stream.
+//
+// `group` - string - the name of the consumer group.
+//
+// `consumer` - string - the name of the consumer. The consumer is created if it does not exist.
+//
+// `minIdle` - time.Duration - the minimum time since the entries were last delivered.
+//
+// `ids` - []string - the IDs of the entries to claim.
+//
+// `options` - XClaimOptions.
+//
+// Returns: The claimed entries. Pending entries that were deleted from the stream are removed from the group
+// and not returned.
+//
+// Errors:
+//
+// "NOGROUP No such key <key> or consumer group <group>" - when the stream or the consumer group does not exist.
+func (server *SugarDB) XClaim(key, group, consumer string, minIdle time.Duration, ids []string, options XClaimOptions) ([]StreamEntry, error) {
+	cmd := append([]string{"XCLAIM", key, group, consumer, strconv.FormatInt(minIdle.Milliseconds(), 10)}, ids...)
+	if options.Idle > 0 {
+		cmd = append(cmd, "IDLE", strconv.FormatInt(options.Idle.Milliseconds(), 10))
+	} else if !options.Time.IsZero() {
+		cmd = append(cmd, "TIME", strconv.FormatInt(options.Time.UnixMilli(), 10))
+	}
+	if options.RetryCount > 0 {
+		cmd = append(cmd, "RETRYCOUNT", strconv.FormatUint(uint64(options.RetryCount), 10))
+	}
+	if options.Force {
+		cmd = append(cmd, "FORCE")
+	}
+	if options.JustID {
+		cmd = append(cmd, "JUSTID")
+	}
+	if options.LastID != "" {
+		cmd = append(cmd, "LASTID", options.LastID)
+	}
+
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+	v, _, err := resp.NewReader(bytes.NewReader(b)).ReadValue()
+	if err != nil {
+		return nil, err
+	}
+	return parseClaimedEntries(v, options.JustID), nil
+}
+
+// XAutoClaim transfers the pending entries with IDs greater than or equal to start to the consumer.
+//
+// Parameters:
+//
+// `key` - string - the key of the stream.
+//
+// `group` - string - the name of the consumer group.
+//
+// `consumer` - string - the name of the consumer. The consumer is created if it does not exist.
+//
+// `minIdle` - time.Duration - the minimum time since the entries were last delivered.
+//
+// `start` - string - the smallest ID of the pending entries to examine.
+//
+// `options` - XAutoClaimOptions.
+//
+// Returns: The ID to pass as start to the next call, or "0-0" if all the pending entries were examined.
+// The claimed entries. The IDs of the pending entries that were deleted from the stream and removed from the group.
+//
+// Errors:
+//
+// "NOGROUP No such key <key> or consumer group <group>" - when the stream or the consumer group does not exist.
+func (server *SugarDB) XAutoClaim(key, group, consumer string, minIdle time.Duration, start string, options XAutoClaimOptions) (string, []StreamEntry, []string, error) {
+	cmd := []string{"XAUTOCLAIM", key, group, consumer, strconv.FormatInt(minIdle.Milliseconds(), 10), start}
+	if options.Count > 0 {
+		cmd = append(cmd, "COUNT", strconv.FormatUint(uint64(options.Count), 10))
+	}
+	if options.JustID {
+		cmd = append(cmd, "JUSTID")
+	}
+
+	b, err := server.handleCommand(server.context, internal.EncodeCommand(cmd), nil, false, true)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	v, _, err := resp.NewReader(bytes.NewReader(b)).ReadValue()
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	deleted := make([]string, len(v.Array()[2].Array()))
+	for i, id := range v.Array()[2].Array() {
+		deleted[i] = id.String()
+	}
+	return v.Array()[0].String(), parseClaimedEntries(v.Array()[1], options.JustID), deleted, nil
+}
+
+// XInfoStream returns the state of the stream.
+//
+// Parameters:
+//
+// `key` - string - the key of the stream.
+//
+// Returns: StreamInfo.
+//
+// Errors:
+//
+// "no such key" - when the stream does not exist.
+//
+// "value at <key> is not a stream" - when the provided key exists but is not a stream.
+func (server *SugarDB) XInfoStream(key string) (StreamInfo, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"XINFO", "STREAM", key}), nil, false, true)
+	if err != nil {
+		return StreamInfo{}, err
+	}
+	v, _, err := resp.NewReader(bytes.NewReader(b)).ReadValue()
+	if err != nil {
+		return StreamInfo{}, err
+	}
+
+	fields := parseInfoFields(v)
+	info := StreamInfo{
+		Length:            fields["length"].Integer(),
+		LastGeneratedID:   fields["last-generated-id"].String(),
+		MaxDeletedEntryID: fields["max-deleted-entry-id"].String(),
+		EntriesAdded:      fields["entries-added"].Integer(),
+		Groups:            fields["groups"].Integer(),
+	}
+	if entry := fields["first-entry"]; !entry.IsNull() {
+		info.FirstEntry = &parseStreamEntries(resp.ArrayValue([]resp.Value{entry}))[0]
+	}
+	if entry := fields["last-entry"]; !entry.IsNull() {
+		info.LastEntry = &parseStreamEntries(resp.ArrayValue([]resp.Value{entry}))[0]
+	}
+	return info, nil
+}
+
+// XInfoGroups returns the state of the consumer groups of the stream.
+//
+// Parameters:
+//
+// `key` - string - the key of the stream.
+//
+// Returns: The consumer groups sorted by name.
+//
+// Errors:
+//
+// "no such key" - when the stream does not exist.
+//
+// "value at <key> is not a stream" - when the provided key exists but is not a stream.
+func (server *SugarDB) XInfoGroups(key string) ([]StreamGroupInfo, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"XINFO", "GROUPS", key}), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+	v, _, err := resp.NewReader(bytes.NewReader(b)).ReadValue()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]StreamGroupInfo, len(v.Array()))
+	for i, group := range v.Array() {
+		fields := parseInfoFields(group)
+		res[i] = StreamGroupInfo{
+			Name:            fields["name"].String(),
+			Consumers:       fields["consumers"].Integer(),
+			Pending:         fields["pending"].Integer(),
+			LastDeliveredID: fields["last-delivered-id"].String(),
+			EntriesRead:     fields["entries-read"].Integer(),
+			Lag:             fields["lag"].Integer(),
+		}
+	}
+	return res, nil
+}
+
+// XInfoConsumers returns the state of the consumers of the consumer group.
+//
+// Parameters:
+//
+// `key` - string - the key of the stream.
+//
+// `group` - string - the name of the consumer group.
+//
+// Returns: The consumers sorted by name.
+//
+// Errors:
+//
+// "no such key" - when the stream does not exist.
+//
+// "NOGROUP No such consumer group <group> for key name <key>" - when the consumer group does not exist.
+func (server *SugarDB) XInfoConsumers(key, group string) ([]StreamConsumerInfo, error) {
+	b, err := server.handleCommand(server.context, internal.EncodeCommand([]string{"XINFO", "CONSUMERS", key, group}), nil, false, true)
+	if err != nil {
+		return nil, err
+	}
+	v, _, err := resp.NewReader(bytes.NewReader(b)).ReadValue()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]StreamConsumerInfo, len(v.Array()))
+	for i, consumer := range v.Array() {
+		fields := parseInfoFields(consumer)
+		res[i] = StreamConsumerInfo{
+			Name:     fields["name"].String(),
+			Pending:  fields["pending"].Integer(),
+			Idle:     time.Duration(fields["idle"].Integer()) * time.Millisecond,
+			Inactive: time.Duration(fields["inactive"].Integer()) * time.Millisecond,
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,376 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sugardb
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSugarDB_XAdd(t *testing.T) {
+	t.Parallel()
+
+	server := createSugarDB()
+
+	_, _, _ = server.Set("XAddKey3", "value", SETOptions{})
+
+	tests := []struct {
+		name    string
+		key     string
+		fields  map[string]string
+		options XAddOptions
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "1. Generate the ID from the current time",
+			key:    "XAddKey1",
+			fields: map[string]string{"b": "2", "a": "1"},
+			want:   "1136189045000-0",
+		},
+		{
+			name:    "2. Add an entry with an explicit ID",
+			key:     "XAddKey1",
+			fields:  map[string]string{"a": "1"},
+			options: XAddOptions{ID: "1136189045001-5"},
+			want:    "1136189045001-5",
+		},
+		{
+			name:    "3. Return error when the ID is not greater than the last ID",
+			key:     "XAddKey1",
+			fields:  map[string]string{"a": "1"},
+			options: XAddOptions{ID: "1-1"},
+			wantErr: true,
+		},
+		{
+			name:    "4. Don't create the stream with NoMkStream",
+			key:     "XAddKey2",
+			fields:  map[string]string{"a": "1"},
+			options: XAddOptions{NoMkStream: true},
+			want:    "",
+		},
+		{
+			name:    "5. Return error when the key is not a stream",
+			key:     "XAddKey3",
+			fields:  map[string]string{"a": "1"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := server.XAdd(tt.key, tt.fields, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("XAdd() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("XAdd() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSugarDB_XRange(t *testing.T) {
+	t.Parallel()
+
+	server := createSugarDB()
+
+	for _, id := range []string{"1-1", "2-1", "3-1"} {
+		if _, err := server.XAdd("XRangeKey1", map[string]string{"id": id}, XAddOptions{ID: id}); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	entry := func(id string) StreamEntry {
+		return StreamEntry{ID: id, Fields: map[string]string{"id": id}}
+	}
+
+	t.Run("XRange", func(t *testing.T) {
+		got, err := server.XRange("XRangeKey1", "(1-1", "+", 0)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if want := []StreamEntry{entry("2-1"), entry("3-1")}; !reflect.DeepEqual(got, want) {
+			t.Errorf("XRange() got = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("XRevRange", func(t *testing.T) {
+		got, err := server.XRevRange("XRangeKey1", "+", "-", 2)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if want := []StreamEntry{entry("3-1"), entry("2-1")}; !reflect.DeepEqual(got, want) {
+			t.Errorf("XRevRange() got = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("XRange on a missing key", func(t *testing.T) {
+		got, err := server.XRange("XRangeKey2", "-", "+", 0)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(got) != 0 {
+			t.Errorf("XRange() got = %v, want empty slice", got)
+		}
+	})
+}
+
+func TestSugarDB_XDel_XTrim(t *testing.T) {
+	t.Parallel()
+
+	server := createSugarDB()
+
+	for _, id := range []string{"1-1", "2-1", "3-1", "4-1", "5-1"} {
+		if _, err := server.XAdd("XTrimKey1", map[string]string{"a": "1"}, XAddOptions{ID: id}); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	steps := []struct {
+		name string
+		fn   func() (int, error)
+		want int
+	}{
+		{name: "XDel", fn: func() (int, error) { return server.XDel("XTrimKey1", "5-1", "6-1") }, want: 1},
+		{
+			name: "XTrim MINID",
+			fn: func() (int, error) {
+				return server.XTrim("XTrimKey1", XTrimOptions{Strategy: "MINID", Threshold: "2-1"})
+			},
+			want: 1,
+		},
+		{
+			name: "XTrim MAXLEN with LIMIT",
+			fn: func() (int, error) {
+				return server.XTrim("XTrimKey1", XTrimOptions{Strategy: "MAXLEN", Threshold: "0", Approximate: true, Limit: 2})
+			},
+			want: 2,
+		},
+		{name: "XLen", fn: func() (int, error) { return server.XLen("XTrimKey1") }, want: 1},
+	}
+
+	for _, step := range steps {
+		got, err := step.fn()
+		if err != nil {
+			t.Errorf("%s: %v", step.name, err)
+			continue
+		}
+		if got != step.want {
+			t.Errorf("%s: got = %d, want %d", step.name, got, step.want)
+		}
+	}
+}
+
+func TestSugarDB_XRead(t *testing.T) {
+	t.Parallel()
+
+	server := createSugarDB()
+
+	if _, err := server.XAdd("XReadKey1", map[string]string{"a": "1"}, XAddOptions{ID: "1-1"}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Run("1. Read the entries after the ID", func(t *testing.T) {
+		got, err := server.XRead(map[string]string{"XReadKey1": "0", "XReadKey2": "0"}, XReadOptions{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		want := map[string][]StreamEntry{
+			"XReadKey1": {{ID: "1-1", Fields: map[string]string{"a": "1"}}},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("XRead() got = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("2. Return an empty map when the timeout expires", func(t *testing.T) {
+		got, err := server.XRead(map[string]string{"XReadKey1": "$"}, XReadOptions{Block: true, Timeout: 50 * time.Millisecond})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(got) != 0 {
+			t.Errorf("XRead() got = %v, want empty map", got)
+		}
+	})
+
+	t.Run("3. Block until an entry is added", func(t *testing.T) {
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_, _ = server.XAdd("XReadKey3", map[string]string{"b": "2"}, XAddOptions{ID: "2-1"})
+		}()
+		got, err := server.XRead(map[string]string{"XReadKey3": "$"}, XReadOptions{Block: true})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		want := map[string][]StreamEntry{
+			"XReadKey3": {{ID: "2-1", Fields: map[string]string{"b": "2"}}},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("XRead() got = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestSugarDB_XGroup(t *testing.T) {
+	t.Parallel()
+
+	server := createSugarDB()
+
+	for _, id := range []string{"1-1", "2-1", "3-1"} {
+		if _, err := server.XAdd("XGroupKey1", map[string]string{"id": id}, XAddOptions{ID: id}); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	t.Run("1. Create consumer groups and consumers", func(t *testing.T) {
+		if ok, err := server.XGroupCreate("XGroupKey1", "g1", "0", XGroupCreateOptions{}); err != nil || !ok {
+			t.Errorf("XGroupCreate() got = %v, %v, want true", ok, err)
+		}
+		if _, err := server.XGroupCreate("XGroupKey1", "g1", "0", XGroupCreateOptions{}); err == nil {
+			t.Error("XGroupCreate() expected BUSYGROUP error")
+		}
+		if _, err := server.XGroupCreate("XGroupKey2", "g1", "$", XGroupCreateOptions{}); err == nil {
+			t.Error("XGroupCreate() expected error when the stream does not exist")
+		}
+		if ok, err := server.XGroupCreate("XGroupKey2", "g1", "$", XGroupCreateOptions{MkStream: true}); err != nil || !ok {
+			t.Errorf("XGroupCreate() with MkStream got = %v, %v, want true", ok, err)
+		}
+		if ok, err := server.XGroupCreateConsumer("XGroupKey2", "g1", "alice"); err != nil || !ok {
+			t.Errorf("XGroupCreateConsumer() got = %v, %v, want true", ok, err)
+		}
+		if ok, err := server.XGroupSetID("XGroupKey2", "g1", "0"); err != nil || !ok {
+			t.Errorf("XGroupSetID() got = %v, %v, want true", ok, err)
+		}
+		if count, err := server.XGroupDelConsumer("XGroupKey2", "g1", "alice"); err != nil || count != 0 {
+			t.Errorf("XGroupDelConsumer() got = %v, %v, want 0", count, err)
+		}
+		if ok, err := server.XGroupDestroy("XGroupKey2", "g1"); err != nil || !ok {
+			t.Errorf("XGroupDestroy() got = %v, %v, want true", ok, err)
+		}
+	})
+
+	t.Run("2. Read, acknowledge and claim the entries of a consumer group", func(t *testing.T) {
+		entry := func(id string) StreamEntry {
+			return StreamEntry{ID: id, Fields: map[string]string{"id": id}}
+		}
+
+		got, err := server.XReadGroup("g1", "alice", map[string]string{"XGroupKey1": ">"}, XReadGroupOptions{Count: 2})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if want := map[string][]StreamEntry{"XGroupKey1": {entry("1-1"), entry("2-1")}}; !reflect.DeepEqual(got, want) {
+			t.Errorf("XReadGroup() got = %v, want %v", got, want)
+		}
+
+		summary, err := server.XPending("XGroupKey1", "g1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if want := (XPendingSummary{Count: 2, First: "1-1", Last: "2-1", Consumers: map[string]int{"alice": 2}}); !reflect.DeepEqual(summary, want) {
+			t.Errorf("XPending() got = %v, want %v", summary, want)
+		}
+
+		if count, err := server.XAck("XGroupKey1", "g1", "1-1"); err != nil || count != 1 {
+			t.Errorf("XAck() got = %v, %v, want 1", count, err)
+		}
+
+		claimed, err := server.XClaim("XGroupKey1", "g1", "bob", 0, []string{"2-1"}, XClaimOptions{Idle: 5 * time.Second})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if want := []StreamEntry{entry("2-1")}; !reflect.DeepEqual(claimed, want) {
+			t.Errorf("XClaim() got = %v, want %v", claimed, want)
+		}
+
+		pending, err := server.XPendingRange("XGroupKey1", "g1", XPendingOptions{Idle: time.Second, Count: 10})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if want := []PendingEntry{{ID: "2-1", Consumer: "bob", Idle: 5 * time.Second, DeliveryCount: 2}}; !reflect.DeepEqual(pending, want) {
+			t.Errorf("XPendingRange() got = %v, want %v", pending, want)
+		}
+
+		next, claimed, deleted, err := server.XAutoClaim("XGroupKey1", "g1", "carol", time.Second, "0", XAutoClaimOptions{JustID: true})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if next != "0-0" || !reflect.DeepEqual(claimed, []StreamEntry{{ID: "2-1"}}) || len(deleted) != 0 {
+			t.Errorf("XAutoClaim() got = %v, %v, %v", next, claimed, deleted)
+		}
+	})
+
+	t.Run("3. Return the state of the stream, its groups and consumers", func(t *testing.T) {
+		info, err := server.XInfoStream("XGroupKey1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		first, last := StreamEntry{ID: "1-1", Fields: map[string]string{"id": "1-1"}}, StreamEntry{ID: "3-1", Fields: map[string]string{"id": "3-1"}}
+		want := StreamInfo{
+			Length:            3,
+			LastGeneratedID:   "3-1",
+			MaxDeletedEntryID: "0-0",
+			EntriesAdded:      3,
+			Groups:            1,
+			FirstEntry:        &first,
+			LastEntry:         &last,
+		}
+		if !reflect.DeepEqual(info, want) {
+			t.Errorf("XInfoStream() got = %+v, want %+v", info, want)
+		}
+
+		groups, err := server.XInfoGroups("XGroupKey1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		wantGroups := []StreamGroupInfo{{Name: "g1", Consumers: 3, Pending: 1, LastDeliveredID: "2-1", EntriesRead: 2, Lag: 1}}
+		if !reflect.DeepEqual(groups, wantGroups) {
+			t.Errorf("XInfoGroups() got = %+v, want %+v", groups, wantGroups)
+		}
+
+		consumers, err := server.XInfoConsumers("XGroupKey1", "g1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		wantConsumers := []StreamConsumerInfo{
+			{Name: "alice", Pending: 0},
+			{Name: "bob", Pending: 0},
+			{Name: "carol", Pending: 1},
+		}
+		if !reflect.DeepEqual(consumers, wantConsumers) {
+			t.Errorf("XInfoConsumers() got = %+v, want %+v", consumers, wantConsumers)
+		}
+	})
+}
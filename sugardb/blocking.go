@@ -0,0 +1,114 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sugardb
+
+import (
+	"context"
+	"github.com/echovault/sugardb/internal"
+	"slices"
+)
+
+// blockOnKeys registers the caller to be notified when any of the keys in the database from the context is written.
+// The returned channel receives a value after the next write to one of the keys. The returned function must be
+// called to stop receiving notifications.
+// Blocking commands register before checking the keys so that a write between the check and the wait is not missed.
+// Inside a transaction or a script the store lock is held, so a nil channel is returned and the command must not block.
+// The same applies to commands replayed from the AOF or applied from the raft log.
+func (server *SugarDB) blockOnKeys(ctx context.Context, keys []string) (<-chan struct{}, func()) {
+	if noBlock, _ := ctx.Value(internal.ContextNoBlock("NoBlock")).(bool); noBlock || inTransaction(ctx) {
+		return nil, func() {}
+	}
+
+	database := ctx.Value("Database").(int)
+	ready := make(chan struct{}, 1)
+	keys = slices.Clone(keys)
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	server.blockedClients.mut.Lock()
+	defer server.blockedClients.mut.Unlock()
+
+	if server.blockedClients.waiters[database] == nil {
+		server.blockedClients.waiters[database] = make(map[string][]chan struct{})
+	}
+	for _, key := range keys {
+		// The waiters of each key are kept in the order they blocked.
+		server.blockedClients.waiters[database][key] = append(server.blockedClients.waiters[database][key], ready)
+	}
+
+	return ready, func() {
+		server.blockedClients.mut.Lock()
+		defer server.blockedClients.mut.Unlock()
+		for _, key := range keys {
+			waiters := server.blockedClients.waiters[database][key]
+			i := slices.Index(waiters, ready)
+			if i < 0 {
+				continue
+			}
+			waiters = slices.Delete(waiters, i, i+1)
+			if len(waiters) == 0 {
+				delete(server.blockedClients.waiters[database], key)
+				continue
+			}
+			server.blockedClients.waiters[database][key] = waiters
+			if i == 0 {
+				// The next client in line might be able to consume what this client left on the key.
+				notify(waiters)
+			}
+		}
+	}
+}
+
+// isFirstBlocked returns true if no other client has been blocked on the key for longer than the client that
+// received the ready channel from blockOnKeys.
+func (server *SugarDB) isFirstBlocked(ctx context.Context, key string, ready <-chan struct{}) bool {
+	database := ctx.Value("Database").(int)
+
+	server.blockedClients.mut.Lock()
+	defer server.blockedClients.mut.Unlock()
+
+	waiters := server.blockedClients.waiters[database][key]
+	return len(waiters) > 0 && waiters[0] == ready
+}
+
+// signalKeys notifies the clients blocked on the keys that the keys were written.
+func (server *SugarDB) signalKeys(database int, keys []string) {
+	server.blockedClients.mut.Lock()
+	defer server.blockedClients.mut.Unlock()
+	for _, key := range keys {
+		notify(server.blockedClients.waiters[database][key])
+	}
+}
+
+func notify(waiters []chan struct{}) {
+	for _, ready := range waiters {
+		select {
+		case ready <- struct{}{}:
+		default:
+			// The client already has a pending notification.
+		}
+	}
+}
+
+// withCallerContext returns a context with the values of the server context that is cancelled when the context
+// passed to a blocking method of the embedded API is cancelled. The returned function must be called to release it.
+func (server *SugarDB) withCallerContext(ctx context.Context) (context.Context, func()) {
+	serverCtx, cancel := context.WithCancel(server.context)
+	stop := context.AfterFunc(ctx, cancel)
+	return serverCtx, func() {
+		stop()
+		cancel()
+	}
+}
@@ -0,0 +1,379 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sugardb
+
+import (
+	"context"
+	"errors"
+	"github.com/echovault/sugardb/internal"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The reply modes set with CLIENT REPLY.
+const (
+	replyOn       = iota // Every command is replied to.
+	replyOff             // No command is replied to.
+	replySkipNext        // CLIENT REPLY SKIP was called. Neither it nor the next command is replied to.
+	replySkip            // The current command is not replied to.
+)
+
+// connectionState holds the runtime state of a TCP connection.
+type connectionState struct {
+	writer          sync.Mutex   // Held while writing to the connection.
+	cancel          func()       // Cancels the context of the connection's commands when the connection is killed.
+	addr            string       // The address of the client.
+	localAddr       string       // The address of the server side of the connection.
+	createdAt       time.Time    // The time the connection was established.
+	lastInteraction atomic.Int64 // The time of the last command in unix milliseconds.
+	command         atomic.Value // The name of the last command.
+	queryBuffer     atomic.Int64 // The size of the command that is being executed.
+	outputBuffer    atomic.Int64 // The size of the response that is being written.
+	noEvict         atomic.Bool  // The flag set with CLIENT NO-EVICT.
+	reply           atomic.Int32 // The reply mode set with CLIENT REPLY.
+}
+
+func newConnectionState(conn *net.Conn, now time.Time, cancel func()) *connectionState {
+	state := &connectionState{
+		cancel:    cancel,
+		addr:      (*conn).RemoteAddr().String(),
+		localAddr: (*conn).LocalAddr().String(),
+		createdAt: now,
+	}
+	state.lastInteraction.Store(now.UnixMilli())
+	state.command.Store("NULL")
+	return state
+}
+
+// replyEnabled returns true if the response to the current command must be written to the client.
+// It's called once after every command.
+func (state *connectionState) replyEnabled() bool {
+	switch state.reply.Load() {
+	case replyOff:
+		return false
+	case replySkipNext:
+		state.reply.Store(replySkip)
+		return false
+	case replySkip:
+		state.reply.Store(replyOn)
+		return false
+	default:
+		return true
+	}
+}
+
+// client is a TCP client with its information.
+type client struct {
+	conn  *net.Conn
+	state *connectionState
+	info  internal.ClientInfo
+}
+
+// getConnection returns the TCP connection with the id, or nil if there is none.
+func (server *SugarDB) getConnection(id uint64) *net.Conn {
+	server.connInfo.mut.RLock()
+	defer server.connInfo.mut.RUnlock()
+	for conn, info := range server.connInfo.tcpClients {
+		if info.Id == id {
+			return conn
+		}
+	}
+	return nil
+}
+
+// getConnectionInfo returns the information of the TCP connection.
+func (server *SugarDB) getConnectionInfo(conn *net.Conn) internal.ConnectionInfo {
+	server.connInfo.mut.RLock()
+	defer server.connInfo.mut.RUnlock()
+	return server.connInfo.tcpClients[conn]
+}
+
+// getConnectionState returns the runtime state of the TCP connection, or nil if the connection is closed.
+func (server *SugarDB) getConnectionState(conn *net.Conn) *connectionState {
+	server.connInfo.mut.RLock()
+	defer server.connInfo.mut.RUnlock()
+	return server.connInfo.states[conn]
+}
+
+// setClientCommand records the command that the TCP connection is executing.
+func (server *SugarDB) setClientCommand(conn *net.Conn, command internal.Command, subCommand internal.SubCommand) {
+	state := server.getConnectionState(conn)
+	if state == nil {
+		return
+	}
+	state.command.Store(commandName(command, subCommand))
+}
+
+// getClients returns the TCP clients ordered by id.
+func (server *SugarDB) getClients() []client {
+	now := server.clock.Now()
+
+	server.connInfo.mut.RLock()
+	clients := make([]client, 0, len(server.connInfo.tcpClients))
+	for conn, info := range server.connInfo.tcpClients {
+		state := server.connInfo.states[conn]
+		if state == nil {
+			continue
+		}
+		clients = append(clients, client{
+			conn:  conn,
+			state: state,
+			info: internal.ClientInfo{
+				Id:           info.Id,
+				Addr:         state.addr,
+				LocalAddr:    state.localAddr,
+				Name:         info.Name,
+				Age:          int64(now.Sub(state.createdAt).Seconds()),
+				Idle:         (now.UnixMilli() - state.lastInteraction.Load()) / 1000,
+				Database:     info.Database,
+				Multi:        -1,
+				QueryBuffer:  int(state.queryBuffer.Load()),
+				OutputBuffer: int(state.outputBuffer.Load()),
+				Protocol:     info.Protocol,
+				Command:      state.command.Load().(string),
+			},
+		})
+	}
+	server.connInfo.mut.RUnlock()
+
+	slices.SortFunc(clients, func(a, b client) int {
+		return int(a.info.Id) - int(b.info.Id)
+	})
+
+	if server.acl != nil {
+		server.acl.RLockUsers()
+		for i := range clients {
+			if connection, ok := server.acl.Connections[clients[i].conn]; ok && connection.User != nil {
+				clients[i].info.User = connection.User.Username
+			}
+		}
+		server.acl.RUnlockUsers()
+	}
+
+	server.transactions.mut.Lock()
+	for i := range clients {
+		if tx, ok := server.transactions.clients[clients[i].conn]; ok {
+			clients[i].info.Multi = len(tx.commands)
+		}
+	}
+	server.transactions.mut.Unlock()
+
+	for _, channel := range server.pubSub.GetAllChannels() {
+		subscribers := channel.Subscribers()
+		for i := range clients {
+			if _, ok := subscribers[clients[i].conn]; !ok {
+				continue
+			}
+			if channel.Pattern() != nil {
+				clients[i].info.PatternSubscriptions++
+			} else {
+				clients[i].info.Subscriptions++
+			}
+		}
+	}
+
+	server.tracking.mut.Lock()
+	tracking := make([]bool, len(clients))
+	for i := range clients {
+		_, tracking[i] = server.tracking.clients[clients[i].conn]
+	}
+	server.tracking.mut.Unlock()
+
+	for i := range clients {
+		flags := ""
+		if clients[i].info.Multi >= 0 {
+			flags += "x"
+		}
+		if clients[i].info.Subscriptions+clients[i].info.PatternSubscriptions > 0 {
+			flags += "P"
+		}
+		if tracking[i] {
+			flags += "t"
+		}
+		if clients[i].state.noEvict.Load() {
+			flags += "e"
+		}
+		if server.isMonitoring(clients[i].conn) {
+			flags += "O"
+		}
+		if flags == "" {
+			flags = "N"
+		}
+		clients[i].info.Flags = flags
+	}
+
+	return clients
+}
+
+func (server *SugarDB) listClients() []internal.ClientInfo {
+	clients := server.getClients()
+	infos := make([]internal.ClientInfo, len(clients))
+	for i, c := range clients {
+		infos[i] = c.info
+	}
+	return infos
+}
+
+func (server *SugarDB) killClients(conn *net.Conn, filter internal.ClientKillFilter) int {
+	self := server.getConnectionInfo(conn).Id
+	killed := 0
+	for _, c := range server.getClients() {
+		subscriber := c.info.Subscriptions+c.info.PatternSubscriptions > 0
+		switch {
+		case filter.SkipMe && conn != nil && c.info.Id == self:
+			continue
+		case filter.Id != 0 && c.info.Id != filter.Id:
+			continue
+		case filter.Addr != "" && c.info.Addr != filter.Addr:
+			continue
+		case filter.LocalAddr != "" && c.info.LocalAddr != filter.LocalAddr:
+			continue
+		case filter.User != "" && c.info.User != filter.User:
+			continue
+		case filter.Type == "normal" && subscriber, filter.Type == "pubsub" && !subscriber:
+			continue
+		case filter.Type == "master" || filter.Type == "replica":
+			// Replication is done with raft, so none of the clients is a master or a replica.
+			continue
+		}
+		// Unblock the command that the client is waiting on, and close the connection once the current command returns.
+		c.state.cancel()
+		_ = (*c.conn).SetReadDeadline(time.Now().Add(-1 * time.Second))
+		killed++
+	}
+	return killed
+}
+
+func (server *SugarDB) setClientName(conn *net.Conn, name string) {
+	server.connInfo.mut.Lock()
+	defer server.connInfo.mut.Unlock()
+
+	if conn == nil {
+		server.connInfo.embedded.Name = name
+		return
+	}
+	info := server.connInfo.tcpClients[conn]
+	info.Name = name
+	server.connInfo.tcpClients[conn] = info
+}
+
+func (server *SugarDB) setClientNoEvict(conn *net.Conn, noEvict bool) error {
+	state := server.getConnectionState(conn)
+	if state == nil {
+		return errors.New("CLIENT NO-EVICT is not supported by the embedded connection")
+	}
+	state.noEvict.Store(noEvict)
+	return nil
+}
+
+func (server *SugarDB) setClientReply(conn *net.Conn, mode string) error {
+	state := server.getConnectionState(conn)
+	if state == nil {
+		return errors.New("CLIENT REPLY is not supported by the embedded connection")
+	}
+	switch mode {
+	case "on":
+		state.reply.Store(replyOn)
+	case "off":
+		state.reply.Store(replyOff)
+	case "skip":
+		state.reply.Store(replySkipNext)
+	default:
+		return errors.New("syntax error")
+	}
+	return nil
+}
+
+// pauseClients suspends the commands of the TCP clients until the timeout elapses or the clients are unpaused.
+// A new pause replaces the one in progress.
+func (server *SugarDB) pauseClients(timeout time.Duration, writeOnly bool) {
+	server.pause.mut.Lock()
+	defer server.pause.mut.Unlock()
+
+	previous := server.pause.done
+	done := make(chan struct{})
+	server.pause.done = done
+	server.pause.writeOnly = writeOnly
+	if previous != nil {
+		close(previous)
+	}
+
+	go func() {
+		select {
+		case <-server.clock.After(timeout):
+			server.pause.mut.Lock()
+			defer server.pause.mut.Unlock()
+			if server.pause.done == done {
+				close(done)
+				server.pause.done = nil
+			}
+		case <-done:
+		}
+	}()
+}
+
+func (server *SugarDB) unpauseClients() {
+	server.pause.mut.Lock()
+	defer server.pause.mut.Unlock()
+	if server.pause.done != nil {
+		close(server.pause.done)
+		server.pause.done = nil
+	}
+}
+
+// waitForPause blocks the command while the clients are paused. Only the commands that write to the keyspace are
+// blocked when the clients are paused with WRITE. CLIENT commands are never blocked so that the clients can be
+// unpaused. An error is returned when the context is cancelled because the connection is killed.
+func (server *SugarDB) waitForPause(ctx context.Context, conn *net.Conn, command internal.Command, subCommand internal.SubCommand) error {
+	if strings.EqualFold(command.Command, "client") {
+		return nil
+	}
+
+	write := internal.IsWriteCommand(command, subCommand)
+	if strings.EqualFold(command.Command, "exec") {
+		// EXEC is a write command when the transaction contains a write command.
+		if tx := server.getTransaction(conn); tx != nil {
+			for _, cmd := range tx.commands {
+				c, err := server.getCommand(cmd[0])
+				if err != nil {
+					continue
+				}
+				sc, _ := internal.GetSubCommand(c, cmd)
+				s, _ := sc.(internal.SubCommand)
+				write = write || internal.IsWriteCommand(c, s)
+			}
+		}
+	}
+
+	for {
+		server.pause.mut.Lock()
+		done, writeOnly := server.pause.done, server.pause.writeOnly
+		server.pause.mut.Unlock()
+
+		if done == nil || (writeOnly && !write) {
+			return nil
+		}
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/echovault/sugardb/internal"
+	"slices"
 	"time"
 )
 
@@ -30,6 +31,7 @@ func (server *SugarDB) raftApplyDeleteKey(ctx context.Context, key string) error
 	serverId, _ := ctx.Value(internal.ContextServerID("ServerID")).(string)
 	protocol, _ := ctx.Value("Protocol").(int)
 	database, _ := ctx.Value("Database").(int)
+	event, _ := ctx.Value(internal.ContextKeyEvent("KeyEvent")).(string)
 
 	deleteKeyRequest := internal.ApplyRequest{
 		Type:         "delete-key",
@@ -38,6 +40,7 @@ func (server *SugarDB) raftApplyDeleteKey(ctx context.Context, key string) error
 		Protocol:     protocol,
 		Database:     database,
 		Key:          key,
+		Event:        event,
 	}
 
 	b, err := json.Marshal(deleteKeyRequest)
@@ -65,6 +68,11 @@ func (server *SugarDB) raftApplyDeleteKey(ctx context.Context, key string) error
 }
 
 func (server *SugarDB) raftApplyCommand(ctx context.Context, cmd []string) ([]byte, error) {
+	cmd, err := server.evalShaAsEval(cmd)
+	if err != nil {
+		return nil, err
+	}
+
 	serverId, _ := ctx.Value(internal.ContextServerID("ServerID")).(string)
 	connectionId, _ := ctx.Value(internal.ContextConnID("ConnectionID")).(string)
 	protocol, _ := ctx.Value("Protocol").(int)
@@ -77,6 +85,7 @@ func (server *SugarDB) raftApplyCommand(ctx context.Context, cmd []string) ([]by
 		Protocol:     protocol,
 		Database:     database,
 		CMD:          cmd,
+		Time:         server.clock.Now().UnixNano(),
 	}
 
 	b, err := json.Marshal(applyRequest)
@@ -102,3 +111,72 @@ func (server *SugarDB) raftApplyCommand(ctx context.Context, cmd []string) ([]by
 
 	return r.Response, nil
 }
+
+// raftApplyContext returns a context that lets a blocking command waiting on the leader apply itself through
+// the raft log. The command is applied without blocking, so a null reply means that it could not make progress.
+func (server *SugarDB) raftApplyContext(ctx context.Context, cmd []string) context.Context {
+	return context.WithValue(ctx, internal.ContextRaftApply("RaftApply"), func() ([]byte, error) {
+		res, err := server.raftApplyCommand(ctx, cmd)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains([]string{"*-1\r\n", "$-1\r\n"}, string(res)) {
+			return nil, nil
+		}
+		return res, nil
+	})
+}
+
+func (server *SugarDB) raftApplyTransaction(ctx context.Context, cmds [][]string, watched []watchedKey) ([]byte, error) {
+	serverId, _ := ctx.Value(internal.ContextServerID("ServerID")).(string)
+	connectionId, _ := ctx.Value(internal.ContextConnID("ConnectionID")).(string)
+	protocol, _ := ctx.Value("Protocol").(int)
+	database, _ := ctx.Value("Database").(int)
+
+	// A queued EVALSHA whose script is not cached is kept, so that it fails in its position in the response.
+	cmds = slices.Clone(cmds)
+	for i, cmd := range cmds {
+		if eval, err := server.evalShaAsEval(cmd); err == nil {
+			cmds[i] = eval
+		}
+	}
+
+	keys := make([]internal.WatchedKey, len(watched))
+	for i, w := range watched {
+		keys[i] = internal.WatchedKey{Database: w.database, Key: w.key, Version: w.version, Deleted: w.deleted}
+	}
+
+	applyRequest := internal.ApplyRequest{
+		Type:         "transaction",
+		ServerID:     serverId,
+		ConnectionID: connectionId,
+		Protocol:     protocol,
+		Database:     database,
+		Transaction:  cmds,
+		Watched:      keys,
+		Time:         server.clock.Now().UnixNano(),
+	}
+
+	b, err := json.Marshal(applyRequest)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse transaction request for commands: %+v", cmds)
+	}
+
+	applyFuture := server.raft.Apply(b, 500*time.Millisecond)
+
+	if err = applyFuture.Error(); err != nil {
+		return nil, err
+	}
+
+	r, ok := applyFuture.Response().(internal.ApplyResponse)
+
+	if !ok {
+		return nil, fmt.Errorf("unprocessable entity %v", r)
+	}
+
+	if r.Error != nil {
+		return nil, r.Error
+	}
+
+	return r.Response, nil
+}
@@ -49,7 +49,7 @@ func (server *SugarDB) GetServerInfo() internal.ServerInfo {
 		}(),
 		Modules:    server.ListModules(),
 		MemoryUsed: server.memUsed,
-		MaxMemory:  server.config.MaxMemory,
+		MaxMemory:  server.getConfig().MaxMemory,
 	}
 }
 
@@ -314,6 +314,61 @@ func WithDiscoveryPort(discoveryPort uint16) func(sugardb *SugarDB) {
 	}
 }
 
+// WithMetricsPort is an option to the NewSugarDB function that allows you to pass a
+// custom MetricsPort to SugarDB. When the port is not 0, Start also starts an HTTP listener on the port
+// that serves the metrics in the Prometheus text format at /metrics.
+// If not specified, SugarDB will use the default configuration from config.DefaultConfig().
+func WithMetricsPort(metricsPort uint16) func(sugardb *SugarDB) {
+	return func(sugardb *SugarDB) {
+		sugardb.config.MetricsPort = metricsPort
+	}
+}
+
+// WithNotifyKeyspaceEvents is an option to the NewSugarDB function that allows you to pass a
+// custom NotifyKeyspaceEvents to SugarDB.
+// If not specified, SugarDB will use the default configuration from config.DefaultConfig().
+func WithNotifyKeyspaceEvents(notifyKeyspaceEvents string) func(sugardb *SugarDB) {
+	return func(sugardb *SugarDB) {
+		sugardb.config.NotifyKeyspaceEvents = notifyKeyspaceEvents
+	}
+}
+
+// WithSlowlogLogSlowerThan is an option to the NewSugarDB function that allows you to pass a
+// custom SlowlogLogSlowerThan to SugarDB.
+// If not specified, SugarDB will use the default configuration from config.DefaultConfig().
+func WithSlowlogLogSlowerThan(slowlogLogSlowerThan int64) func(sugardb *SugarDB) {
+	return func(sugardb *SugarDB) {
+		sugardb.config.SlowlogLogSlowerThan = slowlogLogSlowerThan
+	}
+}
+
+// WithSlowlogMaxLen is an option to the NewSugarDB function that allows you to pass a
+// custom SlowlogMaxLen to SugarDB.
+// If not specified, SugarDB will use the default configuration from config.DefaultConfig().
+func WithSlowlogMaxLen(slowlogMaxLen uint) func(sugardb *SugarDB) {
+	return func(sugardb *SugarDB) {
+		sugardb.config.SlowlogMaxLen = slowlogMaxLen
+	}
+}
+
+// WithLatencyMonitorThreshold is an option to the NewSugarDB function that allows you to pass a
+// custom LatencyMonitorThreshold to SugarDB.
+// If not specified, SugarDB will use the default configuration from config.DefaultConfig().
+func WithLatencyMonitorThreshold(latencyMonitorThreshold uint64) func(sugardb *SugarDB) {
+	return func(sugardb *SugarDB) {
+		sugardb.config.LatencyMonitorThreshold = latencyMonitorThreshold
+	}
+}
+
+// WithLuaTimeLimit is an option to the NewSugarDB function that allows you to pass a
+// custom LuaTimeLimit to SugarDB.
+// If not specified, SugarDB will use the default configuration from config.DefaultConfig().
+func WithLuaTimeLimit(luaTimeLimit uint64) func(sugardb *SugarDB) {
+	return func(sugardb *SugarDB) {
+		sugardb.config.LuaTimeLimit = luaTimeLimit
+	}
+}
+
 // WithRaftBindAddr is an option to the NewSugarDB function that allows you to pass a
 // custom RaftBindAddr to SugarDB.
 // If not specified, SugarDB will use the default configuration from config.DefaultConfig().
@@ -331,3 +386,87 @@ func WithRaftBindPort(raftBindPort uint16) func(sugardb *SugarDB) {
 		sugardb.config.RaftBindPort = raftBindPort
 	}
 }
+
+// WithConfigFile is an option to the NewSugarDB function that allows you to pass a
+// custom ConfigFile to SugarDB. CONFIG REWRITE persists the runtime configuration changes to this file.
+// If not specified, SugarDB will use the default configuration from config.DefaultConfig().
+func WithConfigFile(configFile string) func(sugardb *SugarDB) {
+	return func(sugardb *SugarDB) {
+		sugardb.config.ConfigFile = configFile
+	}
+}
+
+// getConfig returns a copy of the configuration.
+func (server *SugarDB) getConfig() config.Config {
+	server.configMut.RLock()
+	defer server.configMut.RUnlock()
+	return server.config
+}
+
+func (server *SugarDB) getConfigParameters(patterns ...string) map[string]string {
+	return config.GetParameters(server.getConfig(), patterns...)
+}
+
+// setConfigParameters changes the configuration parameters and applies the new values to the running server.
+// None of the parameters is changed if one of them is invalid.
+func (server *SugarDB) setConfigParameters(values map[string]string) error {
+	server.configMut.Lock()
+	defer server.configMut.Unlock()
+
+	conf := server.config
+	if err := config.SetParameters(&conf, values); err != nil {
+		return err
+	}
+
+	if conf.NotifyKeyspaceEvents != server.config.NotifyKeyspaceEvents {
+		keyspaceEvents, _ := config.ParseKeyspaceEvents(conf.NotifyKeyspaceEvents)
+		server.keyspaceEvents.Store(int64(keyspaceEvents))
+	}
+	if (conf.RequirePass != server.config.RequirePass || conf.Password != server.config.Password) && server.acl != nil {
+		server.acl.SetRequirePass(conf.RequirePass, conf.Password)
+	}
+	if conf.LatencyMonitorThreshold != server.config.LatencyMonitorThreshold {
+		server.latency.threshold.Store(conf.LatencyMonitorThreshold)
+	}
+	if conf.EvictionInterval != server.config.EvictionInterval && server.evictionTicker != nil {
+		server.evictionTicker.Reset(conf.EvictionInterval)
+	}
+	// The snapshots and the AOF in cluster mode are handled by raft, which keeps the settings it started with.
+	if conf.AOFSyncStrategy != server.config.AOFSyncStrategy && server.aofEngine != nil {
+		server.aofEngine.SetStrategy(conf.AOFSyncStrategy)
+	}
+	if server.snapshotEngine != nil {
+		if conf.SnapshotInterval != server.config.SnapshotInterval {
+			server.snapshotEngine.SetInterval(conf.SnapshotInterval)
+		}
+		if conf.SnapShotThreshold != server.config.SnapShotThreshold {
+			server.snapshotEngine.SetThreshold(conf.SnapShotThreshold)
+		}
+	}
+
+	server.config = conf
+	return nil
+}
+
+// rewriteConfig persists the runtime configuration to the config file.
+func (server *SugarDB) rewriteConfig() error {
+	return config.Rewrite(server.getConfig())
+}
+
+// resetStats resets the counters reported by INFO.
+func (server *SugarDB) resetStats() {
+	server.stats.connectionsReceived.Store(0)
+	server.stats.commandsProcessed.Store(0)
+	server.stats.expiredKeys.Store(0)
+	server.stats.evictedKeys.Store(0)
+	server.stats.keyspaceHits.Store(0)
+	server.stats.keyspaceMisses.Store(0)
+
+	server.stats.commandsMut.Lock()
+	clear(server.stats.commands)
+	server.stats.commandsMut.Unlock()
+
+	server.stats.eventsMut.Lock()
+	defer server.stats.eventsMut.Unlock()
+	clear(server.stats.events)
+}
@@ -0,0 +1,434 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sugardb
+
+import (
+	"context"
+	"fmt"
+	"github.com/echovault/sugardb/internal"
+	"net"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The sections of the INFO report, in the order they're reported. Command stats are not reported by default.
+var (
+	infoSections        = []string{"server", "clients", "memory", "persistence", "stats", "replication", "keyspace", "commandstats"}
+	defaultInfoSections = []string{"server", "clients", "memory", "persistence", "stats", "replication", "keyspace"}
+)
+
+// serverStats holds the counters reported by INFO.
+type serverStats struct {
+	startTime           time.Time     // The time the server was created.
+	connectionsReceived atomic.Uint64 // The number of TCP connections accepted.
+	commandsProcessed   atomic.Uint64 // The number of commands executed.
+	expiredKeys         atomic.Uint64 // The number of keys deleted because they expired.
+	evictedKeys         atomic.Uint64 // The number of keys deleted because the memory usage exceeded MaxMemory.
+	keyspaceHits        atomic.Uint64 // The number of keys found by the commands.
+	keyspaceMisses      atomic.Uint64 // The number of keys not found by the commands.
+
+	commandsMut sync.Mutex
+	commands    map[string]*commandStats // The stats of each command, by command name.
+
+	eventsMut sync.Mutex
+	events    map[string]*eventStats // The durations of the persistence, raft and eviction events, by event name.
+}
+
+// commandStats holds the call counts and the execution time of a command.
+type commandStats struct {
+	calls         uint64        // The number of executions.
+	failedCalls   uint64        // The number of executions that returned an error.
+	rejectedCalls uint64        // The number of times the command was rejected before it was executed.
+	duration      time.Duration // The total execution time.
+	histogram     [64]uint64    // The number of executions in each bucket of execution time. See latencyBucket.
+}
+
+// eventStats holds the durations of an event, such as an AOF fsync or a snapshot.
+type eventStats struct {
+	count     uint64        // The number of times the event happened.
+	duration  time.Duration // The total duration of the event.
+	histogram [64]uint64    // The number of events in each bucket of duration. See latencyBucket.
+}
+
+// commandName returns the name that identifies the command in the stats, for example "get" or "client|list".
+func commandName(command internal.Command, subCommand internal.SubCommand) string {
+	name := strings.ToLower(command.Command)
+	if subCommand.Command != "" {
+		name += "|" + strings.ToLower(subCommand.Command)
+	}
+	return name
+}
+
+// recordCommand adds the command to the command stats. A zero start time means that the command was rejected
+// before it was executed, for example because of wrong arguments or missing permissions.
+func (server *SugarDB) recordCommand(command internal.Command, subCommand internal.SubCommand, start time.Time, err error) {
+	var duration time.Duration
+	if !start.IsZero() {
+		duration = time.Since(start)
+		server.stats.commandsProcessed.Add(1)
+	}
+
+	name := commandName(command, subCommand)
+
+	server.stats.commandsMut.Lock()
+	defer server.stats.commandsMut.Unlock()
+
+	stats, ok := server.stats.commands[name]
+	if !ok {
+		stats = &commandStats{}
+		server.stats.commands[name] = stats
+	}
+	switch {
+	case start.IsZero():
+		stats.rejectedCalls++
+	case err != nil:
+		stats.calls++
+		stats.failedCalls++
+		stats.duration += duration
+		stats.histogram[latencyBucket(duration)]++
+	default:
+		stats.calls++
+		stats.duration += duration
+		stats.histogram[latencyBucket(duration)]++
+	}
+}
+
+// recordEvent adds the duration of a persistence, raft or eviction event to the event stats, and records it in the
+// latency monitor. Like recordLatency, it's called while the engines hold their own locks.
+func (server *SugarDB) recordEvent(event string, duration time.Duration) {
+	server.stats.eventsMut.Lock()
+	stats, ok := server.stats.events[event]
+	if !ok {
+		stats = &eventStats{}
+		server.stats.events[event] = stats
+	}
+	stats.count++
+	stats.duration += duration
+	stats.histogram[latencyBucket(duration)]++
+	server.stats.eventsMut.Unlock()
+
+	server.recordLatency(event, duration)
+}
+
+// recordKeyspaceLookup counts the keys looked up by the read commands as hits or misses.
+func (server *SugarDB) recordKeyspaceLookup(ctx context.Context, hit bool) {
+	if read, _ := ctx.Value(internal.ContextReadCommand("ReadCommand")).(bool); !read {
+		return
+	}
+	if hit {
+		server.stats.keyspaceHits.Add(1)
+	} else {
+		server.stats.keyspaceMisses.Add(1)
+	}
+}
+
+// recordDeletedKey counts the keys deleted because they expired or were evicted.
+func (server *SugarDB) recordDeletedKey(event string) {
+	switch event {
+	case keyEventExpired:
+		server.stats.expiredKeys.Add(1)
+	case keyEventEvicted:
+		server.stats.evictedKeys.Add(1)
+	}
+}
+
+// getInfo returns the requested sections of the INFO report.
+func (server *SugarDB) getInfo(ctx context.Context, sections ...string) []internal.InfoSection {
+	requested := make([]string, 0, len(sections))
+	for _, section := range sections {
+		switch section = strings.ToLower(section); section {
+		case "default":
+			requested = append(requested, defaultInfoSections...)
+		case "all", "everything":
+			requested = append(requested, infoSections...)
+		default:
+			requested = append(requested, section)
+		}
+	}
+	if len(requested) == 0 {
+		requested = defaultInfoSections
+	}
+
+	res := make([]internal.InfoSection, 0, len(infoSections))
+	for _, section := range infoSections {
+		if !slices.Contains(requested, section) {
+			continue
+		}
+		switch section {
+		case "server":
+			res = append(res, server.serverInfo())
+		case "clients":
+			res = append(res, server.clientsInfo())
+		case "memory":
+			res = append(res, server.memoryInfo(ctx))
+		case "persistence":
+			res = append(res, server.persistenceInfo())
+		case "stats":
+			res = append(res, server.statsInfo())
+		case "replication":
+			res = append(res, server.replicationInfo())
+		case "keyspace":
+			res = append(res, server.keyspaceInfo(ctx))
+		case "commandstats":
+			res = append(res, server.commandStatsInfo())
+		}
+	}
+
+	return res
+}
+
+func (server *SugarDB) serverInfo() internal.InfoSection {
+	info := server.GetServerInfo()
+	uptime := server.clock.Now().Sub(server.stats.startTime)
+	return internal.InfoSection{
+		Name: "Server",
+		Fields: []internal.InfoField{
+			{Name: "sugardb_version", Value: info.Version},
+			{Name: "sugardb_mode", Value: info.Mode},
+			{Name: "server_id", Value: info.Id},
+			{Name: "os", Value: fmt.Sprintf("%s %s", runtime.GOOS, runtime.GOARCH)},
+			{Name: "go_version", Value: runtime.Version()},
+			{Name: "process_id", Value: strconv.Itoa(os.Getpid())},
+			{Name: "tcp_port", Value: strconv.Itoa(int(server.config.Port))},
+			{Name: "server_time_usec", Value: strconv.FormatInt(server.clock.Now().UnixMicro(), 10)},
+			{Name: "uptime_in_seconds", Value: strconv.FormatInt(int64(uptime.Seconds()), 10)},
+			{Name: "uptime_in_days", Value: strconv.FormatInt(int64(uptime.Hours()/24), 10)},
+		},
+	}
+}
+
+func (server *SugarDB) clientsInfo() internal.InfoSection {
+	server.connInfo.mut.RLock()
+	connected := len(server.connInfo.tcpClients)
+	server.connInfo.mut.RUnlock()
+
+	server.blockedClients.mut.Lock()
+	blocked := make(map[chan struct{}]struct{})
+	for _, keys := range server.blockedClients.waiters {
+		for _, waiters := range keys {
+			for _, waiter := range waiters {
+				blocked[waiter] = struct{}{}
+			}
+		}
+	}
+	server.blockedClients.mut.Unlock()
+
+	server.tracking.mut.Lock()
+	tracking := len(server.tracking.clients)
+	server.tracking.mut.Unlock()
+
+	subscribers := make(map[*net.Conn]struct{})
+	for _, channel := range server.pubSub.GetAllChannels() {
+		for conn := range channel.Subscribers() {
+			subscribers[conn] = struct{}{}
+		}
+	}
+
+	return internal.InfoSection{
+		Name: "Clients",
+		Fields: []internal.InfoField{
+			{Name: "connected_clients", Value: strconv.Itoa(connected)},
+			{Name: "blocked_clients", Value: strconv.Itoa(len(blocked))},
+			{Name: "tracking_clients", Value: strconv.Itoa(tracking)},
+			{Name: "pubsub_clients", Value: strconv.Itoa(len(subscribers))},
+		},
+	}
+}
+
+func (server *SugarDB) memoryInfo(ctx context.Context) internal.InfoSection {
+	server.rLockStore(ctx)
+	used := server.memUsed
+	server.rUnlockStore(ctx)
+
+	return internal.InfoSection{
+		Name: "Memory",
+		Fields: []internal.InfoField{
+			{Name: "used_memory", Value: strconv.FormatInt(used, 10)},
+			{Name: "used_memory_human", Value: formatBytes(uint64(max(used, 0)))},
+			{Name: "maxmemory", Value: strconv.FormatUint(server.getConfig().MaxMemory, 10)},
+			{Name: "maxmemory_human", Value: formatBytes(server.getConfig().MaxMemory)},
+			{Name: "maxmemory_policy", Value: server.getConfig().EvictionPolicy},
+		},
+	}
+}
+
+func (server *SugarDB) persistenceInfo() internal.InfoSection {
+	changes := uint64(0)
+	if server.snapshotEngine != nil {
+		changes = server.snapshotEngine.ChangeCount()
+	}
+	aofEnabled := "0"
+	if !server.isInCluster() {
+		aofEnabled = "1"
+	}
+
+	return internal.InfoSection{
+		Name: "Persistence",
+		Fields: []internal.InfoField{
+			{Name: "rdb_changes_since_last_save", Value: strconv.FormatUint(changes, 10)},
+			{Name: "rdb_bgsave_in_progress", Value: formatBool(server.snapshotInProgress.Load())},
+			{Name: "rdb_last_save_time", Value: strconv.FormatInt(server.latestSnapshotMilliseconds.Load()/1000, 10)},
+			{Name: "aof_enabled", Value: aofEnabled},
+			{Name: "aof_rewrite_in_progress", Value: formatBool(server.rewriteAOFInProgress.Load())},
+			{Name: "aof_fsync_strategy", Value: server.getConfig().AOFSyncStrategy},
+			{Name: "state_copy_in_progress", Value: formatBool(server.stateCopyInProgress.Load())},
+		},
+	}
+}
+
+func (server *SugarDB) statsInfo() internal.InfoSection {
+	channels, patterns := 0, 0
+	for _, channel := range server.pubSub.GetAllChannels() {
+		if len(channel.Subscribers()) == 0 {
+			continue
+		}
+		if channel.Pattern() != nil {
+			patterns++
+		} else {
+			channels++
+		}
+	}
+
+	return internal.InfoSection{
+		Name: "Stats",
+		Fields: []internal.InfoField{
+			{Name: "total_connections_received", Value: strconv.FormatUint(server.stats.connectionsReceived.Load(), 10)},
+			{Name: "total_commands_processed", Value: strconv.FormatUint(server.stats.commandsProcessed.Load(), 10)},
+			{Name: "expired_keys", Value: strconv.FormatUint(server.stats.expiredKeys.Load(), 10)},
+			{Name: "evicted_keys", Value: strconv.FormatUint(server.stats.evictedKeys.Load(), 10)},
+			{Name: "keyspace_hits", Value: strconv.FormatUint(server.stats.keyspaceHits.Load(), 10)},
+			{Name: "keyspace_misses", Value: strconv.FormatUint(server.stats.keyspaceMisses.Load(), 10)},
+			{Name: "pubsub_channels", Value: strconv.Itoa(channels)},
+			{Name: "pubsub_patterns", Value: strconv.Itoa(patterns)},
+		},
+	}
+}
+
+func (server *SugarDB) replicationInfo() internal.InfoSection {
+	section := internal.InfoSection{
+		Name:   "Replication",
+		Fields: []internal.InfoField{{Name: "role", Value: server.GetServerInfo().Role}},
+	}
+	if !server.isInCluster() {
+		return section
+	}
+
+	info := server.raft.Info()
+	section.Fields = append(section.Fields,
+		internal.InfoField{Name: "raft_state", Value: strings.ToLower(info.State)},
+		internal.InfoField{Name: "raft_leader_id", Value: info.LeaderID},
+		internal.InfoField{Name: "raft_leader_address", Value: info.LeaderAddress},
+		internal.InfoField{Name: "raft_term", Value: strconv.FormatUint(info.Term, 10)},
+		internal.InfoField{Name: "raft_commit_index", Value: strconv.FormatUint(info.CommitIndex, 10)},
+		internal.InfoField{Name: "raft_applied_index", Value: strconv.FormatUint(info.AppliedIndex, 10)},
+		internal.InfoField{Name: "raft_peers", Value: strconv.Itoa(len(info.Peers))},
+	)
+	for i, peer := range info.Peers {
+		section.Fields = append(section.Fields, internal.InfoField{
+			Name:  fmt.Sprintf("peer%d", i),
+			Value: fmt.Sprintf("id=%s,address=%s,suffrage=%s", peer.ID, peer.Address, peer.Suffrage),
+		})
+	}
+	return section
+}
+
+func (server *SugarDB) keyspaceInfo(ctx context.Context) internal.InfoSection {
+	server.rLockStore(ctx)
+	defer server.rUnlockStore(ctx)
+
+	now := server.clock.Now()
+	databases := make([]int, 0, len(server.store))
+	for database := range server.store {
+		databases = append(databases, database)
+	}
+	slices.Sort(databases)
+
+	section := internal.InfoSection{Name: "Keyspace", Fields: make([]internal.InfoField, 0, len(databases))}
+	for _, database := range databases {
+		if len(server.store[database]) == 0 {
+			continue
+		}
+		expires, ttl := 0, int64(0)
+		for _, entry := range server.store[database] {
+			if entry.ExpireAt == (time.Time{}) {
+				continue
+			}
+			expires++
+			ttl += max(entry.ExpireAt.Sub(now).Milliseconds(), 0)
+		}
+		avgTTL := int64(0)
+		if expires > 0 {
+			avgTTL = ttl / int64(expires)
+		}
+		section.Fields = append(section.Fields, internal.InfoField{
+			Name:  fmt.Sprintf("db%d", database),
+			Value: fmt.Sprintf("keys=%d,expires=%d,avg_ttl=%d", len(server.store[database]), expires, avgTTL),
+		})
+	}
+	return section
+}
+
+func (server *SugarDB) commandStatsInfo() internal.InfoSection {
+	server.stats.commandsMut.Lock()
+	defer server.stats.commandsMut.Unlock()
+
+	names := make([]string, 0, len(server.stats.commands))
+	for name := range server.stats.commands {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	section := internal.InfoSection{Name: "Commandstats", Fields: make([]internal.InfoField, 0, len(names))}
+	for _, name := range names {
+		stats := server.stats.commands[name]
+		usec, usecPerCall := stats.duration.Microseconds(), float64(0)
+		if stats.calls > 0 {
+			usecPerCall = float64(usec) / float64(stats.calls)
+		}
+		section.Fields = append(section.Fields, internal.InfoField{
+			Name: "cmdstat_" + name,
+			Value: fmt.Sprintf("calls=%d,usec=%d,usec_per_call=%.2f,rejected_calls=%d,failed_calls=%d",
+				stats.calls, usec, usecPerCall, stats.rejectedCalls, stats.failedCalls),
+		})
+	}
+	return section
+}
+
+func formatBool(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// formatBytes returns the number of bytes in a human readable format, for example 1.50M.
+func formatBytes(n uint64) string {
+	units := []string{"K", "M", "G", "T"}
+	if n < 1024 {
+		return fmt.Sprintf("%dB", n)
+	}
+	value := float64(n) / 1024
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%.2f%s", value, units[unit])
+}
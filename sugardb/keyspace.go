@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"maps"
 	"math/rand"
 	"runtime"
 	"slices"
@@ -29,6 +30,7 @@ import (
 	"unsafe"
 
 	"github.com/echovault/sugardb/internal"
+	"github.com/echovault/sugardb/internal/config"
 	"github.com/echovault/sugardb/internal/constants"
 	"github.com/echovault/sugardb/internal/eviction"
 )
@@ -38,19 +40,23 @@ import (
 // This only affects TCP connections, it does not swap the logical database currently
 // being used by the embedded API.
 func (server *SugarDB) SwapDBs(database1, database2 int) {
+	server.swapDBs(server.context, database1, database2)
+}
+
+func (server *SugarDB) swapDBs(ctx context.Context, database1, database2 int) {
 	// If the databases are the same, skip the swap.
 	if database1 == database2 {
 		return
 	}
 
 	// If any of the databases does not exist, create them.
-	server.storeLock.Lock()
+	server.lockStore(ctx)
 	for _, database := range []int{database1, database2} {
 		if server.store[database] == nil {
 			server.createDatabase(database)
 		}
 	}
-	server.storeLock.Unlock()
+	server.unlockStore(ctx)
 
 	// Swap the connections for each database.
 	server.connInfo.mut.Lock()
@@ -78,16 +84,25 @@ func (server *SugarDB) SwapDBs(database1, database2 int) {
 // Flush flushes all the data from the database at the specified index.
 // When -1 is passed, all the logical databases are cleared.
 func (server *SugarDB) Flush(database int) {
-	server.storeLock.Lock()
-	defer server.storeLock.Unlock()
+	server.flush(server.context, database)
+}
+
+func (server *SugarDB) flush(ctx context.Context, database int) {
+	server.lockStore(ctx)
+	defer server.unlockStore(ctx)
 
 	server.keysWithExpiry.rwMutex.Lock()
 	defer server.keysWithExpiry.rwMutex.Unlock()
 
+	// The clients that cache keys have to drop all of them.
+	server.invalidateAllKeys(ctx)
+
 	if database == -1 {
 		for db, _ := range server.store {
 			// Clear db store.
 			clear(server.store[db])
+			// Invalidate the versions of the keys.
+			server.removeKeyVersions(ctx, db)
 			// Clear db volatile key tracker.
 			clear(server.keysWithExpiry.keys[db])
 			// Clear db LFU cache.
@@ -104,6 +119,8 @@ func (server *SugarDB) Flush(database int) {
 
 	// Clear db store.
 	clear(server.store[database])
+	// Invalidate the versions of the keys.
+	server.removeKeyVersions(ctx, database)
 	// Clear db volatile key tracker.
 	clear(server.keysWithExpiry.keys[database])
 	// Clear db LFU cache.
@@ -116,9 +133,49 @@ func (server *SugarDB) Flush(database int) {
 	server.lruCache.cache[database].Mutex.Unlock()
 }
 
+// inTransaction returns true when the command behind the context is executed by EXEC.
+func inTransaction(ctx context.Context) bool {
+	inTransaction, _ := ctx.Value(internal.ContextTransaction("Transaction")).(bool)
+	return inTransaction
+}
+
+// outsideTransaction returns a context for work that is detached from the command, such as asynchronous
+// cache updates. That work has to acquire the store lock itself even when the command is part of a transaction.
+func outsideTransaction(ctx context.Context) context.Context {
+	return context.WithValue(ctx, internal.ContextTransaction("Transaction"), false)
+}
+
+// lockStore acquires the store lock for writing.
+// The lock is not acquired inside a transaction because EXEC holds it until all the queued commands are applied.
+// Scripts run with the transaction context for the same reason.
+func (server *SugarDB) lockStore(ctx context.Context) {
+	if !inTransaction(ctx) {
+		server.storeLock.Lock()
+	}
+}
+
+func (server *SugarDB) unlockStore(ctx context.Context) {
+	if !inTransaction(ctx) {
+		server.storeLock.Unlock()
+	}
+}
+
+// rLockStore acquires the store lock for reading. Like lockStore, it's a no-op inside a transaction.
+func (server *SugarDB) rLockStore(ctx context.Context) {
+	if !inTransaction(ctx) {
+		server.storeLock.RLock()
+	}
+}
+
+func (server *SugarDB) rUnlockStore(ctx context.Context) {
+	if !inTransaction(ctx) {
+		server.storeLock.RUnlock()
+	}
+}
+
 func (server *SugarDB) keysExist(ctx context.Context, keys []string) map[string]bool {
-	server.storeLock.RLock()
-	defer server.storeLock.RUnlock()
+	server.rLockStore(ctx)
+	defer server.rUnlockStore(ctx)
 
 	database := ctx.Value("Database").(int)
 
@@ -127,14 +184,17 @@ func (server *SugarDB) keysExist(ctx context.Context, keys []string) map[string]
 	for _, key := range keys {
 		_, ok := server.store[database][key]
 		exists[key] = ok
+		if !ok {
+			server.recordKeyspaceLookup(ctx, false)
+		}
 	}
 
 	return exists
 }
 
 func (server *SugarDB) getExpiry(ctx context.Context, key string) time.Time {
-	server.storeLock.RLock()
-	defer server.storeLock.RUnlock()
+	server.rLockStore(ctx)
+	defer server.rUnlockStore(ctx)
 
 	database := ctx.Value("Database").(int)
 
@@ -146,9 +206,28 @@ func (server *SugarDB) getExpiry(ctx context.Context, key string) time.Time {
 	return entry.ExpireAt
 }
 
+// getKeys returns all the keys in the database from the context that have not expired.
+func (server *SugarDB) getKeys(ctx context.Context) []string {
+	server.rLockStore(ctx)
+	defer server.rUnlockStore(ctx)
+
+	database := ctx.Value("Database").(int)
+	now := server.clock.Now()
+
+	keys := make([]string, 0, len(server.store[database]))
+	for key, entry := range server.store[database] {
+		if entry.ExpireAt != (time.Time{}) && entry.ExpireAt.Before(now) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
 func (server *SugarDB) getValues(ctx context.Context, keys []string) map[string]interface{} {
-	server.storeLock.Lock()
-	defer server.storeLock.Unlock()
+	server.lockStore(ctx)
+	defer server.unlockStore(ctx)
 
 	database := ctx.Value("Database").(int)
 
@@ -157,6 +236,8 @@ func (server *SugarDB) getValues(ctx context.Context, keys []string) map[string]
 	for _, key := range keys {
 		entry, ok := server.store[database][key]
 		if !ok {
+			server.recordKeyspaceLookup(ctx, false)
+			server.notifyKeyMiss(ctx, database, key)
 			values[key] = nil
 			continue
 		}
@@ -164,13 +245,13 @@ func (server *SugarDB) getValues(ctx context.Context, keys []string) map[string]
 		if entry.ExpireAt != (time.Time{}) && entry.ExpireAt.Before(server.clock.Now()) {
 			if !server.isInCluster() {
 				// If in standalone mode, delete the key directly.
-				err := server.deleteKey(ctx, key)
+				err := server.deleteKey(withKeyEvent(ctx, keyEventExpired), key)
 				if err != nil {
 					log.Printf("keyExists: %+v\n", err)
 				}
 			} else if server.isInCluster() && server.raft.IsRaftLeader() {
 				// If we're in a raft cluster, and we're the leader, send command to delete the key in the cluster.
-				err := server.raftApplyDeleteKey(ctx, key)
+				err := server.raftApplyDeleteKey(withKeyEvent(ctx, keyEventExpired), key)
 				if err != nil {
 					log.Printf("keyExists: %+v\n", err)
 				}
@@ -180,10 +261,13 @@ func (server *SugarDB) getValues(ctx context.Context, keys []string) map[string]
 				// because we always want to remove expired keys.
 				server.memberList.ForwardDeleteKey(ctx, key)
 			}
+			server.recordKeyspaceLookup(ctx, false)
+			server.notifyKeyMiss(ctx, database, key)
 			values[key] = nil
 			continue
 		}
 
+		server.recordKeyspaceLookup(ctx, true)
 		values[key] = entry.Value
 	}
 
@@ -192,16 +276,17 @@ func (server *SugarDB) getValues(ctx context.Context, keys []string) map[string]
 		if _, err := server.updateKeysInCache(ctx, keys); err != nil {
 			log.Printf("getValues error: %+v\n", err)
 		}
-	}(ctx, keys)
+	}(outsideTransaction(ctx), keys)
 
 	return values
 }
 
 func (server *SugarDB) setValues(ctx context.Context, entries map[string]interface{}) error {
-	server.storeLock.Lock()
-	defer server.storeLock.Unlock()
+	server.lockStore(ctx)
+	defer server.unlockStore(ctx)
 
-	if internal.IsMaxMemoryExceeded(server.memUsed, server.config.MaxMemory) && server.config.EvictionPolicy == constants.NoEviction {
+	conf := server.getConfig()
+	if internal.IsMaxMemoryExceeded(server.memUsed, conf.MaxMemory) && conf.EvictionPolicy == constants.NoEviction {
 
 		return errors.New("max memory reached, key value not set")
 	}
@@ -213,15 +298,28 @@ func (server *SugarDB) setValues(ctx context.Context, entries map[string]interfa
 		server.createDatabase(database)
 	}
 
+	event := commandEvent(ctx)
+
 	for key, value := range entries {
 		expireAt := time.Time{}
-		if _, ok := server.store[database][key]; ok {
-			expireAt = server.store[database][key].ExpireAt
+		previous, exists := server.store[database][key]
+		if exists {
+			expireAt = previous.ExpireAt
 		}
 		server.store[database][key] = internal.KeyData{
 			Value:    value,
 			ExpireAt: expireAt,
 		}
+		server.updateKeyVersion(ctx, database, key)
+		server.invalidateKey(ctx, key)
+
+		if event != "" {
+			if !exists {
+				server.notifyKeyspaceEvent(database, config.KeyspaceEventsNew, keyEventNew, key)
+			}
+			server.notifyKeyspaceEvent(database, valueEventClass(value), event, key)
+		}
+
 		data := server.store[database][key]
 		mem, err := data.GetMem()
 		if err != nil {
@@ -236,29 +334,51 @@ func (server *SugarDB) setValues(ctx context.Context, entries map[string]interfa
 		}
 	}
 
+	// Wake up the clients blocked on the keys.
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	server.signalKeys(database, keys)
+
 	// Asynchronously update the keys in the cache.
-	go func(ctx context.Context, entries map[string]interface{}) {
-		for key, _ := range entries {
+	// The seeded keys are updated by the command that seeds them, once their expiry is set.
+	keys = slices.DeleteFunc(slices.Clone(keys), func(key string) bool {
+		return server.hasCacheSeed(database, key)
+	})
+	go func(ctx context.Context, keys []string) {
+		for _, key := range keys {
 			_, err := server.updateKeysInCache(ctx, []string{key})
 			if err != nil {
 				log.Printf("setValues error: %+v\n", err)
 			}
 		}
-	}(ctx, entries)
+	}(outsideTransaction(ctx), keys)
 
 	return nil
 }
 
 func (server *SugarDB) setExpiry(ctx context.Context, key string, expireAt time.Time, touch bool) {
-	server.storeLock.Lock()
-	defer server.storeLock.Unlock()
+	server.lockStore(ctx)
+	defer server.unlockStore(ctx)
 
 	database := ctx.Value("Database").(int)
 
+	previousExpireAt := server.store[database][key].ExpireAt
 	server.store[database][key] = internal.KeyData{
 		Value:    server.store[database][key].Value,
 		ExpireAt: expireAt,
 	}
+	server.updateKeyVersion(ctx, database, key)
+	server.invalidateKey(ctx, key)
+
+	if commandEvent(ctx) != "" {
+		if expireAt != (time.Time{}) {
+			server.notifyKeyspaceEvent(database, config.KeyspaceEventsGeneric, keyEventExpire, key)
+		} else if previousExpireAt != (time.Time{}) {
+			server.notifyKeyspaceEvent(database, config.KeyspaceEventsGeneric, keyEventPersist, key)
+		}
+	}
 
 	// If the slice of keys associated with expiry time does not contain the current key, add the key.
 	server.keysWithExpiry.rwMutex.Lock()
@@ -274,7 +394,7 @@ func (server *SugarDB) setExpiry(ctx context.Context, key string, expireAt time.
 			if err != nil {
 				log.Printf("setExpiry error: %+v\n", err)
 			}
-		}(ctx, key)
+		}(outsideTransaction(ctx), key)
 	}
 }
 
@@ -293,6 +413,8 @@ func (server *SugarDB) deleteKey(ctx context.Context, key string) error {
 
 	// Delete the key from keyLocks and store.
 	delete(server.store[database], key)
+	server.removeKeyVersion(ctx, database, key)
+	server.invalidateKey(ctx, key)
 
 	// Remove key from slice of keys associated with expiry.
 	server.keysWithExpiry.rwMutex.Lock()
@@ -302,18 +424,123 @@ func (server *SugarDB) deleteKey(ctx context.Context, key string) error {
 	})
 
 	// Remove the key from the cache associated with the database.
+	policy := server.getConfig().EvictionPolicy
 	switch {
-	case slices.Contains([]string{constants.AllKeysLFU, constants.VolatileLFU}, server.config.EvictionPolicy):
+	case slices.Contains([]string{constants.AllKeysLFU, constants.VolatileLFU}, policy):
 		server.lfuCache.cache[database].Delete(key)
-	case slices.Contains([]string{constants.AllKeysLRU, constants.VolatileLRU}, server.config.EvictionPolicy):
+	case slices.Contains([]string{constants.AllKeysLRU, constants.VolatileLRU}, policy):
 		server.lruCache.cache[database].Delete(key)
 	}
 
+	if event, class := deleteEventClass(ctx); event != "" {
+		server.recordDeletedKey(event)
+		server.notifyKeyspaceEvent(database, class, event, key)
+	}
+
 	log.Printf("deleted key %s\n", key)
 
 	return nil
 }
 
+// isExpired returns true if the entry has an expiry time that is in the past.
+func (server *SugarDB) isExpired(entry internal.KeyData) bool {
+	return entry.ExpireAt != (time.Time{}) && entry.ExpireAt.Before(server.clock.Now())
+}
+
+// updateKeyVersion hands out a new version for the key so that the clients watching it can detect the change.
+// The keys modified by a raft log entry get the index of the entry, so that the version is the same on every node.
+// The caller must hold the store lock.
+func (server *SugarDB) updateKeyVersion(ctx context.Context, database int, key string) {
+	if server.keyVersions.versions[database] == nil {
+		server.keyVersions.versions[database] = make(map[string]uint64)
+	}
+	if index, ok := ctx.Value(internal.ContextRaftIndex("RaftIndex")).(uint64); ok {
+		server.keyVersions.versions[database][key] = index
+		return
+	}
+	server.keyVersions.counter++
+	server.keyVersions.versions[database][key] = server.keyVersions.counter
+}
+
+// removeKeyVersion is called when the key is deleted. If a client is watching the key, the key gets a new version.
+// Otherwise, the version is dropped so that the versions of deleted keys don't accumulate.
+// The watchers are only known by the node that received WATCH, so a deletion applied from the raft log always drops
+// the version and records the index of the entry as the latest deletion in the database instead.
+// The caller must hold the store lock.
+func (server *SugarDB) removeKeyVersion(ctx context.Context, database int, key string) {
+	if index, ok := ctx.Value(internal.ContextRaftIndex("RaftIndex")).(uint64); ok {
+		delete(server.keyVersions.versions[database], key)
+		server.keyVersions.deleted[database] = index
+		return
+	}
+	if server.keyVersions.watchers[database][key] > 0 {
+		server.updateKeyVersion(ctx, database, key)
+		return
+	}
+	delete(server.keyVersions.versions[database], key)
+}
+
+// removeKeyVersions calls removeKeyVersion for all the keys in the database.
+// The caller must hold the store lock.
+func (server *SugarDB) removeKeyVersions(ctx context.Context, database int) {
+	for key := range server.keyVersions.versions[database] {
+		server.removeKeyVersion(ctx, database, key)
+	}
+}
+
+// getKeyVersionsOf returns the versions of the keys in the database from the context.
+func (server *SugarDB) getKeyVersionsOf(ctx context.Context, keys []string) map[string]uint64 {
+	server.rLockStore(ctx)
+	defer server.rUnlockStore(ctx)
+
+	database := ctx.Value("Database").(int)
+	versions := make(map[string]uint64, len(keys))
+	for _, key := range keys {
+		versions[key] = server.keyVersions.versions[database][key]
+	}
+	return versions
+}
+
+// deleteKeyIfVersion deletes the key if it has not been modified since its version was read with getKeyVersionsOf.
+func (server *SugarDB) deleteKeyIfVersion(ctx context.Context, key string, version uint64) (bool, error) {
+	server.lockStore(ctx)
+	defer server.unlockStore(ctx)
+
+	database := ctx.Value("Database").(int)
+	if _, ok := server.store[database][key]; !ok || server.keyVersions.versions[database][key] != version {
+		return false, nil
+	}
+	return true, server.deleteKey(ctx, key)
+}
+
+// getKeyVersions returns a copy of the key versions to save in a raft snapshot.
+func (server *SugarDB) getKeyVersions() internal.KeyVersions {
+	server.storeLock.RLock()
+	defer server.storeLock.RUnlock()
+
+	versions := internal.KeyVersions{
+		Versions: make(map[int]map[string]uint64),
+		Deleted:  maps.Clone(server.keyVersions.deleted),
+	}
+	for database, keys := range server.keyVersions.versions {
+		versions.Versions[database] = maps.Clone(keys)
+	}
+	return versions
+}
+
+// setKeyVersions replaces the key versions with the ones restored from a raft snapshot.
+func (server *SugarDB) setKeyVersions(versions internal.KeyVersions) {
+	server.storeLock.Lock()
+	defer server.storeLock.Unlock()
+
+	server.keyVersions.versions = make(map[int]map[string]uint64)
+	for database, keys := range versions.Versions {
+		server.keyVersions.versions[database] = maps.Clone(keys)
+	}
+	server.keyVersions.deleted = make(map[int]uint64)
+	maps.Copy(server.keyVersions.deleted, versions.Deleted)
+}
+
 func (server *SugarDB) createDatabase(database int) {
 	// Create database store.
 	server.store[database] = make(map[string]internal.KeyData)
@@ -364,14 +591,21 @@ func (server *SugarDB) updateKeysInCache(ctx context.Context, keys []string) (in
 		return touchCounter, nil
 	}
 	// If max memory is 0, there's no max so no need to update caches.
-	if server.config.MaxMemory == 0 {
+	conf := server.getConfig()
+	if conf.MaxMemory == 0 {
+		// Drop the seeds of the keys, as they are never applied.
+		for _, key := range keys {
+			server.takeCacheSeed(database, key)
+		}
 		return touchCounter, nil
 	}
 
-	server.storeLock.Lock()
-	defer server.storeLock.Unlock()
+	server.lockStore(ctx)
+	defer server.unlockStore(ctx)
 
 	for _, key := range keys {
+		seed, seeded := server.takeCacheSeed(database, key)
+
 		// Verify key exists
 		if _, ok := server.store[database][key]; !ok {
 			continue
@@ -379,25 +613,37 @@ func (server *SugarDB) updateKeysInCache(ctx context.Context, keys []string) (in
 
 		touchCounter++
 
-		switch strings.ToLower(server.config.EvictionPolicy) {
+		switch strings.ToLower(conf.EvictionPolicy) {
 		case constants.AllKeysLFU:
 			server.lfuCache.cache[database].Mutex.Lock()
 			server.lfuCache.cache[database].Update(key)
+			if seeded {
+				server.lfuCache.cache[database].SetCount(key, int(seed))
+			}
 			server.lfuCache.cache[database].Mutex.Unlock()
 		case constants.AllKeysLRU:
 			server.lruCache.cache[database].Mutex.Lock()
 			server.lruCache.cache[database].Update(key)
+			if seeded {
+				server.lruCache.cache[database].SetTime(key, seed)
+			}
 			server.lruCache.cache[database].Mutex.Unlock()
 		case constants.VolatileLFU:
 			server.lfuCache.cache[database].Mutex.Lock()
 			if server.store[database][key].ExpireAt != (time.Time{}) {
 				server.lfuCache.cache[database].Update(key)
+				if seeded {
+					server.lfuCache.cache[database].SetCount(key, int(seed))
+				}
 			}
 			server.lfuCache.cache[database].Mutex.Unlock()
 		case constants.VolatileLRU:
 			server.lruCache.cache[database].Mutex.Lock()
 			if server.store[database][key].ExpireAt != (time.Time{}) {
 				server.lruCache.cache[database].Update(key)
+				if seeded {
+					server.lruCache.cache[database].SetTime(key, seed)
+				}
 			}
 			server.lruCache.cache[database].Mutex.Unlock()
 		}
@@ -435,7 +681,8 @@ func (server *SugarDB) updateKeysInCache(ctx context.Context, keys []string) (in
 // adjustMemoryUsage should only be called from standalone echovault or from raft cluster leader.
 func (server *SugarDB) adjustMemoryUsage(ctx context.Context) error {
 	// If max memory is 0, there's no need to adjust memory usage.
-	if server.config.MaxMemory == 0 {
+	conf := server.getConfig()
+	if conf.MaxMemory == 0 {
 		return nil
 	}
 
@@ -444,22 +691,29 @@ func (server *SugarDB) adjustMemoryUsage(ctx context.Context) error {
 	// Check if memory usage is above max-memory.
 	// If it is, pop items from the cache until we get under the limit.
 	// If we're using less memory than the max-memory, there's no need to evict.
-	if uint64(server.memUsed) < server.config.MaxMemory {
+	if uint64(server.memUsed) < conf.MaxMemory {
 		return nil
 	}
+	// The eviction cycle, including the garbage collection, is tracked by the latency monitor.
+	start := time.Now()
+	defer func() {
+		server.recordEvent(constants.EvictionLatencyEvent, time.Since(start))
+	}()
+
 	// Force a garbage collection first before we start evicting keys.
 	runtime.GC()
-	if uint64(server.memUsed) < server.config.MaxMemory {
+	if uint64(server.memUsed) < conf.MaxMemory {
 		return nil
 	}
 
 	// We've done a GC, but we're still at or above the max memory limit.
 	// Start a loop that evicts keys until either the heap is empty or
 	// we're below the max memory limit.
+	ctx = withKeyEvent(ctx, keyEventEvicted)
 
 	log.Printf("Memory used: %v, Max Memory: %v", server.GetServerInfo().MemoryUsed, server.GetServerInfo().MaxMemory)
 	switch {
-	case slices.Contains([]string{constants.AllKeysLFU, constants.VolatileLFU}, strings.ToLower(server.config.EvictionPolicy)):
+	case slices.Contains([]string{constants.AllKeysLFU, constants.VolatileLFU}, strings.ToLower(conf.EvictionPolicy)):
 		// Remove keys from LFU cache until we're below the max memory limit or
 		// until the LFU cache is empty.
 		server.lfuCache.cache[database].Mutex.Lock()
@@ -488,11 +742,11 @@ func (server *SugarDB) adjustMemoryUsage(ctx context.Context) error {
 			// Run garbage collection
 			runtime.GC()
 			// Return if we're below max memory
-			if uint64(server.memUsed) < server.config.MaxMemory {
+			if uint64(server.memUsed) < conf.MaxMemory {
 				return nil
 			}
 		}
-	case slices.Contains([]string{constants.AllKeysLRU, constants.VolatileLRU}, strings.ToLower(server.config.EvictionPolicy)):
+	case slices.Contains([]string{constants.AllKeysLRU, constants.VolatileLRU}, strings.ToLower(conf.EvictionPolicy)):
 		// Remove keys from th LRU cache until we're below the max memory limit or
 		// until the LRU cache is empty.
 		server.lruCache.cache[database].Mutex.Lock()
@@ -521,11 +775,11 @@ func (server *SugarDB) adjustMemoryUsage(ctx context.Context) error {
 			// Run garbage collection
 			runtime.GC()
 			// Return if we're below max memory
-			if uint64(server.memUsed) < server.config.MaxMemory {
+			if uint64(server.memUsed) < conf.MaxMemory {
 				return nil
 			}
 		}
-	case slices.Contains([]string{constants.AllKeysRandom}, strings.ToLower(server.config.EvictionPolicy)):
+	case slices.Contains([]string{constants.AllKeysRandom}, strings.ToLower(conf.EvictionPolicy)):
 		// Remove random keys until we're below the max memory limit
 		// or there are no more keys remaining.
 		for {
@@ -556,7 +810,7 @@ func (server *SugarDB) adjustMemoryUsage(ctx context.Context) error {
 							// Run garbage collection
 							runtime.GC()
 							// Return if we're below max memory
-							if uint64(server.memUsed) < server.config.MaxMemory {
+							if uint64(server.memUsed) < conf.MaxMemory {
 								return nil
 							}
 						}
@@ -565,7 +819,7 @@ func (server *SugarDB) adjustMemoryUsage(ctx context.Context) error {
 				}
 			}
 		}
-	case slices.Contains([]string{constants.VolatileRandom}, strings.ToLower(server.config.EvictionPolicy)):
+	case slices.Contains([]string{constants.VolatileRandom}, strings.ToLower(conf.EvictionPolicy)):
 		// Remove random keys with an associated expiry time until we're below the max memory limit
 		// or there are no more keys with expiry time.
 		for {
@@ -592,7 +846,7 @@ func (server *SugarDB) adjustMemoryUsage(ctx context.Context) error {
 			// Run garbage collection
 			runtime.GC()
 			// Return if we're below max memory
-			if uint64(server.memUsed) < server.config.MaxMemory {
+			if uint64(server.memUsed) < conf.MaxMemory {
 				return nil
 			}
 		}
@@ -612,19 +866,23 @@ func (server *SugarDB) evictKeysWithExpiredTTL(ctx context.Context) error {
 		return nil
 	}
 
+	ctx = withKeyEvent(ctx, keyEventExpired)
+
+	// Each sampling round is tracked by the latency monitor, excluding the rounds that follow it.
+	start := time.Now()
+
 	server.keysWithExpiry.rwMutex.RLock()
 
 	database := ctx.Value("Database").(int)
 
 	// Sample size should be the configured sample size, or the size of the keys with expiry,
 	// whichever one is smaller.
-	sampleSize := int(server.config.EvictionSample)
+	sampleSize := int(server.getConfig().EvictionSample)
 	if len(server.keysWithExpiry.keys[database]) < sampleSize {
-		sampleSize = len(server.keysWithExpiry.keys)
+		sampleSize = len(server.keysWithExpiry.keys[database])
 	}
 	keys := make([]string, sampleSize)
 
-	deletedCount := 0
 	thresholdPercentage := 20
 
 	var idx int
@@ -632,7 +890,7 @@ func (server *SugarDB) evictKeysWithExpiredTTL(ctx context.Context) error {
 	for i := 0; i < len(keys); i++ {
 		for {
 			// Retry retrieval of a random key until we find a key that is not already in the list of sampled keys.
-			idx = rand.Intn(len(server.keysWithExpiry.keys))
+			idx = rand.Intn(len(server.keysWithExpiry.keys[database]))
 			key = server.keysWithExpiry.keys[database][idx]
 			if !slices.Contains(keys, key) {
 				keys[i] = key
@@ -642,22 +900,34 @@ func (server *SugarDB) evictKeysWithExpiredTTL(ctx context.Context) error {
 	}
 	server.keysWithExpiry.rwMutex.RUnlock()
 
-	// Loop through the keys and delete them if they're expired
+	// Loop through the keys and delete them if they're expired.
+	// In a cluster, the store lock is released before the deletions are applied through the raft log
+	// because applying them acquires the lock.
 	server.storeLock.Lock()
-	defer server.storeLock.Unlock()
+	expiredKeys := make([]string, 0, len(keys))
 	for _, k := range keys {
-		// Delete the expired key
-		deletedCount += 1
-		if !server.isInCluster() {
+		if server.isExpired(server.store[database][k]) {
+			expiredKeys = append(expiredKeys, k)
+		}
+	}
+	if !server.isInCluster() {
+		for _, k := range expiredKeys {
 			if err := server.deleteKey(ctx, k); err != nil {
+				server.storeLock.Unlock()
 				return fmt.Errorf("evictKeysWithExpiredTTL -> standalone delete: %+v", err)
 			}
-		} else if server.isInCluster() && server.raft.IsRaftLeader() {
+		}
+	}
+	server.storeLock.Unlock()
+	if server.isInCluster() && server.raft.IsRaftLeader() {
+		for _, k := range expiredKeys {
 			if err := server.raftApplyDeleteKey(ctx, k); err != nil {
 				return fmt.Errorf("evictKeysWithExpiredTTL -> cluster delete: %+v", err)
 			}
 		}
 	}
+	deletedCount := len(expiredKeys)
+	server.recordEvent(constants.ExpireLatencyEvent, time.Since(start))
 
 	// If sampleSize is 0, there's no need to calculate deleted percentage.
 	if sampleSize == 0 {
@@ -667,9 +937,9 @@ func (server *SugarDB) evictKeysWithExpiredTTL(ctx context.Context) error {
 	log.Printf("%d keys sampled, %d keys deleted\n", sampleSize, deletedCount)
 
 	// If the deleted percentage is over 20% of the sample size, execute the function again immediately.
-	if (deletedCount/sampleSize)*100 >= thresholdPercentage {
+	if deletedCount*100/sampleSize >= thresholdPercentage {
 		log.Printf("deletion ratio (%d percent) reached threshold (%d percent), sampling again\n",
-			(deletedCount/sampleSize)*100, thresholdPercentage)
+			deletedCount*100/sampleSize, thresholdPercentage)
 		return server.evictKeysWithExpiredTTL(ctx)
 	}
 
@@ -677,8 +947,8 @@ func (server *SugarDB) evictKeysWithExpiredTTL(ctx context.Context) error {
 }
 
 func (server *SugarDB) randomKey(ctx context.Context) string {
-	server.storeLock.RLock()
-	defer server.storeLock.RUnlock()
+	server.rLockStore(ctx)
+	defer server.rUnlockStore(ctx)
 
 	database := ctx.Value("Database").(int)
 
@@ -746,3 +1016,56 @@ func (server *SugarDB) getObjectIdleTime(ctx context.Context, key string) (float
 
 	return secs, nil
 }
+
+// seedKeyInCache records the access count or access time that the key gets the next time it's updated in the cache.
+// It must be called before the key is written, so that the write doesn't update the key in the cache. The command then
+// applies the seed with updateKeysInCache once the key is written and its expiry is set.
+func (server *SugarDB) seedKeyInCache(ctx context.Context, key string, seed int64) {
+	// Caches are only updated in standalone mode with a max memory.
+	if server.isInCluster() || server.getConfig().MaxMemory == 0 {
+		return
+	}
+	database := ctx.Value("Database").(int)
+	server.cacheSeeds.mutex.Lock()
+	defer server.cacheSeeds.mutex.Unlock()
+	if server.cacheSeeds.seeds == nil {
+		server.cacheSeeds.seeds = make(map[int]map[string]int64)
+	}
+	if server.cacheSeeds.seeds[database] == nil {
+		server.cacheSeeds.seeds[database] = make(map[string]int64)
+	}
+	server.cacheSeeds.seeds[database][key] = seed
+}
+
+// hasCacheSeed returns true if a seed is recorded for the key by seedKeyInCache.
+func (server *SugarDB) hasCacheSeed(database int, key string) bool {
+	server.cacheSeeds.mutex.Lock()
+	defer server.cacheSeeds.mutex.Unlock()
+	_, ok := server.cacheSeeds.seeds[database][key]
+	return ok
+}
+
+// takeCacheSeed removes and returns the seed recorded for the key by seedKeyInCache.
+func (server *SugarDB) takeCacheSeed(database int, key string) (int64, bool) {
+	server.cacheSeeds.mutex.Lock()
+	defer server.cacheSeeds.mutex.Unlock()
+	seed, ok := server.cacheSeeds.seeds[database][key]
+	if ok {
+		delete(server.cacheSeeds.seeds[database], key)
+	}
+	return seed, ok
+}
+
+func (server *SugarDB) setObjectFreq(ctx context.Context, key string, freq int) {
+	switch strings.ToLower(server.getConfig().EvictionPolicy) {
+	case constants.AllKeysLFU, constants.VolatileLFU:
+		server.seedKeyInCache(ctx, key, int64(freq))
+	}
+}
+
+func (server *SugarDB) setObjectIdleTime(ctx context.Context, key string, idleTime time.Duration) {
+	switch strings.ToLower(server.getConfig().EvictionPolicy) {
+	case constants.AllKeysLRU, constants.VolatileLRU:
+		server.seedKeyInCache(ctx, key, server.clock.Now().Add(-idleTime).UnixMilli())
+	}
+}
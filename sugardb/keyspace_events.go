@@ -0,0 +1,106 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sugardb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/echovault/sugardb/internal"
+	"github.com/echovault/sugardb/internal/config"
+	"github.com/echovault/sugardb/internal/modules/set"
+	"github.com/echovault/sugardb/internal/modules/sorted_set"
+	"github.com/echovault/sugardb/internal/modules/stream"
+)
+
+const (
+	keyEventDel     = "del"
+	keyEventExpired = "expired"
+	keyEventEvicted = "evicted"
+	keyEventExpire  = "expire"
+	keyEventPersist = "persist"
+	keyEventNew     = "new"
+	keyEventKeyMiss = "keymiss"
+)
+
+// withKeyEvent returns a context that reports the keys deleted with it as deleted because of the event.
+func withKeyEvent(ctx context.Context, event string) context.Context {
+	return context.WithValue(ctx, internal.ContextKeyEvent("KeyEvent"), event)
+}
+
+// commandEvent returns the name of the command behind the context. It's empty when the keys are modified outside of
+// a command, like when a snapshot is restored, in which case no notifications are published.
+func commandEvent(ctx context.Context) string {
+	event, _ := ctx.Value(internal.ContextCommand("Command")).(string)
+	return event
+}
+
+// valueEventClass returns the keyspace event class of the commands that modify the value.
+func valueEventClass(value interface{}) int {
+	switch value.(type) {
+	case []string:
+		return config.KeyspaceEventsList
+	case map[string]interface{}:
+		return config.KeyspaceEventsHash
+	case *set.Set:
+		return config.KeyspaceEventsSet
+	case *sorted_set.SortedSet:
+		return config.KeyspaceEventsSortedSet
+	case *stream.Stream:
+		return config.KeyspaceEventsStream
+	default:
+		// Integers, floats, bitmaps and HyperLogLogs are strings.
+		return config.KeyspaceEventsString
+	}
+}
+
+// deleteEventClass returns the event and its class for a key deleted with the context. The event is empty when the
+// key is neither deleted by a command nor because it expired or was evicted.
+func deleteEventClass(ctx context.Context) (string, int) {
+	event, _ := ctx.Value(internal.ContextKeyEvent("KeyEvent")).(string)
+	switch {
+	case event == keyEventExpired:
+		return event, config.KeyspaceEventsExpired
+	case event == keyEventEvicted:
+		return event, config.KeyspaceEventsEvicted
+	case commandEvent(ctx) != "":
+		return keyEventDel, config.KeyspaceEventsGeneric
+	default:
+		return "", 0
+	}
+}
+
+// notifyKeyspaceEvent publishes the event to the __keyspace@<db>__:<key> and __keyevent@<db>__:<event> channels
+// when the class of the event is enabled. The keyspace channel receives the event and the keyevent channel
+// receives the key.
+func (server *SugarDB) notifyKeyspaceEvent(database int, class int, event string, key string) {
+	mask := int(server.keyspaceEvents.Load())
+	if mask&class == 0 {
+		return
+	}
+	if mask&config.KeyspaceEventsKeyspace != 0 {
+		server.pubSub.Publish(server.context, event, fmt.Sprintf("__keyspace@%d__:%s", database, key))
+	}
+	if mask&config.KeyspaceEventsKeyevent != 0 {
+		server.pubSub.Publish(server.context, key, fmt.Sprintf("__keyevent@%d__:%s", database, event))
+	}
+}
+
+// notifyKeyMiss publishes a keymiss event when a command reads a key that does not exist.
+func (server *SugarDB) notifyKeyMiss(ctx context.Context, database int, key string) {
+	if commandEvent(ctx) != "" {
+		server.notifyKeyspaceEvent(database, config.KeyspaceEventsKeyMiss, keyEventKeyMiss, key)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sugardb
+
+import (
+	"github.com/echovault/sugardb/internal"
+	"github.com/echovault/sugardb/internal/constants"
+	"math/bits"
+	"slices"
+	"strings"
+	"time"
+)
+
+// The number of spikes kept for each event. The oldest spikes are dropped first.
+const latencyHistoryLen = 160
+
+// latencyEvent holds the spikes of an event tracked by the latency monitor.
+type latencyEvent struct {
+	samples []internal.LatencySample // The spikes, oldest first.
+	max     int64                    // The highest latency in milliseconds.
+}
+
+// recordLatency records a spike of the event when the latency reaches latency-monitor-threshold.
+// It's called by the persistence engines and raft while they hold their own locks, so the threshold is read from an
+// atomic value instead of the config.
+func (server *SugarDB) recordLatency(event string, latency time.Duration) {
+	threshold := server.latency.threshold.Load()
+	if threshold == 0 || uint64(latency.Milliseconds()) < threshold {
+		return
+	}
+
+	sample := internal.LatencySample{Timestamp: server.clock.Now().Unix(), Latency: latency.Milliseconds()}
+
+	server.latency.mut.Lock()
+	defer server.latency.mut.Unlock()
+
+	if server.latency.events == nil {
+		server.latency.events = make(map[string]*latencyEvent)
+	}
+	stats, ok := server.latency.events[event]
+	if !ok {
+		stats = &latencyEvent{}
+		server.latency.events[event] = stats
+	}
+
+	// The spikes of the same second are merged into one sample with the highest latency.
+	if n := len(stats.samples); n > 0 && stats.samples[n-1].Timestamp == sample.Timestamp {
+		stats.samples[n-1].Latency = max(stats.samples[n-1].Latency, sample.Latency)
+	} else {
+		stats.samples = append(stats.samples, sample)
+		if len(stats.samples) > latencyHistoryLen {
+			stats.samples = slices.Delete(stats.samples, 0, len(stats.samples)-latencyHistoryLen)
+		}
+	}
+	stats.max = max(stats.max, sample.Latency)
+}
+
+// recordCommandLatency records a spike of the command or fast-command event. The blocking commands are not tracked
+// because their execution time includes the time they wait for their keys.
+func (server *SugarDB) recordCommandLatency(command internal.Command, subCommand internal.SubCommand, start time.Time) {
+	if server.latency.threshold.Load() == 0 {
+		return
+	}
+	categories := slices.Concat(command.Categories, subCommand.Categories)
+	if slices.Contains(categories, constants.BlockingCategory) {
+		return
+	}
+	event := constants.CommandLatencyEvent
+	if slices.Contains(categories, constants.FastCategory) {
+		event = constants.FastCommandLatencyEvent
+	}
+	server.recordLatency(event, time.Since(start))
+}
+
+func (server *SugarDB) getLatencyLatest() []internal.LatencyEvent {
+	server.latency.mut.Lock()
+	defer server.latency.mut.Unlock()
+
+	events := make([]internal.LatencyEvent, 0, len(server.latency.events))
+	for name, stats := range server.latency.events {
+		latest := stats.samples[len(stats.samples)-1]
+		events = append(events, internal.LatencyEvent{
+			Name:      name,
+			Timestamp: latest.Timestamp,
+			Latest:    latest.Latency,
+			Max:       stats.max,
+		})
+	}
+	slices.SortFunc(events, func(a, b internal.LatencyEvent) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return events
+}
+
+func (server *SugarDB) getLatencyHistory(event string) []internal.LatencySample {
+	server.latency.mut.Lock()
+	defer server.latency.mut.Unlock()
+
+	stats, ok := server.latency.events[strings.ToLower(event)]
+	if !ok {
+		return []internal.LatencySample{}
+	}
+	return slices.Clone(stats.samples)
+}
+
+func (server *SugarDB) resetLatency(events ...string) int {
+	server.latency.mut.Lock()
+	defer server.latency.mut.Unlock()
+
+	if len(events) == 0 {
+		count := len(server.latency.events)
+		clear(server.latency.events)
+		return count
+	}
+
+	count := 0
+	for _, event := range events {
+		if _, ok := server.latency.events[strings.ToLower(event)]; ok {
+			delete(server.latency.events, strings.ToLower(event))
+			count++
+		}
+	}
+	return count
+}
+
+// latencyBucket returns the index of the histogram bucket of the duration.
+// Bucket i counts the executions that took more than 2^(i-1) and at most 2^i microseconds.
+func latencyBucket(duration time.Duration) int {
+	usec := duration.Microseconds()
+	if usec <= 1 {
+		return 0
+	}
+	return bits.Len64(uint64(usec - 1))
+}
+
+func (server *SugarDB) getLatencyHistograms(commands ...string) []internal.LatencyHistogram {
+	server.stats.commandsMut.Lock()
+	defer server.stats.commandsMut.Unlock()
+
+	names := make([]string, 0, len(server.stats.commands))
+	for name, stats := range server.stats.commands {
+		if stats.calls == 0 {
+			continue
+		}
+		if len(commands) > 0 && !slices.ContainsFunc(commands, func(command string) bool {
+			return strings.EqualFold(command, name)
+		}) {
+			continue
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	histograms := make([]internal.LatencyHistogram, 0, len(names))
+	for _, name := range names {
+		stats := server.stats.commands[name]
+		histogram := internal.LatencyHistogram{Command: name, Calls: stats.calls}
+		// The buckets go from the fastest to the slowest execution.
+		first := slices.IndexFunc(stats.histogram[:], func(count uint64) bool { return count > 0 })
+		var count uint64
+		for i := first; first >= 0 && count < stats.calls; i++ {
+			count += stats.histogram[i]
+			histogram.Buckets = append(histogram.Buckets, internal.LatencyHistogramBucket{Usec: 1 << i, Count: count})
+		}
+		histograms = append(histograms, histogram)
+	}
+	return histograms
+}
@@ -0,0 +1,308 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sugardb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The histogram buckets of the metrics are the latency buckets with an even index, from 1 microsecond to
+// 2^metricsMaxBucket microseconds (about 16.8 seconds).
+const metricsMaxBucket = 24
+
+// startMetrics starts the HTTP listener that serves the metrics at /metrics. The listener is created before
+// returning, so that ShutDown always closes it.
+func (server *SugarDB) startMetrics() {
+	conf := server.getConfig()
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", conf.BindAddr, conf.MetricsPort))
+	if err != nil {
+		log.Printf("metrics listener error: %v\n", err)
+		return
+	}
+	log.Printf("Starting metrics server at Address %s, Port %d...\n", conf.BindAddr, conf.MetricsPort)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", server.handleMetrics)
+	metricsServer := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	server.metricsServer.Store(metricsServer)
+
+	go func() {
+		if err := metricsServer.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("metrics listener error: %v\n", err)
+		}
+	}()
+}
+
+func (server *SugarDB) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write([]byte(server.getMetrics()))
+}
+
+// metricsWriter writes metrics in the Prometheus text format.
+type metricsWriter struct {
+	strings.Builder
+}
+
+// family writes the HELP and TYPE lines of a metric. They must be followed by all the samples of the metric.
+func (w *metricsWriter) family(name, kind, help string) {
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sample writes a sample of a metric. The labels are given as name and value pairs.
+func (w *metricsWriter) sample(name string, value string, labels ...string) {
+	w.WriteString(name)
+	if len(labels) > 0 {
+		w.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			_, _ = fmt.Fprintf(w, "%s=\"%s\"", labels[i], escapeLabelValue(labels[i+1]))
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(value)
+	w.WriteByte('\n')
+}
+
+// metric writes a metric with a single sample.
+func (w *metricsWriter) metric(name, kind, help string, value string) {
+	w.family(name, kind, help)
+	w.sample(name, value)
+}
+
+// histogram writes the samples of a histogram from the latency buckets, the count and the sum of the durations.
+func (w *metricsWriter) histogram(name string, buckets [64]uint64, count uint64, sum time.Duration, labels ...string) {
+	var cumulative uint64
+	for i := 0; i <= metricsMaxBucket; i++ {
+		cumulative += buckets[i]
+		if i%2 == 0 {
+			le := formatSeconds(time.Duration(1<<i) * time.Microsecond)
+			w.sample(name+"_bucket", strconv.FormatUint(cumulative, 10), append(slices.Clone(labels), "le", le)...)
+		}
+	}
+	w.sample(name+"_bucket", strconv.FormatUint(count, 10), append(slices.Clone(labels), "le", "+Inf")...)
+	w.sample(name+"_sum", formatSeconds(sum), labels...)
+	w.sample(name+"_count", strconv.FormatUint(count, 10), labels...)
+}
+
+// escapeLabelValue escapes the backslashes, double quotes and line feeds of a label value.
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatSeconds(duration time.Duration) string {
+	return strconv.FormatFloat(duration.Seconds(), 'g', -1, 64)
+}
+
+// getMetrics returns the metrics of the server in the Prometheus text format.
+func (server *SugarDB) getMetrics() string {
+	w := &metricsWriter{}
+
+	info := server.GetServerInfo()
+	w.family("sugardb_info", "gauge", "Information about the SugarDB server.")
+	w.sample("sugardb_info", "1", "version", info.Version, "mode", info.Mode, "role", info.Role)
+	w.metric("sugardb_uptime_seconds", "gauge", "Number of seconds since the server was started.",
+		strconv.FormatInt(int64(server.clock.Now().Sub(server.stats.startTime).Seconds()), 10))
+
+	server.writeClientMetrics(w)
+	server.writeCommandMetrics(w)
+	server.writeMemoryMetrics(w)
+	server.writeKeyspaceMetrics(w)
+	server.writePubSubMetrics(w)
+	server.writePersistenceMetrics(w)
+	server.writeRaftMetrics(w)
+
+	return w.String()
+}
+
+func (server *SugarDB) writeClientMetrics(w *metricsWriter) {
+	server.connInfo.mut.RLock()
+	connected := len(server.connInfo.tcpClients)
+	server.connInfo.mut.RUnlock()
+
+	w.metric("sugardb_connected_clients", "gauge", "Number of connected TCP clients.", strconv.Itoa(connected))
+	w.metric("sugardb_connections_received_total", "counter", "Total number of TCP connections accepted.",
+		strconv.FormatUint(server.stats.connectionsReceived.Load(), 10))
+}
+
+func (server *SugarDB) writeCommandMetrics(w *metricsWriter) {
+	w.metric("sugardb_commands_processed_total", "counter", "Total number of commands executed.",
+		strconv.FormatUint(server.stats.commandsProcessed.Load(), 10))
+
+	server.stats.commandsMut.Lock()
+	defer server.stats.commandsMut.Unlock()
+
+	names := make([]string, 0, len(server.stats.commands))
+	for name := range server.stats.commands {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	w.family("sugardb_command_calls_total", "counter", "Total number of executions of each command.")
+	for _, name := range names {
+		w.sample("sugardb_command_calls_total", strconv.FormatUint(server.stats.commands[name].calls, 10), "command", name)
+	}
+	w.family("sugardb_command_failed_calls_total", "counter", "Total number of executions of each command that returned an error.")
+	for _, name := range names {
+		w.sample("sugardb_command_failed_calls_total", strconv.FormatUint(server.stats.commands[name].failedCalls, 10), "command", name)
+	}
+	w.family("sugardb_command_rejected_calls_total", "counter", "Total number of times each command was rejected before it was executed.")
+	for _, name := range names {
+		w.sample("sugardb_command_rejected_calls_total", strconv.FormatUint(server.stats.commands[name].rejectedCalls, 10), "command", name)
+	}
+	w.family("sugardb_command_duration_seconds", "histogram", "Execution time of each command.")
+	for _, name := range names {
+		stats := server.stats.commands[name]
+		w.histogram("sugardb_command_duration_seconds", stats.histogram, stats.calls, stats.duration, "command", name)
+	}
+}
+
+func (server *SugarDB) writeMemoryMetrics(w *metricsWriter) {
+	server.rLockStore(server.context)
+	used := server.memUsed
+	server.rUnlockStore(server.context)
+
+	w.metric("sugardb_memory_used_bytes", "gauge", "Memory used by the keyspace in bytes.", strconv.FormatInt(used, 10))
+	w.metric("sugardb_memory_max_bytes", "gauge", "The max-memory setting in bytes. The memory is not limited when it's 0.",
+		strconv.FormatUint(server.getConfig().MaxMemory, 10))
+	w.metric("sugardb_evicted_keys_total", "counter", "Total number of keys evicted because the memory used exceeded max-memory.",
+		strconv.FormatUint(server.stats.evictedKeys.Load(), 10))
+}
+
+func (server *SugarDB) writeKeyspaceMetrics(w *metricsWriter) {
+	w.metric("sugardb_expired_keys_total", "counter", "Total number of keys deleted because they expired.",
+		strconv.FormatUint(server.stats.expiredKeys.Load(), 10))
+	w.metric("sugardb_keyspace_hits_total", "counter", "Total number of keys found by the read commands.",
+		strconv.FormatUint(server.stats.keyspaceHits.Load(), 10))
+	w.metric("sugardb_keyspace_misses_total", "counter", "Total number of keys not found by the read commands.",
+		strconv.FormatUint(server.stats.keyspaceMisses.Load(), 10))
+
+	server.rLockStore(server.context)
+	databases := make([]int, 0, len(server.store))
+	for database := range server.store {
+		databases = append(databases, database)
+	}
+	slices.Sort(databases)
+	keys := make([]int, len(databases))
+	expires := make([]int, len(databases))
+	for i, database := range databases {
+		keys[i] = len(server.store[database])
+		for _, entry := range server.store[database] {
+			if entry.ExpireAt != (time.Time{}) {
+				expires[i]++
+			}
+		}
+	}
+	server.rUnlockStore(server.context)
+
+	w.family("sugardb_db_keys", "gauge", "Number of keys in each database.")
+	for i, database := range databases {
+		w.sample("sugardb_db_keys", strconv.Itoa(keys[i]), "db", strconv.Itoa(database))
+	}
+	w.family("sugardb_db_expiring_keys", "gauge", "Number of keys with a TTL in each database.")
+	for i, database := range databases {
+		w.sample("sugardb_db_expiring_keys", strconv.Itoa(expires[i]), "db", strconv.Itoa(database))
+	}
+}
+
+func (server *SugarDB) writePubSubMetrics(w *metricsWriter) {
+	channels, patterns, subscriptions := 0, 0, 0
+	subscribers := make(map[*net.Conn]struct{})
+	for _, channel := range server.pubSub.GetAllChannels() {
+		channelSubscribers := channel.Subscribers()
+		if len(channelSubscribers) == 0 {
+			continue
+		}
+		if channel.Pattern() != nil {
+			patterns++
+		} else {
+			channels++
+		}
+		subscriptions += len(channelSubscribers)
+		for conn := range channelSubscribers {
+			subscribers[conn] = struct{}{}
+		}
+	}
+
+	w.metric("sugardb_pubsub_channels", "gauge", "Number of channels with at least one subscriber.", strconv.Itoa(channels))
+	w.metric("sugardb_pubsub_patterns", "gauge", "Number of patterns with at least one subscriber.", strconv.Itoa(patterns))
+	w.metric("sugardb_pubsub_subscribers", "gauge", "Number of clients subscribed to at least one channel or pattern.",
+		strconv.Itoa(len(subscribers)))
+	w.metric("sugardb_pubsub_subscriptions", "gauge", "Number of subscriptions to the channels and patterns.",
+		strconv.Itoa(subscriptions))
+}
+
+func (server *SugarDB) writePersistenceMetrics(w *metricsWriter) {
+	changes := uint64(0)
+	if server.snapshotEngine != nil {
+		changes = server.snapshotEngine.ChangeCount()
+	}
+	w.metric("sugardb_changes_since_last_save", "gauge", "Number of changes since the latest snapshot.",
+		strconv.FormatUint(changes, 10))
+	w.metric("sugardb_last_save_timestamp_seconds", "gauge", "Unix time of the latest snapshot.",
+		strconv.FormatInt(server.latestSnapshotMilliseconds.Load()/1000, 10))
+
+	server.stats.eventsMut.Lock()
+	defer server.stats.eventsMut.Unlock()
+
+	events := make([]string, 0, len(server.stats.events))
+	for event := range server.stats.events {
+		events = append(events, event)
+	}
+	slices.Sort(events)
+
+	w.family("sugardb_event_duration_seconds", "histogram",
+		"Duration of the aof-fsync, snapshot, raft-apply, eviction-cycle and expire-cycle events.")
+	for _, event := range events {
+		stats := server.stats.events[event]
+		w.histogram("sugardb_event_duration_seconds", stats.histogram, stats.count, stats.duration, "event", event)
+	}
+}
+
+// writeRaftMetrics writes the raft metrics. They're only reported in cluster mode.
+func (server *SugarDB) writeRaftMetrics(w *metricsWriter) {
+	if !server.isInCluster() {
+		return
+	}
+
+	info := server.raft.Info()
+	leader := "0"
+	if info.State == "Leader" {
+		leader = "1"
+	}
+	w.metric("sugardb_raft_leader", "gauge", "Whether this node is the raft leader.", leader)
+	w.metric("sugardb_raft_term", "gauge", "The current raft term.", strconv.FormatUint(info.Term, 10))
+	w.metric("sugardb_raft_commit_index", "gauge", "The index of the latest committed raft log entry.",
+		strconv.FormatUint(info.CommitIndex, 10))
+	w.metric("sugardb_raft_applied_index", "gauge", "The index of the latest raft log entry applied to the keyspace.",
+		strconv.FormatUint(info.AppliedIndex, 10))
+	w.metric("sugardb_raft_peers", "gauge", "Number of servers in the raft configuration.", strconv.Itoa(len(info.Peers)))
+}
@@ -23,7 +23,9 @@ import (
 	"github.com/echovault/sugardb/internal/constants"
 	"io"
 	"net"
+	"slices"
 	"strings"
+	"time"
 )
 
 func (server *SugarDB) getCommand(cmd string) (internal.Command, error) {
@@ -38,15 +40,21 @@ func (server *SugarDB) getCommand(cmd string) (internal.Command, error) {
 }
 
 func (server *SugarDB) getHandlerFuncParams(ctx context.Context, cmd []string, conn *net.Conn) internal.HandlerFuncParams {
+	// The name of the command is the event of the keyspace notifications for the keys it modifies.
+	ctx = context.WithValue(ctx, internal.ContextCommand("Command"), strings.ToLower(cmd[0]))
 	return internal.HandlerFuncParams{
 		Context:               ctx,
 		Command:               cmd,
 		Connection:            conn,
 		KeysExist:             server.keysExist,
 		GetExpiry:             server.getExpiry,
+		GetKeys:               server.getKeys,
 		GetValues:             server.getValues,
 		SetValues:             server.setValues,
 		SetExpiry:             server.setExpiry,
+		GetKeyVersions:        server.getKeyVersionsOf,
+		DeleteKeyIfVersion:    server.deleteKeyIfVersion,
+		Propagate:             func(cmds ...[]string) { propagate(ctx, cmds) },
 		TakeSnapshot:          server.takeSnapshot,
 		GetLatestSnapshotTime: server.getLatestSnapshotTime,
 		RewriteAOF:            server.rewriteAOF,
@@ -56,24 +64,63 @@ func (server *SugarDB) getHandlerFuncParams(ctx context.Context, cmd []string, c
 		GetPubSub:             server.getPubSub,
 		GetACL:                server.getACL,
 		GetAllCommands:        server.getCommands,
-		GetClock:              server.getClock,
-		Flush:                 server.Flush,
+		GetClock:              func() clock.Clock { return server.getContextClock(ctx) },
 		Randomkey:             server.randomKey,
 		Touchkey:              server.updateKeysInCache,
 		GetObjectFrequency:    server.getObjectFreq,
 		GetObjectIdleTime:     server.getObjectIdleTime,
-		SwapDBs:               server.SwapDBs,
+		SetObjectFrequency:    server.setObjectFreq,
+		SetObjectIdleTime:     server.setObjectIdleTime,
 		GetServerInfo:         server.GetServerInfo,
+		GetInfo: func(sections ...string) []internal.InfoSection {
+			return server.getInfo(ctx, sections...)
+		},
+		GetConfig:            server.getConfigParameters,
+		SetConfig:            server.setConfigParameters,
+		RewriteConfig:        server.rewriteConfig,
+		ResetStats:           server.resetStats,
+		GetSlowlog:           server.getSlowlog,
+		SlowlogLen:           server.slowlogLen,
+		ResetSlowlog:         server.resetSlowlog,
+		Monitor:              server.startMonitor,
+		GetLatencyLatest:     server.getLatencyLatest,
+		GetLatencyHistory:    server.getLatencyHistory,
+		ResetLatency:         server.resetLatency,
+		GetLatencyHistograms: server.getLatencyHistograms,
+		StartTransaction:     server.startTransaction,
+		ExecTransaction:      server.execTransaction,
+		DiscardTransaction:   server.discardTransaction,
+		WatchKeys:            server.watchKeys,
+		UnwatchKeys:          server.unwatchKeys,
+		EvalScript:           server.evalScript,
+		LoadScript:           server.loadScript,
+		GetScript:            server.getScript,
+		FlushScripts:         server.flushScripts,
+		KillScript:           server.killScript,
+		BlockOnKeys:          server.blockOnKeys,
+		IsFirstBlocked:       server.isFirstBlocked,
+		SwapDBs: func(database1, database2 int) {
+			server.swapDBs(ctx, database1, database2)
+		},
+		Flush: func(database int) {
+			server.flush(ctx, database)
+		},
 		DeleteKey: func(ctx context.Context, key string) error {
-			server.storeLock.Lock()
-			defer server.storeLock.Unlock()
+			server.lockStore(ctx)
+			defer server.unlockStore(ctx)
 			return server.deleteKey(ctx, key)
 		},
-		GetConnectionInfo: func(conn *net.Conn) internal.ConnectionInfo {
-			server.connInfo.mut.RLock()
-			defer server.connInfo.mut.RUnlock()
-			return server.connInfo.tcpClients[conn]
-		},
+		GetConnectionInfo: server.getConnectionInfo,
+		SetClientTracking: server.setClientTracking,
+		SetClientCaching:  server.setClientCaching,
+		GetClientTracking: server.getClientTracking,
+		ListClients:       server.listClients,
+		KillClients:       server.killClients,
+		SetClientName:     server.setClientName,
+		PauseClients:      server.pauseClients,
+		UnpauseClients:    server.unpauseClients,
+		SetClientNoEvict:  server.setClientNoEvict,
+		SetClientReply:    server.setClientReply,
 		SetConnectionInfo: func(conn *net.Conn, clientname string, protocol int, database int) {
 			server.connInfo.mut.Lock()
 			defer server.connInfo.mut.Unlock()
@@ -89,11 +136,11 @@ func (server *SugarDB) getHandlerFuncParams(ctx context.Context, cmd []string, c
 			}
 
 			// If the database index does not exist, create the new database.
-			server.storeLock.Lock()
+			server.lockStore(ctx)
 			if server.store[database] == nil {
 				server.createDatabase(database)
 			}
-			server.storeLock.Unlock()
+			server.unlockStore(ctx)
 
 			// Set database index for the current connection.
 			info.Database = database
@@ -103,7 +150,7 @@ func (server *SugarDB) getHandlerFuncParams(ctx context.Context, cmd []string, c
 	}
 }
 
-func (server *SugarDB) handleCommand(ctx context.Context, message []byte, conn *net.Conn, replay bool, embedded bool) ([]byte, error) {
+func (server *SugarDB) handleCommand(ctx context.Context, message []byte, conn *net.Conn, replay bool, embedded bool) (res []byte, err error) {
 	// Prepare context before processing the command.
 	server.connInfo.mut.RLock()
 	if embedded && !replay {
@@ -121,6 +168,11 @@ func (server *SugarDB) handleCommand(ctx context.Context, message []byte, conn *
 	}
 	server.connInfo.mut.RUnlock()
 
+	if replay {
+		// A replayed blocking command returns what it returned originally without waiting.
+		ctx = context.WithValue(ctx, internal.ContextNoBlock("NoBlock"), true)
+	}
+
 	cmd, err := internal.Decode(message)
 	if err != nil {
 		return nil, err
@@ -135,6 +187,13 @@ func (server *SugarDB) handleCommand(ctx context.Context, message []byte, conn *
 		return nil, io.EOF
 	}
 
+	// If the client has a transaction in progress, queue the command instead of executing it.
+	if !replay && !slices.Contains([]string{"multi", "exec", "discard", "watch"}, strings.ToLower(cmd[0])) {
+		if tx := server.getTransaction(conn); tx != nil {
+			return server.queueCommand(tx, cmd, conn, embedded)
+		}
+	}
+
 	command, err := server.getCommand(cmd[0])
 	if err != nil {
 		return nil, err
@@ -153,6 +212,23 @@ func (server *SugarDB) handleCommand(ctx context.Context, message []byte, conn *
 		handler = subCommand.HandlerFunc
 	}
 
+	// Send the command to the monitors if it was executed. The commands replayed from the AOF are included.
+	// Then add it to the command stats reported by INFO, and to the slow log if it was executed.
+	var start time.Time
+	defer func() {
+		if !start.IsZero() {
+			server.feedMonitors(ctx, conn, cmd)
+		}
+		if replay {
+			return
+		}
+		server.recordCommand(command, subCommand, start, err)
+		if !start.IsZero() {
+			server.recordSlowlog(conn, cmd, start)
+			server.recordCommandLatency(command, subCommand, start)
+		}
+	}()
+
 	if conn != nil && server.acl != nil && !embedded {
 		// Authorize connection if it's provided and if ACL module is present
 		// and the embedded parameter is false.
@@ -161,6 +237,14 @@ func (server *SugarDB) handleCommand(ctx context.Context, message []byte, conn *
 		}
 	}
 
+	if conn != nil && !embedded && !replay {
+		server.setClientCommand(conn, command, subCommand)
+		// Wait for CLIENT PAUSE to end before executing the command.
+		if err = server.waitForPause(ctx, conn, command, subCommand); err != nil {
+			return nil, err
+		}
+	}
+
 	// If the command is a write command, wait for state copy to finish.
 	if internal.IsWriteCommand(command, subCommand) {
 		for {
@@ -171,16 +255,29 @@ func (server *SugarDB) handleCommand(ctx context.Context, message []byte, conn *
 		}
 	}
 
+	if categories := slices.Concat(command.Categories, subCommand.Categories); slices.Contains(categories, constants.ReadCategory) &&
+		!internal.IsWriteCommand(command, subCommand) {
+		ctx = context.WithValue(ctx, internal.ContextReadCommand("ReadCommand"), true)
+	}
+
+	// The execution of the command starts now. Errors returned before this point reject the command.
+	start = time.Now()
+
 	if !server.isInCluster() || !synchronize {
+		server.trackReadKeys(conn, cmd, command, subCommand)
+		ctx, prop := withPropagation(ctx)
 		res, err := handler(server.getHandlerFuncParams(ctx, cmd, conn))
-		if err != nil {
-			return nil, err
-		}
+		server.resetTrackingCaching(conn, cmd)
 
-		if internal.IsWriteCommand(command, subCommand) && !replay {
+		// The commands propagated by the handler are logged even if it fails, as they are the changes it made.
+		if (err == nil || prop.set) && internal.IsWriteCommand(command, subCommand) && !replay {
 			server.connInfo.mut.RLock()
-			server.aofEngine.LogCommand(server.connInfo.tcpClients[conn].Database, message)
+			database := server.connInfo.tcpClients[conn].Database
 			server.connInfo.mut.RUnlock()
+			server.logCommand(database, message, prop)
+		}
+		if err != nil {
+			return nil, err
 		}
 
 		server.stateMutationInProgress.Store(false)
@@ -190,6 +287,10 @@ func (server *SugarDB) handleCommand(ctx context.Context, message []byte, conn *
 
 	// Handle other commands that need to be synced across the cluster
 	if server.raft.IsRaftLeader() {
+		if slices.Contains(command.Categories, constants.BlockingCategory) {
+			// Blocking commands wait on the leader and apply themselves through the raft log once they can make progress.
+			return handler(server.getHandlerFuncParams(server.raftApplyContext(ctx, cmd), cmd, conn))
+		}
 		var res []byte
 		res, err = server.raftApplyCommand(ctx, cmd)
 		if err != nil {
@@ -207,6 +308,37 @@ func (server *SugarDB) handleCommand(ctx context.Context, message []byte, conn *
 	return nil, errors.New("not cluster leader, cannot carry out command")
 }
 
+// propagation holds the commands that a handler propagates to the AOF in place of the command it executes.
+type propagation struct {
+	set      bool       // True if the handler called Propagate.
+	commands [][]string // The commands written to the AOF.
+}
+
+// withPropagation returns a context in which the handler can replace the command written to the AOF.
+func withPropagation(ctx context.Context) (context.Context, *propagation) {
+	prop := &propagation{}
+	return context.WithValue(ctx, internal.ContextPropagate("Propagate"), prop), prop
+}
+
+// propagate records the commands of a handler in the propagation of the context.
+func propagate(ctx context.Context, cmds [][]string) {
+	if prop, ok := ctx.Value(internal.ContextPropagate("Propagate")).(*propagation); ok {
+		prop.set = true
+		prop.commands = cmds
+	}
+}
+
+// logCommand writes the command to the AOF, or the commands that the handler propagated in its place.
+func (server *SugarDB) logCommand(database int, message []byte, prop *propagation) {
+	if !prop.set {
+		server.aofEngine.LogCommand(database, message)
+		return
+	}
+	for _, cmd := range prop.commands {
+		server.aofEngine.LogCommand(database, internal.EncodeCommand(cmd))
+	}
+}
+
 func (server *SugarDB) getCommands() []internal.Command {
 	return server.commands
 }
@@ -222,3 +354,12 @@ func (server *SugarDB) getPubSub() interface{} {
 func (server *SugarDB) getClock() clock.Clock {
 	return server.clock
 }
+
+// getContextClock returns the clock of the handlers. The commands applied from the raft log use a clock stopped at the
+// leader's time.
+func (server *SugarDB) getContextClock(ctx context.Context) clock.Clock {
+	if t, ok := ctx.Value(internal.ContextRaftTime("RaftTime")).(time.Time); ok {
+		return clock.FixedClock{Time: t}
+	}
+	return server.clock
+}
@@ -0,0 +1,245 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sugardb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/echovault/sugardb/internal"
+	"net"
+	"slices"
+	"strings"
+)
+
+// The number of commands buffered for each monitor. The commands sent to a monitor whose buffer is full are dropped,
+// so that a slow monitor never holds up the commands.
+const monitorBufferSize = 1024
+
+// monitor receives the commands processed by the server.
+type monitor struct {
+	conn   *net.Conn         // The TCP connection in MONITOR mode. It's nil for the monitors of the embedded API.
+	events chan MonitorEvent // The commands sent to the monitor. The channel is closed when the monitor is removed.
+}
+
+func (server *SugarDB) addMonitor(conn *net.Conn) *monitor {
+	server.monitors.mut.Lock()
+	defer server.monitors.mut.Unlock()
+
+	if server.monitors.subscribers == nil {
+		server.monitors.subscribers = make(map[*monitor]struct{})
+	}
+	m := &monitor{conn: conn, events: make(chan MonitorEvent, monitorBufferSize)}
+	server.monitors.subscribers[m] = struct{}{}
+	return m
+}
+
+func (server *SugarDB) removeMonitor(m *monitor) {
+	server.monitors.mut.Lock()
+	defer server.monitors.mut.Unlock()
+
+	if _, ok := server.monitors.subscribers[m]; ok {
+		delete(server.monitors.subscribers, m)
+		close(m.events)
+	}
+}
+
+// removeConnectionMonitors is called when the connection is closed. It stops sending commands to the connection.
+func (server *SugarDB) removeConnectionMonitors(conn *net.Conn) {
+	server.monitors.mut.RLock()
+	monitors := make([]*monitor, 0)
+	for m := range server.monitors.subscribers {
+		if m.conn == conn {
+			monitors = append(monitors, m)
+		}
+	}
+	server.monitors.mut.RUnlock()
+
+	for _, m := range monitors {
+		server.removeMonitor(m)
+	}
+}
+
+// isMonitoring returns true if the TCP connection is in MONITOR mode.
+func (server *SugarDB) isMonitoring(conn *net.Conn) bool {
+	server.monitors.mut.RLock()
+	defer server.monitors.mut.RUnlock()
+	for m := range server.monitors.subscribers {
+		if m.conn == conn {
+			return true
+		}
+	}
+	return false
+}
+
+// startMonitor puts the TCP connection in MONITOR mode.
+// The OK reply is written while holding the write lock of the connection, before the monitor is added,
+// so that the client receives it before the first command.
+func (server *SugarDB) startMonitor(conn *net.Conn) error {
+	state := server.getConnectionState(conn)
+	if state == nil {
+		return errors.New("MONITOR is only supported on TCP connections, use the Monitor method instead")
+	}
+
+	state.writer.Lock()
+	defer state.writer.Unlock()
+
+	if _, err := (*conn).Write([]byte("+OK\r\n")); err != nil {
+		return err
+	}
+	if server.isMonitoring(conn) {
+		return nil
+	}
+
+	m := server.addMonitor(conn)
+	go func() {
+		for event := range m.events {
+			server.writePush(conn, []byte(fmt.Sprintf("+%s\r\n", event)))
+		}
+	}()
+	return nil
+}
+
+// feedMonitors sends the command to the monitors. It never blocks: the command is dropped for the monitors
+// whose buffer is full.
+func (server *SugarDB) feedMonitors(ctx context.Context, conn *net.Conn, cmd []string) {
+	server.monitors.mut.RLock()
+	empty := len(server.monitors.subscribers) == 0
+	server.monitors.mut.RUnlock()
+	// The MONITOR command itself is not sent, like in Redis.
+	if empty || strings.EqualFold(cmd[0], "monitor") {
+		return
+	}
+
+	database, _ := ctx.Value("Database").(int)
+	event := MonitorEvent{
+		Time:     server.clock.Now(),
+		Database: database,
+		Client:   server.monitorClient(ctx, conn),
+		Args:     redactCommandArgs(cmd),
+	}
+
+	server.monitors.mut.RLock()
+	defer server.monitors.mut.RUnlock()
+	for m := range server.monitors.subscribers {
+		select {
+		case m.events <- event:
+		default:
+		}
+	}
+}
+
+// monitorClient returns the origin of the command shown by MONITOR. It's the address of the TCP client,
+// "embedded" for the embedded API, or the source of the commands that are not sent by a client.
+func (server *SugarDB) monitorClient(ctx context.Context, conn *net.Conn) string {
+	if source, ok := ctx.Value(internal.ContextMonitorSource("MonitorSource")).(string); ok {
+		return source
+	}
+	if conn != nil {
+		if state := server.getConnectionState(conn); state != nil {
+			return state.addr
+		}
+	}
+	return "embedded"
+}
+
+// redactCommandArgs copies the arguments of the command, replacing the passwords they hold.
+// It's used for the commands shown by MONITOR and recorded in the slow log.
+func redactCommandArgs(cmd []string) []string {
+	args := slices.Clone(cmd)
+	redact := func(from, count int) {
+		for i := from; i < min(from+count, len(args)); i++ {
+			args[i] = "(redacted)"
+		}
+	}
+	switch strings.ToLower(args[0]) {
+	case "auth":
+		redact(1, len(args))
+	case "hello":
+		// HELLO [protover [AUTH username password] [SETNAME clientname]]
+		for i := 1; i < len(args)-2; i++ {
+			if strings.EqualFold(args[i], "auth") {
+				redact(i+1, 2)
+				break
+			}
+		}
+	case "migrate":
+		// MIGRATE host port key|"" destination-db timeout [COPY] [REPLACE] [AUTH password | AUTH2 username password]
+		// [KEYS key [key ...]]
+		for i := 6; i < len(args); i++ {
+			if strings.EqualFold(args[i], "keys") {
+				break
+			}
+			if strings.EqualFold(args[i], "auth") {
+				redact(i+1, 1)
+				i++
+			} else if strings.EqualFold(args[i], "auth2") {
+				redact(i+1, 2)
+				i += 2
+			}
+		}
+	case "acl":
+		// ACL SETUSER username [rule [rule ...]]. The >password and <password rules hold the password.
+		if len(args) > 1 && strings.EqualFold(args[1], "setuser") {
+			for i := 3; i < len(args); i++ {
+				if strings.HasPrefix(args[i], ">") || strings.HasPrefix(args[i], "<") {
+					redact(i, 1)
+				}
+			}
+		}
+	case "config":
+		// CONFIG SET parameter value [parameter value ...]
+		if len(args) > 1 && strings.EqualFold(args[1], "set") {
+			for i := 2; i < len(args)-1; i += 2 {
+				if strings.EqualFold(args[i], "password") {
+					redact(i+1, 1)
+				}
+			}
+		}
+	}
+	return args
+}
+
+// quoteMonitorArg quotes the argument like Redis does in the output of MONITOR.
+// The quotes, backslashes and the bytes that aren't printable ASCII characters are escaped.
+func quoteMonitorArg(arg string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(arg); i++ {
+		switch c := arg[i]; c {
+		case '\\', '"':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\n':
+			b.WriteString("\\n")
+		case '\r':
+			b.WriteString("\\r")
+		case '\t':
+			b.WriteString("\\t")
+		case '\a':
+			b.WriteString("\\a")
+		case '\b':
+			b.WriteString("\\b")
+		default:
+			if c < 0x20 || c > 0x7e {
+				b.WriteString(fmt.Sprintf("\\x%02x", c))
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
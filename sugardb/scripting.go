@@ -0,0 +1,389 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sugardb
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/echovault/sugardb/internal"
+	"github.com/echovault/sugardb/internal/constants"
+	"github.com/tidwall/resp"
+	lua "github.com/yuin/gopher-lua"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	errScriptKilled   = errors.New("script killed by user with SCRIPT KILL")
+	errScriptTimedOut = errors.New("script killed after running longer than lua-time-limit")
+)
+
+// runningScript is the script that is being run by evalScript.
+type runningScript struct {
+	cancel  context.CancelCauseFunc // Stops the script with the cause.
+	written bool                    // True once the script called a write command. The script can't be stopped then.
+}
+
+// loadScript adds the script to the script cache and returns its SHA1 digest.
+func (server *SugarDB) loadScript(script string) string {
+	digest := sha1.Sum([]byte(script))
+	sha := hex.EncodeToString(digest[:])
+
+	server.scripts.mut.Lock()
+	defer server.scripts.mut.Unlock()
+	server.scripts.cache[sha] = script
+
+	return sha
+}
+
+// getScript returns the cached script with the SHA1 digest.
+func (server *SugarDB) getScript(sha string) (string, bool) {
+	server.scripts.mut.RLock()
+	defer server.scripts.mut.RUnlock()
+	script, ok := server.scripts.cache[strings.ToLower(sha)]
+	return script, ok
+}
+
+// flushScripts removes all the scripts from the script cache.
+func (server *SugarDB) flushScripts() {
+	server.scripts.mut.Lock()
+	defer server.scripts.mut.Unlock()
+	clear(server.scripts.cache)
+}
+
+// evalScript runs the Lua script while holding the store lock, so no other command can
+// observe or modify the keyspace until the script returns.
+// The script can call any command with redis.call and redis.pcall. When the script is called from a
+// TCP connection, the ACL rules of the connection are applied to each of these calls.
+// A script that runs longer than lua-time-limit is stopped, unless it already called a write command.
+func (server *SugarDB) evalScript(ctx context.Context, conn *net.Conn, script string, keys []string, args []string) ([]byte, error) {
+	limit := time.Duration(server.getConfig().LuaTimeLimit) * time.Millisecond
+
+	server.lockStore(ctx)
+	defer server.unlockStore(ctx)
+
+	ctx = context.WithValue(ctx, internal.ContextTransaction("Transaction"), true)
+
+	L := newLuaState()
+	defer L.Close()
+
+	scriptCtx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	L.SetContext(scriptCtx)
+
+	running := &runningScript{cancel: cancel}
+	server.scripts.mut.Lock()
+	server.scripts.running = running
+	server.scripts.mut.Unlock()
+	defer func() {
+		server.scripts.mut.Lock()
+		server.scripts.running = nil
+		server.scripts.mut.Unlock()
+	}()
+
+	if limit > 0 {
+		timer := time.AfterFunc(limit, func() {
+			server.scripts.mut.Lock()
+			defer server.scripts.mut.Unlock()
+			_ = server.stopScript(running, errScriptTimedOut)
+		})
+		defer timer.Stop()
+	}
+
+	L.SetGlobal("KEYS", luaStringTable(L, keys))
+	L.SetGlobal("ARGV", luaStringTable(L, args))
+
+	call := func(protected bool) lua.LGFunction {
+		return func(L *lua.LState) int {
+			cmd := make([]string, L.GetTop())
+			for i := range cmd {
+				switch arg := L.Get(i + 1).(type) {
+				case lua.LString, lua.LNumber:
+					cmd[i] = arg.String()
+				default:
+					L.RaiseError("command arguments must be strings or integers")
+					return 0
+				}
+			}
+
+			res, err := server.callFromScript(ctx, conn, cmd)
+			if err == nil {
+				var value lua.LValue
+				if value, err = respToLua(L, res); err == nil {
+					L.Push(value)
+					return 1
+				}
+			}
+
+			if !protected {
+				L.RaiseError("%s", err.Error())
+				return 0
+			}
+			L.Push(luaReplyTable(L, "err", err.Error()))
+			return 1
+		}
+	}
+
+	redis := L.NewTable()
+	L.SetFuncs(redis, map[string]lua.LGFunction{
+		"call":  call(false),
+		"pcall": call(true),
+		"sha1hex": func(L *lua.LState) int {
+			digest := sha1.Sum([]byte(L.CheckString(1)))
+			L.Push(lua.LString(hex.EncodeToString(digest[:])))
+			return 1
+		},
+		"error_reply": func(L *lua.LState) int {
+			L.Push(luaReplyTable(L, "err", L.CheckString(1)))
+			return 1
+		},
+		"status_reply": func(L *lua.LState) int {
+			L.Push(luaReplyTable(L, "ok", L.CheckString(1)))
+			return 1
+		},
+	})
+	L.SetGlobal("redis", redis)
+
+	fn, err := L.LoadString(script)
+	if err != nil {
+		return nil, fmt.Errorf("error compiling script: %s", err.Error())
+	}
+	L.Push(fn)
+	if err = L.PCall(0, 1, nil); err != nil {
+		if cause := context.Cause(scriptCtx); cause != nil {
+			return nil, fmt.Errorf("error running script: %s", cause.Error())
+		}
+		var apiErr *lua.ApiError
+		if errors.As(err, &apiErr) {
+			return nil, fmt.Errorf("error running script: %s", apiErr.Object.String())
+		}
+		return nil, fmt.Errorf("error running script: %s", err.Error())
+	}
+
+	value := L.Get(-1)
+	if table, ok := value.(*lua.LTable); ok {
+		// An error reply returned by the script is the error of the EVAL call.
+		if msg, ok := table.RawGetString("err").(lua.LString); ok {
+			return nil, errors.New(string(msg))
+		}
+	}
+
+	return luaToResp(value), nil
+}
+
+// callFromScript executes a command called by a script with redis.call or redis.pcall.
+// The store lock is already held by the script, so the command is executed with the transaction context.
+func (server *SugarDB) callFromScript(ctx context.Context, conn *net.Conn, cmd []string) ([]byte, error) {
+	if len(cmd) == 0 {
+		return nil, errors.New("please specify at least one argument for this call")
+	}
+
+	command, err := server.getCommand(cmd[0])
+	if err != nil {
+		return nil, err
+	}
+
+	handler := command.HandlerFunc
+	keyExtractionFunc := command.KeyExtractionFunc
+
+	sc, err := internal.GetSubCommand(command, cmd)
+	if err != nil {
+		return nil, err
+	}
+	subCommand, ok := sc.(internal.SubCommand)
+	if ok {
+		handler = subCommand.HandlerFunc
+		keyExtractionFunc = subCommand.KeyExtractionFunc
+	}
+
+	// Commands that would nest another transaction or script are not allowed.
+	// Blocking commands are allowed, but they don't block inside a script.
+	categories := slices.Concat(command.Categories, subCommand.Categories)
+	for _, category := range []string{constants.TransactionCategory, constants.ScriptingCategory} {
+		if slices.Contains(categories, category) {
+			return nil, fmt.Errorf("command %s is not allowed from script", cmd[0])
+		}
+	}
+
+	if conn != nil && server.acl != nil {
+		if err = server.acl.AuthorizeConnection(conn, cmd, command, subCommand); err != nil {
+			return nil, err
+		}
+	}
+
+	// The key extraction function validates the number of arguments.
+	if _, err = keyExtractionFunc(cmd); err != nil {
+		return nil, err
+	}
+
+	// A script that modified the keyspace can't be stopped, as it would leave its writes half done.
+	if internal.IsWriteCommand(command, subCommand) {
+		server.scripts.mut.Lock()
+		if server.scripts.running != nil {
+			server.scripts.running.written = true
+		}
+		server.scripts.mut.Unlock()
+	}
+
+	res, err := handler(server.getHandlerFuncParams(ctx, cmd, conn))
+	server.feedMonitors(context.WithValue(ctx, internal.ContextMonitorSource("MonitorSource"), "lua"), conn, cmd)
+	return res, err
+}
+
+// killScript stops the script that is running with SCRIPT KILL.
+func (server *SugarDB) killScript() error {
+	server.scripts.mut.Lock()
+	defer server.scripts.mut.Unlock()
+	return server.stopScript(server.scripts.running, errScriptKilled)
+}
+
+// stopScript stops the script with the cause if it's still running and has not called a write command.
+// The caller must hold the lock of the scripts.
+func (server *SugarDB) stopScript(script *runningScript, cause error) error {
+	if script == nil || script != server.scripts.running {
+		return errors.New("NOTBUSY No scripts in execution right now")
+	}
+	if script.written {
+		return errors.New("UNKILLABLE Sorry the script already executed write commands against the dataset")
+	}
+	script.cancel(cause)
+	return nil
+}
+
+// evalShaAsEval returns EVALSHA as EVAL with the source of the cached script, which is how it's written to the
+// raft log. The nodes that apply the log after the script cache was flushed, or after restoring a snapshot, don't
+// have the script in their cache. The other commands are returned unchanged.
+func (server *SugarDB) evalShaAsEval(cmd []string) ([]string, error) {
+	if len(cmd) < 2 || !strings.EqualFold(cmd[0], "evalsha") {
+		return cmd, nil
+	}
+	script, ok := server.getScript(cmd[1])
+	if !ok {
+		return nil, errors.New("NOSCRIPT No matching script. Please use EVAL")
+	}
+	return slices.Concat([]string{"EVAL", script}, cmd[2:]), nil
+}
+
+// newLuaState returns a Lua state with only the libraries that can't access the host.
+func newLuaState() *lua.LState {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	for _, lib := range []struct {
+		name string
+		fn   lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.fn))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	// Remove the base functions that load code from files.
+	for _, name := range []string{"dofile", "loadfile", "require", "module"} {
+		L.SetGlobal(name, lua.LNil)
+	}
+	return L
+}
+
+func luaStringTable(L *lua.LState, values []string) *lua.LTable {
+	table := L.CreateTable(len(values), 0)
+	for _, value := range values {
+		table.Append(lua.LString(value))
+	}
+	return table
+}
+
+// luaReplyTable returns a table with a single field, like the tables returned by redis.error_reply and redis.status_reply.
+func luaReplyTable(L *lua.LState, field string, msg string) *lua.LTable {
+	table := L.NewTable()
+	table.RawSetString(field, lua.LString(msg))
+	return table
+}
+
+// respToLua converts a command response to a Lua value.
+// Integers are converted to numbers, strings to strings, arrays to tables and nulls to false.
+// Errors are converted to tables with an "err" field.
+// Unlike Redis, simple strings are not converted to status tables because many commands return their values as
+// simple strings.
+func respToLua(L *lua.LState, b []byte) (lua.LValue, error) {
+	v, _, err := resp.NewReader(bytes.NewReader(b)).ReadValue()
+	if err != nil {
+		return nil, err
+	}
+	return respValueToLua(L, v), nil
+}
+
+func respValueToLua(L *lua.LState, v resp.Value) lua.LValue {
+	switch v.Type() {
+	case resp.Integer:
+		return lua.LNumber(v.Integer())
+	case resp.Error:
+		return luaReplyTable(L, "err", v.Error().Error())
+	case resp.Array:
+		if v.IsNull() {
+			return lua.LFalse
+		}
+		table := L.CreateTable(len(v.Array()), 0)
+		for _, element := range v.Array() {
+			table.Append(respValueToLua(L, element))
+		}
+		return table
+	default:
+		if v.IsNull() {
+			return lua.LFalse
+		}
+		return lua.LString(v.String())
+	}
+}
+
+// luaToResp converts the value returned by a script to a RESP response.
+// Numbers are truncated to integers, strings are converted to bulk strings, tables to arrays up to the first nil,
+// true to 1, and false and nil to a null bulk string. Tables with an "ok" or "err" field are converted to
+// simple strings and errors.
+func luaToResp(value lua.LValue) []byte {
+	switch v := value.(type) {
+	case lua.LNumber:
+		return []byte(fmt.Sprintf(":%d\r\n", int64(v)))
+	case lua.LString:
+		return []byte(fmt.Sprintf("$%d\r\n%s\r\n", len(v), string(v)))
+	case lua.LBool:
+		if v {
+			return []byte(":1\r\n")
+		}
+		return []byte("$-1\r\n")
+	case *lua.LTable:
+		if msg, ok := v.RawGetString("err").(lua.LString); ok {
+			return []byte(fmt.Sprintf("-Error %s\r\n", string(msg)))
+		}
+		if msg, ok := v.RawGetString("ok").(lua.LString); ok {
+			return []byte(fmt.Sprintf("+%s\r\n", string(msg)))
+		}
+		var elements [][]byte
+		for i := 1; v.RawGetInt(i) != lua.LNil; i++ {
+			elements = append(elements, luaToResp(v.RawGetInt(i)))
+		}
+		return append([]byte(fmt.Sprintf("*%d\r\n", len(elements))), bytes.Join(elements, nil)...)
+	default:
+		return []byte("$-1\r\n")
+	}
+}
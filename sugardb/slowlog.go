@@ -0,0 +1,113 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sugardb
+
+import (
+	"fmt"
+	"github.com/echovault/sugardb/internal"
+	"net"
+	"slices"
+	"time"
+)
+
+// The limits applied to the arguments of the commands recorded in the slow log.
+const (
+	slowlogMaxArgs   = 32  // The maximum number of arguments kept. The others are replaced by a summary argument.
+	slowlogMaxArgLen = 128 // The maximum length of an argument. Longer arguments are truncated.
+)
+
+// recordSlowlog adds the command to the slow log when its execution time exceeds slowlog-log-slower-than.
+func (server *SugarDB) recordSlowlog(conn *net.Conn, cmd []string, start time.Time) {
+	duration := time.Since(start)
+
+	server.configMut.RLock()
+	threshold, maxLen := server.config.SlowlogLogSlowerThan, int(server.config.SlowlogMaxLen)
+	server.configMut.RUnlock()
+
+	if threshold < 0 || duration.Microseconds() < threshold {
+		return
+	}
+
+	entry := internal.SlowlogEntry{
+		Timestamp: server.clock.Now().Unix(),
+		Duration:  duration.Microseconds(),
+		Args:      truncateSlowlogArgs(redactCommandArgs(cmd)),
+	}
+	if conn != nil {
+		if state := server.getConnectionState(conn); state != nil {
+			entry.ClientAddr = state.addr
+		}
+		entry.ClientName = server.getConnectionInfo(conn).Name
+	} else {
+		server.connInfo.mut.RLock()
+		entry.ClientName = server.connInfo.embedded.Name
+		server.connInfo.mut.RUnlock()
+	}
+
+	server.slowlog.mut.Lock()
+	defer server.slowlog.mut.Unlock()
+
+	entry.Id = server.slowlog.nextId
+	server.slowlog.nextId++
+	// The entries are kept newest first. The oldest entries are dropped once the max length is reached.
+	server.slowlog.entries = slices.Insert(server.slowlog.entries, 0, entry)
+	if len(server.slowlog.entries) > maxLen {
+		server.slowlog.entries = slices.Delete(server.slowlog.entries, maxLen, len(server.slowlog.entries))
+	}
+}
+
+// truncateSlowlogArgs keeps the first slowlogMaxArgs arguments, and the first slowlogMaxArgLen bytes of each of them,
+// so that the slow log does not hold on to large values.
+func truncateSlowlogArgs(cmd []string) []string {
+	count := min(len(cmd), slowlogMaxArgs)
+	if len(cmd) > slowlogMaxArgs {
+		// The last kept argument is replaced by the number of arguments that were left out.
+		count = slowlogMaxArgs - 1
+	}
+
+	args := make([]string, 0, min(len(cmd), slowlogMaxArgs))
+	for _, arg := range cmd[:count] {
+		if len(arg) > slowlogMaxArgLen {
+			arg = fmt.Sprintf("%s... (%d more bytes)", arg[:slowlogMaxArgLen], len(arg)-slowlogMaxArgLen)
+		}
+		args = append(args, arg)
+	}
+	if count < len(cmd) {
+		args = append(args, fmt.Sprintf("... (%d more arguments)", len(cmd)-count))
+	}
+	return args
+}
+
+func (server *SugarDB) getSlowlog(count int) []internal.SlowlogEntry {
+	server.slowlog.mut.Lock()
+	defer server.slowlog.mut.Unlock()
+
+	if count < 0 || count > len(server.slowlog.entries) {
+		count = len(server.slowlog.entries)
+	}
+	return slices.Clone(server.slowlog.entries[:count])
+}
+
+func (server *SugarDB) slowlogLen() int {
+	server.slowlog.mut.Lock()
+	defer server.slowlog.mut.Unlock()
+	return len(server.slowlog.entries)
+}
+
+func (server *SugarDB) resetSlowlog() {
+	server.slowlog.mut.Lock()
+	defer server.slowlog.mut.Unlock()
+	server.slowlog.entries = nil
+}
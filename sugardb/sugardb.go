@@ -29,19 +29,25 @@ import (
 	"github.com/echovault/sugardb/internal/memberlist"
 	"github.com/echovault/sugardb/internal/modules/acl"
 	"github.com/echovault/sugardb/internal/modules/admin"
+	"github.com/echovault/sugardb/internal/modules/bitmap"
 	"github.com/echovault/sugardb/internal/modules/connection"
 	"github.com/echovault/sugardb/internal/modules/generic"
+	"github.com/echovault/sugardb/internal/modules/geo"
 	"github.com/echovault/sugardb/internal/modules/hash"
+	"github.com/echovault/sugardb/internal/modules/hyperloglog"
 	"github.com/echovault/sugardb/internal/modules/list"
 	"github.com/echovault/sugardb/internal/modules/pubsub"
+	"github.com/echovault/sugardb/internal/modules/scripting"
 	"github.com/echovault/sugardb/internal/modules/set"
 	"github.com/echovault/sugardb/internal/modules/sorted_set"
+	"github.com/echovault/sugardb/internal/modules/stream"
 	str "github.com/echovault/sugardb/internal/modules/string"
 	"github.com/echovault/sugardb/internal/raft"
 	"github.com/echovault/sugardb/internal/snapshot"
 	"io"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"sync"
 	"sync/atomic"
@@ -53,7 +59,9 @@ type SugarDB struct {
 	clock clock.Clock
 
 	// config holds the echovault configuration variables.
-	config config.Config
+	// The settings that can be changed with CONFIG SET must be read with getConfig.
+	config    config.Config
+	configMut sync.RWMutex // RWMutex for the config settings that can be changed at runtime.
 
 	// The current index for the latest connection id.
 	// This number is incremented everytime there's a new connection and
@@ -65,9 +73,77 @@ type SugarDB struct {
 	connInfo struct {
 		mut        *sync.RWMutex                         // RWMutex for the connInfo object.
 		tcpClients map[*net.Conn]internal.ConnectionInfo // Map that holds connection information for each TCP client.
+		states     map[*net.Conn]*connectionState        // Map that holds the runtime state of each TCP client.
 		embedded   internal.ConnectionInfo               // Information for the embedded connection.
 	}
 
+	// transactions holds the commands queued by each client that has called MULTI.
+	// The embedded API session is stored under the nil connection.
+	transactions struct {
+		mut     *sync.Mutex                // Mutex for the transactions object.
+		clients map[*net.Conn]*transaction // Map that holds the transaction in progress for each client.
+		watched map[*net.Conn][]watchedKey // Map that holds the keys watched by each client.
+	}
+
+	// scripts holds the Lua scripts loaded with EVAL or SCRIPT LOAD, by their SHA1 digest.
+	scripts struct {
+		mut     *sync.RWMutex     // RWMutex for the scripts object.
+		cache   map[string]string // Map that holds the source of each script.
+		running *runningScript    // The script that is running. Nil when no script is running.
+	}
+
+	// tracking holds the state of the clients that enabled client side caching with CLIENT TRACKING.
+	tracking struct {
+		mut     *sync.Mutex                       // Mutex for the tracking object.
+		clients map[*net.Conn]*trackingClient     // Map that holds the tracking state of each client.
+		keys    map[string]map[*net.Conn]struct{} // The clients that read each key in the default tracking mode.
+	}
+
+	// pause holds the state of CLIENT PAUSE.
+	pause struct {
+		mut       *sync.Mutex   // Mutex for the pause object.
+		writeOnly bool          // True if only the write commands are paused.
+		done      chan struct{} // Closed when the pause ends. Nil when the clients are not paused.
+	}
+
+	// blockedClients holds the clients blocked on each key in each database, waiting for the key to be written.
+	blockedClients struct {
+		mut     *sync.Mutex                        // Mutex for the blockedClients object.
+		waiters map[int]map[string][]chan struct{} // The notification channels of the clients blocked on each key, in the order they blocked.
+	}
+
+	// keyVersions tracks the modification version of the keys so that WATCH can detect changes.
+	// It's guarded by the store lock.
+	keyVersions struct {
+		counter  uint64                    // The latest version handed out.
+		versions map[int]map[string]uint64 // The version of each key in each database.
+		deleted  map[int]uint64            // The version of the latest deletion applied from the raft log in each database.
+		watchers map[int]map[string]int    // The number of clients watching each key in each database.
+	}
+
+	// stats holds the counters reported by INFO.
+	stats serverStats
+
+	// slowlog holds the commands whose execution time exceeded slowlog-log-slower-than.
+	slowlog struct {
+		mut     sync.Mutex
+		nextId  uint64                  // The id of the next entry.
+		entries []internal.SlowlogEntry // The entries, newest first.
+	}
+
+	// latency holds the spikes of the events tracked by the latency monitor.
+	latency struct {
+		threshold atomic.Uint64 // The latency-monitor-threshold setting in milliseconds.
+		mut       sync.Mutex
+		events    map[string]*latencyEvent // The spikes of each event, by event name.
+	}
+
+	// monitors holds the TCP clients in MONITOR mode and the streams of the Monitor method.
+	monitors struct {
+		mut         sync.RWMutex
+		subscribers map[*monitor]struct{} // The monitors that receive the processed commands.
+	}
+
 	// Global read-write mutex for entire store.
 	storeLock *sync.RWMutex
 
@@ -100,6 +176,13 @@ type SugarDB struct {
 		// LRU cache represented by a max heap.
 		cache map[int]*eviction.CacheLRU
 	}
+	// Access information of restored keys, applied instead of an access when the command that restores the key
+	// updates it in the LFU or LRU cache. Holds the access count for LFU policies and the access time in unix
+	// milliseconds for LRU policies.
+	cacheSeeds struct {
+		mutex sync.Mutex
+		seeds map[int]map[string]int64
+	}
 
 	// Holds the list of all commands supported by the echovault.
 	commandsRWMut sync.RWMutex
@@ -118,12 +201,19 @@ type SugarDB struct {
 	stateCopyInProgress        atomic.Bool      // Atomic boolean that's true when actively copying state for snapshotting or preamble generation.
 	stateMutationInProgress    atomic.Bool      // Atomic boolean that is set to true when state mutation is in progress.
 	latestSnapshotMilliseconds atomic.Int64     // Unix epoch in milliseconds.
+	keyspaceEvents             atomic.Int64     // Bit mask of the keyspace event classes to publish.
 	snapshotEngine             *snapshot.Engine // Snapshot engine for standalone mode.
 	aofEngine                  *aof.Engine      // AOF engine for standalone mode.
 
 	listener atomic.Value  // Holds the TCP listener.
 	quit     chan struct{} // Channel that signals the closing of all client connections.
 	stopTTL  chan struct{} // Channel that signals the TTL sampling goroutine to stop execution.
+	ttlDone  chan struct{} // Channel that's closed when the TTL sampling goroutine has stopped.
+	ttlOnce  sync.Once     // Closes stopTTL once, as ShutDown can be called more than once.
+
+	evictionTicker *time.Ticker // Ticker of the TTL sampling goroutine. Reset when the eviction interval changes.
+
+	metricsServer atomic.Pointer[http.Server] // Holds the HTTP server of the metrics endpoint, if it's enabled.
 }
 
 // WithContext is an options that for the NewSugarDB function that allows you to
@@ -154,10 +244,12 @@ func NewSugarDB(options ...func(sugarDB *SugarDB)) (*SugarDB, error) {
 		connInfo: struct {
 			mut        *sync.RWMutex
 			tcpClients map[*net.Conn]internal.ConnectionInfo
+			states     map[*net.Conn]*connectionState
 			embedded   internal.ConnectionInfo
 		}{
 			mut:        &sync.RWMutex{},
 			tcpClients: make(map[*net.Conn]internal.ConnectionInfo),
+			states:     make(map[*net.Conn]*connectionState),
 			embedded: internal.ConnectionInfo{
 				Id:       0,
 				Name:     "embedded",
@@ -165,6 +257,57 @@ func NewSugarDB(options ...func(sugarDB *SugarDB)) (*SugarDB, error) {
 				Database: 0,
 			},
 		},
+		transactions: struct {
+			mut     *sync.Mutex
+			clients map[*net.Conn]*transaction
+			watched map[*net.Conn][]watchedKey
+		}{
+			mut:     &sync.Mutex{},
+			clients: make(map[*net.Conn]*transaction),
+			watched: make(map[*net.Conn][]watchedKey),
+		},
+		scripts: struct {
+			mut     *sync.RWMutex
+			cache   map[string]string
+			running *runningScript
+		}{
+			mut:   &sync.RWMutex{},
+			cache: make(map[string]string),
+		},
+		tracking: struct {
+			mut     *sync.Mutex
+			clients map[*net.Conn]*trackingClient
+			keys    map[string]map[*net.Conn]struct{}
+		}{
+			mut:     &sync.Mutex{},
+			clients: make(map[*net.Conn]*trackingClient),
+			keys:    make(map[string]map[*net.Conn]struct{}),
+		},
+		pause: struct {
+			mut       *sync.Mutex
+			writeOnly bool
+			done      chan struct{}
+		}{
+			mut: &sync.Mutex{},
+		},
+		blockedClients: struct {
+			mut     *sync.Mutex
+			waiters map[int]map[string][]chan struct{}
+		}{
+			mut:     &sync.Mutex{},
+			waiters: make(map[int]map[string][]chan struct{}),
+		},
+		keyVersions: struct {
+			counter  uint64
+			versions map[int]map[string]uint64
+			deleted  map[int]uint64
+			watchers map[int]map[string]int
+		}{
+			counter:  0,
+			versions: make(map[int]map[string]uint64),
+			deleted:  make(map[int]uint64),
+			watchers: make(map[int]map[string]int),
+		},
 		storeLock: &sync.RWMutex{},
 		store:     make(map[int]map[string]internal.KeyData),
 		memUsed:   0,
@@ -180,24 +323,41 @@ func NewSugarDB(options ...func(sugarDB *SugarDB)) (*SugarDB, error) {
 			var commands []internal.Command
 			commands = append(commands, acl.Commands()...)
 			commands = append(commands, admin.Commands()...)
+			commands = append(commands, bitmap.Commands()...)
 			commands = append(commands, connection.Commands()...)
 			commands = append(commands, generic.Commands()...)
+			commands = append(commands, geo.Commands()...)
 			commands = append(commands, hash.Commands()...)
+			commands = append(commands, hyperloglog.Commands()...)
 			commands = append(commands, list.Commands()...)
 			commands = append(commands, pubsub.Commands()...)
+			commands = append(commands, scripting.Commands()...)
 			commands = append(commands, set.Commands()...)
 			commands = append(commands, sorted_set.Commands()...)
+			commands = append(commands, stream.Commands()...)
 			commands = append(commands, str.Commands()...)
 			return commands
 		}(),
 		quit:    make(chan struct{}),
 		stopTTL: make(chan struct{}),
+		ttlDone: make(chan struct{}),
 	}
 
 	for _, option := range options {
 		option(sugarDB)
 	}
 
+	sugarDB.stats.startTime = sugarDB.clock.Now()
+	sugarDB.stats.commands = make(map[string]*commandStats)
+	sugarDB.stats.events = make(map[string]*eventStats)
+
+	keyspaceEvents, err := config.ParseKeyspaceEvents(sugarDB.config.NotifyKeyspaceEvents)
+	if err != nil {
+		return nil, err
+	}
+	sugarDB.keyspaceEvents.Store(int64(keyspaceEvents))
+	sugarDB.latency.threshold.Store(sugarDB.config.LatencyMonitorThreshold)
+
 	sugarDB.context = context.WithValue(
 		sugarDB.context, "ServerID",
 		internal.ContextServerID(sugarDB.config.ServerID),
@@ -228,6 +388,19 @@ func NewSugarDB(options ...func(sugarDB *SugarDB)) (*SugarDB, error) {
 			FinishSnapshot:        sugarDB.finishSnapshot,
 			SetLatestSnapshotTime: sugarDB.setLatestSnapshot,
 			GetHandlerFuncParams:  sugarDB.getHandlerFuncParams,
+			FeedMonitors: func(ctx context.Context, cmd []string) {
+				sugarDB.feedMonitors(ctx, nil, cmd)
+			},
+			RecordLatency: sugarDB.recordEvent,
+			ExecuteTransaction: func(ctx context.Context, cmds [][]string, watched []internal.WatchedKey, conn *net.Conn) []byte {
+				keys := make([]watchedKey, len(watched))
+				for i, w := range watched {
+					keys[i] = watchedKey{database: w.Database, key: w.Key, version: w.Version, deleted: w.Deleted}
+				}
+				return sugarDB.runTransaction(ctx, cmds, conn, keys)
+			},
+			GetKeyVersions: sugarDB.getKeyVersions,
+			SetKeyVersions: sugarDB.setKeyVersions,
 			DeleteKey: func(ctx context.Context, key string) error {
 				sugarDB.storeLock.Lock()
 				defer sugarDB.storeLock.Unlock()
@@ -252,7 +425,10 @@ func NewSugarDB(options ...func(sugarDB *SugarDB)) (*SugarDB, error) {
 			RemoveRaftServer: sugarDB.raft.RemoveServer,
 			IsRaftLeader:     sugarDB.raft.IsRaftLeader,
 			ApplyMutate:      sugarDB.raftApplyCommand,
-			ApplyDeleteKey:   sugarDB.raftApplyDeleteKey,
+			ApplyDeleteKey: func(ctx context.Context, key string) error {
+				// Followers only forward the deletion of expired keys.
+				return sugarDB.raftApplyDeleteKey(withKeyEvent(ctx, keyEventExpired), key)
+			},
 		})
 	} else {
 		// Set up standalone snapshot engine
@@ -265,6 +441,7 @@ func NewSugarDB(options ...func(sugarDB *SugarDB)) (*SugarDB, error) {
 			snapshot.WithFinishSnapshotFunc(sugarDB.finishSnapshot),
 			snapshot.WithSetLatestSnapshotTimeFunc(sugarDB.setLatestSnapshot),
 			snapshot.WithGetLatestSnapshotTimeFunc(sugarDB.getLatestSnapshotTime),
+			snapshot.WithRecordLatencyFunc(sugarDB.recordEvent),
 			snapshot.WithGetStateFunc(func() map[int]map[string]internal.KeyData {
 				state := make(map[int]map[string]internal.KeyData)
 				for database, data := range sugarDB.getState() {
@@ -293,6 +470,7 @@ func NewSugarDB(options ...func(sugarDB *SugarDB)) (*SugarDB, error) {
 			aof.WithStrategy(sugarDB.config.AOFSyncStrategy),
 			aof.WithStartRewriteFunc(sugarDB.startRewriteAOF),
 			aof.WithFinishRewriteFunc(sugarDB.finishRewriteAOF),
+			aof.WithRecordLatencyFunc(sugarDB.recordEvent),
 			aof.WithGetStateFunc(func() map[int]map[string]internal.KeyData {
 				state := make(map[int]map[string]internal.KeyData)
 				for database, data := range sugarDB.getState() {
@@ -315,6 +493,7 @@ func NewSugarDB(options ...func(sugarDB *SugarDB)) (*SugarDB, error) {
 			aof.WithHandleCommandFunc(func(database int, command []byte) {
 				ctx := context.WithValue(context.Background(), "Protocol", 2)
 				ctx = context.WithValue(ctx, "Database", database)
+				ctx = context.WithValue(ctx, internal.ContextMonitorSource("MonitorSource"), "aof")
 				_, err := sugarDB.handleCommand(ctx, command, nil, true, false)
 				if err != nil {
 					log.Println(err)
@@ -327,37 +506,54 @@ func NewSugarDB(options ...func(sugarDB *SugarDB)) (*SugarDB, error) {
 		sugarDB.aofEngine = aofEngine
 	}
 
-	// If eviction policy is not noeviction, start a goroutine to evict keys at the configured interval.
-	if sugarDB.config.EvictionPolicy != constants.NoEviction {
-		go func() {
-			ticker := time.NewTicker(sugarDB.config.EvictionInterval)
-			defer func() {
-				ticker.Stop()
-			}()
-			for {
-				select {
-				case <-ticker.C:
-					// Run key eviction for each database that has volatile keys.
-					wg := sync.WaitGroup{}
-					for database, _ := range sugarDB.keysWithExpiry.keys {
-						wg.Add(1)
-						ctx := context.WithValue(context.Background(), "Database", database)
-						go func(ctx context.Context, wg *sync.WaitGroup) {
-							if err := sugarDB.evictKeysWithExpiredTTL(ctx); err != nil {
-								log.Printf("evict with ttl: %v\n", err)
-							}
-							wg.Done()
-						}(ctx, &wg)
-					}
-					wg.Wait()
-				case <-sugarDB.stopTTL:
-					break
+	// Start a goroutine to evict keys at the configured interval when the eviction policy is not noeviction.
+	// The policy is checked at each tick because it can be changed with CONFIG SET.
+	// The ticker is stopped while the eviction interval is 0.
+	sugarDB.evictionTicker = time.NewTicker(time.Second)
+	sugarDB.evictionTicker.Stop()
+	if sugarDB.config.EvictionInterval > 0 {
+		sugarDB.evictionTicker.Reset(sugarDB.config.EvictionInterval)
+	}
+	go func() {
+		ticker := sugarDB.evictionTicker
+		defer func() {
+			ticker.Stop()
+			close(sugarDB.ttlDone)
+		}()
+		for {
+			select {
+			case <-ticker.C:
+				if sugarDB.getConfig().EvictionPolicy == constants.NoEviction {
+					continue
+				}
+				// Run key eviction for each database that has volatile keys.
+				sugarDB.keysWithExpiry.rwMutex.RLock()
+				databases := make([]int, 0, len(sugarDB.keysWithExpiry.keys))
+				for database := range sugarDB.keysWithExpiry.keys {
+					databases = append(databases, database)
+				}
+				sugarDB.keysWithExpiry.rwMutex.RUnlock()
+
+				wg := sync.WaitGroup{}
+				for _, database := range databases {
+					wg.Add(1)
+					ctx := context.WithValue(context.Background(), "Database", database)
+					go func(ctx context.Context, wg *sync.WaitGroup) {
+						if err := sugarDB.evictKeysWithExpiredTTL(ctx); err != nil {
+							log.Printf("evict with ttl: %v\n", err)
+						}
+						wg.Done()
+					}(ctx, &wg)
 				}
+				wg.Wait()
+			case <-sugarDB.stopTTL:
+				return
 			}
-		}()
-	}
+		}
+	}()
 
 	if sugarDB.config.TLS && len(sugarDB.config.CertKeyPairs) <= 0 {
+		sugarDB.ttlOnce.Do(func() { close(sugarDB.stopTTL) })
 		return nil, errors.New("must provide certificate and key file paths for TLS mode")
 	}
 
@@ -488,10 +684,14 @@ func (server *SugarDB) handleConnection(conn net.Conn) {
 
 	// Generate connection ID
 	cid := server.connId.Add(1)
+	server.stats.connectionsReceived.Add(1)
 	ctx := context.WithValue(server.context, internal.ContextConnID("ConnectionID"),
 		fmt.Sprintf("%s-%d", server.context.Value(internal.ContextServerID("ServerID")), cid))
+	// The context is cancelled when the connection is killed, so that a blocked command returns.
+	ctx, cancel := context.WithCancel(ctx)
 
 	// Set the default connection information
+	state := newConnectionState(&conn, server.clock.Now(), cancel)
 	server.connInfo.mut.Lock()
 	server.connInfo.tcpClients[&conn] = internal.ConnectionInfo{
 		Id:       cid,
@@ -499,9 +699,29 @@ func (server *SugarDB) handleConnection(conn net.Conn) {
 		Protocol: 2,
 		Database: 0,
 	}
+	server.connInfo.states[&conn] = state
 	server.connInfo.mut.Unlock()
 
 	defer func() {
+		cancel()
+
+		// Drop the transaction in progress and the watched keys, if any.
+		server.transactions.mut.Lock()
+		delete(server.transactions.clients, &conn)
+		server.transactions.mut.Unlock()
+		server.unwatchKeys(server.context, &conn)
+
+		server.connInfo.mut.Lock()
+		delete(server.connInfo.tcpClients, &conn)
+		delete(server.connInfo.states, &conn)
+		server.connInfo.mut.Unlock()
+
+		// Stop tracking the keys read by the connection.
+		server.untrackConnection(&conn, cid)
+
+		// Stop sending the processed commands to the connection if it's in MONITOR mode.
+		server.removeConnectionMonitors(&conn)
+
 		log.Printf("closing connection %d...", cid)
 		if err := conn.Close(); err != nil {
 			log.Println(err)
@@ -522,47 +742,69 @@ func (server *SugarDB) handleConnection(conn net.Conn) {
 			break
 		}
 
+		state.lastInteraction.Store(server.clock.Now().UnixMilli())
+		state.queryBuffer.Store(int64(len(message)))
 		res, err := server.handleCommand(ctx, message, &conn, false, false)
+		state.queryBuffer.Store(0)
 		if err != nil && errors.Is(err, io.EOF) {
 			break
 		}
+
+		// Skip the response when the client turned replies off with CLIENT REPLY.
+		if !state.replyEnabled() {
+			continue
+		}
+
 		if err != nil {
 			log.Println(err)
+			state.writer.Lock()
 			if _, err = w.Write([]byte(fmt.Sprintf("-Error %s\r\n", err.Error()))); err != nil {
 				log.Println(err)
 			}
+			state.writer.Unlock()
 			continue
 		}
 
-		chunkSize := 1024
-
 		// If the length of the response is 0, return nothing to the client.
 		if len(res) == 0 {
 			continue
 		}
 
-		if len(res) <= chunkSize {
-			_, _ = w.Write(res)
-			continue
-		}
+		// Invalidation messages of client side caching are written to the connection concurrently,
+		// so the whole response is written while holding the lock.
+		state.writer.Lock()
+		state.outputBuffer.Store(int64(len(res)))
+		writeResponse(w, res)
+		state.outputBuffer.Store(0)
+		state.writer.Unlock()
+	}
+}
 
-		// If the response is large, send it in chunks.
-		startIndex := 0
-		for {
-			// If the current start index is less than chunkSize from length, return the remaining bytes.
-			if len(res)-1-startIndex < chunkSize {
-				_, err = w.Write(res[startIndex:])
-				if err != nil {
-					log.Println(err)
-				}
-				break
-			}
-			n, _ := w.Write(res[startIndex : startIndex+chunkSize])
-			if n < chunkSize {
-				break
+// writeResponse writes the response to the client. Large responses are sent in chunks.
+func writeResponse(w io.Writer, res []byte) {
+	chunkSize := 1024
+
+	if len(res) <= chunkSize {
+		_, _ = w.Write(res)
+		return
+	}
+
+	// If the response is large, send it in chunks.
+	startIndex := 0
+	for {
+		// If the current start index is less than chunkSize from length, return the remaining bytes.
+		if len(res)-1-startIndex < chunkSize {
+			_, err := w.Write(res[startIndex:])
+			if err != nil {
+				log.Println(err)
 			}
-			startIndex += chunkSize
+			break
 		}
+		n, _ := w.Write(res[startIndex : startIndex+chunkSize])
+		if n < chunkSize {
+			break
+		}
+		startIndex += chunkSize
 	}
 }
 
@@ -571,7 +813,11 @@ func (server *SugarDB) handleConnection(conn net.Conn) {
 //
 // You can still use command functions like echovault.Set if you're embedding SugarDB in your application.
 // However, if you'd like to also accept TCP request on the same instance, you must call this function.
+// It also starts the HTTP listener of the Prometheus metrics when MetricsPort is set.
 func (server *SugarDB) Start() {
+	if server.getConfig().MetricsPort != 0 {
+		server.startMetrics()
+	}
 	server.startTCP()
 }
 
@@ -637,14 +883,23 @@ func (server *SugarDB) rewriteAOF() error {
 // ShutDown gracefully shuts down the SugarDB instance.
 // This function shuts down the memberlist and raft layers.
 func (server *SugarDB) ShutDown() {
+	if metricsServer := server.metricsServer.Load(); metricsServer != nil {
+		log.Println("closing metrics listener...")
+		if err := metricsServer.Close(); err != nil {
+			log.Printf("metrics listener close: %v\n", err)
+		}
+	}
 	if server.listener.Load() != nil {
 		go func() { server.quit <- struct{}{} }()
-		go func() { server.stopTTL <- struct{}{} }()
 		log.Println("closing tcp listener...")
 		if err := server.listener.Load().(net.Listener).Close(); err != nil {
 			log.Printf("listener close: %v\n", err)
 		}
 	}
+	// The TTL sampling goroutine is started with the instance, so it's stopped even if the listener was not started.
+	// Wait for it so that no key is evicted after the shutdown.
+	server.ttlOnce.Do(func() { close(server.stopTTL) })
+	<-server.ttlDone
 	if !server.isInCluster() {
 		server.aofEngine.Close()
 	}
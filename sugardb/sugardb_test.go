@@ -29,10 +29,14 @@ import (
 	"io"
 	"math"
 	"net"
+	"net/http"
 	"os"
 	"path"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -71,6 +75,41 @@ func getBindAddr() net.IP {
 	return getBindAddrNet(0)
 }
 
+// switchableClock is a clock whose time can be changed while the server's goroutines are reading it.
+type switchableClock struct {
+	now atomic.Pointer[time.Time]
+}
+
+func newSwitchableClock() *switchableClock {
+	c := &switchableClock{}
+	c.Reset()
+	return c
+}
+
+func (c *switchableClock) Now() time.Time {
+	return *c.now.Load()
+}
+
+func (c *switchableClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Set stops the clock at t.
+func (c *switchableClock) Set(t time.Time) {
+	c.now.Store(&t)
+}
+
+// Reset sets the clock back to the mock clock's time.
+func (c *switchableClock) Reset() {
+	c.Set(clock.MockClock{}.Now())
+}
+
+func withClock(c clock.Clock) func(sugarDB *SugarDB) {
+	return func(sugarDB *SugarDB) {
+		sugarDB.clock = c
+	}
+}
+
 func setupServer(
 	dataDir string,
 	serverId string,
@@ -95,6 +134,7 @@ func setupServer(
 	return NewSugarDB(
 		WithContext(context.Background()),
 		WithConfig(conf),
+		withClock(newSwitchableClock()),
 	)
 }
 
@@ -250,6 +290,15 @@ func Test_Cluster(t *testing.T) {
 			{key: "key11", value: "value11"},
 			{key: "key12", value: "value12"},
 		},
+		"transaction": {
+			{key: "key13", value: "value13"},
+			{key: "key14", value: "value14"},
+			{key: "key15", value: "value15"},
+		},
+		"script": {
+			{key: "key16", value: "value16"},
+			{key: "key17", value: "value17"},
+		},
 	}
 
 	t.Run("Test_Replication", func(t *testing.T) {
@@ -571,411 +620,1509 @@ func Test_Cluster(t *testing.T) {
 		}
 	})
 
-	t.Run("Test_NotLeaderError", func(t *testing.T) {
-		node := nodes[len(nodes)-1]
-		err := node.client.WriteArray([]resp.Value{
-			resp.StringValue("SET"),
-			resp.StringValue("key"),
-			resp.StringValue("value"),
-		})
-		if err != nil {
+	t.Run("Test_Script", func(t *testing.T) {
+		tests := tests["script"]
+		node := nodes[0]
+
+		// Run a script that sets all the keys on the cluster leader.
+		command := []resp.Value{
+			resp.StringValue("EVAL"),
+			resp.StringValue("for i, key in ipairs(KEYS) do redis.call('SET', key, ARGV[i]) end return #KEYS"),
+			resp.StringValue(strconv.Itoa(len(tests))),
+		}
+		for _, test := range tests {
+			command = append(command, resp.StringValue(test.key))
+		}
+		for _, test := range tests {
+			command = append(command, resp.StringValue(test.value))
+		}
+		if err := node.client.WriteArray(command); err != nil {
 			t.Error(err)
 			return
 		}
-		res, _, err := node.client.ReadValue()
+		rd, _, err := node.client.ReadValue()
 		if err != nil {
 			t.Error(err)
 			return
 		}
-		expected := "not cluster leader, cannot carry out command"
-		if !strings.Contains(res.Error().Error(), expected) {
-			t.Errorf("expected response to contain \"%s\", got \"%s\"", expected, res.Error().Error())
+		if rd.Integer() != len(tests) {
+			t.Errorf("expected script response %d, got %+v", len(tests), rd)
+			return
 		}
-	})
-
-	t.Run("Test_SnapshotRestore", func(t *testing.T) {
-		// TODO: Test snapshot creation and restoration on the cluster.
-	})
 
-	t.Run("Test_EvictExpiredTTL", func(t *testing.T) {
-		// TODO: Implement test for evicting expired keys on the cluster.
-	})
+		// Yield
+		ticker := time.NewTicker(200 * time.Millisecond)
+		defer func() {
+			ticker.Stop()
+		}()
+		<-ticker.C
 
-	t.Run("Test_GetServerInfo", func(t *testing.T) {
-		nodeInfo := []internal.ServerInfo{
-			{
-				Server:     "sugardb",
-				Version:    constants.Version,
-				Id:         nodes[0].serverId,
-				Mode:       "cluster",
-				Role:       "master",
-				Modules:    nodes[0].server.ListModules(),
-				MemoryUsed: nodes[0].server.memUsed,
-				MaxMemory:  nodes[0].server.config.MaxMemory,
-			},
-			{
-				Server:     "sugardb",
-				Version:    constants.Version,
-				Id:         nodes[1].serverId,
-				Mode:       "cluster",
-				Role:       "replica",
-				Modules:    nodes[1].server.ListModules(),
-				MemoryUsed: nodes[1].server.memUsed,
-				MaxMemory:  nodes[1].server.config.MaxMemory,
-			},
-			{
-				Server:     "sugardb",
-				Version:    constants.Version,
-				Id:         nodes[2].serverId,
-				Mode:       "cluster",
-				Role:       "replica",
-				Modules:    nodes[2].server.ListModules(),
-				MemoryUsed: nodes[2].server.memUsed,
-				MaxMemory:  nodes[2].server.config.MaxMemory,
-			},
-			{
-				Server:     "sugardb",
-				Version:    constants.Version,
-				Id:         nodes[3].serverId,
-				Mode:       "cluster",
-				Role:       "replica",
-				Modules:    nodes[3].server.ListModules(),
-				MemoryUsed: nodes[3].server.memUsed,
-				MaxMemory:  nodes[3].server.config.MaxMemory,
-			},
-			{
-				Server:     "sugardb",
-				Version:    constants.Version,
-				Id:         nodes[4].serverId,
-				Mode:       "cluster",
-				Role:       "replica",
-				Modules:    nodes[4].server.ListModules(),
-				MemoryUsed: nodes[4].server.memUsed,
-				MaxMemory:  nodes[4].server.config.MaxMemory,
-			},
-		}
-		for i := 0; i < len(nodes); i++ {
-			if diff := deep.Equal(nodes[i].server.GetServerInfo(), nodeInfo[i]); diff != nil {
-				t.Errorf("GetServerInfo() - node %d: %+v expected %v got %v", i, err, nodes[i].server.GetServerInfo(), nodeInfo[i])
-				return
+		// Check if the data has been replicated on a quorum (majority of the cluster).
+		quorum := int(math.Ceil(float64(len(nodes)/2)) + 1)
+		for i, test := range tests {
+			count := 0
+			for j := 0; j < len(nodes); j++ {
+				node := nodes[j]
+				if err := node.client.WriteArray([]resp.Value{
+					resp.StringValue("GET"),
+					resp.StringValue(test.key),
+				}); err != nil {
+					t.Errorf("could not write data to follower node %d (test %d): %v", j, i, err)
+				}
+				rd, _, err := node.client.ReadValue()
+				if err != nil {
+					t.Errorf("could not read data from follower node %d (test %d): %v", j, i, err)
+				}
+				if rd.String() == test.value {
+					count += 1 // If the expected value is found, increment the count.
+				}
+			}
+			// Fail if count is less than quorum.
+			if count < quorum {
+				t.Errorf("could not find value %s at key %s in cluster quorum", test.value, test.key)
 			}
 		}
 	})
-}
-
-func Test_Standalone(t *testing.T) {
-	port, err := internal.GetFreePort()
-	if err != nil {
-		t.Error(err)
-		return
-	}
-
-	mockServer, err := NewSugarDB(
-		WithConfig(config.Config{
-			BindAddr:       "localhost",
-			Port:           uint16(port),
-			DataDir:        "",
-			EvictionPolicy: constants.NoEviction,
-			ServerID:       "Server_1",
-		}),
-	)
-	if err != nil {
-		t.Error(err)
-		return
-	}
-
-	go func() {
-		mockServer.Start()
-	}()
 
-	t.Cleanup(func() {
-		mockServer.ShutDown()
-	})
+	t.Run("Test_ScriptSha", func(t *testing.T) {
+		leader := nodes[0]
+		key := "script-sha-key"
 
-	t.Run("Test_EmptyCommand", func(t *testing.T) {
-		conn, err := internal.GetConnection("localhost", port)
-		if err != nil {
-			t.Error(err)
-			return
+		sha := leader.server.loadScript("return redis.call('SET', KEYS[1], ARGV[1])")
+		// The followers don't have the script in their cache, like after a restart.
+		for _, node := range nodes[1:] {
+			node.server.flushScripts()
 		}
-		defer func() {
-			_ = conn.Close()
-		}()
-		client := resp.NewConn(conn)
 
-		if err := client.WriteArray([]resp.Value{}); err != nil {
+		if err := leader.client.WriteArray([]resp.Value{
+			resp.StringValue("EVALSHA"), resp.StringValue(sha), resp.StringValue("1"),
+			resp.StringValue(key), resp.StringValue("value1"),
+		}); err != nil {
 			t.Error(err)
 			return
 		}
-		res, _, err := client.ReadValue()
+		rd, _, err := leader.client.ReadValue()
 		if err != nil {
 			t.Error(err)
+			return
 		}
-		expected := "empty command"
-		if !strings.Contains(res.Error().Error(), expected) {
-			t.Errorf("expcted response to contain \"%s\", got \"%s\"", expected, res.Error().Error())
+		if rd.Error() != nil {
+			t.Errorf("expected EVALSHA to succeed, got error %v", rd.Error())
+			return
+		}
+
+		// Yield
+		<-time.After(200 * time.Millisecond)
+
+		// EVALSHA is replicated with the script, so every node runs it.
+		for i, node := range nodes {
+			if err := node.client.WriteArray([]resp.Value{resp.StringValue("GET"), resp.StringValue(key)}); err != nil {
+				t.Error(err)
+				return
+			}
+			rd, _, err := node.client.ReadValue()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if rd.String() != "value1" {
+				t.Errorf("expected value \"value1\" on node %d, got \"%s\"", i, rd.String())
+			}
 		}
 	})
 
-	t.Run("Test_TLS", func(t *testing.T) {
-		t.Parallel()
+	t.Run("Test_StreamID", func(t *testing.T) {
+		leader := nodes[0]
+		key := "stream-id-key"
 
-		port, err := internal.GetFreePort()
-		if err != nil {
+		// The followers' clocks are at a different time than the leader's clock.
+		leaderClock := leader.server.clock.(*switchableClock)
+		leaderClock.Set(time.UnixMilli(5))
+		defer leaderClock.Reset()
+
+		if err := leader.client.WriteArray([]resp.Value{
+			resp.StringValue("XADD"), resp.StringValue(key), resp.StringValue("*"),
+			resp.StringValue("field1"), resp.StringValue("value1"),
+		}); err != nil {
 			t.Error(err)
 			return
 		}
-
-		conf := DefaultConfig()
-		conf.DataDir = ""
-		conf.BindAddr = "localhost"
-		conf.Port = uint16(port)
-		conf.TLS = true
-		conf.CertKeyPairs = [][]string{
-			{
-				path.Join("..", "openssl", "server", "server1.crt"),
-				path.Join("..", "openssl", "server", "server1.key"),
-			},
-			{
-				path.Join("..", "openssl", "server", "server2.crt"),
-				path.Join("..", "openssl", "server", "server2.key"),
-			},
-		}
-
-		server, err := NewSugarDB(WithConfig(conf))
+		rd, _, err := leader.client.ReadValue()
 		if err != nil {
 			t.Error(err)
 			return
 		}
+		if rd.String() != "5-0" {
+			t.Errorf("expected ID \"5-0\", got \"%s\"", rd.String())
+			return
+		}
 
-		wg := sync.WaitGroup{}
-		wg.Add(1)
-		go func() {
-			wg.Done()
-			server.Start()
-		}()
-		wg.Wait()
+		// Yield
+		<-time.After(200 * time.Millisecond)
 
-		// Dial with ServerCAs
-		serverCAs := x509.NewCertPool()
-		f, err := os.Open(path.Join("..", "openssl", "server", "rootCA.crt"))
-		if err != nil {
+		// Every node generates the ID from the leader's time.
+		for i, node := range nodes {
+			if err := node.client.WriteArray([]resp.Value{
+				resp.StringValue("XRANGE"), resp.StringValue(key), resp.StringValue("-"), resp.StringValue("+"),
+			}); err != nil {
+				t.Error(err)
+				return
+			}
+			rd, _, err := node.client.ReadValue()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			entries := rd.Array()
+			if len(entries) != 1 || len(entries[0].Array()) != 2 {
+				t.Errorf("expected 1 entry on node %d, got %v", i, entries)
+				continue
+			}
+			if id := entries[0].Array()[0].String(); id != "5-0" {
+				t.Errorf("expected ID \"5-0\" on node %d, got \"%s\"", i, id)
+			}
+		}
+	})
+
+	t.Run("Test_Transaction", func(t *testing.T) {
+		tests := tests["transaction"]
+		node := nodes[0]
+
+		// Queue the writes in a transaction on the cluster leader.
+		commands := [][]resp.Value{{resp.StringValue("MULTI")}}
+		for _, test := range tests {
+			commands = append(commands, []resp.Value{
+				resp.StringValue("SET"), resp.StringValue(test.key), resp.StringValue(test.value),
+			})
+		}
+		for i, command := range commands {
+			if err := node.client.WriteArray(command); err != nil {
+				t.Errorf("could not write command %d to leader node: %v", i, err)
+				return
+			}
+			if _, _, err := node.client.ReadValue(); err != nil {
+				t.Errorf("could not read response for command %d from leader node: %v", i, err)
+				return
+			}
+		}
+
+		// Execute the transaction and make sure each of the commands returned "OK".
+		if err := node.client.WriteArray([]resp.Value{resp.StringValue("EXEC")}); err != nil {
 			t.Error(err)
+			return
 		}
-		cert, err := io.ReadAll(bufio.NewReader(f))
+		rd, _, err := node.client.ReadValue()
 		if err != nil {
 			t.Error(err)
+			return
 		}
-		ok := serverCAs.AppendCertsFromPEM(cert)
-		if !ok {
-			t.Error("could not load server CA")
-		}
-
-		conn, err := internal.GetTLSConnection("localhost", port, &tls.Config{
-			RootCAs: serverCAs,
-		})
-		if err != nil {
-			t.Error(err)
+		if len(rd.Array()) != len(tests) {
+			t.Errorf("expected %d responses from EXEC, got %d", len(tests), len(rd.Array()))
 			return
 		}
+		for i, res := range rd.Array() {
+			if !strings.EqualFold(res.String(), "ok") {
+				t.Errorf("expected response %d to be \"OK\", got %s", i, res.String())
+			}
+		}
+
+		// Yield
+		ticker := time.NewTicker(200 * time.Millisecond)
 		defer func() {
-			_ = conn.Close()
-			server.ShutDown()
+			ticker.Stop()
 		}()
-		client := resp.NewConn(conn)
+		<-ticker.C
 
-		// Test that we can set and get a value from the server.
-		key := "key1"
-		value := "value1"
-		err = client.WriteArray([]resp.Value{
-			resp.StringValue("SET"), resp.StringValue(key), resp.StringValue(value),
-		})
-		if err != nil {
-			t.Error(err)
+		// Check if the data has been replicated on a quorum (majority of the cluster).
+		quorum := int(math.Ceil(float64(len(nodes)/2)) + 1)
+		for i, test := range tests {
+			count := 0
+			for j := 0; j < len(nodes); j++ {
+				node := nodes[j]
+				if err := node.client.WriteArray([]resp.Value{
+					resp.StringValue("GET"),
+					resp.StringValue(test.key),
+				}); err != nil {
+					t.Errorf("could not write data to follower node %d (test %d): %v", j, i, err)
+				}
+				rd, _, err := node.client.ReadValue()
+				if err != nil {
+					t.Errorf("could not read data from follower node %d (test %d): %v", j, i, err)
+				}
+				if rd.String() == test.value {
+					count += 1 // If the expected value is found, increment the count.
+				}
+			}
+			// Fail if count is less than quorum.
+			if count < quorum {
+				t.Errorf("could not find value %s at key %s in cluster quorum", test.value, test.key)
+			}
 		}
+	})
 
-		res, _, err := client.ReadValue()
-		if err != nil {
-			t.Error(err)
-		}
+	t.Run("Test_TransactionWatch", func(t *testing.T) {
+		leader := nodes[0]
+		key := "transaction-watch-key"
 
-		if !strings.EqualFold(res.String(), "ok") {
-			t.Errorf("expected response OK, got \"%s\"", res.String())
+		version := func(node ClientServerPair) uint64 {
+			node.server.storeLock.RLock()
+			defer node.server.storeLock.RUnlock()
+			return node.server.keyVersions.versions[0][key]
 		}
-
-		err = client.WriteArray([]resp.Value{resp.StringValue("GET"), resp.StringValue(key)})
-		if err != nil {
-			t.Error(err)
+		set := func(value string) error {
+			if err := leader.client.WriteArray([]resp.Value{
+				resp.StringValue("SET"), resp.StringValue(key), resp.StringValue(value),
+			}); err != nil {
+				return err
+			}
+			_, _, err := leader.client.ReadValue()
+			return err
 		}
 
-		res, _, err = client.ReadValue()
-		if err != nil {
+		if err := set("value1"); err != nil {
 			t.Error(err)
+			return
 		}
+		watched := []watchedKey{{database: 0, key: key, version: version(leader)}}
 
-		if res.String() != value {
-			t.Errorf("expected response at key \"%s\" to be \"%s\", got \"%s\"", key, value, res.String())
+		// A write applied after the key was watched aborts the transaction on every node.
+		if err := set("value2"); err != nil {
+			t.Error(err)
+			return
 		}
-	})
-
-	t.Run("Test_MTLS", func(t *testing.T) {
-		t.Parallel()
-
-		port, err := internal.GetFreePort()
+		res, err := leader.server.raftApplyTransaction(leader.server.context, [][]string{{"SET", key, "value3"}}, watched)
 		if err != nil {
 			t.Error(err)
 			return
 		}
-
-		conf := DefaultConfig()
-		conf.DataDir = ""
-		conf.BindAddr = "localhost"
-		conf.Port = uint16(port)
-		conf.TLS = true
-		conf.MTLS = true
-		conf.ClientCAs = []string{
-			path.Join("..", "openssl", "client", "rootCA.crt"),
-		}
-		conf.CertKeyPairs = [][]string{
-			{
-				path.Join("..", "openssl", "server", "server1.crt"),
-				path.Join("..", "openssl", "server", "server1.key"),
-			},
-			{
-				path.Join("..", "openssl", "server", "server2.crt"),
-				path.Join("..", "openssl", "server", "server2.key"),
-			},
+		if string(res) != "*-1\r\n" {
+			t.Errorf("expected transaction with modified watched key to return null array, got %q", res)
 		}
 
-		server, err := NewSugarDB(WithConfig(conf))
-		if err != nil {
+		// The transaction is applied when the watched version is the latest one.
+		watched[0].version = version(leader)
+		if res, err = leader.server.raftApplyTransaction(leader.server.context, [][]string{{"SET", key, "value4"}}, watched); err != nil {
 			t.Error(err)
 			return
 		}
+		if string(res) != "*1\r\n+OK\r\n" {
+			t.Errorf("expected transaction response \"*1\\r\\n+OK\\r\\n\", got %q", res)
+		}
 
-		wg := sync.WaitGroup{}
-		wg.Add(1)
-		go func() {
-			wg.Done()
-			server.Start()
-		}()
-		wg.Wait()
+		// Yield
+		<-time.After(200 * time.Millisecond)
 
-		// Dial with ServerCAs and client certificates
-		clientCertKeyPairs := [][]string{
-			{
-				path.Join("..", "openssl", "client", "client1.crt"),
-				path.Join("..", "openssl", "client", "client1.key"),
-			},
-			{
-				path.Join("..", "openssl", "client", "client2.crt"),
-				path.Join("..", "openssl", "client", "client2.key"),
-			},
-		}
-		var certificates []tls.Certificate
-		for _, pair := range clientCertKeyPairs {
-			c, err := tls.LoadX509KeyPair(pair[0], pair[1])
+		// The key has the same value and version on every node.
+		for i, node := range nodes {
+			if err := node.client.WriteArray([]resp.Value{resp.StringValue("GET"), resp.StringValue(key)}); err != nil {
+				t.Error(err)
+				return
+			}
+			rd, _, err := node.client.ReadValue()
 			if err != nil {
 				t.Error(err)
+				return
+			}
+			if rd.String() != "value4" {
+				t.Errorf("expected value \"value4\" on node %d, got \"%s\"", i, rd.String())
+			}
+			if v := version(node); v != version(leader) {
+				t.Errorf("expected version %d on node %d, got %d", version(leader), i, v)
 			}
-			certificates = append(certificates, c)
-		}
-
-		serverCAs := x509.NewCertPool()
-		f, err := os.Open(path.Join("..", "openssl", "server", "rootCA.crt"))
-		if err != nil {
-			t.Error(err)
-		}
-		cert, err := io.ReadAll(bufio.NewReader(f))
-		if err != nil {
-			t.Error(err)
-		}
-		ok := serverCAs.AppendCertsFromPEM(cert)
-		if !ok {
-			t.Error("could not load server CA")
 		}
+	})
 
-		conn, err := internal.GetTLSConnection("localhost", port, &tls.Config{
-			RootCAs:      serverCAs,
-			Certificates: certificates,
-		})
+	t.Run("Test_BlockingCommand", func(t *testing.T) {
+		// Block on the cluster leader from a new connection.
+		conn, err := internal.GetConnection(nodes[0].bindAddr, nodes[0].port)
 		if err != nil {
 			t.Error(err)
 			return
 		}
 		defer func() {
 			_ = conn.Close()
-			server.ShutDown()
 		}()
-		client := resp.NewConn(conn)
+		blocked := resp.NewConn(conn)
 
-		// Test that we can set and get a value from the server.
-		key := "key1"
-		value := "value1"
-		err = client.WriteArray([]resp.Value{
-			resp.StringValue("SET"), resp.StringValue(key), resp.StringValue(value),
-		})
-		if err != nil {
-			t.Error(err)
+		done := make(chan resp.Value, 1)
+		go func() {
+			if err := blocked.WriteArray([]resp.Value{
+				resp.StringValue("BLPOP"), resp.StringValue("key18"), resp.StringValue("0"),
+			}); err != nil {
+				t.Error(err)
+			}
+			rd, _, err := blocked.ReadValue()
+			if err != nil {
+				t.Error(err)
+			}
+			done <- rd
+		}()
+
+		// Blocking must not stall the raft log, so other writes are still applied.
+		time.Sleep(200 * time.Millisecond)
+		node := nodes[0]
+		for _, command := range [][]resp.Value{
+			{resp.StringValue("SET"), resp.StringValue("key19"), resp.StringValue("value19")},
+			{resp.StringValue("RPUSH"), resp.StringValue("key18"), resp.StringValue("value18"), resp.StringValue("value20")},
+		} {
+			if err := node.client.WriteArray(command); err != nil {
+				t.Error(err)
+				return
+			}
+			if _, _, err := node.client.ReadValue(); err != nil {
+				t.Error(err)
+				return
+			}
 		}
 
-		res, _, err := client.ReadValue()
-		if err != nil {
-			t.Error(err)
+		// The push applied through the raft log wakes the blocked connection.
+		select {
+		case rd := <-done:
+			if len(rd.Array()) != 2 || rd.Array()[0].String() != "key18" || rd.Array()[1].String() != "value18" {
+				t.Errorf("expected BLPOP response [key18 value18], got %v", rd)
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("timed out waiting for BLPOP to return")
+			return
 		}
 
-		if !strings.EqualFold(res.String(), "ok") {
-			t.Errorf("expected response OK, got \"%s\"", res.String())
+		// Yield
+		ticker := time.NewTicker(200 * time.Millisecond)
+		defer func() {
+			ticker.Stop()
+		}()
+		<-ticker.C
+
+		// The pop is replicated, so one element is left on a quorum (majority of the cluster).
+		quorum := int(math.Ceil(float64(len(nodes)/2)) + 1)
+		count := 0
+		for j := 0; j < len(nodes); j++ {
+			if err := nodes[j].client.WriteArray([]resp.Value{
+				resp.StringValue("LLEN"), resp.StringValue("key18"),
+			}); err != nil {
+				t.Errorf("could not write data to node %d: %v", j, err)
+			}
+			rd, _, err := nodes[j].client.ReadValue()
+			if err != nil {
+				t.Errorf("could not read data from node %d: %v", j, err)
+			}
+			if rd.Integer() == 1 {
+				count += 1
+			}
 		}
+		if count < quorum {
+			t.Errorf("expected the list at key18 to have 1 element in cluster quorum, found it on %d nodes", count)
+		}
+	})
 
-		err = client.WriteArray([]resp.Value{resp.StringValue("GET"), resp.StringValue(key)})
+	t.Run("Test_NotLeaderError", func(t *testing.T) {
+		node := nodes[len(nodes)-1]
+		err := node.client.WriteArray([]resp.Value{
+			resp.StringValue("SET"),
+			resp.StringValue("key"),
+			resp.StringValue("value"),
+		})
 		if err != nil {
 			t.Error(err)
+			return
 		}
-
-		res, _, err = client.ReadValue()
+		res, _, err := node.client.ReadValue()
 		if err != nil {
 			t.Error(err)
+			return
+		}
+		expected := "not cluster leader, cannot carry out command"
+		if !strings.Contains(res.Error().Error(), expected) {
+			t.Errorf("expected response to contain \"%s\", got \"%s\"", expected, res.Error().Error())
 		}
+	})
 
-		if res.String() != value {
-			t.Errorf("expected response at key \"%s\" to be \"%s\", got \"%s\"", key, value, res.String())
+	t.Run("Test_SnapshotRestore", func(t *testing.T) {
+		// TODO: Test snapshot creation and restoration on the cluster.
+	})
+
+	t.Run("Test_EvictExpiredTTL", func(t *testing.T) {
+		// TODO: Implement test for evicting expired keys on the cluster.
+	})
+
+	t.Run("Test_GetServerInfo", func(t *testing.T) {
+		nodeInfo := []internal.ServerInfo{
+			{
+				Server:     "sugardb",
+				Version:    constants.Version,
+				Id:         nodes[0].serverId,
+				Mode:       "cluster",
+				Role:       "master",
+				Modules:    nodes[0].server.ListModules(),
+				MemoryUsed: nodes[0].server.memUsed,
+				MaxMemory:  nodes[0].server.config.MaxMemory,
+			},
+			{
+				Server:     "sugardb",
+				Version:    constants.Version,
+				Id:         nodes[1].serverId,
+				Mode:       "cluster",
+				Role:       "replica",
+				Modules:    nodes[1].server.ListModules(),
+				MemoryUsed: nodes[1].server.memUsed,
+				MaxMemory:  nodes[1].server.config.MaxMemory,
+			},
+			{
+				Server:     "sugardb",
+				Version:    constants.Version,
+				Id:         nodes[2].serverId,
+				Mode:       "cluster",
+				Role:       "replica",
+				Modules:    nodes[2].server.ListModules(),
+				MemoryUsed: nodes[2].server.memUsed,
+				MaxMemory:  nodes[2].server.config.MaxMemory,
+			},
+			{
+				Server:     "sugardb",
+				Version:    constants.Version,
+				Id:         nodes[3].serverId,
+				Mode:       "cluster",
+				Role:       "replica",
+				Modules:    nodes[3].server.ListModules(),
+				MemoryUsed: nodes[3].server.memUsed,
+				MaxMemory:  nodes[3].server.config.MaxMemory,
+			},
+			{
+				Server:     "sugardb",
+				Version:    constants.Version,
+				Id:         nodes[4].serverId,
+				Mode:       "cluster",
+				Role:       "replica",
+				Modules:    nodes[4].server.ListModules(),
+				MemoryUsed: nodes[4].server.memUsed,
+				MaxMemory:  nodes[4].server.config.MaxMemory,
+			},
+		}
+		for i := 0; i < len(nodes); i++ {
+			if diff := deep.Equal(nodes[i].server.GetServerInfo(), nodeInfo[i]); diff != nil {
+				t.Errorf("GetServerInfo() - node %d: %+v expected %v got %v", i, err, nodes[i].server.GetServerInfo(), nodeInfo[i])
+				return
+			}
+		}
+	})
+
+	t.Run("Test_RaftMetrics", func(t *testing.T) {
+		// Write a key to the leader so that there's a raft log entry to apply.
+		if err := nodes[0].client.WriteArray([]resp.Value{
+			resp.StringValue("SET"), resp.StringValue("MetricsKey"), resp.StringValue("value"),
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+		if _, _, err := nodes[0].client.ReadValue(); err != nil {
+			t.Error(err)
+			return
+		}
+
+		for i := 0; i < len(nodes); i++ {
+			metrics := nodes[i].server.getMetrics()
+			lines := []string{"sugardb_raft_leader 0\n", "sugardb_raft_term ", "sugardb_raft_commit_index "}
+			if i == 0 {
+				lines = []string{
+					"sugardb_raft_leader 1\n",
+					"sugardb_raft_peers 5\n",
+					"sugardb_event_duration_seconds_count{event=\"raft-apply\"}",
+				}
+			}
+			for _, line := range lines {
+				if !strings.Contains(metrics, line) {
+					t.Errorf("node %d: expected the metrics to contain %q, got %q", i, line, metrics)
+				}
+			}
+		}
+	})
+}
+
+func Test_Standalone(t *testing.T) {
+	port, err := internal.GetFreePort()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	mockServer, err := NewSugarDB(
+		WithConfig(config.Config{
+			BindAddr:       "localhost",
+			Port:           uint16(port),
+			DataDir:        "",
+			EvictionPolicy: constants.NoEviction,
+			ServerID:       "Server_1",
+		}),
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	go func() {
+		mockServer.Start()
+	}()
+
+	t.Cleanup(func() {
+		mockServer.ShutDown()
+	})
+
+	t.Run("Test_EmptyCommand", func(t *testing.T) {
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		client := resp.NewConn(conn)
+
+		if err := client.WriteArray([]resp.Value{}); err != nil {
+			t.Error(err)
+			return
+		}
+		res, _, err := client.ReadValue()
+		if err != nil {
+			t.Error(err)
+		}
+		expected := "empty command"
+		if !strings.Contains(res.Error().Error(), expected) {
+			t.Errorf("expcted response to contain \"%s\", got \"%s\"", expected, res.Error().Error())
+		}
+	})
+
+	t.Run("Test_TLS", func(t *testing.T) {
+		t.Parallel()
+
+		port, err := internal.GetFreePort()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		conf := DefaultConfig()
+		conf.DataDir = ""
+		conf.BindAddr = "localhost"
+		conf.Port = uint16(port)
+		conf.TLS = true
+		conf.CertKeyPairs = [][]string{
+			{
+				path.Join("..", "openssl", "server", "server1.crt"),
+				path.Join("..", "openssl", "server", "server1.key"),
+			},
+			{
+				path.Join("..", "openssl", "server", "server2.crt"),
+				path.Join("..", "openssl", "server", "server2.key"),
+			},
+		}
+
+		server, err := NewSugarDB(WithConfig(conf))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		wg := sync.WaitGroup{}
+		wg.Add(1)
+		go func() {
+			wg.Done()
+			server.Start()
+		}()
+		wg.Wait()
+
+		// Dial with ServerCAs
+		serverCAs := x509.NewCertPool()
+		f, err := os.Open(path.Join("..", "openssl", "server", "rootCA.crt"))
+		if err != nil {
+			t.Error(err)
+		}
+		cert, err := io.ReadAll(bufio.NewReader(f))
+		if err != nil {
+			t.Error(err)
+		}
+		ok := serverCAs.AppendCertsFromPEM(cert)
+		if !ok {
+			t.Error("could not load server CA")
+		}
+
+		conn, err := internal.GetTLSConnection("localhost", port, &tls.Config{
+			RootCAs: serverCAs,
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+			server.ShutDown()
+		}()
+		client := resp.NewConn(conn)
+
+		// Test that we can set and get a value from the server.
+		key := "key1"
+		value := "value1"
+		err = client.WriteArray([]resp.Value{
+			resp.StringValue("SET"), resp.StringValue(key), resp.StringValue(value),
+		})
+		if err != nil {
+			t.Error(err)
+		}
+
+		res, _, err := client.ReadValue()
+		if err != nil {
+			t.Error(err)
+		}
+
+		if !strings.EqualFold(res.String(), "ok") {
+			t.Errorf("expected response OK, got \"%s\"", res.String())
+		}
+
+		err = client.WriteArray([]resp.Value{resp.StringValue("GET"), resp.StringValue(key)})
+		if err != nil {
+			t.Error(err)
+		}
+
+		res, _, err = client.ReadValue()
+		if err != nil {
+			t.Error(err)
+		}
+
+		if res.String() != value {
+			t.Errorf("expected response at key \"%s\" to be \"%s\", got \"%s\"", key, value, res.String())
+		}
+	})
+
+	t.Run("Test_MTLS", func(t *testing.T) {
+		t.Parallel()
+
+		port, err := internal.GetFreePort()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		conf := DefaultConfig()
+		conf.DataDir = ""
+		conf.BindAddr = "localhost"
+		conf.Port = uint16(port)
+		conf.TLS = true
+		conf.MTLS = true
+		conf.ClientCAs = []string{
+			path.Join("..", "openssl", "client", "rootCA.crt"),
+		}
+		conf.CertKeyPairs = [][]string{
+			{
+				path.Join("..", "openssl", "server", "server1.crt"),
+				path.Join("..", "openssl", "server", "server1.key"),
+			},
+			{
+				path.Join("..", "openssl", "server", "server2.crt"),
+				path.Join("..", "openssl", "server", "server2.key"),
+			},
+		}
+
+		server, err := NewSugarDB(WithConfig(conf))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		wg := sync.WaitGroup{}
+		wg.Add(1)
+		go func() {
+			wg.Done()
+			server.Start()
+		}()
+		wg.Wait()
+
+		// Dial with ServerCAs and client certificates
+		clientCertKeyPairs := [][]string{
+			{
+				path.Join("..", "openssl", "client", "client1.crt"),
+				path.Join("..", "openssl", "client", "client1.key"),
+			},
+			{
+				path.Join("..", "openssl", "client", "client2.crt"),
+				path.Join("..", "openssl", "client", "client2.key"),
+			},
+		}
+		var certificates []tls.Certificate
+		for _, pair := range clientCertKeyPairs {
+			c, err := tls.LoadX509KeyPair(pair[0], pair[1])
+			if err != nil {
+				t.Error(err)
+			}
+			certificates = append(certificates, c)
+		}
+
+		serverCAs := x509.NewCertPool()
+		f, err := os.Open(path.Join("..", "openssl", "server", "rootCA.crt"))
+		if err != nil {
+			t.Error(err)
+		}
+		cert, err := io.ReadAll(bufio.NewReader(f))
+		if err != nil {
+			t.Error(err)
+		}
+		ok := serverCAs.AppendCertsFromPEM(cert)
+		if !ok {
+			t.Error("could not load server CA")
+		}
+
+		conn, err := internal.GetTLSConnection("localhost", port, &tls.Config{
+			RootCAs:      serverCAs,
+			Certificates: certificates,
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+			server.ShutDown()
+		}()
+		client := resp.NewConn(conn)
+
+		// Test that we can set and get a value from the server.
+		key := "key1"
+		value := "value1"
+		err = client.WriteArray([]resp.Value{
+			resp.StringValue("SET"), resp.StringValue(key), resp.StringValue(value),
+		})
+		if err != nil {
+			t.Error(err)
+		}
+
+		res, _, err := client.ReadValue()
+		if err != nil {
+			t.Error(err)
+		}
+
+		if !strings.EqualFold(res.String(), "ok") {
+			t.Errorf("expected response OK, got \"%s\"", res.String())
+		}
+
+		err = client.WriteArray([]resp.Value{resp.StringValue("GET"), resp.StringValue(key)})
+		if err != nil {
+			t.Error(err)
+		}
+
+		res, _, err = client.ReadValue()
+		if err != nil {
+			t.Error(err)
+		}
+
+		if res.String() != value {
+			t.Errorf("expected response at key \"%s\" to be \"%s\", got \"%s\"", key, value, res.String())
+		}
+	})
+
+	t.Run("Test_SnapshotRestore", func(t *testing.T) {
+		t.Parallel()
+
+		dataDir := path.Join(".", "testdata", "test_snapshot")
+		t.Cleanup(func() {
+			_ = os.RemoveAll(dataDir)
+		})
+
+		tests := []struct {
+			name         string
+			dataDir      string
+			values       map[int]map[string]string
+			snapshotFunc func(mockServer *SugarDB) error
+			lastSaveFunc func(mockServer *SugarDB) (int, error)
+			wantLastSave int
+		}{
+			{
+				name:    "1. Snapshot in embedded instance",
+				dataDir: path.Join(dataDir, "embedded_instance"),
+				values: map[int]map[string]string{
+					0: {"key5": "value-05", "key6": "value-06", "key7": "value-07", "key8": "value-08"},
+					1: {"key5": "value-15", "key6": "value-16", "key7": "value-17", "key8": "value-18"},
+				},
+				snapshotFunc: func(mockServer *SugarDB) error {
+					if _, err := mockServer.Save(); err != nil {
+						return err
+					}
+					return nil
+				},
+				lastSaveFunc: func(mockServer *SugarDB) (int, error) {
+					return mockServer.LastSave()
+				},
+				wantLastSave: int(clock.NewClock().Now().UnixMilli()),
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				t.Parallel()
+
+				port, err := internal.GetFreePort()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				conf := DefaultConfig()
+				conf.DataDir = test.dataDir
+				conf.BindAddr = "localhost"
+				conf.Port = uint16(port)
+				conf.RestoreSnapshot = true
+
+				mockServer, err := NewSugarDB(WithConfig(conf))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				defer func() {
+					// Shutdown
+					mockServer.ShutDown()
+				}()
+
+				// Trigger some write commands
+				for database, data := range test.values {
+					_ = mockServer.SelectDB(database)
+					for key, value := range data {
+						if _, _, err = mockServer.Set(key, value, SETOptions{}); err != nil {
+							t.Error(err)
+							return
+						}
+					}
+				}
+
+				// Function to trigger snapshot save
+				if err = test.snapshotFunc(mockServer); err != nil {
+					t.Error(err)
+				}
+
+				// Yield to allow snapshot to complete sync.
+				ticker := time.NewTicker(20 * time.Millisecond)
+				<-ticker.C
+				ticker.Stop()
+
+				// Restart server with the same config. This should restore the snapshot
+				mockServer, err = NewSugarDB(WithConfig(conf))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				// Check that all the key/value pairs have been restored into the store.
+				for database, data := range test.values {
+					_ = mockServer.SelectDB(database)
+					for key, value := range data {
+						res, err := mockServer.Get(key)
+						if err != nil {
+							t.Error(err)
+							return
+						}
+						if res != value {
+							t.Errorf("expected value at key \"%s\" to be \"%s\", got \"%s\"", key, value, res)
+							return
+						}
+					}
+				}
+
+				// Check that the lastsave is the time the last snapshot was taken.
+				lastSave, err := test.lastSaveFunc(mockServer)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				if lastSave != test.wantLastSave {
+					t.Errorf("expected lastsave to be %d, got %d", test.wantLastSave, lastSave)
+				}
+			})
+		}
+	})
+
+	t.Run("Test_AOFRestore", func(t *testing.T) {
+		t.Parallel()
+
+		ticker := time.NewTicker(50 * time.Millisecond)
+
+		dataDir := path.Join(".", "testdata", "test_aof")
+		t.Cleanup(func() {
+			_ = os.RemoveAll(dataDir)
+			ticker.Stop()
+		})
+
+		// Prepare data for testing.
+		data := map[string]map[string]string{
+			"before-rewrite": {
+				"key1": "value1",
+				"key2": "value2",
+				"key3": "value3",
+				"key4": "value4",
+			},
+			"after-rewrite": {
+				"key3": "value3-updated",
+				"key4": "value4-updated",
+				"key5": "value5",
+				"key6": "value6",
+			},
+			"expected-values": {
+				"key1": "value1",
+				"key2": "value2",
+				"key3": "value3-updated",
+				"key4": "value4-updated",
+				"key5": "value5",
+				"key6": "value6",
+			},
+		}
+
+		conf := DefaultConfig()
+		conf.RestoreAOF = true
+		conf.DataDir = dataDir
+		conf.AOFSyncStrategy = "always"
+
+		mockServer, err := NewSugarDB(WithConfig(conf))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		// Perform write commands from "before-rewrite"
+		for key, value := range data["before-rewrite"] {
+			if _, _, err := mockServer.Set(key, value, SETOptions{}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+
+		// Yield
+		<-ticker.C
+
+		// Rewrite AOF
+		if _, err := mockServer.RewriteAOF(); err != nil {
+			t.Error(err)
+			return
+		}
+
+		// Perform write commands from "after-rewrite"
+		for key, value := range data["after-rewrite"] {
+			if _, _, err := mockServer.Set(key, value, SETOptions{}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+
+		// Yield
+		<-ticker.C
+
+		// Shutdown the SugarDB instance
+		mockServer.ShutDown()
+
+		// Start another instance of SugarDB
+		mockServer, err = NewSugarDB(WithConfig(conf))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		// Check if the servers contains the keys and values from "expected-values"
+		for key, value := range data["expected-values"] {
+			res, err := mockServer.Get(key)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if res != value {
+				t.Errorf("expected value at key \"%s\" to be \"%s\", got \"%s\"", key, value, res)
+				return
+			}
+		}
+	})
+
+	t.Run("Test_ScriptRestore", func(t *testing.T) {
+		t.Parallel()
+
+		ticker := time.NewTicker(50 * time.Millisecond)
+
+		dataDir := path.Join(".", "testdata", "test_script_aof")
+		t.Cleanup(func() {
+			_ = os.RemoveAll(dataDir)
+			ticker.Stop()
+		})
+
+		conf := DefaultConfig()
+		conf.RestoreAOF = true
+		conf.DataDir = dataDir
+		conf.AOFSyncStrategy = "always"
+
+		mockServer, err := NewSugarDB(WithConfig(conf))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		sha, err := mockServer.ScriptLoad("return redis.call('SET', KEYS[1], ARGV[1])")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		// The rewritten AOF does not hold SCRIPT LOAD, so EVALSHA is only replayed if it's logged with the script.
+		if _, err = mockServer.RewriteAOF(); err != nil {
+			t.Error(err)
+			return
+		}
+		if _, err = mockServer.EvalSha(sha, []string{"ScriptRestoreKey1"}, []string{"value1"}); err != nil {
+			t.Error(err)
+			return
+		}
+
+		// Yield
+		<-ticker.C
+
+		// Shutdown the SugarDB instance
+		mockServer.ShutDown()
+
+		// Start another instance of SugarDB
+		mockServer, err = NewSugarDB(WithConfig(conf))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		res, err := mockServer.Get("ScriptRestoreKey1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if res != "value1" {
+			t.Errorf("expected value at key \"ScriptRestoreKey1\" to be \"value1\", got \"%s\"", res)
+		}
+	})
+
+	t.Run("Test_ShutDownStopsEviction", func(t *testing.T) {
+		t.Parallel()
+
+		// The TTL sampling goroutine is stopped even if the listener was not started.
+		server := createSugarDB()
+		server.ShutDown()
+		select {
+		case <-server.ttlDone:
+		case <-time.After(time.Second):
+			t.Error("expected the TTL sampling goroutine to stop after ShutDown")
+		}
+		// ShutDown can be called again.
+		server.ShutDown()
+	})
+
+	t.Run("Test_MigrateRestore", func(t *testing.T) {
+		t.Parallel()
+
+		ticker := time.NewTicker(50 * time.Millisecond)
+
+		dataDir := path.Join(".", "testdata", "test_migrate_aof")
+		t.Cleanup(func() {
+			_ = os.RemoveAll(dataDir)
+			ticker.Stop()
+		})
+
+		port, err := internal.GetFreePort()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		targetConf := DefaultConfig()
+		targetConf.BindAddr = "localhost"
+		targetConf.Port = uint16(port)
+		targetConf.DataDir = ""
+		targetConf.RequirePass = true
+		targetConf.Password = "migrate_password"
+		target, err := NewSugarDB(WithConfig(targetConf))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		go func() {
+			target.Start()
+		}()
+		t.Cleanup(func() {
+			target.ShutDown()
+		})
+		// Wait for the target to accept connections.
+		conn, err := internal.GetConnection("localhost", port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_ = conn.Close()
+
+		conf := DefaultConfig()
+		conf.RestoreAOF = true
+		conf.DataDir = dataDir
+		conf.AOFSyncStrategy = "always"
+
+		mockServer, err := NewSugarDB(WithConfig(conf))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for _, key := range []string{"MigrateRestoreKey1", "MigrateRestoreKey2"} {
+			if _, _, err = mockServer.Set(key, "value", SETOptions{}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		ok, err := mockServer.Migrate("localhost", port, 0, []string{"MigrateRestoreKey1"}, MIGRATEOptions{Password: "migrate_password"})
+		if err != nil || !ok {
+			t.Errorf("expected MIGRATE to move the key, got %v, %v", ok, err)
+			return
+		}
+
+		// Yield
+		<-ticker.C
+
+		// MIGRATE is logged as the deletion of the migrated keys, so the password of the target is not written.
+		aof, err := os.ReadFile(path.Join(dataDir, "aof", "log.aof"))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if strings.Contains(string(aof), "MIGRATE") || strings.Contains(string(aof), "migrate_password") {
+			t.Errorf("expected MIGRATE not to be written to the AOF, got %q", aof)
+		}
+
+		// Shutdown the SugarDB instance
+		mockServer.ShutDown()
+
+		// Start another instance of SugarDB
+		mockServer, err = NewSugarDB(WithConfig(conf))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		for key, want := range map[string]string{"MigrateRestoreKey1": "", "MigrateRestoreKey2": "value"} {
+			res, err := mockServer.Get(key)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if res != want {
+				t.Errorf("expected value at key \"%s\" to be \"%s\", got \"%s\"", key, want, res)
+			}
+		}
+	})
+
+	t.Run("Test_StreamRestore", func(t *testing.T) {
+		t.Parallel()
+
+		dataDir := path.Join(".", "testdata", "test_stream_restore")
+		t.Cleanup(func() {
+			_ = os.RemoveAll(dataDir)
+		})
+
+		tests := []struct {
+			name    string
+			dataDir string
+			setup   func(conf *config.Config)
+			persist func(mockServer *SugarDB) error
+		}{
+			{
+				name:    "1. Restore streams from a snapshot",
+				dataDir: path.Join(dataDir, "snapshot"),
+				setup: func(conf *config.Config) {
+					conf.RestoreSnapshot = true
+				},
+				persist: func(mockServer *SugarDB) error {
+					_, err := mockServer.Save()
+					return err
+				},
+			},
+			{
+				name:    "2. Restore streams from the AOF",
+				dataDir: path.Join(dataDir, "aof"),
+				setup: func(conf *config.Config) {
+					conf.RestoreAOF = true
+					conf.AOFSyncStrategy = "always"
+				},
+				persist: func(mockServer *SugarDB) error {
+					_, err := mockServer.RewriteAOF()
+					return err
+				},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				t.Parallel()
+
+				conf := DefaultConfig()
+				conf.DataDir = test.dataDir
+				test.setup(&conf)
+
+				mockClock := newSwitchableClock()
+				mockServer, err := NewSugarDB(WithConfig(conf), withClock(mockClock))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				for _, id := range []string{"1-1", "2-1", "3-1"} {
+					if _, err = mockServer.XAdd("stream1", map[string]string{"id": id}, XAddOptions{ID: id}); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+				if _, err = mockServer.XDel("stream1", "3-1"); err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err = mockServer.XGroupCreate("stream1", "group1", "0", XGroupCreateOptions{}); err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err = mockServer.XReadGroup("group1", "alice", map[string]string{"stream1": ">"}, XReadGroupOptions{Count: 1}); err != nil {
+					t.Error(err)
+					return
+				}
+				// A hash with the fields of the type tag is not restored as a stream.
+				if _, err = mockServer.HSet("hash1", map[string]string{"Type": "stream", "Data": "{}"}); err != nil {
+					t.Error(err)
+					return
+				}
+
+				if err = test.persist(mockServer); err != nil {
+					t.Error(err)
+					return
+				}
+
+				// Yield to allow the snapshot or AOF rewrite to complete.
+				<-time.After(50 * time.Millisecond)
+
+				if _, err = mockServer.XAdd("stream1", map[string]string{"id": "4-1"}, XAddOptions{ID: "4-1"}); err != nil {
+					t.Error(err)
+					return
+				}
+				// The ID generated for "*" is written to the AOF, so the restarted server, whose clock is at a
+				// different time, restores the entry with the same ID.
+				mockClock.Set(time.UnixMilli(5))
+				if _, err = mockServer.XAdd("stream1", map[string]string{"id": "*"}, XAddOptions{ID: "*"}); err != nil {
+					t.Error(err)
+					return
+				}
+
+				mockServer.ShutDown()
+
+				mockServer, err = NewSugarDB(WithConfig(conf))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				defer mockServer.ShutDown()
+
+				got, err := mockServer.XRange("stream1", "-", "+", 0)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				// The snapshot was taken before the last entry was added.
+				want := []StreamEntry{
+					{ID: "1-1", Fields: map[string]string{"id": "1-1"}},
+					{ID: "2-1", Fields: map[string]string{"id": "2-1"}},
+				}
+				if conf.RestoreAOF {
+					want = append(want,
+						StreamEntry{ID: "4-1", Fields: map[string]string{"id": "4-1"}},
+						StreamEntry{ID: "5-0", Fields: map[string]string{"id": "*"}},
+					)
+				}
+				if diff := deep.Equal(got, want); diff != nil {
+					t.Errorf("unexpected stream entries: %v", diff)
+				}
+
+				// The ID of the deleted entry is still the last ID of the stream.
+				if _, err = mockServer.XAdd("stream1", map[string]string{"id": "3-1"}, XAddOptions{ID: "3-1"}); err == nil {
+					t.Error("expected error adding an entry with the ID of a deleted entry, got nil")
+				}
+
+				// The consumer group keeps its pending entries and the ID of the last delivered entry.
+				pending, err := mockServer.XPending("stream1", "group1")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				wantPending := XPendingSummary{Count: 1, First: "1-1", Last: "1-1", Consumers: map[string]int{"alice": 1}}
+				if diff := deep.Equal(pending, wantPending); diff != nil {
+					t.Errorf("unexpected pending entries: %v", diff)
+				}
+				read, err := mockServer.XReadGroup("group1", "bob", map[string]string{"stream1": ">"}, XReadGroupOptions{})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if diff := deep.Equal(read["stream1"], want[1:]); diff != nil {
+					t.Errorf("unexpected entries delivered to the consumer group: %v", diff)
+				}
+
+				fields, err := mockServer.HGetAll("hash1")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				slices.Sort(fields)
+				if diff := deep.Equal(fields, []string{"Data", "Type", "stream", "{}"}); diff != nil {
+					t.Errorf("unexpected hash fields: %v", diff)
+				}
+			})
+		}
+	})
+
+	t.Run("Test_BitmapRestore", func(t *testing.T) {
+		t.Parallel()
+
+		dataDir := path.Join(".", "testdata", "test_bitmap_restore")
+		t.Cleanup(func() {
+			_ = os.RemoveAll(dataDir)
+		})
+
+		tests := []struct {
+			name    string
+			dataDir string
+			setup   func(conf *config.Config)
+			persist func(mockServer *SugarDB) error
+		}{
+			{
+				name:    "1. Restore bitmaps from a snapshot",
+				dataDir: path.Join(dataDir, "snapshot"),
+				setup: func(conf *config.Config) {
+					conf.RestoreSnapshot = true
+				},
+				persist: func(mockServer *SugarDB) error {
+					_, err := mockServer.Save()
+					return err
+				},
+			},
+			{
+				name:    "2. Restore bitmaps from the AOF",
+				dataDir: path.Join(dataDir, "aof"),
+				setup: func(conf *config.Config) {
+					conf.RestoreAOF = true
+					conf.AOFSyncStrategy = "always"
+				},
+				persist: func(mockServer *SugarDB) error {
+					_, err := mockServer.RewriteAOF()
+					return err
+				},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				t.Parallel()
+
+				conf := DefaultConfig()
+				conf.DataDir = test.dataDir
+				test.setup(&conf)
+
+				mockServer, err := NewSugarDB(WithConfig(conf))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				// Setting bit 6 of "1" turns it into "3", which must be restored as raw bytes and not as a number.
+				if _, _, err = mockServer.Set("bitmap1", "1", SETOptions{}); err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err = mockServer.SetBit("bitmap1", 6, 1); err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err = mockServer.SetBit("bitmap2", 7, 1); err != nil {
+					t.Error(err)
+					return
+				}
+
+				if err = test.persist(mockServer); err != nil {
+					t.Error(err)
+					return
+				}
+
+				// Yield to allow the snapshot or AOF rewrite to complete.
+				<-time.After(50 * time.Millisecond)
+
+				if _, err = mockServer.SetBit("bitmap2", 0, 1); err != nil {
+					t.Error(err)
+					return
+				}
+
+				mockServer.ShutDown()
+
+				mockServer, err = NewSugarDB(WithConfig(conf))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				defer mockServer.ShutDown()
+
+				// The snapshot was taken before the last bit was set.
+				want := map[string]string{"bitmap1": "3", "bitmap2": "\x01"}
+				if conf.RestoreAOF {
+					want["bitmap2"] = "\x81"
+				}
+				for key, value := range want {
+					got, err := mockServer.Get(key)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					if got != value {
+						t.Errorf("expected value of %s to be %q, got %q", key, value, got)
+					}
+				}
+
+				if bit, err := mockServer.GetBit("bitmap1", 6); err != nil || bit != 1 {
+					t.Errorf("expected bit 6 of bitmap1 to be 1, got %d, %v", bit, err)
+				}
+			})
 		}
 	})
 
-	t.Run("Test_SnapshotRestore", func(t *testing.T) {
+	t.Run("Test_HyperLogLogRestore", func(t *testing.T) {
 		t.Parallel()
 
-		dataDir := path.Join(".", "testdata", "test_snapshot")
+		dataDir := path.Join(".", "testdata", "test_hyperloglog_restore")
 		t.Cleanup(func() {
 			_ = os.RemoveAll(dataDir)
 		})
 
 		tests := []struct {
-			name         string
-			dataDir      string
-			values       map[int]map[string]string
-			snapshotFunc func(mockServer *SugarDB) error
-			lastSaveFunc func(mockServer *SugarDB) (int, error)
-			wantLastSave int
+			name    string
+			dataDir string
+			setup   func(conf *config.Config)
+			persist func(mockServer *SugarDB) error
 		}{
 			{
-				name:    "1. Snapshot in embedded instance",
-				dataDir: path.Join(dataDir, "embedded_instance"),
-				values: map[int]map[string]string{
-					0: {"key5": "value-05", "key6": "value-06", "key7": "value-07", "key8": "value-08"},
-					1: {"key5": "value-15", "key6": "value-16", "key7": "value-17", "key8": "value-18"},
+				name:    "1. Restore HyperLogLogs from a snapshot",
+				dataDir: path.Join(dataDir, "snapshot"),
+				setup: func(conf *config.Config) {
+					conf.RestoreSnapshot = true
 				},
-				snapshotFunc: func(mockServer *SugarDB) error {
-					if _, err := mockServer.Save(); err != nil {
-						return err
-					}
-					return nil
+				persist: func(mockServer *SugarDB) error {
+					_, err := mockServer.Save()
+					return err
 				},
-				lastSaveFunc: func(mockServer *SugarDB) (int, error) {
-					return mockServer.LastSave()
+			},
+			{
+				name:    "2. Restore HyperLogLogs from the AOF",
+				dataDir: path.Join(dataDir, "aof"),
+				setup: func(conf *config.Config) {
+					conf.RestoreAOF = true
+					conf.AOFSyncStrategy = "always"
+				},
+				persist: func(mockServer *SugarDB) error {
+					_, err := mockServer.RewriteAOF()
+					return err
 				},
-				wantLastSave: int(clock.NewClock().Now().UnixMilli()),
 			},
 		}
 
@@ -983,186 +2130,357 @@ func Test_Standalone(t *testing.T) {
 			t.Run(test.name, func(t *testing.T) {
 				t.Parallel()
 
-				port, err := internal.GetFreePort()
-				if err != nil {
-					t.Error(err)
-					return
-				}
-
 				conf := DefaultConfig()
 				conf.DataDir = test.dataDir
-				conf.BindAddr = "localhost"
-				conf.Port = uint16(port)
-				conf.RestoreSnapshot = true
+				test.setup(&conf)
 
 				mockServer, err := NewSugarDB(WithConfig(conf))
 				if err != nil {
 					t.Error(err)
 					return
 				}
-				defer func() {
-					// Shutdown
-					mockServer.ShutDown()
-				}()
 
-				// Trigger some write commands
-				for database, data := range test.values {
-					_ = mockServer.SelectDB(database)
-					for key, value := range data {
-						if _, _, err = mockServer.Set(key, value, SETOptions{}); err != nil {
-							t.Error(err)
-							return
-						}
+				// hll1 keeps the sparse representation and hll2 is converted to the dense representation.
+				if _, err = mockServer.PFAdd("hll1", "a", "b", "c"); err != nil {
+					t.Error(err)
+					return
+				}
+				elements := make([]string, 2000)
+				for i := range elements {
+					elements[i] = fmt.Sprintf("element-%d", i)
+				}
+				if _, err = mockServer.PFAdd("hll2", elements...); err != nil {
+					t.Error(err)
+					return
+				}
+
+				want := make(map[string]int)
+				for _, key := range []string{"hll1", "hll2"} {
+					if want[key], err = mockServer.PFCount(key); err != nil {
+						t.Error(err)
+						return
 					}
 				}
 
-				// Function to trigger snapshot save
-				if err = test.snapshotFunc(mockServer); err != nil {
+				if err = test.persist(mockServer); err != nil {
 					t.Error(err)
+					return
 				}
 
-				// Yield to allow snapshot to complete sync.
-				ticker := time.NewTicker(20 * time.Millisecond)
-				<-ticker.C
-				ticker.Stop()
+				// Yield to allow the snapshot or AOF rewrite to complete.
+				<-time.After(50 * time.Millisecond)
 
-				// Restart server with the same config. This should restore the snapshot
-				mockServer, err = NewSugarDB(WithConfig(conf))
-				if err != nil {
+				if _, err = mockServer.PFAdd("hll1", "d"); err != nil {
 					t.Error(err)
 					return
 				}
 
-				// Check that all the key/value pairs have been restored into the store.
-				for database, data := range test.values {
-					_ = mockServer.SelectDB(database)
-					for key, value := range data {
-						res, err := mockServer.Get(key)
-						if err != nil {
-							t.Error(err)
-							return
-						}
-						if res != value {
-							t.Errorf("expected value at key \"%s\" to be \"%s\", got \"%s\"", key, value, res)
-							return
-						}
-					}
-				}
+				mockServer.ShutDown()
 
-				// Check that the lastsave is the time the last snapshot was taken.
-				lastSave, err := test.lastSaveFunc(mockServer)
+				mockServer, err = NewSugarDB(WithConfig(conf))
 				if err != nil {
 					t.Error(err)
 					return
 				}
+				defer mockServer.ShutDown()
 
-				if lastSave != test.wantLastSave {
-					t.Errorf("expected lastsave to be %d, got %d", test.wantLastSave, lastSave)
+				// The snapshot was taken before the last element was added.
+				if conf.RestoreAOF {
+					want["hll1"] = 4
+				}
+				for key, count := range want {
+					got, err := mockServer.PFCount(key)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					if got != count {
+						t.Errorf("expected count of %s to be %d, got %d", key, count, got)
+					}
+				}
+
+				// The restored HyperLogLogs still recognise the elements that were added.
+				if changed, err := mockServer.PFAdd("hll2", elements[:100]...); err != nil || changed {
+					t.Errorf("expected PFAdd of existing elements to return false, got %v, %v", changed, err)
 				}
 			})
 		}
 	})
 
-	t.Run("Test_AOFRestore", func(t *testing.T) {
+	t.Run("Test_EvictExpiredTTL", func(t *testing.T) {
 		t.Parallel()
 
-		ticker := time.NewTicker(50 * time.Millisecond)
+		conf := DefaultConfig()
+		conf.DataDir = ""
+		conf.EvictionPolicy = constants.AllKeysLRU
+		conf.EvictionInterval = 50 * time.Millisecond
 
-		dataDir := path.Join(".", "testdata", "test_aof")
+		server, err := NewSugarDB(WithConfig(conf))
+		if err != nil {
+			t.Error(err)
+			return
+		}
 		t.Cleanup(func() {
-			_ = os.RemoveAll(dataDir)
-			ticker.Stop()
+			server.ShutDown()
 		})
 
-		// Prepare data for testing.
-		data := map[string]map[string]string{
-			"before-rewrite": {
-				"key1": "value1",
-				"key2": "value2",
-				"key3": "value3",
-				"key4": "value4",
-			},
-			"after-rewrite": {
-				"key3": "value3-updated",
-				"key4": "value4-updated",
-				"key5": "value5",
-				"key6": "value6",
-			},
-			"expected-values": {
-				"key1": "value1",
-				"key2": "value2",
-				"key3": "value3-updated",
-				"key4": "value4-updated",
-				"key5": "value5",
-				"key6": "value6",
-			},
+		now := server.clock.Now()
+		expired := []string{"ExpiredKey1", "ExpiredKey2", "ExpiredKey3"}
+		volatile := []string{"VolatileKey1", "VolatileKey2"}
+		for _, key := range expired {
+			presetKeyData(server, context.Background(), key, internal.KeyData{Value: "value", ExpireAt: now.Add(-time.Second)})
+		}
+		for _, key := range volatile {
+			presetKeyData(server, context.Background(), key, internal.KeyData{Value: "value", ExpireAt: now.Add(time.Hour)})
+		}
+
+		<-time.After(300 * time.Millisecond)
+
+		// The expired keys are deleted without being accessed, and the keys that have not expired are kept.
+		ctx := context.WithValue(context.Background(), "Database", 0)
+		exists := server.keysExist(ctx, append(expired, volatile...))
+		for _, key := range expired {
+			if exists[key] {
+				t.Errorf("expected expired key %s to be deleted", key)
+			}
+		}
+		for _, key := range volatile {
+			if !exists[key] {
+				t.Errorf("expected key %s to exist", key)
+			}
 		}
+	})
+
+	t.Run("Test_KeyspaceNotifications", func(t *testing.T) {
+		t.Parallel()
 
 		conf := DefaultConfig()
-		conf.RestoreAOF = true
-		conf.DataDir = dataDir
-		conf.AOFSyncStrategy = "always"
+		conf.DataDir = ""
+		conf.NotifyKeyspaceEvents = "KEA"
 
-		mockServer, err := NewSugarDB(WithConfig(conf))
+		server, err := NewSugarDB(WithConfig(conf))
 		if err != nil {
 			t.Error(err)
 			return
 		}
 
-		// Perform write commands from "before-rewrite"
-		for key, value := range data["before-rewrite"] {
-			if _, _, err := mockServer.Set(key, value, SETOptions{}); err != nil {
-				t.Error(err)
-				return
+		// Keys written to this server are evicted as soon as they are added to the LRU cache
+		// because they exceed the max memory.
+		conf.MaxMemory = 1
+		conf.EvictionPolicy = constants.AllKeysLRU
+		evictingServer, err := NewSugarDB(WithConfig(conf))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		t.Cleanup(func() {
+			server.ShutDown()
+			evictingServer.ShutDown()
+		})
+
+		// readMessage reads the next message of the subscription, or fails the test if there is none.
+		readMessage := func(read ReadPubSubMessage) []string {
+			messages := make(chan []string, 1)
+			go func() {
+				messages <- read()
+			}()
+			select {
+			case message := <-messages:
+				return message
+			case <-time.After(2 * time.Second):
+				t.Error("timed out waiting for pub/sub message")
+				return nil
 			}
 		}
 
-		// Yield
-		<-ticker.C
+		tests := []struct {
+			name    string
+			server  *SugarDB // The server to subscribe to. Defaults to server.
+			channel string
+			mutate  func() error
+			want    []string
+		}{
+			{
+				name:    "1. Keyspace channel receives the name of the command",
+				channel: "__keyspace@0__:NotifyKey1",
+				mutate: func() error {
+					_, _, err := server.Set("NotifyKey1", "value", SETOptions{})
+					return err
+				},
+				want: []string{"message", "__keyspace@0__:NotifyKey1", "set"},
+			},
+			{
+				name:    "2. Keyevent channel receives the name of the key",
+				channel: "__keyevent@0__:lpush",
+				mutate: func() error {
+					_, err := server.LPush("NotifyKey2", "element")
+					return err
+				},
+				want: []string{"message", "__keyevent@0__:lpush", "NotifyKey2"},
+			},
+			{
+				name:    "3. Deleted keys publish del",
+				channel: "__keyspace@0__:NotifyKey3",
+				mutate: func() error {
+					presetValue(server, context.Background(), "NotifyKey3", "value")
+					_, err := server.Del("NotifyKey3")
+					return err
+				},
+				want: []string{"message", "__keyspace@0__:NotifyKey3", "del"},
+			},
+			{
+				name:    "4. Expired keys publish expired when they are accessed",
+				channel: "__keyevent@0__:expired",
+				mutate: func() error {
+					presetKeyData(server, context.Background(), "NotifyKey4", internal.KeyData{
+						Value:    "value",
+						ExpireAt: server.clock.Now().Add(-time.Second),
+					})
+					_, err := server.Get("NotifyKey4")
+					return err
+				},
+				want: []string{"message", "__keyevent@0__:expired", "NotifyKey4"},
+			},
+			{
+				name:    "5. Evicted keys publish evicted",
+				server:  evictingServer,
+				channel: "__keyevent@0__:evicted",
+				mutate: func() error {
+					_, _, err := evictingServer.Set("NotifyKey5", "value", SETOptions{})
+					return err
+				},
+				want: []string{"message", "__keyevent@0__:evicted", "NotifyKey5"},
+			},
+		}
 
-		// Rewrite AOF
-		if _, err := mockServer.RewriteAOF(); err != nil {
-			t.Error(err)
-			return
+		for i, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				subscriber := server
+				if tt.server != nil {
+					subscriber = tt.server
+				}
+				tag := fmt.Sprintf("keyspace_notifications_%p_%d", subscriber, i)
+				read, err := subscriber.Subscribe(tag, tt.channel)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				defer subscriber.Unsubscribe(tag, tt.channel)
+				// Read the subscribe confirmation.
+				readMessage(read)
+
+				if err = tt.mutate(); err != nil {
+					t.Error(err)
+					return
+				}
+				if got := readMessage(read); !slices.Equal(got, tt.want) {
+					t.Errorf("expected message %v, got %v", tt.want, got)
+				}
+			})
 		}
+	})
 
-		// Perform write commands from "after-rewrite"
-		for key, value := range data["after-rewrite"] {
-			if _, _, err := mockServer.Set(key, value, SETOptions{}); err != nil {
-				t.Error(err)
-				return
-			}
+	t.Run("Test_InvalidKeyspaceEvents", func(t *testing.T) {
+		conf := DefaultConfig()
+		conf.DataDir = ""
+		conf.NotifyKeyspaceEvents = "KEq"
+		if _, err := NewSugarDB(WithConfig(conf)); err == nil {
+			t.Error("expected error for invalid keyspace events flags")
 		}
+	})
 
-		// Yield
-		<-ticker.C
+	t.Run("Test_Metrics", func(t *testing.T) {
+		t.Parallel()
 
-		// Shutdown the SugarDB instance
-		mockServer.ShutDown()
+		port, err := internal.GetFreePort()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		metricsPort, err := internal.GetFreePort()
+		if err != nil {
+			t.Error(err)
+			return
+		}
 
-		// Start another instance of SugarDB
-		mockServer, err = NewSugarDB(WithConfig(conf))
+		conf := DefaultConfig()
+		conf.DataDir = ""
+		conf.Port = uint16(port)
+		conf.MetricsPort = uint16(metricsPort)
+		mockServer, err := NewSugarDB(WithConfig(conf))
 		if err != nil {
 			t.Error(err)
 			return
 		}
+		go func() {
+			mockServer.Start()
+		}()
+		t.Cleanup(func() {
+			mockServer.ShutDown()
+		})
 
-		// Check if the servers contains the keys and values from "expected-values"
-		for key, value := range data["expected-values"] {
-			res, err := mockServer.Get(key)
-			if err != nil {
+		for _, key := range []string{"MetricsKey1", "MetricsKey2", "MetricsKey3"} {
+			if _, _, err = mockServer.Set(key, "value", SETOptions{}); err != nil {
 				t.Error(err)
 				return
 			}
-			if res != value {
-				t.Errorf("expected value at key \"%s\" to be \"%s\", got \"%s\"", key, value, res)
-				return
+		}
+
+		// Retry until the metrics listener is started.
+		url := fmt.Sprintf("http://localhost:%d/metrics", metricsPort)
+		var res *http.Response
+		for i := 0; i < 10; i++ {
+			if res, err = http.Get(url); err == nil {
+				break
 			}
+			<-time.After(100 * time.Millisecond)
+		}
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() {
+			_ = res.Body.Close()
+		}()
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Error(err)
+			return
 		}
-	})
 
-	t.Run("Test_EvictExpiredTTL", func(t *testing.T) {
-		// TODO: Implement test for evicting expired keys in standalone mode.
+		if contentType := res.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+			t.Errorf("expected the Prometheus text format, got content type %q", contentType)
+		}
+		for _, line := range []string{
+			"# TYPE sugardb_command_calls_total counter\n",
+			"sugardb_command_calls_total{command=\"set\"} 3\n",
+			"# TYPE sugardb_command_duration_seconds histogram\n",
+			"sugardb_command_duration_seconds_bucket{command=\"set\",le=\"+Inf\"} 3\n",
+			"sugardb_command_duration_seconds_count{command=\"set\"} 3\n",
+			"sugardb_memory_max_bytes 0\n",
+			"sugardb_db_keys{db=\"0\"} 3\n",
+			"sugardb_pubsub_channels 0\n",
+			"sugardb_info{version=\"" + constants.Version + "\",mode=\"standalone\",role=\"master\"} 1\n",
+		} {
+			if !strings.Contains(string(body), line) {
+				t.Errorf("expected the metrics to contain %q, got %q", line, string(body))
+			}
+		}
+		// The raft metrics are only reported in cluster mode.
+		if strings.Contains(string(body), "sugardb_raft_") {
+			t.Errorf("expected no raft metrics in standalone mode, got %q", string(body))
+		}
+
+		res, err = http.Post(url, "text/plain", nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_ = res.Body.Close()
+		if res.StatusCode != http.StatusMethodNotAllowed {
+			t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, res.StatusCode)
+		}
 	})
 
 	t.Run("Test_GetServerInfo", func(t *testing.T) {
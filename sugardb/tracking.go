@@ -0,0 +1,346 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sugardb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/echovault/sugardb/internal"
+	"github.com/echovault/sugardb/internal/constants"
+	"log"
+	"net"
+	"slices"
+	"strings"
+)
+
+// invalidationChannel is the pubsub channel that RESP2 connections subscribe to in order to receive the
+// invalidation messages redirected to them.
+const invalidationChannel = "__redis__:invalidate"
+
+// trackingClient holds the client side caching state of a connection that called CLIENT TRACKING ON.
+type trackingClient struct {
+	options internal.TrackingOptions
+	connID  string              // The connection ID from the context. Used to skip the connection's own changes in NOLOOP mode.
+	caching bool                // True if CLIENT CACHING was called for the next command.
+	keys    map[string]struct{} // The keys read by the connection in the default mode.
+}
+
+func (server *SugarDB) setClientTracking(ctx context.Context, conn *net.Conn, enable bool, options internal.TrackingOptions) error {
+	if conn == nil {
+		return errors.New("client tracking is not supported by the embedded connection")
+	}
+
+	if !enable {
+		server.tracking.mut.Lock()
+		defer server.tracking.mut.Unlock()
+		server.removeTrackingClient(conn)
+		return nil
+	}
+
+	if options.Redirect != 0 && server.getConnection(options.Redirect) == nil {
+		return errors.New("the client ID you want redirect to does not exist")
+	}
+
+	server.tracking.mut.Lock()
+	defer server.tracking.mut.Unlock()
+
+	client, ok := server.tracking.clients[conn]
+	if ok && client.options.BCast != options.BCast {
+		return errors.New("you can't switch BCAST mode on/off before disabling tracking for this client, " +
+			"and then re-enabling it with a different mode")
+	}
+
+	// Turning tracking on again adds the new prefixes to the ones that are already tracked.
+	prefixes := make([]string, 0, len(options.Prefixes))
+	if ok {
+		prefixes = append(prefixes, client.options.Prefixes...)
+	}
+	for _, prefix := range options.Prefixes {
+		if slices.Contains(prefixes, prefix) {
+			continue
+		}
+		for _, p := range prefixes {
+			if strings.HasPrefix(prefix, p) || strings.HasPrefix(p, prefix) {
+				return fmt.Errorf("prefix '%s' overlaps with an existing prefix '%s'. "+
+					"Prefixes for a single client must not overlap", prefix, p)
+			}
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	if !ok {
+		connID, _ := ctx.Value(internal.ContextConnID("ConnectionID")).(string)
+		client = &trackingClient{connID: connID, keys: make(map[string]struct{})}
+		server.tracking.clients[conn] = client
+	}
+	client.options = options
+	client.options.Prefixes = prefixes
+	client.caching = false
+
+	return nil
+}
+
+func (server *SugarDB) setClientCaching(conn *net.Conn, caching bool) error {
+	server.tracking.mut.Lock()
+	defer server.tracking.mut.Unlock()
+
+	client, ok := server.tracking.clients[conn]
+	switch {
+	case !ok || (!client.options.OptIn && !client.options.OptOut):
+		return errors.New("CLIENT CACHING can be called only when the client is in tracking mode with " +
+			"OPTIN or OPTOUT mode enabled")
+	case caching && !client.options.OptIn:
+		return errors.New("CLIENT CACHING YES is only valid when tracking is enabled in OPTIN mode")
+	case !caching && !client.options.OptOut:
+		return errors.New("CLIENT CACHING NO is only valid when tracking is enabled in OPTOUT mode")
+	}
+
+	client.caching = true
+	return nil
+}
+
+func (server *SugarDB) getClientTracking(conn *net.Conn) internal.TrackingInfo {
+	server.tracking.mut.Lock()
+	client, ok := server.tracking.clients[conn]
+	if !ok {
+		server.tracking.mut.Unlock()
+		return internal.TrackingInfo{}
+	}
+	info := internal.TrackingInfo{
+		TrackingOptions: client.options,
+		Enabled:         true,
+		Caching:         client.caching,
+	}
+	info.Prefixes = slices.Clone(client.options.Prefixes)
+	server.tracking.mut.Unlock()
+
+	info.BrokenRedirect = info.Redirect != 0 && server.getConnection(info.Redirect) == nil
+	return info
+}
+
+// removeTrackingClient turns tracking off for the connection and forgets the keys it read.
+// The caller must hold the tracking lock.
+func (server *SugarDB) removeTrackingClient(conn *net.Conn) {
+	client, ok := server.tracking.clients[conn]
+	if !ok {
+		return
+	}
+	for key := range client.keys {
+		delete(server.tracking.keys[key], conn)
+		if len(server.tracking.keys[key]) == 0 {
+			delete(server.tracking.keys, key)
+		}
+	}
+	delete(server.tracking.clients, conn)
+}
+
+// untrackConnection is called when the connection is closed. It turns tracking off for the connection and lets the
+// RESP3 clients that redirect their invalidation messages to it know that the redirection is broken.
+func (server *SugarDB) untrackConnection(conn *net.Conn, id uint64) {
+	server.tracking.mut.Lock()
+	server.removeTrackingClient(conn)
+	redirecting := make([]*net.Conn, 0)
+	for c, client := range server.tracking.clients {
+		if client.options.Redirect == id {
+			redirecting = append(redirecting, c)
+		}
+	}
+	server.tracking.mut.Unlock()
+
+	for _, c := range redirecting {
+		go func(c *net.Conn) {
+			if server.getConnectionInfo(c).Protocol != 3 {
+				return
+			}
+			server.writePush(c, []byte(fmt.Sprintf(">2\r\n$21\r\ntracking-redir-broken\r\n:%d\r\n", id)))
+		}(c)
+	}
+}
+
+// trackReadKeys remembers the keys that the command is about to read so that the connection is sent an
+// invalidation message when they're modified. Only read commands are tracked. The keys are recorded before the
+// command is executed so that a modification made while the command runs is not missed.
+func (server *SugarDB) trackReadKeys(conn *net.Conn, cmd []string, command internal.Command, subCommand internal.SubCommand) {
+	if conn == nil {
+		return
+	}
+
+	server.tracking.mut.Lock()
+	defer server.tracking.mut.Unlock()
+
+	client, ok := server.tracking.clients[conn]
+	if !ok || client.options.BCast {
+		return
+	}
+	if (client.options.OptIn && !client.caching) || (client.options.OptOut && client.caching) {
+		return
+	}
+
+	categories := slices.Concat(command.Categories, subCommand.Categories)
+	if !slices.Contains(categories, constants.ReadCategory) || internal.IsWriteCommand(command, subCommand) {
+		return
+	}
+
+	keyExtractionFunc := command.KeyExtractionFunc
+	if subCommand.KeyExtractionFunc != nil {
+		keyExtractionFunc = subCommand.KeyExtractionFunc
+	}
+	keys, err := keyExtractionFunc(cmd)
+	if err != nil {
+		return
+	}
+
+	for _, key := range keys.ReadKeys {
+		if server.tracking.keys[key] == nil {
+			server.tracking.keys[key] = make(map[*net.Conn]struct{})
+		}
+		server.tracking.keys[key][conn] = struct{}{}
+		client.keys[key] = struct{}{}
+	}
+}
+
+// resetTrackingCaching clears the flag set by CLIENT CACHING once the next command is executed.
+// The flag applies to all the commands of a transaction that is started right after CLIENT CACHING.
+func (server *SugarDB) resetTrackingCaching(conn *net.Conn, cmd []string) {
+	if conn == nil || strings.EqualFold(cmd[0], "multi") ||
+		(strings.EqualFold(cmd[0], "client") && len(cmd) > 1 && strings.EqualFold(cmd[1], "caching")) {
+		return
+	}
+
+	server.tracking.mut.Lock()
+	defer server.tracking.mut.Unlock()
+	if client, ok := server.tracking.clients[conn]; ok {
+		client.caching = false
+	}
+}
+
+// invalidateKey sends an invalidation message for the key to the connections that read it and to the connections
+// in broadcasting mode that track a prefix of the key. In the default mode, the key is tracked again only after it's
+// read again.
+func (server *SugarDB) invalidateKey(ctx context.Context, key string) {
+	server.tracking.mut.Lock()
+	defer server.tracking.mut.Unlock()
+
+	if len(server.tracking.clients) == 0 {
+		return
+	}
+
+	connID, _ := ctx.Value(internal.ContextConnID("ConnectionID")).(string)
+	skip := func(client *trackingClient) bool {
+		return client.options.NoLoop && connID != "" && client.connID == connID
+	}
+
+	for conn := range server.tracking.keys[key] {
+		client := server.tracking.clients[conn]
+		delete(client.keys, key)
+		if !skip(client) {
+			go server.sendInvalidation(conn, client.options.Redirect, []string{key})
+		}
+	}
+	delete(server.tracking.keys, key)
+
+	for conn, client := range server.tracking.clients {
+		if !client.options.BCast || skip(client) {
+			continue
+		}
+		if len(client.options.Prefixes) == 0 || slices.ContainsFunc(client.options.Prefixes, func(prefix string) bool {
+			return strings.HasPrefix(key, prefix)
+		}) {
+			go server.sendInvalidation(conn, client.options.Redirect, []string{key})
+		}
+	}
+}
+
+// invalidateAllKeys sends a null invalidation message to all the tracking connections when the keys are flushed.
+func (server *SugarDB) invalidateAllKeys(ctx context.Context) {
+	server.tracking.mut.Lock()
+	defer server.tracking.mut.Unlock()
+
+	connID, _ := ctx.Value(internal.ContextConnID("ConnectionID")).(string)
+	for conn, client := range server.tracking.clients {
+		clear(client.keys)
+		if client.options.NoLoop && connID != "" && client.connID == connID {
+			continue
+		}
+		go server.sendInvalidation(conn, client.options.Redirect, nil)
+	}
+	clear(server.tracking.keys)
+}
+
+// sendInvalidation writes the invalidation message for the keys to the connection, or to the connection it
+// redirects to. RESP3 connections receive an invalidate push message. RESP2 connections receive a pubsub message
+// when they're subscribed to the invalidation channel. A nil slice of keys invalidates all the keys.
+func (server *SugarDB) sendInvalidation(conn *net.Conn, redirect uint64, keys []string) {
+	target := conn
+	if redirect != 0 {
+		if target = server.getConnection(redirect); target == nil {
+			return
+		}
+	}
+
+	var message []byte
+	protocol := server.getConnectionInfo(target).Protocol
+	switch {
+	case protocol == 3:
+		message = []byte(">2\r\n$10\r\ninvalidate\r\n")
+	case server.subscribedToInvalidations(target):
+		message = []byte(fmt.Sprintf("*3\r\n$7\r\nmessage\r\n$%d\r\n%s\r\n", len(invalidationChannel), invalidationChannel))
+	default:
+		return
+	}
+
+	if keys == nil {
+		if protocol == 3 {
+			message = append(message, []byte("_\r\n")...)
+		} else {
+			message = append(message, []byte("*-1\r\n")...)
+		}
+	} else {
+		message = append(message, []byte(fmt.Sprintf("*%d\r\n", len(keys)))...)
+		for _, key := range keys {
+			message = append(message, []byte(fmt.Sprintf("$%d\r\n%s\r\n", len(key), key))...)
+		}
+	}
+
+	server.writePush(target, message)
+}
+
+// subscribedToInvalidations returns true if the connection is subscribed to the invalidation channel.
+func (server *SugarDB) subscribedToInvalidations(conn *net.Conn) bool {
+	for _, channel := range server.pubSub.GetAllChannels() {
+		if channel.Pattern() != nil || channel.Name() != invalidationChannel {
+			continue
+		}
+		_, ok := channel.Subscribers()[conn]
+		return ok
+	}
+	return false
+}
+
+// writePush writes a message that the client did not request to the TCP connection.
+// The write lock of the connection keeps it from being interleaved with a response.
+func (server *SugarDB) writePush(conn *net.Conn, message []byte) {
+	state := server.getConnectionState(conn)
+	if state == nil {
+		return
+	}
+
+	state.writer.Lock()
+	defer state.writer.Unlock()
+	if _, err := (*conn).Write(message); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,397 @@
+// Copyright 2024 Kelvin Clement Mwinuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sugardb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/echovault/sugardb/internal"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// connectionCommands are the commands that change the state of the client connection, with the subcommands that do.
+// A nil slice means the command itself. They're not allowed in embedded transactions, as the session of the
+// transaction is not a client connection.
+var connectionCommands = map[string][]string{
+	"auth":         nil,
+	"hello":        nil,
+	"monitor":      nil,
+	"subscribe":    nil,
+	"psubscribe":   nil,
+	"unsubscribe":  nil,
+	"punsubscribe": nil,
+	"client":       {"tracking", "caching", "reply", "no-evict"},
+}
+
+// transaction holds the commands queued by a client between MULTI and EXEC.
+type transaction struct {
+	commands [][]string // The queued commands in the order they were received.
+	sync     bool       // True if any of the queued commands has to be synced across the cluster.
+	aborted  bool       // True if a command could not be queued. EXEC discards the transaction in that case.
+}
+
+// watchedKey is a key watched by a client with WATCH, along with the state of the key at the time it was watched.
+type watchedKey struct {
+	database int    // The database the key was watched in.
+	key      string // The watched key.
+	version  uint64 // The version of the key. 0 if the key did not have a version.
+	deleted  uint64 // The version of the latest deletion in the database applied from the raft log.
+	expired  bool   // True if the key was already expired.
+}
+
+// getTransaction returns the transaction in progress for the connection, or nil if there is none.
+func (server *SugarDB) getTransaction(conn *net.Conn) *transaction {
+	server.transactions.mut.Lock()
+	defer server.transactions.mut.Unlock()
+	return server.transactions.clients[conn]
+}
+
+func (server *SugarDB) startTransaction(conn *net.Conn) error {
+	// The embedded API calls share a nil connection, so a transaction on it would queue the calls of every goroutine.
+	if conn == nil {
+		return errors.New("MULTI is not supported without a connection, use the Multi method instead")
+	}
+
+	server.transactions.mut.Lock()
+	defer server.transactions.mut.Unlock()
+	if _, ok := server.transactions.clients[conn]; ok {
+		return errors.New("MULTI calls can not be nested")
+	}
+	server.transactions.clients[conn] = &transaction{commands: make([][]string, 0)}
+	return nil
+}
+
+func (server *SugarDB) discardTransaction(conn *net.Conn) error {
+	server.transactions.mut.Lock()
+	if _, ok := server.transactions.clients[conn]; !ok {
+		server.transactions.mut.Unlock()
+		return errors.New("DISCARD without MULTI")
+	}
+	delete(server.transactions.clients, conn)
+	server.transactions.mut.Unlock()
+
+	// DISCARD also unwatches all the keys.
+	server.unwatchKeys(server.context, conn)
+	return nil
+}
+
+// watchKeys records the current version of the keys so that the next EXEC from the connection is
+// aborted if any of them is modified, deleted or expires in the meantime.
+func (server *SugarDB) watchKeys(ctx context.Context, conn *net.Conn, keys []string) error {
+	if conn == nil {
+		return errors.New("WATCH is not supported without a connection, use the Multi method instead")
+	}
+	if server.getTransaction(conn) != nil {
+		return errors.New("WATCH inside MULTI is not allowed")
+	}
+
+	database := ctx.Value("Database").(int)
+	watched := make([]watchedKey, len(keys))
+
+	server.lockStore(ctx)
+	for i, key := range keys {
+		entry, ok := server.store[database][key]
+		watched[i] = watchedKey{
+			database: database,
+			key:      key,
+			version:  server.keyVersions.versions[database][key],
+			deleted:  server.keyVersions.deleted[database],
+			expired:  ok && server.isExpired(entry),
+		}
+		if server.keyVersions.watchers[database] == nil {
+			server.keyVersions.watchers[database] = make(map[string]int)
+		}
+		server.keyVersions.watchers[database][key]++
+	}
+	server.unlockStore(ctx)
+
+	server.transactions.mut.Lock()
+	defer server.transactions.mut.Unlock()
+	server.transactions.watched[conn] = append(server.transactions.watched[conn], watched...)
+	return nil
+}
+
+// unwatchKeys forgets all the keys watched by the connection.
+func (server *SugarDB) unwatchKeys(ctx context.Context, conn *net.Conn) {
+	server.transactions.mut.Lock()
+	watched := server.transactions.watched[conn]
+	delete(server.transactions.watched, conn)
+	server.transactions.mut.Unlock()
+
+	if len(watched) == 0 {
+		return
+	}
+
+	server.lockStore(ctx)
+	defer server.unlockStore(ctx)
+	server.releaseWatchedKeys(watched)
+}
+
+// releaseWatchedKeys decrements the number of watchers of the keys.
+// The caller must hold the store lock.
+func (server *SugarDB) releaseWatchedKeys(watched []watchedKey) {
+	for _, w := range watched {
+		server.keyVersions.watchers[w.database][w.key]--
+		if server.keyVersions.watchers[w.database][w.key] > 0 {
+			continue
+		}
+		delete(server.keyVersions.watchers[w.database], w.key)
+		// Drop the version kept for a deleted key now that nobody is watching it.
+		if _, ok := server.store[w.database][w.key]; !ok {
+			delete(server.keyVersions.versions[w.database], w.key)
+		}
+	}
+}
+
+// closeSession forgets the session of an embedded transaction once it has ended.
+// A SELECT queued in the transaction registers the session in the connection info, so it's removed here,
+// along with the state that the commands executed in the transaction may have attached to the session.
+func (server *SugarDB) closeSession(session *net.Conn) {
+	server.unwatchKeys(server.context, session)
+
+	server.transactions.mut.Lock()
+	delete(server.transactions.clients, session)
+	server.transactions.mut.Unlock()
+
+	server.connInfo.mut.Lock()
+	delete(server.connInfo.tcpClients, session)
+	server.connInfo.mut.Unlock()
+
+	server.tracking.mut.Lock()
+	server.removeTrackingClient(session)
+	server.tracking.mut.Unlock()
+
+	if server.acl != nil {
+		server.acl.UnregisterConnection(session)
+	}
+}
+
+// watchedKeysModified returns true if any of the watched keys was modified or deleted since it was watched.
+// The caller must hold the store lock.
+func (server *SugarDB) watchedKeysModified(watched []watchedKey) bool {
+	for _, w := range watched {
+		if server.keyVersions.versions[w.database][w.key] != w.version {
+			return true
+		}
+		// The deletions applied from the raft log drop the version of the key, so a key that did not exist and was
+		// created and deleted again is detected with the latest deletion in the database.
+		if _, ok := server.store[w.database][w.key]; !ok && w.version == 0 &&
+			server.keyVersions.deleted[w.database] != w.deleted {
+			return true
+		}
+	}
+	return false
+}
+
+// watchedKeysExpired returns true if any of the watched keys expired since it was watched.
+// The caller must hold the store lock.
+func (server *SugarDB) watchedKeysExpired(watched []watchedKey) bool {
+	for _, w := range watched {
+		if entry, ok := server.store[w.database][w.key]; ok && !w.expired && server.isExpired(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// queueCommand validates the command and adds it to the transaction.
+// Like the checks in handleCommand, an unknown command, a wrong number of arguments or an ACL
+// rejection returns an error. The transaction is then marked as aborted so that EXEC discards it.
+func (server *SugarDB) queueCommand(tx *transaction, cmd []string, conn *net.Conn, embedded bool) ([]byte, error) {
+	err := func() error {
+		command, err := server.getCommand(cmd[0])
+		if err != nil {
+			return err
+		}
+
+		synchronize := command.Sync
+		keyExtractionFunc := command.KeyExtractionFunc
+
+		sc, err := internal.GetSubCommand(command, cmd)
+		if err != nil {
+			return err
+		}
+		subCommand, ok := sc.(internal.SubCommand)
+		if ok {
+			synchronize = subCommand.Sync
+			keyExtractionFunc = subCommand.KeyExtractionFunc
+		}
+
+		// The session of an embedded transaction is not a client connection, so the commands bound to the
+		// connection are rejected.
+		if subCommands, ok := connectionCommands[strings.ToLower(command.Command)]; embedded && ok &&
+			(subCommands == nil || slices.Contains(subCommands, strings.ToLower(subCommand.Command))) {
+			return fmt.Errorf("%s is not allowed in embedded transactions", strings.ToUpper(strings.TrimSpace(command.Command+" "+subCommand.Command)))
+		}
+
+		if conn != nil && server.acl != nil && !embedded {
+			if err = server.acl.AuthorizeConnection(conn, cmd, command, subCommand); err != nil {
+				return err
+			}
+		}
+
+		// The key extraction function validates the number of arguments.
+		if _, err = keyExtractionFunc(cmd); err != nil {
+			return err
+		}
+
+		server.transactions.mut.Lock()
+		defer server.transactions.mut.Unlock()
+		tx.commands = append(tx.commands, cmd)
+		tx.sync = tx.sync || synchronize
+		return nil
+	}()
+
+	if err != nil {
+		server.transactions.mut.Lock()
+		tx.aborted = true
+		server.transactions.mut.Unlock()
+		return nil, err
+	}
+
+	return []byte("+QUEUED\r\n"), nil
+}
+
+func (server *SugarDB) execTransaction(ctx context.Context, conn *net.Conn) ([]byte, error) {
+	server.transactions.mut.Lock()
+	tx, ok := server.transactions.clients[conn]
+	delete(server.transactions.clients, conn)
+	watched := server.transactions.watched[conn]
+	server.transactions.mut.Unlock()
+
+	if !ok {
+		return nil, errors.New("EXEC without MULTI")
+	}
+
+	// EXEC unwatches all the keys, whether the transaction is executed or not.
+	defer server.unwatchKeys(ctx, conn)
+
+	if tx.aborted {
+		return nil, errors.New("EXECABORT Transaction discarded because of previous errors")
+	}
+
+	if !server.isInCluster() || !tx.sync {
+		return server.runTransaction(ctx, tx.commands, conn, watched), nil
+	}
+
+	// Apply the whole transaction in one raft log entry so that it's applied atomically on every node.
+	// The versions of the watched keys are checked when the entry is applied, after all the writes before it.
+	// The expiry depends on the clock of the node, so the leader checks it before the transaction is replicated.
+	if server.raft.IsRaftLeader() {
+		server.storeLock.RLock()
+		modified := server.watchedKeysModified(watched) || server.watchedKeysExpired(watched)
+		server.storeLock.RUnlock()
+		if modified {
+			return []byte("*-1\r\n"), nil
+		}
+		return server.raftApplyTransaction(ctx, tx.commands, watched)
+	}
+
+	return nil, errors.New("not cluster leader, cannot carry out transaction")
+}
+
+// runTransaction executes the commands while holding the store lock, so no other command can
+// observe or modify the keyspace until all of them are applied.
+// A command that fails does not stop the transaction. Its error is returned in its position in the
+// response array and the remaining commands are still executed.
+// If any of the watched keys was modified, none of the commands are executed and a null array is returned.
+func (server *SugarDB) runTransaction(ctx context.Context, cmds [][]string, conn *net.Conn, watched []watchedKey) []byte {
+	// If the transaction contains a write command, wait for state copy to finish.
+	for _, cmd := range cmds {
+		command, err := server.getCommand(cmd[0])
+		if err != nil {
+			continue
+		}
+		sc, _ := internal.GetSubCommand(command, cmd)
+		subCommand, _ := sc.(internal.SubCommand)
+		if internal.IsWriteCommand(command, subCommand) {
+			for {
+				if !server.stateCopyInProgress.Load() {
+					server.stateMutationInProgress.Store(true)
+					break
+				}
+			}
+			defer server.stateMutationInProgress.Store(false)
+			break
+		}
+	}
+
+	server.storeLock.Lock()
+	defer server.storeLock.Unlock()
+
+	if server.watchedKeysModified(watched) {
+		return []byte("*-1\r\n")
+	}
+	// The transactions applied from the raft log rely on the expiry check of the leader.
+	if _, ok := ctx.Value(internal.ContextRaftIndex("RaftIndex")).(uint64); !ok && server.watchedKeysExpired(watched) {
+		return []byte("*-1\r\n")
+	}
+
+	ctx = context.WithValue(ctx, internal.ContextTransaction("Transaction"), true)
+	database, _ := ctx.Value("Database").(int)
+
+	res := []byte(fmt.Sprintf("*%d\r\n", len(cmds)))
+	for _, cmd := range cmds {
+		b, err := func() ([]byte, error) {
+			command, err := server.getCommand(cmd[0])
+			if err != nil {
+				return nil, err
+			}
+
+			handler := command.HandlerFunc
+
+			sc, err := internal.GetSubCommand(command, cmd)
+			if err != nil {
+				return nil, err
+			}
+			subCommand, ok := sc.(internal.SubCommand)
+			if ok {
+				handler = subCommand.HandlerFunc
+			}
+
+			server.trackReadKeys(conn, cmd, command, subCommand)
+			cmdCtx, prop := withPropagation(ctx)
+			b, err := handler(server.getHandlerFuncParams(cmdCtx, cmd, conn))
+			server.feedMonitors(ctx, conn, cmd)
+
+			// Like in handleCommand, the commands propagated by the handler are logged even if it fails.
+			if (err == nil || prop.set) && internal.IsWriteCommand(command, subCommand) && !server.isInCluster() {
+				server.logCommand(database, internal.EncodeCommand(cmd), prop)
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			// SELECT changes the database of the commands queued after it.
+			if strings.EqualFold(cmd[0], "select") {
+				database, _ = strconv.Atoi(cmd[1])
+				ctx = context.WithValue(ctx, "Database", database)
+			}
+
+			return b, nil
+		}()
+		if err != nil {
+			res = append(res, []byte(fmt.Sprintf("-Error %s\r\n", err.Error()))...)
+			continue
+		}
+		res = append(res, b...)
+	}
+
+	return res
+}